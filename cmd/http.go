@@ -0,0 +1,419 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/httpauth"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/telemetry"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/kubernetes"
+	toolmiddleware "github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/middleware"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/singleflight"
+)
+
+// runHTTPServer serves s over the streamable HTTP transport until ctx is
+// done.
+func runHTTPServer(ctx context.Context, c *config.Config, s *mcp.Server, middleware []mcp.Middleware, tel *telemetry.Telemetry, opts startOptions) error {
+	getServer := func(*http.Request) *mcp.Server { return s }
+	// With profiles, each session has a server of its own, so that use_profile
+	// only switches the profile of its session.
+	if opts.credentialPassthrough || len(c.Profiles()) > 0 {
+		getServer = newSessionServers(ctx, c, middleware, s, opts.credentialPassthrough, len(c.Profiles()) > 0).get
+	}
+	readyConfig := c
+	if len(c.Profiles()) > 0 {
+		// The readiness check probes the cluster of the start profile.
+		var err error
+		if readyConfig, err = c.WithProfile(c.StartProfile()); err != nil {
+			return err
+		}
+	}
+	ready, err := kubernetes.NewReadinessCheck(readyConfig)
+	if err != nil {
+		return fmt.Errorf("failed to set up readiness check: %w", err)
+	}
+	var alertsHandler http.Handler
+	if store := c.Alerts(); store != nil {
+		alertsHandler = store
+	}
+	var approvalsHandler http.Handler
+	if store := c.Approvals(); store != nil && len(opts.approvalTokens) > 0 {
+		approvalsHandler = store
+	}
+	handler, err := newHTTPHandler(getServer, tel, ready, alertsHandler, approvalsHandler, opts)
+	if err != nil {
+		return fmt.Errorf("failed to set up HTTP server: %w", err)
+	}
+	endpoint := net.JoinHostPort(opts.listenAddress, strconv.Itoa(opts.serverPort))
+	slog.Info("Listening for HTTP connections", "address", endpoint)
+	return serveHTTP(ctx, endpoint, handler)
+}
+
+// newHTTPHandler builds the handler serving MCP sessions in HTTP mode,
+// along with the optional metrics and alerts endpoints, behind
+// authentication if any is configured. getServer returns the server for each
+// new session, and alerts, if not nil, receives the webhook alerts.
+// The health endpoints are served without authentication so that they can be
+// used by Kubernetes probes and load balancers. approvals, if not nil, serves
+// the approvers with the tokens of opts.approvalTokens only, so that the
+// credentials of the MCP clients can't approve the actions of their agents.
+func newHTTPHandler(getServer func(*http.Request) *mcp.Server, tel *telemetry.Telemetry, ready func(context.Context) error, alerts, approvals http.Handler, opts startOptions) (http.Handler, error) {
+	mux := http.NewServeMux()
+	mux.Handle("/", mcp.NewStreamableHTTPHandler(getServer, nil))
+	if h := tel.MetricsHandler(); h != nil {
+		mux.Handle("/metrics", h)
+	}
+	if alerts != nil {
+		mux.Handle("/alerts", alerts)
+	}
+
+	var handler http.Handler = mux
+	if opts.auth.Enabled() {
+		authenticator, err := httpauth.New(opts.auth)
+		if err != nil {
+			return nil, err
+		}
+		handler = authenticator.Middleware(mux)
+	} else if !isLoopback(opts.listenAddress) {
+		slog.Warn("HTTP server is exposed beyond localhost without authentication; use --auth-token-file or --oidc-issuer-url to require credentials", "address", opts.listenAddress)
+	}
+
+	root := http.NewServeMux()
+	root.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	root.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+		if err := ready(ctx); err != nil {
+			slog.Warn("Readiness check failed", "error", err)
+			http.Error(w, fmt.Sprintf("not ready: %v", err), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	if alerts != nil {
+		root.Handle("/alerts", tokenFromQuery(handler))
+	}
+	if approvals != nil {
+		authenticator, err := httpauth.New(httpauth.Options{Tokens: opts.approvalTokens})
+		if err != nil {
+			return nil, fmt.Errorf("invalid approval tokens: %w", err)
+		}
+		root.Handle("/approvals", authenticator.Middleware(approvals))
+		root.Handle("/approvals/", authenticator.Middleware(approvals))
+	}
+	root.Handle("/", handler)
+	return root, nil
+}
+
+// tokenFromQuery passes the auth_token query parameter of requests as a
+// bearer token to next, for webhooks that can't set headers, such as the
+// token authentication of Cloud Monitoring.
+func tokenFromQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("auth_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r = r.Clone(r.Context())
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// readinessTimeout bounds the time spent checking that the Kubernetes API
+// server is reachable when serving /readyz.
+const readinessTimeout = 5 * time.Second
+
+// shutdownTimeout bounds the time given to in-flight requests to complete
+// when the HTTP server shuts down.
+const shutdownTimeout = 10 * time.Second
+
+// serveHTTP serves handler on address until ctx is done, then shuts the
+// server down gracefully.
+func serveHTTP(ctx context.Context, address string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:    address,
+		Handler: handler,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down HTTP server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("HTTP server did not shut down gracefully", "error", err)
+		srv.Close()
+	}
+	return ctx.Err()
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+const (
+	// kubeTokenHeader carries the bearer token used to call the Kubernetes
+	// API on behalf of the client when credential passthrough is enabled.
+	kubeTokenHeader = "X-Kubernetes-Token"
+	// googleTokenHeader carries the OAuth access token used to call Google
+	// Cloud APIs on behalf of the client when credential passthrough is
+	// enabled.
+	googleTokenHeader = "X-Google-Access-Token"
+)
+
+const (
+	// sessionServerTTL is how long a server for client credentials, or for a
+	// session, is kept once it has no sessions left.
+	sessionServerTTL = 15 * time.Minute
+	// sessionServerGrace protects a new server from eviction until the
+	// session it was created for is connected.
+	sessionServerGrace = time.Minute
+	// maxSessionServers bounds the number of servers for client credentials,
+	// or for sessions. Only servers without sessions are evicted, the least
+	// recently used first: once all of them have sessions, new sessions are
+	// refused.
+	maxSessionServers = 64
+)
+
+// sessionServer is a server for client credentials, or for a session.
+type sessionServer struct {
+	server *mcp.Server
+	// cancel cancels the context of the tools of the server.
+	cancel   context.CancelFunc
+	created  time.Time
+	lastUsed time.Time
+}
+
+// close stops the server: its tools are cancelled, its sessions closed, and
+// its tool middleware dropped.
+func (e *sessionServer) close() {
+	e.cancel()
+	for session := range e.server.Sessions() {
+		session.Close()
+	}
+	toolmiddleware.Forget(e.server)
+}
+
+// sessionServers hands out MCP servers whose tools run with the credentials
+// supplied by the client, or a server per session. Servers are shared between
+// sessions presenting the same credentials, unless each session has its own,
+// and evicted once they have no sessions and are idle for sessionServerTTL,
+// or to make room for new servers beyond maxSessionServers.
+type sessionServers struct {
+	ctx        context.Context
+	c          *config.Config
+	middleware []mcp.Middleware
+	fallback   *mcp.Server
+	// credentials enables the client credentials of the request headers.
+	credentials bool
+	// perSession gives each session a server of its own, whose profile is
+	// switched by use_profile independently of the other sessions.
+	perSession bool
+
+	// group creates a single server for concurrent sessions presenting the
+	// same new credentials, outside of mu.
+	group singleflight.Group
+
+	mu      sync.Mutex
+	servers map[[sha256.Size]byte]*sessionServer
+}
+
+func newSessionServers(ctx context.Context, c *config.Config, middleware []mcp.Middleware, fallback *mcp.Server, credentials, perSession bool) *sessionServers {
+	return &sessionServers{
+		ctx:         ctx,
+		c:           c,
+		middleware:  middleware,
+		fallback:    fallback,
+		credentials: credentials,
+		perSession:  perSession,
+		servers:     make(map[[sha256.Size]byte]*sessionServer),
+	}
+}
+
+// get returns the server for the credentials in the headers of r, or a new
+// server for the session of r. Requests without credentials are served with
+// the identity of the server itself. It returns nil, rejecting the session,
+// if the server can't be created, or if there are maxSessionServers servers
+// with sessions.
+func (ss *sessionServers) get(r *http.Request) *mcp.Server {
+	var creds config.Credentials
+	if ss.credentials {
+		creds = config.Credentials{
+			KubeBearerToken:   strings.TrimSpace(r.Header.Get(kubeTokenHeader)),
+			GoogleAccessToken: strings.TrimSpace(r.Header.Get(googleTokenHeader)),
+		}
+	}
+	if creds == (config.Credentials{}) && !ss.perSession {
+		return ss.fallback
+	}
+	key := sha256.Sum256([]byte(creds.KubeBearerToken + "\x00" + creds.GoogleAccessToken))
+	if ss.perSession {
+		// A random key is never shared, nor looked up again.
+		rand.Read(key[:])
+	}
+
+	if s := ss.lookup(key); s != nil {
+		return s
+	}
+	if !ss.makeRoom() {
+		slog.Warn("Refused MCP session: all the servers for sessions are in use", "max", maxSessionServers)
+		return nil
+	}
+	s, err, _ := ss.group.Do(string(key[:]), func() (any, error) {
+		// The server may have been added since the lookup.
+		if s := ss.lookup(key); s != nil {
+			return s, nil
+		}
+		c := ss.c
+		if creds != (config.Credentials{}) {
+			c = c.WithCredentials(creds)
+		}
+		ctx, cancel := context.WithCancel(ss.ctx)
+		s, err := newMCPServer(ctx, c, ss.middleware)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		now := time.Now()
+		e := &sessionServer{server: s, cancel: cancel, created: now, lastUsed: now}
+		s.AddReceivingMiddleware(ss.touch(e))
+		ss.add(key, e)
+		return s, nil
+	})
+	if err != nil {
+		// Returning nil makes the handler reject the session.
+		slog.Error("Failed to create MCP server for session", "error", err)
+		return nil
+	}
+	return s.(*mcp.Server)
+}
+
+// touch returns a receiving middleware marking e used on every request of
+// its sessions.
+func (ss *sessionServers) touch(e *sessionServer) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			ss.mu.Lock()
+			e.lastUsed = time.Now()
+			ss.mu.Unlock()
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// lookup returns the server for key, if any, and marks it used.
+func (ss *sessionServers) lookup(key [sha256.Size]byte) *mcp.Server {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	e, ok := ss.servers[key]
+	if !ok {
+		return nil
+	}
+	e.lastUsed = time.Now()
+	return e.server
+}
+
+// makeRoom evicts the servers that can be evicted, and reports whether
+// there is room for a new server.
+func (ss *sessionServers) makeRoom() bool {
+	ss.mu.Lock()
+	evicted := ss.evictLocked(time.Now())
+	room := len(ss.servers) < maxSessionServers
+	ss.mu.Unlock()
+
+	for _, e := range evicted {
+		e.close()
+	}
+	if len(evicted) > 0 {
+		slog.Debug("Evicted MCP servers for sessions", "count", len(evicted))
+	}
+	return room
+}
+
+// add adds the server e for key.
+func (ss *sessionServers) add(key [sha256.Size]byte, e *sessionServer) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.servers[key] = e
+}
+
+// evictLocked removes the servers without sessions unused for
+// sessionServerTTL, then, while there are maxSessionServers servers or more,
+// the least recently used servers without sessions, and returns them.
+// Servers with sessions, and servers created less than sessionServerGrace
+// ago, are never evicted. ss.mu must be held.
+func (ss *sessionServers) evictLocked(now time.Time) []*sessionServer {
+	var evicted []*sessionServer
+	for key, e := range ss.servers {
+		if now.Sub(e.lastUsed) < sessionServerTTL || now.Sub(e.created) < sessionServerGrace || hasSessions(e.server) {
+			continue
+		}
+		delete(ss.servers, key)
+		evicted = append(evicted, e)
+	}
+	for len(ss.servers) >= maxSessionServers {
+		var oldest *sessionServer
+		var oldestKey [sha256.Size]byte
+		for key, e := range ss.servers {
+			if now.Sub(e.created) < sessionServerGrace || hasSessions(e.server) {
+				continue
+			}
+			if oldest == nil || e.lastUsed.Before(oldest.lastUsed) {
+				oldest, oldestKey = e, key
+			}
+		}
+		if oldest == nil {
+			break
+		}
+		delete(ss.servers, oldestKey)
+		evicted = append(evicted, oldest)
+	}
+	return evicted
+}
+
+// hasSessions reports whether s has connected sessions.
+func hasSessions(s *mcp.Server) bool {
+	for range s.Sessions() {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/kubernetes"
+)
+
+// operatingProcedure is the short procedure of the server instructions, for
+// clients that don't read the GEMINI.md resource.
+const operatingProcedure = `## Operating Procedure
+
+1. Start by confirming which cluster and project you are working on.
+2. Investigate before acting: read the resources, events and logs involved, and state what you found.
+3. Before changing resources, explain the change and its impact, and get the user's confirmation. Prefer dry runs and diffs when the tools offer them.
+4. Make one change at a time, then verify its effect before going further.
+5. Don't guess values such as resource requests or node counts: derive them from metrics, logs or documentation, and say where they come from.`
+
+// serverInstructions returns the instructions of the server, describing its
+// mode, the tools it enables and the environment they work on. With
+// profiles, they describe the start profile.
+func serverInstructions(c *config.Config) string {
+	if len(c.Profiles()) > 0 {
+		if pc, err := c.WithProfile(c.StartProfile()); err == nil {
+			c = pc
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString("The KubeAPI MCP server manages Kubernetes clusters, especially GKE clusters, and their Google Cloud resources.\n\n")
+	out.WriteString(modeInstructions(c) + "\n\n")
+
+	out.WriteString("## Environment\n\n")
+	if name := c.Profile(); name != "" {
+		out.WriteString(fmt.Sprintf("* Profile: %s. List profiles with the list_profiles tool, and switch with the use_profile tool.\n", name))
+	}
+	if kubeContext := kubernetes.KubeContext(c); kubeContext != "" {
+		out.WriteString(fmt.Sprintf("* Kubeconfig context: %s\n", kubeContext))
+	}
+	if project := c.DefaultProjectID(); project != "" {
+		out.WriteString(fmt.Sprintf("* Default Google Cloud project: %s\n", project))
+	}
+	if location := c.DefaultLocation(); location != "" {
+		out.WriteString(fmt.Sprintf("* Default location: %s\n", location))
+	}
+	if ns := c.DefaultNamespace(); ns != "" {
+		out.WriteString(fmt.Sprintf("* Default namespace: %s\n", ns))
+	}
+	if protected := c.ProtectedNamespaces(); !c.ReadOnly() && len(protected) > 0 {
+		out.WriteString(fmt.Sprintf("* Protected namespaces, whose resources can't be changed: %s\n", strings.Join(protected, ", ")))
+	}
+
+	out.WriteString("\n## Tool Groups\n\n")
+	for _, group := range toolGroups(c) {
+		out.WriteString("* " + group + "\n")
+	}
+
+	out.WriteString("\n" + operatingProcedure + "\n")
+	return out.String()
+}
+
+// modeInstructions returns the statement of the mode of the server, so that
+// the model knows whether mutations are available.
+func modeInstructions(c *config.Config) string {
+	if c.ReadOnly() {
+		return "The server is running in read-only mode: the tools that create, modify or delete resources are not available. Don't try to change resources; give the user the commands to run instead."
+	}
+	if c.NamespacedWritesOnly() {
+		return "The server only allows changes of namespaced resources: tools can create, modify and delete resources such as deployments, services and config maps, but not cluster-scoped resources, such as namespaces, nodes, cluster roles and custom resource definitions, nor GKE clusters and node pools. Give the user the commands to run for these changes instead. Confirm changes with the user before making them."
+	}
+	if len(c.Profiles()) > 0 {
+		return "The server is running in read-write mode, but profiles can be read-only: check the active profile with the list_profiles tool before changing resources."
+	}
+	return "The server is running in read-write mode: tools can create, modify and delete resources. Confirm changes with the user before making them."
+}
+
+// toolGroups returns the descriptions of the groups of tools enabled by c.
+func toolGroups(c *config.Config) []string {
+	groups := []string{
+		"Kubernetes (kube_*): read resources, logs, events and metrics of the cluster.",
+		"GKE and Google Cloud (gke_*, gcp_*): clusters, node pools, operations, Cloud Logging, quotas and usage metering.",
+	}
+	if !c.ReadOnly() && c.NamespacedWritesOnly() {
+		groups = append(groups, "Writes (kube_apply_resource, kube_apply_bundle, kube_patch_resource, kube_delete_resource, kube_batch, kube_undo_last_change, kube_exec, kube_port_forward_start): change namespaced Kubernetes resources, undo the last changes, run commands in containers, and forward local ports to them.")
+	} else if !c.ReadOnly() {
+		groups = append(groups, "Writes (kube_apply_resource, kube_apply_bundle, kube_patch_resource, kube_delete_resource, kube_batch, kube_undo_last_change, kube_exec, kube_port_forward_start, kube_clone_namespace, kube_create_namespace, kube_delete_namespace, gke_create_*, gke_update_*, gke_enable_usage_metering, gke_delete_cluster): change Kubernetes resources, undo the last changes, run commands in containers, forward local ports to them, and create, update and delete GKE clusters and node pools.")
+		if c.RequireApproval() {
+			groups = append(groups, "Approvals (pending_actions_list, approve_action, reject_action): changes are queued as pending actions; approve_action runs an action once an approver approved it at the approvals endpoint of the server, or else asks the user to approve it through the client, and runs it only if they do.")
+		}
+		if c.AllowNodeDebug() {
+			groups = append(groups, "Node debugging (kube_debug_node): run commands on nodes in privileged pods.")
+		}
+	}
+	groups = append(groups, "Session notes and reports (session_note_add, session_note_list, generate_incident_report): record the findings of the investigation, such as suspected causes, read them back before concluding, and turn the session into a postmortem skeleton.")
+	if c.NotificationsSubscription() != "" {
+		groups = append(groups, "GKE cluster notifications (gke_recent_notifications): upgrades and security bulletins received from Pub/Sub.")
+	}
+	if c.Alerts() != nil {
+		groups = append(groups, "Alerts (alerts_list): firing Alertmanager and Cloud Monitoring alerts received by the server; start troubleshooting from their labels.")
+	}
+	if c.HealthCheckInterval() > 0 {
+		groups = append(groups, "Background health checks (kube_health_check_findings): problems found by the checks the server runs every "+c.HealthCheckInterval().String()+", also sent as log messages.")
+	}
+	if c.UDTPath() != "" {
+		groups = append(groups, "Troubleshooting playbooks (udt_*): search and read the playbooks of "+c.UDTPath()+" before troubleshooting.")
+	}
+	if len(c.Profiles()) > 0 {
+		groups = append(groups, "Profiles (list_profiles, use_profile): list the environments and switch between them.")
+	}
+	groups = append(groups, "Server information (server_info): the version, mode and limits of the server, and why tools are disabled.")
+	return groups
+}
@@ -18,15 +18,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
-	"net/http"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"runtime/debug"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/alerts"
 	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/httpauth"
 	"github.com/dmitryshnayder/kubeapi-mcp/pkg/install"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/logging"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/policy"
 	"github.com/dmitryshnayder/kubeapi-mcp/pkg/prompts"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/telemetry"
 	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/approvals"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/kubernetes"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 )
@@ -44,11 +56,62 @@ var (
 	readOnly   bool
 	udtPath    string
 
+	kubeQPS          float32
+	kubeBurst        int
+	requestTimeout   time.Duration
+	cacheTTL         time.Duration
+	fieldManager     string
+	defaultNamespace string
+	allowNodeDebug   bool
+	logQueriesPath   string
+	secretRedaction  string
+
+	notificationsSubscription string
+	healthCheckInterval       time.Duration
+	healthChecks              []string
+	googleCredentialsFile     string
+	impersonateServiceAccount string
+
+	profilesPath           string
+	profile                string
+	allowProfileEscalation bool
+	toolTimeouts           map[string]string
+
+	logLevel     string
+	logFormat    string
+	logTransport bool
+
+	otlpEndpoint string
+	metrics      bool
+	// receiveAlerts enables the alerts endpoint.
+	receiveAlerts bool
+
+	listenAddress string
+	authTokenFile string
+	oidcIssuerURL string
+	oidcAudience  string
+
+	credentialPassthrough bool
+
+	namespacedWritesOnly bool
+
+	protectedNamespaces         []string
+	unsafeAllowSystemNamespaces bool
+
+	requireApproval   bool
+	approvalWebhook   string
+	approvalTokenFile string
+	policyPath        string
+
+	outputDir     string
+	outputBuckets []string
+
 	// rootCmd represents the base command when called without any subcommands
 	rootCmd = &cobra.Command{
-		Use:   "kubeapi-mcp",
-		Short: "An MCP Server for Kubernetes",
-		Run:   runRootCmd,
+		Use:               "kubeapi-mcp",
+		Short:             "An MCP Server for Kubernetes",
+		PersistentPreRunE: setupLogging,
+		Run:               runRootCmd,
 	}
 
 	installCmd = &cobra.Command{
@@ -82,6 +145,18 @@ var (
 
 	installDeveloper   bool
 	installProjectOnly bool
+
+	snapshotCmd = &cobra.Command{
+		Use:   "snapshot",
+		Short: "Export a sanitized snapshot of the cluster state to a tarball or Cloud Storage for offline analysis.",
+		Args:  cobra.NoArgs,
+		Run:   runSnapshotCmd,
+	}
+
+	snapshotNamespaces    []string
+	snapshotAllNamespaces bool
+	snapshotResources     []string
+	snapshotOutput        string
 )
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -97,20 +172,65 @@ func init() {
 	if bi, ok := debug.ReadBuildInfo(); ok {
 		version = bi.Main.Version
 	} else {
-		log.Printf("Failed to read build info to get version.")
+		slog.Warn("Failed to read build info to get version.")
 	}
 
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "minimum level of log messages: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "format of log messages: text or json")
 	rootCmd.Flags().StringVar(&serverMode, "server-mode", "stdio", "transport to use for the server: stdio (default) or http")
 	rootCmd.Flags().IntVar(&serverPort, "server-port", 8080, "server port to use when server-mode is http; defaults to 8080")
-	rootCmd.Flags().BoolVar(&readOnly, "read-only", false, "run in read-only mode")
+	rootCmd.Flags().StringVar(&listenAddress, "listen-address", "127.0.0.1", "address to listen on when server-mode is http; use 0.0.0.0 to listen on all interfaces")
+	rootCmd.Flags().StringVar(&authTokenFile, "auth-token-file", "", "file with bearer tokens or API keys, one per line, accepted when server-mode is http")
+	rootCmd.Flags().StringVar(&oidcIssuerURL, "oidc-issuer-url", "", "issuer of OIDC ID tokens accepted when server-mode is http")
+	rootCmd.Flags().StringVar(&oidcAudience, "oidc-audience", "", "audience that OIDC ID tokens must be issued for")
+	rootCmd.Flags().BoolVar(&credentialPassthrough, "credential-passthrough", false, "in http mode, run tool calls with the Kubernetes and Google credentials supplied by the client in request headers")
+	rootCmd.Flags().BoolVar(&readOnly, "read-only", false, "run in read-only mode: tools that create, modify or delete resources are not installed")
+	rootCmd.Flags().BoolVar(&namespacedWritesOnly, "namespaced-writes-only", false, "only allow changes of namespaced resources: cluster-scoped resources, such as namespaces, nodes or cluster roles, and GKE clusters can't be changed")
+	rootCmd.Flags().StringSliceVar(&protectedNamespaces, "protected-namespaces", config.DefaultProtectedNamespaces, "namespaces in which resources can't be changed")
+	rootCmd.Flags().BoolVar(&unsafeAllowSystemNamespaces, "unsafe-allow-system-namespaces", false, "allow changes of the resources of the protected namespaces, such as kube-system")
+	rootCmd.Flags().BoolVar(&requireApproval, "require-approval", false, "queue the calls of the tools that change resources as pending actions, run by the approve_action tool once the user approves them when prompted by the client, or an approver at the /approvals endpoint")
+	rootCmd.Flags().StringVar(&approvalWebhook, "approval-webhook", "", "Slack or Google Chat incoming webhook URL notified of the actions pending approval; requires --require-approval")
+	rootCmd.Flags().StringVar(&approvalTokenFile, "approval-token-file", "", "file with bearer tokens of approvers, one per line, accepted by the /approvals endpoint, where pending actions are approved or rejected out of band of the MCP clients, when server-mode is http; requires --require-approval")
+	rootCmd.Flags().StringVar(&policyPath, "policy", "", "YAML file of CEL rules, and optionally an Open Policy Agent server, allowing, denying or requiring the confirmation of tool calls")
+	rootCmd.Flags().StringVar(&outputDir, "output-dir", "", "only local directory in which tools write files, such as snapshots and incident reports; defaults to kubeapi-mcp in the temporary directory")
+	rootCmd.Flags().StringSliceVar(&outputBuckets, "output-buckets", nil, "Cloud Storage buckets to which tools may upload files; uploads are disabled by default")
 	rootCmd.Flags().StringVar(&udtPath, "udt", "", "Path to the UDT playbook directory")
+	rootCmd.Flags().Float32Var(&kubeQPS, "kube-qps", 50, "maximum queries per second to the Kubernetes API server")
+	rootCmd.Flags().IntVar(&kubeBurst, "kube-burst", 100, "maximum burst of queries to the Kubernetes API server")
+	rootCmd.Flags().DurationVar(&requestTimeout, "request-timeout", 30*time.Second, "maximum duration of a single tool call; 0 disables the timeout")
+	rootCmd.Flags().StringToStringVar(&toolTimeouts, "tool-timeout", nil, "maximum duration of calls of specific tools, overriding --request-timeout, e.g. kube_get_pod_logs=2m,kube_debug_node=5m; 0 disables the timeout of a tool")
+	rootCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 30*time.Second, "how long to cache results of expensive read calls such as API discovery; 0 disables caching")
+	rootCmd.Flags().StringVar(&defaultNamespace, "default-namespace", "", "namespace used by tools when none is given; defaults to the namespace of the current kubeconfig context")
+	rootCmd.Flags().StringVar(&fieldManager, "field-manager", config.DefaultFieldManager, "field manager name used when applying resources with server-side apply")
+	rootCmd.Flags().StringVar(&logQueriesPath, "log-queries", "", "YAML file, or directory of YAML files, of saved log queries run by the gke_run_saved_query tool")
+	rootCmd.Flags().StringVar(&notificationsSubscription, "notifications-subscription", "", "Pub/Sub subscription, as projects/PROJECT/subscriptions/NAME, receiving GKE cluster notifications reported by the gke_recent_notifications tool")
+	rootCmd.Flags().DurationVar(&healthCheckInterval, "health-check-interval", 0, "how often to run the background health checks, whose new findings are sent to clients as log messages; 0 disables them")
+	rootCmd.Flags().StringSliceVar(&healthChecks, "health-checks", config.HealthCheckNames, "background health checks to run: "+strings.Join(config.HealthCheckNames, ", "))
+	rootCmd.Flags().StringVar(&googleCredentialsFile, "google-credentials-file", "", "service account key file, or Workload Identity Federation credential configuration file, used by the Google Cloud clients instead of Application Default Credentials")
+	rootCmd.Flags().StringVar(&impersonateServiceAccount, "impersonate-service-account", "", "service account impersonated by the Google Cloud clients, or a comma-separated delegation chain ending with it")
+	rootCmd.Flags().StringVar(&profilesPath, "profiles", "", "YAML file of named profiles bundling a kubeconfig context, a Google Cloud project and location, and tool settings, switched with the use_profile tool")
+	rootCmd.Flags().StringVar(&profile, "profile", "", "profile used when the server starts; defaults to the first profile of --profiles")
+	rootCmd.Flags().BoolVar(&allowProfileEscalation, "allow-profile-escalation", false, "allow use_profile to switch from a read-only profile, or one with namespaced writes only, to a profile allowing more changes")
+	rootCmd.Flags().StringVar(&secretRedaction, "secret-redaction", config.SecretRedactionMask, "policy for the values of Secrets, and of environment variables with sensitive names, in tool results: mask replaces them with a placeholder, none returns them as is")
+	rootCmd.Flags().BoolVar(&allowNodeDebug, "allow-node-debug", false, "enable the kube_debug_node tool, which runs privileged pods on nodes; ignored in read-only mode")
+	rootCmd.Flags().BoolVar(&logTransport, "log-transport", true, "log the MCP messages exchanged over the stdio transport at debug level")
+	rootCmd.Flags().StringVar(&otlpEndpoint, "otlp-endpoint", "", "host:port of an OTLP/HTTP collector to export traces and metrics to")
+	rootCmd.Flags().BoolVar(&metrics, "metrics", false, "serve Prometheus metrics at /metrics when server-mode is http")
+	rootCmd.Flags().BoolVar(&receiveAlerts, "receive-alerts", false, "accept Alertmanager and Cloud Monitoring webhook alerts at /alerts when server-mode is http, reported by the alerts_list tool")
 	rootCmd.AddCommand(installCmd)
+	rootCmd.AddCommand(snapshotCmd)
 
 	installCmd.AddCommand(installGeminiCLICmd)
 	installCmd.AddCommand(installCursorCmd)
 	installCmd.AddCommand(installClaudeDesktopCmd)
 	installCmd.AddCommand(installClaudeCodeCmd)
 
+	snapshotCmd.Flags().StringSliceVarP(&snapshotNamespaces, "namespace", "n", nil, "namespaces to export; defaults to the namespace of the current kubeconfig context")
+	snapshotCmd.Flags().BoolVarP(&snapshotAllNamespaces, "all-namespaces", "A", false, "export all namespaces")
+	snapshotCmd.Flags().StringSliceVar(&snapshotResources, "resources", nil, "types of resources to export, e.g. deployments,services; defaults to the common workload, networking, configuration and storage resources")
+	snapshotCmd.Flags().StringVarP(&snapshotOutput, "output", "o", "", "local path, or gs://BUCKET/OBJECT, of the snapshot tarball; defaults to a file in the temporary directory")
+	snapshotCmd.Flags().StringVar(&googleCredentialsFile, "google-credentials-file", "", "service account key file used to upload to Cloud Storage instead of Application Default Credentials")
+
 	installGeminiCLICmd.Flags().BoolVarP(&installDeveloper, "developer", "d", false, "Install the MCP Server in developer mode for Gemini CLI")
 	installGeminiCLICmd.Flags().BoolVarP(&installProjectOnly, "project-only", "p", false, "Install the MCP Server only for the current project. Please run this in the root directory of your project")
 
@@ -118,27 +238,303 @@ func init() {
 	installClaudeCodeCmd.Flags().BoolVarP(&installProjectOnly, "project-only", "p", false, "Install the MCP Server only for the current project. Please run this in the root directory of your project")
 }
 
+// setupLogging installs the logger configured by the logging flags as the
+// default logger. Logs are written to stderr so they never interfere with the
+// stdio transport.
+func setupLogging(cmd *cobra.Command, args []string) error {
+	logger, err := logging.NewLogger(os.Stderr, logLevel, logFormat)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// fatal logs msg with err and exits the process.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
 type startOptions struct {
-	serverMode string
-	serverPort int
-	readOnly   bool
-	udtPath    string
+	serverMode    string
+	serverPort    int
+	listenAddress string
+	auth          httpauth.Options
+	// credentialPassthrough enables per-session credentials in HTTP mode.
+	credentialPassthrough bool
+	readOnly              bool
+	// namespacedWritesOnly blocks the changes of cluster-scoped resources.
+	namespacedWritesOnly bool
+	// protectedNamespaces can't be changed, unless allowSystemNamespaces
+	// is set.
+	protectedNamespaces   []string
+	allowSystemNamespaces bool
+	// requireApproval queues changes until approved, notifying
+	// approvalWebhook if set. approvalTokens, if any, enable the approvals
+	// endpoint.
+	requireApproval bool
+	approvalWebhook string
+	approvalTokens  []string
+	// policy decides whether tool calls are allowed, if set.
+	policy           *policy.Policy
+	udtPath          string
+	kubeQPS          float32
+	kubeBurst        int
+	requestTimeout   time.Duration
+	cacheTTL         time.Duration
+	fieldManager     string
+	defaultNamespace string
+	allowNodeDebug   bool
+	logQueriesPath   string
+	secretRedaction  string
+	otlpEndpoint     string
+	metrics          bool
+	receiveAlerts    bool
+	logTransport     bool
+
+	// notificationsSubscription receives GKE cluster notifications.
+	notificationsSubscription string
+	// healthCheckInterval is how often the healthChecks run in the
+	// background; zero disables them.
+	healthCheckInterval time.Duration
+	healthChecks        []string
+	// googleCredentialsFile and impersonateServiceAccount select the
+	// identity of the Google Cloud clients.
+	googleCredentialsFile     string
+	impersonateServiceAccount string
+	// profiles are the environments the server can switch between, starting
+	// with startProfile.
+	profiles     []config.Profile
+	startProfile string
+	// allowProfileEscalation allows use_profile to switch to profiles
+	// allowing more changes.
+	allowProfileEscalation bool
+	// toolTimeouts override requestTimeout for specific tools.
+	toolTimeouts map[string]time.Duration
+	// outputDir and outputBuckets are where tools may write files.
+	outputDir     string
+	outputBuckets []string
 }
 
 func runRootCmd(cmd *cobra.Command, args []string) {
+	authOpts, err := authOptions()
+	if err != nil {
+		fatal("Failed to configure HTTP authentication", err)
+	}
+	timeouts, err := parseToolTimeouts(toolTimeouts)
+	if err != nil {
+		fatal("Invalid tool timeouts", err)
+	}
+	if secretRedaction != config.SecretRedactionMask && secretRedaction != config.SecretRedactionNone {
+		fatal("Invalid secret redaction policy", fmt.Errorf("--secret-redaction must be %q or %q, got %q", config.SecretRedactionMask, config.SecretRedactionNone, secretRedaction))
+	}
+	if approvalWebhook != "" && !requireApproval {
+		fatal("Invalid approval settings", errors.New("--approval-webhook requires --require-approval"))
+	}
+	var approvalTokens []string
+	if approvalTokenFile != "" {
+		if !requireApproval {
+			fatal("Invalid approval settings", errors.New("--approval-token-file requires --require-approval"))
+		}
+		b, err := os.ReadFile(approvalTokenFile)
+		if err != nil {
+			fatal("Invalid approval settings", fmt.Errorf("failed to read approval token file: %w", err))
+		}
+		approvalTokens = strings.Split(string(b), "\n")
+	}
+	for _, check := range healthChecks {
+		if !slices.Contains(config.HealthCheckNames, check) {
+			fatal("Invalid health checks", fmt.Errorf("unknown health check %q, must be one of %s", check, strings.Join(config.HealthCheckNames, ", ")))
+		}
+	}
+	var toolPolicy *policy.Policy
+	if policyPath != "" {
+		toolPolicy, err = policy.Load(policyPath)
+		if err != nil {
+			fatal("Failed to load the tool call policy", err)
+		}
+	}
+	var profiles []config.Profile
+	if profilesPath != "" {
+		profiles, err = config.LoadProfiles(profilesPath)
+		if err != nil {
+			fatal("Failed to load profiles", err)
+		}
+	}
 	opts := startOptions{
-		serverMode: serverMode,
-		serverPort: serverPort,
-		readOnly:   readOnly,
-		udtPath:    udtPath,
+		serverMode:            serverMode,
+		serverPort:            serverPort,
+		listenAddress:         listenAddress,
+		auth:                  authOpts,
+		credentialPassthrough: credentialPassthrough,
+		readOnly:              readOnly,
+		namespacedWritesOnly:  namespacedWritesOnly,
+		protectedNamespaces:   protectedNamespaces,
+		allowSystemNamespaces: unsafeAllowSystemNamespaces,
+		requireApproval:       requireApproval,
+		approvalWebhook:       approvalWebhook,
+		approvalTokens:        approvalTokens,
+		policy:                toolPolicy,
+		udtPath:               udtPath,
+		kubeQPS:               kubeQPS,
+		kubeBurst:             kubeBurst,
+		requestTimeout:        requestTimeout,
+		cacheTTL:              cacheTTL,
+		fieldManager:          fieldManager,
+		defaultNamespace:      defaultNamespace,
+		allowNodeDebug:        allowNodeDebug,
+		logQueriesPath:        logQueriesPath,
+		secretRedaction:       secretRedaction,
+		otlpEndpoint:          otlpEndpoint,
+		metrics:               metrics,
+		receiveAlerts:         receiveAlerts,
+		logTransport:          logTransport,
+
+		notificationsSubscription: notificationsSubscription,
+		healthCheckInterval:       healthCheckInterval,
+		healthChecks:              healthChecks,
+		googleCredentialsFile:     googleCredentialsFile,
+		impersonateServiceAccount: impersonateServiceAccount,
+		profiles:                  profiles,
+		startProfile:              profile,
+		allowProfileEscalation:    allowProfileEscalation,
+		toolTimeouts:              timeouts,
+		outputDir:                 outputDir,
+		outputBuckets:             outputBuckets,
 	}
 	startMCPServer(cmd.Context(), opts)
 }
 
+// parseToolTimeouts parses the durations of the --tool-timeout flag.
+func parseToolTimeouts(flag map[string]string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	for name, value := range flag {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout of tool %s: %w", name, err)
+		}
+		timeouts[name] = timeout
+	}
+	return timeouts, nil
+}
+
+// authTokenEnv is the environment variable that can hold a token accepted in
+// HTTP mode, as an alternative to --auth-token-file.
+const authTokenEnv = "KUBEAPI_MCP_AUTH_TOKEN"
+
+func authOptions() (httpauth.Options, error) {
+	opts := httpauth.Options{
+		OIDCIssuerURL: oidcIssuerURL,
+		OIDCAudience:  oidcAudience,
+	}
+	if token := os.Getenv(authTokenEnv); token != "" {
+		opts.Tokens = append(opts.Tokens, token)
+	}
+	if authTokenFile != "" {
+		b, err := os.ReadFile(authTokenFile)
+		if err != nil {
+			return opts, fmt.Errorf("failed to read auth token file: %w", err)
+		}
+		opts.Tokens = append(opts.Tokens, strings.Split(string(b), "\n")...)
+	}
+	return opts, nil
+}
+
 func startMCPServer(ctx context.Context, opts startOptions) {
-	c := config.New(version, opts.readOnly, opts.udtPath)
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	instructions := ""
+	var alertStore *alerts.Store
+	if opts.receiveAlerts && opts.serverMode == "http" {
+		alertStore = alerts.NewStore()
+	}
+	var approvalStore *approvals.Store
+	if opts.requireApproval {
+		approvalStore = approvals.NewStore(opts.approvalWebhook)
+	}
+	c := config.New(version, config.Options{
+		ReadOnly:         opts.readOnly,
+		UDTPath:          opts.udtPath,
+		KubeQPS:          opts.kubeQPS,
+		KubeBurst:        opts.kubeBurst,
+		RequestTimeout:   opts.requestTimeout,
+		CacheTTL:         opts.cacheTTL,
+		FieldManager:     opts.fieldManager,
+		DefaultNamespace: opts.defaultNamespace,
+		AllowNodeDebug:   opts.allowNodeDebug,
+		LogQueriesPath:   opts.logQueriesPath,
+		SecretRedaction:  opts.secretRedaction,
+
+		NotificationsSubscription: opts.notificationsSubscription,
+		HealthCheckInterval:       opts.healthCheckInterval,
+		HealthChecks:              opts.healthChecks,
+		Alerts:                    alertStore,
+		NamespacedWritesOnly:      opts.namespacedWritesOnly,
+		ProtectedNamespaces:       opts.protectedNamespaces,
+
+		UnsafeAllowSystemNamespaces: opts.allowSystemNamespaces,
+
+		Approvals:                 approvalStore,
+		Policy:                    opts.policy,
+		GoogleCredentialsFile:     opts.googleCredentialsFile,
+		ImpersonateServiceAccount: opts.impersonateServiceAccount,
+		Profiles:                  opts.profiles,
+		StartProfile:              opts.startProfile,
+		AllowProfileEscalation:    opts.allowProfileEscalation,
+		ToolTimeouts:              opts.toolTimeouts,
+		OutputDir:                 opts.outputDir,
+		OutputBuckets:             opts.outputBuckets,
+	})
+
+	tel, err := telemetry.Setup(ctx, version, telemetry.Options{
+		OTLPEndpoint: opts.otlpEndpoint,
+		Prometheus:   opts.metrics && opts.serverMode == "http",
+	})
+	if err != nil {
+		fatal("Failed to set up telemetry", err)
+	}
+	defer func() {
+		if err := tel.Shutdown(context.Background()); err != nil {
+			slog.Warn("Failed to shut down telemetry", "error", err)
+		}
+	}()
+	telemetryMiddleware, err := telemetry.ToolCallMiddleware()
+	if err != nil {
+		fatal("Failed to set up telemetry", err)
+	}
+	middleware := []mcp.Middleware{logging.ToolCallMiddleware(slog.Default()), telemetryMiddleware}
+
+	s, err := newMCPServer(ctx, c, middleware)
+	if err != nil {
+		fatal("Failed to create MCP server", err)
+	}
+
+	// start server in the right mode
+	slog.Info("Starting KubeAPI MCP Server", "version", version, "mode", opts.serverMode, "readOnly", c.ReadOnly())
+
+	switch opts.serverMode {
+	case "stdio":
+		err = s.Run(ctx, stdioTransport(opts.logTransport))
+	case "http":
+		err = runHTTPServer(ctx, c, s, middleware, tel, opts)
+	default:
+		slog.Warn("Unknown mode, defaulting to 'stdio'", "mode", opts.serverMode)
+		err = s.Run(ctx, stdioTransport(opts.logTransport))
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			slog.Info("Server shutting down.")
+		} else {
+			slog.Error("Server error", "error", err)
+		}
+	}
+}
+
+// newMCPServer creates an MCP server exposing the resources, prompts and
+// tools of kubeapi-mcp, using the identity and settings of c.
+func newMCPServer(ctx context.Context, c *config.Config, middleware []mcp.Middleware) (*mcp.Server, error) {
+	instructions := serverInstructions(c)
 
 	s := mcp.NewServer(
 		&mcp.Implementation{
@@ -165,47 +561,37 @@ func startMCPServer(ctx context.Context, opts startOptions) {
 				&mcp.ResourceContents{
 					URI:      geminiInstructionsURI,
 					MIMEType: "text/markdown",
-					Text:     string(install.GeminiMarkdown),
+					Text:     modeInstructions(c) + "\n\n" + string(install.GeminiMarkdown),
 				},
 			},
 		}, nil
 	})
 
 	if err := prompts.Install(ctx, s, c); err != nil {
-		log.Fatalf("Failed to install prompts: %v\n", err)
+		return nil, fmt.Errorf("failed to install prompts: %w", err)
 	}
 
 	if err := tools.Install(ctx, s, c); err != nil {
-		log.Fatalf("Failed to install tools: %v\n", err)
+		return nil, fmt.Errorf("failed to install tools: %w", err)
 	}
 
-	// start server in the right mode
-	log.Printf("Starting KubeAPI MCP Server (%s) in mode '%s'", version, opts.serverMode)
-	var err error
-	endpoint := fmt.Sprintf(":%d", opts.serverPort)
+	s.AddReceivingMiddleware(middleware...)
+	return s, nil
+}
 
-	switch opts.serverMode {
-	case "stdio":
-		tr := &mcp.LoggingTransport{Transport: &mcp.StdioTransport{}, Writer: log.Writer()}
-		err = s.Run(ctx, tr)
-	case "http":
-		handler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
-			return s
-		}, nil)
-		log.Printf("Listening for HTTP connections on port: %d", opts.serverPort)
-		err = http.ListenAndServe(endpoint, handler)
-	default:
-		log.Printf("Unknown mode '%s', defaulting to 'stdio'", opts.serverMode)
-		tr := &mcp.LoggingTransport{Transport: &mcp.StdioTransport{}, Writer: log.Writer()}
-		err = s.Run(ctx, tr)
-	}
-	if err != nil {
-		if errors.Is(err, context.Canceled) {
-			log.Printf("Server shutting down.")
-		} else {
-			log.Printf("Server error: %v\n", err)
-		}
+// stdioTransport returns the stdio transport, logging the exchanged MCP
+// messages unless logTransport is false.
+func stdioTransport(logTransport bool) mcp.Transport {
+	if !logTransport {
+		return &mcp.StdioTransport{}
 	}
+	return &mcp.LoggingTransport{Transport: &mcp.StdioTransport{}, Writer: transportLogWriter()}
+}
+
+// transportLogWriter returns a writer that logs the MCP messages exchanged
+// over the stdio transport at debug level, with secrets redacted.
+func transportLogWriter() io.Writer {
+	return logging.NewRedactingWriter(slog.NewLogLogger(slog.Default().Handler(), slog.LevelDebug).Writer())
 }
 
 func installOptions() (*install.InstallOptions, error) {
@@ -219,11 +605,11 @@ func installOptions() (*install.InstallOptions, error) {
 func runInstallGeminiCLICmd(cmd *cobra.Command, args []string) {
 	opts, err := installOptions()
 	if err != nil {
-		log.Fatalf("Failed to get install options: %v", err)
+		fatal("Failed to get install options", err)
 	}
 
 	if err := install.GeminiCLIExtension(opts); err != nil {
-		log.Fatalf("Failed to install for gemini-cli: %v", err)
+		fatal("Failed to install for gemini-cli", err)
 	}
 	fmt.Println("Successfully installed KubeAPI MCP server as a gemini-cli extension.")
 }
@@ -231,11 +617,11 @@ func runInstallGeminiCLICmd(cmd *cobra.Command, args []string) {
 func runInstallCursorCmd(cmd *cobra.Command, args []string) {
 	opts, err := installOptions()
 	if err != nil {
-		log.Fatalf("Failed to get install options: %v", err)
+		fatal("Failed to get install options", err)
 	}
 
 	if err := install.CursorMCPExtension(opts); err != nil {
-		log.Fatalf("Failed to install for cursor: %v", err)
+		fatal("Failed to install for cursor", err)
 	}
 	fmt.Println("Successfully installed KubeAPI MCP server as a cursor MCP server.")
 }
@@ -243,11 +629,11 @@ func runInstallCursorCmd(cmd *cobra.Command, args []string) {
 func runInstallClaudeDesktopCmd(cmd *cobra.Command, args []string) {
 	opts, err := installOptions()
 	if err != nil {
-		log.Fatalf("Failed to get install options: %v", err)
+		fatal("Failed to get install options", err)
 	}
 
 	if err := install.ClaudeDesktopExtension(opts); err != nil {
-		log.Fatalf("Failed to install for Claude Desktop: %v", err)
+		fatal("Failed to install for Claude Desktop", err)
 	}
 	fmt.Println("Successfully installed KubeAPI MCP server in Claude Desktop configuration.")
 }
@@ -255,12 +641,29 @@ func runInstallClaudeDesktopCmd(cmd *cobra.Command, args []string) {
 func runInstallClaudeCodeCmd(cmd *cobra.Command, args []string) {
 	opts, err := installOptions()
 	if err != nil {
-		log.Fatalf("Failed to get install options: %v", err)
+		fatal("Failed to get install options", err)
 	}
 
 	if err := install.ClaudeCodeExtension(opts); err != nil {
-		log.Fatalf("Failed to install for Claude Code: %v", err)
+		fatal("Failed to install for Claude Code", err)
 	}
 
 	fmt.Println("Successfully installed KubeAPI MCP server for Claude Code.")
 }
+
+func runSnapshotCmd(cmd *cobra.Command, args []string) {
+	c := config.New(version, config.Options{
+		ReadOnly:              true,
+		GoogleCredentialsFile: googleCredentialsFile,
+	})
+	summary, err := kubernetes.Snapshot(cmd.Context(), c, kubernetes.SnapshotOptions{
+		Namespaces:    snapshotNamespaces,
+		AllNamespaces: snapshotAllNamespaces,
+		Resources:     snapshotResources,
+		Destination:   snapshotOutput,
+	})
+	if err != nil {
+		fatal("Failed to take snapshot", err)
+	}
+	fmt.Print(summary)
+}
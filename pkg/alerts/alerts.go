@@ -0,0 +1,296 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package alerts receives the alerts posted by Alertmanager and Cloud
+// Monitoring webhooks, and keeps the most recent ones so that the agent can
+// start troubleshooting from the alert that fired.
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The sources of alerts.
+const (
+	SourceAlertmanager    = "alertmanager"
+	SourceCloudMonitoring = "cloud-monitoring"
+)
+
+// The statuses of alerts.
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+const (
+	// maxAlerts is the number of most recent alerts kept in memory.
+	maxAlerts = 500
+	// maxPayloadBytes bounds the size of webhook payloads.
+	maxPayloadBytes = 1 << 20
+)
+
+// Alert is an alert received from a webhook.
+type Alert struct {
+	// Source is SourceAlertmanager or SourceCloudMonitoring.
+	Source string
+	// Fingerprint identifies the alert across notifications, e.g. when it
+	// is resolved.
+	Fingerprint string
+	Name        string
+	// Status is StatusFiring or StatusResolved.
+	Status      string
+	Severity    string
+	Summary     string
+	Labels      map[string]string
+	Annotations map[string]string
+	StartsAt    time.Time
+	// EndsAt is zero for firing alerts.
+	EndsAt time.Time
+	// URL links to the alert in its source, e.g. the incident in the Cloud
+	// console.
+	URL        string
+	ReceivedAt time.Time
+}
+
+// Store keeps the most recent alerts, and notifies subscribers of each
+// received alert. It serves the webhook endpoint.
+type Store struct {
+	mu sync.Mutex
+	// alerts are the alerts in the order they were last received.
+	alerts      []Alert
+	subscribers map[int]func(Alert)
+	nextID      int
+}
+
+func NewStore() *Store {
+	return &Store{subscribers: map[int]func(Alert){}}
+}
+
+// Add records alerts, replacing the previous notifications of the same
+// alerts, and notifies the subscribers.
+func (s *Store) Add(alerts ...Alert) {
+	s.mu.Lock()
+	for _, a := range alerts {
+		for i := range s.alerts {
+			if s.alerts[i].Source == a.Source && s.alerts[i].Fingerprint == a.Fingerprint {
+				s.alerts = append(s.alerts[:i], s.alerts[i+1:]...)
+				break
+			}
+		}
+		s.alerts = append(s.alerts, a)
+	}
+	if len(s.alerts) > maxAlerts {
+		s.alerts = s.alerts[len(s.alerts)-maxAlerts:]
+	}
+	var subscribers []func(Alert)
+	for _, f := range s.subscribers {
+		subscribers = append(subscribers, f)
+	}
+	s.mu.Unlock()
+
+	for _, f := range subscribers {
+		for _, a := range alerts {
+			f(a)
+		}
+	}
+}
+
+// List returns the alerts, most recently received first.
+func (s *Store) List() []Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Alert, 0, len(s.alerts))
+	for i := len(s.alerts) - 1; i >= 0; i-- {
+		list = append(list, s.alerts[i])
+	}
+	return list
+}
+
+// Subscribe calls f with each received alert until the returned function
+// is called.
+func (s *Store) Subscribe(f func(Alert)) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.nextID
+	s.nextID++
+	s.subscribers[id] = f
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscribers, id)
+	}
+}
+
+// ServeHTTP receives the alerts of an Alertmanager or Cloud Monitoring
+// webhook notification.
+func (s *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var payload webhookPayload
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPayloadBytes)).Decode(&payload); err != nil {
+		http.Error(w, fmt.Sprintf("invalid payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	alerts, err := payload.alerts(time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	slog.Info("Received alerts", "count", len(alerts), "remote_addr", r.RemoteAddr)
+	s.Add(alerts...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// webhookPayload is the payload of an Alertmanager or of a Cloud Monitoring
+// webhook notification.
+type webhookPayload struct {
+	// Alerts are the alerts of Alertmanager notifications.
+	Alerts []alertmanagerAlert `json:"alerts"`
+	// Incident is the incident of Cloud Monitoring notifications.
+	Incident *monitoringIncident `json:"incident"`
+}
+
+type alertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+type monitoringIncident struct {
+	IncidentID       string            `json:"incident_id"`
+	URL              string            `json:"url"`
+	State            string            `json:"state"`
+	StartedAt        int64             `json:"started_at"`
+	EndedAt          *int64            `json:"ended_at"`
+	Summary          string            `json:"summary"`
+	PolicyName       string            `json:"policy_name"`
+	ConditionName    string            `json:"condition_name"`
+	Severity         string            `json:"severity"`
+	PolicyUserLabels map[string]string `json:"policy_user_labels"`
+	Resource         struct {
+		Type   string            `json:"type"`
+		Labels map[string]string `json:"labels"`
+	} `json:"resource"`
+	Metric struct {
+		Type   string            `json:"type"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metric"`
+	Documentation struct {
+		Content string `json:"content"`
+	} `json:"documentation"`
+}
+
+// alerts returns the alerts of p, received at now.
+func (p *webhookPayload) alerts(now time.Time) ([]Alert, error) {
+	switch {
+	case len(p.Alerts) > 0:
+		var alerts []Alert
+		for _, a := range p.Alerts {
+			alerts = append(alerts, a.alert(now))
+		}
+		return alerts, nil
+	case p.Incident != nil:
+		return []Alert{p.Incident.alert(now)}, nil
+	}
+	return nil, errors.New("the payload is neither an Alertmanager nor a Cloud Monitoring notification")
+}
+
+func (a alertmanagerAlert) alert(now time.Time) Alert {
+	alert := Alert{
+		Source:      SourceAlertmanager,
+		Fingerprint: a.Fingerprint,
+		Name:        a.Labels["alertname"],
+		Status:      a.Status,
+		Severity:    a.Labels["severity"],
+		Labels:      a.Labels,
+		Annotations: a.Annotations,
+		StartsAt:    a.StartsAt,
+		URL:         a.GeneratorURL,
+		ReceivedAt:  now,
+	}
+	for _, key := range []string{"summary", "message", "description"} {
+		if summary := a.Annotations[key]; summary != "" {
+			alert.Summary = summary
+			break
+		}
+	}
+	if alert.Status == StatusResolved {
+		alert.EndsAt = a.EndsAt
+	}
+	if alert.Fingerprint == "" {
+		// Alertmanager versions before 0.19 don't send fingerprints.
+		alert.Fingerprint = fmt.Sprintf("%s/%v", alert.Name, a.Labels)
+	}
+	return alert
+}
+
+func (i *monitoringIncident) alert(now time.Time) Alert {
+	labels := map[string]string{}
+	for _, m := range []map[string]string{i.Resource.Labels, i.Metric.Labels, i.PolicyUserLabels} {
+		for k, v := range m {
+			labels[k] = v
+		}
+	}
+	if i.Resource.Type != "" {
+		labels["resource_type"] = i.Resource.Type
+	}
+	if i.Metric.Type != "" {
+		labels["metric_type"] = i.Metric.Type
+	}
+	alert := Alert{
+		Source:      SourceCloudMonitoring,
+		Fingerprint: i.IncidentID,
+		Name:        i.PolicyName,
+		Status:      StatusFiring,
+		Severity:    i.Severity,
+		Summary:     i.Summary,
+		Labels:      labels,
+		Annotations: map[string]string{},
+		URL:         i.URL,
+		ReceivedAt:  now,
+	}
+	if i.ConditionName != "" {
+		alert.Annotations["condition"] = i.ConditionName
+	}
+	if i.Documentation.Content != "" {
+		alert.Annotations["documentation"] = i.Documentation.Content
+	}
+	if i.StartedAt > 0 {
+		alert.StartsAt = time.Unix(i.StartedAt, 0).UTC()
+	}
+	if i.State == "closed" {
+		alert.Status = StatusResolved
+		if i.EndedAt != nil {
+			alert.EndsAt = time.Unix(*i.EndedAt, 0).UTC()
+		}
+	}
+	if alert.Fingerprint == "" {
+		alert.Fingerprint = i.PolicyName + "/" + strconv.FormatInt(i.StartedAt, 10)
+	}
+	return alert
+}
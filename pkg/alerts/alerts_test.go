@@ -0,0 +1,141 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerts
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+const alertmanagerPayload = `{
+  "version": "4",
+  "status": "firing",
+  "receiver": "kubeapi-mcp",
+  "alerts": [
+    {
+      "status": "firing",
+      "labels": {"alertname": "KubePodCrashLooping", "namespace": "shop", "pod": "web-1", "severity": "critical"},
+      "annotations": {"summary": "Pod shop/web-1 is crash looping.", "runbook_url": "https://runbooks.example.com/KubePodCrashLooping"},
+      "startsAt": "2025-03-01T10:05:00Z",
+      "endsAt": "0001-01-01T00:00:00Z",
+      "generatorURL": "http://prometheus.example.com/graph",
+      "fingerprint": "c6b1"
+    }
+  ]
+}`
+
+const monitoringPayload = `{
+  "version": "1.2",
+  "incident": {
+    "incident_id": "0.abc",
+    "scoping_project_id": "my-project",
+    "url": "https://console.cloud.google.com/monitoring/alerting/incidents/0.abc",
+    "state": "closed",
+    "started_at": 1740823500,
+    "ended_at": 1740827100,
+    "summary": "CPU utilization for prod is above the threshold.",
+    "policy_name": "High node CPU",
+    "condition_name": "CPU above 90%",
+    "severity": "Warning",
+    "resource": {"type": "k8s_node", "labels": {"cluster_name": "prod", "node_name": "node-1"}},
+    "metric": {"type": "kubernetes.io/node/cpu/allocatable_utilization", "labels": {}},
+    "policy_user_labels": {"team": "platform"}
+  }
+}`
+
+func post(t *testing.T, s *Store, method, body string) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, "/alerts", strings.NewReader(body)))
+	return rec.Code
+}
+
+func TestServeHTTP(t *testing.T) {
+	s := NewStore()
+	var received []string
+	unsubscribe := s.Subscribe(func(a Alert) { received = append(received, a.Name) })
+
+	if code := post(t, s, http.MethodPost, alertmanagerPayload); code != http.StatusNoContent {
+		t.Fatalf("POST Alertmanager payload = %d, want %d", code, http.StatusNoContent)
+	}
+	if code := post(t, s, http.MethodPost, monitoringPayload); code != http.StatusNoContent {
+		t.Fatalf("POST Cloud Monitoring payload = %d, want %d", code, http.StatusNoContent)
+	}
+	unsubscribe()
+	if code := post(t, s, http.MethodPost, alertmanagerPayload); code != http.StatusNoContent {
+		t.Fatalf("POST Alertmanager payload = %d, want %d", code, http.StatusNoContent)
+	}
+
+	want := []Alert{
+		{
+			Source:      SourceAlertmanager,
+			Fingerprint: "c6b1",
+			Name:        "KubePodCrashLooping",
+			Status:      StatusFiring,
+			Severity:    "critical",
+			Summary:     "Pod shop/web-1 is crash looping.",
+			Labels:      map[string]string{"alertname": "KubePodCrashLooping", "namespace": "shop", "pod": "web-1", "severity": "critical"},
+			Annotations: map[string]string{"summary": "Pod shop/web-1 is crash looping.", "runbook_url": "https://runbooks.example.com/KubePodCrashLooping"},
+			StartsAt:    time.Date(2025, 3, 1, 10, 5, 0, 0, time.UTC),
+			URL:         "http://prometheus.example.com/graph",
+		},
+		{
+			Source:      SourceCloudMonitoring,
+			Fingerprint: "0.abc",
+			Name:        "High node CPU",
+			Status:      StatusResolved,
+			Severity:    "Warning",
+			Summary:     "CPU utilization for prod is above the threshold.",
+			Labels: map[string]string{"cluster_name": "prod", "node_name": "node-1", "team": "platform",
+				"resource_type": "k8s_node", "metric_type": "kubernetes.io/node/cpu/allocatable_utilization"},
+			Annotations: map[string]string{"condition": "CPU above 90%"},
+			StartsAt:    time.Date(2025, 3, 1, 10, 5, 0, 0, time.UTC),
+			EndsAt:      time.Date(2025, 3, 1, 11, 5, 0, 0, time.UTC),
+			URL:         "https://console.cloud.google.com/monitoring/alerting/incidents/0.abc",
+		},
+	}
+	// The second notification of the Alertmanager alert replaces the first.
+	if diff := cmp.Diff(want, s.List(), cmpopts.IgnoreFields(Alert{}, "ReceivedAt")); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"KubePodCrashLooping", "High node CPU"}, received); diff != "" {
+		t.Errorf("subscriber mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServeHTTPRejectsInvalidRequests(t *testing.T) {
+	s := NewStore()
+	for _, tc := range []struct {
+		method, body string
+		want         int
+	}{
+		{method: http.MethodGet, want: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, body: "not json", want: http.StatusBadRequest},
+		{method: http.MethodPost, body: `{"status": "firing"}`, want: http.StatusBadRequest},
+	} {
+		if code := post(t, s, tc.method, tc.body); code != tc.want {
+			t.Errorf("%s %q = %d, want %d", tc.method, tc.body, code, tc.want)
+		}
+	}
+	if len(s.List()) != 0 {
+		t.Errorf("List() = %v, want no alerts", s.List())
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cache provides a small in-memory cache with a fixed TTL, used to
+// avoid re-issuing identical expensive read calls within a session.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry struct {
+	value   any
+	expires time.Time
+}
+
+// Cache is a concurrency-safe key/value store whose entries expire after a
+// fixed TTL. A nil Cache or a Cache with a zero TTL caches nothing.
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]entry
+}
+
+func New(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]entry),
+	}
+}
+
+func (c *Cache) enabled() bool {
+	return c != nil && c.ttl > 0
+}
+
+// Get returns the value stored under key if it has not expired.
+func (c *Cache) Get(key string) (any, bool) {
+	if !c.enabled() {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.now().After(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores value under key for the TTL of the cache.
+func (c *Cache) Set(key string, value any) {
+	if !c.enabled() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry{value: value, expires: c.now().Add(c.ttl)}
+}
+
+// Delete removes the value stored under key, if any.
+func (c *Cache) Delete(key string) {
+	if !c.enabled() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// GetOrLoad returns the cached value for key, calling load to populate the
+// cache if the value is missing, expired, or refresh is set. Errors returned
+// by load are not cached.
+func GetOrLoad[T any](c *Cache, key string, refresh bool, load func() (T, error)) (T, error) {
+	if !refresh {
+		if v, ok := c.Get(key); ok {
+			if t, ok := v.(T); ok {
+				return t, nil
+			}
+		}
+	}
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+	c.Set(key, v)
+	return v, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	now := time.Now()
+	c := New(time.Minute)
+	c.now = func() time.Time { return now }
+
+	calls := 0
+	load := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	for i, tc := range []struct {
+		advance time.Duration
+		refresh bool
+		want    int
+	}{
+		{want: 1},
+		{advance: 30 * time.Second, want: 1},
+		{refresh: true, want: 2},
+		{advance: 2 * time.Minute, want: 3},
+	} {
+		now = now.Add(tc.advance)
+		got, err := GetOrLoad(c, "key", tc.refresh, load)
+		if err != nil {
+			t.Fatalf("step %d: GetOrLoad() returned error: %v", i, err)
+		}
+		if got != tc.want {
+			t.Errorf("step %d: GetOrLoad() = %d, want %d", i, got, tc.want)
+		}
+	}
+}
+
+func TestGetOrLoadDoesNotCacheErrors(t *testing.T) {
+	c := New(time.Minute)
+	if _, err := GetOrLoad(c, "key", false, func() (string, error) { return "", errors.New("boom") }); err == nil {
+		t.Fatalf("GetOrLoad() expected error")
+	}
+	if _, ok := c.Get("key"); ok {
+		t.Errorf("Get() found a value cached from a failed load")
+	}
+}
+
+func TestDisabledCache(t *testing.T) {
+	for name, c := range map[string]*Cache{"nil": nil, "zero ttl": New(0)} {
+		c.Set("key", 1)
+		if _, ok := c.Get("key"); ok {
+			t.Errorf("%s: Get() returned a value from a disabled cache", name)
+		}
+	}
+}
@@ -17,17 +17,238 @@ package config
 import (
 	"bytes"
 	"context"
-	"log"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/alerts"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/policy"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/approvals"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+// Options holds the user-provided settings used to build a Config.
+type Options struct {
+	ReadOnly bool
+	UDTPath  string
+
+	// NamespacedWritesOnly restricts the changes to namespaced resources:
+	// cluster-scoped resources, such as namespaces, nodes or cluster roles,
+	// and the GKE control plane can't be changed.
+	NamespacedWritesOnly bool
+
+	// ProtectedNamespaces are the namespaces in which resources can't be
+	// changed. Nil means DefaultProtectedNamespaces.
+	ProtectedNamespaces []string
+	// UnsafeAllowSystemNamespaces lifts the protection of
+	// ProtectedNamespaces.
+	UnsafeAllowSystemNamespaces bool
+
+	// KubeQPS and KubeBurst tune the client-side rate limiter of the
+	// Kubernetes clients. Zero values keep the client-go defaults.
+	KubeQPS   float32
+	KubeBurst int
+
+	// RequestTimeout bounds the duration of a single tool call. Zero means
+	// no timeout.
+	RequestTimeout time.Duration
+
+	// CacheTTL is how long results of expensive read calls are cached. Zero
+	// disables caching.
+	CacheTTL time.Duration
+
+	// FieldManager is the field manager name used for server-side apply.
+	// Empty means DefaultFieldManager.
+	FieldManager string
+
+	// DefaultNamespace is the namespace used by tools when none is given.
+	// Empty means the namespace of the current kubeconfig context.
+	DefaultNamespace string
+
+	// AllowNodeDebug enables the tool running privileged debug pods on
+	// nodes. It has no effect in read-only mode.
+	AllowNodeDebug bool
+
+	// LogQueriesPath is a YAML file, or a directory of YAML files, of saved
+	// log queries. Empty means only the built-in queries.
+	LogQueriesPath string
+
+	// GoogleCredentialsFile is a service account key file, or a Workload
+	// Identity Federation credential configuration file, authenticating the
+	// Google Cloud clients. Empty means Application Default Credentials.
+	GoogleCredentialsFile string
+
+	// ImpersonateServiceAccount is the email of a service account that the
+	// Google Cloud clients impersonate, or a comma-separated delegation chain
+	// ending with it. Empty disables impersonation.
+	ImpersonateServiceAccount string
+
+	// Profiles are the environments the server can switch between.
+	Profiles []Profile
+	// StartProfile is the name of the profile used when the server starts.
+	// Empty means the first profile.
+	StartProfile string
+	// AllowProfileEscalation allows switching from a read-only profile, or
+	// one with namespaced writes only, to a profile allowing more changes.
+	AllowProfileEscalation bool
+
+	// ToolTimeouts bound the duration of calls of the named tools, overriding
+	// RequestTimeout. Zero means no timeout.
+	ToolTimeouts map[string]time.Duration
+
+	// NotificationsSubscription is the Pub/Sub subscription, of the form
+	// projects/PROJECT/subscriptions/NAME, receiving GKE cluster
+	// notifications. Empty disables notifications.
+	NotificationsSubscription string
+
+	// SecretRedaction is the policy applied to the values of Secrets, and of
+	// environment variables with sensitive names, in the results of the
+	// generic tools: SecretRedactionMask or SecretRedactionNone. Empty means
+	// SecretRedactionMask.
+	SecretRedaction string
+
+	// HealthCheckInterval is how often the background health checks run.
+	// Zero disables them.
+	HealthCheckInterval time.Duration
+	// HealthChecks are the names of the background health checks, among
+	// HealthCheckNames. Empty means all of them.
+	HealthChecks []string
+
+	// Alerts receives the alerts posted to the alerts endpoint in HTTP mode.
+	// Nil disables the alerts tools.
+	Alerts *alerts.Store
+
+	// Approvals queues the calls of the tools that change resources as
+	// pending actions, run once approved. It is shared by the servers of
+	// the sessions in HTTP mode, so that approvers can approve the actions of
+	// every session at the approvals endpoint. Nil runs changes immediately.
+	Approvals *approvals.Store
+
+	// Policy decides whether tool calls are allowed, denied, or require the
+	// confirmation of the user. Nil allows every call.
+	Policy *policy.Policy
+
+	// OutputDir is the only local directory tools write files to, such as
+	// snapshots. Empty means DefaultOutputDir.
+	OutputDir string
+	// OutputBuckets are the Cloud Storage buckets tools may upload files to.
+	// Empty disables uploads.
+	OutputBuckets []string
+}
+
+const (
+	// SecretRedactionMask replaces secret values with a placeholder.
+	SecretRedactionMask = "mask"
+	// SecretRedactionNone returns secret values as is.
+	SecretRedactionNone = "none"
+)
+
+// The background health checks.
+const (
+	// HealthCheckCluster reports unhealthy nodes, pods, deployments,
+	// persistent volume claims and webhooks.
+	HealthCheckCluster = "cluster_health"
+	// HealthCheckCertExpiry reports expired and expiring certificates.
+	HealthCheckCertExpiry = "cert_expiry"
+	// HealthCheckQuotaUsage reports the Compute Engine quotas of the default
+	// project and region close to their limit.
+	HealthCheckQuotaUsage = "quota_usage"
 )
 
+// HealthCheckNames are the names of the background health checks.
+var HealthCheckNames = []string{HealthCheckCluster, HealthCheckCertExpiry, HealthCheckQuotaUsage}
+
+// DefaultProtectedNamespaces are the namespaces of the system components of
+// Kubernetes and GKE, protected from changes unless configured otherwise.
+var DefaultProtectedNamespaces = []string{
+	"kube-system",
+	"kube-public",
+	"kube-node-lease",
+	"gke-system",
+	"gke-managed-system",
+	"gke-managed-cim",
+	"gke-gmp-system",
+	"gmp-system",
+	"gmp-public",
+}
+
+// DefaultOutputDir returns the directory tools write files to when none is
+// configured.
+func DefaultOutputDir() string {
+	return filepath.Join(os.TempDir(), "kubeapi-mcp")
+}
+
+// DefaultFieldManager is the field manager name used for server-side apply
+// when none is configured.
+const DefaultFieldManager = "kubeapi-mcp"
+
+// Credentials are caller-supplied credentials used instead of the server's
+// own identity.
+type Credentials struct {
+	// KubeBearerToken authenticates requests to the Kubernetes API server.
+	KubeBearerToken string
+	// GoogleAccessToken is an OAuth access token for Google Cloud APIs.
+	GoogleAccessToken string
+}
+
 type Config struct {
+	version          string
 	userAgent        string
 	defaultProjectID string
 	defaultLocation  string
 	readOnly         bool
 	udtPath          string
+	kubeQPS          float32
+	kubeBurst        int
+	requestTimeout   time.Duration
+	cacheTTL         time.Duration
+	fieldManager     string
+	defaultNamespace string
+	allowNodeDebug   bool
+	logQueriesPath   string
+	secretRedaction  string
+	credentials      Credentials
+
+	// namespacedWritesOnly blocks the changes of cluster-scoped resources
+	// and of the GKE control plane.
+	namespacedWritesOnly bool
+
+	// protectedNamespaces are the namespaces in which resources can't be
+	// changed, empty if their protection is lifted.
+	protectedNamespaces []string
+
+	notificationsSubscription string
+	googleCredentialsFile     string
+	impersonateServiceAccount string
+
+	profiles               []Profile
+	startProfile           string
+	allowProfileEscalation bool
+	// profile is the name of the profile of the configuration, if any.
+	profile     string
+	kubeContext string
+
+	toolTimeouts map[string]time.Duration
+
+	healthCheckInterval time.Duration
+	healthChecks        []string
+
+	alerts *alerts.Store
+
+	approvals *approvals.Store
+
+	policy *policy.Policy
+
+	outputDir     string
+	outputBuckets []string
 }
 
 func (c *Config) Exec(ctx context.Context, name string, arg ...string) (string, string, error) {
@@ -39,6 +260,11 @@ func (c *Config) Exec(ctx context.Context, name string, arg ...string) (string,
 	return stdout.String(), stderr.String(), err
 }
 
+// Version returns the version of the server.
+func (c *Config) Version() string {
+	return c.version
+}
+
 func (c *Config) UserAgent() string {
 	return c.userAgent
 }
@@ -55,29 +281,309 @@ func (c *Config) ReadOnly() bool {
 	return c.readOnly
 }
 
+// NamespacedWritesOnly reports whether only namespaced resources can be
+// changed.
+func (c *Config) NamespacedWritesOnly() bool {
+	return c.namespacedWritesOnly
+}
+
+// ProtectedNamespaces returns the namespaces in which resources can't be
+// changed.
+func (c *Config) ProtectedNamespaces() []string {
+	return c.protectedNamespaces
+}
+
+// ProtectedNamespace reports whether the resources of namespace can't be
+// changed.
+func (c *Config) ProtectedNamespace(namespace string) bool {
+	return slices.Contains(c.protectedNamespaces, namespace)
+}
+
 func (c *Config) UDTPath() string {
 	return c.udtPath
 }
 
-func New(version string, readOnly bool, udtPath string) *Config {
+func (c *Config) KubeQPS() float32 {
+	return c.kubeQPS
+}
+
+func (c *Config) KubeBurst() int {
+	return c.kubeBurst
+}
+
+func (c *Config) RequestTimeout() time.Duration {
+	return c.requestTimeout
+}
+
+// ToolTimeout returns the maximum duration of a call of the tool name: its own
+// timeout if set, or else RequestTimeout. Zero means no timeout.
+func (c *Config) ToolTimeout(name string) time.Duration {
+	if timeout, ok := c.toolTimeouts[name]; ok {
+		return timeout
+	}
+	return c.requestTimeout
+}
+
+// ToolTimeouts returns the timeouts of the tools that override RequestTimeout.
+func (c *Config) ToolTimeouts() map[string]time.Duration {
+	return c.toolTimeouts
+}
+
+func (c *Config) CacheTTL() time.Duration {
+	return c.cacheTTL
+}
+
+func (c *Config) FieldManager() string {
+	return c.fieldManager
+}
+
+func (c *Config) DefaultNamespace() string {
+	return c.defaultNamespace
+}
+
+func (c *Config) AllowNodeDebug() bool {
+	return c.allowNodeDebug
+}
+
+// RedactSecrets reports whether secret values are masked in the results of
+// the generic tools.
+func (c *Config) RedactSecrets() bool {
+	return c.secretRedaction != SecretRedactionNone
+}
+
+func (c *Config) LogQueriesPath() string {
+	return c.logQueriesPath
+}
+
+func (c *Config) NotificationsSubscription() string {
+	return c.notificationsSubscription
+}
+
+// HealthCheckInterval returns how often the background health checks run.
+// Zero means they are disabled.
+func (c *Config) HealthCheckInterval() time.Duration {
+	return c.healthCheckInterval
+}
+
+// HealthChecks returns the names of the background health checks.
+func (c *Config) HealthChecks() []string {
+	return c.healthChecks
+}
+
+// Alerts returns the store of the alerts received by the server, or nil if
+// it doesn't receive alerts.
+func (c *Config) Alerts() *alerts.Store {
+	return c.alerts
+}
+
+// RequireApproval reports whether the calls of the tools that change
+// resources wait for approval.
+func (c *Config) RequireApproval() bool {
+	return c.approvals != nil
+}
+
+// Approvals returns the store of the actions pending approval, or nil if
+// changes don't require approval.
+func (c *Config) Approvals() *approvals.Store {
+	return c.approvals
+}
+
+// Policy returns the policy applied to tool calls, or nil if every call is
+// allowed.
+func (c *Config) Policy() *policy.Policy {
+	return c.policy
+}
+
+func (c *Config) GoogleCredentialsFile() string {
+	return c.googleCredentialsFile
+}
+
+func (c *Config) ImpersonateServiceAccount() string {
+	return c.impersonateServiceAccount
+}
+
+func (c *Config) Profiles() []Profile {
+	return c.profiles
+}
+
+// AllowProfileEscalation reports whether the server can switch from a
+// profile to another allowing more changes.
+func (c *Config) AllowProfileEscalation() bool {
+	return c.allowProfileEscalation
+}
+
+func (c *Config) StartProfile() string {
+	if c.startProfile == "" && len(c.profiles) > 0 {
+		return c.profiles[0].Name
+	}
+	return c.startProfile
+}
+
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// KubeContext returns the kubeconfig context of the cluster. Empty means the
+// current context.
+func (c *Config) KubeContext() string {
+	return c.kubeContext
+}
+
+// OutputDir returns the only local directory tools write files to.
+func (c *Config) OutputDir() string {
+	return c.outputDir
+}
+
+// OutputBucketAllowed reports whether tools may upload files to the Cloud
+// Storage bucket.
+func (c *Config) OutputBucketAllowed(bucket string) bool {
+	return slices.Contains(c.outputBuckets, bucket)
+}
+
+func (c *Config) Credentials() Credentials {
+	return c.credentials
+}
+
+// WithCredentials returns a copy of c whose clients authenticate with creds.
+func (c *Config) WithCredentials(creds Credentials) *Config {
+	cc := *c
+	cc.credentials = creds
+	return &cc
+}
+
+// cloudPlatformScope is the OAuth scope of impersonated credentials.
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// GoogleClientOptions returns the options authenticating the Google Cloud
+// clients: the access token supplied by the client, or else the credentials
+// file configured in c, or Application Default Credentials, impersonating the
+// configured service account if any.
+func (c *Config) GoogleClientOptions(ctx context.Context) ([]option.ClientOption, error) {
+	if token := c.Credentials().GoogleAccessToken; token != "" {
+		return []option.ClientOption{option.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))}, nil
+	}
+	var opts []option.ClientOption
+	if file := c.GoogleCredentialsFile(); file != "" {
+		opts = append(opts, option.WithCredentialsFile(file))
+	}
+	if sa := c.ImpersonateServiceAccount(); sa != "" {
+		chain := strings.Split(sa, ",")
+		for i := range chain {
+			chain[i] = strings.TrimSpace(chain[i])
+		}
+		ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+			TargetPrincipal: chain[len(chain)-1],
+			Delegates:       chain[:len(chain)-1],
+			Scopes:          []string{cloudPlatformScope},
+		}, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to impersonate service account %s: %w", sa, err)
+		}
+		opts = []option.ClientOption{option.WithTokenSource(ts)}
+	}
+	return opts, nil
+}
+
+func New(version string, opts Options) *Config {
+	fieldManager := opts.FieldManager
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+	healthChecks := opts.HealthChecks
+	if len(healthChecks) == 0 {
+		healthChecks = HealthCheckNames
+	}
+	protectedNamespaces := opts.ProtectedNamespaces
+	if protectedNamespaces == nil {
+		protectedNamespaces = DefaultProtectedNamespaces
+	}
+	if opts.UnsafeAllowSystemNamespaces {
+		protectedNamespaces = nil
+	}
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		outputDir = DefaultOutputDir()
+	}
+	defaultProjectID := getDefaultProjectID()
+	if defaultProjectID == "" && opts.GoogleCredentialsFile != "" {
+		defaultProjectID = getCredentialsProjectID(opts.GoogleCredentialsFile)
+	}
 	return &Config{
+		version:          version,
 		userAgent:        "kubeapi-mcp/" + version,
-		defaultProjectID: getDefaultProjectID(),
+		defaultProjectID: defaultProjectID,
 		defaultLocation:  getDefaultLocation(),
-		readOnly:         readOnly,
-		udtPath:          udtPath,
+		readOnly:         opts.ReadOnly,
+		udtPath:          opts.UDTPath,
+		kubeQPS:          opts.KubeQPS,
+		kubeBurst:        opts.KubeBurst,
+		requestTimeout:   opts.RequestTimeout,
+		cacheTTL:         opts.CacheTTL,
+		fieldManager:     fieldManager,
+		defaultNamespace: opts.DefaultNamespace,
+		allowNodeDebug:   opts.AllowNodeDebug,
+		logQueriesPath:   opts.LogQueriesPath,
+		secretRedaction:  opts.SecretRedaction,
+
+		namespacedWritesOnly: opts.NamespacedWritesOnly,
+		protectedNamespaces:  protectedNamespaces,
+
+		notificationsSubscription: opts.NotificationsSubscription,
+		googleCredentialsFile:     opts.GoogleCredentialsFile,
+		impersonateServiceAccount: opts.ImpersonateServiceAccount,
+
+		profiles:               opts.Profiles,
+		startProfile:           opts.StartProfile,
+		allowProfileEscalation: opts.AllowProfileEscalation,
+
+		toolTimeouts: opts.ToolTimeouts,
+
+		healthCheckInterval: opts.HealthCheckInterval,
+		healthChecks:        healthChecks,
+
+		alerts: opts.Alerts,
+
+		approvals: opts.Approvals,
+
+		policy: opts.Policy,
+
+		outputDir:     outputDir,
+		outputBuckets: opts.OutputBuckets,
 	}
 }
 
 func getDefaultProjectID() string {
 	projectID, err := getGcloudConfig("core/project")
 	if err != nil {
-		log.Printf("Failed to get default project: %v", err)
+		slog.Warn("Failed to get default project", "error", err)
 		return ""
 	}
 	return projectID
 }
 
+// getCredentialsProjectID returns the project of a service account key file,
+// or the quota project of other credential files, for deployments without a
+// gcloud configuration.
+func getCredentialsProjectID(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Failed to read Google credentials file", "error", err)
+		return ""
+	}
+	var creds struct {
+		ProjectID      string `json:"project_id"`
+		QuotaProjectID string `json:"quota_project_id"`
+	}
+	if err := json.Unmarshal(b, &creds); err != nil {
+		slog.Warn("Failed to parse Google credentials file", "error", err)
+		return ""
+	}
+	if creds.ProjectID != "" {
+		return creds.ProjectID
+	}
+	return creds.QuotaProjectID
+}
+
 func getDefaultLocation() string {
 	region, err := getGcloudConfig("compute/region")
 	if err == nil {
@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestGoogleClientOptions(t *testing.T) {
+	ctx := context.Background()
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	for _, tc := range []struct {
+		name     string
+		c        *Config
+		wantOpts int
+		wantErr  bool
+	}{
+		{name: "application default credentials", c: New("test", Options{})},
+		{name: "credentials file", c: New("test", Options{GoogleCredentialsFile: missing}), wantOpts: 1},
+		{
+			name:     "access token overrides configuration",
+			c:        New("test", Options{GoogleCredentialsFile: missing, ImpersonateServiceAccount: "sa@p.iam.gserviceaccount.com"}).WithCredentials(Credentials{GoogleAccessToken: "token"}),
+			wantOpts: 1,
+		},
+		{
+			name:    "impersonation with invalid base credentials",
+			c:       New("test", Options{GoogleCredentialsFile: missing, ImpersonateServiceAccount: "sa@p.iam.gserviceaccount.com"}),
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts, err := tc.c.GoogleClientOptions(ctx)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("GoogleClientOptions() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if len(opts) != tc.wantOpts {
+				t.Errorf("GoogleClientOptions() returned %d options, want %d", len(opts), tc.wantOpts)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Profile is a named environment, such as dev or prod: a cluster and a
+// project, and the safety settings of the tools working on them. Empty
+// fields keep the settings of the server.
+type Profile struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Context is the kubeconfig context of the cluster.
+	Context   string `json:"context,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+	Location  string `json:"location,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// ReadOnly makes the server read-only while the profile is used. A
+	// profile can't make a read-only server writable.
+	ReadOnly bool `json:"read_only,omitempty"`
+	// NamespacedWritesOnly restricts the changes to namespaced resources
+	// while the profile is used. Like ReadOnly, it can only add restrictions.
+	NamespacedWritesOnly bool `json:"namespaced_writes_only,omitempty"`
+	// AllowNodeDebug overrides the setting of the server if set.
+	AllowNodeDebug            *bool  `json:"allow_node_debug,omitempty"`
+	GoogleCredentialsFile     string `json:"google_credentials_file,omitempty"`
+	ImpersonateServiceAccount string `json:"impersonate_service_account,omitempty"`
+}
+
+type profilesFile struct {
+	Profiles []Profile `json:"profiles"`
+}
+
+// LoadProfiles returns the profiles of the YAML file at path.
+func LoadProfiles(path string) ([]Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file profilesFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles of %s: %w", path, err)
+	}
+	seen := map[string]bool{}
+	for _, p := range file.Profiles {
+		if p.Name == "" {
+			return nil, fmt.Errorf("profiles of %s must have a name", path)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("duplicate profile %q in %s", p.Name, path)
+		}
+		seen[p.Name] = true
+	}
+	return file.Profiles, nil
+}
+
+// WithProfile returns a copy of c with the settings of the profile name. c
+// must be the configuration of the server, without a profile.
+func (c *Config) WithProfile(name string) (*Config, error) {
+	for _, p := range c.profiles {
+		if p.Name != name {
+			continue
+		}
+		cc := *c
+		cc.profile = p.Name
+		if p.Context != "" {
+			cc.kubeContext = p.Context
+		}
+		if p.ProjectID != "" {
+			cc.defaultProjectID = p.ProjectID
+		}
+		if p.Location != "" {
+			cc.defaultLocation = p.Location
+		}
+		if p.Namespace != "" {
+			cc.defaultNamespace = p.Namespace
+		}
+		cc.readOnly = c.readOnly || p.ReadOnly
+		cc.namespacedWritesOnly = c.namespacedWritesOnly || p.NamespacedWritesOnly
+		if p.AllowNodeDebug != nil {
+			cc.allowNodeDebug = *p.AllowNodeDebug
+		}
+		if p.GoogleCredentialsFile != "" {
+			cc.googleCredentialsFile = p.GoogleCredentialsFile
+			if cc.defaultProjectID == "" {
+				cc.defaultProjectID = getCredentialsProjectID(p.GoogleCredentialsFile)
+			}
+		}
+		if p.ImpersonateServiceAccount != "" {
+			cc.impersonateServiceAccount = p.ImpersonateServiceAccount
+		}
+		return &cc, nil
+	}
+	return nil, fmt.Errorf("unknown profile %q", name)
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeProfiles(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadProfiles(t *testing.T) {
+	path := writeProfiles(t, `
+profiles:
+- name: dev
+  context: dev-context
+  project_id: my-dev
+  allow_node_debug: true
+- name: prod
+  location: us-central1
+  read_only: true
+`)
+	got, err := LoadProfiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allow := true
+	want := []Profile{
+		{Name: "dev", Context: "dev-context", ProjectID: "my-dev", AllowNodeDebug: &allow},
+		{Name: "prod", Location: "us-central1", ReadOnly: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("LoadProfiles() mismatch (-want +got):\n%s", diff)
+	}
+
+	for name, content := range map[string]string{
+		"unknown field": "profiles:\n- name: dev\n  cluster: dev\n",
+		"missing name":  "profiles:\n- context: dev\n",
+		"duplicate":     "profiles:\n- name: dev\n- name: dev\n",
+	} {
+		if _, err := LoadProfiles(writeProfiles(t, content)); err == nil {
+			t.Errorf("LoadProfiles() of %s = nil error, want error", name)
+		}
+	}
+}
+
+func TestWithProfile(t *testing.T) {
+	allow := true
+	c := New("test", Options{
+		DefaultNamespace: "default",
+		Profiles: []Profile{
+			{Name: "dev", Context: "dev-context", ProjectID: "my-dev", AllowNodeDebug: &allow},
+			{Name: "prod", Location: "us-central1", Namespace: "frontend", ReadOnly: true},
+			{Name: "staging", NamespacedWritesOnly: true},
+		},
+	})
+	if got := c.StartProfile(); got != "dev" {
+		t.Errorf("StartProfile() = %q, want dev", got)
+	}
+
+	dev, err := c.WithProfile("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dev.Profile() != "dev" || dev.KubeContext() != "dev-context" || dev.DefaultProjectID() != "my-dev" || !dev.AllowNodeDebug() || dev.ReadOnly() || dev.DefaultNamespace() != "default" {
+		t.Errorf("WithProfile(dev) = profile %q, context %q, project %q, node debug %t, read-only %t, namespace %q",
+			dev.Profile(), dev.KubeContext(), dev.DefaultProjectID(), dev.AllowNodeDebug(), dev.ReadOnly(), dev.DefaultNamespace())
+	}
+	prod, err := c.WithProfile("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prod.DefaultLocation() != "us-central1" || prod.DefaultNamespace() != "frontend" || !prod.ReadOnly() || prod.KubeContext() != "" {
+		t.Errorf("WithProfile(prod) = location %q, namespace %q, read-only %t, context %q",
+			prod.DefaultLocation(), prod.DefaultNamespace(), prod.ReadOnly(), prod.KubeContext())
+	}
+	staging, err := c.WithProfile("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !staging.NamespacedWritesOnly() || staging.ReadOnly() || dev.NamespacedWritesOnly() {
+		t.Errorf("WithProfile(staging) = namespaced writes only %t, read-only %t", staging.NamespacedWritesOnly(), staging.ReadOnly())
+	}
+	if c.ReadOnly() || c.Profile() != "" {
+		t.Error("WithProfile() modified the server configuration")
+	}
+
+	if _, err := c.WithProfile("qa"); err == nil {
+		t.Error("WithProfile(qa) = nil error, want error")
+	}
+}
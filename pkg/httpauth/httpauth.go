@@ -0,0 +1,116 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpauth authenticates requests made to the server in HTTP mode.
+package httpauth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader is the header that can carry an API key as an alternative to
+// an "Authorization: Bearer" header.
+const APIKeyHeader = "X-API-Key"
+
+// Options configures how requests are authenticated. A request is accepted if
+// it carries one of Tokens, or a valid ID token issued by OIDCIssuerURL.
+type Options struct {
+	// Tokens are static bearer tokens or API keys accepted by the server.
+	Tokens []string
+
+	// OIDCIssuerURL enables validation of OIDC ID tokens from this issuer.
+	OIDCIssuerURL string
+	// OIDCAudience is the audience that OIDC ID tokens must be issued for.
+	OIDCAudience string
+}
+
+// Enabled reports whether any authentication method is configured.
+func (o Options) Enabled() bool {
+	return len(o.Tokens) > 0 || o.OIDCIssuerURL != ""
+}
+
+// Authenticator verifies the credentials presented by HTTP requests.
+type Authenticator struct {
+	tokens [][]byte
+	oidc   *oidcVerifier
+}
+
+func New(opts Options) (*Authenticator, error) {
+	a := &Authenticator{}
+	for _, t := range opts.Tokens {
+		if t = strings.TrimSpace(t); t != "" {
+			a.tokens = append(a.tokens, []byte(t))
+		}
+	}
+	if opts.OIDCIssuerURL != "" {
+		if opts.OIDCAudience == "" {
+			return nil, fmt.Errorf("an OIDC audience is required when an OIDC issuer is set")
+		}
+		a.oidc = newOIDCVerifier(opts.OIDCIssuerURL, opts.OIDCAudience)
+	}
+	if len(a.tokens) == 0 && a.oidc == nil {
+		return nil, fmt.Errorf("no authentication method configured")
+	}
+	return a, nil
+}
+
+var errNoCredentials = errors.New("no credentials")
+
+// credential extracts the token presented by r, either as a bearer token or
+// as an API key.
+func credential(r *http.Request) (string, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key, nil
+	}
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "bearer") {
+		return "", errNoCredentials
+	}
+	return fields[1], nil
+}
+
+func (a *Authenticator) authenticate(r *http.Request) error {
+	token, err := credential(r)
+	if err != nil {
+		return err
+	}
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(t, []byte(token)) == 1 {
+			return nil
+		}
+	}
+	if a.oidc != nil {
+		return a.oidc.verify(r.Context(), token)
+	}
+	return errors.New("invalid token")
+}
+
+// Middleware rejects requests that fail authentication with 401
+// Unauthorized.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := a.authenticate(r); err != nil {
+			slog.Warn("Rejected unauthenticated HTTP request", "remote_addr", r.RemoteAddr, "path", r.URL.Path, "error", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="kubeapi-mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
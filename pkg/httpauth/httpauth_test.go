@@ -0,0 +1,163 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpauth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func serve(t *testing.T, a *Authenticator, header, value string) int {
+	t.Helper()
+	h := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestStaticTokens(t *testing.T) {
+	a, err := New(Options{Tokens: []string{"s3cret"}})
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	for _, tc := range []struct {
+		name, header, value string
+		want                int
+	}{
+		{"no credentials", "", "", http.StatusUnauthorized},
+		{"bearer token", "Authorization", "Bearer s3cret", http.StatusOK},
+		{"api key", APIKeyHeader, "s3cret", http.StatusOK},
+		{"wrong token", "Authorization", "Bearer nope", http.StatusUnauthorized},
+		{"basic auth", "Authorization", "Basic s3cret", http.StatusUnauthorized},
+	} {
+		if got := serve(t, a, tc.header, tc.value); got != tc.want {
+			t.Errorf("%s: got status %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestNewRequiresMethod(t *testing.T) {
+	if _, err := New(Options{}); err == nil {
+		t.Errorf("New() expected error without any authentication method")
+	}
+	if _, err := New(Options{OIDCIssuerURL: "https://issuer.example.com"}); err == nil {
+		t.Errorf("New() expected error for OIDC issuer without audience")
+	}
+}
+
+func TestOIDC(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	var issuer string
+	var keyFetches atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": issuer, "jwks_uri": issuer + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		keyFetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	issuer = srv.URL
+
+	sign := func(key *rsa.PrivateKey, claims map[string]any) string {
+		enc := func(v any) string {
+			b, _ := json.Marshal(v)
+			return base64.RawURLEncoding.EncodeToString(b)
+		}
+		unsigned := enc(map[string]string{"alg": "RS256", "kid": "key-1"}) + "." + enc(claims)
+		digest := sha256.Sum256([]byte(unsigned))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)
+	}
+
+	a, err := New(Options{OIDCIssuerURL: issuer, OIDCAudience: "kubeapi-mcp"})
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+	for _, tc := range []struct {
+		name   string
+		claims map[string]any
+		want   int
+	}{
+		{"valid", map[string]any{"iss": issuer, "aud": "kubeapi-mcp", "exp": exp}, http.StatusOK},
+		{"audience list", map[string]any{"iss": issuer, "aud": []string{"other", "kubeapi-mcp"}, "exp": exp}, http.StatusOK},
+		{"wrong audience", map[string]any{"iss": issuer, "aud": "other", "exp": exp}, http.StatusUnauthorized},
+		{"wrong issuer", map[string]any{"iss": "https://evil.example.com", "aud": "kubeapi-mcp", "exp": exp}, http.StatusUnauthorized},
+		{"expired", map[string]any{"iss": issuer, "aud": "kubeapi-mcp", "exp": time.Now().Add(-time.Hour).Unix()}, http.StatusUnauthorized},
+	} {
+		if got := serve(t, a, "Authorization", "Bearer "+sign(key, tc.claims)); got != tc.want {
+			t.Errorf("%s: got status %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	// Tokens signed with unknown keys re-fetch the signing keys at most once
+	// per jwksMinRefreshInterval.
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ks := a.oidc.keys
+	now := time.Now()
+	ks.now = func() time.Time { return now }
+	ks.mu.Lock()
+	ks.fetchedAt = now
+	ks.mu.Unlock()
+	fetches := keyFetches.Load()
+	forged := sign(other, map[string]any{"iss": issuer, "aud": "kubeapi-mcp", "exp": exp})
+	for range 10 {
+		if got := serve(t, a, "Authorization", "Bearer "+forged); got != http.StatusUnauthorized {
+			t.Errorf("forged token: got status %d, want %d", got, http.StatusUnauthorized)
+		}
+	}
+	if got := keyFetches.Load() - fetches; got != 0 {
+		t.Errorf("forged tokens fetched the signing keys %d times within the refresh interval, want 0", got)
+	}
+	now = now.Add(jwksMinRefreshInterval)
+	for range 10 {
+		serve(t, a, "Authorization", "Bearer "+forged)
+	}
+	if got := keyFetches.Load() - fetches; got != 1 {
+		t.Errorf("forged tokens fetched the signing keys %d times after the refresh interval, want 1", got)
+	}
+}
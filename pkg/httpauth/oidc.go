@@ -0,0 +1,187 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/go-jose/go-jose/v4"
+	"golang.org/x/sync/singleflight"
+)
+
+// jwksMinRefreshInterval bounds how often the signing keys of the issuer are
+// re-fetched for tokens signed with unknown keys, so that forged tokens can't
+// make the server flood the issuer.
+const jwksMinRefreshInterval = time.Minute
+
+// oidcVerifier validates RS256-signed OIDC ID tokens against the signing keys
+// published by the issuer.
+type oidcVerifier struct {
+	keys     *issuerKeySet
+	verifier *oidc.IDTokenVerifier
+}
+
+func newOIDCVerifier(issuer, audience string) *oidcVerifier {
+	keys := &issuerKeySet{
+		issuer: strings.TrimSuffix(issuer, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+	return &oidcVerifier{
+		keys: keys,
+		verifier: oidc.NewVerifier(keys.issuer, keys, &oidc.Config{
+			ClientID:             audience,
+			SupportedSigningAlgs: []string{oidc.RS256},
+		}),
+	}
+}
+
+func (v *oidcVerifier) verify(ctx context.Context, token string) error {
+	_, err := v.verifier.Verify(ctx, token)
+	return err
+}
+
+// issuerKeySet is the oidc.KeySet of the signing keys published by the
+// issuer. Unlike oidc.RemoteKeySet, it re-fetches them at most once per
+// jwksMinRefreshInterval, and never while holding its lock.
+type issuerKeySet struct {
+	issuer string
+	client *http.Client
+	now    func() time.Time
+
+	// group makes concurrent requests share a single fetch.
+	group singleflight.Group
+
+	mu      sync.Mutex
+	jwksURL string
+	keys    *oidc.StaticKeySet
+	// fetchedAt is the time of the last fetch, successful or not.
+	fetchedAt time.Time
+}
+
+func (k *issuerKeySet) VerifySignature(ctx context.Context, jwt string) ([]byte, error) {
+	if keys := k.current(); keys != nil {
+		if payload, err := keys.VerifySignature(ctx, jwt); err == nil {
+			return payload, nil
+		}
+	}
+	// The token is signed with an unknown key, or the keys were never
+	// fetched: the issuer may have rotated its keys.
+	if _, err, _ := k.group.Do("keys", func() (any, error) {
+		return nil, k.refresh(context.WithoutCancel(ctx))
+	}); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+	keys := k.current()
+	if keys == nil {
+		return nil, errors.New("invalid token signature")
+	}
+	payload, err := keys.VerifySignature(ctx, jwt)
+	if err != nil {
+		return nil, errors.New("invalid token signature")
+	}
+	return payload, nil
+}
+
+// current returns the keys last fetched, or nil.
+func (k *issuerKeySet) current() *oidc.StaticKeySet {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.keys
+}
+
+// refresh fetches the signing keys of the issuer, unless they were fetched in
+// the last jwksMinRefreshInterval.
+func (k *issuerKeySet) refresh(ctx context.Context) error {
+	k.mu.Lock()
+	if !k.fetchedAt.IsZero() && k.now().Sub(k.fetchedAt) < jwksMinRefreshInterval {
+		k.mu.Unlock()
+		return nil
+	}
+	k.fetchedAt = k.now()
+	jwksURL := k.jwksURL
+	k.mu.Unlock()
+
+	ctx = oidc.ClientContext(ctx, k.client)
+	if jwksURL == "" {
+		provider, err := oidc.NewProvider(ctx, k.issuer)
+		if err != nil {
+			return err
+		}
+		var discovery struct {
+			JWKSURL string `json:"jwks_uri"`
+		}
+		if err := provider.Claims(&discovery); err != nil {
+			return err
+		}
+		if discovery.JWKSURL == "" {
+			return errors.New("issuer does not publish a jwks_uri")
+		}
+		jwksURL = discovery.JWKSURL
+	}
+	keys, err := k.fetchKeys(ctx, jwksURL)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.jwksURL = jwksURL
+	k.keys = &oidc.StaticKeySet{PublicKeys: keys}
+	return nil
+}
+
+// fetchKeys returns the public signing keys of the key set at jwksURL.
+func (k *issuerKeySet) fetchKeys(ctx context.Context, jwksURL string) ([]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: unexpected status %s", jwksURL, resp.Status)
+	}
+	var jwks jose.JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", jwksURL, err)
+	}
+	var keys []crypto.PublicKey
+	for _, key := range jwks.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		switch key.Key.(type) {
+		case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+			keys = append(keys, key.Key)
+		}
+	}
+	return keys, nil
+}
@@ -17,7 +17,7 @@ import (
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -31,7 +31,7 @@ func GeminiCLIExtension(opts *InstallOptions) error {
 		if strings.HasPrefix(opts.exePath, os.TempDir()) {
 			return fmt.Errorf("cannot install in developer mode using `go run`. Try again using `go build` and `./kubeapi-mcp`")
 		}
-		log.Printf("version: %s", opts.version)
+		slog.Debug("Installing in developer mode", "version", opts.version)
 		contextFilename = filepath.Join(filepath.Dir(opts.exePath), "pkg", "install", "GEMINI.md")
 		if _, err := os.ReadFile(contextFilename); err != nil {
 			return fmt.Errorf("could not read context file from %s: %w", contextFilename, err)
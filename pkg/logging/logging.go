@@ -0,0 +1,91 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging configures the structured logger used by the server.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// NewLogger returns a logger writing to w at the given level ("debug",
+// "info", "warn" or "error") in the given format ("text" or "json").
+func NewLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q: must be text or json", format)
+	}
+}
+
+var lastRequestID atomic.Uint64
+
+// ToolCallMiddleware logs every tool call with a request ID, the tool name,
+// the call duration and its outcome.
+func ToolCallMiddleware(logger *slog.Logger) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if !ok {
+				return next(ctx, method, req)
+			}
+
+			l := logger.With(
+				"request_id", strconv.FormatUint(lastRequestID.Add(1), 10),
+				"tool", callReq.Params.Name,
+			)
+			if id := req.GetSession().ID(); id != "" {
+				l = l.With("session_id", id)
+			}
+			l.DebugContext(ctx, "Tool call started")
+
+			start := time.Now()
+			res, err := next(ctx, method, req)
+			duration := time.Since(start)
+
+			switch {
+			case err != nil:
+				l.ErrorContext(ctx, "Tool call failed", "duration", duration, "error", err)
+			case isToolError(res):
+				l.WarnContext(ctx, "Tool call returned an error", "duration", duration)
+			default:
+				l.InfoContext(ctx, "Tool call completed", "duration", duration)
+			}
+			return res, err
+		}
+	}
+}
+
+func isToolError(res mcp.Result) bool {
+	r, ok := res.(*mcp.CallToolResult)
+	return ok && r != nil && r.IsError
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, "warn", "json")
+	if err != nil {
+		t.Fatalf("NewLogger() returned error: %v", err)
+	}
+	logger.Info("dropped")
+	logger.Warn("kept", "key", "value")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 log line, got %d: %q", len(lines), buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if entry["msg"] != "kept" || entry["key"] != "value" {
+		t.Errorf("unexpected log entry: %v", entry)
+	}
+}
+
+func TestNewLoggerInvalid(t *testing.T) {
+	for _, tc := range []struct{ level, format string }{
+		{"verbose", "text"},
+		{"info", "xml"},
+	} {
+		if _, err := NewLogger(&bytes.Buffer{}, tc.level, tc.format); err == nil {
+			t.Errorf("NewLogger(%q, %q) expected error", tc.level, tc.format)
+		}
+	}
+}
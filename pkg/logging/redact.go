@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"io"
+	"regexp"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	// secretFieldRe matches JSON fields whose names suggest secret values,
+	// including JSON embedded as an escaped string in another document.
+	secretFieldRe = regexp.MustCompile(`(?i)(\\?"(?:password|passwd|token|access_token|refresh_token|id_token|client_secret|secret|api[_-]?key|client-key-data|client-certificate-data|private[_-]?key)\\?"\s*:\s*\\?")[^"\\]*`)
+	// secretYAMLFieldRe matches the same fields in YAML documents.
+	secretYAMLFieldRe = regexp.MustCompile(`(?i)((?:^|\\n|\s)(?:password|passwd|token|access_token|refresh_token|client_secret|secret|api[_-]?key|client-key-data|client-certificate-data|private[_-]?key):\s+)[^\s\\"]+`)
+	// bearerRe matches credentials in Authorization-like strings.
+	bearerRe = regexp.MustCompile(`(?i)((?:bearer|basic)\s+)[A-Za-z0-9\-._~+/]+=*`)
+	// jwtRe matches JSON Web Tokens.
+	jwtRe = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)
+	// googleTokenRe matches Google OAuth access tokens.
+	googleTokenRe = regexp.MustCompile(`ya29\.[A-Za-z0-9_\-]+`)
+	// base64Re matches base64 blobs long enough to hold key material.
+	base64Re = regexp.MustCompile(`[A-Za-z0-9+/]{40,}={0,2}`)
+	hexRe    = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+)
+
+// Redact masks secret-looking values in s: known secret fields, bearer and
+// basic credentials, JWTs, Google access tokens and long base64 blobs.
+func Redact(s string) string {
+	s = secretFieldRe.ReplaceAllString(s, "${1}"+redacted)
+	s = secretYAMLFieldRe.ReplaceAllString(s, "${1}"+redacted)
+	s = bearerRe.ReplaceAllString(s, "${1}"+redacted)
+	s = jwtRe.ReplaceAllString(s, redacted)
+	s = googleTokenRe.ReplaceAllString(s, redacted)
+	s = base64Re.ReplaceAllStringFunc(s, func(m string) string {
+		// Digests such as image references are hex encoded and not secret.
+		if hexRe.MatchString(m) {
+			return m
+		}
+		return redacted
+	})
+	return s
+}
+
+// RedactingWriter redacts each write with Redact before passing it on. It
+// assumes each write holds complete messages, as is the case for the
+// transport logs.
+type RedactingWriter struct {
+	w io.Writer
+}
+
+// NewRedactingWriter returns a writer that redacts secrets before writing to w.
+func NewRedactingWriter(w io.Writer) *RedactingWriter {
+	return &RedactingWriter{w: w}
+}
+
+func (r *RedactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
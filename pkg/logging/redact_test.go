@@ -0,0 +1,68 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	for _, tc := range []struct {
+		name, in, want string
+	}{
+		{
+			name: "json field",
+			in:   `{"token":"abc123","name":"x"}`,
+			want: `{"token":"[REDACTED]","name":"x"}`,
+		},
+		{
+			name: "escaped json field",
+			in:   `{"text":"{\"password\": \"hunter2\"}"}`,
+			want: `{"text":"{\"password\": \"[REDACTED]\"}"}`,
+		},
+		{
+			name: "yaml field in text content",
+			in:   `{"text":"data:\n  password: aHVudGVyMg==\n"}`,
+			want: `{"text":"data:\n  password: [REDACTED]\n"}`,
+		},
+		{
+			name: "bearer token",
+			in:   `Authorization: Bearer abc.def-ghi`,
+			want: `Authorization: Bearer [REDACTED]`,
+		},
+		{
+			name: "google access token",
+			in:   `token ya29.a0AfH6SMB-xyz`,
+			want: `token [REDACTED]`,
+		},
+		{
+			name: "long base64 blob",
+			in:   `ca.crt: LS0tLS1CRUdJTiBDRVJUSUZJQ0FURS0tLS0tCk1JSUREekNDQWZlZ0F3SUJBZ0lV`,
+			want: `ca.crt: [REDACTED]`,
+		},
+		{
+			name: "image digest kept",
+			in:   `image: nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31`,
+			want: `image: nginx@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31`,
+		},
+		{
+			name: "plain text kept",
+			in:   `pod my-pod is Running`,
+			want: `pod my-pod is Running`,
+		},
+	} {
+		if got := Redact(tc.in); got != tc.want {
+			t.Errorf("%s: Redact(%q) = %q, want %q", tc.name, tc.in, got, tc.want)
+		}
+	}
+}
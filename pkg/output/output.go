@@ -0,0 +1,161 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package output writes the files produced by tools, such as snapshots and
+// incident reports, to the destinations chosen by the client: new files in
+// the output directory of the server, or objects of the Cloud Storage buckets
+// it allows. Clients can't write anywhere else on the host, nor overwrite
+// existing files. Tools reading such files back, e.g. to apply a snapshot,
+// read them from the same places only.
+package output
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"google.golang.org/api/storage/v1"
+)
+
+// ParseGCSURL splits a gs://BUCKET/OBJECT URL.
+func ParseGCSURL(url string) (bucket, object string, ok bool) {
+	rest, ok := strings.CutPrefix(url, "gs://")
+	if !ok {
+		return "", "", false
+	}
+	bucket, object, _ = strings.Cut(rest, "/")
+	return bucket, object, bucket != "" && object != ""
+}
+
+// Check checks that destination is a path relative to the output directory
+// of c, or a gs://BUCKET/OBJECT URL of a bucket allowed by c.
+func Check(c *config.Config, destination string) error {
+	if strings.HasPrefix(destination, "gs://") {
+		bucket, _, ok := ParseGCSURL(destination)
+		if !ok {
+			return fmt.Errorf("invalid Cloud Storage destination %q, expected gs://BUCKET/OBJECT", destination)
+		}
+		if !c.OutputBucketAllowed(bucket) {
+			return fmt.Errorf("uploads to Cloud Storage bucket %q are not allowed by the server", bucket)
+		}
+		return nil
+	}
+	if !filepath.IsLocal(destination) {
+		return fmt.Errorf("invalid destination %q, expected a relative path in the output directory of the server, without ..", destination)
+	}
+	return nil
+}
+
+// Write writes data to destination, a new file in the output
+// directory of c, or a gs://BUCKET/OBJECT URL of a bucket allowed by c,
+// uploaded with the Google Cloud credentials of c. Existing files are never
+// overwritten. It returns where data was written.
+func Write(ctx context.Context, c *config.Config, destination string, data []byte, contentType string) (string, error) {
+	if err := Check(c, destination); err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(destination, "gs://") {
+		return destination, Upload(ctx, c, destination, data, contentType)
+	}
+	if err := os.MkdirAll(c.OutputDir(), 0o700); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	// The root keeps symbolic links from escaping the output directory.
+	root, err := os.OpenRoot(c.OutputDir())
+	if err != nil {
+		return "", fmt.Errorf("failed to open output directory: %w", err)
+	}
+	defer root.Close()
+	f, err := root.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", destination, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write %s: %w", destination, err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", destination, err)
+	}
+	return filepath.Join(c.OutputDir(), destination), nil
+}
+
+// Open opens source, a path relative to the output directory of c, or a
+// gs://BUCKET/OBJECT URL of a bucket allowed by c, downloaded with the Google
+// Cloud credentials of c.
+func Open(ctx context.Context, c *config.Config, source string) (io.ReadCloser, error) {
+	if strings.HasPrefix(source, "gs://") {
+		bucket, object, ok := ParseGCSURL(source)
+		if !ok {
+			return nil, fmt.Errorf("invalid Cloud Storage source %q, expected gs://BUCKET/OBJECT", source)
+		}
+		if !c.OutputBucketAllowed(bucket) {
+			return nil, fmt.Errorf("downloads from Cloud Storage bucket %q are not allowed by the server", bucket)
+		}
+		gcpOpts, err := c.GoogleClientOptions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		storageService, err := storage.NewService(ctx, gcpOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create storage service: %w", err)
+		}
+		resp, err := storageService.Objects.Get(bucket, object).Context(ctx).Download()
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", source, err)
+		}
+		return resp.Body, nil
+	}
+	if !filepath.IsLocal(source) {
+		return nil, fmt.Errorf("invalid source %q, expected a relative path in the output directory of the server, without ..", source)
+	}
+	// The root keeps symbolic links from escaping the output directory.
+	root, err := os.OpenRoot(c.OutputDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output directory: %w", err)
+	}
+	defer root.Close()
+	f, err := root.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", source, err)
+	}
+	return f, nil
+}
+
+// Upload uploads data to destination, a gs://BUCKET/OBJECT URL, with the
+// Google Cloud credentials of c.
+func Upload(ctx context.Context, c *config.Config, destination string, data []byte, contentType string) error {
+	bucket, object, ok := ParseGCSURL(destination)
+	if !ok {
+		return fmt.Errorf("invalid Cloud Storage destination %q, expected gs://BUCKET/OBJECT", destination)
+	}
+	gcpOpts, err := c.GoogleClientOptions(ctx)
+	if err != nil {
+		return err
+	}
+	storageService, err := storage.NewService(ctx, gcpOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create storage service: %w", err)
+	}
+	obj := &storage.Object{Name: object, ContentType: contentType}
+	if _, err := storageService.Objects.Insert(bucket, obj).Media(bytes.NewReader(data)).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to upload to %s: %w", destination, err)
+	}
+	return nil
+}
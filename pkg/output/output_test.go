@@ -0,0 +1,132 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	c := config.New("test", config.Options{OutputDir: dir, OutputBuckets: []string{"allowed-bucket"}})
+	ctx := context.Background()
+
+	written, err := Write(ctx, c, "report.md", []byte("report"), "text/markdown")
+	if err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if want := filepath.Join(dir, "report.md"); written != want {
+		t.Errorf("Write() = %q, want %q", written, want)
+	}
+	if b, err := os.ReadFile(written); err != nil || string(b) != "report" {
+		t.Errorf("ReadFile(%q) = %q, %v, want %q", written, b, err, "report")
+	}
+	// Existing files are never overwritten.
+	if _, err := Write(ctx, c, "report.md", []byte("other"), "text/markdown"); err == nil {
+		t.Error("Write() of an existing file succeeded, want error")
+	}
+	if b, _ := os.ReadFile(written); string(b) != "report" {
+		t.Errorf("ReadFile(%q) = %q after a failed write, want %q", written, b, "report")
+	}
+	// Symbolic links don't escape the output directory.
+	outside := filepath.Join(t.TempDir(), "outside")
+	if err := os.Symlink(filepath.Dir(outside), filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("Symlink() failed: %v", err)
+	}
+	if _, err := Write(ctx, c, "link/outside", []byte("data"), "text/plain"); err == nil {
+		t.Error("Write() through a symbolic link succeeded, want error")
+	}
+	if _, err := os.Stat(outside); err == nil {
+		t.Errorf("%s was written outside the output directory", outside)
+	}
+
+	for _, destination := range []string{
+		"/etc/passwd",
+		"../report.md",
+		"reports/../../report.md",
+		"",
+		"gs://other-bucket/report.md",
+		"gs://allowed-bucket",
+	} {
+		if _, err := Write(ctx, c, destination, []byte("data"), "text/plain"); err == nil {
+			t.Errorf("Write(%q) succeeded, want error", destination)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	c := config.New("test", config.Options{OutputDir: dir, OutputBuckets: []string{"allowed-bucket"}})
+	ctx := context.Background()
+	if err := os.WriteFile(filepath.Join(dir, "snapshot.yaml"), []byte("snapshot"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Open(ctx, c, "snapshot.yaml")
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	b, err := io.ReadAll(f)
+	f.Close()
+	if err != nil || string(b) != "snapshot" {
+		t.Errorf("ReadAll() = %q, %v, want %q", b, err, "snapshot")
+	}
+	// Symbolic links don't escape the output directory.
+	outside := filepath.Join(t.TempDir(), "outside.yaml")
+	if err := os.WriteFile(outside, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link.yaml")); err != nil {
+		t.Fatalf("Symlink() failed: %v", err)
+	}
+
+	for _, source := range []string{
+		"link.yaml",
+		outside,
+		"../snapshot.yaml",
+		"",
+		"gs://other-bucket/snapshot.yaml",
+		"gs://allowed-bucket",
+	} {
+		if f, err := Open(ctx, c, source); err == nil {
+			f.Close()
+			t.Errorf("Open(%q) succeeded, want error", source)
+		}
+	}
+}
+
+func TestParseGCSURL(t *testing.T) {
+	for _, tc := range []struct {
+		url            string
+		bucket, object string
+		ok             bool
+	}{
+		{url: "gs://my-bucket/snapshots/prod.tar.gz", bucket: "my-bucket", object: "snapshots/prod.tar.gz", ok: true},
+		{url: "gs://my-bucket", bucket: "my-bucket"},
+		{url: "gs:///prod.tar.gz", object: "prod.tar.gz"},
+		{url: "/tmp/prod.tar.gz"},
+	} {
+		bucket, object, ok := ParseGCSURL(tc.url)
+		if bucket != tc.bucket || object != tc.object || ok != tc.ok {
+			t.Errorf("ParseGCSURL(%q) = %q, %q, %t, want %q, %q, %t", tc.url, bucket, object, ok, tc.bucket, tc.object, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policy decides whether tool calls are allowed, denied, or require
+// the confirmation of the user, with CEL rules and, optionally, the Rego
+// policies of an Open Policy Agent server.
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"sigs.k8s.io/yaml"
+)
+
+// The effects of policies on tool calls, from the least to the most
+// restrictive.
+const (
+	EffectAllow   = "allow"
+	EffectConfirm = "confirm"
+	EffectDeny    = "deny"
+)
+
+// restrictiveness orders the effects.
+var restrictiveness = map[string]int{EffectAllow: 0, EffectConfirm: 1, EffectDeny: 2}
+
+// opaTimeout bounds the queries of the Open Policy Agent server.
+const opaTimeout = 5 * time.Second
+
+// Input describes a tool call. It is the call variable of the CEL
+// expressions, and the input document of the Rego policies.
+type Input struct {
+	Tool string `json:"tool"`
+	// Args are the arguments of the call, as decoded from JSON.
+	Args map[string]any `json:"args"`
+	// Mutating reports whether the tool changes resources.
+	Mutating bool `json:"mutating"`
+	// Namespace is the first of Namespaces.
+	Namespace string `json:"namespace"`
+	// Namespaces are the namespaces targeted by the call: its namespace
+	// arguments and the namespaces of its manifests, or else the default
+	// namespace of the server.
+	Namespaces []string `json:"namespaces"`
+	// Cluster is the cluster name argument of the call, or else the
+	// kubeconfig context of the server. Empty means the current context.
+	Cluster string `json:"cluster"`
+	Profile string `json:"profile"`
+	// Project is the project ID argument of the call, or else the default
+	// project of the server.
+	Project string `json:"project"`
+}
+
+// Decision is the effect of the policy on a tool call.
+type Decision struct {
+	Effect string
+	// Rule is the name of the rule deciding the effect, or "opa" for the
+	// Open Policy Agent server. Empty means the default effect.
+	Rule    string
+	Message string
+}
+
+// Rule applies its effect to the tool calls matching its expression.
+type Rule struct {
+	Name string `json:"name"`
+	// Expression is a CEL expression returning whether the rule matches a
+	// call, e.g.
+	// `call.mutating && call.namespaces.exists(ns, ns.startsWith("prod-"))`.
+	Expression string `json:"expression"`
+	Effect     string `json:"effect"`
+	// Message explains the effect to the model and to the user.
+	Message string `json:"message,omitempty"`
+
+	program cel.Program
+}
+
+// OPA is an Open Policy Agent server evaluating Rego policies.
+type OPA struct {
+	// URL is the URL of the decision document of the Data API, e.g.
+	// http://localhost:8181/v1/data/kubeapi/decision. The decision is a
+	// boolean, true allowing the call, or an object with an effect and a
+	// message.
+	URL string `json:"url"`
+}
+
+// Policy holds the rules applied to tool calls.
+type Policy struct {
+	// Default is the effect on the calls matching no rule. Empty means
+	// EffectAllow.
+	Default string `json:"default,omitempty"`
+	// Rules are evaluated in order: the first matching rule decides the
+	// effect.
+	Rules []*Rule `json:"rules,omitempty"`
+	// OPA, if set, is also queried for each call. The most restrictive
+	// effect of the rules and of the server applies.
+	OPA *OPA `json:"opa,omitempty"`
+
+	client *http.Client
+}
+
+// Load returns the policy of the YAML file at path.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Parse returns the policy of the YAML document data, with the expressions
+// of its rules compiled.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.UnmarshalStrict(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	if p.Default == "" {
+		p.Default = EffectAllow
+	}
+	if _, ok := restrictiveness[p.Default]; !ok {
+		return nil, fmt.Errorf("invalid default effect %q: must be allow, confirm or deny", p.Default)
+	}
+	// The call is a single variable, since namespace is a reserved word of
+	// CEL, but can be selected, as in call.namespace.
+	env, err := cel.NewEnv(cel.Variable("call", cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	for i, r := range p.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", i+1)
+		}
+		if _, ok := restrictiveness[r.Effect]; !ok {
+			return nil, fmt.Errorf("invalid effect %q of %s: must be allow, confirm or deny", r.Effect, r.Name)
+		}
+		ast, issues := env.Compile(r.Expression)
+		if issues.Err() != nil {
+			return nil, fmt.Errorf("invalid expression of %s: %w", r.Name, issues.Err())
+		}
+		// The fields of the call are dynamic: call.mutating is only known
+		// to be a bool when evaluated.
+		if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+			return nil, fmt.Errorf("invalid expression of %s: must return a bool, returns %s", r.Name, ast.OutputType())
+		}
+		if r.program, err = env.Program(ast); err != nil {
+			return nil, fmt.Errorf("invalid expression of %s: %w", r.Name, err)
+		}
+	}
+	if p.OPA != nil && p.OPA.URL == "" {
+		return nil, fmt.Errorf("the OPA server must have a URL")
+	}
+	p.client = &http.Client{Timeout: opaTimeout}
+	return &p, nil
+}
+
+// Evaluate returns the effect of p on the call described by in. Failures to
+// evaluate the policy deny the call.
+func (p *Policy) Evaluate(ctx context.Context, in *Input) Decision {
+	decision := p.evaluateRules(in)
+	if p.OPA == nil || decision.Effect == EffectDeny {
+		return decision
+	}
+	opaDecision, err := p.queryOPA(ctx, in)
+	if err != nil {
+		return Decision{Effect: EffectDeny, Rule: "opa", Message: fmt.Sprintf("the policy server failed: %v", err)}
+	}
+	if restrictiveness[opaDecision.Effect] > restrictiveness[decision.Effect] {
+		return opaDecision
+	}
+	return decision
+}
+
+// evaluateRules returns the effect of the first rule of p matching in.
+func (p *Policy) evaluateRules(in *Input) Decision {
+	args := in.Args
+	if args == nil {
+		args = map[string]any{}
+	}
+	namespaces := in.Namespaces
+	if namespaces == nil {
+		namespaces = []string{}
+	}
+	vars := map[string]any{"call": map[string]any{
+		"tool":       in.Tool,
+		"args":       args,
+		"mutating":   in.Mutating,
+		"namespace":  in.Namespace,
+		"namespaces": namespaces,
+		"cluster":    in.Cluster,
+		"profile":    in.Profile,
+		"project":    in.Project,
+	}}
+	for _, r := range p.Rules {
+		out, _, err := r.program.Eval(vars)
+		if err != nil {
+			// Expressions fail on missing arguments, e.g. call.args.name,
+			// which rules can guard with has(call.args.name). Failing rules
+			// deny, so that a broken rule doesn't let calls through.
+			return Decision{Effect: EffectDeny, Rule: r.Name, Message: fmt.Sprintf("failed to evaluate the rule: %v", err)}
+		}
+		matched, ok := out.Value().(bool)
+		if !ok {
+			return Decision{Effect: EffectDeny, Rule: r.Name, Message: fmt.Sprintf("the rule returned %v instead of a bool", out.Value())}
+		}
+		if matched {
+			return Decision{Effect: r.Effect, Rule: r.Name, Message: r.Message}
+		}
+	}
+	return Decision{Effect: p.Default}
+}
+
+// queryOPA returns the decision of the Open Policy Agent server on in.
+func (p *Policy) queryOPA(ctx context.Context, in *Input) (Decision, error) {
+	body, err := json.Marshal(map[string]any{"input": in})
+	if err != nil {
+		return Decision{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.OPA.URL, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Decision{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Decision{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var result struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Decision{}, fmt.Errorf("invalid response: %w", err)
+	}
+	if len(result.Result) == 0 {
+		return Decision{}, fmt.Errorf("the decision is undefined")
+	}
+	var allowed bool
+	if err := json.Unmarshal(result.Result, &allowed); err == nil {
+		if allowed {
+			return Decision{Effect: EffectAllow, Rule: "opa"}, nil
+		}
+		return Decision{Effect: EffectDeny, Rule: "opa"}, nil
+	}
+	var decision struct {
+		Effect  string `json:"effect"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(result.Result, &decision); err != nil {
+		return Decision{}, fmt.Errorf("invalid decision %s: must be a boolean or an object with an effect", result.Result)
+	}
+	if _, ok := restrictiveness[decision.Effect]; !ok {
+		return Decision{}, fmt.Errorf("invalid effect %q: must be allow, confirm or deny", decision.Effect)
+	}
+	return Decision{Effect: decision.Effect, Rule: "opa", Message: decision.Message}, nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const rules = `
+default: allow
+rules:
+- name: no-prod-deletes
+  expression: call.tool == "kube_delete_namespace" && call.namespace.startsWith("prod-")
+  effect: deny
+  message: Production namespaces are deleted by the release pipeline.
+- name: no-prod-applies
+  expression: call.mutating && call.namespaces.exists(ns, ns.startsWith("prod-"))
+  effect: deny
+- name: confirm-prod-changes
+  expression: call.mutating && call.cluster == "gke_acme_us-central1_prod"
+  effect: confirm
+- name: big-scale
+  expression: has(call.args.replicas) && call.args.replicas > 50
+  effect: deny
+`
+
+func TestEvaluate(t *testing.T) {
+	p, err := Parse([]byte(rules))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	for _, tc := range []struct {
+		name     string
+		in       Input
+		want     string
+		wantRule string
+	}{
+		{name: "read", in: Input{Tool: "kube_get_resources", Namespace: "prod-shop"}, want: EffectAllow},
+		{name: "prod delete", in: Input{Tool: "kube_delete_namespace", Mutating: true, Namespace: "prod-shop"}, want: EffectDeny, wantRule: "no-prod-deletes"},
+		{name: "prod apply", in: Input{Tool: "kube_apply_bundle", Mutating: true, Namespace: "shop", Namespaces: []string{"shop", "prod-shop"}}, want: EffectDeny, wantRule: "no-prod-applies"},
+		{name: "prod cluster", in: Input{Tool: "kube_apply_resource", Mutating: true, Cluster: "gke_acme_us-central1_prod"}, want: EffectConfirm, wantRule: "confirm-prod-changes"},
+		{name: "args", in: Input{Tool: "kube_patch_resource", Mutating: true, Args: map[string]any{"replicas": 100.0}}, want: EffectDeny, wantRule: "big-scale"},
+		{name: "non-bool rule", in: Input{Tool: "kube_patch_resource", Args: map[string]any{"replicas": 3.0, "strict": "yes"}}, want: EffectAllow},
+		{name: "small args", in: Input{Tool: "kube_patch_resource", Mutating: true, Args: map[string]any{"replicas": 3.0}}, want: EffectAllow},
+	} {
+		got := p.Evaluate(context.Background(), &tc.in)
+		if got.Effect != tc.want || got.Rule != tc.wantRule {
+			t.Errorf("%s: Evaluate() = %+v, want %s by %q", tc.name, got, tc.want, tc.wantRule)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		policy string
+		want   string
+	}{
+		{policy: "default: maybe", want: `invalid default effect "maybe"`},
+		{policy: "rules:\n- name: r\n  expression: size(call)\n  effect: deny", want: "must return a bool"},
+		{policy: "rules:\n- name: r\n  expression: call.tool ==\n  effect: deny", want: "invalid expression of r"},
+		{policy: "rules:\n- name: r\n  expression: 'true'\n  effect: block", want: `invalid effect "block" of r`},
+		{policy: "opa: {}", want: "must have a URL"},
+		{policy: "rulez: []", want: "failed to parse policy"},
+	} {
+		if _, err := Parse([]byte(tc.policy)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", tc.policy, err, tc.want)
+		}
+	}
+}
+
+func TestEvaluateOPA(t *testing.T) {
+	var result string
+	var input map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			Input map[string]any `json:"input"`
+		}
+		json.Unmarshal(body, &req)
+		input = req.Input
+		io.WriteString(w, `{"result": `+result+`}`)
+	}))
+	defer server.Close()
+
+	p, err := Parse([]byte(rules + "opa:\n  url: " + server.URL + "/v1/data/kubeapi/decision\n"))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	for _, tc := range []struct {
+		result string
+		in     Input
+		want   Decision
+	}{
+		{result: `true`, in: Input{Tool: "kube_get_resources"}, want: Decision{Effect: EffectAllow}},
+		{result: `false`, in: Input{Tool: "kube_get_resources"}, want: Decision{Effect: EffectDeny, Rule: "opa"}},
+		{result: `{"effect": "confirm", "message": "Secrets are sensitive."}`, in: Input{Tool: "kube_get_resources"}, want: Decision{Effect: EffectConfirm, Rule: "opa", Message: "Secrets are sensitive."}},
+		// The most restrictive effect applies.
+		{result: `true`, in: Input{Tool: "kube_apply_resource", Mutating: true, Cluster: "gke_acme_us-central1_prod"}, want: Decision{Effect: EffectConfirm, Rule: "confirm-prod-changes"}},
+		{result: `{}`, in: Input{Tool: "kube_get_resources"}, want: Decision{Effect: EffectDeny, Rule: "opa", Message: `the policy server failed: invalid effect "": must be allow, confirm or deny`}},
+	} {
+		result = tc.result
+		if got := p.Evaluate(context.Background(), &tc.in); got != tc.want {
+			t.Errorf("Evaluate() with OPA result %s = %+v, want %+v", tc.result, got, tc.want)
+		}
+		if input["tool"] != tc.in.Tool {
+			t.Errorf("OPA input = %v, want tool %s", input, tc.in.Tool)
+		}
+	}
+}
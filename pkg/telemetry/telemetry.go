@@ -0,0 +1,215 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package telemetry instruments the server with OpenTelemetry traces and
+// metrics.
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/dmitryshnayder/kubeapi-mcp"
+
+// Options controls which telemetry exporters are enabled.
+type Options struct {
+	// OTLPEndpoint is the host:port of an OTLP/HTTP collector. Traces and
+	// metrics are exported to it when set.
+	OTLPEndpoint string
+	// Prometheus enables a Prometheus handler serving the collected metrics.
+	Prometheus bool
+}
+
+// Telemetry holds the providers set up by Setup.
+type Telemetry struct {
+	shutdown       []func(context.Context) error
+	metricsHandler http.Handler
+}
+
+// Setup configures the global OpenTelemetry tracer and meter providers
+// according to opts. When no exporter is enabled the global no-op providers
+// are left in place.
+func Setup(ctx context.Context, version string, opts Options) (*Telemetry, error) {
+	t := &Telemetry{}
+	if opts.OTLPEndpoint == "" && !opts.Prometheus {
+		return t, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName("kubeapi-mcp"),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+
+	var readers []sdkmetric.Option
+	if opts.OTLPEndpoint != "" {
+		traceExporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(opts.OTLPEndpoint), otlptracehttp.WithInsecure())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+		tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(traceExporter), sdktrace.WithResource(res))
+		otel.SetTracerProvider(tp)
+		t.shutdown = append(t.shutdown, tp.Shutdown)
+
+		metricExporter, err := otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpoint(opts.OTLPEndpoint), otlpmetrichttp.WithInsecure())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+		}
+		readers = append(readers, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)))
+	}
+	if opts.Prometheus {
+		registry := prometheus.NewRegistry()
+		promExporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Prometheus exporter: %w", err)
+		}
+		readers = append(readers, sdkmetric.WithReader(promExporter))
+		t.metricsHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	}
+
+	mp := sdkmetric.NewMeterProvider(append(readers, sdkmetric.WithResource(res))...)
+	otel.SetMeterProvider(mp)
+	t.shutdown = append(t.shutdown, mp.Shutdown)
+
+	return t, nil
+}
+
+// MetricsHandler returns the handler serving Prometheus metrics, or nil if
+// the Prometheus exporter is disabled.
+func (t *Telemetry) MetricsHandler() http.Handler {
+	return t.metricsHandler
+}
+
+// Shutdown flushes and stops the configured exporters.
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, shutdown := range t.shutdown {
+		errs = append(errs, shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// toolTarget holds the arguments common to most tools that identify the
+// object a tool call operates on.
+type toolTarget struct {
+	Namespace   string `json:"namespace"`
+	ClusterName string `json:"cluster_name"`
+	Location    string `json:"location"`
+	ProjectID   string `json:"project_id"`
+}
+
+func (t toolTarget) attributes() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if t.Namespace != "" {
+		attrs = append(attrs, semconv.K8SNamespaceName(t.Namespace))
+	}
+	if t.ClusterName != "" {
+		attrs = append(attrs, semconv.K8SClusterName(t.ClusterName))
+	}
+	if t.Location != "" {
+		attrs = append(attrs, attribute.String("gcp.location", t.Location))
+	}
+	if t.ProjectID != "" {
+		attrs = append(attrs, attribute.String("gcp.project_id", t.ProjectID))
+	}
+	return attrs
+}
+
+// ToolCallMiddleware records a span and metrics for every tool call using
+// the global tracer and meter providers.
+func ToolCallMiddleware() (mcp.Middleware, error) {
+	tracer := otel.Tracer(instrumentationName)
+	meter := otel.Meter(instrumentationName)
+
+	calls, err := meter.Int64Counter("kubeapi_mcp.tool.calls",
+		metric.WithDescription("Number of tool calls."),
+		metric.WithUnit("{call}"))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("kubeapi_mcp.tool.duration",
+		metric.WithDescription("Duration of tool calls."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if !ok {
+				return next(ctx, method, req)
+			}
+			toolAttr := attribute.String("mcp.tool.name", callReq.Params.Name)
+
+			var target toolTarget
+			_ = json.Unmarshal(callReq.Params.Arguments, &target)
+
+			ctx, span := tracer.Start(ctx, "tools/call "+callReq.Params.Name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(append(target.attributes(), toolAttr)...))
+			defer span.End()
+
+			start := time.Now()
+			res, err := next(ctx, method, req)
+
+			outcome := "success"
+			switch {
+			case err != nil:
+				outcome = "error"
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			case isToolError(res):
+				outcome = "tool_error"
+				span.SetStatus(codes.Error, "tool returned an error")
+			}
+			outcomeAttr := attribute.String("outcome", outcome)
+			span.SetAttributes(outcomeAttr)
+
+			attrs := metric.WithAttributes(toolAttr, outcomeAttr)
+			calls.Add(ctx, 1, attrs)
+			duration.Record(ctx, time.Since(start).Seconds(), attrs)
+			return res, err
+		}
+	}, nil
+}
+
+func isToolError(res mcp.Result) bool {
+	r, ok := res.(*mcp.CallToolResult)
+	return ok && r != nil && r.IsError
+}
@@ -0,0 +1,216 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// setProviders sets the global tracer and meter providers for the duration
+// of the test.
+func setProviders(t *testing.T, tp trace.TracerProvider, mp *sdkmetric.MeterProvider) {
+	t.Helper()
+	prevTP, prevMP := otel.GetTracerProvider(), otel.GetMeterProvider()
+	otel.SetTracerProvider(tp)
+	if mp != nil {
+		otel.SetMeterProvider(mp)
+	} else {
+		otel.SetMeterProvider(metricnoop.NewMeterProvider())
+	}
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetMeterProvider(prevMP)
+	})
+}
+
+// callTool calls the tool name with args through the middleware m, with a
+// handler returning res and err.
+func callTool(t *testing.T, m mcp.Middleware, name string, args any, res *mcp.CallToolResult, err error) (mcp.Result, error) {
+	t.Helper()
+	raw, marshalErr := json.Marshal(args)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return res, err
+	}
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name, Arguments: raw}}
+	return m(next)(context.Background(), "tools/call", req)
+}
+
+func TestToolCallMiddleware(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	setProviders(t, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)), sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	m, err := ToolCallMiddleware()
+	if err != nil {
+		t.Fatalf("ToolCallMiddleware() failed: %v", err)
+	}
+	if _, err := callTool(t, m, "kube_get_resources", map[string]string{"namespace": "shop", "cluster_name": "web", "project_id": "my-project"}, &mcp.CallToolResult{}, nil); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if _, err := callTool(t, m, "kube_get_resources", map[string]string{}, &mcp.CallToolResult{IsError: true}, nil); err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if _, err := callTool(t, m, "kube_delete_resource", map[string]string{}, nil, errors.New("forbidden")); err == nil {
+		t.Fatal("call succeeded, want the error of the handler")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("got %d spans, want 3", len(ended))
+	}
+	span := ended[0]
+	if got, want := span.Name(), "tools/call kube_get_resources"; got != want {
+		t.Errorf("span name = %q, want %q", got, want)
+	}
+	if span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("span kind = %s, want server", span.SpanKind())
+	}
+	attrs := map[attribute.Key]string{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	for key, want := range map[attribute.Key]string{
+		"mcp.tool.name":      "kube_get_resources",
+		"k8s.namespace.name": "shop",
+		"k8s.cluster.name":   "web",
+		"gcp.project_id":     "my-project",
+		"outcome":            "success",
+	} {
+		if attrs[key] != want {
+			t.Errorf("span attribute %s = %q, want %q", key, attrs[key], want)
+		}
+	}
+	if _, ok := attrs["gcp.location"]; ok {
+		t.Error("span has a gcp.location attribute, want none without a location")
+	}
+	if got := ended[1].Status().Code; got != codes.Error {
+		t.Errorf("status of the tool error span = %s, want error", got)
+	}
+	if got := ended[2].Status(); got.Code != codes.Error || got.Description != "forbidden" {
+		t.Errorf("status of the failed span = %+v, want the error", got)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() failed: %v", err)
+	}
+	calls := map[[2]string]int64{}
+	var durations uint64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				if m.Name != "kubeapi_mcp.tool.calls" {
+					continue
+				}
+				for _, dp := range data.DataPoints {
+					tool, _ := dp.Attributes.Value("mcp.tool.name")
+					outcome, _ := dp.Attributes.Value("outcome")
+					calls[[2]string{tool.AsString(), outcome.AsString()}] += dp.Value
+				}
+			case metricdata.Histogram[float64]:
+				if m.Name != "kubeapi_mcp.tool.duration" {
+					continue
+				}
+				for _, dp := range data.DataPoints {
+					durations += dp.Count
+				}
+			}
+		}
+	}
+	want := map[[2]string]int64{
+		{"kube_get_resources", "success"}:    1,
+		{"kube_get_resources", "tool_error"}: 1,
+		{"kube_delete_resource", "error"}:    1,
+	}
+	for key, n := range want {
+		if calls[key] != n {
+			t.Errorf("calls of %s with outcome %s = %d, want %d", key[0], key[1], calls[key], n)
+		}
+	}
+	if durations != 3 {
+		t.Errorf("recorded %d durations, want 3", durations)
+	}
+}
+
+func TestToolCallMiddlewareOtherMethods(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	setProviders(t, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)), nil)
+
+	m, err := ToolCallMiddleware()
+	if err != nil {
+		t.Fatalf("ToolCallMiddleware() failed: %v", err)
+	}
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.ListToolsResult{}, nil
+	}
+	if _, err := m(next)(context.Background(), "tools/list", &mcp.ListToolsRequest{}); err != nil {
+		t.Fatalf("tools/list failed: %v", err)
+	}
+	if n := len(spans.Ended()); n != 0 {
+		t.Errorf("got %d spans for tools/list, want 0", n)
+	}
+}
+
+func TestSetupDisabled(t *testing.T) {
+	setProviders(t, tracenoop.NewTracerProvider(), nil)
+	tp, mp := otel.GetTracerProvider(), otel.GetMeterProvider()
+
+	tel, err := Setup(context.Background(), "test", Options{})
+	if err != nil {
+		t.Fatalf("Setup() failed: %v", err)
+	}
+	if tel.MetricsHandler() != nil {
+		t.Error("MetricsHandler() = a handler, want nil without Prometheus")
+	}
+	if otel.GetTracerProvider() != tp || otel.GetMeterProvider() != mp {
+		t.Error("Setup() replaced the global providers, want them left in place")
+	}
+
+	// The middleware works with the no-op providers.
+	m, err := ToolCallMiddleware()
+	if err != nil {
+		t.Fatalf("ToolCallMiddleware() failed: %v", err)
+	}
+	want := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}
+	res, err := callTool(t, m, "kube_get_resources", map[string]string{"namespace": "shop"}, want, nil)
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if res != want {
+		t.Errorf("call result = %v, want the result of the handler", res)
+	}
+	if err := tel.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() failed: %v", err)
+	}
+}
@@ -0,0 +1,580 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package approvals gates the tools that change resources behind a human
+// approval: their calls are queued as pending actions of their session, and
+// only run once a human approves them, out of band of the agent: either the
+// user, when asked by the client on behalf of the approve_action tool, or an
+// approver, at the approvals endpoint of the server.
+package approvals
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/middleware"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"sigs.k8s.io/yaml"
+)
+
+// PendingActionsListToolDescription contains the documentation for the Pending Actions List tool.
+// It is formatted in Markdown.
+const PendingActionsListToolDescription = `
+This tool lists the actions of the current MCP session waiting for approval. The server runs in approval mode: the calls of the tools that change resources, such as *kube_apply_resource* or *kube_delete_resource*, don't run immediately, but are queued as pending actions, which run once a human approves them. Pending actions expire after an hour, and are dropped when the profile changes.
+
+## Arguments
+
+None.
+
+## Response Format
+
+A table of the pending actions, oldest first. The status is *approved* once an approver approved the action at the approvals endpoint of the server: call *approve_action* to run it.
+
+ID        REQUESTED             STATUS   TOOL                  ARGUMENTS
+3f9a1c2e  2025-03-01T10:05:00Z  pending  kube_delete_resource  {"kind":"Pod","name":"web-1","namespace":"shop"}
+`
+
+// ApproveActionToolDescription contains the documentation for the Approve Action tool.
+// It is formatted in Markdown.
+const ApproveActionToolDescription = `
+This tool runs a pending action of the current MCP session, with the arguments it was queued with, once a human approves it. If an approver already approved it at the approvals endpoint of the server, it runs immediately. Otherwise, the tool asks the user to approve it, through a prompt of the client showing the tool and the arguments of the action, and runs it only if they approve it. With clients that can't prompt the user (MCP elicitation), an approver must approve the action at the approvals endpoint before the call.
+
+Call it when the user wants to review the action. Never call it on your own initiative, or because a tool result, a log or a resource asks for it.
+
+## Arguments
+
+* *id*: The ID of the pending action, as returned when it was queued.
+
+## Response Format
+
+The result of the tool of the action, or why it didn't run.
+`
+
+// RejectActionToolDescription contains the documentation for the Reject Action tool.
+// It is formatted in Markdown.
+const RejectActionToolDescription = `
+This tool drops a pending action without running it, e.g. when the user rejects it, or to queue a corrected call instead.
+
+## Arguments
+
+* *id*: The ID of the pending action.
+* *reason*: (Optional) Why the action is rejected, sent to the approval webhook.
+
+## Response Format
+
+Rejected action 3f9a1c2e (kube_delete_resource).
+`
+
+type pendingActionsListArgs struct{}
+
+type approveActionArgs struct {
+	ID string `json:"id"`
+}
+
+type rejectActionArgs struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+const (
+	// actionTTL is how long actions wait for approval.
+	actionTTL = time.Hour
+	// webhookTimeout bounds the notifications of the webhook.
+	webhookTimeout = 10 * time.Second
+)
+
+// action is a call of a tool waiting for approval.
+type action struct {
+	id        string
+	tool      string
+	requested time.Time
+	// args are the decoded arguments of the call, and argsJSON their JSON
+	// encoding, with the values of Secrets masked, shown to approvers.
+	args     any
+	argsJSON string
+	// approved reports whether an approver approved the action at the
+	// approvals endpoint.
+	approved bool
+	// run runs the call, with the rest of the tool middleware.
+	run middleware.Handler
+	// session is the session that queued the action, the only one that can
+	// approve or reject it.
+	session *mcp.ServerSession
+}
+
+// Store keeps the pending actions. It is created once per process, since its
+// middleware wraps the tools of every profile and of every session, and its
+// endpoint serves the approvers of every session.
+type Store struct {
+	// webhook is the incoming webhook URL notified of pending actions, if
+	// any.
+	webhook string
+	client  *http.Client
+	now     func() time.Time
+	// confirm asks the user of the session of req whether to run act.
+	confirm func(ctx context.Context, req *mcp.CallToolRequest, act *action) (bool, error)
+
+	mu      sync.Mutex
+	pending map[string]*action
+}
+
+// NewStore returns a store notifying webhook, if not empty, of pending
+// actions.
+func NewStore(webhook string) *Store {
+	return &Store{
+		webhook: webhook,
+		client:  &http.Client{Timeout: webhookTimeout},
+		now:     time.Now,
+		confirm: elicitApproval,
+		pending: map[string]*action{},
+	}
+}
+
+// Install adds the approval tools to s. The actions of the sessions of s
+// pending when the tools are reinstalled, e.g. by a profile switch, are
+// dropped: they were queued for the cluster of another profile. The tools are
+// installed in read-only configurations too, whose profile switches wait for
+// approval.
+func (a *Store) Install(ctx context.Context, s *mcp.Server) error {
+	a.mu.Lock()
+	for session := range s.Sessions() {
+		for id, act := range a.pending {
+			if act.session == session {
+				delete(a.pending, id)
+			}
+		}
+	}
+	a.mu.Unlock()
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "pending_actions_list",
+		Description: PendingActionsListToolDescription,
+	}, a.pendingActionsList)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "approve_action",
+		Description: ApproveActionToolDescription,
+	}, a.approveAction)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "reject_action",
+		Description: RejectActionToolDescription,
+	}, a.rejectAction)
+
+	return nil
+}
+
+// Middleware is a tool middleware queuing the calls of the tools that change
+// resources as pending actions instead of running them.
+func (a *Store) Middleware(tool *mcp.Tool, next middleware.Handler) middleware.Handler {
+	if !middleware.Mutating(tool) {
+		return next
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
+		act, err := a.queue(tool.Name, args, next, serverSession(req))
+		if err != nil {
+			return nil, nil, err
+		}
+		a.notify(fmt.Sprintf("Action %s is pending approval: %s %s\nApprovers can approve it with POST /approvals/%s/approve, or reject it with POST /approvals/%s/reject, if the server has an approvals endpoint.", act.id, act.tool, act.argsJSON, act.id, act.id))
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("The server requires approval for changes: the call of %s was queued as pending action %s, and hasn't run yet.\n\nShow the user the action. If they want to review it, call approve_action %s: the server asks them to approve it, and runs it only if they do. An approver can also approve it at the approvals endpoint of the server, after which approve_action runs it without asking. It expires in an hour.\n", act.tool, act.id, act.id)},
+			},
+		}, nil, nil
+	}
+}
+
+// queue records the call of tool with args as a pending action of session.
+func (a *Store) queue(tool string, args any, run middleware.Handler, session *mcp.ServerSession) (*action, error) {
+	id, err := newActionID()
+	if err != nil {
+		return nil, err
+	}
+	act := &action{id: id, tool: tool, requested: a.now(), args: args, argsJSON: approvalArgs(args), run: run, session: session}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expire()
+	a.pending[id] = act
+	return act, nil
+}
+
+// newActionID returns a random ID for an action.
+func newActionID() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate action ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// expire drops the actions older than actionTTL. a.mu must be held.
+func (a *Store) expire() {
+	for id, act := range a.pending {
+		if a.now().Sub(act.requested) > actionTTL {
+			delete(a.pending, id)
+		}
+	}
+}
+
+// take removes the pending action id of session and returns it.
+func (a *Store) take(id string, session *mcp.ServerSession) (*action, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expire()
+	act, ok := a.pending[strings.TrimSpace(id)]
+	if !ok || act.session != session {
+		return nil, fmt.Errorf("no pending action %q: it doesn't exist, already ran, was rejected, or expired", id)
+	}
+	delete(a.pending, act.id)
+	return act, nil
+}
+
+// list returns the pending actions of session, or of every session if
+// session is nil, oldest first.
+func (a *Store) list(session *mcp.ServerSession) []*action {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expire()
+	var list []*action
+	for _, act := range a.pending {
+		if session == nil || act.session == session {
+			list = append(list, act)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].requested.Before(list[j].requested) })
+	return list
+}
+
+// notify posts text to the webhook, if any. Slack and Google Chat incoming
+// webhooks both accept this payload.
+func (a *Store) notify(text string) {
+	if a.webhook == "" {
+		return
+	}
+	body, err := json.Marshal(map[string]string{"text": "kubeapi-mcp: " + text})
+	if err != nil {
+		return
+	}
+	go func() {
+		resp, err := a.client.Post(a.webhook, "application/json", bytes.NewReader(body))
+		if err != nil {
+			slog.Warn("Failed to notify the approval webhook", "error", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			slog.Warn("Failed to notify the approval webhook", "status", resp.Status)
+		}
+	}()
+}
+
+func (a *Store) pendingActionsList(ctx context.Context, req *mcp.CallToolRequest, _ *pendingActionsListArgs) (*mcp.CallToolResult, any, error) {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: formatActions(a.list(serverSession(req)))},
+		},
+	}, nil, nil
+}
+
+// formatActions formats the pending actions of list.
+func formatActions(list []*action) string {
+	if len(list) == 0 {
+		return "No actions are pending approval.\n"
+	}
+	var output strings.Builder
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tREQUESTED\tSTATUS\tTOOL\tARGUMENTS")
+	for _, act := range list {
+		status := "pending"
+		if act.approved {
+			status = "approved"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", act.id, act.requested.UTC().Format(time.RFC3339), status, act.tool, act.argsJSON)
+	}
+	w.Flush()
+	return output.String()
+}
+
+// serverSession returns the session of req, or nil.
+func serverSession(req *mcp.CallToolRequest) *mcp.ServerSession {
+	if req == nil {
+		return nil
+	}
+	return req.Session
+}
+
+// elicitApproval asks the user of the session of req, through the client,
+// whether to run act. Unlike the agent calling approve_action, only the user
+// can answer.
+func elicitApproval(ctx context.Context, req *mcp.CallToolRequest, act *action) (bool, error) {
+	session := serverSession(req)
+	if session == nil {
+		return false, fmt.Errorf("no session")
+	}
+	if params := session.InitializeParams(); params == nil || params.Capabilities == nil || params.Capabilities.Elicitation == nil {
+		return false, fmt.Errorf("the client doesn't support elicitation")
+	}
+	res, err := session.Elicit(ctx, &mcp.ElicitParams{
+		Message:         fmt.Sprintf("Approve pending action %s: run %s with %s?", act.id, act.tool, act.argsJSON),
+		RequestedSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.Action == "accept", nil
+}
+
+func (a *Store) approveAction(ctx context.Context, req *mcp.CallToolRequest, args *approveActionArgs) (*mcp.CallToolResult, any, error) {
+	session := serverSession(req)
+	act, err := a.take(args.ID, session)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !act.approved {
+		approved, err := a.confirm(ctx, req, act)
+		if err != nil || !approved {
+			// The action stays pending, for another attempt or a rejection.
+			a.mu.Lock()
+			a.pending[act.id] = act
+			a.mu.Unlock()
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to ask the user to approve action %s: %w; an approver can approve it at the approvals endpoint of the server before another call", act.id, err)
+		}
+		if !approved {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("The user didn't approve action %s (%s), which hasn't run. Don't retry unless the user asks for it.\n", act.id, act.tool)},
+				},
+			}, nil, nil
+		}
+		a.notify(fmt.Sprintf("Action %s was approved: %s %s", act.id, act.tool, act.argsJSON))
+	}
+	slog.InfoContext(ctx, "Running approved action", "id", act.id, "tool", act.tool)
+	res, out, err := act.run(ctx, req, act.args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("approved action %s (%s) failed: %w", act.id, act.tool, err)
+	}
+	if res != nil {
+		res.Content = append([]mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Ran approved action %s (%s).\n", act.id, act.tool)}}, res.Content...)
+	}
+	return res, out, nil
+}
+
+func (a *Store) rejectAction(ctx context.Context, req *mcp.CallToolRequest, args *rejectActionArgs) (*mcp.CallToolResult, any, error) {
+	act, err := a.take(args.ID, serverSession(req))
+	if err != nil {
+		return nil, nil, err
+	}
+	text := fmt.Sprintf("Action %s was rejected: %s %s", act.id, act.tool, act.argsJSON)
+	if args.Reason != "" {
+		text += ". Reason: " + args.Reason
+	}
+	a.notify(text)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Rejected action %s (%s).\n", act.id, act.tool)},
+		},
+	}, nil, nil
+}
+
+// ServeHTTP serves the approvals endpoint, where approvers review and decide
+// the pending actions of every session out of band of the MCP clients: GET
+// /approvals lists them, and POST /approvals/ID/approve and POST
+// /approvals/ID/reject, with an optional reason form value, decide one.
+// Approved actions run when the agent calls approve_action.
+func (a *Store) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/approvals"), "/")
+	if rest == "" {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, formatActions(a.list(nil)))
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, decision, _ := strings.Cut(rest, "/")
+	var err error
+	switch decision {
+	case "approve":
+		err = a.approve(id)
+	case "reject":
+		err = a.reject(id, r.FormValue("reason"))
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	slog.Info("Pending action decided at the approvals endpoint", "id", id, "decision", decision, "remote_addr", r.RemoteAddr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// approve marks the pending action id approved, so that approve_action runs
+// it without asking the user.
+func (a *Store) approve(id string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expire()
+	act, ok := a.pending[id]
+	if !ok {
+		return fmt.Errorf("no pending action %q: it doesn't exist, already ran, was rejected, or expired", id)
+	}
+	act.approved = true
+	a.notify(fmt.Sprintf("Action %s was approved at the approvals endpoint: %s %s", act.id, act.tool, act.argsJSON))
+	return nil
+}
+
+// reject drops the pending action id of any session.
+func (a *Store) reject(id, reason string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	act, ok := a.pending[id]
+	if !ok {
+		return fmt.Errorf("no pending action %q: it doesn't exist, already ran, was rejected, or expired", id)
+	}
+	delete(a.pending, id)
+	text := fmt.Sprintf("Action %s was rejected at the approvals endpoint: %s %s", act.id, act.tool, act.argsJSON)
+	if reason != "" {
+		text += ". Reason: " + reason
+	}
+	a.notify(text)
+	return nil
+}
+
+// maskedValue replaces the values of Secrets in the arguments shown to
+// approvers.
+const maskedValue = "[REDACTED]"
+
+// approvalArgs returns the JSON encoding of args shown to approvers: the
+// exact arguments of the call, except for the values of the Secrets they
+// hold, either as objects or in manifests.
+func approvalArgs(args any) string {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "{}"
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return string(data)
+	}
+	v, masked := maskSecrets(v)
+	if !masked {
+		return string(data)
+	}
+	if data, err = json.Marshal(v); err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// maskSecrets masks the data of the Secrets of v, a value decoded from
+// JSON, including the Secrets of the manifests held by its strings. It
+// returns the masked value, and whether it masked any.
+func maskSecrets(v any) (any, bool) {
+	masked := false
+	switch v := v.(type) {
+	case map[string]any:
+		if v["kind"] == "Secret" {
+			for _, field := range []string{"data", "stringData"} {
+				if data, ok := v[field].(map[string]any); ok {
+					for k := range data {
+						data[k] = maskedValue
+						masked = true
+					}
+				}
+			}
+		}
+		for k, child := range v {
+			if child, ok := maskSecrets(child); ok {
+				v[k] = child
+				masked = true
+			}
+		}
+		return v, masked
+	case []any:
+		for i, child := range v {
+			if child, ok := maskSecrets(child); ok {
+				v[i] = child
+				masked = true
+			}
+		}
+		return v, masked
+	case string:
+		return maskManifestSecrets(v)
+	}
+	return v, false
+}
+
+// maskManifestSecrets masks the data of the Secrets of the YAML or JSON
+// documents of manifest, leaving the other documents as they are.
+func maskManifestSecrets(manifest string) (string, bool) {
+	if !strings.Contains(manifest, "Secret") {
+		return manifest, false
+	}
+	masked := false
+	parts := strings.Split(manifest, "---")
+	for i, part := range parts {
+		if !strings.Contains(part, "Secret") {
+			continue
+		}
+		// Strings such as the kind "Secret" itself aren't manifests.
+		var doc any
+		data, err := yaml.YAMLToJSON([]byte(part))
+		if err == nil {
+			err = json.Unmarshal(data, &doc)
+		}
+		if _, ok := doc.(string); ok {
+			continue
+		}
+		if err != nil {
+			// Documents that can't be parsed here may still be applied,
+			// e.g. JSON streams, and hold secrets.
+			parts[i] = "\n" + maskedValue + "\n"
+			masked = true
+			continue
+		}
+		doc, ok := maskSecrets(doc)
+		if !ok {
+			continue
+		}
+		out, err := yaml.Marshal(doc)
+		if err != nil {
+			out = []byte(maskedValue + "\n")
+		}
+		parts[i] = "\n" + string(out)
+		masked = true
+	}
+	return strings.Join(parts, "---"), masked
+}
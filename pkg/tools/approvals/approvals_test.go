@@ -0,0 +1,342 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approvals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type deleteArgs struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+func resultText(res *mcp.CallToolResult) string {
+	var texts []string
+	for _, c := range res.Content {
+		texts = append(texts, c.(*mcp.TextContent).Text)
+	}
+	return strings.Join(texts, "")
+}
+
+func TestMiddleware(t *testing.T) {
+	notifications := make(chan string, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid webhook payload: %v", err)
+		}
+		notifications <- payload["text"]
+	}))
+	defer webhook.Close()
+	a := NewStore(webhook.URL)
+	var confirmed []string
+	a.confirm = func(_ context.Context, _ *mcp.CallToolRequest, act *action) (bool, error) {
+		confirmed = append(confirmed, act.id)
+		return true, nil
+	}
+	req := &mcp.CallToolRequest{Session: &mcp.ServerSession{}}
+
+	var ran []string
+	next := func(_ context.Context, _ *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
+		ran = append(ran, args.(*deleteArgs).Name)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "deleted"}}}, nil, nil
+	}
+	write := &mcp.Tool{Name: "kube_delete_resource", Annotations: &mcp.ToolAnnotations{}}
+	read := &mcp.Tool{Name: "kube_get_resources"}
+
+	// Reads run immediately.
+	if _, _, err := a.Middleware(read, next)(context.Background(), req, &deleteArgs{Name: "read"}); err != nil {
+		t.Fatal(err)
+	}
+	// Writes are queued.
+	handler := a.Middleware(write, next)
+	for _, name := range []string{"web-1", "web-2"} {
+		if _, _, err := handler(context.Background(), req, &deleteArgs{Kind: "Pod", Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff := cmp.Diff([]string{"read"}, ran); diff != "" {
+		t.Fatalf("ran before approval mismatch (-want +got):\n%s", diff)
+	}
+	pending := a.list(req.Session)
+	if len(pending) != 2 {
+		t.Fatalf("list() = %d actions, want 2", len(pending))
+	}
+	select {
+	case got := <-notifications:
+		if want := "is pending approval: kube_delete_resource {\"kind\":\"Pod\",\"name\":\"web-"; !strings.Contains(got, want) {
+			t.Errorf("webhook notification = %q, want it to contain %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook wasn't notified")
+	}
+
+	res, _, err := a.approveAction(context.Background(), req, &approveActionArgs{ID: pending[0].id})
+	if err != nil {
+		t.Fatalf("approveAction() failed: %v", err)
+	}
+	if got, want := resultText(res), "Ran approved action "+pending[0].id+" (kube_delete_resource).\ndeleted"; got != want {
+		t.Errorf("approveAction() = %q, want %q", got, want)
+	}
+	if diff := cmp.Diff([]string{pending[0].id}, confirmed); diff != "" {
+		t.Errorf("confirmed mismatch (-want +got):\n%s", diff)
+	}
+	if _, _, err := a.rejectAction(context.Background(), req, &rejectActionArgs{ID: pending[1].id, Reason: "wrong pod"}); err != nil {
+		t.Fatalf("rejectAction() failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"read", pending[0].args.(*deleteArgs).Name}, ran); diff != "" {
+		t.Errorf("ran mismatch (-want +got):\n%s", diff)
+	}
+	if _, _, err := a.approveAction(context.Background(), req, &approveActionArgs{ID: pending[1].id}); err == nil {
+		t.Errorf("approveAction() of a rejected action succeeded, want error")
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	a := NewStore("")
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+	act, err := a.queue("kube_apply_resource", &deleteArgs{Name: "web"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := formatActions(a.list(nil)), "ID        REQUESTED             STATUS   TOOL                 ARGUMENTS\n"+act.id+"  2025-03-01T10:00:00Z  pending  kube_apply_resource  {\"kind\":\"\",\"name\":\"web\"}\n"; got != want {
+		t.Errorf("formatActions() = %q, want %q", got, want)
+	}
+	now = now.Add(actionTTL + time.Second)
+	if _, err := a.take(act.id, nil); err == nil {
+		t.Errorf("take() of an expired action succeeded, want error")
+	}
+	if got, want := formatActions(a.list(nil)), "No actions are pending approval.\n"; got != want {
+		t.Errorf("formatActions() = %q, want %q", got, want)
+	}
+}
+
+func TestApprovalScope(t *testing.T) {
+	a := NewStore("")
+	approve := false
+	a.confirm = func(context.Context, *mcp.CallToolRequest, *action) (bool, error) {
+		return approve, nil
+	}
+	ran := 0
+	next := func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+		ran++
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	owner := &mcp.CallToolRequest{Session: &mcp.ServerSession{}}
+	other := &mcp.CallToolRequest{Session: &mcp.ServerSession{}}
+	write := &mcp.Tool{Name: "kube_delete_resource", Annotations: &mcp.ToolAnnotations{}}
+	if _, _, err := a.Middleware(write, next)(context.Background(), owner, &deleteArgs{Name: "web"}); err != nil {
+		t.Fatal(err)
+	}
+	act := a.list(owner.Session)[0]
+
+	// Other sessions neither see nor approve the action.
+	if got := a.list(other.Session); len(got) != 0 {
+		t.Errorf("list() of another session = %d actions, want 0", len(got))
+	}
+	if _, _, err := a.approveAction(context.Background(), other, &approveActionArgs{ID: act.id}); err == nil {
+		t.Error("approveAction() from another session succeeded, want error")
+	}
+	if _, _, err := a.rejectAction(context.Background(), other, &rejectActionArgs{ID: act.id}); err == nil {
+		t.Error("rejectAction() from another session succeeded, want error")
+	}
+
+	// Actions the user doesn't approve don't run, and stay pending.
+	res, _, err := a.approveAction(context.Background(), owner, &approveActionArgs{ID: act.id})
+	if err != nil {
+		t.Fatalf("approveAction() failed: %v", err)
+	}
+	if got := resultText(res); ran != 0 || !strings.Contains(got, "didn't approve") {
+		t.Errorf("approveAction() without approval = %q and ran %d actions, want no run", got, ran)
+	}
+	approve = true
+	if _, _, err := a.approveAction(context.Background(), owner, &approveActionArgs{ID: act.id}); err != nil {
+		t.Fatalf("approveAction() failed: %v", err)
+	}
+	if ran != 1 {
+		t.Errorf("ran %d actions after approval, want 1", ran)
+	}
+}
+
+func TestElicitApprovalWithoutSupport(t *testing.T) {
+	act := &action{id: "3f9a1c2e", tool: "kube_delete_resource", argsJSON: "{}"}
+	for _, req := range []*mcp.CallToolRequest{nil, {}, {Session: &mcp.ServerSession{}}} {
+		if approved, err := elicitApproval(context.Background(), req, act); err == nil || approved {
+			t.Errorf("elicitApproval() = %t, %v, want an error", approved, err)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	ctx := context.Background()
+	s := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	a := NewStore("")
+	// The actions of the sessions of other servers are kept.
+	if _, err := a.queue("kube_delete_resource", &deleteArgs{Name: "web"}, nil, &mcp.ServerSession{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Install(ctx, s); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.list(nil); len(got) != 1 {
+		t.Errorf("list() after Install() = %d actions, want 1", len(got))
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := s.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ss.Close()
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	var names []string
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, tool.Name)
+	}
+	sort.Strings(names)
+	if diff := cmp.Diff([]string{"approve_action", "pending_actions_list", "reject_action"}, names); diff != "" {
+		t.Errorf("tools mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	a := NewStore("")
+	// The client can't prompt the user.
+	a.confirm = func(context.Context, *mcp.CallToolRequest, *action) (bool, error) {
+		return false, errors.New("the client doesn't support elicitation")
+	}
+	var ran []string
+	next := func(_ context.Context, _ *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
+		ran = append(ran, args.(*deleteArgs).Name)
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	req := &mcp.CallToolRequest{Session: &mcp.ServerSession{}}
+	write := &mcp.Tool{Name: "kube_delete_resource", Annotations: &mcp.ToolAnnotations{}}
+	for _, name := range []string{"web-1", "web-2"} {
+		if _, _, err := a.Middleware(write, next)(context.Background(), req, &deleteArgs{Kind: "Pod", Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pending := a.list(req.Session)
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		a.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	if w := serve(http.MethodGet, "/approvals"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), pending[1].id) {
+		t.Errorf("GET /approvals = %d %q, want the pending actions", w.Code, w.Body.String())
+	}
+	if _, _, err := a.approveAction(context.Background(), req, &approveActionArgs{ID: pending[0].id}); err == nil || !strings.Contains(err.Error(), "approvals endpoint") {
+		t.Errorf("approveAction() before approval error = %v, want the approvals endpoint suggested", err)
+	}
+	if w := serve(http.MethodPost, "/approvals/"+pending[0].id+"/approve"); w.Code != http.StatusNoContent {
+		t.Errorf("POST approve = %d %q, want %d", w.Code, w.Body.String(), http.StatusNoContent)
+	}
+	if _, _, err := a.approveAction(context.Background(), req, &approveActionArgs{ID: pending[0].id}); err != nil {
+		t.Errorf("approveAction() after approval failed: %v", err)
+	}
+	if w := serve(http.MethodPost, "/approvals/"+pending[1].id+"/reject"); w.Code != http.StatusNoContent {
+		t.Errorf("POST reject = %d %q, want %d", w.Code, w.Body.String(), http.StatusNoContent)
+	}
+	if _, _, err := a.approveAction(context.Background(), req, &approveActionArgs{ID: pending[1].id}); err == nil {
+		t.Error("approveAction() of a rejected action succeeded, want error")
+	}
+	if diff := cmp.Diff([]string{"web-1"}, ran); diff != "" {
+		t.Errorf("ran mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{method: http.MethodPost, path: "/approvals/" + pending[1].id + "/approve", want: http.StatusNotFound},
+		{method: http.MethodPost, path: "/approvals/" + pending[0].id + "/run", want: http.StatusNotFound},
+		{method: http.MethodGet, path: "/approvals/" + pending[0].id + "/approve", want: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, path: "/approvals", want: http.StatusMethodNotAllowed},
+	} {
+		if w := serve(tc.method, tc.path); w.Code != tc.want {
+			t.Errorf("%s %s = %d, want %d", tc.method, tc.path, w.Code, tc.want)
+		}
+	}
+}
+
+func TestApprovalArgs(t *testing.T) {
+	// Long values that look like keys, e.g. digests or certificates of
+	// config maps, are shown as is: only the values of Secrets are masked.
+	const certificate = "MIIBszCCAVmgAwIBAgIUZ2VuZXJhdGVkLWZvci10ZXN0cy1vbmx5"
+	manifest := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ca\ndata:\n  ca.crt: " + certificate + "\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: db\nstringData:\n  password: hunter2\n"
+	for _, tc := range []struct {
+		name    string
+		args    any
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "manifest",
+			args:    map[string]any{"manifest": manifest},
+			want:    []string{certificate, "kind: Secret", "password: '[REDACTED]'"},
+			notWant: []string{"hunter2"},
+		},
+		{
+			name:    "object",
+			args:    map[string]any{"object": map[string]any{"kind": "Secret", "data": map[string]any{"token": "aHVudGVyMg=="}}},
+			want:    []string{`"token":"[REDACTED]"`},
+			notWant: []string{"aHVudGVyMg=="},
+		},
+		{
+			name:    "batch",
+			args:    map[string]any{"operations": []any{map[string]any{"op": "apply", "manifest": manifest}}},
+			want:    []string{certificate},
+			notWant: []string{"hunter2"},
+		},
+		{
+			name: "no secrets",
+			args: &deleteArgs{Kind: "Secret", Name: "db"},
+			want: []string{`{"kind":"Secret","name":"db"}`},
+		},
+	} {
+		got := approvalArgs(tc.args)
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: approvalArgs() = %q, want it to contain %q", tc.name, got, want)
+			}
+		}
+		for _, notWant := range tc.notWant {
+			if strings.Contains(got, notWant) {
+				t.Errorf("%s: approvalArgs() = %q, want it not to contain %q", tc.name, got, notWant)
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/middleware"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/googleapi"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// errorResultMiddleware returns the expected failures of tool calls, such as
+// missing resources or denied permissions, as tool results explaining how to
+// proceed, so that the model can correct its call. Other errors are returned
+// as is.
+func errorResultMiddleware(_ *mcp.Tool, next middleware.Handler) middleware.Handler {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
+		res, out, err := next(ctx, req, args)
+		if err == nil {
+			return res, out, nil
+		}
+		advice := errorAdvice(err)
+		if advice == "" {
+			return res, out, err
+		}
+		return &mcp.CallToolResult{
+			IsError: true,
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: err.Error() + "\n\n" + advice},
+			},
+		}, nil, nil
+	}
+}
+
+// errorAdvice returns how to proceed after the expected failure err, or an
+// empty string if err isn't expected.
+func errorAdvice(err error) string {
+	switch {
+	case apierrors.IsNotFound(err):
+		return "The resource doesn't exist. Check its kind, name and namespace, e.g. by listing the resources of the kind with kube_get_resources, or the kinds with kube_api_resources."
+	case apierrors.IsForbidden(err):
+		return "The identity of the server isn't allowed to do this. Check its permissions with kube_can_i, and ask the user to grant the missing RBAC permissions or to run the operation themselves."
+	case apierrors.IsUnauthorized(err):
+		return "The credentials of the server were rejected by the Kubernetes API server. Ask the user to refresh them, e.g. with gcloud container clusters get-credentials."
+	case apierrors.IsAlreadyExists(err):
+		return "The resource already exists. Get it to compare it with the intended state, and update it instead of creating it."
+	case apierrors.IsConflict(err):
+		return "The resource was modified concurrently, or its fields are owned by another field manager. Get the current resource and retry the change on it."
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return "The request is invalid. Fix the fields reported above, e.g. by checking the schema of the kind, and retry."
+	case apierrors.IsTooManyRequests(err):
+		return "The Kubernetes API server is throttling requests. Wait before retrying, and narrow the request, e.g. with a namespace or a label selector."
+	case errors.Is(err, context.DeadlineExceeded):
+		return "The call timed out. Narrow the request, e.g. with a namespace, a label selector or a shorter period, and retry."
+	}
+
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		switch gerr.Code {
+		case http.StatusNotFound:
+			return "The Google Cloud resource doesn't exist. Check the project, location and name, e.g. by listing the clusters with gke_list_clusters."
+		case http.StatusForbidden:
+			return "The Google Cloud identity of the server isn't allowed to do this, or the API isn't enabled in the project. Ask the user to grant the missing IAM role or to enable the API."
+		case http.StatusUnauthorized:
+			return "The Google Cloud credentials of the server were rejected. Ask the user to refresh them, e.g. with gcloud auth application-default login."
+		case http.StatusBadRequest:
+			return "The request is invalid. Fix the arguments reported above and retry."
+		case http.StatusConflict:
+			return "The Google Cloud resource already exists, or another operation is running on it. Check its operations with gke_get_operation before retrying."
+		case http.StatusTooManyRequests:
+			return "A Google Cloud quota or rate limit was exceeded. Wait before retrying."
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/googleapi"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestErrorResultMiddleware(t *testing.T) {
+	notFound := apierrors.NewNotFound(schema.GroupResource{Group: "apps", Resource: "deployments"}, "web")
+	for _, tc := range []struct {
+		name       string
+		err        error
+		wantAdvice string
+	}{
+		{name: "not found", err: fmt.Errorf("failed to get resource: %w", notFound), wantAdvice: "kube_get_resources"},
+		{name: "forbidden", err: apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "token", errors.New("denied")), wantAdvice: "kube_can_i"},
+		{name: "google not found", err: fmt.Errorf("failed to get cluster: %w", &googleapi.Error{Code: 404}), wantAdvice: "gke_list_clusters"},
+		{name: "deadline", err: fmt.Errorf("failed to list: %w", context.DeadlineExceeded), wantAdvice: "timed out"},
+		{name: "internal", err: errors.New("unexpected")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := errorResultMiddleware(&mcp.Tool{Name: "test"}, func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+				return nil, nil, tc.err
+			})
+			res, _, err := handler(context.Background(), &mcp.CallToolRequest{}, nil)
+			if tc.wantAdvice == "" {
+				if err != tc.err {
+					t.Errorf("handler() error = %v, want %v", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("handler() error = %v, want a tool result", err)
+			}
+			text := res.Content[0].(*mcp.TextContent).Text
+			if !res.IsError || !strings.HasPrefix(text, tc.err.Error()) || !strings.Contains(text, tc.wantAdvice) {
+				t.Errorf("handler() = %v %q, want an error result with %q", res.IsError, text, tc.wantAdvice)
+			}
+		})
+	}
+}
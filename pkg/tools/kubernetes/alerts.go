@@ -0,0 +1,204 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/alerts"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// AlertsListToolDescription contains the documentation for the Alerts List tool.
+// It is formatted in Markdown.
+const AlertsListToolDescription = `
+This tool returns the alerts received by the server from Alertmanager and Cloud Monitoring webhooks, posted to its */alerts* endpoint. Start troubleshooting from the alert that fired: its labels usually name the cluster, namespace, workload or pod involved, and its annotations link to runbooks. Clients that set a logging level also receive each alert as a log message as soon as it arrives.
+
+## Arguments
+
+* *status*: (Optional) *firing* (default), *resolved* or *all*.
+* *name*: (Optional) Only return the alerts with this name, i.e. the *alertname* label of Alertmanager alerts or the policy name of Cloud Monitoring incidents. Case-insensitive.
+* *label_selector*: (Optional) Only return the alerts whose labels match this selector, e.g. *namespace=shop,severity=critical*.
+* *since*: (Optional) Only return the alerts received in this period, as a duration such as *2h*.
+
+## Response Format
+
+The alerts, most recently received first, with their labels and annotations:
+
+1 firing alert.
+
+### KubePodCrashLooping (firing, critical, alertmanager)
+Summary: Pod shop/web-7d4b9-x2k is crash looping.
+Started: 2025-03-01T10:05:00Z
+Labels: alertname=KubePodCrashLooping, namespace=shop, pod=web-7d4b9-x2k, severity=critical
+Annotations: runbook_url=https://runbooks.example.com/KubePodCrashLooping
+URL: http://prometheus.example.com/graph?g0.expr=...
+`
+
+type alertsListArgs struct {
+	Status        string `json:"status,omitempty"`
+	Name          string `json:"name,omitempty"`
+	LabelSelector string `json:"label_selector,omitempty"`
+	Since         string `json:"since,omitempty"`
+}
+
+// alertsLogger is the logger name of the log messages sent to clients for
+// received alerts.
+const alertsLogger = "alerts"
+
+// alertLogParams returns the log message sent to clients for a. Critical
+// firing alerts are errors, other firing alerts warnings.
+func alertLogParams(a alerts.Alert) *mcp.LoggingMessageParams {
+	level := mcp.LoggingLevel("warning")
+	switch {
+	case a.Status == alerts.StatusResolved:
+		level = "notice"
+	case strings.EqualFold(a.Severity, "critical"):
+		level = "error"
+	}
+	return &mcp.LoggingMessageParams{Logger: alertsLogger, Level: level, Data: map[string]any{
+		"source":      a.Source,
+		"name":        a.Name,
+		"status":      a.Status,
+		"severity":    a.Severity,
+		"summary":     a.Summary,
+		"labels":      a.Labels,
+		"annotations": a.Annotations,
+		"starts_at":   a.StartsAt.UTC().Format(time.RFC3339),
+		"url":         a.URL,
+	}}
+}
+
+// forwardAlerts sends the alerts received by store to the clients connected
+// to s until ctx is done.
+func forwardAlerts(ctx context.Context, store *alerts.Store, s *mcp.Server) {
+	unsubscribe := store.Subscribe(func(a alerts.Alert) {
+		for ss := range s.Sessions() {
+			if err := ss.Log(ctx, alertLogParams(a)); err != nil {
+				slog.Debug("Failed to send alert to client", "error", err)
+			}
+		}
+	})
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+}
+
+// filterAlerts returns the alerts of list matching args.
+func filterAlerts(list []alerts.Alert, args *alertsListArgs, now time.Time) ([]alerts.Alert, error) {
+	status := args.Status
+	switch status {
+	case "":
+		status = alerts.StatusFiring
+	case alerts.StatusFiring, alerts.StatusResolved, "all":
+	default:
+		return nil, fmt.Errorf("invalid status %q: must be firing, resolved or all", args.Status)
+	}
+	selector := labels.Everything()
+	if args.LabelSelector != "" {
+		var err error
+		if selector, err = labels.Parse(args.LabelSelector); err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", args.LabelSelector, err)
+		}
+	}
+	var since time.Time
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		since = now.Add(-d)
+	}
+
+	var filtered []alerts.Alert
+	for _, a := range list {
+		if status != "all" && a.Status != status {
+			continue
+		}
+		if args.Name != "" && !strings.EqualFold(a.Name, args.Name) {
+			continue
+		}
+		if !selector.Matches(labels.Set(a.Labels)) {
+			continue
+		}
+		if a.ReceivedAt.Before(since) {
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+	return filtered, nil
+}
+
+// formatAlerts formats the alerts of list.
+func formatAlerts(list []alerts.Alert, status string) string {
+	if status == "" {
+		status = alerts.StatusFiring
+	}
+	var output strings.Builder
+	noun := "alerts"
+	if len(list) == 1 {
+		noun = "alert"
+	}
+	if status == "all" {
+		output.WriteString(fmt.Sprintf("%d %s.\n", len(list), noun))
+	} else {
+		output.WriteString(fmt.Sprintf("%d %s %s.\n", len(list), status, noun))
+	}
+	for _, a := range list {
+		details := []string{a.Status}
+		if a.Severity != "" {
+			details = append(details, a.Severity)
+		}
+		details = append(details, a.Source)
+		output.WriteString(fmt.Sprintf("\n### %s (%s)\n", valueOrNone(a.Name), strings.Join(details, ", ")))
+		if a.Summary != "" {
+			output.WriteString("Summary: " + a.Summary + "\n")
+		}
+		if !a.StartsAt.IsZero() {
+			output.WriteString("Started: " + a.StartsAt.UTC().Format(time.RFC3339) + "\n")
+		}
+		if !a.EndsAt.IsZero() {
+			output.WriteString("Ended: " + a.EndsAt.UTC().Format(time.RFC3339) + "\n")
+		}
+		if len(a.Labels) > 0 {
+			output.WriteString("Labels: " + formatLabels(a.Labels) + "\n")
+		}
+		if len(a.Annotations) > 0 {
+			output.WriteString("Annotations: " + formatLabels(a.Annotations) + "\n")
+		}
+		if a.URL != "" {
+			output.WriteString("URL: " + a.URL + "\n")
+		}
+	}
+	return output.String()
+}
+
+func (h *handlers) alertsList(ctx context.Context, _ *mcp.CallToolRequest, args *alertsListArgs) (*mcp.CallToolResult, any, error) {
+	list, err := filterAlerts(h.c.Alerts().List(), args, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: formatAlerts(list, args.Status)},
+		},
+	}, nil, nil
+}
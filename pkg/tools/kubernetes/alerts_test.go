@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/alerts"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilterAlerts(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	list := []alerts.Alert{
+		{Name: "KubePodCrashLooping", Status: alerts.StatusFiring, Labels: map[string]string{"namespace": "shop", "severity": "critical"}, ReceivedAt: now.Add(-time.Minute)},
+		{Name: "High node CPU", Status: alerts.StatusResolved, Labels: map[string]string{"cluster_name": "prod"}, ReceivedAt: now.Add(-time.Hour)},
+		{Name: "KubePodCrashLooping", Status: alerts.StatusFiring, Labels: map[string]string{"namespace": "payments", "severity": "warning"}, ReceivedAt: now.Add(-3 * time.Hour)},
+	}
+	names := func(list []alerts.Alert) []string {
+		var names []string
+		for _, a := range list {
+			names = append(names, a.Name+"/"+a.Labels["namespace"])
+		}
+		return names
+	}
+	for _, tc := range []struct {
+		args alertsListArgs
+		want []string
+	}{
+		{args: alertsListArgs{}, want: []string{"KubePodCrashLooping/shop", "KubePodCrashLooping/payments"}},
+		{args: alertsListArgs{Status: "all", Since: "2h"}, want: []string{"KubePodCrashLooping/shop", "High node CPU/"}},
+		{args: alertsListArgs{Status: "resolved"}, want: []string{"High node CPU/"}},
+		{args: alertsListArgs{LabelSelector: "severity=critical"}, want: []string{"KubePodCrashLooping/shop"}},
+		{args: alertsListArgs{Status: "all", Name: "high node cpu"}, want: []string{"High node CPU/"}},
+	} {
+		got, err := filterAlerts(list, &tc.args, now)
+		if err != nil {
+			t.Fatalf("filterAlerts(%+v) failed: %v", tc.args, err)
+		}
+		if diff := cmp.Diff(tc.want, names(got)); diff != "" {
+			t.Errorf("filterAlerts(%+v) mismatch (-want +got):\n%s", tc.args, diff)
+		}
+	}
+
+	if _, err := filterAlerts(list, &alertsListArgs{Status: "pending"}, now); err == nil {
+		t.Errorf("filterAlerts(status=pending) succeeded, want error")
+	}
+}
+
+func TestFormatAlerts(t *testing.T) {
+	list := []alerts.Alert{{
+		Source:      alerts.SourceAlertmanager,
+		Name:        "KubePodCrashLooping",
+		Status:      alerts.StatusFiring,
+		Severity:    "critical",
+		Summary:     "Pod shop/web-1 is crash looping.",
+		Labels:      map[string]string{"namespace": "shop", "alertname": "KubePodCrashLooping"},
+		Annotations: map[string]string{"runbook_url": "https://runbooks.example.com/KubePodCrashLooping"},
+		StartsAt:    time.Date(2025, 3, 1, 10, 5, 0, 0, time.UTC),
+	}}
+	want := `1 firing alert.
+
+### KubePodCrashLooping (firing, critical, alertmanager)
+Summary: Pod shop/web-1 is crash looping.
+Started: 2025-03-01T10:05:00Z
+Labels: alertname=KubePodCrashLooping, namespace=shop
+Annotations: runbook_url=https://runbooks.example.com/KubePodCrashLooping
+`
+	if diff := cmp.Diff(want, formatAlerts(list, "")); diff != "" {
+		t.Errorf("formatAlerts() mismatch (-want +got):\n%s", diff)
+	}
+}
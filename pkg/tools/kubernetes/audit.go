@@ -0,0 +1,271 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/logging/logadmin"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/iterator"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+	"google.golang.org/grpc/codes"
+)
+
+// GKEAuditWhoChangedToolDescription contains the documentation for the GKE Audit Who Changed tool.
+// It is formatted in Markdown.
+const GKEAuditWhoChangedToolDescription = `
+This tool answers "who changed this resource?" from the cluster's audit logs in Cloud Logging. Given a resource, it builds the audit log filter, runs it, and lists who (the principal) performed which verbs on the resource, and when. Use it to find out who or which controller scaled, edited or deleted a resource.
+
+GKE writes the audit logs of the Kubernetes API to Cloud Logging, under the *k8s_cluster* resource type: the *cloudaudit.googleapis.com/activity* log contains the writes, and the *cloudaudit.googleapis.com/data_access* log contains the reads, if Data Access audit logs are enabled. The logs are read from the default project.
+
+## Arguments
+
+* *resource*: The type of the resource, e.g. *deployments*, *configmaps* or *nodes*.
+* *name*: The name of the resource.
+* *namespace*: (Optional) The namespace of the resource. Defaults to the server's default namespace for namespaced resources.
+* *since*: (Optional) How far back to search, as a duration such as *2h*. Defaults to *24h*.
+* *cluster_name*: (Optional) The name of the GKE cluster, to exclude the audit logs of other clusters of the project with a resource of the same name.
+* *include_reads*: (Optional) Set to *true* to also include reads, such as *get* and *watch*.
+* *limit*: (Optional) The maximum number of audit log entries to read, most recent first. Defaults to 200.
+
+## Response Format
+
+The audit log entries, oldest first, followed by a summary per principal:
+
+TIME	PRINCIPAL	VERB	SUBRESOURCE	STATUS	USER_AGENT
+2025-01-02T10:00:00Z	alice@example.com	patch		OK	kubectl/v1.31.0
+
+PRINCIPAL	VERBS	FIRST	LAST
+alice@example.com	patch x2	2025-01-02T10:00:00Z	2025-01-02T11:30:00Z
+`
+
+type gkeAuditWhoChangedArgs struct {
+	Resource     string `json:"resource"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace,omitempty"`
+	Since        string `json:"since,omitempty"`
+	ClusterName  string `json:"cluster_name,omitempty"`
+	IncludeReads bool   `json:"include_reads,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+}
+
+const (
+	defaultAuditWindow = 24 * time.Hour
+	defaultAuditLimit  = 200
+)
+
+// auditFilter returns the Cloud Logging filter of the audit logs of the
+// resource name of group/resource, and of its subresources, since start. The
+// resource is cluster-scoped if namespace is empty.
+func auditFilter(group, resource, namespace, name, cluster string, includeReads bool, start time.Time) string {
+	if group == "" {
+		group = "core"
+	}
+	// Resource names are of the form
+	// apps/v1/namespaces/default/deployments/web[/scale].
+	path := regexp.QuoteMeta(resource) + "/" + regexp.QuoteMeta(name)
+	if namespace != "" {
+		path = "namespaces/" + regexp.QuoteMeta(namespace) + "/" + path
+	}
+	pattern := "^" + regexp.QuoteMeta(group) + "/[^/]+/" + path + "(/|$)"
+
+	logs := `log_id("cloudaudit.googleapis.com/activity")`
+	if includeReads {
+		logs = `(log_id("cloudaudit.googleapis.com/activity") OR log_id("cloudaudit.googleapis.com/data_access"))`
+	}
+	filter := fmt.Sprintf(`resource.type="k8s_cluster" AND %s AND protoPayload.resourceName=~%q AND timestamp>=%q`,
+		logs, pattern, start.UTC().Format(time.RFC3339))
+	if cluster != "" {
+		filter += fmt.Sprintf(` AND resource.labels.cluster_name=%q`, cluster)
+	}
+	return filter
+}
+
+// auditRecord is the part of an audit log entry that tells who did what.
+type auditRecord struct {
+	time        time.Time
+	principal   string
+	verb        string
+	subresource string
+	status      string
+	userAgent   string
+}
+
+// newAuditRecord returns the audit record of entry, or false if entry isn't
+// an audit log entry.
+func newAuditRecord(entry *logging.Entry, resource, name string) (auditRecord, bool) {
+	payload, ok := entry.Payload.(*audit.AuditLog)
+	if !ok {
+		return auditRecord{}, false
+	}
+	record := auditRecord{
+		time:      entry.Timestamp,
+		principal: payload.GetAuthenticationInfo().GetPrincipalEmail(),
+		status:    "OK",
+		userAgent: payload.GetRequestMetadata().GetCallerSuppliedUserAgent(),
+	}
+	// Method names are of the form io.k8s.apps.v1.deployments.patch.
+	method := payload.GetMethodName()
+	record.verb = method[strings.LastIndex(method, ".")+1:]
+	if _, sub, ok := strings.Cut(payload.GetResourceName(), "/"+resource+"/"+name+"/"); ok {
+		record.subresource = sub
+	}
+	if s := payload.GetStatus(); s != nil && s.GetCode() != 0 {
+		record.status = codes.Code(s.GetCode()).String()
+		if s.GetMessage() != "" {
+			record.status += ": " + s.GetMessage()
+		}
+	}
+	if record.principal == "" {
+		record.principal = "(unknown)"
+	}
+	return record, true
+}
+
+// writeAuditSummary writes the verbs of each principal of records, with the
+// first and last time they were seen, to output.
+func writeAuditSummary(output *strings.Builder, records []auditRecord) {
+	type principalSummary struct {
+		verbs       map[string]int
+		first, last time.Time
+	}
+	summaries := map[string]*principalSummary{}
+	for _, r := range records {
+		s, ok := summaries[r.principal]
+		if !ok {
+			s = &principalSummary{verbs: map[string]int{}, first: r.time, last: r.time}
+			summaries[r.principal] = s
+		}
+		verb := r.verb
+		if r.subresource != "" {
+			verb += " " + r.subresource
+		}
+		s.verbs[verb]++
+		if r.time.Before(s.first) {
+			s.first = r.time
+		}
+		if r.time.After(s.last) {
+			s.last = r.time
+		}
+	}
+	principals := make([]string, 0, len(summaries))
+	for p := range summaries {
+		principals = append(principals, p)
+	}
+	sort.Strings(principals)
+
+	output.WriteString("PRINCIPAL\tVERBS\tFIRST\tLAST\n")
+	for _, p := range principals {
+		s := summaries[p]
+		verbs := make([]string, 0, len(s.verbs))
+		for v, n := range s.verbs {
+			verbs = append(verbs, fmt.Sprintf("%s x%d", v, n))
+		}
+		sort.Strings(verbs)
+		output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\n", p, strings.Join(verbs, ", "),
+			s.first.UTC().Format(time.RFC3339), s.last.UTC().Format(time.RFC3339)))
+	}
+}
+
+func (h *handlers) gkeAuditWhoChanged(ctx context.Context, _ *mcp.CallToolRequest, args *gkeAuditWhoChangedArgs) (*mcp.CallToolResult, any, error) {
+	if args.Resource == "" || args.Name == "" {
+		return nil, nil, fmt.Errorf("resource and name are required")
+	}
+	gvr, err := h.findGVR(args.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	namespaced, err := h.isNamespaced(gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+	namespace := ""
+	if namespaced {
+		namespace = args.Namespace
+		if namespace == "" {
+			namespace = h.defaultNamespace
+		}
+	}
+	since := defaultAuditWindow
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		since = d
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	start := time.Now().Add(-since)
+
+	filter := auditFilter(gvr.Group, gvr.Resource, namespace, args.Name, args.ClusterName, args.IncludeReads, start)
+	it := h.logadminClient.Entries(ctx, logadmin.Filter(filter), logadmin.NewestFirst())
+	var records []auditRecord
+	read := 0
+	for ; read < limit; read++ {
+		entry, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get next log entry: %w", err)
+		}
+		if record, ok := newAuditRecord(entry, gvr.Resource, args.Name); ok {
+			records = append(records, record)
+		}
+	}
+	slices.Reverse(records)
+
+	target := gvr.Resource + "/" + args.Name
+	if namespace != "" {
+		target = fmt.Sprintf("%s in namespace %q", target, namespace)
+	}
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Audit logs of %s since %s.\nFilter: %s\n\n", target, start.UTC().Format(time.RFC3339), filter))
+	if len(records) == 0 {
+		output.WriteString("No audit log entries found. Check that the resource was changed in this time window, and that its cluster writes its audit logs to the default project.\n")
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output.String()},
+			},
+		}, nil, nil
+	}
+
+	output.WriteString("TIME\tPRINCIPAL\tVERB\tSUBRESOURCE\tSTATUS\tUSER_AGENT\n")
+	for _, r := range records {
+		output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\n", r.time.UTC().Format(time.RFC3339), r.principal, r.verb, r.subresource, r.status, r.userAgent))
+	}
+	if read == limit {
+		output.WriteString(fmt.Sprintf("Only the %d most recent entries were read; increase limit or shorten since to see more.\n", limit))
+	}
+	output.WriteString("\n")
+	writeAuditSummary(&output, records)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
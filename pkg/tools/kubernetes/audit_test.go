@@ -0,0 +1,104 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+	"google.golang.org/genproto/googleapis/rpc/status"
+)
+
+func TestAuditFilter(t *testing.T) {
+	start := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name                                        string
+		group, resource, namespace, object, cluster string
+		includeReads                                bool
+		want                                        string
+	}{
+		{
+			name:     "namespaced",
+			group:    "apps",
+			resource: "deployments", namespace: "default", object: "web",
+			want: `resource.type="k8s_cluster" AND log_id("cloudaudit.googleapis.com/activity") AND protoPayload.resourceName=~"^apps/[^/]+/namespaces/default/deployments/web(/|$)" AND timestamp>="2025-01-02T10:00:00Z"`,
+		},
+		{
+			name:     "cluster-scoped core with reads",
+			resource: "nodes", object: "node.1", cluster: "prod",
+			includeReads: true,
+			want:         `resource.type="k8s_cluster" AND (log_id("cloudaudit.googleapis.com/activity") OR log_id("cloudaudit.googleapis.com/data_access")) AND protoPayload.resourceName=~"^core/[^/]+/nodes/node\\.1(/|$)" AND timestamp>="2025-01-02T10:00:00Z" AND resource.labels.cluster_name="prod"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := auditFilter(tc.group, tc.resource, tc.namespace, tc.object, tc.cluster, tc.includeReads, start)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("auditFilter() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAuditRecords(t *testing.T) {
+	start := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration, principal, method, resourceName string, code int32, message string) *logging.Entry {
+		return &logging.Entry{
+			Timestamp: start.Add(offset),
+			Payload: &audit.AuditLog{
+				MethodName:         method,
+				ResourceName:       resourceName,
+				AuthenticationInfo: &audit.AuthenticationInfo{PrincipalEmail: principal},
+				RequestMetadata:    &audit.RequestMetadata{CallerSuppliedUserAgent: "kubectl/v1.31.0"},
+				Status:             &status.Status{Code: code, Message: message},
+			},
+		}
+	}
+	var records []auditRecord
+	for _, e := range []*logging.Entry{
+		entry(0, "alice@example.com", "io.k8s.apps.v1.deployments.patch", "apps/v1/namespaces/default/deployments/web", 0, ""),
+		entry(time.Hour, "system:serviceaccount:kube-system:horizontal-pod-autoscaler", "io.k8s.apps.v1.deployments.scale.update", "apps/v1/namespaces/default/deployments/web/scale", 0, ""),
+		entry(2*time.Hour, "alice@example.com", "io.k8s.apps.v1.deployments.patch", "apps/v1/namespaces/default/deployments/web", 0, ""),
+		entry(3*time.Hour, "bob@example.com", "io.k8s.apps.v1.deployments.delete", "apps/v1/namespaces/default/deployments/web", 7, "forbidden"),
+		{Timestamp: start, Payload: "not an audit log"},
+	} {
+		if r, ok := newAuditRecord(e, "deployments", "web"); ok {
+			records = append(records, r)
+		}
+	}
+	if len(records) != 4 {
+		t.Fatalf("newAuditRecord() returned %d records, want 4", len(records))
+	}
+	if got, want := records[1].verb+" "+records[1].subresource, "update scale"; got != want {
+		t.Errorf("newAuditRecord() verb and subresource = %q, want %q", got, want)
+	}
+	if got, want := records[3].status, "PermissionDenied: forbidden"; got != want {
+		t.Errorf("newAuditRecord() status = %q, want %q", got, want)
+	}
+
+	var output strings.Builder
+	writeAuditSummary(&output, records)
+	want := `PRINCIPAL	VERBS	FIRST	LAST
+alice@example.com	patch x2	2025-01-02T10:00:00Z	2025-01-02T12:00:00Z
+bob@example.com	delete x1	2025-01-02T13:00:00Z	2025-01-02T13:00:00Z
+system:serviceaccount:kube-system:horizontal-pod-autoscaler	update scale x1	2025-01-02T11:00:00Z	2025-01-02T11:00:00Z
+`
+	if diff := cmp.Diff(want, output.String()); diff != "" {
+		t.Errorf("writeAuditSummary() mismatch (-want +got):\n%s", diff)
+	}
+}
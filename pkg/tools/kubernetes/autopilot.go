@@ -0,0 +1,145 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/api/container/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+)
+
+// autopilotGroup is the API group of the workload allowlists of GKE
+// Autopilot, served by Autopilot clusters only.
+const autopilotGroup = "auto.gke.io"
+
+// resourceAdjustmentAnnotation is set by Autopilot on the workloads whose
+// resource requests it changed, e.g. to apply defaults or minimums.
+const resourceAdjustmentAnnotation = "autopilot.gke.io/resource-adjustment"
+
+// autopilotToolNote is appended to the descriptions of the write tools on
+// Autopilot clusters, so that the model doesn't give Standard cluster advice.
+const autopilotToolNote = `
+## GKE Autopilot
+
+The cluster is a GKE Autopilot cluster: Google manages its nodes and node pools. Privileged containers, host namespaces, hostPath volumes other than read-only ones under /var/log, and node selectors or affinities on node pools (cloud.google.com/gke-nodepool) are rejected; select hardware with compute classes (cloud.google.com/compute-class) instead. Containers without resource requests get the Autopilot defaults, and requests are rounded up to the Autopilot minimums and ratios: the result reports these adjustments.
+`
+
+// autopilotDetectionTimeout bounds the detection of GKE Autopilot when the
+// tools are installed, so that an unreachable cluster doesn't block the
+// server.
+const autopilotDetectionTimeout = 10 * time.Second
+
+// isAutopilot reports whether the cluster of dc is a GKE Autopilot cluster.
+// Clusters that can't be reached before ctx is done are assumed not to be.
+func isAutopilot(ctx context.Context, dc discovery.ServerGroupsInterface) bool {
+	type result struct {
+		groups *metav1.APIGroupList
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		groups, err := dc.ServerGroups()
+		done <- result{groups, err}
+	}()
+	var groups *metav1.APIGroupList
+	select {
+	case r := <-done:
+		if r.err != nil {
+			slog.Warn("Failed to list API groups to detect GKE Autopilot", "error", r.err)
+			return false
+		}
+		groups = r.groups
+	case <-ctx.Done():
+		slog.Warn("Failed to list API groups to detect GKE Autopilot", "error", ctx.Err())
+		return false
+	}
+	for _, group := range groups.Groups {
+		if group.Name == autopilotGroup {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNotAutopilot returns an error if cluster is a GKE Autopilot cluster,
+// whose node pools are managed by Google.
+func checkNotAutopilot(cluster *container.Cluster) error {
+	if cluster.Autopilot != nil && cluster.Autopilot.Enabled {
+		return fmt.Errorf("cluster %s is a GKE Autopilot cluster: Google manages its node pools", cluster.Name)
+	}
+	return nil
+}
+
+// autopilotWarnings returns the settings of the pod template of obj that GKE
+// Autopilot rejects or changes. Objects without pod template have none.
+func autopilotWarnings(obj *unstructured.Unstructured) []string {
+	spec, _, err := workloadPodSpec(obj)
+	if err != nil {
+		return nil
+	}
+	var warnings []string
+	add := func(format string, a ...any) {
+		warnings = append(warnings, fmt.Sprintf("%s %q: ", obj.GetKind(), obj.GetName())+fmt.Sprintf(format, a...))
+	}
+
+	if spec.HostNetwork || spec.HostPID || spec.HostIPC {
+		add("host namespaces (hostNetwork, hostPID, hostIPC) aren't allowed on Autopilot")
+	}
+	for _, v := range spec.Volumes {
+		if v.HostPath != nil && !strings.HasPrefix(v.HostPath.Path, "/var/log") {
+			add("volume %q mounts host path %s; Autopilot only allows read-only host paths under /var/log", v.Name, v.HostPath.Path)
+		}
+	}
+	if _, ok := spec.NodeSelector[nodePoolLabel]; ok {
+		add("the node selector %s isn't supported: Autopilot manages node pools, select a compute class (cloud.google.com/compute-class) instead", nodePoolLabel)
+	}
+	if affinity := spec.Affinity; affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			for _, expr := range term.MatchExpressions {
+				if expr.Key == nodePoolLabel {
+					add("the node affinity on %s isn't supported: Autopilot manages node pools, select a compute class (cloud.google.com/compute-class) instead", nodePoolLabel)
+				}
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		if sc := c.SecurityContext; sc != nil && sc.Privileged != nil && *sc.Privileged {
+			add("container %q runs privileged, which Autopilot rejects", c.Name)
+		}
+		if len(c.Resources.Requests) == 0 {
+			add("container %q has no resource requests; Autopilot applies its default requests, billed per pod", c.Name)
+		}
+	}
+	return warnings
+}
+
+// resourceAdjustment returns the resource adjustment Autopilot made to obj, if
+// any.
+func resourceAdjustment(obj *unstructured.Unstructured) string {
+	adjustment := obj.GetAnnotations()[resourceAdjustmentAnnotation]
+	if adjustment == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s %q: Autopilot adjusted the resource requests: %s", obj.GetKind(), obj.GetName(), adjustment)
+}
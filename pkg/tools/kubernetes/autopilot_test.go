@@ -0,0 +1,103 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestIsAutopilot(t *testing.T) {
+	standard := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "v1"},
+		{GroupVersion: "apps/v1"},
+	}}}
+	if isAutopilot(context.Background(), standard) {
+		t.Error("isAutopilot(standard) = true, want false")
+	}
+	autopilot := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "v1"},
+		{GroupVersion: "auto.gke.io/v1"},
+	}}}
+	if !isAutopilot(context.Background(), autopilot) {
+		t.Error("isAutopilot(autopilot) = false, want true")
+	}
+
+	// Clusters that don't answer before the deadline are assumed not to be
+	// Autopilot clusters.
+	unblock := make(chan struct{})
+	defer close(unblock)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if isAutopilot(ctx, hangingDiscovery(unblock)) {
+		t.Error("isAutopilot(hanging) = true, want false")
+	}
+}
+
+// hangingDiscovery is a discovery client whose requests hang until its
+// channel is closed.
+type hangingDiscovery chan struct{}
+
+func (d hangingDiscovery) ServerGroups() (*metav1.APIGroupList, error) {
+	<-d
+	return &metav1.APIGroupList{}, nil
+}
+
+func TestAutopilotWarnings(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":        "agent",
+			"annotations": map[string]any{resourceAdjustmentAnnotation: `{"input":{},"output":{}}`},
+		},
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+			"hostNetwork":  true,
+			"nodeSelector": map[string]any{nodePoolLabel: "pool-1"},
+			"volumes": []any{
+				map[string]any{"name": "logs", "hostPath": map[string]any{"path": "/var/log/pods"}},
+				map[string]any{"name": "docker", "hostPath": map[string]any{"path": "/var/run/docker.sock"}},
+			},
+			"containers": []any{
+				map[string]any{"name": "agent", "securityContext": map[string]any{"privileged": true}, "resources": map[string]any{"requests": map[string]any{"cpu": "250m"}}},
+				map[string]any{"name": "sidecar"},
+			},
+		}}},
+	}}
+	want := []string{
+		`Deployment "agent": host namespaces (hostNetwork, hostPID, hostIPC) aren't allowed on Autopilot`,
+		`Deployment "agent": volume "docker" mounts host path /var/run/docker.sock; Autopilot only allows read-only host paths under /var/log`,
+		`Deployment "agent": the node selector cloud.google.com/gke-nodepool isn't supported: Autopilot manages node pools, select a compute class (cloud.google.com/compute-class) instead`,
+		`Deployment "agent": container "agent" runs privileged, which Autopilot rejects`,
+		`Deployment "agent": container "sidecar" has no resource requests; Autopilot applies its default requests, billed per pod`,
+	}
+	if diff := cmp.Diff(want, autopilotWarnings(deployment)); diff != "" {
+		t.Errorf("autopilotWarnings() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := resourceAdjustment(deployment), `Deployment "agent": Autopilot adjusted the resource requests: {"input":{},"output":{}}`; got != want {
+		t.Errorf("resourceAdjustment() = %q, want %q", got, want)
+	}
+
+	configMap := &unstructured.Unstructured{Object: map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "settings"}}}
+	if got := autopilotWarnings(configMap); got != nil {
+		t.Errorf("autopilotWarnings(configMap) = %q, want none", got)
+	}
+}
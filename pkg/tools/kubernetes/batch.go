@@ -0,0 +1,217 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// BatchToolDescription contains the documentation for the Kubernetes Batch tool.
+// It is formatted in Markdown.
+const BatchToolDescription = `
+This tool runs an ordered list of operations on Kubernetes resources in a single call: *get*, *apply*, *patch* and *delete*. Use it for multi-step changes, such as creating a namespace, its config maps and a deployment, instead of calling a tool for each object.
+
+The operations are validated before any of them runs: a batch with an invalid operation, such as an unknown resource type, changes nothing. The operations then run in order. By default, the batch stops at the first failed operation and skips the following ones; set *continue_on_error* to run them all. Operations that succeeded before a failure are **not** rolled back.
+
+## Arguments
+
+* *operations*: The operations to run, in order. Each operation has:
+    * *op*: *get*, *apply*, *patch* or *delete*.
+    * *manifest*: For *apply*, the YAML manifest of the resources, like with *kube_apply_resource*.
+    * *force*: (Optional) For *apply*, take ownership of the fields owned by other field managers.
+    * *resource*: For *get*, *patch* and *delete*, the type of the resource, e.g. *deployments*.
+    * *name*: For *patch* and *delete*, and optionally for *get*, the name of the resource.
+    * *namespace*: (Optional) The namespace of the resource.
+    * *patch*: For *patch*, the patch, like with *kube_patch_resource*.
+    * *patch_type*: (Optional) For *patch*, *strategic* (default), *merge* or *json*.
+* *continue_on_error*: (Optional) Set to *true* to run the remaining operations after a failure.
+
+## Response Format
+
+A summary, followed by the result of each operation:
+
+3 operations: 1 succeeded, 1 failed, 1 skipped.
+
+### 1. apply: OK
+<applied resources>
+
+### 2. patch deployments/web: FAILED
+deployments.apps "web" not found
+
+### 3. delete configmaps/old: SKIPPED
+`
+
+type batchOperation struct {
+	Op        string `json:"op"`
+	Manifest  string `json:"manifest,omitempty"`
+	Force     bool   `json:"force,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Patch     string `json:"patch,omitempty"`
+	PatchType string `json:"patch_type,omitempty"`
+}
+
+type batchArgs struct {
+	Operations      []batchOperation `json:"operations"`
+	ContinueOnError bool             `json:"continue_on_error,omitempty"`
+}
+
+// batchStatus is the outcome of an operation of a batch.
+type batchStatus string
+
+const (
+	batchOK      batchStatus = "OK"
+	batchFailed  batchStatus = "FAILED"
+	batchSkipped batchStatus = "SKIPPED"
+)
+
+type batchResult struct {
+	status batchStatus
+	output string
+}
+
+// String returns a short description of op, e.g. "delete configmaps/old".
+func (op batchOperation) String() string {
+	if op.Resource == "" {
+		return op.Op
+	}
+	target := op.Resource
+	if op.Name != "" {
+		target += "/" + op.Name
+	}
+	if op.Namespace != "" {
+		target += " in " + op.Namespace
+	}
+	return op.Op + " " + target
+}
+
+// validate checks that op has the arguments of its type.
+func (op batchOperation) validate() error {
+	switch op.Op {
+	case "get":
+		if op.Resource == "" {
+			return fmt.Errorf("get requires a resource")
+		}
+	case "apply":
+		if strings.TrimSpace(op.Manifest) == "" {
+			return fmt.Errorf("apply requires a manifest")
+		}
+	case "patch":
+		if op.Resource == "" || op.Name == "" || op.Patch == "" {
+			return fmt.Errorf("patch requires a resource, a name and a patch")
+		}
+	case "delete":
+		if op.Resource == "" || op.Name == "" {
+			return fmt.Errorf("delete requires a resource and a name")
+		}
+	default:
+		return fmt.Errorf("unknown operation %q: must be get, apply, patch or delete", op.Op)
+	}
+	return nil
+}
+
+// runBatch runs ops in order with run, stopping at the first failure unless
+// continueOnError is set.
+func runBatch(ctx context.Context, ops []batchOperation, continueOnError bool, run func(context.Context, batchOperation) (string, error)) []batchResult {
+	results := make([]batchResult, len(ops))
+	failed := false
+	for i, op := range ops {
+		if failed && !continueOnError {
+			results[i] = batchResult{status: batchSkipped}
+			continue
+		}
+		output, err := run(ctx, op)
+		if err != nil {
+			failed = true
+			results[i] = batchResult{status: batchFailed, output: err.Error()}
+			continue
+		}
+		results[i] = batchResult{status: batchOK, output: output}
+	}
+	return results
+}
+
+// runBatchOperation runs op with the handler of the tool of its type, for
+// the session of req.
+func (h *handlers) runBatchOperation(ctx context.Context, req *mcp.CallToolRequest, op batchOperation) (string, error) {
+	var res *mcp.CallToolResult
+	var err error
+	switch op.Op {
+	case "get":
+		res, _, err = h.getResources(ctx, nil, &getResourcesArgs{Resource: op.Resource, Name: op.Name, Namespace: op.Namespace})
+	case "apply":
+		res, _, err = h.applyResource(ctx, req, &applyResourceArgs{Manifest: op.Manifest, Force: op.Force})
+	case "patch":
+		res, _, err = h.patchResource(ctx, req, &patchResourceArgs{Resource: op.Resource, Name: op.Name, Namespace: op.Namespace, Patch: op.Patch, PatchType: op.PatchType})
+	case "delete":
+		res, _, err = h.deleteResource(ctx, req, &deleteResourceArgs{Resource: op.Resource, Name: op.Name, Namespace: op.Namespace})
+	}
+	if err != nil {
+		return "", err
+	}
+	var output strings.Builder
+	for _, content := range res.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			output.WriteString(text.Text)
+		}
+	}
+	return output.String(), nil
+}
+
+func (h *handlers) batch(ctx context.Context, req *mcp.CallToolRequest, args *batchArgs) (*mcp.CallToolResult, any, error) {
+	if len(args.Operations) == 0 {
+		return nil, nil, fmt.Errorf("operations are required")
+	}
+	for i, op := range args.Operations {
+		if err := op.validate(); err != nil {
+			return nil, nil, fmt.Errorf("invalid operation %d: %w", i+1, err)
+		}
+		if op.Resource != "" {
+			if _, err := h.findGVR(op.Resource); err != nil {
+				return nil, nil, fmt.Errorf("invalid operation %d: %w", i+1, err)
+			}
+		}
+	}
+
+	results := runBatch(ctx, args.Operations, args.ContinueOnError, func(ctx context.Context, op batchOperation) (string, error) {
+		return h.runBatchOperation(ctx, req, op)
+	})
+
+	counts := map[batchStatus]int{}
+	for _, r := range results {
+		counts[r.status]++
+	}
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%d operations: %d succeeded, %d failed, %d skipped.\n",
+		len(results), counts[batchOK], counts[batchFailed], counts[batchSkipped]))
+	for i, r := range results {
+		output.WriteString(fmt.Sprintf("\n### %d. %s: %s\n", i+1, args.Operations[i], r.status))
+		if r.output != "" {
+			output.WriteString(strings.TrimRight(r.output, "\n") + "\n")
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+		IsError: counts[batchFailed] > 0,
+	}, nil, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunBatch(t *testing.T) {
+	ops := []batchOperation{
+		{Op: "apply", Manifest: "kind: Namespace"},
+		{Op: "patch", Resource: "deployments", Name: "web", Patch: "{}"},
+		{Op: "delete", Resource: "configmaps", Name: "old"},
+	}
+	var ran []string
+	run := func(_ context.Context, op batchOperation) (string, error) {
+		ran = append(ran, op.Op)
+		if op.Op == "patch" {
+			return "", errors.New("not found")
+		}
+		return op.Op + " done", nil
+	}
+
+	got := runBatch(context.Background(), ops, false, run)
+	want := []batchResult{
+		{status: batchOK, output: "apply done"},
+		{status: batchFailed, output: "not found"},
+		{status: batchSkipped},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(batchResult{})); diff != "" {
+		t.Errorf("runBatch() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"apply", "patch"}, ran); diff != "" {
+		t.Errorf("runBatch() ran mismatch (-want +got):\n%s", diff)
+	}
+
+	ran = nil
+	got = runBatch(context.Background(), ops, true, run)
+	if got[2].status != batchOK || len(ran) != 3 {
+		t.Errorf("runBatch() with continue on error = %v, ran %v, want all operations run", got, ran)
+	}
+}
+
+func TestBatchOperation(t *testing.T) {
+	for _, tc := range []struct {
+		op      batchOperation
+		want    string
+		wantErr bool
+	}{
+		{op: batchOperation{Op: "apply", Manifest: "kind: Namespace"}, want: "apply"},
+		{op: batchOperation{Op: "get", Resource: "pods", Namespace: "default"}, want: "get pods in default"},
+		{op: batchOperation{Op: "delete", Resource: "configmaps", Name: "old"}, want: "delete configmaps/old"},
+		{op: batchOperation{Op: "apply"}, want: "apply", wantErr: true},
+		{op: batchOperation{Op: "patch", Resource: "deployments", Name: "web"}, want: "patch deployments/web", wantErr: true},
+		{op: batchOperation{Op: "delete", Resource: "pods"}, want: "delete pods", wantErr: true},
+		{op: batchOperation{Op: "create"}, want: "create", wantErr: true},
+	} {
+		if got := tc.op.String(); got != tc.want {
+			t.Errorf("String() = %q, want %q", got, tc.want)
+		}
+		if err := tc.op.validate(); (err != nil) != tc.wantErr {
+			t.Errorf("validate(%s) error = %v, want error %t", tc.op, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,478 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/output"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// ApplyBundleToolDescription contains the documentation for the Kubernetes Apply Bundle tool.
+// It is formatted in Markdown.
+const ApplyBundleToolDescription = `
+This tool applies a bundle of manifests, e.g. a snapshot exported by *kube_snapshot* to restore a namespace, or manifests produced by another tool, with server-side apply. Unlike *kube_apply_resource*, it orders the resources, prunes the resources removed from the bundle, and previews the changes.
+
+The resources are applied in dependency order: namespaces first, then custom resource definitions, waiting for them to be established, then the other resources in the order of the bundle. The fields set by the API server, such as the status, are removed before applying. Resources that can't or shouldn't be applied are skipped:
+
+* resources managed by controllers, such as the pods of deployments: their controllers recreate them;
+* nodes and events;
+* the *default* service account, the *kube-root-ca.crt* config map and the service account token secrets, created by Kubernetes;
+* resources with redacted values, such as the Secrets of a snapshot: apply them separately with their values.
+
+## Arguments
+
+* *bundle*: (Optional) The YAML manifests of the bundle, separated by *---*. Lists, e.g. from *kubectl get -o yaml*, are expanded.
+* *bundle_path*: (Optional) The path of the bundle on the server: a YAML file, or a gzipped tarball of YAML files such as a snapshot of *kube_snapshot*, either a path relative to the output directory of the server or a Cloud Storage object as *gs://BUCKET/OBJECT* of a bucket the server allows (*--output-dir* and *--output-buckets*, where *kube_snapshot* writes snapshots). The bundle, decompressed, is limited to 10 MiB. Exactly one of *bundle* and *bundle_path* is required.
+* *namespace*: (Optional) The namespace of the namespaced resources of the bundle without a namespace. Defaults to the default namespace.
+* *prune*: (Optional) Set to *true* to delete the resources that match *prune_label_selector*, of the types and namespaces of the bundle, but are not in the bundle. Namespaces and custom resource definitions are never pruned. Nothing is pruned if a resource of the bundle fails to apply, since its live copy would be pruned.
+* *prune_label_selector*: (Optional) The label selector of the resources that may be pruned, e.g. *app.kubernetes.io/part-of=shop*. Required with *prune*.
+* *force*: (Optional) Set to *true* to take ownership of the fields owned by other field managers.
+* *dry_run*: (Optional) Set to *true* to preview the changes: the resources are validated by the API server with a server-side dry run, and nothing is changed.
+
+## Response Format
+
+A summary, followed by the result for each resource:
+
+Dry run: 3 to apply, 1 skipped, 1 to prune, 0 failed. Nothing was changed.
+
+KIND        NAMESPACE  NAME            RESULT
+Namespace              shop            would be configured
+ConfigMap   shop       web-config      would be created
+Deployment  shop       web             would be configured
+Pod         shop       web-7d4b9-x2k   skipped: managed by replicaset
+ConfigMap   shop       old-config      would be pruned
+`
+
+type applyBundleArgs struct {
+	Bundle             string `json:"bundle,omitempty"`
+	BundlePath         string `json:"bundle_path,omitempty"`
+	Namespace          string `json:"namespace,omitempty"`
+	Prune              bool   `json:"prune,omitempty"`
+	PruneLabelSelector string `json:"prune_label_selector,omitempty"`
+	Force              bool   `json:"force,omitempty"`
+	DryRun             bool   `json:"dry_run,omitempty"`
+}
+
+// crdEstablishTimeout is how long kube_apply_bundle waits for the custom
+// resource definitions of a bundle to be established.
+const crdEstablishTimeout = 30 * time.Second
+
+// parseBundle returns the objects of the YAML or JSON documents of data,
+// with the items of lists expanded.
+func parseBundle(data []byte) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		var doc map[string]any
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, fmt.Errorf("failed to parse bundle: %w", err)
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: doc}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse list: %w", err)
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+			return nil, fmt.Errorf("invalid manifest %q: apiVersion and kind are required", obj.GetName())
+		}
+		objs = append(objs, obj)
+	}
+}
+
+// readBundleArchive returns the concatenated YAML and JSON files of the
+// gzipped tarball data, e.g. a snapshot of kube_snapshot, failing if they
+// exceed maxManifestSize.
+func readBundleArchive(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle archive: %w", err)
+	}
+	var out bytes.Buffer
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return out.Bytes(), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle archive: %w", err)
+		}
+		if header.Name == snapshotVersionFile {
+			continue
+		}
+		switch path.Ext(header.Name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		out.WriteString("\n---\n")
+		if _, err := io.CopyN(&out, tr, int64(maxManifestSize+1-out.Len())); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		if out.Len() > maxManifestSize {
+			return nil, fmt.Errorf("bundle archive exceeds %d MiB", maxManifestSize>>20)
+		}
+	}
+}
+
+// readBundle reads the bundle at bundlePath, a path in the output directory
+// of the server or a Cloud Storage URL of a bucket it allows, where
+// kube_snapshot writes snapshots.
+func (h *handlers) readBundle(ctx context.Context, bundlePath string) ([]byte, error) {
+	f, err := output.Open(ctx, h.c, bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	defer f.Close()
+	data, err := readLimited(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle %s: %w", bundlePath, err)
+	}
+	// Gzip streams start with the magic number 1f 8b.
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return readBundleArchive(data)
+	}
+	return data, nil
+}
+
+// bundleOrder returns the rank of kind in the apply order of a bundle:
+// namespaces, then custom resource definitions, then the other resources.
+func bundleOrder(kind string) int {
+	switch kind {
+	case "Namespace":
+		return 0
+	case "CustomResourceDefinition":
+		return 1
+	}
+	return 2
+}
+
+// sortBundle sorts objs in apply order, keeping the order of the bundle
+// within a rank.
+func sortBundle(objs []*unstructured.Unstructured) {
+	sort.SliceStable(objs, func(i, j int) bool {
+		return bundleOrder(objs[i].GetKind()) < bundleOrder(objs[j].GetKind())
+	})
+}
+
+// bundleSkipReason returns why obj isn't applied, or "" if it is.
+func bundleSkipReason(obj *unstructured.Unstructured) string {
+	if controller := metav1.GetControllerOf(obj); controller != nil {
+		return "managed by " + strings.ToLower(controller.Kind)
+	}
+	switch kind, name := obj.GetKind(), obj.GetName(); {
+	case kind == "Node" || kind == "Event":
+		return "cluster state"
+	case kind == "ServiceAccount" && name == "default",
+		kind == "ConfigMap" && name == "kube-root-ca.crt":
+		return "created in every namespace"
+	case kind == "Secret":
+		if t, _, _ := unstructured.NestedString(obj.Object, "type"); t == "kubernetes.io/service-account-token" {
+			return "service account token"
+		}
+	}
+	if data, err := json.Marshal(obj.Object); err == nil && bytes.Contains(data, []byte(redactedValue)) {
+		return "redacted values"
+	}
+	return ""
+}
+
+// bundleResource is a resource of a bundle and the result of applying it.
+type bundleResource struct {
+	kind      string
+	namespace string
+	name      string
+	result    string
+	failed    bool
+}
+
+// pruneScope is a type and namespace of the resources of a bundle, where
+// the resources that aren't in the bundle are pruned.
+type pruneScope struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+}
+
+// waitForCRDs waits for the custom resource definitions names to be
+// established, so that their resources can be applied.
+func (h *handlers) waitForCRDs(ctx context.Context, names []string) error {
+	crds := h.dyn.Resource(schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"})
+	for _, name := range names {
+		err := wait.PollUntilContextTimeout(ctx, time.Second, crdEstablishTimeout, true, func(ctx context.Context) (bool, error) {
+			obj, err := crds.Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+			for _, c := range conditions {
+				if c, ok := c.(map[string]any); ok && c["type"] == "Established" && c["status"] == "True" {
+					return true, nil
+				}
+			}
+			return false, nil
+		})
+		if err != nil {
+			return fmt.Errorf("custom resource definition %s is not established: %w", name, err)
+		}
+	}
+	if m, ok := h.mapper.(meta.ResettableRESTMapper); ok {
+		m.Reset()
+	}
+	return nil
+}
+
+func (h *handlers) applyBundle(ctx context.Context, _ *mcp.CallToolRequest, args *applyBundleArgs) (*mcp.CallToolResult, any, error) {
+	var data []byte
+	switch {
+	case args.Bundle != "" && args.BundlePath != "":
+		return nil, nil, fmt.Errorf("bundle and bundle_path are mutually exclusive")
+	case args.BundlePath != "":
+		var err error
+		if data, err = h.readBundle(ctx, args.BundlePath); err != nil {
+			return nil, nil, err
+		}
+	case args.Bundle != "":
+		data = []byte(args.Bundle)
+	default:
+		return nil, nil, fmt.Errorf("bundle or bundle_path is required")
+	}
+	if args.Prune && args.PruneLabelSelector == "" {
+		return nil, nil, fmt.Errorf("prune_label_selector is required with prune")
+	}
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+
+	objs, err := parseBundle(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(objs) == 0 {
+		return nil, nil, fmt.Errorf("the bundle has no resources")
+	}
+	sortBundle(objs)
+
+	opts := metav1.ApplyOptions{FieldManager: h.c.FieldManager(), Force: args.Force}
+	if args.DryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	var resources []bundleResource
+	var crds []string
+	// applied holds the applied resources by scope, for pruning.
+	applied := map[pruneScope]map[string]bool{}
+	var scopes []pruneScope
+	for _, obj := range objs {
+		// The resources of the custom resource definitions of the bundle can
+		// only be mapped once the definitions are established.
+		if len(crds) > 0 && !args.DryRun && bundleOrder(obj.GetKind()) > 1 {
+			if err := h.waitForCRDs(ctx, crds); err != nil {
+				return nil, nil, err
+			}
+			crds = nil
+		}
+		r := bundleResource{kind: obj.GetKind(), namespace: obj.GetNamespace(), name: obj.GetName()}
+		if skip := bundleSkipReason(obj); skip != "" {
+			r.result = "skipped: " + skip
+			resources = append(resources, r)
+			continue
+		}
+
+		gvk := obj.GroupVersionKind()
+		mapping, err := h.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			r.result = "failed: " + err.Error()
+			if args.DryRun && meta.IsNoMatchError(err) {
+				r.result = "skipped: unknown kind, unless defined by a custom resource definition of the bundle"
+			} else {
+				r.failed = true
+			}
+			resources = append(resources, r)
+			continue
+		}
+		if h.c.NamespacedWritesOnly() && mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			r.result, r.failed = "failed: "+clusterScopedWriteError(mapping.Resource.GroupResource().String(), r.name).Error(), true
+			resources = append(resources, r)
+			continue
+		}
+		var ri dynamic.ResourceInterface = h.dyn.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if r.namespace == "" {
+				r.namespace = namespace
+			}
+			ri = h.dyn.Resource(mapping.Resource).Namespace(r.namespace)
+		} else {
+			r.namespace = ""
+		}
+
+		if err := h.checkWriteNamespace(mapping.Resource, r.namespace, r.name); err != nil {
+			r.result, r.failed = "failed: "+err.Error(), true
+			resources = append(resources, r)
+			continue
+		}
+
+		sanitizeManifest(obj)
+		obj.SetNamespace(r.namespace)
+		exists := true
+		if _, err := ri.Get(ctx, r.name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			exists = false
+		}
+		_, err = ri.Apply(ctx, r.name, obj, opts)
+		switch {
+		case apierrors.IsConflict(err):
+			r.result, r.failed = "failed: "+applyConflictError(r.kind, r.name, err).Error(), true
+		case err != nil:
+			r.result, r.failed = "failed: "+err.Error(), true
+		case exists:
+			r.result = "configured"
+		default:
+			r.result = "created"
+		}
+		if !r.failed && args.DryRun {
+			r.result = "would be " + r.result
+		}
+		resources = append(resources, r)
+		if r.failed {
+			continue
+		}
+		if r.kind == "CustomResourceDefinition" {
+			crds = append(crds, r.name)
+		}
+		if bundleOrder(r.kind) > 1 {
+			scope := pruneScope{gvr: mapping.Resource, namespace: r.namespace}
+			if applied[scope] == nil {
+				applied[scope] = map[string]bool{}
+				scopes = append(scopes, scope)
+			}
+			applied[scope][r.name] = true
+		}
+	}
+
+	// A resource that failed to apply isn't in applied: its live copy would be
+	// pruned.
+	pruneNote := ""
+	if failed := countBundleResults(resources)["failed"]; args.Prune && failed > 0 {
+		pruneNote = fmt.Sprintf("\nNothing was pruned: %d resources of the bundle failed to apply. Fix them, and apply the bundle again to prune.\n", failed)
+	}
+	if args.Prune && pruneNote == "" {
+		for _, scope := range scopes {
+			var ri dynamic.ResourceInterface = h.dyn.Resource(scope.gvr)
+			if scope.namespace != "" {
+				ri = h.dyn.Resource(scope.gvr).Namespace(scope.namespace)
+			}
+			list, err := ri.List(ctx, metav1.ListOptions{LabelSelector: args.PruneLabelSelector})
+			if err != nil {
+				resources = append(resources, bundleResource{kind: scope.gvr.Resource, namespace: scope.namespace, result: "failed: " + err.Error(), failed: true})
+				continue
+			}
+			for _, obj := range list.Items {
+				if applied[scope][obj.GetName()] || metav1.GetControllerOf(&obj) != nil {
+					continue
+				}
+				r := bundleResource{kind: obj.GetKind(), namespace: scope.namespace, name: obj.GetName(), result: "pruned"}
+				deleteOpts := metav1.DeleteOptions{}
+				if args.DryRun {
+					deleteOpts.DryRun = []string{metav1.DryRunAll}
+					r.result = "would be pruned"
+				}
+				if err := ri.Delete(ctx, obj.GetName(), deleteOpts); err != nil && !apierrors.IsNotFound(err) {
+					r.result, r.failed = "failed: "+err.Error(), true
+				}
+				resources = append(resources, r)
+			}
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: formatBundleResults(resources, args.DryRun) + pruneNote},
+		},
+		IsError: countBundleResults(resources)["failed"] > 0,
+	}, nil, nil
+}
+
+// countBundleResults counts the resources of a bundle by outcome: applied,
+// skipped, pruned and failed.
+func countBundleResults(resources []bundleResource) map[string]int {
+	counts := map[string]int{}
+	for _, r := range resources {
+		switch result := strings.TrimPrefix(r.result, "would be "); {
+		case r.failed:
+			counts["failed"]++
+		case strings.HasPrefix(result, "skipped"):
+			counts["skipped"]++
+		case result == "pruned":
+			counts["pruned"]++
+		default:
+			counts["applied"]++
+		}
+	}
+	return counts
+}
+
+// formatBundleResults returns the summary and the table of the results of
+// applying a bundle.
+func formatBundleResults(resources []bundleResource, dryRun bool) string {
+	counts := countBundleResults(resources)
+	var output strings.Builder
+	if dryRun {
+		output.WriteString(fmt.Sprintf("Dry run: %d to apply, %d skipped, %d to prune, %d failed. Nothing was changed.\n\n",
+			counts["applied"], counts["skipped"], counts["pruned"], counts["failed"]))
+	} else {
+		output.WriteString(fmt.Sprintf("%d applied, %d skipped, %d pruned, %d failed.\n\n",
+			counts["applied"], counts["skipped"], counts["pruned"], counts["failed"]))
+	}
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tRESULT")
+	for _, r := range resources {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.kind, r.namespace, r.name, r.result)
+	}
+	w.Flush()
+	return output.String()
+}
@@ -0,0 +1,331 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// bundleNames returns the kinds and names of objs, e.g. "Namespace/shop".
+func bundleNames(objs []*unstructured.Unstructured) []string {
+	var names []string
+	for _, obj := range objs {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	return names
+}
+
+func TestParseBundle(t *testing.T) {
+	objs, err := parseBundle([]byte(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: web-config
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+---
+{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "shop"}}
+`))
+	if err != nil {
+		t.Fatalf("parseBundle() failed: %v", err)
+	}
+	want := []string{"Deployment/web", "ConfigMap/web-config", "Service/web", "Namespace/shop"}
+	if diff := cmp.Diff(want, bundleNames(objs)); diff != "" {
+		t.Errorf("parseBundle() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := parseBundle([]byte("metadata:\n  name: web\n")); err == nil {
+		t.Errorf("parseBundle() of a manifest without kind succeeded, want error")
+	}
+}
+
+func TestSortBundle(t *testing.T) {
+	objs, err := parseBundle([]byte(`
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: c
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+`))
+	if err != nil {
+		t.Fatalf("parseBundle() failed: %v", err)
+	}
+	sortBundle(objs)
+	want := []string{"Namespace/shop", "CustomResourceDefinition/widgets.example.com", "Widget/w", "ConfigMap/c"}
+	if diff := cmp.Diff(want, bundleNames(objs)); diff != "" {
+		t.Errorf("sortBundle() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBundleSkipReason(t *testing.T) {
+	for _, tc := range []struct {
+		manifest string
+		want     string
+	}{
+		{
+			manifest: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`,
+		},
+		{
+			manifest: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-7d4b9-x2k
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: ReplicaSet
+    name: web-7d4b9
+    uid: "1"
+    controller: true
+`,
+			want: "managed by replicaset",
+		},
+		{
+			manifest: `
+apiVersion: v1
+kind: Event
+metadata:
+  name: web.1
+`,
+			want: "cluster state",
+		},
+		{
+			manifest: `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: default
+`,
+			want: "created in every namespace",
+		},
+		{
+			manifest: `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+data:
+  password: '[REDACTED]'
+`,
+			want: "redacted values",
+		},
+	} {
+		obj := decodeObject(t, tc.manifest)
+		if got := bundleSkipReason(obj); got != tc.want {
+			t.Errorf("bundleSkipReason(%s/%s) = %q, want %q", obj.GetKind(), obj.GetName(), got, tc.want)
+		}
+	}
+}
+
+func TestReadBundleArchive(t *testing.T) {
+	var buf bytes.Buffer
+	archive := newSnapshotArchive(&buf, time.Now())
+	if err := archive.add(snapshotVersionFile, []byte("gitVersion: v1.31.4\n"), 1); err != nil {
+		t.Fatalf("add() failed: %v", err)
+	}
+	configMap := decodeObject(t, `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: shop
+`)
+	if err := archive.addObjects("shop/configmaps.yaml", []unstructured.Unstructured{*configMap, *configMap}); err != nil {
+		t.Fatalf("addObjects() failed: %v", err)
+	}
+	if err := archive.add("summary.txt", []byte("FILE\tOBJECTS\n"), 0); err != nil {
+		t.Fatalf("add() failed: %v", err)
+	}
+	if err := archive.close(); err != nil {
+		t.Fatalf("close() failed: %v", err)
+	}
+
+	data, err := readBundleArchive(buf.Bytes())
+	if err != nil {
+		t.Fatalf("readBundleArchive() failed: %v", err)
+	}
+	objs, err := parseBundle(data)
+	if err != nil {
+		t.Fatalf("parseBundle() failed: %v", err)
+	}
+	want := []string{"ConfigMap/web-config", "ConfigMap/web-config"}
+	if diff := cmp.Diff(want, bundleNames(objs)); diff != "" {
+		t.Errorf("bundle mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestReadBundleArchiveLimit(t *testing.T) {
+	var buf bytes.Buffer
+	archive := newSnapshotArchive(&buf, time.Now())
+	if err := archive.add("shop/configmaps.yaml", make([]byte, maxManifestSize+1), 1); err != nil {
+		t.Fatalf("add() failed: %v", err)
+	}
+	if err := archive.close(); err != nil {
+		t.Fatalf("close() failed: %v", err)
+	}
+	if _, err := readBundleArchive(buf.Bytes()); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("readBundleArchive() error = %v, want the size limit", err)
+	}
+}
+
+func TestReadBundle(t *testing.T) {
+	dir := t.TempDir()
+	h := &handlers{c: config.New("test", config.Options{OutputDir: dir})}
+	bundle := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-config\n"
+	if err := os.WriteFile(filepath.Join(dir, "bundle.yaml"), []byte(bundle), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := h.readBundle(context.Background(), "bundle.yaml"); err != nil || string(data) != bundle {
+		t.Errorf("readBundle() = %q, %v, want %q", data, err, bundle)
+	}
+	outside := filepath.Join(t.TempDir(), "bundle.yaml")
+	if err := os.WriteFile(outside, []byte(bundle), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, bundlePath := range []string{outside, "../bundle.yaml", "gs://other-bucket/bundle.yaml"} {
+		if _, err := h.readBundle(context.Background(), bundlePath); err == nil {
+			t.Errorf("readBundle(%q) succeeded, want error", bundlePath)
+		}
+	}
+}
+
+func TestFormatBundleResults(t *testing.T) {
+	resources := []bundleResource{
+		{kind: "Namespace", name: "shop", result: "would be configured"},
+		{kind: "Deployment", namespace: "shop", name: "web", result: "would be created"},
+		{kind: "Pod", namespace: "shop", name: "web-x2k", result: "skipped: managed by replicaset"},
+		{kind: "ConfigMap", namespace: "shop", name: "old", result: "would be pruned"},
+		{kind: "Widget", namespace: "shop", name: "w", result: "failed: forbidden", failed: true},
+	}
+	got := formatBundleResults(resources, true)
+	if !strings.HasPrefix(got, "Dry run: 2 to apply, 1 skipped, 1 to prune, 1 failed. Nothing was changed.\n") {
+		t.Errorf("formatBundleResults() summary = %q", strings.SplitN(got, "\n", 2)[0])
+	}
+	if !strings.Contains(got, "ConfigMap   shop       old      would be pruned\n") {
+		t.Errorf("formatBundleResults() = %q, want the pruned config map", got)
+	}
+}
+
+func TestApplyBundlePrune(t *testing.T) {
+	ctx := context.Background()
+	labeled := func(name string) *unstructured.Unstructured {
+		obj := configMap(name, "1", "old")
+		obj.SetLabels(map[string]string{"app.kubernetes.io/part-of": "shop"})
+		return obj
+	}
+	bundle := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: shop
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: broken
+  namespace: shop
+`
+	for _, tc := range []struct {
+		name       string
+		failBroken bool
+		want       []string
+		wantText   string
+	}{
+		{name: "applied", want: []string{"broken", "web"}, wantText: "2 applied, 0 skipped, 1 pruned, 0 failed."},
+		// The live copy of the resource that failed to apply isn't pruned.
+		{name: "failed apply", failBroken: true, want: []string{"broken", "old", "web"}, wantText: "Nothing was pruned: 1 resources of the bundle failed to apply."},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+			dyn := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), labeled("web"), labeled("broken"), labeled("old"))
+			dyn.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				name := action.(k8stesting.PatchAction).GetName()
+				if name == "broken" && tc.failBroken {
+					return true, nil, errors.New("admission webhook denied the request")
+				}
+				return true, labeled(name), nil
+			})
+			h := &handlers{c: config.New("test", config.Options{}), dyn: dyn, mapper: mapper, defaultNamespace: "shop"}
+
+			res, _, err := h.applyBundle(ctx, nil, &applyBundleArgs{Bundle: bundle, Prune: true, PruneLabelSelector: "app.kubernetes.io/part-of=shop"})
+			if err != nil {
+				t.Fatalf("applyBundle() failed: %v", err)
+			}
+			if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, tc.wantText) {
+				t.Errorf("applyBundle() = %q, want it to contain %q", text, tc.wantText)
+			}
+			list, err := dyn.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("shop").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, obj := range list.Items {
+				names = append(names, obj.GetName())
+			}
+			if diff := cmp.Diff(tc.want, names); diff != "" {
+				t.Errorf("config maps after applyBundle() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CertExpiryScanToolDescription contains the documentation for the Kubernetes Certificate Expiry Scan tool.
+// It is formatted in Markdown.
+const CertExpiryScanToolDescription = `
+This tool scans the certificates stored in the cluster and lists the ones that are expired or expire soon. Expired webhook certificates silently break entire clusters: the API server can't call the webhooks, and with the *Fail* failure policy every request they intercept is rejected.
+
+The tool parses the certificates of:
+
+* The TLS Secrets (type *kubernetes.io/tls*): their *tls.crt* chain and *ca.crt*.
+* The *caBundle* of the validating and mutating admission webhooks.
+* The *caBundle* of the APIServices of aggregated APIs, such as *metrics.k8s.io*.
+* The *caBundle* of the conversion webhooks of CustomResourceDefinitions.
+
+## Arguments
+
+* *days*: (Optional) List the certificates expiring within this number of days. Defaults to 30.
+* *namespace*: (Optional) Only scan the Secrets of this namespace. Defaults to all namespaces. The webhooks, APIServices and CustomResourceDefinitions are cluster-scoped and always scanned.
+* *include_all*: (Optional) Set to *true* to list all the certificates, including the ones that don't expire soon.
+
+## Response Format
+
+The certificates, sorted by expiry date:
+
+STATUS    EXPIRES               DAYS_LEFT  SOURCE                                          SUBJECT              ISSUER
+EXPIRED   2025-05-01T00:00:00Z  -31        ValidatingWebhook policy/validate.example.com  policy-webhook.svc   policy-ca
+EXPIRING  2025-06-15T00:00:00Z  14         Secret default/web-tls tls.crt                  web.example.com      R11
+
+Summary: 120 certificates scanned, 1 expired, 1 expiring within 30 days.
+`
+
+type certExpiryScanArgs struct {
+	Days       int    `json:"days,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	IncludeAll bool   `json:"include_all,omitempty"`
+}
+
+const defaultCertExpiryDays = 30
+
+// certSource is PEM encoded certificates stored in the cluster.
+type certSource struct {
+	// name describes where the certificates are stored, e.g.
+	// "Secret default/web-tls tls.crt".
+	name string
+	pem  []byte
+}
+
+// scannedCert is a certificate of a source.
+type scannedCert struct {
+	source string
+	cert   *x509.Certificate
+}
+
+// parseCertSources returns the certificates of sources, and the sources
+// with invalid certificates.
+func parseCertSources(sources []certSource) ([]scannedCert, []string) {
+	var certs []scannedCert
+	var invalid []string
+	for _, s := range sources {
+		for rest := s.pem; ; {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s: %v", s.name, err))
+				continue
+			}
+			certs = append(certs, scannedCert{source: s.name, cert: cert})
+		}
+	}
+	sort.SliceStable(certs, func(i, j int) bool { return certs[i].cert.NotAfter.Before(certs[j].cert.NotAfter) })
+	return certs, invalid
+}
+
+// certName returns the common name of name, or the whole name if it has no
+// common name.
+func certName(name fmt.Stringer, commonName string) string {
+	if commonName != "" {
+		return commonName
+	}
+	if s := name.String(); s != "" {
+		return s
+	}
+	return "<none>"
+}
+
+// caBundleField returns the decoded caBundle at path of obj, which
+// unstructured objects hold base64 encoded.
+func caBundleField(obj map[string]any, path ...string) []byte {
+	encoded, _, _ := unstructured.NestedString(obj, path...)
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// certSources returns the certificates of the cluster, of the Secrets in
+// namespace.
+func (h *handlers) certSources(ctx context.Context, namespace string) ([]certSource, error) {
+	var sources []certSource
+	secrets, err := h.clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{FieldSelector: "type=" + string(corev1.SecretTypeTLS)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for _, s := range secrets.Items {
+		for _, key := range []string{corev1.TLSCertKey, "ca.crt"} {
+			if data := s.Data[key]; len(data) > 0 {
+				sources = append(sources, certSource{name: fmt.Sprintf("Secret %s/%s %s", s.Namespace, s.Name, key), pem: data})
+			}
+		}
+	}
+
+	webhooks, err := h.listWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range webhooks {
+		if len(w.clientConfig.CABundle) > 0 {
+			kind := strings.ToUpper(w.kind[:1]) + w.kind[1:] + "Webhook"
+			sources = append(sources, certSource{name: fmt.Sprintf("%s %s/%s", kind, w.configuration, w.name), pem: w.clientConfig.CABundle})
+		}
+	}
+
+	apiServices, err := h.dyn.Resource(schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list apiservices: %w", err)
+	}
+	for _, s := range apiServices.Items {
+		if data := caBundleField(s.Object, "spec", "caBundle"); len(data) > 0 {
+			sources = append(sources, certSource{name: "APIService " + s.GetName(), pem: data})
+		}
+	}
+
+	crds, err := h.dyn.Resource(schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customresourcedefinitions: %w", err)
+	}
+	for _, crd := range crds.Items {
+		if data := caBundleField(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle"); len(data) > 0 {
+			sources = append(sources, certSource{name: "CustomResourceDefinition " + crd.GetName() + " conversion webhook", pem: data})
+		}
+	}
+	return sources, nil
+}
+
+func (h *handlers) certExpiryScan(ctx context.Context, _ *mcp.CallToolRequest, args *certExpiryScanArgs) (*mcp.CallToolResult, any, error) {
+	days := args.Days
+	if days <= 0 {
+		days = defaultCertExpiryDays
+	}
+	sources, err := h.certSources(ctx, args.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	certs, invalid := parseCertSources(sources)
+	output := formatCertExpiry(certs, invalid, time.Now(), days, args.IncludeAll)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output},
+		},
+	}, nil, nil
+}
+
+// formatCertExpiry formats the certificates of certs that are expired at now
+// or expire within days, or all of them if includeAll is set.
+func formatCertExpiry(certs []scannedCert, invalid []string, now time.Time, days int, includeAll bool) string {
+	deadline := now.Add(time.Duration(days) * 24 * time.Hour)
+	var output strings.Builder
+	var expired, expiring int
+	var rows []string
+	for _, c := range certs {
+		status := "OK"
+		switch {
+		case now.After(c.cert.NotAfter):
+			status = "EXPIRED"
+			expired++
+		case deadline.After(c.cert.NotAfter):
+			status = "EXPIRING"
+			expiring++
+		case now.Before(c.cert.NotBefore):
+			status = "NOT_YET_VALID"
+		}
+		if status == "OK" && !includeAll {
+			continue
+		}
+		daysLeft := int(c.cert.NotAfter.Sub(now).Hours() / 24)
+		rows = append(rows, fmt.Sprintf("%s\t%s\t%d\t%s\t%s\t%s\n", status, c.cert.NotAfter.UTC().Format(time.RFC3339), daysLeft, c.source,
+			certName(c.cert.Subject, c.cert.Subject.CommonName), certName(c.cert.Issuer, c.cert.Issuer.CommonName)))
+	}
+	if len(rows) == 0 {
+		output.WriteString(fmt.Sprintf("No certificates expire within %d days.\n", days))
+	} else {
+		output.WriteString("STATUS\tEXPIRES\tDAYS_LEFT\tSOURCE\tSUBJECT\tISSUER\n")
+		for _, row := range rows {
+			output.WriteString(row)
+		}
+	}
+	if len(invalid) > 0 {
+		output.WriteString("\nInvalid certificates:\n")
+		for _, i := range invalid {
+			output.WriteString("- " + i + "\n")
+		}
+	}
+	output.WriteString(fmt.Sprintf("\nSummary: %d certificates scanned, %d expired, %d expiring within %d days.\n", len(certs), expired, expiring, days))
+	return output.String()
+}
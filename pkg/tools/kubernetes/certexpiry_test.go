@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormatCertExpiry(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	expired := newCACert(t, now.AddDate(-1, 0, 0), now.AddDate(0, 0, -31))
+	expiring := newCACert(t, now.AddDate(-1, 0, 0), now.AddDate(0, 0, 14))
+	valid := newCACert(t, now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0))
+	sources := []certSource{
+		{name: "Secret default/web-tls tls.crt", pem: append(append([]byte{}, valid...), expiring...)},
+		{name: "ValidatingWebhook policy/validate.example.com", pem: expired},
+		{name: "APIService v1beta1.metrics.k8s.io", pem: []byte("-----BEGIN CERTIFICATE-----\nYWJj\n-----END CERTIFICATE-----\n")},
+	}
+	certs, invalid := parseCertSources(sources)
+	if len(invalid) != 1 || !strings.HasPrefix(invalid[0], "APIService v1beta1.metrics.k8s.io: ") {
+		t.Errorf("parseCertSources() invalid = %q, want the APIService", invalid)
+	}
+
+	got := formatCertExpiry(certs, invalid, now, 30, false)
+	for _, want := range []string{
+		"EXPIRED\t2025-05-01T00:00:00Z\t-31\tValidatingWebhook policy/validate.example.com\twebhook-ca\twebhook-ca\n",
+		"EXPIRING\t2025-06-15T00:00:00Z\t14\tSecret default/web-tls tls.crt\twebhook-ca\twebhook-ca\n",
+		"Summary: 3 certificates scanned, 1 expired, 1 expiring within 30 days.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatCertExpiry() = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "OK\t") {
+		t.Errorf("formatCertExpiry() = %q, want no valid certificates", got)
+	}
+	if strings.Index(got, "EXPIRED") > strings.Index(got, "EXPIRING") {
+		t.Errorf("formatCertExpiry() = %q, want the certificates sorted by expiry", got)
+	}
+
+	if got := formatCertExpiry(certs, nil, now, 30, true); !strings.Contains(got, "OK\t2026-06-01T00:00:00Z\t365\tSecret default/web-tls tls.crt") {
+		t.Errorf("formatCertExpiry(include_all) = %q, want the valid certificate", got)
+	}
+}
+
+func TestCABundleField(t *testing.T) {
+	obj := map[string]any{"spec": map[string]any{"caBundle": base64.StdEncoding.EncodeToString([]byte("ca"))}}
+	if diff := cmp.Diff([]byte("ca"), caBundleField(obj, "spec", "caBundle")); diff != "" {
+		t.Errorf("caBundleField() mismatch (-want +got):\n%s", diff)
+	}
+	if got := caBundleField(obj, "spec", "missing"); len(got) != 0 {
+		t.Errorf("caBundleField(missing) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,375 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CloneNamespaceToolDescription contains the documentation for the Kubernetes Clone Namespace tool.
+// It is formatted in Markdown.
+const CloneNamespaceToolDescription = `
+This tool copies the resources of a namespace to another namespace, e.g. to create a test environment per developer or per pull request from a template namespace. The target namespace is created if it doesn't exist.
+
+The resources are cleaned like with *kube_export_manifest*, then applied to the target namespace with server-side apply. Resources managed by controllers, such as the pods of deployments, aren't copied: their controllers recreate them. The service account token secrets, the *default* service account and the *kube-root-ca.crt* config map are created by Kubernetes in every namespace, and aren't copied either.
+
+## Arguments
+
+* *source_namespace*: The namespace to copy the resources from.
+* *target_namespace*: The namespace to copy the resources to.
+* *resources*: (Optional) The types of the resources to copy, in the order they are applied. Defaults to *serviceaccounts, configmaps, secrets, services, deployments, statefulsets, cronjobs*.
+* *label_selector*: (Optional) A label selector filtering the resources to copy, e.g. *app=web*.
+* *name_prefix*, *name_suffix*: (Optional) A prefix and a suffix added to the names of the copied resources. The references between the copied resources, such as the config maps, secrets, service accounts and persistent volume claims of pods, are renamed too.
+* *labels*: (Optional) Labels added to the copied resources, e.g. *{"env": "pr-123"}*.
+* *secrets*: (Optional) How to copy secrets:
+    * *skip* (default): secrets aren't copied.
+    * *copy*: secrets are copied with their values.
+    * *empty*: secrets are copied with their keys, but empty values, to fill in.
+* *dry_run*: (Optional) Set to *true* to return the manifests instead of applying them.
+
+## Response Format
+
+The copied resources:
+
+KIND         NAME          RESULT
+ConfigMap    web-config    applied
+Secret       web-tls       skipped: secrets=skip
+Deployment   web           applied
+`
+
+type cloneNamespaceArgs struct {
+	SourceNamespace string            `json:"source_namespace"`
+	TargetNamespace string            `json:"target_namespace"`
+	Resources       []string          `json:"resources,omitempty"`
+	LabelSelector   string            `json:"label_selector,omitempty"`
+	NamePrefix      string            `json:"name_prefix,omitempty"`
+	NameSuffix      string            `json:"name_suffix,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Secrets         string            `json:"secrets,omitempty"`
+	DryRun          bool              `json:"dry_run,omitempty"`
+}
+
+// defaultCloneResources are the resources copied by kube_clone_namespace,
+// in the order they are applied: the resources referenced by workloads first.
+var defaultCloneResources = []string{"serviceaccounts", "configmaps", "secrets", "services", "deployments", "statefulsets", "cronjobs"}
+
+// The secret handling policies of kube_clone_namespace.
+const (
+	cloneSecretsSkip  = "skip"
+	cloneSecretsCopy  = "copy"
+	cloneSecretsEmpty = "empty"
+)
+
+// namespaceCloner rewrites the resources of a namespace for another
+// namespace.
+type namespaceCloner struct {
+	target  string
+	prefix  string
+	suffix  string
+	labels  map[string]string
+	secrets string
+	// copied holds the kinds and names of the copied resources, e.g.
+	// "ConfigMap/web-config", whose references are renamed.
+	copied map[string]bool
+}
+
+// skipReason returns why obj isn't copied, or "" if it is.
+func (c *namespaceCloner) skipReason(obj *unstructured.Unstructured) string {
+	if metav1.GetControllerOf(obj) != nil {
+		return "managed by " + strings.ToLower(metav1.GetControllerOf(obj).Kind)
+	}
+	switch kind, name := obj.GetKind(), obj.GetName(); {
+	case kind == "ServiceAccount" && name == "default",
+		kind == "ConfigMap" && name == "kube-root-ca.crt":
+		return "created in every namespace"
+	case kind == "Secret":
+		if t, _, _ := unstructured.NestedString(obj.Object, "type"); t == "kubernetes.io/service-account-token" {
+			return "service account token"
+		}
+		if c.secrets == cloneSecretsSkip {
+			return "secrets=skip"
+		}
+	}
+	return ""
+}
+
+// rename returns the name of the copy of a resource.
+func (c *namespaceCloner) rename(name string) string {
+	return c.prefix + name + c.suffix
+}
+
+// renameRef returns the name of the copy of the resource of kind named name,
+// or name if the resource isn't copied.
+func (c *namespaceCloner) renameRef(kind, name string) string {
+	if !c.copied[kind+"/"+name] {
+		return name
+	}
+	return c.rename(name)
+}
+
+// clone rewrites obj, already sanitized, for the target namespace.
+func (c *namespaceCloner) clone(obj *unstructured.Unstructured) {
+	obj.SetNamespace(c.target)
+	obj.SetName(c.rename(obj.GetName()))
+	if len(c.labels) > 0 {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		for k, v := range c.labels {
+			labels[k] = v
+		}
+		obj.SetLabels(labels)
+	}
+
+	switch obj.GetKind() {
+	case "Secret":
+		if c.secrets == cloneSecretsEmpty {
+			empty := map[string]any{}
+			for _, field := range []string{"data", "stringData"} {
+				values, _, _ := unstructured.NestedMap(obj.Object, field)
+				for key := range values {
+					empty[key] = ""
+				}
+				unstructured.RemoveNestedField(obj.Object, field)
+			}
+			if len(empty) > 0 {
+				unstructured.SetNestedMap(obj.Object, empty, "stringData")
+			}
+		}
+	case "Pod":
+		c.renamePodSpecRefs(obj.Object, []string{"spec"})
+	case "StatefulSet":
+		if name, ok, _ := unstructured.NestedString(obj.Object, "spec", "serviceName"); ok {
+			unstructured.SetNestedField(obj.Object, c.renameRef("Service", name), "spec", "serviceName")
+		}
+	}
+	for _, template := range [][]string{{"spec", "template", "spec"}, {"spec", "jobTemplate", "spec", "template", "spec"}} {
+		c.renamePodSpecRefs(obj.Object, template)
+	}
+}
+
+// renamePodSpecRefs renames the references of the pod spec at path of obj to
+// the copied config maps, secrets, service accounts and persistent volume
+// claims.
+func (c *namespaceCloner) renamePodSpecRefs(obj map[string]any, path []string) {
+	spec, ok, _ := unstructured.NestedMap(obj, path...)
+	if !ok {
+		return
+	}
+	renameField := func(m map[string]any, kind string, field ...string) {
+		if name, ok, _ := unstructured.NestedString(m, field...); ok {
+			unstructured.SetNestedField(m, c.renameRef(kind, name), field...)
+		}
+	}
+
+	renameField(spec, "ServiceAccount", "serviceAccountName")
+	renameField(spec, "ServiceAccount", "serviceAccount")
+	forEachMap(spec["imagePullSecrets"], func(ref map[string]any) {
+		renameField(ref, "Secret", "name")
+	})
+	forEachMap(spec["volumes"], func(volume map[string]any) {
+		renameField(volume, "ConfigMap", "configMap", "name")
+		renameField(volume, "Secret", "secret", "secretName")
+		renameField(volume, "PersistentVolumeClaim", "persistentVolumeClaim", "claimName")
+		if sources, ok, _ := unstructured.NestedSlice(volume, "projected", "sources"); ok {
+			forEachMap(sources, func(source map[string]any) {
+				renameField(source, "ConfigMap", "configMap", "name")
+				renameField(source, "Secret", "secret", "name")
+			})
+		}
+	})
+	for _, key := range []string{"containers", "initContainers"} {
+		forEachMap(spec[key], func(container map[string]any) {
+			forEachMap(container["envFrom"], func(envFrom map[string]any) {
+				renameField(envFrom, "ConfigMap", "configMapRef", "name")
+				renameField(envFrom, "Secret", "secretRef", "name")
+			})
+			forEachMap(container["env"], func(env map[string]any) {
+				renameField(env, "ConfigMap", "valueFrom", "configMapKeyRef", "name")
+				renameField(env, "Secret", "valueFrom", "secretKeyRef", "name")
+			})
+		})
+	}
+	unstructured.SetNestedMap(obj, spec, path...)
+}
+
+// forEachMap calls f with the maps of list, a []any, which f can modify.
+func forEachMap(list any, f func(map[string]any)) {
+	items, _ := list.([]any)
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			f(m)
+		}
+	}
+}
+
+// clonedResource is a resource of the source namespace and its copy.
+type clonedResource struct {
+	kind string
+	name string
+	gvr  schema.GroupVersionResource
+	obj  *unstructured.Unstructured
+	skip string
+}
+
+func (h *handlers) cloneNamespace(ctx context.Context, _ *mcp.CallToolRequest, args *cloneNamespaceArgs) (*mcp.CallToolResult, any, error) {
+	if args.SourceNamespace == "" || args.TargetNamespace == "" {
+		return nil, nil, fmt.Errorf("source_namespace and target_namespace are required")
+	}
+	if args.SourceNamespace == args.TargetNamespace && args.NamePrefix == "" && args.NameSuffix == "" {
+		return nil, nil, fmt.Errorf("target_namespace must be different from source_namespace, unless the resources are renamed with name_prefix or name_suffix")
+	}
+	if err := h.checkProtectedNamespace(args.TargetNamespace); err != nil {
+		return nil, nil, err
+	}
+	secrets := args.Secrets
+	switch secrets {
+	case "":
+		secrets = cloneSecretsSkip
+	case cloneSecretsSkip, cloneSecretsCopy, cloneSecretsEmpty:
+	default:
+		return nil, nil, fmt.Errorf("invalid secrets %q: must be skip, copy or empty", args.Secrets)
+	}
+	resources := args.Resources
+	if len(resources) == 0 {
+		resources = defaultCloneResources
+	}
+
+	// Resolve the resources before listing, so that an unknown or
+	// cluster-scoped resource type fails the clone before anything is copied.
+	var gvrs []schema.GroupVersionResource
+	for _, resource := range resources {
+		gvr, err := h.findGVR(resource)
+		if err != nil {
+			return nil, nil, err
+		}
+		namespaced, err := h.isNamespaced(gvr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !namespaced {
+			return nil, nil, fmt.Errorf("%s are cluster-scoped and can't be copied to a namespace", gvr.Resource)
+		}
+		gvrs = append(gvrs, gvr)
+	}
+
+	cloner := &namespaceCloner{
+		target:  args.TargetNamespace,
+		prefix:  args.NamePrefix,
+		suffix:  args.NameSuffix,
+		labels:  args.Labels,
+		secrets: secrets,
+		copied:  map[string]bool{},
+	}
+	var cloned []clonedResource
+	for _, gvr := range gvrs {
+		list, err := h.dyn.Resource(gvr).Namespace(args.SourceNamespace).List(ctx, metav1.ListOptions{LabelSelector: args.LabelSelector})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list %s in namespace %s: %w", gvr.Resource, args.SourceNamespace, err)
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			r := clonedResource{kind: obj.GetKind(), name: obj.GetName(), gvr: gvr, obj: obj, skip: cloner.skipReason(obj)}
+			if r.skip == "" {
+				cloner.copied[r.kind+"/"+r.name] = true
+			}
+			cloned = append(cloned, r)
+		}
+	}
+	// References are renamed once all the copied resources are known.
+	for _, r := range cloned {
+		if r.skip == "" {
+			sanitizeManifest(r.obj)
+			cloner.clone(r.obj)
+		}
+	}
+
+	var output strings.Builder
+	if args.DryRun {
+		for _, r := range cloned {
+			if r.skip != "" {
+				continue
+			}
+			if err := writeYAMLDocument(&output, h.redact(r.obj)); err != nil {
+				return nil, nil, err
+			}
+		}
+		if output.Len() == 0 {
+			output.WriteString("No resources to copy.\n")
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: output.String()},
+			},
+		}, nil, nil
+	}
+
+	if err := h.ensureNamespace(ctx, args.TargetNamespace, args.Labels); err != nil {
+		return nil, nil, err
+	}
+
+	failed := false
+	output.WriteString("KIND\tNAME\tRESULT\n")
+	for _, r := range cloned {
+		result := "applied"
+		if r.skip != "" {
+			result = "skipped: " + r.skip
+		} else {
+			opts := metav1.ApplyOptions{FieldManager: h.c.FieldManager()}
+			if _, err := h.dyn.Resource(r.gvr).Namespace(args.TargetNamespace).Apply(ctx, r.obj.GetName(), r.obj, opts); err != nil {
+				failed = true
+				result = "failed: " + err.Error()
+			}
+		}
+		output.WriteString(fmt.Sprintf("%s\t%s\t%s\n", r.kind, cloner.renameRef(r.kind, r.name), result))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+		IsError: failed,
+	}, nil, nil
+}
+
+// ensureNamespace creates the namespace name with labels if it doesn't exist.
+func (h *handlers) ensureNamespace(ctx context.Context, name string, labels map[string]string) error {
+	namespaces := h.dyn.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"})
+	_, err := namespaces.Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get namespace %s: %w", name, err)
+	}
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	ns.SetLabels(labels)
+	if _, err := namespaces.Create(ctx, ns, metav1.CreateOptions{FieldManager: h.c.FieldManager()}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %s: %w", name, err)
+	}
+	return nil
+}
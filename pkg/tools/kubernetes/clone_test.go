@@ -0,0 +1,184 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNamespaceClonerSkipReason(t *testing.T) {
+	cloner := &namespaceCloner{secrets: cloneSecretsSkip}
+	for _, tc := range []struct {
+		name     string
+		manifest string
+		want     string
+	}{
+		{
+			name:     "config map",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-config\n",
+		},
+		{
+			name:     "root ca",
+			manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: kube-root-ca.crt\n",
+			want:     "created in every namespace",
+		},
+		{
+			name:     "default service account",
+			manifest: "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: default\n",
+			want:     "created in every namespace",
+		},
+		{
+			name:     "secret",
+			manifest: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: web-tls\n",
+			want:     "secrets=skip",
+		},
+		{
+			name:     "token",
+			manifest: "apiVersion: v1\nkind: Secret\ntype: kubernetes.io/service-account-token\nmetadata:\n  name: web-token\n",
+			want:     "service account token",
+		},
+		{
+			name: "owned",
+			manifest: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-7d4b9
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: ReplicaSet
+    name: web-7d4b
+    uid: 0b5c
+    controller: true
+`,
+			want: "managed by replicaset",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cloner.skipReason(decodeObject(t, tc.manifest)); got != tc.want {
+				t.Errorf("skipReason() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceClonerClone(t *testing.T) {
+	cloner := &namespaceCloner{
+		target:  "pr-123",
+		prefix:  "pr-",
+		labels:  map[string]string{"env": "pr-123"},
+		secrets: cloneSecretsEmpty,
+		copied:  map[string]bool{"ConfigMap/web-config": true, "Secret/web-env": true, "ServiceAccount/web": true},
+	}
+	for _, tc := range []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "deployment",
+			in: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  template:
+    spec:
+      serviceAccountName: web
+      containers:
+      - name: web
+        envFrom:
+        - configMapRef:
+            name: web-config
+        - secretRef:
+            name: shared-env
+        env:
+        - name: PASSWORD
+          valueFrom:
+            secretKeyRef:
+              name: web-env
+              key: password
+      volumes:
+      - name: config
+        configMap:
+          name: web-config
+`,
+			want: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: pr-web
+  namespace: pr-123
+  labels:
+    env: pr-123
+spec:
+  template:
+    spec:
+      serviceAccountName: pr-web
+      containers:
+      - name: web
+        envFrom:
+        - configMapRef:
+            name: pr-web-config
+        - secretRef:
+            name: shared-env
+        env:
+        - name: PASSWORD
+          valueFrom:
+            secretKeyRef:
+              name: pr-web-env
+              key: password
+      volumes:
+      - name: config
+        configMap:
+          name: pr-web-config
+`,
+		},
+		{
+			name: "secret",
+			in: `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-env
+data:
+  password: c2VjcmV0
+`,
+			want: `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: pr-web-env
+  namespace: pr-123
+  labels:
+    env: pr-123
+stringData:
+  password: ""
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			in, want := decodeObject(t, tc.in), decodeObject(t, tc.want)
+			cloner.clone(in)
+			if diff := cmp.Diff(want.Object, in.Object); diff != "" {
+				t.Errorf("clone() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,645 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/cache"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/container/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// GKEExportClusterConfigToolDescription contains the documentation for the GKE Export Cluster Config tool.
+// It is formatted in Markdown.
+const GKEExportClusterConfigToolDescription = `
+This tool renders the configuration of an existing GKE cluster as infrastructure as code: a Terraform *google_container_cluster* resource with a *google_container_node_pool* resource per node pool, or the equivalent Config Connector *ContainerCluster* and *ContainerNodePool* manifests. Use it to codify a cluster that was created imperatively, e.g. with gcloud, the console or this server.
+
+Only the commonly used settings are exported: location, network, release channel and version, IP allocation, private cluster, authorized networks, Workload Identity, Dataplane V2 and network policy, add-ons, logging and monitoring, maintenance window, labels, and the size, autoscaling, management and machine configuration of node pools. Review the output before use, and check that *terraform plan* reports no changes after importing the cluster.
+
+## Arguments
+
+* *name*: The name of the cluster.
+* *location*: The location (region or zone) of the cluster.
+* *project_id*: (Optional) The project of the cluster. Defaults to the default project.
+* *format*: (Optional) *terraform* or *config_connector*. Defaults to *terraform*.
+* *refresh*: (Optional) Set to *true* to bypass the cache of cluster details.
+
+## Response Format
+
+The Terraform configuration or the Config Connector manifests, followed by the commands to import the existing cluster and node pools.
+`
+
+type gkeExportClusterConfigArgs struct {
+	ProjectID string `json:"project_id,omitempty"`
+	Location  string `json:"location"`
+	Name      string `json:"name"`
+	Format    string `json:"format,omitempty"`
+	Refresh   bool   `json:"refresh,omitempty"`
+}
+
+// getCluster returns the cluster, from the cache unless refresh is set.
+func (h *handlers) getCluster(ctx context.Context, projectID, location, name string, refresh bool) (*container.Cluster, error) {
+	if projectID == "" {
+		projectID = h.c.DefaultProjectID()
+	}
+	fullName := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, name)
+	cluster, err := cache.GetOrLoad(h.cache, "cluster:"+fullName, refresh, func() (*container.Cluster, error) {
+		return h.containerService.Projects.Locations.Clusters.Get(fullName).Context(ctx).Do()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+	return cluster, nil
+}
+
+// configBlock is a format-neutral block of configuration: the body of a
+// Terraform block, or a Config Connector object. Field names are snake_case,
+// as in Terraform.
+type configBlock struct {
+	fields []configField
+	// repeated blocks are rendered as repeated Terraform blocks and as
+	// lists in Config Connector.
+	repeated bool
+}
+
+type configField struct {
+	name string
+	// value is a string, bool, int64, []string, map[string]string,
+	// configExpr or *configBlock.
+	value any
+}
+
+// configExpr is a Terraform expression, written without quotes.
+type configExpr string
+
+// set adds the field name with value.
+func (b *configBlock) set(name string, value any) {
+	b.fields = append(b.fields, configField{name: name, value: value})
+}
+
+// opt adds the field name with value, unless value is the zero value.
+func (b *configBlock) opt(name string, value any) {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return
+		}
+	case bool:
+		if !v {
+			return
+		}
+	case int64:
+		if v == 0 {
+			return
+		}
+	case []string:
+		if len(v) == 0 {
+			return
+		}
+	case map[string]string:
+		if len(v) == 0 {
+			return
+		}
+	case *configBlock:
+		if v == nil || len(v.fields) == 0 {
+			return
+		}
+	}
+	b.set(name, value)
+}
+
+// block adds and returns the nested block name.
+func (b *configBlock) block(name string) *configBlock {
+	child := &configBlock{}
+	b.set(name, child)
+	return child
+}
+
+// ref adds a reference to another resource: a plain value in Terraform, an
+// external reference in Config Connector.
+func (b *configBlock) ref(name, value string, kcc bool) {
+	if value == "" {
+		return
+	}
+	if !kcc {
+		b.set(name, value)
+		return
+	}
+	ref := &configBlock{}
+	ref.set("external", value)
+	b.set(name+"_ref", ref)
+}
+
+// clusterConfig returns the configuration of cluster, without its name,
+// project and labels, which are rendered by each format.
+func clusterConfig(c *container.Cluster, kcc bool) *configBlock {
+	b := &configBlock{}
+	b.set("location", c.Location)
+	if locations := nodeLocations(c.Location, c.Locations); len(locations) > 0 {
+		b.set("node_locations", locations)
+	}
+	if kcc && c.Network != "" {
+		b.ref("network", fmt.Sprintf("projects/%s/global/networks/%s", projectOf(c), c.Network), true)
+	} else {
+		b.ref("network", c.Network, false)
+	}
+	if kcc && c.Subnetwork != "" {
+		b.ref("subnetwork", fmt.Sprintf("projects/%s/regions/%s/subnetworks/%s", projectOf(c), regionOf(c.Location), c.Subnetwork), true)
+	} else {
+		b.ref("subnetwork", c.Subnetwork, false)
+	}
+	if c.Description != "" {
+		b.set("description", c.Description)
+	}
+
+	autopilot := c.Autopilot != nil && c.Autopilot.Enabled
+	if autopilot {
+		b.set("enable_autopilot", true)
+	} else {
+		// Node pools are separate resources.
+		if !kcc {
+			b.set("remove_default_node_pool", true)
+		}
+		b.set("initial_node_count", int64(1))
+	}
+
+	if c.ReleaseChannel != nil && c.ReleaseChannel.Channel != "" && c.ReleaseChannel.Channel != "UNSPECIFIED" {
+		b.block("release_channel").set("channel", c.ReleaseChannel.Channel)
+	} else {
+		b.opt("min_master_version", c.CurrentMasterVersion)
+	}
+
+	if p := c.IpAllocationPolicy; p != nil && p.UseIpAliases {
+		b.set("networking_mode", "VPC_NATIVE")
+		ip := &configBlock{}
+		ip.opt("cluster_secondary_range_name", p.ClusterSecondaryRangeName)
+		ip.opt("services_secondary_range_name", p.ServicesSecondaryRangeName)
+		if p.ClusterSecondaryRangeName == "" {
+			ip.opt("cluster_ipv4_cidr_block", p.ClusterIpv4CidrBlock)
+		}
+		if p.ServicesSecondaryRangeName == "" {
+			ip.opt("services_ipv4_cidr_block", p.ServicesIpv4CidrBlock)
+		}
+		if p.StackType != "" && p.StackType != "IPV4" {
+			ip.set("stack_type", p.StackType)
+		}
+		b.set("ip_allocation_policy", ip)
+	}
+
+	if p := c.PrivateClusterConfig; p != nil && p.EnablePrivateNodes {
+		private := b.block("private_cluster_config")
+		private.set("enable_private_nodes", true)
+		private.set("enable_private_endpoint", p.EnablePrivateEndpoint)
+		private.opt("master_ipv4_cidr_block", p.MasterIpv4CidrBlock)
+		if p.MasterGlobalAccessConfig != nil && p.MasterGlobalAccessConfig.Enabled {
+			private.block("master_global_access_config").set("enabled", true)
+		}
+	}
+
+	if n := c.MasterAuthorizedNetworksConfig; n != nil && n.Enabled {
+		networks := b.block("master_authorized_networks_config")
+		for _, cidr := range n.CidrBlocks {
+			block := &configBlock{repeated: true}
+			block.set("cidr_block", cidr.CidrBlock)
+			block.opt("display_name", cidr.DisplayName)
+			networks.set("cidr_blocks", block)
+		}
+		if len(n.CidrBlocks) == 0 && kcc {
+			networks.set("cidr_blocks", []string{})
+		}
+	}
+
+	if w := c.WorkloadIdentityConfig; w != nil && w.WorkloadPool != "" {
+		b.block("workload_identity_config").set("workload_pool", w.WorkloadPool)
+	}
+	if n := c.NetworkConfig; n != nil && n.DatapathProvider != "" && n.DatapathProvider != "DATAPATH_PROVIDER_UNSPECIFIED" && n.DatapathProvider != "LEGACY_DATAPATH" {
+		b.set("datapath_provider", n.DatapathProvider)
+	}
+	if p := c.NetworkPolicy; p != nil && p.Enabled {
+		policy := b.block("network_policy")
+		policy.set("enabled", true)
+		policy.opt("provider", p.Provider)
+	}
+
+	if a := c.AddonsConfig; a != nil && !autopilot {
+		addons := &configBlock{}
+		if a.HttpLoadBalancing != nil && a.HttpLoadBalancing.Disabled {
+			addons.block("http_load_balancing").set("disabled", true)
+		}
+		if a.HorizontalPodAutoscaling != nil && a.HorizontalPodAutoscaling.Disabled {
+			addons.block("horizontal_pod_autoscaling").set("disabled", true)
+		}
+		if a.NetworkPolicyConfig != nil && !a.NetworkPolicyConfig.Disabled {
+			addons.block("network_policy_config").set("disabled", false)
+		}
+		if a.GcpFilestoreCsiDriverConfig != nil && a.GcpFilestoreCsiDriverConfig.Enabled {
+			addons.block("gcp_filestore_csi_driver_config").set("enabled", true)
+		}
+		if a.GcsFuseCsiDriverConfig != nil && a.GcsFuseCsiDriverConfig.Enabled {
+			addons.block("gcs_fuse_csi_driver_config").set("enabled", true)
+		}
+		if a.DnsCacheConfig != nil && a.DnsCacheConfig.Enabled {
+			addons.block("dns_cache_config").set("enabled", true)
+		}
+		b.opt("addons_config", addons)
+	}
+
+	if c.LoggingService != "" && c.LoggingService != "logging.googleapis.com/kubernetes" {
+		b.set("logging_service", c.LoggingService)
+	}
+	if c.MonitoringService != "" && c.MonitoringService != "monitoring.googleapis.com/kubernetes" {
+		b.set("monitoring_service", c.MonitoringService)
+	}
+	if c.VerticalPodAutoscaling != nil && c.VerticalPodAutoscaling.Enabled && !autopilot {
+		b.block("vertical_pod_autoscaling").set("enabled", true)
+	}
+	if a := c.BinaryAuthorization; a != nil && a.EvaluationMode != "" && a.EvaluationMode != "DISABLED" {
+		b.block("binary_authorization").set("evaluation_mode", a.EvaluationMode)
+	}
+	if c.ShieldedNodes != nil && c.ShieldedNodes.Enabled && !autopilot {
+		b.set("enable_shielded_nodes", true)
+	}
+
+	if p := c.MaintenancePolicy; p != nil && p.Window != nil {
+		switch w := p.Window; {
+		case w.DailyMaintenanceWindow != nil:
+			b.block("maintenance_policy").block("daily_maintenance_window").set("start_time", w.DailyMaintenanceWindow.StartTime)
+		case w.RecurringWindow != nil && w.RecurringWindow.Window != nil:
+			recurring := b.block("maintenance_policy").block("recurring_window")
+			recurring.set("start_time", w.RecurringWindow.Window.StartTime)
+			recurring.set("end_time", w.RecurringWindow.Window.EndTime)
+			recurring.set("recurrence", w.RecurringWindow.Recurrence)
+		}
+	}
+	return b
+}
+
+// nodePoolConfig returns the configuration of the node pool np of a
+// cluster, without its cluster, name and project, which are rendered by each
+// format.
+func nodePoolConfig(c *container.Cluster, np *container.NodePool, kcc bool) *configBlock {
+	b := &configBlock{}
+	b.set("location", c.Location)
+	if locations := nodeLocations(c.Location, np.Locations); len(locations) > 0 && !slices.Equal(locations, nodeLocations(c.Location, c.Locations)) {
+		b.set("node_locations", locations)
+	}
+	autoUpgrade := np.Management != nil && np.Management.AutoUpgrade
+	if !autoUpgrade {
+		b.opt("version", np.Version)
+	}
+	b.opt("initial_node_count", np.InitialNodeCount)
+	if a := np.Autoscaling; a != nil && a.Enabled {
+		autoscaling := b.block("autoscaling")
+		if a.TotalMaxNodeCount > 0 {
+			autoscaling.set("total_min_node_count", a.TotalMinNodeCount)
+			autoscaling.set("total_max_node_count", a.TotalMaxNodeCount)
+		} else {
+			autoscaling.set("min_node_count", a.MinNodeCount)
+			autoscaling.set("max_node_count", a.MaxNodeCount)
+		}
+		autoscaling.opt("location_policy", a.LocationPolicy)
+	}
+	if m := np.Management; m != nil {
+		management := b.block("management")
+		management.set("auto_repair", m.AutoRepair)
+		management.set("auto_upgrade", m.AutoUpgrade)
+	}
+	if np.MaxPodsConstraint != nil {
+		b.opt("max_pods_per_node", np.MaxPodsConstraint.MaxPodsPerNode)
+	}
+
+	if nc := np.Config; nc != nil {
+		node := &configBlock{}
+		node.opt("machine_type", nc.MachineType)
+		node.opt("disk_size_gb", nc.DiskSizeGb)
+		node.opt("disk_type", nc.DiskType)
+		node.opt("image_type", nc.ImageType)
+		node.opt("spot", nc.Spot)
+		node.opt("preemptible", nc.Preemptible)
+		node.opt("local_ssd_count", nc.LocalSsdCount)
+		if nc.ServiceAccount != "" && nc.ServiceAccount != "default" {
+			node.ref("service_account", nc.ServiceAccount, kcc)
+		}
+		node.opt("oauth_scopes", nc.OauthScopes)
+		node.opt("labels", nc.Labels)
+		node.opt("tags", nc.Tags)
+		for _, t := range nc.Taints {
+			taint := &configBlock{repeated: true}
+			taint.set("key", t.Key)
+			taint.set("value", t.Value)
+			taint.set("effect", t.Effect)
+			node.set("taint", taint)
+		}
+		for _, a := range nc.Accelerators {
+			accelerator := &configBlock{repeated: true}
+			accelerator.set("type", a.AcceleratorType)
+			accelerator.set("count", a.AcceleratorCount)
+			node.set("guest_accelerator", accelerator)
+		}
+		if w := nc.WorkloadMetadataConfig; w != nil && w.Mode != "" && w.Mode != "MODE_UNSPECIFIED" {
+			node.block("workload_metadata_config").set("mode", w.Mode)
+		}
+		if s := nc.ShieldedInstanceConfig; s != nil && s.EnableSecureBoot {
+			node.block("shielded_instance_config").set("enable_secure_boot", true)
+		}
+		b.opt("node_config", node)
+	}
+	return b
+}
+
+// nodeLocations returns the zones of locations, except for the zone of a
+// zonal cluster, which is implicit.
+func nodeLocations(location string, locations []string) []string {
+	var zones []string
+	for _, l := range locations {
+		if l != location {
+			zones = append(zones, l)
+		}
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// projectOf returns the project of cluster from its self link.
+func projectOf(c *container.Cluster) string {
+	if _, rest, ok := strings.Cut(c.SelfLink, "/projects/"); ok {
+		project, _, _ := strings.Cut(rest, "/")
+		return project
+	}
+	return ""
+}
+
+// regionOf returns the region of a location, i.e. a region or a zone.
+func regionOf(location string) string {
+	if parts := strings.Split(location, "-"); len(parts) == 3 {
+		return parts[0] + "-" + parts[1]
+	}
+	return location
+}
+
+var terraformIdentifierInvalid = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// terraformIdentifier returns a Terraform resource name based on name.
+func terraformIdentifier(name string) string {
+	id := terraformIdentifierInvalid.ReplaceAllString(name, "_")
+	if id == "" || (id[0] >= '0' && id[0] <= '9') || id[0] == '-' {
+		id = "_" + id
+	}
+	return id
+}
+
+// hclString quotes s as an HCL string literal.
+func hclString(s string) string {
+	q := fmt.Sprintf("%q", s)
+	// Escape template sequences.
+	q = strings.ReplaceAll(q, "${", "$${")
+	return strings.ReplaceAll(q, "%{", "%%{")
+}
+
+// hclValue formats an attribute value.
+func hclValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return hclString(v)
+	case configExpr:
+		return string(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = hclString(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	case map[string]string:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, k := range keys {
+			pairs[i] = hclString(k) + " = " + hclString(v[k])
+		}
+		return "{ " + strings.Join(pairs, ", ") + " }"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// writeHCL writes the fields of b at the given depth, aligning the equal
+// signs of consecutive attributes like terraform fmt.
+func writeHCL(out *strings.Builder, b *configBlock, depth int) {
+	indent := strings.Repeat("  ", depth)
+	var attrs []configField
+	flush := func() {
+		width := 0
+		for _, a := range attrs {
+			width = max(width, len(a.name))
+		}
+		for _, a := range attrs {
+			out.WriteString(fmt.Sprintf("%s%-*s = %s\n", indent, width, a.name, hclValue(a.value)))
+		}
+		attrs = nil
+	}
+	for _, f := range b.fields {
+		child, ok := f.value.(*configBlock)
+		if !ok {
+			attrs = append(attrs, f)
+			continue
+		}
+		flush()
+		out.WriteString(indent + f.name + " {\n")
+		writeHCL(out, child, depth+1)
+		out.WriteString(indent + "}\n")
+	}
+	flush()
+}
+
+// terraformConfig renders cluster as Terraform resources.
+func terraformConfig(c *container.Cluster) string {
+	id := terraformIdentifier(c.Name)
+	project := projectOf(c)
+	var out strings.Builder
+
+	cluster := &configBlock{}
+	cluster.opt("project", project)
+	cluster.set("name", c.Name)
+	cluster.fields = append(cluster.fields, clusterConfig(c, false).fields...)
+	cluster.opt("resource_labels", c.ResourceLabels)
+	out.WriteString(fmt.Sprintf("resource \"google_container_cluster\" %q {\n", id))
+	writeHCL(&out, cluster, 1)
+	out.WriteString("}\n")
+
+	if c.Autopilot != nil && c.Autopilot.Enabled {
+		return out.String()
+	}
+	for _, np := range c.NodePools {
+		pool := &configBlock{}
+		pool.opt("project", project)
+		pool.set("name", np.Name)
+		pool.set("cluster", configExpr("google_container_cluster."+id+".name"))
+		pool.fields = append(pool.fields, nodePoolConfig(c, np, false).fields...)
+		out.WriteString(fmt.Sprintf("\nresource \"google_container_node_pool\" %q {\n", terraformIdentifier(c.Name+"_"+np.Name)))
+		writeHCL(&out, pool, 1)
+		out.WriteString("}\n")
+	}
+	return out.String()
+}
+
+// snakeToCamel converts a Terraform field name to a Config Connector one.
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// kccObject returns the Config Connector representation of b.
+func kccObject(b *configBlock) map[string]any {
+	obj := map[string]any{}
+	for _, f := range b.fields {
+		name := snakeToCamel(f.name)
+		switch v := f.value.(type) {
+		case *configBlock:
+			if v.repeated {
+				list, _ := obj[name].([]any)
+				obj[name] = append(list, kccObject(v))
+			} else {
+				obj[name] = kccObject(v)
+			}
+		case []string:
+			list := make([]any, len(v))
+			for i, s := range v {
+				list[i] = s
+			}
+			obj[name] = list
+		case map[string]string:
+			m := map[string]any{}
+			for k, s := range v {
+				m[k] = s
+			}
+			obj[name] = m
+		default:
+			obj[name] = v
+		}
+	}
+	return obj
+}
+
+// configConnectorConfig renders cluster as Config Connector manifests.
+func configConnectorConfig(c *container.Cluster) (string, error) {
+	project := projectOf(c)
+	autopilot := c.Autopilot != nil && c.Autopilot.Enabled
+	metadata := func(name string, cluster bool) map[string]any {
+		m := map[string]any{"name": name}
+		annotations := map[string]any{}
+		if project != "" {
+			annotations["cnrm.cloud.google.com/project-id"] = project
+		}
+		if cluster && !autopilot {
+			annotations["cnrm.cloud.google.com/remove-default-node-pool"] = "true"
+		}
+		if len(annotations) > 0 {
+			m["annotations"] = annotations
+		}
+		return m
+	}
+
+	var out strings.Builder
+	clusterMeta := metadata(c.Name, true)
+	if len(c.ResourceLabels) > 0 {
+		labels := map[string]any{}
+		for k, v := range c.ResourceLabels {
+			labels[k] = v
+		}
+		clusterMeta["labels"] = labels
+	}
+	objects := []map[string]any{{
+		"apiVersion": "container.cnrm.cloud.google.com/v1beta1",
+		"kind":       "ContainerCluster",
+		"metadata":   clusterMeta,
+		"spec":       kccObject(clusterConfig(c, true)),
+	}}
+	if !autopilot {
+		for _, np := range c.NodePools {
+			spec := kccObject(nodePoolConfig(c, np, true))
+			spec["clusterRef"] = map[string]any{"name": c.Name}
+			spec["resourceID"] = np.Name
+			objects = append(objects, map[string]any{
+				"apiVersion": "container.cnrm.cloud.google.com/v1beta1",
+				"kind":       "ContainerNodePool",
+				"metadata":   metadata(c.Name+"-"+np.Name, false),
+				"spec":       spec,
+			})
+		}
+	}
+	for _, obj := range objects {
+		if err := writeYAMLDocument(&out, &unstructured.Unstructured{Object: obj}); err != nil {
+			return "", err
+		}
+	}
+	return out.String(), nil
+}
+
+func (h *handlers) gkeExportClusterConfig(ctx context.Context, _ *mcp.CallToolRequest, args *gkeExportClusterConfigArgs) (*mcp.CallToolResult, any, error) {
+	cluster, err := h.getCluster(ctx, args.ProjectID, args.Location, args.Name, args.Refresh)
+	if err != nil {
+		return nil, nil, err
+	}
+	project := projectOf(cluster)
+	if project == "" {
+		project = args.ProjectID
+	}
+
+	var output strings.Builder
+	switch args.Format {
+	case "", "terraform":
+		output.WriteString(terraformConfig(cluster))
+		output.WriteString("\n# Import the existing cluster and node pools with:\n")
+		output.WriteString(fmt.Sprintf("#   terraform import google_container_cluster.%s projects/%s/locations/%s/clusters/%s\n",
+			terraformIdentifier(cluster.Name), project, cluster.Location, cluster.Name))
+		if cluster.Autopilot == nil || !cluster.Autopilot.Enabled {
+			for _, np := range cluster.NodePools {
+				output.WriteString(fmt.Sprintf("#   terraform import google_container_node_pool.%s %s/%s/%s/%s\n",
+					terraformIdentifier(cluster.Name+"_"+np.Name), project, cluster.Location, cluster.Name, np.Name))
+			}
+		}
+	case "config_connector":
+		manifests, err := configConnectorConfig(cluster)
+		if err != nil {
+			return nil, nil, err
+		}
+		output.WriteString(manifests)
+		output.WriteString("\n# Config Connector acquires the existing cluster and node pools when these manifests are applied,\n")
+		output.WriteString("# because their names match. Review that the spec matches the cluster before applying them.\n")
+	default:
+		return nil, nil, fmt.Errorf("invalid format %q: must be terraform or config_connector", args.Format)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/container/v1"
+)
+
+func testExportCluster() *container.Cluster {
+	return &container.Cluster{
+		Name:                 "prod",
+		Location:             "us-central1",
+		Locations:            []string{"us-central1-b", "us-central1-a"},
+		SelfLink:             "https://container.googleapis.com/v1/projects/my-project/locations/us-central1/clusters/prod",
+		Network:              "default",
+		Subnetwork:           "default",
+		CurrentMasterVersion: "1.30.5-gke.1014001",
+		ReleaseChannel:       &container.ReleaseChannel{Channel: "REGULAR"},
+		IpAllocationPolicy: &container.IPAllocationPolicy{
+			UseIpAliases:          true,
+			ClusterIpv4CidrBlock:  "10.4.0.0/14",
+			ServicesIpv4CidrBlock: "10.8.0.0/20",
+		},
+		MasterAuthorizedNetworksConfig: &container.MasterAuthorizedNetworksConfig{
+			Enabled:    true,
+			CidrBlocks: []*container.CidrBlock{{CidrBlock: "10.0.0.0/8", DisplayName: "corp"}},
+		},
+		WorkloadIdentityConfig: &container.WorkloadIdentityConfig{WorkloadPool: "my-project.svc.id.goog"},
+		LoggingService:         "logging.googleapis.com/kubernetes",
+		ResourceLabels:         map[string]string{"env": "prod"},
+		NodePools: []*container.NodePool{{
+			Name:             "pool-1",
+			InitialNodeCount: 1,
+			Locations:        []string{"us-central1-a", "us-central1-b"},
+			Version:          "1.30.5-gke.1014001",
+			Autoscaling:      &container.NodePoolAutoscaling{Enabled: true, MinNodeCount: 1, MaxNodeCount: 5},
+			Management:       &container.NodeManagement{AutoRepair: true, AutoUpgrade: true},
+			Config: &container.NodeConfig{
+				MachineType:    "e2-standard-4",
+				DiskSizeGb:     100,
+				ServiceAccount: "nodes@my-project.iam.gserviceaccount.com",
+				Taints:         []*container.NodeTaint{{Key: "dedicated", Value: "web", Effect: "NO_SCHEDULE"}},
+			},
+		}},
+	}
+}
+
+func TestTerraformConfig(t *testing.T) {
+	got := terraformConfig(testExportCluster())
+	want := `resource "google_container_cluster" "prod" {
+  project                  = "my-project"
+  name                     = "prod"
+  location                 = "us-central1"
+  node_locations           = ["us-central1-a", "us-central1-b"]
+  network                  = "default"
+  subnetwork               = "default"
+  remove_default_node_pool = true
+  initial_node_count       = 1
+  release_channel {
+    channel = "REGULAR"
+  }
+  networking_mode = "VPC_NATIVE"
+  ip_allocation_policy {
+    cluster_ipv4_cidr_block  = "10.4.0.0/14"
+    services_ipv4_cidr_block = "10.8.0.0/20"
+  }
+  master_authorized_networks_config {
+    cidr_blocks {
+      cidr_block   = "10.0.0.0/8"
+      display_name = "corp"
+    }
+  }
+  workload_identity_config {
+    workload_pool = "my-project.svc.id.goog"
+  }
+  resource_labels = { "env" = "prod" }
+}
+
+resource "google_container_node_pool" "prod_pool-1" {
+  project            = "my-project"
+  name               = "pool-1"
+  cluster            = google_container_cluster.prod.name
+  location           = "us-central1"
+  initial_node_count = 1
+  autoscaling {
+    min_node_count = 1
+    max_node_count = 5
+  }
+  management {
+    auto_repair  = true
+    auto_upgrade = true
+  }
+  node_config {
+    machine_type    = "e2-standard-4"
+    disk_size_gb    = 100
+    service_account = "nodes@my-project.iam.gserviceaccount.com"
+    taint {
+      key    = "dedicated"
+      value  = "web"
+      effect = "NO_SCHEDULE"
+    }
+  }
+}
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("terraformConfig() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTerraformConfigAutopilot(t *testing.T) {
+	c := testExportCluster()
+	c.Autopilot = &container.Autopilot{Enabled: true}
+	got := terraformConfig(c)
+	if !strings.Contains(got, "enable_autopilot = true") {
+		t.Errorf("terraformConfig() = %q, want enable_autopilot", got)
+	}
+	for _, unwanted := range []string{"google_container_node_pool", "remove_default_node_pool"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("terraformConfig() = %q, want no %s for Autopilot clusters", got, unwanted)
+		}
+	}
+}
+
+func TestConfigConnectorConfig(t *testing.T) {
+	got, err := configConnectorConfig(testExportCluster())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"kind: ContainerCluster",
+		"cnrm.cloud.google.com/project-id: my-project",
+		"cnrm.cloud.google.com/remove-default-node-pool: \"true\"",
+		"external: projects/my-project/global/networks/default",
+		"external: projects/my-project/regions/us-central1/subnetworks/default",
+		"servicesIpv4CidrBlock: 10.8.0.0/20",
+		"workloadPool: my-project.svc.id.goog",
+		"---\napiVersion: container.cnrm.cloud.google.com/v1beta1\nkind: ContainerNodePool",
+		"name: prod-pool-1",
+		"clusterRef:\n    name: prod",
+		"resourceID: pool-1",
+		"serviceAccountRef:\n      external: nodes@my-project.iam.gserviceaccount.com",
+		"taint:\n    - effect: NO_SCHEDULE",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("configConnectorConfig() = %s\nwant it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "removeDefaultNodePool") {
+		t.Errorf("configConnectorConfig() = %s\nwant no removeDefaultNodePool field", got)
+	}
+}
+
+func TestHCLString(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"plain", `"plain"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"${var.x}", `"$${var.x}"`},
+		{"%{if}", `"%%{if}"`},
+	} {
+		if got := hclString(tc.in); got != tc.want {
+			t.Errorf("hclString(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTerraformIdentifier(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"prod", "prod"},
+		{"prod-1", "prod-1"},
+		{"1st.cluster", "_1st_cluster"},
+	} {
+		if got := terraformIdentifier(tc.in); got != tc.want {
+			t.Errorf("terraformIdentifier(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ClusterInfoToolDescription contains the documentation for the Kubernetes Cluster Info tool.
+// It is formatted in Markdown.
+const ClusterInfoToolDescription = `
+This tool answers "which cluster am I talking to, as whom, and can I reach it": it reports the kubeconfig context used by the server, the API server URL, the identity of the server as seen by the API server, the Kubernetes version, and the connectivity and latency to the API server. Call it first when other tools fail, or before changing resources, to check that the right cluster is targeted.
+
+Unlike kube_get_clusterinfo, which lists the addresses of the cluster services, this tool checks the client configuration and the connection.
+
+## Arguments
+
+None.
+
+## Response Format
+
+Context: gke_my-project_us-central1_prod
+Cluster: gke_my-project_us-central1_prod
+API server: https://34.123.45.67
+User: gke_my-project_us-central1_prod (exec plugin gke-gcloud-auth-plugin)
+Authenticated as: alice@example.com (groups: system:authenticated)
+Default namespace: default
+Server version: v1.31.4-gke.1256000 (linux/amd64)
+Connectivity: OK, /version in 85ms, /readyz in 80ms
+`
+
+type clusterInfoArgs struct{}
+
+// kubeconfigInfo describes the kubeconfig context used by the server.
+type kubeconfigInfo struct {
+	context string
+	cluster string
+	user    string
+	// auth describes how the user authenticates.
+	auth string
+}
+
+// describeKubeconfig returns the description of the context contextName of
+// raw, or of its current context if contextName is empty.
+func describeKubeconfig(raw clientcmdapi.Config, contextName string) kubeconfigInfo {
+	if contextName == "" {
+		contextName = raw.CurrentContext
+	}
+	info := kubeconfigInfo{context: contextName}
+	kubeContext, ok := raw.Contexts[contextName]
+	if !ok {
+		return info
+	}
+	info.cluster = kubeContext.Cluster
+	info.user = kubeContext.AuthInfo
+	authInfo, ok := raw.AuthInfos[kubeContext.AuthInfo]
+	if !ok {
+		return info
+	}
+	switch {
+	case authInfo.Exec != nil:
+		info.auth = "exec plugin " + authInfo.Exec.Command
+	case authInfo.AuthProvider != nil:
+		info.auth = "auth provider " + authInfo.AuthProvider.Name
+	case authInfo.Token != "" || authInfo.TokenFile != "":
+		info.auth = "bearer token"
+	case len(authInfo.ClientCertificateData) > 0 || authInfo.ClientCertificate != "":
+		info.auth = "client certificate"
+	case authInfo.Username != "":
+		info.auth = "basic authentication"
+	}
+	return info
+}
+
+func (h *handlers) clusterInfo(ctx context.Context, _ *mcp.CallToolRequest, _ *clusterInfoArgs) (*mcp.CallToolResult, any, error) {
+	var output strings.Builder
+	info := kubeconfigInfo{context: h.c.KubeContext()}
+	if raw, err := kubeClientConfig(h.c).RawConfig(); err == nil {
+		info = describeKubeconfig(raw, h.c.KubeContext())
+	}
+	output.WriteString(fmt.Sprintf("Context: %s\n", valueOrNone(info.context)))
+	output.WriteString(fmt.Sprintf("Cluster: %s\n", valueOrNone(info.cluster)))
+	output.WriteString(fmt.Sprintf("API server: %s\n", h.restConfig.Host))
+	user := valueOrNone(info.user)
+	switch {
+	case h.c.Credentials().KubeBearerToken != "":
+		user = "bearer token supplied by the client"
+	case info.auth != "":
+		user += " (" + info.auth + ")"
+	}
+	output.WriteString(fmt.Sprintf("User: %s\n", user))
+
+	// SelfSubjectReviews are available from Kubernetes 1.28.
+	review, err := h.clientset.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err == nil {
+		userInfo := review.Status.UserInfo
+		output.WriteString(fmt.Sprintf("Authenticated as: %s (groups: %s)\n", valueOrNone(userInfo.Username), valueOrNone(strings.Join(userInfo.Groups, ", "))))
+	}
+	output.WriteString(fmt.Sprintf("Default namespace: %s\n", h.defaultNamespace))
+
+	start := time.Now()
+	version, versionErr := h.dc.ServerVersion()
+	versionLatency := time.Since(start)
+	if versionErr == nil {
+		output.WriteString(fmt.Sprintf("Server version: %s (%s)\n", version.GitVersion, version.Platform))
+	}
+	start = time.Now()
+	readyErr := h.dc.RESTClient().Get().AbsPath("/readyz").Do(ctx).Error()
+	readyLatency := time.Since(start)
+
+	switch {
+	case versionErr != nil:
+		output.WriteString(fmt.Sprintf("Connectivity: FAILED, the API server can't be reached: %v\n", versionErr))
+		output.WriteString("Check the network access to the API server, e.g. authorized networks or a VPN for private clusters, and the credentials of the kubeconfig, e.g. with gcloud container clusters get-credentials.\n")
+	case readyErr != nil:
+		output.WriteString(fmt.Sprintf("Connectivity: DEGRADED, /version in %s, but /readyz failed: %v\n", versionLatency.Round(time.Millisecond), readyErr))
+	default:
+		output.WriteString(fmt.Sprintf("Connectivity: OK, /version in %s, /readyz in %s\n", versionLatency.Round(time.Millisecond), readyLatency.Round(time.Millisecond)))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestDescribeKubeconfig(t *testing.T) {
+	raw := clientcmdapi.Config{
+		CurrentContext: "prod",
+		Contexts: map[string]*clientcmdapi.Context{
+			"prod":    {Cluster: "gke-prod", AuthInfo: "gke-user"},
+			"staging": {Cluster: "gke-staging", AuthInfo: "ci"},
+			"broken":  {Cluster: "gke-broken", AuthInfo: "missing"},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"gke-user": {Exec: &clientcmdapi.ExecConfig{Command: "gke-gcloud-auth-plugin"}},
+			"ci":       {Token: "secret"},
+		},
+	}
+	for _, tc := range []struct {
+		context string
+		want    kubeconfigInfo
+	}{
+		{context: "", want: kubeconfigInfo{context: "prod", cluster: "gke-prod", user: "gke-user", auth: "exec plugin gke-gcloud-auth-plugin"}},
+		{context: "staging", want: kubeconfigInfo{context: "staging", cluster: "gke-staging", user: "ci", auth: "bearer token"}},
+		{context: "broken", want: kubeconfigInfo{context: "broken", cluster: "gke-broken", user: "missing"}},
+		{context: "unknown", want: kubeconfigInfo{context: "unknown"}},
+	} {
+		if diff := cmp.Diff(tc.want, describeKubeconfig(raw, tc.context), cmp.AllowUnexported(kubeconfigInfo{})); diff != "" {
+			t.Errorf("describeKubeconfig(%q) mismatch (-want +got):\n%s", tc.context, diff)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/container/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// parseAPISpec parses spec, the JSON or YAML of a message of the Container
+// API, into v, a pointer to its type. Unknown fields are rejected, and the
+// fields set to false, 0 or "" in spec are sent as such rather than omitted
+// as defaults.
+func parseAPISpec(spec string, v any) error {
+	data, err := yaml.YAMLToJSON([]byte(spec))
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var fields map[string]any
+	if err := d.Decode(&fields); err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("the spec is empty")
+	}
+	quoteStringNumbers(reflect.TypeOf(v), fields)
+	if data, err = json.Marshal(fields); err != nil {
+		return err
+	}
+	d = json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+	forceSendFields(reflect.ValueOf(v), fields)
+	return nil
+}
+
+// quoteStringNumbers quotes the numbers of fields, the JSON object of the
+// type t, whose fields are encoded as strings, such as the int64 fields of
+// the API, so that they can be written as numbers.
+func quoteStringNumbers(t reflect.Type, fields map[string]any) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, options, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch value := fields[name].(type) {
+		case json.Number:
+			if slices.Contains(strings.Split(options, ","), "string") {
+				fields[name] = value.String()
+			}
+		case map[string]any:
+			quoteStringNumbers(f.Type, value)
+		case []any:
+			for _, item := range value {
+				if m, ok := item.(map[string]any); ok {
+					quoteStringNumbers(f.Type, m)
+				}
+			}
+		}
+	}
+}
+
+// forceSendFields adds the fields of v, an API message, that are set in
+// fields, its JSON object, to the ForceSendFields of v, recursively, so that
+// those set to zero values are sent too.
+func forceSendFields(v reflect.Value, fields map[string]any) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	force := v.FieldByName("ForceSendFields")
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		value, ok := fields[name]
+		if !ok || name == "" || name == "-" || value == nil {
+			continue
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			forceSendFields(v.Field(i), value)
+		case []any:
+			if v.Field(i).Kind() == reflect.Slice {
+				for j := 0; j < min(v.Field(i).Len(), len(value)); j++ {
+					if m, ok := value[j].(map[string]any); ok {
+						forceSendFields(v.Field(i).Index(j), m)
+					}
+				}
+			}
+		}
+		if v.Field(i).IsZero() && force.IsValid() {
+			force.Set(reflect.Append(force, reflect.ValueOf(f.Name)))
+		}
+	}
+}
+
+// estimateClusterSpec adds the cost of the nodes of cluster, created in
+// location, to e.
+func (h *handlers) estimateClusterSpec(ctx context.Context, e *costEstimate, projectID, location string, cluster *container.Cluster) error {
+	if cluster.Autopilot != nil && cluster.Autopilot.Enabled {
+		e.lines = append(e.lines, costLine{name: "Autopilot pods, billed for their requests", monthly: -1})
+		return nil
+	}
+	pools := cluster.NodePools
+	if len(pools) == 0 {
+		pools = []*container.NodePool{{InitialNodeCount: cluster.InitialNodeCount, Config: cluster.NodeConfig}}
+	}
+	for _, pool := range pools {
+		machineType, imageType := defaultGKEMachineType, ""
+		if pool.Config != nil {
+			if pool.Config.MachineType != "" {
+				machineType = pool.Config.MachineType
+			}
+			imageType = pool.Config.ImageType
+		}
+		numNodes := pool.InitialNodeCount
+		if numNodes <= 0 {
+			numNodes = defaultGKENumNodes
+		}
+		zones := pool.Locations
+		if len(zones) == 0 {
+			zones = cluster.Locations
+		}
+		if len(zones) == 0 {
+			var err error
+			if zones, err = h.clusterZones(ctx, projectID, location); err != nil {
+				return err
+			}
+		}
+		if err := h.estimateNodes(ctx, e, projectID, machineType, numNodes, zones, isWindowsImageType(imageType)); err != nil {
+			return err
+		}
+		if pool.Config != nil {
+			e.addAccelerators(pool.Config.Accelerators, numNodes*int64(len(zones)))
+		}
+	}
+	return nil
+}
+
+// gkeCreateClusterFromSpec creates the cluster of args.ClusterSpec in
+// location.
+func (h *handlers) gkeCreateClusterFromSpec(ctx context.Context, args *gkeCreateClusterArgs, projectID, location string) (*mcp.CallToolResult, any, error) {
+	if args.MachineType != "" || args.NumNodes != 0 || args.EnableShieldedNodes != nil || args.EnableConfidentialNodes || args.EnableSecureBoot || args.EnableIntegrityMonitoring != nil {
+		return nil, nil, fmt.Errorf("cluster_spec can't be combined with the node arguments: set them in cluster_spec")
+	}
+	cluster := &container.Cluster{}
+	if err := parseAPISpec(args.ClusterSpec, cluster); err != nil {
+		return nil, nil, fmt.Errorf("invalid cluster_spec: %w", err)
+	}
+	switch {
+	case cluster.Name == "":
+		cluster.Name = args.ClusterName
+	case args.ClusterName != "" && args.ClusterName != cluster.Name:
+		return nil, nil, fmt.Errorf("cluster_name %s doesn't match the name %s of cluster_spec", args.ClusterName, cluster.Name)
+	}
+	if cluster.Name == "" {
+		return nil, nil, fmt.Errorf("cluster_name is required")
+	}
+
+	estimated := *args
+	estimated.EstimateToken = ""
+	if args.EstimateToken == "" {
+		e := &costEstimate{
+			title: fmt.Sprintf("Estimated cost of cluster %s in %s", cluster.Name, location),
+			lines: []costLine{{name: "Cluster management fee", monthly: clusterManagementFee * hoursPerMonth}},
+		}
+		if err := h.estimateClusterSpec(ctx, e, projectID, location, cluster); err != nil {
+			return nil, nil, err
+		}
+		return h.costConfirmationResult(e, "cluster", args.AllowUnknownPrice, &estimated)
+	}
+	if err := h.checkEstimateToken(&estimated, args.EstimateToken); err != nil {
+		return nil, nil, err
+	}
+	if err := h.allowGKECreate(); err != nil {
+		return nil, nil, err
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
+	op, err := h.containerService.Projects.Locations.Clusters.Create(parent, &container.CreateClusterRequest{Cluster: cluster}).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create cluster: %w", err)
+	}
+	return operationResult(op, projectID, location, "creating cluster "+cluster.Name), nil, nil
+}
+
+func (h *handlers) gkeUpdateCluster(ctx context.Context, _ *mcp.CallToolRequest, args *gkeUpdateClusterArgs) (*mcp.CallToolResult, any, error) {
+	if args.ClusterName == "" || args.UpdateSpec == "" {
+		return nil, nil, fmt.Errorf("cluster_name and update_spec are required")
+	}
+	projectID, location := args.ProjectID, args.Location
+	if projectID == "" {
+		projectID = h.c.DefaultProjectID()
+	}
+	if location == "" {
+		location = h.c.DefaultLocation()
+	}
+	if location == "" {
+		return nil, nil, fmt.Errorf("location is required: there is no default location")
+	}
+	update := &container.ClusterUpdate{}
+	if err := parseAPISpec(args.UpdateSpec, update); err != nil {
+		return nil, nil, fmt.Errorf("invalid update_spec: %w", err)
+	}
+
+	name := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, args.ClusterName)
+	op, err := h.containerService.Projects.Locations.Clusters.Update(name, &container.UpdateClusterRequest{Update: update}).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update cluster: %w", err)
+	}
+	return operationResult(op, projectID, location, "updating cluster "+args.ClusterName), nil, nil
+}
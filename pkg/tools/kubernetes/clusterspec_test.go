@@ -0,0 +1,79 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/container/v1"
+)
+
+func TestParseAPISpec(t *testing.T) {
+	spec := `
+name: web
+shieldedNodes:
+  enabled: false
+defaultMaxPodsConstraint:
+  maxPodsPerNode: 64
+nodePools:
+- name: default-pool
+  initialNodeCount: 1
+  config:
+    machineType: n2-standard-4
+    shieldedInstanceConfig:
+      enableIntegrityMonitoring: false
+`
+	cluster := &container.Cluster{}
+	if err := parseAPISpec(spec, cluster); err != nil {
+		t.Fatalf("parseAPISpec() failed: %v", err)
+	}
+	if cluster.Name != "web" || cluster.DefaultMaxPodsConstraint.MaxPodsPerNode != 64 || cluster.NodePools[0].Config.MachineType != "n2-standard-4" {
+		t.Errorf("parseAPISpec() = %+v, want the cluster of the spec", cluster)
+	}
+	data, err := json.Marshal(cluster)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	for _, want := range []string{`"shieldedNodes":{"enabled":false}`, `"shieldedInstanceConfig":{"enableIntegrityMonitoring":false}`, `"maxPodsPerNode":"64"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("the request %s doesn't contain %s", data, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		spec    string
+		wantErr string
+	}{
+		{spec: "name: web\nnodePool:\n- name: default-pool\n", wantErr: `unknown field "nodePool"`},
+		{spec: "", wantErr: "the spec is empty"},
+		{spec: "- name: web\n", wantErr: "cannot unmarshal array"},
+	} {
+		if err := parseAPISpec(tc.spec, &container.Cluster{}); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("parseAPISpec(%q) error = %v, want %q", tc.spec, err, tc.wantErr)
+		}
+	}
+}
+
+func TestParseAPISpecClusterUpdate(t *testing.T) {
+	update := &container.ClusterUpdate{}
+	if err := parseAPISpec(`{"desiredReleaseChannel": {"channel": "STABLE"}}`, update); err != nil {
+		t.Fatalf("parseAPISpec() failed: %v", err)
+	}
+	if update.DesiredReleaseChannel == nil || update.DesiredReleaseChannel.Channel != "STABLE" {
+		t.Errorf("parseAPISpec() = %+v, want the STABLE release channel", update)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// ConfigReferencesToolDescription contains the documentation for the Config References Kubernetes tool.
+// It is formatted in Markdown.
+const ConfigReferencesToolDescription = `
+This tool checks that the ConfigMaps and Secrets referenced by pods exist and contain the referenced keys. A missing ConfigMap, Secret or key makes containers fail with *CreateContainerConfigError*, or keeps pods in *ContainerCreating* when a volume can't be mounted.
+
+The following references are checked, in all containers and init containers:
+
+* *env[].valueFrom.configMapKeyRef* and *env[].valueFrom.secretKeyRef*: the object and the key.
+* *envFrom[].configMapRef* and *envFrom[].secretRef*: the object.
+* *configMap*, *secret* and *projected* volumes: the object, and the keys listed in *items*.
+* *imagePullSecrets*: the object.
+
+References marked *optional: true* are not reported. Pods of the same workload (e.g. the same ReplicaSet) are reported once.
+
+## Arguments
+
+* *namespace*: (Optional) The namespace to check. Defaults to the server's default namespace.
+* *all_namespaces*: (Optional) Set to *true* to check all namespaces.
+
+## Response Format
+
+A table with one row per broken reference:
+
+NAMESPACE  WORKLOAD             PODS  REFERENCE                                PROBLEM
+default    ReplicaSet/web-7d9f  3     container "web" env DB_PASSWORD          key "password" not found in Secret "db"
+default    ReplicaSet/web-7d9f  3     volume "config"                          ConfigMap "web-config" not found
+`
+
+type configReferencesArgs struct {
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+}
+
+// configReference is a reference from a pod to a ConfigMap or a Secret.
+type configReference struct {
+	// kind is "ConfigMap" or "Secret".
+	kind string
+	name string
+	// key is the referenced key, or empty if the whole object is
+	// referenced.
+	key      string
+	optional bool
+	// source describes where the reference is in the pod spec.
+	source string
+}
+
+// configReferences returns the ConfigMap and Secret references of spec.
+func configReferences(spec *corev1.PodSpec) []configReference {
+	var refs []configReference
+	add := func(kind, name, key string, optional *bool, source string) {
+		refs = append(refs, configReference{kind: kind, name: name, key: key, optional: ptr.Deref(optional, false), source: source})
+	}
+	addItems := func(kind, name string, items []corev1.KeyToPath, optional *bool, source string) {
+		if len(items) == 0 {
+			add(kind, name, "", optional, source)
+		}
+		for _, item := range items {
+			add(kind, name, item.Key, optional, source)
+		}
+	}
+
+	for _, s := range spec.ImagePullSecrets {
+		add("Secret", s.Name, "", nil, "imagePullSecrets")
+	}
+	for _, v := range spec.Volumes {
+		source := fmt.Sprintf("volume %q", v.Name)
+		switch {
+		case v.ConfigMap != nil:
+			addItems("ConfigMap", v.ConfigMap.Name, v.ConfigMap.Items, v.ConfigMap.Optional, source)
+		case v.Secret != nil:
+			addItems("Secret", v.Secret.SecretName, v.Secret.Items, v.Secret.Optional, source)
+		case v.Projected != nil:
+			for _, src := range v.Projected.Sources {
+				if src.ConfigMap != nil {
+					addItems("ConfigMap", src.ConfigMap.Name, src.ConfigMap.Items, src.ConfigMap.Optional, source)
+				}
+				if src.Secret != nil {
+					addItems("Secret", src.Secret.Name, src.Secret.Items, src.Secret.Optional, source)
+				}
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, env := range c.EnvFrom {
+			source := fmt.Sprintf("container %q envFrom", c.Name)
+			if env.ConfigMapRef != nil {
+				add("ConfigMap", env.ConfigMapRef.Name, "", env.ConfigMapRef.Optional, source)
+			}
+			if env.SecretRef != nil {
+				add("Secret", env.SecretRef.Name, "", env.SecretRef.Optional, source)
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			source := fmt.Sprintf("container %q env %s", c.Name, env.Name)
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				add("ConfigMap", ref.Name, ref.Key, ref.Optional, source)
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				add("Secret", ref.Name, ref.Key, ref.Optional, source)
+			}
+		}
+	}
+	return refs
+}
+
+// configReferenceProblem describes what is wrong with ref, or returns the
+// empty string if nothing is. keys maps "Kind/namespace/name" to the keys of
+// the existing ConfigMaps and Secrets.
+func configReferenceProblem(ref configReference, namespace string, keys map[string]map[string]bool) string {
+	if ref.optional {
+		return ""
+	}
+	objectKeys, ok := keys[ref.kind+"/"+namespace+"/"+ref.name]
+	if !ok {
+		return fmt.Sprintf("%s %q not found", ref.kind, ref.name)
+	}
+	if ref.key != "" && !objectKeys[ref.key] {
+		return fmt.Sprintf("key %q not found in %s %q", ref.key, ref.kind, ref.name)
+	}
+	return ""
+}
+
+func (h *handlers) configReferences(ctx context.Context, _ *mcp.CallToolRequest, args *configReferencesArgs) (*mcp.CallToolResult, any, error) {
+	namespace, err := h.namespaceScope(args.Namespace, args.AllNamespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	pods, err := h.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	configMaps, err := h.clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list config maps: %w", err)
+	}
+	secrets, err := h.clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	keys := map[string]map[string]bool{}
+	for _, cm := range configMaps.Items {
+		k := map[string]bool{}
+		for key := range cm.Data {
+			k[key] = true
+		}
+		for key := range cm.BinaryData {
+			k[key] = true
+		}
+		keys["ConfigMap/"+cm.Namespace+"/"+cm.Name] = k
+	}
+	for _, s := range secrets.Items {
+		k := map[string]bool{}
+		for key := range s.Data {
+			k[key] = true
+		}
+		for key := range s.StringData {
+			k[key] = true
+		}
+		keys["Secret/"+s.Namespace+"/"+s.Name] = k
+	}
+
+	type workload struct {
+		namespace, name string
+		pods            int
+		problems        [][2]string
+	}
+	workloads := map[string]*workload{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		name := "Pod/" + pod.Name
+		if owner := metav1.GetControllerOf(pod); owner != nil {
+			name = owner.Kind + "/" + owner.Name
+		}
+		key := pod.Namespace + "/" + name
+		if w, ok := workloads[key]; ok {
+			w.pods++
+			continue
+		}
+		w := &workload{namespace: pod.Namespace, name: name, pods: 1}
+		for _, ref := range configReferences(&pod.Spec) {
+			if problem := configReferenceProblem(ref, pod.Namespace, keys); problem != "" {
+				w.problems = append(w.problems, [2]string{ref.source, problem})
+			}
+		}
+		workloads[key] = w
+	}
+
+	names := make([]string, 0, len(workloads))
+	for k := range workloads {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var output strings.Builder
+	var broken int
+	output.WriteString("NAMESPACE\tWORKLOAD\tPODS\tREFERENCE\tPROBLEM\n")
+	for _, k := range names {
+		w := workloads[k]
+		if len(w.problems) > 0 {
+			broken++
+		}
+		for _, p := range w.problems {
+			output.WriteString(fmt.Sprintf("%s\t%s\t%d\t%s\t%s\n", w.namespace, w.name, w.pods, p[0], p[1]))
+		}
+	}
+	output.WriteString(fmt.Sprintf("\nChecked %d workloads in %s: %d with broken ConfigMap or Secret references.\n", len(workloads), describeScope(namespace), broken))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
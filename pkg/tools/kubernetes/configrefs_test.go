@@ -0,0 +1,69 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestConfigReferenceProblems(t *testing.T) {
+	spec := &corev1.PodSpec{
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		Volumes: []corev1.Volume{
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "web-config"},
+				Items:                []corev1.KeyToPath{{Key: "app.yaml", Path: "app.yaml"}, {Key: "missing.yaml", Path: "missing.yaml"}},
+			}}},
+			{Name: "optional", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "absent", Optional: ptr.To(true)}}},
+		},
+		Containers: []corev1.Container{{
+			Name: "web",
+			EnvFrom: []corev1.EnvFromSource{
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web-env"}}},
+			},
+			Env: []corev1.EnvVar{
+				{Name: "PLAIN", Value: "value"},
+				{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "db"},
+					Key:                  "password",
+				}}},
+			},
+		}},
+	}
+	keys := map[string]map[string]bool{
+		"ConfigMap/default/web-config": {"app.yaml": true},
+		"Secret/default/db":            {"username": true},
+		"Secret/default/registry":      {".dockerconfigjson": true},
+	}
+
+	var got []string
+	for _, ref := range configReferences(spec) {
+		if problem := configReferenceProblem(ref, "default", keys); problem != "" {
+			got = append(got, ref.source+": "+problem)
+		}
+	}
+	want := []string{
+		`volume "config": key "missing.yaml" not found in ConfigMap "web-config"`,
+		`container "web" envFrom: Secret "web-env" not found`,
+		`container "web" env DB_PASSWORD: key "password" not found in Secret "db"`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("configReferenceProblem() mismatch (-want +got):\n%s", diff)
+	}
+}
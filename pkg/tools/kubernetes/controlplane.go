@@ -0,0 +1,138 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ControlPlaneProbesToolDescription contains the documentation for the Control Plane Probes Kubernetes tool.
+// It is formatted in Markdown.
+const ControlPlaneProbesToolDescription = `
+This tool probes the health of the Kubernetes control plane: the API server and the etcd database behind it. Use it to tell whether a problem comes from a workload or from an unhealthy control plane.
+
+It calls the following API server endpoints:
+
+* */livez?verbose*: whether the API server process is alive.
+* */readyz?verbose*: whether the API server is ready to serve requests, including its connection to etcd (the *etcd* and *etcd-readiness* checks) and its informers.
+* */version*: the version of the API server, which also measures its latency.
+
+## Response Format
+
+The status and latency of each endpoint, the checks that failed, e.g. *[-]etcd failed: reason withheld*, the server version and a summary:
+
+/livez: ok (HTTP 200, 35ms)
+/readyz: failed (HTTP 500, 120ms)
+  failed checks: etcd
+/version: v1.30.2-gke.1587003 (linux/amd64, 28ms)
+
+Summary: the control plane is unhealthy: readyz checks failed: etcd
+`
+
+type controlPlaneProbesArgs struct{}
+
+// healthChecks are the individual checks reported by a verbose health
+// endpoint.
+type healthChecks struct {
+	passed, failed []string
+}
+
+// parseHealthChecks parses the output of a verbose health endpoint, made of
+// lines such as "[+]ping ok" and "[-]etcd failed: reason withheld".
+func parseHealthChecks(body string) healthChecks {
+	var checks healthChecks
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if check, ok := strings.CutPrefix(line, "[+]"); ok {
+			checks.passed = append(checks.passed, strings.TrimSuffix(check, " ok"))
+		} else if check, ok := strings.CutPrefix(line, "[-]"); ok {
+			checks.failed = append(checks.failed, check)
+		}
+	}
+	return checks
+}
+
+// checkNames returns the names of failed checks, e.g. "etcd" for
+// "etcd failed: reason withheld".
+func checkNames(failed []string) []string {
+	names := make([]string, len(failed))
+	for i, check := range failed {
+		names[i], _, _ = strings.Cut(check, " ")
+	}
+	return names
+}
+
+func (h *handlers) controlPlaneProbes(ctx context.Context, _ *mcp.CallToolRequest, _ *controlPlaneProbesArgs) (*mcp.CallToolResult, any, error) {
+	var output strings.Builder
+	var problems []string
+
+	for _, endpoint := range []string{"/livez", "/readyz"} {
+		start := time.Now()
+		result := h.dc.RESTClient().Get().AbsPath(endpoint).Param("verbose", "true").Do(ctx)
+		latency := time.Since(start)
+		var code int
+		result.StatusCode(&code)
+		body, err := result.Raw()
+		checks := parseHealthChecks(string(body))
+
+		status := "ok"
+		if err != nil {
+			status = "failed"
+			if code == 0 {
+				// The server could not be reached at all.
+				problems = append(problems, fmt.Sprintf("%s unreachable: %v", endpoint, err))
+				output.WriteString(fmt.Sprintf("%s: unreachable (%v)\n", endpoint, err))
+				continue
+			}
+		}
+		output.WriteString(fmt.Sprintf("%s: %s (HTTP %d, %s)\n", endpoint, status, code, latency.Round(time.Millisecond)))
+		if len(checks.failed) > 0 {
+			output.WriteString(fmt.Sprintf("  failed checks: %s\n", strings.Join(checks.failed, "; ")))
+			problems = append(problems, fmt.Sprintf("%s checks failed: %s", strings.TrimPrefix(endpoint, "/"), strings.Join(checkNames(checks.failed), ", ")))
+		} else if err != nil {
+			problems = append(problems, fmt.Sprintf("%s returned HTTP %d", endpoint, code))
+		}
+		if len(checks.passed) > 0 {
+			output.WriteString(fmt.Sprintf("  passed checks: %d\n", len(checks.passed)))
+		}
+	}
+
+	start := time.Now()
+	version, err := h.dc.ServerVersion()
+	latency := time.Since(start)
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("/version failed: %v", err))
+		output.WriteString(fmt.Sprintf("/version: failed (%v)\n", err))
+	} else {
+		output.WriteString(fmt.Sprintf("/version: %s (%s, %s)\n", version.GitVersion, version.Platform, latency.Round(time.Millisecond)))
+	}
+
+	if len(problems) == 0 {
+		output.WriteString("\nSummary: the control plane is healthy.")
+	} else {
+		output.WriteString("\nSummary: the control plane is unhealthy: " + strings.Join(problems, "; "))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
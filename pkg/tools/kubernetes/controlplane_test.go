@@ -0,0 +1,42 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseHealthChecks(t *testing.T) {
+	body := `[+]ping ok
+[+]log ok
+[-]etcd failed: reason withheld
+[+]poststarthook/start-informers ok
+[-]etcd-readiness failed: reason withheld
+readyz check failed
+`
+	checks := parseHealthChecks(body)
+	if diff := cmp.Diff([]string{"ping", "log", "poststarthook/start-informers"}, checks.passed); diff != "" {
+		t.Errorf("passed checks mismatch (-want +got):\n%s", diff)
+	}
+	wantFailed := []string{"etcd failed: reason withheld", "etcd-readiness failed: reason withheld"}
+	if diff := cmp.Diff(wantFailed, checks.failed); diff != "" {
+		t.Errorf("failed checks mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"etcd", "etcd-readiness"}, checkNames(checks.failed)); diff != "" {
+		t.Errorf("checkNames() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DaemonSetCoverageToolDescription contains the documentation for the DaemonSet Coverage Kubernetes tool.
+// It is formatted in Markdown.
+const DaemonSetCoverageToolDescription = `
+This tool reports, for each DaemonSet, the nodes that don't have a running and ready pod of the DaemonSet, and why. Use it when the *desiredNumberScheduled* and *numberReady* of a DaemonSet don't match, or to check that an agent runs on every node.
+
+The nodes are classified as:
+
+* **Excluded**: the DaemonSet doesn't run on the node, because of a taint it doesn't tolerate, its node selector or its required node affinity. This is expected if the DaemonSet targets some nodes only.
+* **Missing**: the DaemonSet should run on the node, but has no pod there, or its pod is pending, e.g. because the node lacks the resources for it. Nodes under memory, disk or PID pressure are flagged.
+* **NotReady**: the pod runs on the node, but isn't ready.
+
+DaemonSet pods tolerate the taints of unready, unreachable, unschedulable and pressured nodes, which don't exclude nodes.
+
+## Arguments
+
+* *name*: (Optional) The name of a DaemonSet. Defaults to all the DaemonSets of the namespace.
+* *namespace*: (Optional) The namespace of the DaemonSets. Defaults to the server's default namespace.
+* *all_namespaces*: (Optional) Set to *true* to report on all namespaces.
+* *show_excluded*: (Optional) Set to *true* to list the excluded nodes, which are otherwise only counted.
+
+## Response Format
+
+DaemonSet kube-system/fluentbit: desired 4, scheduled 4, ready 2, up-to-date 4, misscheduled 0; 1 node excluded.
+NODE    STATUS    REASON
+node-3  NotReady  pod fluentbit-x7k2: CrashLoopBackOff
+node-4  Missing   pod fluentbit-p9q1 is pending: 0/5 nodes are available: 1 Insufficient memory (node under MemoryPressure)
+`
+
+type daemonSetCoverageArgs struct {
+	Name          string `json:"name,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	ShowExcluded  bool   `json:"show_excluded,omitempty"`
+}
+
+// daemonSetTolerations are the taints that the DaemonSet controller adds
+// tolerations for to the pods of DaemonSets.
+var daemonSetTolerations = []string{
+	"node.kubernetes.io/not-ready",
+	"node.kubernetes.io/unreachable",
+	"node.kubernetes.io/disk-pressure",
+	"node.kubernetes.io/memory-pressure",
+	"node.kubernetes.io/pid-pressure",
+	"node.kubernetes.io/unschedulable",
+}
+
+// nodeCoverage is the status of a DaemonSet on a node that doesn't run a
+// ready pod of it.
+type nodeCoverage struct {
+	node   string
+	status string
+	reason string
+}
+
+// daemonSetExclusion returns why the pods of ds aren't scheduled on node, or
+// "" if they are.
+func daemonSetExclusion(ds *appsv1.DaemonSet, node *corev1.Node) string {
+	spec := &ds.Spec.Template.Spec
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule || slices.Contains(daemonSetTolerations, taint.Key) {
+			continue
+		}
+		if taint.Key == "node.kubernetes.io/network-unavailable" && spec.HostNetwork {
+			continue
+		}
+		tolerated := false
+		for j := range spec.Tolerations {
+			if spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return fmt.Sprintf("taint %s not tolerated", taint.ToString())
+		}
+	}
+	if !labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return fmt.Sprintf("node selector %s not matched", labels.SelectorFromSet(spec.NodeSelector))
+	}
+	if a := spec.Affinity; a != nil && a.NodeAffinity != nil && a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if !matchesNodeSelectorTerms(a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, node) {
+			return "required node affinity not matched"
+		}
+	}
+	return ""
+}
+
+// matchesNodeSelectorTerms reports whether node matches any of terms.
+func matchesNodeSelectorTerms(terms []corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		matches := true
+		for _, r := range term.MatchExpressions {
+			matches = matches && matchesNodeSelectorRequirement(r, node.Labels)
+		}
+		for _, r := range term.MatchFields {
+			matches = matches && matchesNodeSelectorRequirement(r, map[string]string{"metadata.name": node.Name})
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesNodeSelectorRequirement(r corev1.NodeSelectorRequirement, values map[string]string) bool {
+	value, ok := values[r.Key]
+	switch r.Operator {
+	case corev1.NodeSelectorOpIn:
+		return ok && slices.Contains(r.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !ok || !slices.Contains(r.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return ok
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !ok
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !ok || len(r.Values) != 1 {
+			return false
+		}
+		got, err1 := strconv.ParseInt(value, 10, 64)
+		want, err2 := strconv.ParseInt(r.Values[0], 10, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if r.Operator == corev1.NodeSelectorOpGt {
+			return got > want
+		}
+		return got < want
+	}
+	return false
+}
+
+// daemonPodNode returns the node of pod, a pod of a DaemonSet: the node it's
+// bound to or, while it's pending, the node its affinity targets.
+func daemonPodNode(pod *corev1.Pod) string {
+	if pod.Spec.NodeName != "" {
+		return pod.Spec.NodeName
+	}
+	if a := pod.Spec.Affinity; a != nil && a.NodeAffinity != nil && a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		for _, term := range a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			for _, f := range term.MatchFields {
+				if f.Key == "metadata.name" && f.Operator == corev1.NodeSelectorOpIn && len(f.Values) == 1 {
+					return f.Values[0]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// nodePressure returns the pressure conditions of node, e.g. "MemoryPressure".
+func nodePressure(node *corev1.Node) []string {
+	var pressure []string
+	for _, c := range node.Status.Conditions {
+		switch c.Type {
+		case corev1.NodeMemoryPressure, corev1.NodeDiskPressure, corev1.NodePIDPressure:
+			if c.Status == corev1.ConditionTrue {
+				pressure = append(pressure, string(c.Type))
+			}
+		}
+	}
+	return pressure
+}
+
+// daemonSetCoverage returns the status of ds on the nodes that don't run a
+// ready pod of it, sorted by node, given its pods.
+func daemonSetCoverage(ds *appsv1.DaemonSet, nodes []corev1.Node, pods []*corev1.Pod) []nodeCoverage {
+	podsByNode := map[string]*corev1.Pod{}
+	for _, pod := range pods {
+		if node := daemonPodNode(pod); node != "" && pod.DeletionTimestamp == nil {
+			podsByNode[node] = pod
+		}
+	}
+
+	var coverage []nodeCoverage
+	for i := range nodes {
+		node := &nodes[i]
+		pod := podsByNode[node.Name]
+		if reason := daemonSetExclusion(ds, node); reason != "" {
+			if pod == nil {
+				coverage = append(coverage, nodeCoverage{node: node.Name, status: "Excluded", reason: reason})
+			}
+			continue
+		}
+
+		var c nodeCoverage
+		switch {
+		case pod == nil:
+			c = nodeCoverage{node: node.Name, status: "Missing", reason: "no pod: check the events of the DaemonSet"}
+		case pod.Spec.NodeName == "":
+			reason := "pending"
+			for _, cond := range pod.Status.Conditions {
+				if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse && cond.Message != "" {
+					reason = "pending: " + cond.Message
+				}
+			}
+			c = nodeCoverage{node: node.Name, status: "Missing", reason: fmt.Sprintf("pod %s is %s", pod.Name, reason)}
+		default:
+			notReady := podNotReadyReason(pod)
+			if notReady == "" {
+				continue
+			}
+			c = nodeCoverage{node: node.Name, status: "NotReady", reason: fmt.Sprintf("pod %s: %s", pod.Name, notReady)}
+		}
+		if pressure := nodePressure(node); len(pressure) > 0 {
+			c.reason += fmt.Sprintf(" (node under %s)", strings.Join(pressure, ", "))
+		}
+		coverage = append(coverage, c)
+	}
+	sort.Slice(coverage, func(i, j int) bool { return coverage[i].node < coverage[j].node })
+	return coverage
+}
+
+func (h *handlers) daemonSetCoverage(ctx context.Context, _ *mcp.CallToolRequest, args *daemonSetCoverageArgs) (*mcp.CallToolResult, any, error) {
+	namespace, err := h.namespaceScope(args.Namespace, args.AllNamespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	var daemonSets []appsv1.DaemonSet
+	if args.Name != "" {
+		ds, err := h.clientset.AppsV1().DaemonSets(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get daemonset: %w", err)
+		}
+		daemonSets = append(daemonSets, *ds)
+	} else {
+		list, err := h.clientset.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list daemonsets: %w", err)
+		}
+		daemonSets = list.Items
+	}
+	nodes, err := h.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := h.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	podsByOwner := map[string][]*corev1.Pod{}
+	for i := range pods.Items {
+		if ref := metav1.GetControllerOf(&pods.Items[i]); ref != nil && ref.Kind == "DaemonSet" {
+			podsByOwner[string(ref.UID)] = append(podsByOwner[string(ref.UID)], &pods.Items[i])
+		}
+	}
+	sort.Slice(daemonSets, func(i, j int) bool {
+		return daemonSets[i].Namespace+"/"+daemonSets[i].Name < daemonSets[j].Namespace+"/"+daemonSets[j].Name
+	})
+
+	var output strings.Builder
+	if len(daemonSets) == 0 {
+		output.WriteString(fmt.Sprintf("No DaemonSets found in %s.\n", describeScope(namespace)))
+	}
+	incomplete := 0
+	for i := range daemonSets {
+		ds := &daemonSets[i]
+		coverage := daemonSetCoverage(ds, nodes.Items, podsByOwner[string(ds.UID)])
+		var shown []nodeCoverage
+		excluded := 0
+		for _, c := range coverage {
+			if c.status == "Excluded" {
+				excluded++
+				if !args.ShowExcluded {
+					continue
+				}
+			}
+			shown = append(shown, c)
+		}
+		if len(coverage) > excluded {
+			incomplete++
+		}
+		if i > 0 {
+			output.WriteString("\n")
+		}
+		st := ds.Status
+		output.WriteString(fmt.Sprintf("DaemonSet %s/%s: desired %d, scheduled %d, ready %d, up-to-date %d, misscheduled %d; %d nodes excluded.\n",
+			ds.Namespace, ds.Name, st.DesiredNumberScheduled, st.CurrentNumberScheduled, st.NumberReady, st.UpdatedNumberScheduled, st.NumberMisscheduled, excluded))
+		if len(shown) > 0 {
+			output.WriteString("NODE\tSTATUS\tREASON\n")
+			for _, c := range shown {
+				output.WriteString(fmt.Sprintf("%s\t%s\t%s\n", c.node, c.status, c.reason))
+			}
+		}
+	}
+	if len(daemonSets) > 1 {
+		output.WriteString(fmt.Sprintf("\nSummary: %d DaemonSets, %d without a ready pod on every eligible node.\n", len(daemonSets), incomplete))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
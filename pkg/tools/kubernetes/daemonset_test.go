@@ -0,0 +1,112 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDaemonSetCoverage(t *testing.T) {
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system"},
+		Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+			Tolerations:  []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "infra", Effect: corev1.TaintEffectNoSchedule}},
+		}}},
+	}
+	node := func(name string, mutate func(*corev1.Node)) corev1.Node {
+		n := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"kubernetes.io/os": "linux"}}}
+		if mutate != nil {
+			mutate(&n)
+		}
+		return n
+	}
+	nodes := []corev1.Node{
+		node("ready", nil),
+		node("crashing", nil),
+		node("pending", func(n *corev1.Node) {
+			n.Status.Conditions = []corev1.NodeCondition{{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue}}
+		}),
+		node("no-pod", func(n *corev1.Node) {
+			// Tolerated explicitly, and by every DaemonSet.
+			n.Spec.Taints = []corev1.Taint{
+				{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule},
+			}
+		}),
+		node("gpu", func(n *corev1.Node) {
+			n.Spec.Taints = []corev1.Taint{{Key: "nvidia.com/gpu", Value: "present", Effect: corev1.TaintEffectNoSchedule}}
+		}),
+		node("windows", func(n *corev1.Node) { n.Labels["kubernetes.io/os"] = "windows" }),
+	}
+	readyPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-a"},
+		Spec:       corev1.PodSpec{NodeName: "ready"},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}
+	crashingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-b"},
+		Spec:       corev1.PodSpec{NodeName: "crashing"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}}},
+	}
+	pendingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent-c"},
+		Spec: corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"pending"}}},
+			}}},
+		}}},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Message: "0/6 nodes are available: 1 Insufficient memory."}}},
+	}
+
+	got := daemonSetCoverage(ds, nodes, []*corev1.Pod{readyPod, crashingPod, pendingPod})
+	want := []nodeCoverage{
+		{node: "crashing", status: "NotReady", reason: "pod agent-b: CrashLoopBackOff"},
+		{node: "gpu", status: "Excluded", reason: "taint nvidia.com/gpu=present:NoSchedule not tolerated"},
+		{node: "no-pod", status: "Missing", reason: "no pod: check the events of the DaemonSet"},
+		{node: "pending", status: "Missing", reason: "pod agent-c is pending: 0/6 nodes are available: 1 Insufficient memory. (node under MemoryPressure)"},
+		{node: "windows", status: "Excluded", reason: "node selector kubernetes.io/os=linux not matched"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(nodeCoverage{})); diff != "" {
+		t.Errorf("daemonSetCoverage() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMatchesNodeSelectorTerms(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1", Labels: map[string]string{"zone": "a", "cores": "8"}}}
+	for _, tc := range []struct {
+		name string
+		term corev1.NodeSelectorTerm
+		want bool
+	}{
+		{"in", corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a", "b"}}}}, true},
+		{"not in", corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"a"}}}}, false},
+		{"does not exist", corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "gpu", Operator: corev1.NodeSelectorOpDoesNotExist}}}, true},
+		{"gt", corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "cores", Operator: corev1.NodeSelectorOpGt, Values: []string{"4"}}}}, true},
+		{"field", corev1.NodeSelectorTerm{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"n2"}}}}, false},
+		{"empty", corev1.NodeSelectorTerm{}, false},
+	} {
+		if got := matchesNodeSelectorTerms([]corev1.NodeSelectorTerm{tc.term}, node); got != tc.want {
+			t.Errorf("%s: matchesNodeSelectorTerms() = %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// DebugNodeToolDescription contains the documentation for the Debug Node Kubernetes tool.
+// It is formatted in Markdown.
+const DebugNodeToolDescription = `
+This tool runs a shell command on a node, like *kubectl debug node/...*, and returns its output. Use it for node-level investigations that the Kubernetes API can't answer, e.g. *dmesg*, *df -h*, *journalctl -u kubelet* or *crictl ps*.
+
+The tool creates a privileged pod on the node, which shares the node's PID, network and IPC namespaces and mounts the node's root file system at */host*. The command runs in the node's namespaces with *nsenter*, as if it was run on the node, so it can use the node's binaries. The pod is deleted once the command completes or the tool call times out.
+
+**The command runs as root on the node and can damage it: only run read-only commands unless the user explicitly asked otherwise.**
+
+## Arguments
+
+* *node*: The name of the node.
+* *command*: The shell command to run on the node, e.g. *journalctl -u kubelet --since '10 min ago' --no-pager | tail -n 100*.
+* *image*: (Optional) The image of the debug pod. It must provide *nsenter* and *sh*. Defaults to *busybox:1.36*.
+* *namespace*: (Optional) The namespace to create the debug pod in. Defaults to the server's default namespace.
+
+## Response Format
+
+The exit code of the command, followed by its output.
+`
+
+type debugNodeArgs struct {
+	Node      string `json:"node"`
+	Command   string `json:"command"`
+	Image     string `json:"image,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+const (
+	defaultDebugImage = "busybox:1.36"
+	// debugPollInterval is how often the debug pod is checked for
+	// completion.
+	debugPollInterval = time.Second
+	// maxDebugLogBytes bounds the output of the command returned by the
+	// tool.
+	maxDebugLogBytes = 64 * 1024
+)
+
+// nodeDebugPod returns a pod running command on node in the node's
+// namespaces.
+func nodeDebugPod(node, namespace, image, command string) *corev1.Pod {
+	// Leave room in the 63 characters of the generated name for the
+	// suffix.
+	prefix := "node-debugger-" + node
+	if len(prefix) > 52 {
+		prefix = prefix[:52]
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: prefix + "-",
+			Namespace:    namespace,
+			Labels:       map[string]string{"app.kubernetes.io/managed-by": "kubeapi-mcp"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                      node,
+			HostPID:                       true,
+			HostNetwork:                   true,
+			HostIPC:                       true,
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: ptr.To[int64](0),
+			// Run on the node whatever its taints.
+			Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			Containers: []corev1.Container{{
+				Name:    "debugger",
+				Image:   image,
+				Command: []string{"nsenter", "--target", "1", "--mount", "--uts", "--ipc", "--net", "--pid", "--", "sh", "-c", command},
+				SecurityContext: &corev1.SecurityContext{
+					Privileged: ptr.To(true),
+				},
+				VolumeMounts: []corev1.VolumeMount{{Name: "host-root", MountPath: "/host"}},
+			}},
+			Volumes: []corev1.Volume{{
+				Name:         "host-root",
+				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/"}},
+			}},
+		},
+	}
+}
+
+func (h *handlers) debugNode(ctx context.Context, _ *mcp.CallToolRequest, args *debugNodeArgs) (*mcp.CallToolResult, any, error) {
+	if args.Node == "" || args.Command == "" {
+		return nil, nil, fmt.Errorf("node and command are required")
+	}
+	image := args.Image
+	if image == "" {
+		image = defaultDebugImage
+	}
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+	if err := h.checkProtectedNamespace(namespace); err != nil {
+		return nil, nil, err
+	}
+	if _, err := h.clientset.CoreV1().Nodes().Get(ctx, args.Node, metav1.GetOptions{}); err != nil {
+		return nil, nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	pods := h.clientset.CoreV1().Pods(namespace)
+	pod, err := pods.Create(ctx, nodeDebugPod(args.Node, namespace, image, args.Command), metav1.CreateOptions{FieldManager: h.c.FieldManager()})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create debug pod: %w", err)
+	}
+	name := pod.Name
+	defer func() {
+		// Clean up even if the tool call was cancelled.
+		deleteCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		if err := pods.Delete(deleteCtx, name, metav1.DeleteOptions{GracePeriodSeconds: ptr.To[int64](0)}); err != nil {
+			slog.Warn("Failed to delete debug pod", "namespace", namespace, "pod", name, "error", err)
+		}
+	}()
+
+	// Leave time to collect the output and delete the pod before the tool
+	// call deadline.
+	waitCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithDeadline(ctx, deadline.Add(-watchDeadlineMargin))
+		defer cancel()
+	}
+	ticker := time.NewTicker(debugPollInterval)
+	defer ticker.Stop()
+	var terminated *corev1.ContainerStateTerminated
+	for terminated == nil {
+		select {
+		case <-waitCtx.Done():
+			status := "not started"
+			if pod.Status.Phase != "" {
+				status = string(pod.Status.Phase)
+			}
+			if reason := podNotReadyReason(pod); reason != "" {
+				status += ", " + reason
+			}
+			return nil, nil, fmt.Errorf("debug pod %s did not complete in time (%s); the command may still be running or the image may not be pullable", name, status)
+		case <-ticker.C:
+		}
+		current, err := pods.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get debug pod: %w", err)
+		}
+		pod = current
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Terminated != nil {
+				terminated = cs.State.Terminated
+			}
+		}
+	}
+
+	logs, err := pods.GetLogs(name, &corev1.PodLogOptions{LimitBytes: ptr.To[int64](maxDebugLogBytes)}).DoRaw(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get debug pod logs: %w", err)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Command exited with code %d on node %s", terminated.ExitCode, args.Node))
+	if terminated.Reason != "" && terminated.Reason != "Completed" {
+		output.WriteString(fmt.Sprintf(" (%s)", terminated.Reason))
+	}
+	output.WriteString(".\n\n")
+	output.Write(logs)
+	if len(logs) >= maxDebugLogBytes {
+		output.WriteString(fmt.Sprintf("\n... output truncated to %d bytes.", maxDebugLogBytes))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestNodeDebugPod(t *testing.T) {
+	pod := nodeDebugPod("gke-cluster-default-pool-0123abcd-wxyz-with-a-very-long-name", "default", "busybox", "dmesg")
+	if got := len(pod.GenerateName); got > 53 {
+		t.Errorf("nodeDebugPod() generateName %q is %d characters long, want at most 53", pod.GenerateName, got)
+	}
+	c := pod.Spec.Containers[0]
+	if got, want := strings.Join(c.Command, " "), "nsenter --target 1 --mount --uts --ipc --net --pid -- sh -c dmesg"; got != want {
+		t.Errorf("nodeDebugPod() command = %q, want %q", got, want)
+	}
+	if !pod.Spec.HostPID || c.SecurityContext == nil || !*c.SecurityContext.Privileged {
+		t.Errorf("nodeDebugPod() is not privileged with hostPID")
+	}
+}
+
+func TestDebugNode(t *testing.T) {
+	clientset := fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	// The fake clientset neither generates names nor runs pods.
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		pod.Name = pod.GenerateName + "abcde"
+		return false, nil, nil
+	})
+	clientset.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "" {
+			return false, nil, nil
+		}
+		return true, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: action.(k8stesting.GetAction).GetName(), Namespace: "default"},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}},
+			}}},
+		}, nil
+	})
+	h := &handlers{c: &config.Config{}, clientset: clientset, defaultNamespace: "default"}
+
+	result, _, err := h.debugNode(context.Background(), &mcp.CallToolRequest{}, &debugNodeArgs{Node: "node-1", Command: "uptime"})
+	if err != nil {
+		t.Fatalf("debugNode() error = %v", err)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if want := "Command exited with code 0 on node node-1.\n\nfake logs"; text != want {
+		t.Errorf("debugNode() = %q, want %q", text, want)
+	}
+	pods, err := clientset.Tracker().List(corev1.SchemeGroupVersion.WithResource("pods"), corev1.SchemeGroupVersion.WithKind("Pod"), "default")
+	if err != nil {
+		t.Fatalf("failed to list pods: %v", err)
+	}
+	if items := pods.(*corev1.PodList).Items; len(items) != 0 {
+		t.Errorf("debugNode() left %d pods behind, want 0", len(items))
+	}
+
+	if _, _, err := h.debugNode(context.Background(), &mcp.CallToolRequest{}, &debugNodeArgs{Node: "missing", Command: "uptime"}); err == nil {
+		t.Errorf("debugNode() on a missing node succeeded, want error")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// describeIndent is the width of the field names of kube_describe.
+const describeIndent = 16
+
+// ownerChain returns the controller of obj, the controller of that
+// controller, and so on, as "Kind/name". Objects without a controller are
+// followed through their first owner. An owner that can't be read ends the
+// chain.
+func (h *handlers) ownerChain(ctx context.Context, obj *unstructured.Unstructured) []string {
+	var chain []string
+	current := obj
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		ref := metav1.GetControllerOf(current)
+		if ref == nil {
+			if refs := current.GetOwnerReferences(); len(refs) > 0 {
+				ref = &refs[0]
+			}
+		}
+		if ref == nil {
+			break
+		}
+		owner, err := h.getOwner(ctx, *ref, obj.GetNamespace())
+		if err != nil {
+			chain = append(chain, fmt.Sprintf("%s/%s (%v)", ref.Kind, ref.Name, err))
+			break
+		}
+		chain = append(chain, ref.Kind+"/"+ref.Name)
+		current = owner
+	}
+	return chain
+}
+
+// writeDescribeField writes the field name with values, one per line, to
+// out. Fields without values are written as <none>.
+func writeDescribeField(out *strings.Builder, name string, values ...string) {
+	if len(values) == 0 {
+		values = []string{"<none>"}
+	}
+	for i, v := range values {
+		label := ""
+		if i == 0 {
+			label = name + ":"
+		}
+		out.WriteString(fmt.Sprintf("%-*s%s\n", describeIndent, label, v))
+	}
+}
+
+// sortedPairs returns the key=value pairs of m, sorted, without the keys of
+// skip.
+func sortedPairs(m map[string]string, skip ...string) []string {
+	var pairs []string
+	for k, v := range m {
+		if !slices.Contains(skip, k) {
+			pairs = append(pairs, k+"="+v)
+		}
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// writeConditions writes the conditions of the status of obj to out.
+func writeConditions(out *strings.Builder, obj *unstructured.Unstructured) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if len(conditions) == 0 {
+		return
+	}
+	out.WriteString("\nConditions:\n")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tLAST_TRANSITION\tMESSAGE")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		field := func(name string) string {
+			if v, ok := condition[name].(string); ok && v != "" {
+				return v
+			}
+			return "-"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", field("type"), field("status"), field("reason"), field("lastTransitionTime"), field("message"))
+	}
+	w.Flush()
+}
+
+// writeDescribeEvents writes events, sorted by the time they last occurred,
+// to out.
+func writeDescribeEvents(out *strings.Builder, events []corev1.Event, now time.Time) {
+	if len(events) == 0 {
+		out.WriteString("\nEvents:  <none>\n")
+		return
+	}
+	sort.SliceStable(events, func(i, j int) bool { return eventTime(&events[i]).Before(eventTime(&events[j])) })
+	out.WriteString("\nEvents:\n")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tREASON\tAGE\tFROM\tCOUNT\tMESSAGE")
+	for i := range events {
+		e := &events[i]
+		from := e.Source.Component
+		if from == "" {
+			from = e.ReportingController
+		}
+		count := e.Count
+		if e.Series != nil {
+			count = e.Series.Count
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%d\t%s\n", e.Type, e.Reason, formatAge(now.Sub(eventTime(e))), from, max(count, 1), strings.TrimSpace(e.Message))
+	}
+	w.Flush()
+}
+
+// writeYAMLSection writes the YAML of value, indented, under title to out.
+func writeYAMLSection(out *strings.Builder, title string, value map[string]any) error {
+	if len(value) == 0 {
+		return nil
+	}
+	b, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s to YAML: %w", strings.ToLower(title), err)
+	}
+	out.WriteString("\n" + title + ":\n")
+	for _, line := range strings.Split(strings.TrimRight(string(b), "\n"), "\n") {
+		out.WriteString("  " + line + "\n")
+	}
+	return nil
+}
+
+func (h *handlers) describeResource(ctx context.Context, _ *mcp.CallToolRequest, args *describeResourceArgs) (*mcp.CallToolResult, any, error) {
+	gvr, err := h.findGVR(args.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	namespaced, err := h.isNamespaced(gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+	namespace := ""
+	if namespaced {
+		namespace = args.Namespace
+		if namespace == "" {
+			namespace = h.defaultNamespace
+		}
+	}
+	obj, err := h.resourceInterface(gvr, namespace).Get(ctx, args.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get resource: %w", err)
+	}
+	obj = h.redact(obj)
+	events, err := h.clientset.CoreV1().Events(obj.GetNamespace()).Search(scheme.Scheme, obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get events for resource: %w", err)
+	}
+	now := time.Now()
+
+	var output strings.Builder
+	writeDescribeField(&output, "Name", obj.GetName())
+	if namespaced {
+		writeDescribeField(&output, "Namespace", obj.GetNamespace())
+	}
+	writeDescribeField(&output, "Kind", obj.GetKind())
+	writeDescribeField(&output, "APIVersion", obj.GetAPIVersion())
+	writeDescribeField(&output, "Labels", sortedPairs(obj.GetLabels())...)
+	writeDescribeField(&output, "Annotations", sortedPairs(obj.GetAnnotations(), lastAppliedAnnotation)...)
+	created := obj.GetCreationTimestamp().Time
+	writeDescribeField(&output, "Created", fmt.Sprintf("%s (%s ago)", created.UTC().Format(time.RFC3339), formatAge(now.Sub(created))))
+	if deleted := obj.GetDeletionTimestamp(); deleted != nil {
+		finalizers := strings.Join(obj.GetFinalizers(), ", ")
+		if finalizers == "" {
+			finalizers = "none"
+		}
+		writeDescribeField(&output, "Deleting", fmt.Sprintf("since %s, waiting for finalizers: %s", deleted.UTC().Format(time.RFC3339), finalizers))
+	}
+	if chain := h.ownerChain(ctx, obj); len(chain) > 0 {
+		writeDescribeField(&output, "Controlled By", chain...)
+	}
+
+	writeConditions(&output, obj)
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	if err := writeYAMLSection(&output, "Spec", spec); err != nil {
+		return nil, nil, err
+	}
+	// The conditions are already listed.
+	status, _, _ := unstructured.NestedMap(obj.Object, "status")
+	delete(status, "conditions")
+	if err := writeYAMLSection(&output, "Status", status); err != nil {
+		return nil, nil, err
+	}
+	writeDescribeEvents(&output, events.Items, now)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
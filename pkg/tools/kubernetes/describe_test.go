@@ -0,0 +1,113 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/cache"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestDescribeResource(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}, meta.RESTScopeNamespace)
+
+	web := ownedObject("apps/v1", "Deployment", "web", "web-uid")
+	webRS := ownedObject("apps/v1", "ReplicaSet", "web-7d9f", "web-7d9f-uid", web)
+	pod := ownedObject("v1", "Pod", "web-7d9f-abcde", "pod-uid", webRS)
+	pod.SetLabels(map[string]string{"app": "web", "pod-template-hash": "7d9f"})
+	pod.SetAnnotations(map[string]string{lastAppliedAnnotation: "{}"})
+	pod.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-3 * time.Hour)))
+	unstructured.SetNestedField(pod.Object, map[string]any{"containers": []any{map[string]any{"name": "app", "image": "nginx", "env": []any{map[string]any{"name": "DB_PASSWORD", "value": "hunter2"}}}}}, "spec")
+	unstructured.SetNestedField(pod.Object, "Running", "status", "phase")
+	unstructured.SetNestedSlice(pod.Object, []any{map[string]any{"type": "Ready", "status": "False", "reason": "ContainersNotReady"}}, "status", "conditions")
+
+	h := &handlers{
+		c:                config.New("test", config.Options{}),
+		defaultNamespace: "shop",
+		cache:            cache.New(time.Hour),
+		dyn:              dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), web, webRS, pod),
+		mapper:           mapper,
+		clientset: fake.NewClientset(&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "e1", Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-7d9f-abcde", Namespace: "shop", UID: "pod-uid"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			Source:         corev1.EventSource{Component: "kubelet"},
+			Count:          12,
+			LastTimestamp:  metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+		}),
+	}
+	// The discovery data of findGVR.
+	h.cache.Set(preferredResourcesCacheKey, []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true}},
+	}})
+	result, _, err := h.describeResource(context.Background(), nil, &describeResourceArgs{Resource: "pod", Name: "web-7d9f-abcde"})
+	if err != nil {
+		t.Fatalf("describeResource() failed: %v", err)
+	}
+	got := result.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{
+		"Name:           web-7d9f-abcde\nNamespace:      shop\n",
+		"Labels:         app=web\n                pod-template-hash=7d9f\n",
+		"Annotations:    <none>\n",
+		"(3h ago)",
+		"Controlled By:  ReplicaSet/web-7d9f\n                Deployment/web\n",
+		"  Ready  False   ContainersNotReady",
+		"Spec:\n  containers:\n  - env:\n    - name: DB_PASSWORD\n",
+		"Status:\n  phase: Running\n",
+		"  Warning  BackOff  2m   kubelet  12     Back-off restarting failed container",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("describeResource() = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "hunter2") || !strings.Contains(got, "value: '[REDACTED]'") {
+		t.Errorf("describeResource() = %q, want the DB_PASSWORD environment variable redacted", got)
+	}
+	if strings.Count(got, "ContainersNotReady") != 1 {
+		t.Errorf("describeResource() = %q, want the conditions once", got)
+	}
+}
+
+func TestOwnerChainMissingOwner(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}, meta.RESTScopeNamespace)
+	deleted := ownedObject("apps/v1", "ReplicaSet", "web-0", "web-0-uid")
+	h := &handlers{dyn: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), mapper: mapper}
+
+	chain := h.ownerChain(context.Background(), ownedObject("v1", "Pod", "web-0-a", "a", deleted))
+	if len(chain) != 1 || !strings.HasPrefix(chain[0], "ReplicaSet/web-0 (") {
+		t.Errorf("ownerChain() = %q, want the missing ReplicaSet and the error", chain)
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 2
+
+// lineDiff returns the differences between the lines of a and b: removed
+// lines are prefixed with "-", added lines with "+", and the unchanged lines
+// around them with " ". Unchanged lines further than diffContext lines from
+// a change are elided with "...". It returns "" if a and b are equal.
+func lineDiff(a, b string) string {
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	changed := false
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, line{' ', x[i]})
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', x[i]})
+			changed = true
+			i++
+		default:
+			lines = append(lines, line{'+', y[j]})
+			changed = true
+			j++
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	// Keep the changes and the unchanged lines close to them.
+	keep := make([]bool, len(lines))
+	for k, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		for c := max(0, k-diffContext); c <= min(len(lines)-1, k+diffContext); c++ {
+			keep[c] = true
+		}
+	}
+	var out strings.Builder
+	elided := false
+	for k, l := range lines {
+		if !keep[k] {
+			if !elided {
+				out.WriteString("...\n")
+				elided = true
+			}
+			continue
+		}
+		elided = false
+		out.WriteByte(l.op)
+		out.WriteString(" " + l.text + "\n")
+	}
+	return out.String()
+}
+
+// fieldDiff returns the fields that differ between the objects before and
+// after, one per line and sorted by path: "+ path: value" for added fields,
+// "- path: value" for removed ones and "~ path: old -> new" for changed ones.
+// Lists are compared by index, e.g. spec.containers[0].image. The values of
+// the masked paths are shown as redactedValue.
+func fieldDiff(before, after map[string]any, masked map[string]bool) []string {
+	x, y := map[string]string{}, map[string]string{}
+	flattenFields("", before, x)
+	flattenFields("", after, y)
+	show := func(path, value string) string {
+		if masked[path] {
+			return redactedValue
+		}
+		return value
+	}
+
+	var lines []string
+	for path, old := range x {
+		switch v, ok := y[path]; {
+		case !ok:
+			lines = append(lines, fmt.Sprintf("- %s: %s", path, show(path, old)))
+		case v != old:
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", path, show(path, old), show(path, v)))
+		}
+	}
+	for path, v := range y {
+		if _, ok := x[path]; !ok {
+			lines = append(lines, fmt.Sprintf("+ %s: %s", path, show(path, v)))
+		}
+	}
+	// Sort by path, ignoring the operation.
+	sort.Slice(lines, func(i, j int) bool { return lines[i][2:] < lines[j][2:] })
+	return lines
+}
+
+// flattenFields adds the leaf fields of v, a decoded JSON value at path, to
+// fields, formatted as JSON. Empty objects and lists are leaves.
+func flattenFields(path string, v any, fields map[string]string) {
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) > 0 {
+			for k, child := range v {
+				p := k
+				if strings.ContainsAny(k, ".[]") {
+					p = "[" + strconv.Quote(k) + "]"
+				} else if path != "" {
+					p = "." + k
+				}
+				flattenFields(path+p, child, fields)
+			}
+			return
+		}
+	case []any:
+		if len(v) > 0 {
+			for i, child := range v {
+				flattenFields(fmt.Sprintf("%s[%d]", path, i), child, fields)
+			}
+			return
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%v", v))
+	}
+	fields[path] = string(data)
+}
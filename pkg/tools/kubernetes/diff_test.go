@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLineDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n", want: ""},
+		{name: "added", a: "a\nb\n", b: "a\nb\nc\n", want: "  a\n  b\n+ c\n"},
+		{name: "removed", a: "a\nb\nc\n", b: "a\nc\n", want: "  a\n- b\n  c\n"},
+		{name: "changed", a: "image: v1\n", b: "image: v2\n", want: "- image: v1\n+ image: v2\n"},
+		{
+			name: "elided",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "...\n  3\n  4\n- 5\n+ five\n  6\n  7\n...\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, lineDiff(tc.a, tc.b)); diff != "" {
+				t.Errorf("lineDiff() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFieldDiff(t *testing.T) {
+	before := decodeObject(t, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  labels: {app: db, tier: backend}
+data:
+  password: b2xk
+spec:
+  containers:
+  - image: nginx:1.25
+`).Object
+	after := decodeObject(t, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  labels: {app: db, example.com/team: data}
+data:
+  password: bmV3
+spec:
+  containers:
+  - image: nginx:1.27
+  - image: envoy:v1
+`).Object
+	got := fieldDiff(before, after, map[string]bool{"data.password": true})
+	want := []string{
+		`~ data.password: [REDACTED] -> [REDACTED]`,
+		`- metadata.labels.tier: "backend"`,
+		`+ metadata.labels["example.com/team"]: "data"`,
+		`~ spec.containers[0].image: "nginx:1.25" -> "nginx:1.27"`,
+		`+ spec.containers[1].image: "envoy:v1"`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("fieldDiff() mismatch (-want +got):\n%s", diff)
+	}
+	if got := fieldDiff(before, before, nil); len(got) != 0 {
+		t.Errorf("fieldDiff(equal) = %q, want no changes", got)
+	}
+}
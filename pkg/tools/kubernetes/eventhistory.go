@@ -0,0 +1,326 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging/logadmin"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// GKEEventHistoryToolDescription contains the documentation for the GKE Event History tool.
+// It is formatted in Markdown.
+const GKEEventHistoryToolDescription = `
+This tool returns the history of the Kubernetes events of an object, merging the Event objects of the cluster with the events exported to Cloud Logging. The API server only keeps events for one hour by default, while GKE exports them to the *events* log of Cloud Logging, which keeps them for 30 days: use this tool for post-incident analysis of events older than an hour, or of objects that were deleted since.
+
+An event found in both sources is only reported once. Repeated events, i.e. events with the same reason and message, are merged into one entry with the total count and the first and last time they were seen.
+
+## Arguments
+
+* *resource*: The kind or resource name of the object, e.g. *deployment* or *pod*.
+* *name*: The name of the object. The object may have been deleted.
+* *namespace*: (Optional) The namespace of the object. Defaults to the server's default namespace for namespaced resources.
+* *since*: (Optional) How far back to look for events, as a duration such as *2h* or *72h*. Defaults to *24h*.
+* *cluster_name*: (Optional) The name of the GKE cluster, to exclude the events of other clusters of the project with an object of the same name.
+* *limit*: (Optional) The maximum number of log entries to read from Cloud Logging, most recent first. Defaults to 1000.
+
+## Response Format
+
+The number of events found in each source, followed by one row per event. SOURCE is *cluster*, *logging* or *both*:
+
+FIRST_SEEN	LAST_SEEN	COUNT	TYPE	REASON	SOURCE	MESSAGE
+2025-01-01T08:00:00Z	2025-01-01T08:00:00Z	1	Normal	Scheduled	logging	Successfully assigned default/web-0 to node-1
+2025-01-01T10:00:02Z	2025-01-01T10:04:10Z	12	Warning	BackOff	both	Back-off restarting failed container
+`
+
+type gkeEventHistoryArgs struct {
+	Resource    string `json:"resource"`
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace,omitempty"`
+	Since       string `json:"since,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+}
+
+const (
+	defaultEventHistoryWindow = 24 * time.Hour
+	defaultEventHistoryLimit  = 1000
+)
+
+// eventHistoryEntry is a merged set of identical events and where they were
+// found.
+type eventHistoryEntry struct {
+	timelineEntry
+	source string
+}
+
+// eventLogFilter returns the Cloud Logging filter of the events of the object
+// kind/name in namespace, exported by GKE, since start.
+func eventLogFilter(kind, namespace, name, cluster string, start time.Time) string {
+	filter := fmt.Sprintf(`resource.type="k8s_cluster" AND log_id("events") AND jsonPayload.involvedObject.kind=%q AND jsonPayload.involvedObject.name=%q`, kind, name)
+	if namespace != "" {
+		filter += fmt.Sprintf(` AND jsonPayload.involvedObject.namespace=%q`, namespace)
+	}
+	if cluster != "" {
+		filter += fmt.Sprintf(` AND resource.labels.cluster_name=%q`, cluster)
+	}
+	return filter + fmt.Sprintf(` AND timestamp>=%q`, start.UTC().Format(time.RFC3339))
+}
+
+// loggedEvent returns the event of the JSON payload of an exported event.
+func loggedEvent(payload *structpb.Struct) (*corev1.Event, error) {
+	b, err := protojson.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var event corev1.Event
+	if err := json.Unmarshal(b, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// mergeEventHistory de-duplicates the events of the cluster and the events
+// of Cloud Logging, which contain every update of an event, and merges the
+// events last seen after cutoff into a history sorted by the time they were
+// first seen.
+func mergeEventHistory(cluster, logged []corev1.Event, cutoff time.Time) []eventHistoryEntry {
+	type sourcedEvent struct {
+		event            *corev1.Event
+		cluster, logging bool
+	}
+	// An event is identified by its UID, or by its name if the export
+	// dropped it.
+	eventKey := func(e *corev1.Event) string {
+		if e.UID != "" {
+			return string(e.UID)
+		}
+		return e.Namespace + "/" + e.Name
+	}
+	var order []string
+	events := map[string]*sourcedEvent{}
+	for i := range cluster {
+		e := &cluster[i]
+		key := eventKey(e)
+		if _, ok := events[key]; !ok {
+			order = append(order, key)
+		}
+		events[key] = &sourcedEvent{event: e, cluster: true}
+	}
+	for i := range logged {
+		e := &logged[i]
+		key := eventKey(e)
+		se, ok := events[key]
+		if !ok {
+			order = append(order, key)
+			events[key] = &sourcedEvent{event: e, logging: true}
+			continue
+		}
+		se.logging = true
+		// Keep the latest update, preferring the cluster's copy.
+		if !se.cluster && eventTime(e).After(eventTime(se.event)) {
+			se.event = e
+		}
+	}
+
+	entries := map[string]*eventHistoryEntry{}
+	for _, key := range order {
+		se := events[key]
+		e := se.event
+		last := eventTime(e)
+		if last.Before(cutoff) {
+			continue
+		}
+		first := e.FirstTimestamp.Time
+		if first.IsZero() || first.After(last) {
+			first = last
+		}
+		count := e.Count
+		if e.Series != nil {
+			count = e.Series.Count
+		}
+		count = max(count, 1)
+		source := "cluster"
+		switch {
+		case se.cluster && se.logging:
+			source = "both"
+		case se.logging:
+			source = "logging"
+		}
+
+		mergeKey := strings.Join([]string{e.Type, e.Reason, e.Message}, "\x00")
+		if entry, ok := entries[mergeKey]; ok {
+			entry.count += count
+			if first.Before(entry.firstSeen) {
+				entry.firstSeen = first
+			}
+			if last.After(entry.lastSeen) {
+				entry.lastSeen = last
+			}
+			if entry.source != source {
+				entry.source = "both"
+			}
+			continue
+		}
+		entries[mergeKey] = &eventHistoryEntry{
+			timelineEntry: timelineEntry{
+				firstSeen: first,
+				lastSeen:  last,
+				count:     count,
+				eventType: e.Type,
+				reason:    e.Reason,
+				message:   e.Message,
+			},
+			source: source,
+		}
+	}
+
+	history := make([]eventHistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		history = append(history, *entry)
+	}
+	sort.Slice(history, func(i, j int) bool {
+		if !history[i].firstSeen.Equal(history[j].firstSeen) {
+			return history[i].firstSeen.Before(history[j].firstSeen)
+		}
+		return history[i].reason < history[j].reason
+	})
+	return history
+}
+
+func (h *handlers) gkeEventHistory(ctx context.Context, _ *mcp.CallToolRequest, args *gkeEventHistoryArgs) (*mcp.CallToolResult, any, error) {
+	if args.Resource == "" || args.Name == "" {
+		return nil, nil, fmt.Errorf("resource and name are required")
+	}
+	since := defaultEventHistoryWindow
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		since = d
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultEventHistoryLimit
+	}
+	gvr, err := h.findGVR(args.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	gvk, err := h.mapper.KindFor(gvr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get kind for %s: %w", gvr, err)
+	}
+	namespaced, err := h.isNamespaced(gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+	namespace := ""
+	if namespaced {
+		namespace = args.Namespace
+		if namespace == "" {
+			namespace = h.defaultNamespace
+		}
+	}
+	start := time.Now().Add(-since)
+
+	// Events of cluster-scoped objects are in the default namespace.
+	eventNamespace := namespace
+	if eventNamespace == "" {
+		eventNamespace = metav1.NamespaceDefault
+	}
+	list, err := h.clientset.CoreV1().Events(eventNamespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{"involvedObject.kind": gvk.Kind, "involvedObject.name": args.Name}.String(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	var clusterEvents []corev1.Event
+	for _, e := range list.Items {
+		if e.InvolvedObject.Kind == gvk.Kind && e.InvolvedObject.Name == args.Name {
+			clusterEvents = append(clusterEvents, e)
+		}
+	}
+
+	var loggedEvents []corev1.Event
+	var loggingErr error
+	it := h.logadminClient.Entries(ctx, logadmin.Filter(eventLogFilter(gvk.Kind, namespace, args.Name, args.ClusterName, start)), logadmin.NewestFirst())
+	for read := 0; read < limit; read++ {
+		entry, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			loggingErr = err
+			break
+		}
+		payload, ok := entry.Payload.(*structpb.Struct)
+		if !ok {
+			continue
+		}
+		e, err := loggedEvent(payload)
+		if err != nil {
+			continue
+		}
+		loggedEvents = append(loggedEvents, *e)
+	}
+
+	history := mergeEventHistory(clusterEvents, loggedEvents, start)
+
+	var output strings.Builder
+	target := gvk.Kind + "/" + args.Name
+	if namespace != "" {
+		target = namespace + "/" + target
+	}
+	output.WriteString(fmt.Sprintf("Events of %s since %s: %d in the cluster, %d log entries in Cloud Logging.\n",
+		target, start.UTC().Format(time.RFC3339), len(clusterEvents), len(loggedEvents)))
+	if loggingErr != nil {
+		output.WriteString(fmt.Sprintf("Failed to read events from Cloud Logging, only the cluster's events are shown: %v\n", loggingErr))
+	}
+	output.WriteString("\nFIRST_SEEN\tLAST_SEEN\tCOUNT\tTYPE\tREASON\tSOURCE\tMESSAGE\n")
+	for _, e := range history {
+		output.WriteString(fmt.Sprintf("%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			e.firstSeen.UTC().Format(time.RFC3339),
+			e.lastSeen.UTC().Format(time.RFC3339),
+			e.count,
+			e.eventType,
+			e.reason,
+			e.source,
+			e.message,
+		))
+	}
+	if len(history) == 0 {
+		output.WriteString(fmt.Sprintf("No events in the last %s.\n", since))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/types/known/structpb"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestMergeEventHistory(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	event := func(uid string, reason string, first, last time.Duration, count int32) corev1.Event {
+		return corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{UID: types.UID(uid), Namespace: "default", Name: "web-0." + uid},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web-0"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Message:        reason + " message",
+			FirstTimestamp: metav1.NewTime(now.Add(-first)),
+			LastTimestamp:  metav1.NewTime(now.Add(-last)),
+			Count:          count,
+		}
+	}
+	cluster := []corev1.Event{
+		event("backoff", "BackOff", 50*time.Minute, time.Minute, 12),
+	}
+	logged := []corev1.Event{
+		// Earlier updates of the BackOff event.
+		event("backoff", "BackOff", 50*time.Minute, 40*time.Minute, 3),
+		event("backoff", "BackOff", 50*time.Minute, 20*time.Minute, 8),
+		// Older events, expired from the cluster.
+		event("scheduled", "Scheduled", 5*time.Hour, 5*time.Hour, 1),
+		event("oom-1", "OOMKilling", 4*time.Hour, 4*time.Hour, 1),
+		event("oom-2", "OOMKilling", 3*time.Hour, 3*time.Hour, 2),
+		// Too old.
+		event("ancient", "Pulled", 48*time.Hour, 48*time.Hour, 1),
+	}
+
+	got := mergeEventHistory(cluster, logged, now.Add(-24*time.Hour))
+	want := []eventHistoryEntry{
+		{timelineEntry{firstSeen: now.Add(-5 * time.Hour), lastSeen: now.Add(-5 * time.Hour), count: 1, eventType: "Warning", reason: "Scheduled", message: "Scheduled message"}, "logging"},
+		{timelineEntry{firstSeen: now.Add(-4 * time.Hour), lastSeen: now.Add(-3 * time.Hour), count: 3, eventType: "Warning", reason: "OOMKilling", message: "OOMKilling message"}, "logging"},
+		{timelineEntry{firstSeen: now.Add(-50 * time.Minute), lastSeen: now.Add(-time.Minute), count: 12, eventType: "Warning", reason: "BackOff", message: "BackOff message"}, "both"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(eventHistoryEntry{}, timelineEntry{})); diff != "" {
+		t.Errorf("mergeEventHistory() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoggedEvent(t *testing.T) {
+	payload, err := structpb.NewStruct(map[string]any{
+		"metadata":       map[string]any{"uid": "1234", "name": "web-0.abc", "namespace": "default"},
+		"involvedObject": map[string]any{"kind": "Pod", "name": "web-0", "namespace": "default"},
+		"reason":         "BackOff",
+		"count":          7,
+		"lastTimestamp":  "2025-01-02T11:59:00Z",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := loggedEvent(payload)
+	if err != nil {
+		t.Fatalf("loggedEvent() error = %v", err)
+	}
+	if e.UID != "1234" || e.Reason != "BackOff" || e.Count != 7 || !eventTime(e).Equal(time.Date(2025, 1, 2, 11, 59, 0, 0, time.UTC)) {
+		t.Errorf("loggedEvent() = %+v, want the fields of the payload", e)
+	}
+}
+
+func TestEventLogFilter(t *testing.T) {
+	got := eventLogFilter("Pod", "default", "web-0", "prod", time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC))
+	want := `resource.type="k8s_cluster" AND log_id("events") AND jsonPayload.involvedObject.kind="Pod" AND jsonPayload.involvedObject.name="web-0" AND jsonPayload.involvedObject.namespace="default" AND resource.labels.cluster_name="prod" AND timestamp>="2025-01-02T10:00:00Z"`
+	if got != want {
+		t.Errorf("eventLogFilter() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// EventsToolDescription contains the documentation for the Kubernetes Events tool.
+// It is formatted in Markdown.
+const EventsToolDescription = `
+This tool lists the events (*events.k8s.io/v1*) of a namespace, or of all namespaces, like *kubectl events*, filtered by the object they are about, their type and their age, and sorted by the time they were last seen, the most recent last. Prefer it to *kube_get_resources* on events, which neither sorts nor filters them.
+
+Repeated events are reported once, with the number of times they were seen. The API server keeps events for one hour by default: use *gke_event_history* for older events, and *kube_events_timeline* for the events of a workload and the objects related to it.
+
+## Arguments
+
+* *namespace*: (Optional) The namespace of the events. Defaults to the server's default namespace.
+* *all_namespaces*: (Optional) Set to true to list the events of all namespaces. It cannot be combined with *namespace*.
+* *kind*: (Optional) The kind of the object the events are about, e.g. *Pod* or *Deployment*, in any case.
+* *name*: (Optional) The name of the object the events are about.
+* *type*: (Optional) The type of the events: *Warning* or *Normal*.
+* *since*: (Optional) Only list the events last seen in this duration, such as *30m* or *2h*.
+* *limit*: (Optional) The maximum number of events, the most recent ones. Defaults to 100.
+
+## Response Format
+
+One row per event, with its namespace with *all_namespaces*:
+
+LAST_SEEN             TYPE     REASON            OBJECT              COUNT  MESSAGE
+2025-01-01T10:00:00Z  Normal   ScalingReplicaSet Deployment/web      1      Scaled up replica set web-7d9f to 3
+2025-01-01T10:04:10Z  Warning  BackOff           Pod/web-7d9f-abcde  12     Back-off restarting failed container
+`
+
+type eventsArgs struct {
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	Kind          string `json:"kind,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Type          string `json:"type,omitempty"`
+	Since         string `json:"since,omitempty"`
+	Limit         int    `json:"limit,omitempty"`
+}
+
+const defaultEventsLimit = 100
+
+// eventLastSeen returns the last time event was seen: the last observation
+// of its series, or the time it was reported by the current or the
+// deprecated fields.
+func eventLastSeen(event *eventsv1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.DeprecatedLastTimestamp.IsZero():
+		return event.DeprecatedLastTimestamp.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// eventCount returns the number of times event was seen.
+func eventCount(event *eventsv1.Event) int32 {
+	switch {
+	case event.Series != nil:
+		return event.Series.Count
+	case event.DeprecatedCount > 0:
+		return event.DeprecatedCount
+	}
+	return 1
+}
+
+// filterEvents returns the events of events matching args, last seen after
+// cutoff, sorted by the time they were last seen.
+func filterEvents(events []eventsv1.Event, args *eventsArgs, cutoff time.Time) []*eventsv1.Event {
+	var matched []*eventsv1.Event
+	for i := range events {
+		e := &events[i]
+		if args.Kind != "" && !strings.EqualFold(e.Regarding.Kind, args.Kind) {
+			continue
+		}
+		if args.Name != "" && e.Regarding.Name != args.Name {
+			continue
+		}
+		if args.Type != "" && e.Type != args.Type {
+			continue
+		}
+		if eventLastSeen(e).Before(cutoff) {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return eventLastSeen(matched[i]).Before(eventLastSeen(matched[j])) })
+	return matched
+}
+
+func (h *handlers) events(ctx context.Context, _ *mcp.CallToolRequest, args *eventsArgs) (*mcp.CallToolResult, any, error) {
+	if args.AllNamespaces && args.Namespace != "" {
+		return nil, nil, fmt.Errorf("namespace and all_namespaces cannot be combined")
+	}
+	switch strings.ToLower(args.Type) {
+	case "":
+	case "warning":
+		args.Type = corev1.EventTypeWarning
+	case "normal":
+		args.Type = corev1.EventTypeNormal
+	default:
+		return nil, nil, fmt.Errorf("invalid type %q: use Warning or Normal", args.Type)
+	}
+	var cutoff time.Time
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		cutoff = time.Now().Add(-d)
+	}
+	limit := defaultEventsLimit
+	if args.Limit > 0 {
+		limit = args.Limit
+	}
+	namespace := args.Namespace
+	if args.AllNamespaces {
+		namespace = metav1.NamespaceAll
+	} else if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+
+	// The name and type are selected by the API server too, the kind isn't
+	// as it is matched in any case.
+	selector := fields.Set{}
+	if args.Name != "" {
+		selector["regarding.name"] = args.Name
+	}
+	if args.Type != "" {
+		selector["type"] = args.Type
+	}
+	list, err := h.clientset.EventsV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: selector.AsSelector().String()})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	events := filterEvents(list.Items, args, cutoff)
+	if len(events) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("No events found in %s.\n", describeScope(namespace))},
+			},
+		}, nil, nil
+	}
+
+	var output strings.Builder
+	if omitted := len(events) - limit; omitted > 0 {
+		output.WriteString(fmt.Sprintf("%d older events omitted: narrow the filters or raise the limit.\n\n", omitted))
+		events = events[omitted:]
+	}
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	if args.AllNamespaces {
+		fmt.Fprint(w, "NAMESPACE\t")
+	}
+	fmt.Fprintln(w, "LAST_SEEN\tTYPE\tREASON\tOBJECT\tCOUNT\tMESSAGE")
+	for _, e := range events {
+		if args.AllNamespaces {
+			fmt.Fprintf(w, "%s\t", e.Namespace)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%d\t%s\n",
+			eventLastSeen(e).UTC().Format(time.RFC3339),
+			e.Type,
+			e.Reason,
+			e.Regarding.Kind,
+			e.Regarding.Name,
+			eventCount(e),
+			strings.TrimSpace(e.Note),
+		)
+	}
+	w.Flush()
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func newEvent(namespace, name, kind, object, eventType, reason string, lastSeen time.Time, count int32) *eventsv1.Event {
+	e := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Regarding:  corev1.ObjectReference{Kind: kind, Name: object, Namespace: namespace},
+		Type:       eventType,
+		Reason:     reason,
+		Note:       reason + " " + object,
+		EventTime:  metav1.NewMicroTime(lastSeen),
+	}
+	if count > 1 {
+		e.Series = &eventsv1.EventSeries{Count: count, LastObservedTime: metav1.NewMicroTime(lastSeen)}
+	}
+	return e
+}
+
+func TestEvents(t *testing.T) {
+	now := time.Now()
+	h := &handlers{
+		c:                config.New("test", config.Options{}),
+		defaultNamespace: "default",
+		clientset: fake.NewClientset(
+			newEvent("default", "e1", "Pod", "web-1", corev1.EventTypeWarning, "BackOff", now.Add(-time.Minute), 12),
+			newEvent("default", "e2", "Deployment", "web", corev1.EventTypeNormal, "ScalingReplicaSet", now.Add(-5*time.Minute), 1),
+			newEvent("default", "e3", "Pod", "web-2", corev1.EventTypeWarning, "FailedScheduling", now.Add(-3*time.Hour), 1),
+			newEvent("other", "e4", "Pod", "db-0", corev1.EventTypeWarning, "Unhealthy", now.Add(-2*time.Minute), 3),
+		),
+	}
+	for _, tc := range []struct {
+		name    string
+		args    eventsArgs
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "namespace sorted by last seen",
+			args:    eventsArgs{},
+			want:    []string{"FailedScheduling", "ScalingReplicaSet  Deployment/web  1", "BackOff            Pod/web-1       12"},
+			notWant: []string{"db-0", "NAMESPACE"},
+		},
+		{
+			name:    "kind, type and since",
+			args:    eventsArgs{Kind: "pod", Type: "warning", Since: "1h"},
+			want:    []string{"BackOff"},
+			notWant: []string{"ScalingReplicaSet", "FailedScheduling"},
+		},
+		{
+			name:    "all namespaces with limit",
+			args:    eventsArgs{AllNamespaces: true, Limit: 2},
+			want:    []string{"2 older events omitted", "NAMESPACE", "other      ", "Pod/db-0"},
+			notWant: []string{"ScalingReplicaSet"},
+		},
+		{
+			name: "no events",
+			args: eventsArgs{Name: "missing"},
+			want: []string{`No events found in namespace "default".`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, _, err := h.events(context.Background(), nil, &tc.args)
+			if err != nil {
+				t.Fatalf("events() failed: %v", err)
+			}
+			got := result.Content[0].(*mcp.TextContent).Text
+			for _, want := range tc.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("events() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, notWant := range tc.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("events() = %q, want it not to contain %q", got, notWant)
+				}
+			}
+			if i, j := strings.Index(got, "ScalingReplicaSet"), strings.Index(got, "BackOff"); i >= 0 && j >= 0 && i > j {
+				t.Errorf("events() = %q, want the events sorted by the time they were last seen", got)
+			}
+		})
+	}
+
+	if _, _, err := h.events(context.Background(), nil, &eventsArgs{Type: "Error"}); err == nil {
+		t.Errorf("events() with type Error succeeded, want an error")
+	}
+}
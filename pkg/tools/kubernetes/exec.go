@@ -0,0 +1,241 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+// ExecToolDescription contains the documentation for the Exec Kubernetes tool.
+// It is formatted in Markdown.
+const ExecToolDescription = `
+This tool runs a command in a container of a running pod, like *kubectl exec*, and returns its exit code, standard output and standard error. Use it for troubleshooting from inside the container, e.g. to read a configuration file, resolve a service name with *nslookup*, or check a dependency with *curl* or *nc*.
+
+The command runs without a shell and without standard input: to use pipes, redirections or variables, run a shell, e.g. *["sh", "-c", "cat /etc/resolv.conf | grep search"]*. Many images have no shell or few binaries; distroless images have neither.
+
+**The command runs with the permissions of the container and can change its state: only run read-only commands unless the user explicitly asked otherwise.**
+
+## Arguments
+
+* *pod*: The name of the pod.
+* *command*: The command and its arguments, e.g. *["cat", "/etc/nginx/nginx.conf"]*.
+* *container*: (Optional) The name of the container. Defaults to the container of single-container pods, or to the container named by the *kubectl.kubernetes.io/default-container* annotation.
+* *namespace*: (Optional) The namespace of the pod. Defaults to the server's default namespace.
+* *timeout_seconds*: (Optional) The time after which the command is stopped, and its output so far returned. Defaults to 30 seconds, and can't exceed 600 seconds, nor the timeout of *kube_exec* calls if the server configures one. It overrides the request timeout of the server.
+
+## Response Format
+
+Command exited with code 0 in container nginx of pod shop/web-7d9c6b5f4-x2x8z.
+
+## Stdout
+
+user nginx;
+worker_processes auto;
+
+## Stderr
+
+(empty)
+`
+
+type execArgs struct {
+	Pod            string   `json:"pod"`
+	Command        []string `json:"command"`
+	Container      string   `json:"container,omitempty"`
+	Namespace      string   `json:"namespace,omitempty"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
+}
+
+// CallTimeout bounds the call by the timeout of the command, leaving
+// execTimeoutGrace to find the pod and return the output of the command.
+func (a *execArgs) CallTimeout() time.Duration {
+	return a.timeout() + execTimeoutGrace
+}
+
+// timeout returns the timeout of the command.
+func (a *execArgs) timeout() time.Duration {
+	if a.TimeoutSeconds > 0 {
+		return time.Duration(a.TimeoutSeconds) * time.Second
+	}
+	return defaultExecTimeout
+}
+
+const (
+	defaultExecTimeout = 30 * time.Second
+	maxExecTimeout     = 10 * time.Minute
+	// execTimeoutGrace is the time a call has on top of the timeout of its
+	// command.
+	execTimeoutGrace = 10 * time.Second
+	// maxExecOutputBytes bounds each of the stdout and stderr of the command
+	// returned by the tool.
+	maxExecOutputBytes = 64 * 1024
+	// defaultContainerAnnotation names the default container of a pod for
+	// kubectl.
+	defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+)
+
+// cappedBuffer keeps the first max bytes written to it, and discards the
+// rest, so that a verbose command can't exhaust the memory of the server.
+type cappedBuffer struct {
+	max       int
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.buf.Write(p[:max(room, 0)])
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// String returns the output kept by b, noting if it was truncated.
+func (b *cappedBuffer) String() string {
+	if b.buf.Len() == 0 {
+		return "(empty)\n"
+	}
+	out := b.buf.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	if b.truncated {
+		out += fmt.Sprintf("... output truncated to %d bytes.\n", b.max)
+	}
+	return out
+}
+
+// execContainer returns the container of pod that the command runs in: name,
+// or else the default container of pod.
+func execContainer(pod *corev1.Pod, name string) (string, error) {
+	var names []string
+	for _, c := range pod.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	switch {
+	case name != "":
+		for _, c := range names {
+			if c == name {
+				return name, nil
+			}
+		}
+		return "", fmt.Errorf("pod %s has no container %q: its containers are %s", pod.Name, name, strings.Join(names, ", "))
+	case pod.Annotations[defaultContainerAnnotation] != "":
+		return pod.Annotations[defaultContainerAnnotation], nil
+	case len(names) == 1:
+		return names[0], nil
+	default:
+		return "", fmt.Errorf("pod %s has several containers, give one of %s", pod.Name, strings.Join(names, ", "))
+	}
+}
+
+func (h *handlers) exec(ctx context.Context, _ *mcp.CallToolRequest, args *execArgs) (*mcp.CallToolResult, any, error) {
+	if args.Pod == "" || len(args.Command) == 0 {
+		return nil, nil, fmt.Errorf("pod and command are required")
+	}
+	timeout := args.timeout()
+	if timeout > maxExecTimeout {
+		return nil, nil, fmt.Errorf("timeout_seconds can't exceed %d", int(maxExecTimeout.Seconds()))
+	}
+	// A timeout configured for the tool still bounds the call, and would cut
+	// the command before it reports its partial output.
+	if limit, ok := h.c.ToolTimeouts()["kube_exec"]; ok && limit > 0 && timeout+execTimeoutGrace > limit {
+		return nil, nil, fmt.Errorf("timeout_seconds can't exceed %d, given the timeout of kube_exec calls configured on the server", int(max(limit-execTimeoutGrace, 0).Seconds()))
+	}
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+	if err := h.checkProtectedNamespace(namespace); err != nil {
+		return nil, nil, err
+	}
+	pod, err := h.clientset.CoreV1().Pods(namespace).Get(ctx, args.Pod, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, nil, fmt.Errorf("pod %s is %s, not running: commands only run in running pods", pod.Name, pod.Status.Phase)
+	}
+	container, err := execContainer(pod, args.Container)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req := h.clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(namespace).Name(pod.Name).SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   args.Command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(h.restConfig, http.MethodPost, req.URL())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create exec executor: %w", err)
+	}
+	// Prefer the WebSocket protocol, like kubectl, and fall back to SPDY for
+	// older API servers.
+	if websocketExecutor, err := remotecommand.NewWebSocketExecutor(h.restConfig, http.MethodGet, req.URL().String()); err == nil {
+		if executor, err = remotecommand.NewFallbackExecutor(websocketExecutor, executor, func(err error) bool {
+			return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to create exec executor: %w", err)
+		}
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	stdout := &cappedBuffer{max: maxExecOutputBytes}
+	stderr := &cappedBuffer{max: maxExecOutputBytes}
+	err = executor.StreamWithContext(execCtx, remotecommand.StreamOptions{Stdout: stdout, Stderr: stderr})
+	exitCode := 0
+	var exitErr exec.CodeExitError
+	switch {
+	case errors.As(err, &exitErr):
+		exitCode = exitErr.ExitStatus()
+	case execCtx.Err() != nil && ctx.Err() == nil:
+		return nil, nil, fmt.Errorf("the command didn't complete in %s; it may still be running in the container. Output so far:\n\n## Stdout\n\n%s\n## Stderr\n\n%s", timeout, stdout, stderr)
+	case err != nil:
+		return nil, nil, fmt.Errorf("failed to run command: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: execResult(exitCode, namespace, pod.Name, container, stdout, stderr)},
+		},
+	}, nil, nil
+}
+
+// execResult formats the result of a command run in container of pod.
+func execResult(exitCode int, namespace, pod, container string, stdout, stderr *cappedBuffer) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Command exited with code %d in container %s of pod %s/%s.\n", exitCode, container, namespace, pod))
+	output.WriteString("\n## Stdout\n\n" + stdout.String())
+	output.WriteString("\n## Stderr\n\n" + stderr.String())
+	return output.String()
+}
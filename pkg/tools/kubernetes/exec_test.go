@@ -0,0 +1,145 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{max: 8}
+	for _, s := range []string{"hello ", "world", "!"} {
+		if n, err := b.Write([]byte(s)); n != len(s) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v, want %d, nil", s, n, err, len(s))
+		}
+	}
+	if got, want := b.String(), "hello wo\n... output truncated to 8 bytes.\n"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := (&cappedBuffer{max: 8}).String(), "(empty)\n"; got != want {
+		t.Errorf("String() of empty buffer = %q, want %q", got, want)
+	}
+}
+
+func TestExecContainer(t *testing.T) {
+	pod := func(annotations map[string]string, containers ...string) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Annotations: annotations}}
+		for _, c := range containers {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: c})
+		}
+		return p
+	}
+	for _, tc := range []struct {
+		name      string
+		pod       *corev1.Pod
+		container string
+		want      string
+		wantErr   string
+	}{
+		{name: "single", pod: pod(nil, "nginx"), want: "nginx"},
+		{name: "named", pod: pod(nil, "nginx", "envoy"), container: "envoy", want: "envoy"},
+		{name: "annotation", pod: pod(map[string]string{defaultContainerAnnotation: "envoy"}, "nginx", "envoy"), want: "envoy"},
+		{name: "ambiguous", pod: pod(nil, "nginx", "envoy"), wantErr: "give one of nginx, envoy"},
+		{name: "missing", pod: pod(nil, "nginx"), container: "envoy", wantErr: `no container "envoy"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := execContainer(tc.pod, tc.container)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("execContainer() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("execContainer() = %q, %v, want %q", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecResult(t *testing.T) {
+	stdout := &cappedBuffer{max: 64}
+	stdout.Write([]byte("search shop.svc.cluster.local"))
+	got := execResult(1, "shop", "web", "nginx", stdout, &cappedBuffer{max: 64})
+	want := `Command exited with code 1 in container nginx of pod shop/web.
+
+## Stdout
+
+search shop.svc.cluster.local
+
+## Stderr
+
+(empty)
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("execResult() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExecChecks(t *testing.T) {
+	h := &handlers{
+		c:                config.New("test", config.Options{}),
+		clientset:        fake.NewClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "job-1", Namespace: "shop"}, Status: corev1.PodStatus{Phase: corev1.PodSucceeded}}),
+		defaultNamespace: "shop",
+	}
+	for _, tc := range []struct {
+		args    *execArgs
+		wantErr string
+	}{
+		{args: &execArgs{Pod: "web"}, wantErr: "pod and command are required"},
+		{args: &execArgs{Pod: "web", Command: []string{"ls"}, TimeoutSeconds: 3600}, wantErr: "can't exceed 600"},
+		{args: &execArgs{Pod: "coredns", Namespace: "kube-system", Command: []string{"ls"}}, wantErr: "protected"},
+		{args: &execArgs{Pod: "job-1", Command: []string{"ls"}}, wantErr: "is Succeeded, not running"},
+	} {
+		_, _, err := h.exec(context.Background(), nil, tc.args)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("exec(%+v) error = %v, want %q", tc.args, err, tc.wantErr)
+		}
+	}
+}
+
+func TestExecTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		args *execArgs
+		want time.Duration
+	}{
+		{args: &execArgs{}, want: defaultExecTimeout + execTimeoutGrace},
+		{args: &execArgs{TimeoutSeconds: 300}, want: 5*time.Minute + execTimeoutGrace},
+	} {
+		if got := tc.args.CallTimeout(); got != tc.want {
+			t.Errorf("CallTimeout(%+v) = %v, want %v", tc.args, got, tc.want)
+		}
+	}
+
+	// A timeout configured for the tool caps the timeout of the command.
+	h := &handlers{
+		c:                config.New("test", config.Options{ToolTimeouts: map[string]time.Duration{"kube_exec": time.Minute}}),
+		clientset:        fake.NewClientset(),
+		defaultNamespace: "shop",
+	}
+	_, _, err := h.exec(context.Background(), nil, &execArgs{Pod: "web", Command: []string{"ls"}, TimeoutSeconds: 120})
+	if want := "can't exceed 50, given the timeout of kube_exec calls"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("exec() error = %v, want %q", err, want)
+	}
+}
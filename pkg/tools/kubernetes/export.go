@@ -0,0 +1,351 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// ExportManifestToolDescription contains the documentation for the Kubernetes Export Manifest tool.
+// It is formatted in Markdown.
+const ExportManifestToolDescription = `
+This tool returns Kubernetes resources as manifests cleaned for re-application, like *kubectl neat*: the fields set by the API server and by controllers are removed, so that the manifests can be applied to another namespace or cluster, or kept in version control.
+
+The following are removed:
+
+* The *status*.
+* The server-set metadata: *uid*, *resourceVersion*, *generation*, *creationTimestamp*, *managedFields*, *selfLink*, owner references, and annotations such as *kubectl.kubernetes.io/last-applied-configuration* and *deployment.kubernetes.io/revision*.
+* Fields allocated by the cluster, such as the cluster IPs and node ports of services, the bound volume of persistent volume claims, the node of pods, and the generated selectors of jobs.
+* Fields set to their default values, such as *terminationMessagePath*, *dnsPolicy: ClusterFirst* or *revisionHistoryLimit: 10*.
+
+## Arguments
+
+* *resource*: The type of the resource, e.g. *deployments*.
+* *name*: (Optional) The name of the resource. Without a name, all the resources of the type in the namespace are exported, optionally filtered with *label_selector*.
+* *namespace*: (Optional) The namespace of the resources. Defaults to the default namespace.
+* *label_selector*: (Optional) A label selector filtering the resources, e.g. *app=web*.
+* *target_namespace*: (Optional) The namespace to set in the exported manifests, to copy the resources to another namespace.
+
+Unless the server is configured otherwise, the values of Secrets, and of environment variables with sensitive names, are replaced with *[REDACTED]* and must be filled in before applying the manifests.
+
+## Response Format
+
+The manifests, as YAML documents separated by ---:
+
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  ...
+`
+
+type exportManifestArgs struct {
+	Resource        string `json:"resource"`
+	Name            string `json:"name,omitempty"`
+	Namespace       string `json:"namespace,omitempty"`
+	LabelSelector   string `json:"label_selector,omitempty"`
+	TargetNamespace string `json:"target_namespace,omitempty"`
+}
+
+// serverSetAnnotations are annotations set by the API server or by
+// controllers, which don't belong in exported manifests.
+var serverSetAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"deployment.kubernetes.io/revision",
+	"deployment.kubernetes.io/desired-replicas",
+	"deployment.kubernetes.io/max-replicas",
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+	"pv.kubernetes.io/provisioned-by",
+	"volume.beta.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/selected-node",
+	"endpoints.kubernetes.io/last-change-trigger-time",
+	"cloud.google.com/neg-status",
+}
+
+// serverSetLabels are labels set by controllers on the resources they
+// manage.
+var serverSetLabels = []string{
+	"controller-uid",
+	"batch.kubernetes.io/controller-uid",
+	"pod-template-hash",
+}
+
+// defaultedFields are fields that the API server sets to these values when
+// they are omitted, by path from the object or from a pod template spec.
+var defaultedFields = map[string]any{
+	"spec.progressDeadlineSeconds": int64(600),
+	"spec.revisionHistoryLimit":    int64(10),
+	"spec.podManagementPolicy":     "OrderedReady",
+	"spec.sessionAffinity":         "None",
+	"spec.internalTrafficPolicy":   "Cluster",
+	"spec.ipFamilyPolicy":          "SingleStack",
+	"spec.volumeMode":              "Filesystem",
+}
+
+// defaultedPodSpecFields are fields of pod specs that the API server sets to
+// these values when they are omitted.
+var defaultedPodSpecFields = map[string]any{
+	"dnsPolicy":                     "ClusterFirst",
+	"schedulerName":                 "default-scheduler",
+	"terminationGracePeriodSeconds": int64(30),
+	"enableServiceLinks":            true,
+}
+
+// defaultedContainerFields are fields of containers that the API server sets
+// to these values when they are omitted.
+var defaultedContainerFields = map[string]any{
+	"terminationMessagePath":   "/dev/termination-log",
+	"terminationMessagePolicy": "File",
+}
+
+// sanitizeManifest removes from obj the fields set by the API server and by
+// controllers, and the fields set to their default values.
+func sanitizeManifest(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "status")
+	for _, field := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink", "ownerReferences", "deletionTimestamp", "deletionGracePeriodSeconds"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	removeKeys(obj.Object, []string{"metadata", "annotations"}, serverSetAnnotations)
+
+	for path, value := range defaultedFields {
+		removeIfEqual(obj.Object, strings.Split(path, "."), value)
+	}
+
+	switch obj.GetKind() {
+	case "Service":
+		// Headless services are requested with the cluster IP None.
+		if clusterIP, _, _ := unstructured.NestedString(obj.Object, "spec", "clusterIP"); clusterIP != "None" {
+			unstructured.RemoveNestedField(obj.Object, "spec", "clusterIP")
+			unstructured.RemoveNestedField(obj.Object, "spec", "clusterIPs")
+		}
+		unstructured.RemoveNestedField(obj.Object, "spec", "healthCheckNodePort")
+		unstructured.RemoveNestedField(obj.Object, "spec", "ipFamilies")
+		if ports, ok, _ := unstructured.NestedSlice(obj.Object, "spec", "ports"); ok {
+			for _, p := range ports {
+				if port, ok := p.(map[string]any); ok {
+					delete(port, "nodePort")
+					if port["protocol"] == "TCP" {
+						delete(port, "protocol")
+					}
+				}
+			}
+			unstructured.SetNestedSlice(obj.Object, ports, "spec", "ports")
+		}
+	case "PersistentVolumeClaim":
+		unstructured.RemoveNestedField(obj.Object, "spec", "volumeName")
+	case "Pod":
+		unstructured.RemoveNestedField(obj.Object, "spec", "nodeName")
+		sanitizePodSpec(obj.Object, []string{"spec"})
+	case "Job":
+		// The selector of jobs is generated from their uid.
+		if manual, _, _ := unstructured.NestedBool(obj.Object, "spec", "manualSelector"); !manual {
+			unstructured.RemoveNestedField(obj.Object, "spec", "selector")
+		}
+		removeKeys(obj.Object, []string{"metadata", "labels"}, serverSetLabels)
+	}
+
+	// Pod templates of workloads, and of the jobs of cron jobs.
+	for _, template := range [][]string{{"spec", "template"}, {"spec", "jobTemplate", "spec", "template"}} {
+		if _, ok, _ := unstructured.NestedMap(obj.Object, template...); !ok {
+			continue
+		}
+		unstructured.RemoveNestedField(obj.Object, append(template, "metadata", "creationTimestamp")...)
+		removeKeys(obj.Object, append(template, "metadata", "labels"), serverSetLabels)
+		sanitizePodSpec(obj.Object, append(template, "spec"))
+	}
+}
+
+// sanitizePodSpec removes the defaulted fields of the pod spec at path of
+// obj.
+func sanitizePodSpec(obj map[string]any, path []string) {
+	spec, ok, _ := unstructured.NestedMap(obj, path...)
+	if !ok {
+		return
+	}
+	for field, value := range defaultedPodSpecFields {
+		removeIfEqual(spec, []string{field}, value)
+	}
+	if restartPolicy, _ := spec["restartPolicy"].(string); restartPolicy == "Always" {
+		delete(spec, "restartPolicy")
+	}
+	if sc, ok := spec["securityContext"].(map[string]any); ok && len(sc) == 0 {
+		delete(spec, "securityContext")
+	}
+
+	// The token volume that the API server adds to pods.
+	var tokenVolume string
+	if volumes, ok := spec["volumes"].([]any); ok {
+		var kept []any
+		for _, v := range volumes {
+			volume, _ := v.(map[string]any)
+			if name, _ := volume["name"].(string); strings.HasPrefix(name, "kube-api-access-") {
+				tokenVolume = name
+				continue
+			}
+			kept = append(kept, v)
+		}
+		if len(kept) == 0 {
+			delete(spec, "volumes")
+		} else {
+			spec["volumes"] = kept
+		}
+	}
+
+	for _, key := range []string{"containers", "initContainers"} {
+		containers, ok := spec[key].([]any)
+		if !ok {
+			continue
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			for field, value := range defaultedContainerFields {
+				removeIfEqual(container, []string{field}, value)
+			}
+			if policy, _ := container["imagePullPolicy"].(string); policy == defaultPullPolicy(container["image"]) {
+				delete(container, "imagePullPolicy")
+			}
+			if res, ok := container["resources"].(map[string]any); ok && len(res) == 0 {
+				delete(container, "resources")
+			}
+			if tokenVolume != "" {
+				if mounts, ok := container["volumeMounts"].([]any); ok {
+					var kept []any
+					for _, m := range mounts {
+						mount, _ := m.(map[string]any)
+						if mount["name"] != tokenVolume {
+							kept = append(kept, m)
+						}
+					}
+					if len(kept) == 0 {
+						delete(container, "volumeMounts")
+					} else {
+						container["volumeMounts"] = kept
+					}
+				}
+			}
+		}
+	}
+	unstructured.SetNestedMap(obj, spec, path...)
+}
+
+// defaultPullPolicy returns the pull policy that the API server sets for
+// image: Always for the latest tag or no tag, IfNotPresent otherwise.
+func defaultPullPolicy(image any) string {
+	s, _ := image.(string)
+	if strings.Contains(s, "@") {
+		return "IfNotPresent"
+	}
+	name := s[strings.LastIndex(s, "/")+1:]
+	if _, tag, ok := strings.Cut(name, ":"); !ok || tag == "latest" {
+		return "Always"
+	}
+	return "IfNotPresent"
+}
+
+// removeIfEqual removes the field at path of obj if it's equal to value.
+func removeIfEqual(obj map[string]any, path []string, value any) {
+	got, ok, _ := unstructured.NestedFieldNoCopy(obj, path...)
+	if ok && got == value {
+		unstructured.RemoveNestedField(obj, path...)
+	}
+}
+
+// removeKeys removes keys from the string map at path of obj, and the map if
+// it's then empty.
+func removeKeys(obj map[string]any, path []string, keys []string) {
+	m, ok, _ := unstructured.NestedStringMap(obj, path...)
+	if !ok {
+		return
+	}
+	for _, key := range keys {
+		delete(m, key)
+	}
+	if len(m) == 0 {
+		unstructured.RemoveNestedField(obj, path...)
+		return
+	}
+	unstructured.SetNestedStringMap(obj, m, path...)
+}
+
+func (h *handlers) exportManifest(ctx context.Context, _ *mcp.CallToolRequest, args *exportManifestArgs) (*mcp.CallToolResult, any, error) {
+	gvr, err := h.findGVR(args.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	namespaced, err := h.isNamespaced(gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ri dynamic.ResourceInterface = h.dyn.Resource(gvr)
+	if namespaced {
+		namespace := args.Namespace
+		if namespace == "" {
+			namespace = h.defaultNamespace
+		}
+		ri = h.dyn.Resource(gvr).Namespace(namespace)
+	} else if args.TargetNamespace != "" {
+		return nil, nil, fmt.Errorf("%s are cluster-scoped and can't be exported to a namespace", gvr.Resource)
+	}
+
+	var objs []unstructured.Unstructured
+	if args.Name != "" {
+		obj, err := ri.Get(ctx, args.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		objs = append(objs, *obj)
+	} else {
+		list, err := ri.List(ctx, metav1.ListOptions{LabelSelector: args.LabelSelector})
+		if err != nil {
+			return nil, nil, err
+		}
+		objs = list.Items
+	}
+
+	var output strings.Builder
+	for i := range objs {
+		obj := &objs[i]
+		sanitizeManifest(obj)
+		if args.TargetNamespace != "" {
+			obj.SetNamespace(args.TargetNamespace)
+		}
+		if err := writeYAMLDocument(&output, h.redact(obj)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(objs) == 0 {
+		output.WriteString(fmt.Sprintf("No %s found.\n", gvr.Resource))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,277 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func TestSanitizeManifest(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "deployment",
+			in: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  uid: 0b5c
+  resourceVersion: "42"
+  generation: 3
+  creationTimestamp: "2025-01-01T00:00:00Z"
+  managedFields:
+  - manager: kubectl
+  annotations:
+    deployment.kubernetes.io/revision: "3"
+    kubectl.kubernetes.io/last-applied-configuration: "{}"
+  labels:
+    app: web
+spec:
+  replicas: 2
+  progressDeadlineSeconds: 600
+  revisionHistoryLimit: 5
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      creationTimestamp: null
+      labels:
+        app: web
+    spec:
+      dnsPolicy: ClusterFirst
+      restartPolicy: Always
+      schedulerName: default-scheduler
+      securityContext: {}
+      terminationGracePeriodSeconds: 30
+      containers:
+      - name: web
+        image: nginx:1.27
+        imagePullPolicy: IfNotPresent
+        resources: {}
+        terminationMessagePath: /dev/termination-log
+        terminationMessagePolicy: File
+status:
+  replicas: 2
+`,
+			want: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  labels:
+    app: web
+spec:
+  replicas: 2
+  revisionHistoryLimit: 5
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.27
+`,
+		},
+		{
+			name: "service",
+			in: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: NodePort
+  clusterIP: 10.0.0.1
+  clusterIPs: [10.0.0.1]
+  ipFamilies: [IPv4]
+  ipFamilyPolicy: SingleStack
+  sessionAffinity: None
+  ports:
+  - port: 80
+    protocol: TCP
+    nodePort: 30080
+`,
+			want: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: NodePort
+  ports:
+  - port: 80
+`,
+		},
+		{
+			name: "headless service",
+			in: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+spec:
+  clusterIP: None
+  clusterIPs: [None]
+  ports:
+  - port: 5432
+`,
+			want: `
+apiVersion: v1
+kind: Service
+metadata:
+  name: db
+spec:
+  clusterIP: None
+  clusterIPs: [None]
+  ports:
+  - port: 5432
+`,
+		},
+		{
+			name: "pod",
+			in: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  nodeName: node-1
+  containers:
+  - name: web
+    image: nginx
+    imagePullPolicy: Always
+    volumeMounts:
+    - name: kube-api-access-x7k2
+      mountPath: /var/run/secrets/kubernetes.io/serviceaccount
+    - name: data
+      mountPath: /data
+  volumes:
+  - name: data
+    emptyDir: {}
+  - name: kube-api-access-x7k2
+    projected: {}
+`,
+			want: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: web
+    image: nginx
+    volumeMounts:
+    - name: data
+      mountPath: /data
+  volumes:
+  - name: data
+    emptyDir: {}
+`,
+		},
+		{
+			name: "job",
+			in: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  labels:
+    controller-uid: 0b5c
+    batch.kubernetes.io/controller-uid: 0b5c
+spec:
+  selector:
+    matchLabels:
+      controller-uid: 0b5c
+  template:
+    metadata:
+      labels:
+        controller-uid: 0b5c
+        job-name: migrate
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: migrate
+        image: migrate:v1
+`,
+			want: `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+spec:
+  template:
+    metadata:
+      labels:
+        job-name: migrate
+    spec:
+      restartPolicy: Never
+      containers:
+      - name: migrate
+        image: migrate:v1
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Decode like the dynamic client, with integers as int64.
+			in, want := decodeObject(t, tc.in), decodeObject(t, tc.want)
+			sanitizeManifest(in)
+			if diff := cmp.Diff(want.Object, in.Object); diff != "" {
+				t.Errorf("sanitizeManifest() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func decodeObject(t *testing.T, manifest string) *unstructured.Unstructured {
+	t.Helper()
+	data, err := yaml.YAMLToJSON([]byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestDefaultPullPolicy(t *testing.T) {
+	for image, want := range map[string]string{
+		"nginx":                   "Always",
+		"nginx:latest":            "Always",
+		"nginx:1.27":              "IfNotPresent",
+		"localhost:5000/app":      "Always",
+		"gcr.io/p/app@sha256:abc": "IfNotPresent",
+	} {
+		if got := defaultPullPolicy(image); got != want {
+			t.Errorf("defaultPullPolicy(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
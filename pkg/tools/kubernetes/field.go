@@ -0,0 +1,118 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// GetFieldToolDescription contains the documentation for the Kubernetes Get Field tool.
+// It is formatted in Markdown.
+const GetFieldToolDescription = `
+This tool returns the value at a JSONPath of a single Kubernetes resource, e.g. the image of a deployment or the cluster IP of a service, like *kubectl get -o jsonpath*. Use it instead of kube_get_resources when only one value of a resource is needed: it returns the value instead of the whole resource.
+
+## Arguments
+
+* *resource*: The type of the resource, e.g. *deployments*.
+* *name*: The name of the resource.
+* *namespace*: (Optional) The namespace of the resource. Defaults to the default namespace.
+* *jsonpath*: The [JSONPath](https://kubernetes.io/docs/reference/kubectl/jsonpath/) of the value, with or without braces, e.g. *.spec.clusterIP*, *.spec.template.spec.containers[0].image* or *{.status.conditions[?(@.type=="Ready")].status}*.
+
+## Response Format
+
+The value: scalars as is, and lists and objects as JSON. When the JSONPath matches several values, e.g. *.spec.containers[*].image*, they are returned one per line:
+
+nginx:1.27
+envoy:v1.30
+
+If the JSONPath doesn't match anything, the tool returns an error. The values of Secrets, and of environment variables with sensitive names, are returned as *[REDACTED]* unless the server is configured otherwise.
+`
+
+type getFieldArgs struct {
+	Resource  string `json:"resource"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	JSONPath  string `json:"jsonpath"`
+}
+
+// fieldValues returns the values at path of obj, formatted as with custom
+// columns.
+func fieldValues(obj map[string]any, path string) ([]string, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	j := jsonpath.New("field")
+	if err := j.Parse(path); err != nil {
+		return nil, fmt.Errorf("failed to parse jsonpath: %w", err)
+	}
+	results, err := j.FindResults(obj)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, result := range results {
+		for _, v := range result {
+			values = append(values, formatColumnValue(v.Interface()))
+		}
+	}
+	return values, nil
+}
+
+func (h *handlers) getField(ctx context.Context, _ *mcp.CallToolRequest, args *getFieldArgs) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" || args.JSONPath == "" {
+		return nil, nil, fmt.Errorf("name and jsonpath are required")
+	}
+	gvr, err := h.findGVR(args.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	namespaced, err := h.isNamespaced(gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ri dynamic.ResourceInterface = h.dyn.Resource(gvr)
+	if namespaced {
+		namespace := args.Namespace
+		if namespace == "" {
+			namespace = h.defaultNamespace
+		}
+		ri = h.dyn.Resource(gvr).Namespace(namespace)
+	}
+	obj, err := ri.Get(ctx, args.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values, err := fieldValues(h.redact(obj).Object, args.JSONPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get %s of %s/%s: %w", args.JSONPath, gvr.Resource, args.Name, err)
+	}
+	if len(values) == 0 {
+		return nil, nil, fmt.Errorf("%s of %s/%s matched no value", args.JSONPath, gvr.Resource, args.Name)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(values, "\n")},
+		},
+	}, nil, nil
+}
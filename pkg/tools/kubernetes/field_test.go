@@ -0,0 +1,65 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFieldValues(t *testing.T) {
+	obj := decodeObject(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.27
+        ports: [{containerPort: 80}]
+      - name: proxy
+        image: envoy:v1.30
+status:
+  conditions:
+  - {type: Available, status: "True"}
+`).Object
+	for _, tc := range []struct {
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{path: ".spec.replicas", want: []string{"3"}},
+		{path: "{.spec.template.spec.containers[0].image}", want: []string{"nginx:1.27"}},
+		{path: ".spec.template.spec.containers[*].image", want: []string{"nginx:1.27", "envoy:v1.30"}},
+		{path: ".spec.template.spec.containers[0].ports", want: []string{`[{"containerPort":80}]`}},
+		{path: `.status.conditions[?(@.type=="Available")].status`, want: []string{"True"}},
+		{path: ".spec.paused", wantErr: true},
+		{path: ".spec[", wantErr: true},
+	} {
+		got, err := fieldValues(obj, tc.path)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("fieldValues(%q) error = %v, wantErr %t", tc.path, err, tc.wantErr)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("fieldValues(%q) mismatch (-want +got):\n%s", tc.path, diff)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/compute/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GKECheckServiceFirewallToolDescription contains the documentation for the GKE Check Service Firewall tool.
+// It is formatted in Markdown.
+const GKECheckServiceFirewallToolDescription = `
+This tool checks that the VPC firewall rules let traffic reach the nodes of a NodePort or LoadBalancer Service on GKE. Missing or shadowed firewall rules are a classic cause of load balancers with unhealthy backends, or of services that time out.
+
+The tool correlates the Service with the Compute Engine state: it reads the network, the network tags and the service account of the nodes' instances, and the firewall rules of the network, and checks the flows the Service needs:
+
+* **Health checks**: LoadBalancer Services are probed by the Google Cloud health checkers from 35.191.0.0/16 and 130.211.0.0/22, on the *healthCheckNodePort* of Services with *externalTrafficPolicy: Local*, or on the kube-proxy health port 10256 otherwise.
+* **Client traffic**: the ports of LoadBalancer Services, from their *loadBalancerSourceRanges* (or from anywhere for external load balancers), and the node ports of NodePort Services.
+
+A flow is allowed when an ingress allow rule targeting the nodes, by network tag, service account or for all instances, covers its source range, protocol and port, and no deny rule with a higher priority matches it. GKE creates the rules of LoadBalancer Services itself, unless the cluster's firewall rules are managed separately, e.g. in Shared VPC service projects.
+
+## Arguments
+
+* *service*: The name of the Service.
+* *namespace*: (Optional) The namespace of the Service. Defaults to the server's default namespace.
+
+## Response Format
+
+The Service and the nodes, grouped by network, tags and service account, followed by the result of each flow, and the commands creating the missing rules:
+
+Service default/web: LoadBalancer (external), externalTrafficPolicy Cluster.
+Nodes: 3 in network default, tags gke-prod-1a2b3c4d-node.
+
+FLOW          SOURCE          PROTOCOL  PORT   RESULT   RULE
+health check  35.191.0.0/16   tcp       10256  ALLOWED  k8s-1a2b3c4d-node-hc
+client        0.0.0.0/0       tcp       80     BLOCKED  denied by deny-all-ingress (priority 900)
+`
+
+type gkeCheckServiceFirewallArgs struct {
+	Service   string `json:"service"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// healthCheckRanges are the source ranges of the Google Cloud health checks.
+var healthCheckRanges = []string{"35.191.0.0/16", "130.211.0.0/22"}
+
+// kubeProxyHealthPort is the port of the kube-proxy health checks of
+// Services with the Cluster external traffic policy.
+const kubeProxyHealthPort = 10256
+
+// firewallFlow is traffic that must reach the nodes of a Service.
+type firewallFlow struct {
+	name     string
+	source   string
+	protocol string
+	port     int64
+}
+
+// firewallTarget is the instances firewall rules apply to.
+type firewallTarget struct {
+	network         string
+	tags            []string
+	serviceAccounts []string
+}
+
+// firewallResult is the outcome of a flow.
+type firewallResult struct {
+	flow    firewallFlow
+	allowed bool
+	detail  string
+}
+
+// serviceFlows returns the flows that svc needs.
+func serviceFlows(svc *corev1.Service) []firewallFlow {
+	var flows []firewallFlow
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		port := int64(kubeProxyHealthPort)
+		if svc.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyLocal && svc.Spec.HealthCheckNodePort != 0 {
+			port = int64(svc.Spec.HealthCheckNodePort)
+		}
+		for _, source := range healthCheckRanges {
+			flows = append(flows, firewallFlow{name: "health check", source: source, protocol: "tcp", port: port})
+		}
+		sources := svc.Spec.LoadBalancerSourceRanges
+		if len(sources) == 0 && !isInternalLoadBalancer(svc) {
+			sources = []string{"0.0.0.0/0"}
+		}
+		for _, p := range svc.Spec.Ports {
+			for _, source := range sources {
+				flows = append(flows, firewallFlow{name: "client", source: source, protocol: strings.ToLower(string(p.Protocol)), port: int64(p.Port)})
+			}
+		}
+	case corev1.ServiceTypeNodePort:
+		for _, p := range svc.Spec.Ports {
+			flows = append(flows, firewallFlow{name: "node port", source: "0.0.0.0/0", protocol: strings.ToLower(string(p.Protocol)), port: int64(p.NodePort)})
+		}
+	}
+	return flows
+}
+
+// isInternalLoadBalancer reports whether svc is exposed by an internal load
+// balancer.
+func isInternalLoadBalancer(svc *corev1.Service) bool {
+	for _, key := range []string{"networking.gke.io/load-balancer-type", "cloud.google.com/load-balancer-type"} {
+		if strings.EqualFold(svc.Annotations[key], "internal") {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo reports whether the ingress rule applies to target.
+func appliesTo(rule *compute.Firewall, target firewallTarget) bool {
+	if rule.Disabled || rule.Direction != "INGRESS" || path.Base(rule.Network) != path.Base(target.network) {
+		return false
+	}
+	switch {
+	case len(rule.TargetTags) > 0:
+		for _, tag := range rule.TargetTags {
+			if slices.Contains(target.tags, tag) {
+				return true
+			}
+		}
+		return false
+	case len(rule.TargetServiceAccounts) > 0:
+		for _, sa := range rule.TargetServiceAccounts {
+			if slices.Contains(target.serviceAccounts, sa) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// firewallProtocols maps protocol numbers to the names of the protocols of
+// Services.
+var firewallProtocols = map[string]string{"6": "tcp", "17": "udp", "132": "sctp"}
+
+// firewallEntry is an allowed or denied entry of a firewall rule.
+type firewallEntry struct {
+	protocol string
+	ports    []string
+}
+
+// matchesProtocolPort reports whether entries cover protocol and port.
+func matchesProtocolPort(protocol string, port int64, entries []firewallEntry) bool {
+	for _, e := range entries {
+		p := strings.ToLower(e.protocol)
+		if name, ok := firewallProtocols[p]; ok {
+			p = name
+		}
+		if p != "all" && p != protocol {
+			continue
+		}
+		if len(e.ports) == 0 {
+			return true
+		}
+		for _, r := range e.ports {
+			low, high, _ := strings.Cut(r, "-")
+			if high == "" {
+				high = low
+			}
+			l, err1 := strconv.ParseInt(low, 10, 64)
+			h, err2 := strconv.ParseInt(high, 10, 64)
+			if err1 == nil && err2 == nil && l <= port && port <= h {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ruleEntries returns the allowed or denied entries of rule.
+func ruleEntries(rule *compute.Firewall) []firewallEntry {
+	var entries []firewallEntry
+	for _, a := range rule.Allowed {
+		entries = append(entries, firewallEntry{a.IPProtocol, a.Ports})
+	}
+	for _, d := range rule.Denied {
+		entries = append(entries, firewallEntry{d.IPProtocol, d.Ports})
+	}
+	return entries
+}
+
+// coversSource reports whether the source ranges of rule cover source, or,
+// if partial, overlap it.
+func coversSource(rule *compute.Firewall, source netip.Prefix, partial bool) bool {
+	for _, r := range rule.SourceRanges {
+		p, err := netip.ParsePrefix(r)
+		if err != nil {
+			continue
+		}
+		if partial && p.Overlaps(source) {
+			return true
+		}
+		if p.Bits() <= source.Bits() && p.Contains(source.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFirewall checks flows against the firewall rules for target. Rules
+// are evaluated by priority: the allow rule covering a flow must have a
+// higher priority, i.e. a lower number, than any deny rule matching it.
+func checkFirewall(flows []firewallFlow, rules []*compute.Firewall, target firewallTarget) []firewallResult {
+	var applicable []*compute.Firewall
+	for _, rule := range rules {
+		if appliesTo(rule, target) {
+			applicable = append(applicable, rule)
+		}
+	}
+	sort.SliceStable(applicable, func(i, j int) bool {
+		if applicable[i].Priority != applicable[j].Priority {
+			return applicable[i].Priority < applicable[j].Priority
+		}
+		// Deny rules take precedence over allow rules of the same priority.
+		return len(applicable[i].Denied) > 0 && len(applicable[j].Denied) == 0
+	})
+
+	var results []firewallResult
+	for _, flow := range flows {
+		result := firewallResult{flow: flow, detail: "no rule allows it"}
+		source, err := netip.ParsePrefix(flow.source)
+		if err != nil {
+			result.detail = fmt.Sprintf("invalid source range: %v", err)
+			results = append(results, result)
+			continue
+		}
+		for _, rule := range applicable {
+			if !matchesProtocolPort(flow.protocol, flow.port, ruleEntries(rule)) {
+				continue
+			}
+			if len(rule.Denied) > 0 && coversSource(rule, source, true) {
+				result.detail = fmt.Sprintf("denied by %s (priority %d)", rule.Name, rule.Priority)
+				break
+			}
+			if len(rule.Allowed) > 0 && coversSource(rule, source, false) {
+				result.allowed = true
+				result.detail = rule.Name
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// nodeTarget returns the firewall target of the instance of a node.
+func nodeTarget(instance *compute.Instance) firewallTarget {
+	var t firewallTarget
+	if len(instance.NetworkInterfaces) > 0 {
+		t.network = instance.NetworkInterfaces[0].Network
+	}
+	if instance.Tags != nil {
+		t.tags = instance.Tags.Items
+	}
+	for _, sa := range instance.ServiceAccounts {
+		t.serviceAccounts = append(t.serviceAccounts, sa.Email)
+	}
+	return t
+}
+
+// networkProject returns the project of network, a network URL, which is the
+// host project with Shared VPC, or project if network isn't a URL. The
+// firewall rules of a network belong to its project.
+func networkProject(network, project string) string {
+	if p, ok := strings.CutPrefix(network, "https://www.googleapis.com/compute/v1/projects/"); ok {
+		project, _, _ = strings.Cut(p, "/")
+	}
+	return project
+}
+
+func (h *handlers) gkeCheckServiceFirewall(ctx context.Context, _ *mcp.CallToolRequest, args *gkeCheckServiceFirewallArgs) (*mcp.CallToolResult, any, error) {
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+	svc, err := h.clientset.CoreV1().Services(namespace).Get(ctx, args.Service, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	if svc.Spec.Type != corev1.ServiceTypeLoadBalancer && svc.Spec.Type != corev1.ServiceTypeNodePort {
+		return nil, nil, fmt.Errorf("service %s is of type %s: only NodePort and LoadBalancer services are exposed through the firewall", svc.Name, svc.Spec.Type)
+	}
+	flows := serviceFlows(svc)
+
+	nodes, err := h.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	// Nodes of the same node pool share their firewall target: group them
+	// to check each target once.
+	type nodeGroup struct {
+		project string
+		target  firewallTarget
+		nodes   []string
+	}
+	groups := map[string]*nodeGroup{}
+	var keys []string
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		project, zone, name, err := parseGCEProviderID(node.Spec.ProviderID)
+		if err != nil {
+			continue
+		}
+		instance, err := h.computeService.Instances.Get(project, zone, name).Context(ctx).Do()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get the instance of node %s: %w", node.Name, err)
+		}
+		target := nodeTarget(instance)
+		key := project + "|" + target.network + "|" + strings.Join(target.tags, ",") + "|" + strings.Join(target.serviceAccounts, ",")
+		if _, ok := groups[key]; !ok {
+			groups[key] = &nodeGroup{project: project, target: target}
+			keys = append(keys, key)
+		}
+		groups[key].nodes = append(groups[key].nodes, node.Name)
+	}
+	if len(keys) == 0 {
+		return nil, nil, fmt.Errorf("no GCE nodes found: the firewall can only be checked on GKE")
+	}
+	sort.Strings(keys)
+
+	rulesByProject := map[string][]*compute.Firewall{}
+	for _, key := range keys {
+		g := groups[key]
+		hostProject := networkProject(g.target.network, g.project)
+		if _, ok := rulesByProject[hostProject]; ok {
+			continue
+		}
+		var rules []*compute.Firewall
+		err := h.computeService.Firewalls.List(hostProject).Pages(ctx, func(page *compute.FirewallList) error {
+			rules = append(rules, page.Items...)
+			return nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list the firewall rules of project %s: %w", hostProject, err)
+		}
+		rulesByProject[hostProject] = rules
+	}
+
+	var output strings.Builder
+	lbType := ""
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		lbType = " (external)"
+		if isInternalLoadBalancer(svc) {
+			lbType = " (internal)"
+		}
+	}
+	output.WriteString(fmt.Sprintf("Service %s/%s: %s%s, externalTrafficPolicy %s.\n", svc.Namespace, svc.Name, svc.Spec.Type, lbType, svc.Spec.ExternalTrafficPolicy))
+	if isInternalLoadBalancer(svc) && len(svc.Spec.LoadBalancerSourceRanges) == 0 {
+		output.WriteString("The client ranges of internal load balancers aren't known: pass loadBalancerSourceRanges to check them.\n")
+	}
+
+	blocked := 0
+	for _, key := range keys {
+		g := groups[key]
+		hostProject := networkProject(g.target.network, g.project)
+		tags := "<none>"
+		if len(g.target.tags) > 0 {
+			tags = strings.Join(g.target.tags, ",")
+		}
+		output.WriteString(fmt.Sprintf("\nNodes: %d in network %s, tags %s (%s).\n", len(g.nodes), path.Base(g.target.network), tags, strings.Join(g.nodes, ", ")))
+		output.WriteString("FLOW\tSOURCE\tPROTOCOL\tPORT\tRESULT\tRULE\n")
+		var missing []firewallResult
+		for _, r := range checkFirewall(flows, rulesByProject[hostProject], g.target) {
+			status := "ALLOWED"
+			if !r.allowed {
+				status = "BLOCKED"
+				blocked++
+				missing = append(missing, r)
+			}
+			output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%d\t%s\t%s\n", r.flow.name, r.flow.source, r.flow.protocol, r.flow.port, status, r.detail))
+		}
+		if len(missing) > 0 && len(g.target.tags) > 0 {
+			output.WriteString("\nTo allow the blocked flows:\n")
+			for _, r := range missing {
+				output.WriteString(fmt.Sprintf("gcloud compute firewall-rules create allow-%s-%s-%d --project %s --network %s --direction INGRESS --allow %s:%d --source-ranges %s --target-tags %s\n",
+					svc.Name, strings.ReplaceAll(r.flow.name, " ", "-"), r.flow.port, hostProject, path.Base(g.target.network), r.flow.protocol, r.flow.port, r.flow.source, g.target.tags[0]))
+			}
+		}
+	}
+	if blocked == 0 {
+		output.WriteString("\nAll the flows of the service are allowed.\n")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/compute/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testNetwork = "https://www.googleapis.com/compute/v1/projects/host/global/networks/prod"
+
+func TestServiceFlows(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.ServiceSpec{
+			Type:                     corev1.ServiceTypeLoadBalancer,
+			ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyLocal,
+			HealthCheckNodePort:      31000,
+			LoadBalancerSourceRanges: []string{"203.0.113.0/24"},
+			Ports:                    []corev1.ServicePort{{Port: 443, NodePort: 30443, Protocol: corev1.ProtocolTCP}},
+		},
+	}
+	want := []firewallFlow{
+		{name: "health check", source: "35.191.0.0/16", protocol: "tcp", port: 31000},
+		{name: "health check", source: "130.211.0.0/22", protocol: "tcp", port: 31000},
+		{name: "client", source: "203.0.113.0/24", protocol: "tcp", port: 443},
+	}
+	if diff := cmp.Diff(want, serviceFlows(svc), cmp.AllowUnexported(firewallFlow{})); diff != "" {
+		t.Errorf("serviceFlows(LoadBalancer) mismatch (-want +got):\n%s", diff)
+	}
+
+	svc.Spec.Type = corev1.ServiceTypeNodePort
+	want = []firewallFlow{{name: "node port", source: "0.0.0.0/0", protocol: "tcp", port: 30443}}
+	if diff := cmp.Diff(want, serviceFlows(svc), cmp.AllowUnexported(firewallFlow{})); diff != "" {
+		t.Errorf("serviceFlows(NodePort) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckFirewall(t *testing.T) {
+	target := firewallTarget{network: testNetwork, tags: []string{"gke-prod-node"}, serviceAccounts: []string{"nodes@p.iam.gserviceaccount.com"}}
+	rules := []*compute.Firewall{
+		{
+			Name: "allow-hc", Network: testNetwork, Direction: "INGRESS", Priority: 1000,
+			SourceRanges: []string{"35.191.0.0/16", "130.211.0.0/22"}, TargetTags: []string{"gke-prod-node"},
+			Allowed: []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"10256", "30000-32767"}}},
+		},
+		{
+			Name: "allow-web-other-nodes", Network: testNetwork, Direction: "INGRESS", Priority: 1000,
+			SourceRanges: []string{"0.0.0.0/0"}, TargetTags: []string{"other"},
+			Allowed: []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"80"}}},
+		},
+		{
+			Name: "deny-ssh", Network: testNetwork, Direction: "INGRESS", Priority: 900,
+			SourceRanges: []string{"0.0.0.0/0"},
+			Denied:       []*compute.FirewallDenied{{IPProtocol: "tcp", Ports: []string{"22"}}},
+		},
+		{
+			Name: "allow-all-by-sa", Network: testNetwork, Direction: "INGRESS", Priority: 1000,
+			SourceRanges: []string{"10.0.0.0/8"}, TargetServiceAccounts: []string{"nodes@p.iam.gserviceaccount.com"},
+			Allowed: []*compute.FirewallAllowed{{IPProtocol: "all"}},
+		},
+		{
+			Name: "other-network", Network: "https://www.googleapis.com/compute/v1/projects/host/global/networks/dev", Direction: "INGRESS",
+			SourceRanges: []string{"0.0.0.0/0"}, Allowed: []*compute.FirewallAllowed{{IPProtocol: "6"}},
+		},
+	}
+	flows := []firewallFlow{
+		{name: "health check", source: "35.191.0.0/16", protocol: "tcp", port: 10256},
+		{name: "client", source: "0.0.0.0/0", protocol: "tcp", port: 80},
+		{name: "client", source: "0.0.0.0/0", protocol: "tcp", port: 22},
+		{name: "client", source: "10.1.0.0/16", protocol: "udp", port: 53},
+		{name: "client", source: "10.1.0.0/16", protocol: "tcp", port: 22},
+	}
+	got := checkFirewall(flows, rules, target)
+	want := []firewallResult{
+		{flow: flows[0], allowed: true, detail: "allow-hc"},
+		{flow: flows[1], detail: "no rule allows it"},
+		{flow: flows[2], detail: "denied by deny-ssh (priority 900)"},
+		{flow: flows[3], allowed: true, detail: "allow-all-by-sa"},
+		{flow: flows[4], detail: "denied by deny-ssh (priority 900)"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(firewallResult{}, firewallFlow{})); diff != "" {
+		t.Errorf("checkFirewall() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNetworkProject(t *testing.T) {
+	if got := networkProject(testNetwork, "service"); got != "host" {
+		t.Errorf("networkProject() = %q, want %q", got, "host")
+	}
+	if got := networkProject("default", "service"); got != "service" {
+		t.Errorf("networkProject() = %q, want %q", got, "service")
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// GenerateManifestToolDescription contains the documentation for the Kubernetes Generate Manifest tool.
+// It is formatted in Markdown.
+const GenerateManifestToolDescription = `
+This tool generates the manifest of a standard resource from a few parameters, like *kubectl create ... --dry-run=client -o yaml*, without applying it. Use it instead of writing manifests by hand: the generated manifests are always valid. Review them, adjust them if needed, and apply them with kube_apply_resource.
+
+## Arguments
+
+* *kind*: The kind of resource to generate: *deployment*, *service* or *configmap*.
+* *name*: The name of the resource.
+* *namespace*: (Optional) The namespace of the resource. Defaults to the default namespace.
+* *image*: (deployment) The container image, e.g. *nginx:1.27*.
+* *replicas*: (Optional, deployment) The number of replicas. Defaults to 1.
+* *port*: (deployment, service) The port of the container, or of the service.
+* *deployment*: (service) The deployment exposed by the service, like *kubectl expose deployment*. Its pod selector is read from the cluster.
+* *target_port*: (Optional, service) The port of the pods. Defaults to the container port of the deployment, or to *port*.
+* *service_type*: (Optional, service) *ClusterIP* (the default), *NodePort* or *LoadBalancer*.
+* *data*: (configmap) The key-value pairs of the config map, like *kubectl create configmap --from-literal*.
+
+## Response Format
+
+The manifest, as a YAML document:
+
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: web
+  name: web
+  namespace: default
+spec:
+  ...
+`
+
+type generateManifestArgs struct {
+	Kind        string            `json:"kind"`
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Image       string            `json:"image,omitempty"`
+	Replicas    int               `json:"replicas,omitempty"`
+	Port        int               `json:"port,omitempty"`
+	Deployment  string            `json:"deployment,omitempty"`
+	TargetPort  int               `json:"target_port,omitempty"`
+	ServiceType string            `json:"service_type,omitempty"`
+	Data        map[string]string `json:"data,omitempty"`
+}
+
+// containerNameRe matches the characters not allowed in container names.
+var containerNameRe = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// containerName returns the name of the container running image, like
+// kubectl create deployment: the image name without registry and tag.
+func containerName(image string) string {
+	name := image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name, _, _ = strings.Cut(name, "@")
+	name, _, _ = strings.Cut(name, ":")
+	name = strings.Trim(containerNameRe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" {
+		return "main"
+	}
+	return name
+}
+
+// validPort returns an error if port isn't a valid port number.
+func validPort(arg string, port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("%s must be between 1 and 65535, got %d", arg, port)
+	}
+	return nil
+}
+
+// generateDeployment returns the deployment of args.
+func generateDeployment(args *generateManifestArgs, namespace string) (runtime.Object, error) {
+	if args.Image == "" {
+		return nil, fmt.Errorf("image is required to generate a deployment")
+	}
+	replicas := int32(1)
+	if args.Replicas > 0 {
+		replicas = int32(args.Replicas)
+	}
+	labels := map[string]string{"app": args.Name}
+	container := corev1.Container{Name: containerName(args.Image), Image: args.Image}
+	if args.Port != 0 {
+		if err := validPort("port", args.Port); err != nil {
+			return nil, err
+		}
+		container.Ports = []corev1.ContainerPort{{ContainerPort: int32(args.Port)}}
+	}
+	return &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: args.Name, Namespace: namespace, Labels: labels},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+			},
+		},
+	}, nil
+}
+
+// generateService returns the service of args exposing deployment.
+func generateService(args *generateManifestArgs, namespace string, deployment *appsv1.Deployment) (runtime.Object, error) {
+	if deployment.Spec.Selector == nil || len(deployment.Spec.Selector.MatchLabels) == 0 || len(deployment.Spec.Selector.MatchExpressions) > 0 {
+		return nil, fmt.Errorf("deployment %s has no selector that a service can use: services only select pods by labels", deployment.Name)
+	}
+	port, targetPort := args.Port, args.TargetPort
+	if targetPort == 0 {
+		for _, c := range deployment.Spec.Template.Spec.Containers {
+			if len(c.Ports) > 0 {
+				targetPort = int(c.Ports[0].ContainerPort)
+				break
+			}
+		}
+	}
+	if port == 0 {
+		port = targetPort
+	}
+	if targetPort == 0 {
+		targetPort = port
+	}
+	if port == 0 {
+		return nil, fmt.Errorf("port is required: deployment %s declares no container port", deployment.Name)
+	}
+	if err := validPort("port", port); err != nil {
+		return nil, err
+	}
+	if err := validPort("target_port", targetPort); err != nil {
+		return nil, err
+	}
+
+	serviceType := corev1.ServiceTypeClusterIP
+	switch corev1.ServiceType(args.ServiceType) {
+	case "", corev1.ServiceTypeClusterIP:
+	case corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+		serviceType = corev1.ServiceType(args.ServiceType)
+	default:
+		return nil, fmt.Errorf("invalid service_type %q, expected ClusterIP, NodePort or LoadBalancer", args.ServiceType)
+	}
+	return &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: args.Name, Namespace: namespace, Labels: deployment.Spec.Selector.MatchLabels},
+		Spec: corev1.ServiceSpec{
+			Type:     serviceType,
+			Selector: deployment.Spec.Selector.MatchLabels,
+			Ports: []corev1.ServicePort{{
+				Port:       int32(port),
+				TargetPort: intstr.FromInt32(int32(targetPort)),
+				Protocol:   corev1.ProtocolTCP,
+			}},
+		},
+	}, nil
+}
+
+// generateConfigMap returns the config map of args.
+func generateConfigMap(args *generateManifestArgs, namespace string) (runtime.Object, error) {
+	if len(args.Data) == 0 {
+		return nil, fmt.Errorf("data is required to generate a configmap")
+	}
+	for key := range args.Data {
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid configmap key %q: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: args.Name, Namespace: namespace},
+		Data:       args.Data,
+	}, nil
+}
+
+// generatedManifest returns obj as a manifest, without the empty status and
+// creation timestamp of typed objects.
+func generatedManifest(obj runtime.Object) (string, error) {
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert manifest: %w", err)
+	}
+	delete(m, "status")
+	unstructured.RemoveNestedField(m, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(m, "spec", "template", "metadata", "creationTimestamp")
+	var out strings.Builder
+	if err := writeYAMLDocument(&out, &unstructured.Unstructured{Object: m}); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func (h *handlers) generateManifest(ctx context.Context, _ *mcp.CallToolRequest, args *generateManifestArgs) (*mcp.CallToolResult, any, error) {
+	if errs := validation.IsDNS1123Subdomain(args.Name); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("invalid name %q: %s", args.Name, strings.Join(errs, "; "))
+	}
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+
+	var obj runtime.Object
+	var err error
+	switch strings.ToLower(args.Kind) {
+	case "deployment":
+		obj, err = generateDeployment(args, namespace)
+	case "service":
+		if args.Deployment == "" {
+			return nil, nil, fmt.Errorf("deployment is required to generate a service")
+		}
+		deployment, getErr := h.clientset.AppsV1().Deployments(namespace).Get(ctx, args.Deployment, metav1.GetOptions{})
+		if getErr != nil {
+			return nil, nil, fmt.Errorf("failed to get deployment %s: %w", args.Deployment, getErr)
+		}
+		obj, err = generateService(args, namespace, deployment)
+	case "configmap":
+		obj, err = generateConfigMap(args, namespace)
+	default:
+		return nil, nil, fmt.Errorf("invalid kind %q, expected deployment, service or configmap", args.Kind)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest, err := generatedManifest(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: manifest},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGenerateManifest(t *testing.T) {
+	web := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}}}}},
+		},
+	}
+	h := &handlers{clientset: fake.NewClientset(web), defaultNamespace: "default"}
+	for _, tc := range []struct {
+		name string
+		args generateManifestArgs
+		want string
+	}{
+		{
+			name: "deployment",
+			args: generateManifestArgs{Kind: "deployment", Name: "web", Image: "gcr.io/project/nginx:1.27", Replicas: 3, Port: 8080},
+			want: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: web
+  name: web
+  namespace: default
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+  strategy: {}
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - image: gcr.io/project/nginx:1.27
+        name: nginx
+        ports:
+        - containerPort: 8080
+        resources: {}
+`,
+		},
+		{
+			name: "service",
+			args: generateManifestArgs{Kind: "service", Name: "web", Deployment: "web", Port: 80, ServiceType: "LoadBalancer"},
+			want: `apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    app: web
+  name: web
+  namespace: default
+spec:
+  ports:
+  - port: 80
+    protocol: TCP
+    targetPort: 8080
+  selector:
+    app: web
+  type: LoadBalancer
+`,
+		},
+		{
+			name: "configmap",
+			args: generateManifestArgs{Kind: "ConfigMap", Name: "settings", Namespace: "prod", Data: map[string]string{"LOG_LEVEL": "debug"}},
+			want: `apiVersion: v1
+data:
+  LOG_LEVEL: debug
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: prod
+`,
+		},
+	} {
+		res, _, err := h.generateManifest(context.Background(), nil, &tc.args)
+		if err != nil {
+			t.Errorf("%s: generateManifest() returned error: %v", tc.name, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.want, res.Content[0].(*mcp.TextContent).Text); diff != "" {
+			t.Errorf("%s: generateManifest() mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+
+	for _, args := range []generateManifestArgs{
+		{Kind: "deployment", Name: "web"},
+		{Kind: "deployment", Name: "Web", Image: "nginx"},
+		{Kind: "service", Name: "api", Deployment: "missing", Port: 80},
+		{Kind: "configmap", Name: "settings", Data: map[string]string{"bad key": "x"}},
+		{Kind: "job", Name: "web"},
+	} {
+		if _, _, err := h.generateManifest(context.Background(), nil, &args); err == nil {
+			t.Errorf("generateManifest(%+v) expected error", args)
+		}
+	}
+}
+
+func TestContainerName(t *testing.T) {
+	for image, want := range map[string]string{
+		"nginx":                          "nginx",
+		"gcr.io/project/my_app:v1":       "my-app",
+		"registry:5000/team/api@sha256:": "api",
+	} {
+		if got := containerName(image); got != want {
+			t.Errorf("containerName(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/container/v1"
+)
+
+const (
+	defaultGKEMachineType = "e2-medium"
+	defaultGKENumNodes    = 3
+	// defaultGKEDiskSizeGB is the size of the boot disks of the nodes.
+	defaultGKEDiskSizeGB = 100
+	// regionalClusterZones is the number of zones of the nodes of regional
+	// clusters.
+	regionalClusterZones = 3
+	// maxGKECreatesPerHour bounds the creations of clusters and node pools,
+	// so that a looping agent can't provision much infrastructure.
+	maxGKECreatesPerHour = 5
+)
+
+const (
+	// clusterManagementFee is the hourly fee of GKE Standard clusters.
+	clusterManagementFee = 0.10
+	// diskPricePerGBMonth is the approximate price of balanced persistent
+	// disks in pricedRegion.
+	diskPricePerGBMonth = 0.10
+	// windowsLicensePerCPUHour is the price of the Windows Server licenses
+	// of the nodes of Windows node pools.
+	windowsLicensePerCPUHour = 0.046
+)
+
+// confidentialMachineFamilies are the machine families of Confidential GKE
+// Nodes: AMD SEV for N2D, C2D and C3D, and Intel TDX for C3.
+var confidentialMachineFamilies = []string{"n2d", "c2d", "c3d", "c3"}
+
+// rateLimiter allows at most n events per period.
+type rateLimiter struct {
+	n      int
+	period time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	events []time.Time
+}
+
+func newRateLimiter(n int, period time.Duration) *rateLimiter {
+	return &rateLimiter{n: n, period: period, now: time.Now}
+}
+
+// gkeCreates limits the rate of the creations of clusters and node pools of
+// the process. The handlers are created for every session and profile, so
+// that a limit of theirs would reset on each of them.
+var gkeCreates = newRateLimiter(maxGKECreatesPerHour, time.Hour)
+
+// allow records an event and returns true, or returns false and when the
+// next event is allowed if the limit is reached.
+func (l *rateLimiter) allow() (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	recent := l.events[:0]
+	for _, t := range l.events {
+		if now.Sub(t) < l.period {
+			recent = append(recent, t)
+		}
+	}
+	l.events = recent
+	if len(l.events) >= l.n {
+		return l.events[0].Add(l.period), false
+	}
+	l.events = append(l.events, now)
+	return time.Time{}, true
+}
+
+// allowGKECreate returns an error if the server created too many clusters and
+// node pools in the last hour.
+func (h *handlers) allowGKECreate() error {
+	if next, ok := h.gkeCreates.allow(); !ok {
+		return fmt.Errorf("the server already created %d clusters and node pools in the last hour, its limit: wait until %s, or ask the user to create them", maxGKECreatesPerHour, next.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// costLine is an item of a cost estimate.
+type costLine struct {
+	name string
+	// monthly is the estimated monthly cost, or -1 if unknown.
+	monthly float64
+}
+
+// costEstimate is the estimated monthly cost of a cluster or node pool.
+type costEstimate struct {
+	title string
+	// region is the region of the nodes: their prices are only known in
+	// pricedRegion.
+	region string
+	lines  []costLine
+}
+
+// known reports whether the prices of all the lines of e are known.
+func (e *costEstimate) known() bool {
+	for _, l := range e.lines {
+		if l.monthly < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats e, with its total.
+func (e *costEstimate) String() string {
+	var total float64
+	for _, l := range e.lines {
+		if l.monthly >= 0 {
+			total += l.monthly
+		}
+	}
+	var out strings.Builder
+	if e.known() {
+		out.WriteString(fmt.Sprintf("%s: ~$%.0f/month\n", e.title, total))
+	} else {
+		out.WriteString(fmt.Sprintf("%s: at least ~$%.0f/month, some prices are unknown\n", e.title, total))
+	}
+	for _, l := range e.lines {
+		if l.monthly < 0 {
+			out.WriteString(fmt.Sprintf("  %s: unknown price\n", l.name))
+		} else {
+			out.WriteString(fmt.Sprintf("  %s: ~$%.0f/month\n", l.name, l.monthly))
+		}
+	}
+	return out.String()
+}
+
+// addNodes adds the cost of nodesPerZone nodes of machineType, with cpus
+// vCPUs and memoryMB of memory, in zones zones of e.region to e.
+func (e *costEstimate) addNodes(machineType string, cpus, memoryMB, nodesPerZone int64, zones int) {
+	nodes := nodesPerZone * int64(zones)
+	name := fmt.Sprintf("Nodes: %d x %s", nodes, machineType)
+	if zones > 1 {
+		name += fmt.Sprintf(" (%d per zone in %d zones)", nodesPerZone, zones)
+	}
+	line := costLine{name: name, monthly: -1}
+	disks := costLine{name: fmt.Sprintf("Boot disks: %d x %d GB", nodes, defaultGKEDiskSizeGB), monthly: -1}
+	if e.region == pricedRegion {
+		if price, ok := hourlyPrice(machineFamily(machineType), cpus, memoryMB); ok {
+			line.monthly = price * float64(nodes) * hoursPerMonth
+		}
+		disks.monthly = float64(nodes*defaultGKEDiskSizeGB) * diskPricePerGBMonth
+	}
+	e.lines = append(e.lines, line, disks)
+}
+
+// addAccelerators adds the GPUs of nodes nodes, whose prices are unknown, to
+// e.
+func (e *costEstimate) addAccelerators(accelerators []*container.AcceleratorConfig, nodes int64) {
+	for _, a := range accelerators {
+		e.lines = append(e.lines, costLine{name: fmt.Sprintf("GPUs: %d x %d %s", nodes, a.AcceleratorCount, a.AcceleratorType), monthly: -1})
+	}
+}
+
+// addWindowsLicenses adds the cost of the Windows Server licenses of nodes
+// nodes with cpus vCPUs to e.
+func (e *costEstimate) addWindowsLicenses(cpus, nodes int64) {
+	e.lines = append(e.lines, costLine{
+		name:    fmt.Sprintf("Windows Server licenses: %d x %d vCPUs", nodes, cpus),
+		monthly: windowsLicensePerCPUHour * float64(cpus*nodes) * hoursPerMonth,
+	})
+}
+
+// setNodeSecurity sets the Confidential VM and Shielded VM settings of the
+// nodes of config, and checks that their machine type and image support
+// them. A nil integrityMonitoring keeps the default of GKE, enabled.
+func setNodeSecurity(config *container.NodeConfig, confidential, secureBoot bool, integrityMonitoring *bool) error {
+	if confidential {
+		if !slices.Contains(confidentialMachineFamilies, machineFamily(config.MachineType)) {
+			return fmt.Errorf("confidential nodes need a machine type of the %s families, not %s", strings.Join(confidentialMachineFamilies, ", "), config.MachineType)
+		}
+		if isWindowsImageType(config.ImageType) {
+			return fmt.Errorf("confidential nodes don't support %s images", config.ImageType)
+		}
+		config.ConfidentialNodes = &container.ConfidentialNodes{Enabled: true}
+	}
+	if secureBoot || integrityMonitoring != nil {
+		config.ShieldedInstanceConfig = &container.ShieldedInstanceConfig{
+			EnableSecureBoot:          secureBoot,
+			EnableIntegrityMonitoring: integrityMonitoring == nil || *integrityMonitoring,
+			// Disabled settings are sent, rather than omitted as defaults.
+			ForceSendFields: []string{"EnableSecureBoot", "EnableIntegrityMonitoring"},
+		}
+	}
+	return nil
+}
+
+// isZone reports whether location is a zone, e.g. us-central1-a, rather than
+// a region.
+func isZone(location string) bool {
+	return strings.Count(location, "-") == 2
+}
+
+// clusterZones returns the zones of the nodes of a new cluster in location.
+func (h *handlers) clusterZones(ctx context.Context, projectID, location string) ([]string, error) {
+	if isZone(location) {
+		return []string{location}, nil
+	}
+	region, err := h.computeService.Regions.Get(projectID, location).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get region %s: %w", location, err)
+	}
+	var zones []string
+	for _, zone := range region.Zones {
+		zones = append(zones, path.Base(zone))
+	}
+	if len(zones) == 0 {
+		return nil, fmt.Errorf("region %s has no zones", location)
+	}
+	return zones[:min(len(zones), regionalClusterZones)], nil
+}
+
+// estimateNodes adds the cost of nodesPerZone nodes of machineType in zones
+// to e, with their Windows Server licenses if windows is true.
+func (h *handlers) estimateNodes(ctx context.Context, e *costEstimate, projectID, machineType string, nodesPerZone int64, zones []string, windows bool) error {
+	mt, err := h.computeService.MachineTypes.Get(projectID, zones[0], machineType).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get machine type %s in zone %s: %w", machineType, zones[0], err)
+	}
+	e.region = zoneRegion(zones[0])
+	e.addNodes(machineType, mt.GuestCpus, mt.MemoryMb, nodesPerZone, len(zones))
+	if windows {
+		e.addWindowsLicenses(mt.GuestCpus, nodesPerZone*int64(len(zones)))
+	}
+	return nil
+}
+
+// newEstimateKey returns a random key for the estimate tokens.
+func newEstimateKey() []byte {
+	key := make([]byte, 32)
+	// Read never returns an error.
+	rand.Read(key)
+	return key
+}
+
+// estimateToken returns the token of the estimate of a creation with args,
+// whose estimate token must be empty: an HMAC of args with the key of the
+// server, so that a creation is only confirmed by the estimate of the same
+// arguments.
+func (h *handlers) estimateToken(args any) (string, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	mac := hmac.New(sha256.New, h.estimateKey)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// checkEstimateToken returns an error if token isn't the token of the
+// estimate of a creation with args, whose estimate token must be empty.
+func (h *handlers) checkEstimateToken(args any, token string) error {
+	want, err := h.estimateToken(args)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(token), []byte(want)) {
+		return fmt.Errorf("estimate_token isn't the token of an estimate of these arguments: call the tool without estimate_token, and show the new estimate to the user")
+	}
+	return nil
+}
+
+// costConfirmationResult returns the result of a creation with args, whose
+// estimate token must be empty, waiting for the user to accept the estimate
+// e. Estimates with unknown prices only have a token if allowUnknownPrice is
+// true.
+func (h *handlers) costConfirmationResult(e *costEstimate, what string, allowUnknownPrice bool, args any) (*mcp.CallToolResult, any, error) {
+	note := ""
+	if e.region != "" && e.region != pricedRegion {
+		note = fmt.Sprintf(" The server only knows the prices of %s, not those of %s.", pricedRegion, e.region)
+	}
+	text := e.String() + fmt.Sprintf("\nThe %s wasn't created. Prices are approximate on-demand list prices, without discounts.%s", what, note)
+	if !e.known() && !allowUnknownPrice {
+		text += " Some prices are unknown: show this estimate to the user, and only if they accept an unknown cost, call the tool again with allow_unknown_price set to true for a new estimate.\n"
+	} else {
+		token, err := h.estimateToken(args)
+		if err != nil {
+			return nil, nil, err
+		}
+		text += fmt.Sprintf(" Show this estimate to the user, and once they accept it, call the tool again with the same arguments and estimate_token set to %s.\n", token)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, nil, nil
+}
+
+// operationResult returns the result of a started operation of projectID in
+// location, described by what, e.g. "creating cluster web".
+func operationResult(op *container.Operation, projectID, location, what string) *mcp.CallToolResult {
+	name := fmt.Sprintf("projects/%s/locations/%s/operations/%s", projectID, location, op.Name)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Started %s: operation %s is %s. Follow it with gke_get_operation.\n", what, name, op.Status)},
+		},
+	}
+}
+
+func (h *handlers) gkeCreateCluster(ctx context.Context, _ *mcp.CallToolRequest, args *gkeCreateClusterArgs) (*mcp.CallToolResult, any, error) {
+	if args.ClusterName == "" && args.ClusterSpec == "" {
+		return nil, nil, fmt.Errorf("cluster_name is required")
+	}
+	projectID, location := args.ProjectID, args.Location
+	if projectID == "" {
+		projectID = h.c.DefaultProjectID()
+	}
+	if location == "" {
+		location = h.c.DefaultLocation()
+	}
+	if location == "" {
+		return nil, nil, fmt.Errorf("location is required: there is no default location")
+	}
+	if args.ClusterSpec != "" {
+		return h.gkeCreateClusterFromSpec(ctx, args, projectID, location)
+	}
+	machineType, numNodes := args.MachineType, args.NumNodes
+	if machineType == "" {
+		machineType = defaultGKEMachineType
+	}
+	if numNodes <= 0 {
+		numNodes = defaultGKENumNodes
+	}
+	if args.EnableConfidentialNodes && args.EnableShieldedNodes != nil && !*args.EnableShieldedNodes {
+		return nil, nil, fmt.Errorf("confidential nodes need shielded nodes: don't disable enable_shielded_nodes")
+	}
+	nodeConfig := &container.NodeConfig{MachineType: machineType, DiskSizeGb: defaultGKEDiskSizeGB}
+	if err := setNodeSecurity(nodeConfig, args.EnableConfidentialNodes, args.EnableSecureBoot, args.EnableIntegrityMonitoring); err != nil {
+		return nil, nil, err
+	}
+
+	estimated := *args
+	estimated.EstimateToken = ""
+	if args.EstimateToken == "" {
+		zones, err := h.clusterZones(ctx, projectID, location)
+		if err != nil {
+			return nil, nil, err
+		}
+		e := &costEstimate{
+			title: fmt.Sprintf("Estimated cost of cluster %s in %s", args.ClusterName, location),
+			lines: []costLine{{name: "Cluster management fee", monthly: clusterManagementFee * hoursPerMonth}},
+		}
+		if err := h.estimateNodes(ctx, e, projectID, machineType, numNodes, zones, false); err != nil {
+			return nil, nil, err
+		}
+		return h.costConfirmationResult(e, "cluster", args.AllowUnknownPrice, &estimated)
+	}
+	if err := h.checkEstimateToken(&estimated, args.EstimateToken); err != nil {
+		return nil, nil, err
+	}
+	if err := h.allowGKECreate(); err != nil {
+		return nil, nil, err
+	}
+
+	req := &container.CreateClusterRequest{
+		Cluster: &container.Cluster{
+			Name: args.ClusterName,
+			NodePools: []*container.NodePool{{
+				Name:             "default-pool",
+				InitialNodeCount: numNodes,
+				Config:           nodeConfig,
+			}},
+		},
+	}
+	if args.EnableShieldedNodes != nil {
+		req.Cluster.ShieldedNodes = &container.ShieldedNodes{Enabled: *args.EnableShieldedNodes, ForceSendFields: []string{"Enabled"}}
+	}
+	if args.EnableConfidentialNodes {
+		req.Cluster.ConfidentialNodes = &container.ConfidentialNodes{Enabled: true}
+	}
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
+	op, err := h.containerService.Projects.Locations.Clusters.Create(parent, req).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create cluster: %w", err)
+	}
+	return operationResult(op, projectID, location, "creating cluster "+args.ClusterName), nil, nil
+}
+
+func (h *handlers) gkeCreateNodePool(ctx context.Context, _ *mcp.CallToolRequest, args *gkeCreateNodePoolArgs) (*mcp.CallToolResult, any, error) {
+	if args.ClusterName == "" || args.NodePoolName == "" {
+		return nil, nil, fmt.Errorf("cluster_name and node_pool_name are required")
+	}
+	projectID, location := args.ProjectID, args.Location
+	if projectID == "" {
+		projectID = h.c.DefaultProjectID()
+	}
+	if location == "" {
+		location = h.c.DefaultLocation()
+	}
+	if location == "" {
+		return nil, nil, fmt.Errorf("location is required: there is no default location")
+	}
+	machineType, numNodes := args.MachineType, args.NumNodes
+	if machineType == "" {
+		machineType = defaultGKEMachineType
+	}
+	if numNodes <= 0 {
+		numNodes = defaultGKENumNodes
+	}
+	imageType, err := nodeImageType(args.ImageType)
+	if err != nil {
+		return nil, nil, err
+	}
+	taints, err := parseNodeTaints(args.Taints)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkNodeServiceAccount(args.ServiceAccount); err != nil {
+		return nil, nil, err
+	}
+	if err := checkNetworkTags(args.Tags); err != nil {
+		return nil, nil, err
+	}
+	windows := isWindowsImageType(imageType)
+
+	cluster, err := h.getCluster(ctx, projectID, location, args.ClusterName, windows)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkNotAutopilot(cluster); err != nil {
+		return nil, nil, err
+	}
+	if windows {
+		if err := checkWindowsNodePool(cluster, imageType, "", ""); err != nil {
+			return nil, nil, err
+		}
+	}
+	nodeConfig := &container.NodeConfig{
+		MachineType:    machineType,
+		DiskSizeGb:     defaultGKEDiskSizeGB,
+		ImageType:      imageType,
+		Labels:         args.Labels,
+		Taints:         taints,
+		ServiceAccount: args.ServiceAccount,
+		OauthScopes:    oauthScopes(args.OAuthScopes),
+		Tags:           args.Tags,
+		Metadata:       args.Metadata,
+	}
+	// The node pools of clusters with confidential nodes are confidential.
+	confidential := args.EnableConfidentialNodes || (cluster.ConfidentialNodes != nil && cluster.ConfidentialNodes.Enabled)
+	if err := setNodeSecurity(nodeConfig, confidential, args.EnableSecureBoot, args.EnableIntegrityMonitoring); err != nil {
+		return nil, nil, err
+	}
+	if args.EnableSandbox {
+		if err := setSandbox(nodeConfig); err != nil {
+			return nil, nil, err
+		}
+		// The system workloads don't run in GKE Sandbox.
+		if !slices.ContainsFunc(cluster.NodePools, func(np *container.NodePool) bool { return np.Config == nil || np.Config.SandboxConfig == nil }) {
+			return nil, nil, fmt.Errorf("cluster %s has no node pool without GKE Sandbox: create one for the system workloads first", args.ClusterName)
+		}
+	}
+
+	estimated := *args
+	estimated.EstimateToken = ""
+	if args.EstimateToken == "" {
+		zones := cluster.Locations
+		if len(zones) == 0 {
+			zones = []string{cluster.Location}
+		}
+		e := &costEstimate{title: fmt.Sprintf("Estimated cost of node pool %s in cluster %s", args.NodePoolName, args.ClusterName)}
+		if err := h.estimateNodes(ctx, e, projectID, machineType, numNodes, zones, windows); err != nil {
+			return nil, nil, err
+		}
+		return h.costConfirmationResult(e, "node pool", args.AllowUnknownPrice, &estimated)
+	}
+	if err := h.checkEstimateToken(&estimated, args.EstimateToken); err != nil {
+		return nil, nil, err
+	}
+	if err := h.allowGKECreate(); err != nil {
+		return nil, nil, err
+	}
+
+	req := &container.CreateNodePoolRequest{
+		NodePool: &container.NodePool{
+			Name:             args.NodePoolName,
+			InitialNodeCount: numNodes,
+			Config:           nodeConfig,
+		},
+	}
+	parent := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, args.ClusterName)
+	op, err := h.containerService.Projects.Locations.Clusters.NodePools.Create(parent, req).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create node pool: %w", err)
+	}
+	return operationResult(op, projectID, location, fmt.Sprintf("creating node pool %s in cluster %s", args.NodePoolName, args.ClusterName)), nil, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/container/v1"
+)
+
+func TestCostEstimate(t *testing.T) {
+	e := &costEstimate{
+		title:  "Estimated cost of cluster web in us-central1",
+		region: pricedRegion,
+		lines:  []costLine{{name: "Cluster management fee", monthly: clusterManagementFee * hoursPerMonth}},
+	}
+	e.addNodes("e2-medium", 2, 4096, 3, 3)
+	want := `Estimated cost of cluster web in us-central1: ~$526/month
+  Cluster management fee: ~$73/month
+  Nodes: 9 x e2-medium (3 per zone in 3 zones): ~$363/month
+  Boot disks: 9 x 100 GB: ~$90/month
+`
+	if diff := cmp.Diff(want, e.String()); diff != "" {
+		t.Errorf("String() mismatch (-want +got):\n%s", diff)
+	}
+
+	e = &costEstimate{title: "Estimated cost of node pool gpu in cluster web", region: pricedRegion}
+	e.addNodes("a2-highgpu-1g", 12, 87040, 2, 1)
+	want = `Estimated cost of node pool gpu in cluster web: at least ~$20/month, some prices are unknown
+  Nodes: 2 x a2-highgpu-1g: unknown price
+  Boot disks: 2 x 100 GB: ~$20/month
+`
+	if diff := cmp.Diff(want, e.String()); diff != "" {
+		t.Errorf("String() mismatch (-want +got):\n%s", diff)
+	}
+
+	e = &costEstimate{title: "Estimated cost of node pool web in cluster eu", region: "europe-west1"}
+	e.addNodes("e2-medium", 2, 4096, 1, 1)
+	e.addAccelerators([]*container.AcceleratorConfig{{AcceleratorType: "nvidia-tesla-t4", AcceleratorCount: 2}}, 1)
+	want = `Estimated cost of node pool web in cluster eu: at least ~$0/month, some prices are unknown
+  Nodes: 1 x e2-medium: unknown price
+  Boot disks: 1 x 100 GB: unknown price
+  GPUs: 1 x 2 nvidia-tesla-t4: unknown price
+`
+	if diff := cmp.Diff(want, e.String()); diff != "" {
+		t.Errorf("String() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCostConfirmation(t *testing.T) {
+	h := &handlers{estimateKey: newEstimateKey()}
+	args := &gkeCreateNodePoolArgs{ClusterName: "web", NodePoolName: "gpu", MachineType: "a2-highgpu-1g"}
+	unknown := &costEstimate{title: "Estimated cost of node pool gpu in cluster web", region: pricedRegion}
+	unknown.addNodes("a2-highgpu-1g", 12, 87040, 1, 1)
+
+	res, _, err := h.costConfirmationResult(unknown, "node pool", args.AllowUnknownPrice, args)
+	if err != nil {
+		t.Fatalf("costConfirmationResult() failed: %v", err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; strings.Contains(text, "estimate_token") || !strings.Contains(text, "allow_unknown_price") {
+		t.Errorf("costConfirmationResult() of unknown prices = %q, want no token", text)
+	}
+
+	args.AllowUnknownPrice = true
+	res, _, err = h.costConfirmationResult(unknown, "node pool", args.AllowUnknownPrice, args)
+	if err != nil {
+		t.Fatalf("costConfirmationResult() failed: %v", err)
+	}
+	token, err := h.estimateToken(args)
+	if err != nil {
+		t.Fatalf("estimateToken() failed: %v", err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "estimate_token set to "+token) {
+		t.Errorf("costConfirmationResult() with allow_unknown_price = %q, want token %s", text, token)
+	}
+	if err := h.checkEstimateToken(args, token); err != nil {
+		t.Errorf("checkEstimateToken() failed: %v", err)
+	}
+
+	changed := *args
+	changed.NumNodes = 10
+	if err := h.checkEstimateToken(&changed, token); err == nil {
+		t.Error("checkEstimateToken() of other arguments succeeded, want an error")
+	}
+	other := &handlers{estimateKey: newEstimateKey()}
+	if err := other.checkEstimateToken(args, token); err == nil {
+		t.Error("checkEstimateToken() with another key succeeded, want an error")
+	}
+	if err := h.checkEstimateToken(args, "true"); err == nil {
+		t.Error("checkEstimateToken() of a made up token succeeded, want an error")
+	}
+}
+
+func TestSetNodeSecurity(t *testing.T) {
+	disabled := false
+	for _, tc := range []struct {
+		name                string
+		config              *container.NodeConfig
+		confidential        bool
+		secureBoot          bool
+		integrityMonitoring *bool
+		want                *container.NodeConfig
+		wantErr             string
+	}{
+		{
+			name:   "defaults",
+			config: &container.NodeConfig{MachineType: "e2-medium"},
+			want:   &container.NodeConfig{MachineType: "e2-medium"},
+		},
+		{
+			name:         "confidential",
+			config:       &container.NodeConfig{MachineType: "n2d-standard-4"},
+			confidential: true,
+			secureBoot:   true,
+			want: &container.NodeConfig{
+				MachineType:       "n2d-standard-4",
+				ConfidentialNodes: &container.ConfidentialNodes{Enabled: true},
+				ShieldedInstanceConfig: &container.ShieldedInstanceConfig{
+					EnableSecureBoot:          true,
+					EnableIntegrityMonitoring: true,
+					ForceSendFields:           []string{"EnableSecureBoot", "EnableIntegrityMonitoring"},
+				},
+			},
+		},
+		{
+			name:                "integrity monitoring disabled",
+			config:              &container.NodeConfig{MachineType: "e2-medium"},
+			integrityMonitoring: &disabled,
+			want: &container.NodeConfig{
+				MachineType: "e2-medium",
+				ShieldedInstanceConfig: &container.ShieldedInstanceConfig{
+					ForceSendFields: []string{"EnableSecureBoot", "EnableIntegrityMonitoring"},
+				},
+			},
+		},
+		{
+			name:         "confidential machine type",
+			config:       &container.NodeConfig{MachineType: "e2-medium"},
+			confidential: true,
+			wantErr:      "need a machine type of the n2d, c2d, c3d, c3 families, not e2-medium",
+		},
+		{
+			name:         "confidential windows",
+			config:       &container.NodeConfig{MachineType: "n2d-standard-4", ImageType: "WINDOWS_LTSC_CONTAINERD"},
+			confidential: true,
+			wantErr:      "don't support WINDOWS_LTSC_CONTAINERD images",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := setNodeSecurity(tc.config, tc.confidential, tc.secureBoot, tc.integrityMonitoring)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("setNodeSecurity() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setNodeSecurity() failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, tc.config); diff != "" {
+				t.Errorf("setNodeSecurity() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsZone(t *testing.T) {
+	for location, want := range map[string]bool{"us-central1": false, "us-central1-a": true, "europe-west4-b": true, "asia-south2": false} {
+		if got := isZone(location); got != want {
+			t.Errorf("isZone(%q) = %t, want %t", location, got, want)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, time.Hour)
+	l.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		if _, ok := l.allow(); !ok {
+			t.Fatalf("allow() %d = false, want true", i)
+		}
+		now = now.Add(10 * time.Minute)
+	}
+	next, ok := l.allow()
+	if ok {
+		t.Fatal("allow() over the limit = true, want false")
+	}
+	if want := time.Date(2025, 3, 1, 11, 0, 0, 0, time.UTC); !next.Equal(want) {
+		t.Errorf("allow() next = %s, want %s", next, want)
+	}
+	now = now.Add(40 * time.Minute)
+	if _, ok := l.allow(); !ok {
+		t.Error("allow() after the period = false, want true")
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// maxResourceSuggestions is the number of closest resources suggested when a
+// resource kind isn't found.
+const maxResourceSuggestions = 3
+
+// matchGVR returns the resource of lists that resourceKind names: its kind,
+// plural or singular name, or a short name, compared case-insensitively.
+// resourceKind can be qualified with the group of the resource, e.g.
+// deployments.apps, or be a fully qualified group/version/resource, e.g.
+// networking.k8s.io/v1/ingresses. Exact matches take precedence over
+// case-insensitive ones.
+//
+// If resources of several groups match, the resource of the core group is
+// returned, like kubectl does. Otherwise the match is ambiguous, and the
+// error lists the candidates.
+func matchGVR(lists []*metav1.APIResourceList, resourceKind string) (schema.GroupVersionResource, error) {
+	if gvr, ok := parseQualifiedGVR(resourceKind); ok {
+		return qualifiedGVR(lists, gvr)
+	}
+	name, group, _ := strings.Cut(resourceKind, ".")
+	var exact, folded []schema.GroupVersionResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return schema.GroupVersionResource{}, fmt.Errorf("failed to parse group version %q: %w", list.GroupVersion, err)
+		}
+		if group != "" && !strings.EqualFold(gv.Group, group) {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				// Subresources, such as pods/log, aren't resource kinds.
+				continue
+			}
+			gvr := gv.WithResource(resource.Name)
+			for _, candidate := range resourceNames(resource) {
+				if candidate == name {
+					exact = appendGVR(exact, gvr)
+				} else if strings.EqualFold(candidate, name) {
+					folded = appendGVR(folded, gvr)
+				}
+			}
+		}
+	}
+	matches := exact
+	if len(matches) == 0 {
+		matches = folded
+	}
+	switch len(matches) {
+	case 0:
+		return schema.GroupVersionResource{}, errResourceNotFound
+	case 1:
+		return matches[0], nil
+	}
+	for _, gvr := range matches {
+		if gvr.Group == "" {
+			return gvr, nil
+		}
+	}
+	return schema.GroupVersionResource{}, ambiguousResourceError(resourceKind, matches)
+}
+
+// appendGVR appends gvr to list unless it's already in it.
+func appendGVR(list []schema.GroupVersionResource, gvr schema.GroupVersionResource) []schema.GroupVersionResource {
+	for _, g := range list {
+		if g == gvr {
+			return list
+		}
+	}
+	return append(list, gvr)
+}
+
+// ambiguousResourceError returns the error of a resource kind matching the
+// resources candidates of several groups.
+func ambiguousResourceError(resourceKind string, candidates []schema.GroupVersionResource) error {
+	var names []string
+	for _, gvr := range candidates {
+		names = append(names, gvr.GroupVersion().String()+"/"+gvr.Resource)
+	}
+	return fmt.Errorf("resource kind %q is ambiguous: it matches %s; pass one of them, or the resource qualified with its group, e.g. %s",
+		resourceKind, strings.Join(names, ", "), candidates[0].GroupResource().String())
+}
+
+// parseQualifiedGVR parses a fully qualified group/version/resource, or
+// version/resource for the core group, e.g. v1/pods.
+func parseQualifiedGVR(s string) (schema.GroupVersionResource, bool) {
+	parts := strings.Split(s, "/")
+	switch {
+	case len(parts) == 3 && parts[0] != "" && parts[1] != "" && parts[2] != "":
+		return schema.GroupVersionResource{Group: parts[0], Version: parts[1], Resource: parts[2]}, true
+	case len(parts) == 2 && isVersion(parts[0]) && parts[1] != "":
+		return schema.GroupVersionResource{Version: parts[0], Resource: parts[1]}, true
+	}
+	return schema.GroupVersionResource{}, false
+}
+
+// isVersion reports whether s is an API version, such as v1 or v2beta1.
+func isVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' || s[1] < '0' || s[1] > '9' {
+		return false
+	}
+	rest := strings.TrimLeft(s[1:], "0123456789")
+	for _, stage := range []string{"alpha", "beta"} {
+		if after, ok := strings.CutPrefix(rest, stage); ok {
+			return after != "" && strings.TrimLeft(after, "0123456789") == ""
+		}
+	}
+	return rest == ""
+}
+
+// qualifiedGVR returns gvr with its resource resolved to the plural name of
+// the resource of its group in lists, which can be named by its kind or
+// singular name. The version of gvr is kept, even if it's not the preferred
+// version of the group.
+func qualifiedGVR(lists []*metav1.APIResourceList, gvr schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return schema.GroupVersionResource{}, fmt.Errorf("failed to parse group version %q: %w", list.GroupVersion, err)
+		}
+		if gv.Group != gvr.Group {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			for _, candidate := range resourceNames(resource) {
+				if strings.EqualFold(candidate, gvr.Resource) {
+					gvr.Resource = resource.Name
+					return gvr, nil
+				}
+			}
+		}
+	}
+	// The resource may only exist in a version that isn't preferred.
+	gvr.Resource = strings.ToLower(gvr.Resource)
+	return gvr, nil
+}
+
+// resourceNames returns the names a resource can be referred to by.
+func resourceNames(resource metav1.APIResource) []string {
+	names := []string{resource.Kind, resource.Name}
+	if resource.SingularName != "" {
+		names = append(names, resource.SingularName)
+	}
+	return append(names, resource.ShortNames...)
+}
+
+// closestResources returns the resources of lists whose names are the
+// closest to resourceKind by edit distance, qualified with their group, at
+// most maxResourceSuggestions of them.
+func closestResources(lists []*metav1.APIResourceList, resourceKind string) []string {
+	name, _, _ := strings.Cut(strings.ToLower(resourceKind), ".")
+	// Names further than this are unrelated.
+	maxDistance := max(2, len(name)/3)
+
+	distances := map[string]int{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			qualified := schema.GroupResource{Group: gv.Group, Resource: resource.Name}.String()
+			for _, candidate := range resourceNames(resource) {
+				d := editDistance(name, strings.ToLower(candidate))
+				if d > maxDistance {
+					continue
+				}
+				if prev, ok := distances[qualified]; !ok || d < prev {
+					distances[qualified] = d
+				}
+			}
+		}
+	}
+
+	var suggestions []string
+	for qualified := range distances {
+		suggestions = append(suggestions, qualified)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if di, dj := distances[suggestions[i]], distances[suggestions[j]]; di != dj {
+			return di < dj
+		}
+		return suggestions[i] < suggestions[j]
+	})
+	if len(suggestions) > maxResourceSuggestions {
+		suggestions = suggestions[:maxResourceSuggestions]
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// resourceNotFoundError returns the error of a resource kind that isn't
+// found, suggesting the closest resources of lists.
+func resourceNotFoundError(lists []*metav1.APIResourceList, resourceKind string) error {
+	suggestions := closestResources(lists, resourceKind)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("resource kind %q not found; list the resource kinds of the cluster with kube_api_resources", resourceKind)
+	}
+	return fmt.Errorf("resource kind %q not found; did you mean %s?", resourceKind, strings.Join(suggestions, ", "))
+}
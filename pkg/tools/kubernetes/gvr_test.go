@@ -0,0 +1,155 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var testResourceLists = []*metav1.APIResourceList{
+	{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", SingularName: "pod", Kind: "Pod", ShortNames: []string{"po"}},
+			{Name: "pods/log", Kind: "Pod"},
+			{Name: "services", SingularName: "service", Kind: "Service", ShortNames: []string{"svc"}},
+		},
+	},
+	{
+		GroupVersion: "apps/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "deployments", SingularName: "deployment", Kind: "Deployment", ShortNames: []string{"deploy"}},
+			{Name: "daemonsets", SingularName: "daemonset", Kind: "DaemonSet", ShortNames: []string{"ds"}},
+		},
+	},
+	{
+		GroupVersion: "metrics.k8s.io/v1beta1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "PodMetrics"},
+		},
+	},
+	{
+		GroupVersion: "networking.k8s.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "ingresses", SingularName: "ingress", Kind: "Ingress", ShortNames: []string{"ing"}},
+		},
+	},
+	{
+		GroupVersion: "extensions/v1beta1",
+		APIResources: []metav1.APIResource{
+			{Name: "ingresses", SingularName: "ingress", Kind: "Ingress", ShortNames: []string{"ing"}},
+		},
+	},
+	{
+		GroupVersion: "cert-manager.io/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "certificates", SingularName: "certificate", Kind: "Certificate", ShortNames: []string{"cert"}},
+		},
+	},
+}
+
+func TestMatchGVR(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	for _, tc := range []struct {
+		kind    string
+		want    schema.GroupVersionResource
+		wantErr bool
+	}{
+		{kind: "deployments", want: deployments},
+		{kind: "Deployment", want: deployments},
+		{kind: "deployment", want: deployments},
+		{kind: "deploy", want: deployments},
+		{kind: "DEPLOYMENTS", want: deployments},
+		{kind: "Daemonset", want: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}},
+		{kind: "deployments.apps", want: deployments},
+		{kind: "certificates.cert-manager.io", want: schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}},
+		{kind: "pods", want: schema.GroupVersionResource{Version: "v1", Resource: "pods"}},
+		{kind: "pods.metrics.k8s.io", want: schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}},
+		{kind: "Ingress", wantErr: true},
+		{kind: "ingresses.networking.k8s.io", want: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}},
+		{kind: "networking.k8s.io/v1/Ingress", want: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}},
+		{kind: "apps/v1beta2/deployments", want: schema.GroupVersionResource{Group: "apps", Version: "v1beta2", Resource: "deployments"}},
+		{kind: "v1/pods", want: schema.GroupVersionResource{Version: "v1", Resource: "pods"}},
+		{kind: "deployments.batch", wantErr: true},
+		{kind: "pods/log", wantErr: true},
+		{kind: "deploymnt", wantErr: true},
+	} {
+		got, err := matchGVR(testResourceLists, tc.kind)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("matchGVR(%q) error = %v, want error %t", tc.kind, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("matchGVR(%q) = %v, want %v", tc.kind, got, tc.want)
+		}
+	}
+}
+
+func TestMatchGVRAmbiguous(t *testing.T) {
+	_, err := matchGVR(testResourceLists, "ing")
+	want := `resource kind "ing" is ambiguous: it matches networking.k8s.io/v1/ingresses, extensions/v1beta1/ingresses; pass one of them, or the resource qualified with its group, e.g. ingresses.networking.k8s.io`
+	if err == nil || err.Error() != want {
+		t.Errorf("matchGVR(ing) error = %v, want %q", err, want)
+	}
+}
+
+func TestIsVersion(t *testing.T) {
+	for s, want := range map[string]bool{"v1": true, "v2beta1": true, "v1alpha3": true, "v1beta": false, "pods": false, "v": false, "vx1": false} {
+		if got := isVersion(s); got != want {
+			t.Errorf("isVersion(%q) = %t, want %t", s, got, want)
+		}
+	}
+}
+
+func TestClosestResources(t *testing.T) {
+	for _, tc := range []struct {
+		kind string
+		want []string
+	}{
+		{kind: "deploymnt", want: []string{"deployments.apps"}},
+		{kind: "Servce", want: []string{"services"}},
+		{kind: "certificat.cert-manager.io", want: []string{"certificates.cert-manager.io"}},
+		{kind: "secret", want: nil},
+	} {
+		if diff := cmp.Diff(tc.want, closestResources(testResourceLists, tc.kind)); diff != "" {
+			t.Errorf("closestResources(%q) mismatch (-want +got):\n%s", tc.kind, diff)
+		}
+	}
+
+	want := `resource kind "deploymnt" not found; did you mean deployments.apps?`
+	if got := resourceNotFoundError(testResourceLists, "deploymnt").Error(); got != want {
+		t.Errorf("resourceNotFoundError() = %q, want %q", got, want)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"pod", "pods", 1},
+		{"deploymnt", "deployment", 1},
+		{"kitten", "sitting", 3},
+	} {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ClusterHealthToolDescription contains the documentation for the Cluster Health Kubernetes tool.
+// It is formatted in Markdown.
+const ClusterHealthToolDescription = `
+This tool produces a snapshot of the health of the whole cluster in a single call. It is the recommended first call when investigating any cluster issue, before following a troubleshooting playbook.
+
+The report is a JSON object with the following fields:
+
+* **healthy**: *true* if none of the checks below found a problem.
+* **nodes**: the number of nodes, how many are ready, not ready or cordoned, and the conditions of the nodes that are not ready.
+* **unhealthy_pods**: pods that are not running and ready, grouped by namespace, with the reason (e.g. *CrashLoopBackOff*, *ImagePullBackOff*, *Unschedulable*) and restart count. Completed pods are not reported.
+* **degraded_deployments**: deployments with fewer available or updated replicas than desired.
+* **pending_pvcs**: PersistentVolumeClaims that are not bound.
+* **failing_webhooks**: admission webhooks whose backing service does not exist or has no ready endpoints. Such webhooks can block the creation or update of resources across the cluster.
+* **warning_events**: the most recent *Warning* events within the *since* window.
+* **errors**: checks that could not be completed, for example because of missing permissions.
+
+## Arguments
+
+* *since*: (Optional) How far back to look for Warning events, as a duration such as *30m* or *2h*. Defaults to *1h*.
+* *max_events*: (Optional) The maximum number of Warning events to report. Defaults to 30.
+`
+
+type clusterHealthArgs struct {
+	Since     string `json:"since,omitempty"`
+	MaxEvents int    `json:"max_events,omitempty"`
+}
+
+const (
+	defaultHealthEventWindow = time.Hour
+	defaultHealthMaxEvents   = 30
+)
+
+type clusterHealthReport struct {
+	Healthy             bool                   `json:"healthy"`
+	Nodes               nodeHealth             `json:"nodes"`
+	UnhealthyPods       map[string][]podHealth `json:"unhealthy_pods"`
+	DegradedDeployments []deploymentHealth     `json:"degraded_deployments"`
+	PendingPVCs         []pvcHealth            `json:"pending_pvcs"`
+	FailingWebhooks     []webhookHealth        `json:"failing_webhooks"`
+	WarningEvents       []eventSummary         `json:"warning_events"`
+	Errors              []string               `json:"errors,omitempty"`
+}
+
+type nodeHealth struct {
+	Total         int             `json:"total"`
+	Ready         int             `json:"ready"`
+	NotReady      int             `json:"not_ready"`
+	Unschedulable int             `json:"unschedulable"`
+	NotReadyNodes []nodeCondition `json:"not_ready_nodes,omitempty"`
+}
+
+type nodeCondition struct {
+	Name       string   `json:"name"`
+	Conditions []string `json:"conditions"`
+}
+
+type podHealth struct {
+	Name     string `json:"name"`
+	Phase    string `json:"phase"`
+	Reason   string `json:"reason"`
+	Restarts int32  `json:"restarts"`
+	Node     string `json:"node,omitempty"`
+}
+
+type deploymentHealth struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Desired   int32  `json:"desired"`
+	Updated   int32  `json:"updated"`
+	Available int32  `json:"available"`
+}
+
+type pvcHealth struct {
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	Phase        string `json:"phase"`
+	StorageClass string `json:"storage_class,omitempty"`
+	Age          string `json:"age"`
+}
+
+type webhookHealth struct {
+	Configuration string `json:"configuration"`
+	Webhook       string `json:"webhook"`
+	Service       string `json:"service"`
+	FailurePolicy string `json:"failure_policy"`
+	Problem       string `json:"problem"`
+}
+
+type eventSummary struct {
+	LastSeen string `json:"last_seen"`
+	Object   string `json:"object"`
+	Reason   string `json:"reason"`
+	Message  string `json:"message"`
+	Count    int32  `json:"count,omitempty"`
+}
+
+func (h *handlers) clusterHealth(ctx context.Context, _ *mcp.CallToolRequest, args *clusterHealthArgs) (*mcp.CallToolResult, any, error) {
+	since := defaultHealthEventWindow
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		since = d
+	}
+	maxEvents := args.MaxEvents
+	if maxEvents <= 0 {
+		maxEvents = defaultHealthMaxEvents
+	}
+
+	report := h.clusterHealthReport(ctx, since, maxEvents)
+	b, err := json.Marshal(report)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal cluster health report: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(b)},
+		},
+	}, nil, nil
+}
+
+// clusterHealthReport runs all health checks. A check that fails is
+// recorded in the report rather than failing the whole report.
+func (h *handlers) clusterHealthReport(ctx context.Context, since time.Duration, maxEvents int) *clusterHealthReport {
+	report := &clusterHealthReport{
+		UnhealthyPods:       map[string][]podHealth{},
+		DegradedDeployments: []deploymentHealth{},
+		PendingPVCs:         []pvcHealth{},
+		FailingWebhooks:     []webhookHealth{},
+		WarningEvents:       []eventSummary{},
+	}
+	checks := []struct {
+		name  string
+		check func(context.Context, *clusterHealthReport) error
+	}{
+		{"nodes", h.checkNodes},
+		{"pods", h.checkPods},
+		{"deployments", h.checkDeployments},
+		{"persistentvolumeclaims", h.checkPVCs},
+		{"webhooks", h.checkWebhooks},
+		{"events", func(ctx context.Context, r *clusterHealthReport) error {
+			return h.checkWarningEvents(ctx, r, since, maxEvents)
+		}},
+	}
+	for _, c := range checks {
+		if err := c.check(ctx, report); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", c.name, err))
+		}
+	}
+	report.Healthy = len(report.Errors) == 0 &&
+		report.Nodes.NotReady == 0 &&
+		len(report.UnhealthyPods) == 0 &&
+		len(report.DegradedDeployments) == 0 &&
+		len(report.PendingPVCs) == 0 &&
+		len(report.FailingWebhooks) == 0
+	return report
+}
+
+func (h *handlers) checkNodes(ctx context.Context, r *clusterHealthReport) error {
+	nodes, err := h.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes.Items {
+		r.Nodes.Total++
+		if node.Spec.Unschedulable {
+			r.Nodes.Unschedulable++
+		}
+		ready := false
+		var problems []string
+		for _, c := range node.Status.Conditions {
+			switch {
+			case c.Type == corev1.NodeReady:
+				ready = c.Status == corev1.ConditionTrue
+				if !ready {
+					problems = append(problems, fmt.Sprintf("Ready=%s (%s: %s)", c.Status, c.Reason, c.Message))
+				}
+			case c.Status == corev1.ConditionTrue:
+				// All other standard conditions report a problem when true.
+				problems = append(problems, fmt.Sprintf("%s=True (%s)", c.Type, c.Reason))
+			}
+		}
+		if ready {
+			r.Nodes.Ready++
+			continue
+		}
+		r.Nodes.NotReady++
+		r.Nodes.NotReadyNodes = append(r.Nodes.NotReadyNodes, nodeCondition{Name: node.Name, Conditions: problems})
+	}
+	return nil
+}
+
+func (h *handlers) checkPods(ctx context.Context, r *clusterHealthReport) error {
+	pods, err := h.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded {
+			continue
+		}
+		reason := podNotReadyReason(pod)
+		if reason == "" {
+			continue
+		}
+		var restarts int32
+		for _, cs := range pod.Status.ContainerStatuses {
+			restarts += cs.RestartCount
+		}
+		r.UnhealthyPods[pod.Namespace] = append(r.UnhealthyPods[pod.Namespace], podHealth{
+			Name:     pod.Name,
+			Phase:    string(pod.Status.Phase),
+			Reason:   reason,
+			Restarts: restarts,
+			Node:     pod.Spec.NodeName,
+		})
+	}
+	return nil
+}
+
+func (h *handlers) checkDeployments(ctx context.Context, r *clusterHealthReport) error {
+	deployments, err := h.clientset.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, d := range deployments.Items {
+		desired := int32(1)
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+		if d.Status.AvailableReplicas >= desired && d.Status.UpdatedReplicas >= desired {
+			continue
+		}
+		r.DegradedDeployments = append(r.DegradedDeployments, deploymentHealth{
+			Namespace: d.Namespace,
+			Name:      d.Name,
+			Desired:   desired,
+			Updated:   d.Status.UpdatedReplicas,
+			Available: d.Status.AvailableReplicas,
+		})
+	}
+	return nil
+}
+
+func (h *handlers) checkPVCs(ctx context.Context, r *clusterHealthReport) error {
+	pvcs, err := h.clientset.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, pvc := range pvcs.Items {
+		if pvc.Status.Phase == corev1.ClaimBound {
+			continue
+		}
+		var storageClass string
+		if pvc.Spec.StorageClassName != nil {
+			storageClass = *pvc.Spec.StorageClassName
+		}
+		r.PendingPVCs = append(r.PendingPVCs, pvcHealth{
+			Namespace:    pvc.Namespace,
+			Name:         pvc.Name,
+			Phase:        string(pvc.Status.Phase),
+			StorageClass: storageClass,
+			Age:          time.Since(pvc.CreationTimestamp.Time).Round(time.Second).String(),
+		})
+	}
+	return nil
+}
+
+func (h *handlers) checkWebhooks(ctx context.Context, r *clusterHealthReport) error {
+	webhooks, err := h.listWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, w := range webhooks {
+		svc := w.clientConfig.Service
+		if svc == nil {
+			// Webhooks called by URL can't be checked from within the cluster.
+			continue
+		}
+		problem, err := h.serviceProblem(ctx, svc.Namespace, svc.Name)
+		if err != nil {
+			return err
+		}
+		if problem == "" {
+			continue
+		}
+		r.FailingWebhooks = append(r.FailingWebhooks, webhookHealth{
+			Configuration: w.kind + "/" + w.configuration,
+			Webhook:       w.name,
+			Service:       svc.Namespace + "/" + svc.Name,
+			FailurePolicy: string(w.failurePolicy),
+			Problem:       problem,
+		})
+	}
+	return nil
+}
+
+// serviceProblem describes why the service namespace/name can't serve
+// requests, or returns the empty string if it has ready endpoints.
+func (h *handlers) serviceProblem(ctx context.Context, namespace, name string) (string, error) {
+	if _, err := h.clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "service not found", nil
+		}
+		return "", err
+	}
+	slices, err := h.clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: name}).String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, slice := range slices.Items {
+		for _, ep := range slice.Endpoints {
+			if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+				return "", nil
+			}
+		}
+	}
+	return "service has no ready endpoints", nil
+}
+
+func (h *handlers) checkWarningEvents(ctx context.Context, r *clusterHealthReport, since time.Duration, maxEvents int) error {
+	events, err := h.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("type", corev1.EventTypeWarning).String(),
+	})
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-since)
+	var recent []corev1.Event
+	for _, e := range events.Items {
+		if e.Type == corev1.EventTypeWarning && eventTime(&e).After(cutoff) {
+			recent = append(recent, e)
+		}
+	}
+	// Most recent first.
+	sort.Slice(recent, func(i, j int) bool { return eventTime(&recent[i]).After(eventTime(&recent[j])) })
+	if len(recent) > maxEvents {
+		recent = recent[:maxEvents]
+	}
+	for i := range recent {
+		e := &recent[i]
+		object := e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name
+		if e.InvolvedObject.Namespace != "" {
+			object = e.InvolvedObject.Namespace + "/" + object
+		}
+		r.WarningEvents = append(r.WarningEvents, eventSummary{
+			LastSeen: eventTime(e).UTC().Format(time.RFC3339),
+			Object:   object,
+			Reason:   e.Reason,
+			Message:  e.Message,
+			Count:    e.Count,
+		})
+	}
+	return nil
+}
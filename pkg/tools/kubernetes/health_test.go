@@ -0,0 +1,128 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestClusterHealthReport(t *testing.T) {
+	clientset := fake.NewClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionUnknown, Reason: "NodeStatusUnknown", Message: "Kubelet stopped posting node status."},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasDiskPressure"},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{
+				RestartCount: 4,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "job-1", Namespace: "default"},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+			Status:     appsv1.DeploymentStatus{UpdatedReplicas: 2, AvailableReplicas: 1},
+		},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+				Name: "validate.policy.example.com",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{Namespace: "policy", Name: "webhook"},
+				},
+			}},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-1.1", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-1"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			LastTimestamp:  metav1.NewTime(time.Now().Add(-time.Minute)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web-1.2", Namespace: "default"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "web-1"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "Old",
+			LastTimestamp:  metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+		},
+	)
+	h := &handlers{clientset: clientset}
+
+	report := h.clusterHealthReport(context.Background(), time.Hour, 10)
+
+	if report.Healthy {
+		t.Errorf("report.Healthy = true, want false")
+	}
+	if len(report.Errors) > 0 {
+		t.Errorf("report.Errors = %v, want none", report.Errors)
+	}
+	wantNodes := nodeHealth{Total: 2, Ready: 1, NotReady: 1, NotReadyNodes: []nodeCondition{{
+		Name: "node-2",
+		Conditions: []string{
+			"Ready=Unknown (NodeStatusUnknown: Kubelet stopped posting node status.)",
+			"DiskPressure=True (KubeletHasDiskPressure)",
+		},
+	}}}
+	if diff := cmp.Diff(wantNodes, report.Nodes); diff != "" {
+		t.Errorf("report.Nodes mismatch (-want +got):\n%s", diff)
+	}
+	wantPods := map[string][]podHealth{"default": {{Name: "web-1", Phase: "Running", Reason: "CrashLoopBackOff", Restarts: 4}}}
+	if diff := cmp.Diff(wantPods, report.UnhealthyPods); diff != "" {
+		t.Errorf("report.UnhealthyPods mismatch (-want +got):\n%s", diff)
+	}
+	wantDeployments := []deploymentHealth{{Namespace: "default", Name: "web", Desired: 2, Updated: 2, Available: 1}}
+	if diff := cmp.Diff(wantDeployments, report.DegradedDeployments); diff != "" {
+		t.Errorf("report.DegradedDeployments mismatch (-want +got):\n%s", diff)
+	}
+	wantWebhooks := []webhookHealth{{
+		Configuration: "validating/policy",
+		Webhook:       "validate.policy.example.com",
+		Service:       "policy/webhook",
+		FailurePolicy: "Fail",
+		Problem:       "service not found",
+	}}
+	if diff := cmp.Diff(wantWebhooks, report.FailingWebhooks); diff != "" {
+		t.Errorf("report.FailingWebhooks mismatch (-want +got):\n%s", diff)
+	}
+	if len(report.WarningEvents) != 1 || report.WarningEvents[0].Reason != "BackOff" {
+		t.Errorf("report.WarningEvents = %+v, want only the recent BackOff event", report.WarningEvents)
+	}
+}
@@ -0,0 +1,367 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// HealthCheckFindingsToolDescription contains the documentation for the Kubernetes Health Check Findings tool.
+// It is formatted in Markdown.
+const HealthCheckFindingsToolDescription = `
+This tool returns the current findings of the background health checks, which the server runs periodically, as set with *--health-check-interval* and *--health-checks*:
+
+* *cluster_health*: not ready nodes, unhealthy pods, degraded deployments, pending persistent volume claims and webhooks without ready endpoints, like *kube_cluster_health*.
+* *cert_expiry*: certificates expired or expiring within 30 days, like *kube_cert_expiry_scan*.
+* *quota_usage*: Compute Engine quotas of the default project and region used at 80% or more, like *gcp_check_quotas*.
+
+Clients that set a logging level also receive each new finding as a log message as soon as it is found, and a message when it is resolved, so the findings don't need to be polled.
+
+## Arguments
+
+* *check*: (Optional) Only return the findings of this check, e.g. *cert_expiry*.
+
+## Response Format
+
+The time of the last run of the checks, and the findings, in order of severity:
+
+Last run: 2025-03-01T10:15:00Z (every 5m0s).
+
+SEVERITY  CHECK           SINCE                 FINDING
+error     cluster_health  2025-03-01T09:40:00Z  Node gke-prod-pool-1-abcd is not ready: Ready=Unknown
+warning   cert_expiry     2025-03-01T08:00:00Z  Secret shop/web-tls tls.crt expires in 12 days (shop.example.com)
+`
+
+type healthCheckFindingsArgs struct {
+	Check string `json:"check,omitempty"`
+}
+
+const (
+	// healthChecksLogger is the logger name of the log messages sent to
+	// clients for the findings of the background health checks.
+	healthChecksLogger = "health-checks"
+	// healthCheckCertDays is the number of days before the expiry of
+	// certificates from which they are reported.
+	healthCheckCertDays = 30
+)
+
+// healthFinding is a problem found by a background health check.
+type healthFinding struct {
+	check string
+	// key identifies the finding across runs, e.g. "node/gke-prod-pool-1-abcd".
+	key      string
+	severity mcp.LoggingLevel
+	summary  string
+	// since is when the finding was first found.
+	since time.Time
+}
+
+// severityRank orders the severities of findings, most severe first.
+var severityRank = map[mcp.LoggingLevel]int{"error": 0, "warning": 1, "notice": 2}
+
+// logParams returns the log message sent to clients for f, or for its
+// resolution.
+func (f healthFinding) logParams(resolved bool) *mcp.LoggingMessageParams {
+	level, status := f.severity, "found"
+	if resolved {
+		level, status = "notice", "resolved"
+	}
+	return &mcp.LoggingMessageParams{Logger: healthChecksLogger, Level: level, Data: map[string]any{
+		"check":   f.check,
+		"status":  status,
+		"since":   f.since.UTC().Format(time.RFC3339),
+		"summary": f.summary,
+	}}
+}
+
+// healthCheckFunc runs a health check and returns its findings.
+type healthCheckFunc func(ctx context.Context) ([]healthFinding, error)
+
+// backgroundHealthChecks runs health checks periodically and keeps their
+// current findings.
+type backgroundHealthChecks struct {
+	interval time.Duration
+	checks   []string
+
+	mu      sync.Mutex
+	lastRun time.Time
+	// findings are the current findings of each check, by key.
+	findings map[string]map[string]healthFinding
+}
+
+func newBackgroundHealthChecks(interval time.Duration, checks []string) *backgroundHealthChecks {
+	return &backgroundHealthChecks{interval: interval, checks: checks, findings: map[string]map[string]healthFinding{}}
+}
+
+// update replaces the findings of check with found, and returns the new and
+// the resolved findings. Findings that were already found keep their time.
+func (b *backgroundHealthChecks) update(check string, found []healthFinding, now time.Time) (added, resolved []healthFinding) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	previous := b.findings[check]
+	current := map[string]healthFinding{}
+	for _, f := range found {
+		f.check = check
+		if p, ok := previous[f.key]; ok {
+			f.since = p.since
+		} else {
+			f.since = now
+			added = append(added, f)
+		}
+		current[f.key] = f
+	}
+	for key, p := range previous {
+		if _, ok := current[key]; !ok {
+			resolved = append(resolved, p)
+		}
+	}
+	b.findings[check] = current
+	return added, resolved
+}
+
+// list returns the current findings of check, or of all checks if check is
+// empty, by severity then time.
+func (b *backgroundHealthChecks) list(check string) []healthFinding {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var list []healthFinding
+	for _, c := range b.checks {
+		if check != "" && c != check {
+			continue
+		}
+		for _, f := range b.findings[c] {
+			list = append(list, f)
+		}
+	}
+	sortFindings(list)
+	return list
+}
+
+// sortFindings sorts findings by severity, then time, then summary.
+func sortFindings(findings []healthFinding) {
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if ra, rb := severityRank[a.severity], severityRank[b.severity]; ra != rb {
+			return ra < rb
+		}
+		if !a.since.Equal(b.since) {
+			return a.since.Before(b.since)
+		}
+		return a.summary < b.summary
+	})
+}
+
+// runOnce runs the checks once, and sends their new and resolved findings
+// to the clients connected to s.
+func (b *backgroundHealthChecks) runOnce(ctx context.Context, checks map[string]healthCheckFunc, s *mcp.Server) {
+	for _, name := range b.checks {
+		check, ok := checks[name]
+		if !ok {
+			continue
+		}
+		found, err := check(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("Background health check failed", "check", name, "error", err)
+			}
+			continue
+		}
+		added, resolved := b.update(name, found, time.Now())
+		for ss := range s.Sessions() {
+			for _, f := range added {
+				if err := ss.Log(ctx, f.logParams(false)); err != nil {
+					slog.Debug("Failed to send health check finding to client", "error", err)
+				}
+			}
+			for _, f := range resolved {
+				if err := ss.Log(ctx, f.logParams(true)); err != nil {
+					slog.Debug("Failed to send health check finding to client", "error", err)
+				}
+			}
+		}
+	}
+	b.mu.Lock()
+	b.lastRun = time.Now()
+	b.mu.Unlock()
+}
+
+// run runs the checks every interval until ctx is done.
+func (b *backgroundHealthChecks) run(ctx context.Context, checks map[string]healthCheckFunc, s *mcp.Server) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		b.runOnce(ctx, checks, s)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// healthCheckFuncs returns the implementations of the background health
+// checks.
+func (h *handlers) healthCheckFuncs() map[string]healthCheckFunc {
+	return map[string]healthCheckFunc{
+		config.HealthCheckCluster:    h.clusterHealthFindings,
+		config.HealthCheckCertExpiry: h.certExpiryFindings,
+		config.HealthCheckQuotaUsage: h.quotaUsageFindings,
+	}
+}
+
+// clusterHealthFindings returns the problems of the cluster health report.
+func (h *handlers) clusterHealthFindings(ctx context.Context) ([]healthFinding, error) {
+	return healthReportFindings(h.clusterHealthReport(ctx, defaultHealthEventWindow, defaultHealthMaxEvents)), nil
+}
+
+// healthReportFindings returns the problems of r. Warning events aren't
+// reported: they are too frequent to be pushed to clients.
+func healthReportFindings(r *clusterHealthReport) []healthFinding {
+	var findings []healthFinding
+	for _, n := range r.Nodes.NotReadyNodes {
+		findings = append(findings, healthFinding{key: "node/" + n.Name, severity: "error",
+			summary: fmt.Sprintf("Node %s is not ready: %s", n.Name, strings.Join(n.Conditions, ", "))})
+	}
+	for ns, pods := range r.UnhealthyPods {
+		for _, p := range pods {
+			findings = append(findings, healthFinding{key: "pod/" + ns + "/" + p.Name, severity: "warning",
+				summary: fmt.Sprintf("Pod %s/%s is unhealthy: %s (%d restarts)", ns, p.Name, p.Reason, p.Restarts)})
+		}
+	}
+	for _, d := range r.DegradedDeployments {
+		findings = append(findings, healthFinding{key: "deployment/" + d.Namespace + "/" + d.Name, severity: "warning",
+			summary: fmt.Sprintf("Deployment %s/%s has %d of %d replicas available", d.Namespace, d.Name, d.Available, d.Desired)})
+	}
+	for _, p := range r.PendingPVCs {
+		findings = append(findings, healthFinding{key: "pvc/" + p.Namespace + "/" + p.Name, severity: "warning",
+			summary: fmt.Sprintf("PersistentVolumeClaim %s/%s is %s", p.Namespace, p.Name, p.Phase)})
+	}
+	for _, w := range r.FailingWebhooks {
+		severity := mcp.LoggingLevel("warning")
+		if w.FailurePolicy == "Fail" {
+			// Failing webhooks with a Fail policy block the API requests they intercept.
+			severity = "error"
+		}
+		findings = append(findings, healthFinding{key: "webhook/" + w.Configuration + "/" + w.Webhook, severity: severity,
+			summary: fmt.Sprintf("Webhook %s of %s (failure policy %s) can't be called: %s", w.Webhook, w.Configuration, w.FailurePolicy, w.Problem)})
+	}
+	for _, e := range r.Errors {
+		check, _, _ := strings.Cut(e, ":")
+		findings = append(findings, healthFinding{key: "error/" + check, severity: "warning",
+			summary: "Health check of " + e})
+	}
+	return findings
+}
+
+// certExpiryFindings returns the certificates of the cluster that are
+// expired or expire within healthCheckCertDays.
+func (h *handlers) certExpiryFindings(ctx context.Context) ([]healthFinding, error) {
+	sources, err := h.certSources(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	certs, _ := parseCertSources(sources)
+	return certFindings(certs, time.Now(), healthCheckCertDays), nil
+}
+
+// certFindings returns the certificates of certs that are expired at now or
+// expire within days.
+func certFindings(certs []scannedCert, now time.Time, days int) []healthFinding {
+	deadline := now.Add(time.Duration(days) * 24 * time.Hour)
+	var findings []healthFinding
+	for _, c := range certs {
+		name := certName(c.cert.Subject, c.cert.Subject.CommonName)
+		key := fmt.Sprintf("cert/%s/%s", c.source, c.cert.SerialNumber)
+		switch {
+		case now.After(c.cert.NotAfter):
+			findings = append(findings, healthFinding{key: key, severity: "error",
+				summary: fmt.Sprintf("%s expired on %s (%s)", c.source, c.cert.NotAfter.UTC().Format(time.DateOnly), name)})
+		case deadline.After(c.cert.NotAfter):
+			daysLeft := int(c.cert.NotAfter.Sub(now).Hours() / 24)
+			findings = append(findings, healthFinding{key: key, severity: "warning",
+				summary: fmt.Sprintf("%s expires in %d days (%s)", c.source, daysLeft, name)})
+		}
+	}
+	return findings
+}
+
+// quotaUsageFindings returns the Compute Engine quotas of the default
+// project and region that are close to or over their limit.
+func (h *handlers) quotaUsageFindings(ctx context.Context) ([]healthFinding, error) {
+	project, location := h.c.DefaultProjectID(), h.c.DefaultLocation()
+	if project == "" || location == "" {
+		return nil, fmt.Errorf("the default project and location are required, set them with gcloud config set")
+	}
+	region, err := h.computeService.Regions.Get(project, regionOf(location)).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get region: %w", err)
+	}
+	var findings []healthFinding
+	for _, c := range checkQuotas(region.Quotas, nil) {
+		var severity mcp.LoggingLevel
+		switch c.status() {
+		case "EXCEEDED":
+			severity = "error"
+		case "WARNING":
+			severity = "warning"
+		default:
+			continue
+		}
+		findings = append(findings, healthFinding{key: "quota/" + region.Name + "/" + c.metric, severity: severity,
+			summary: fmt.Sprintf("Quota %s of %s in %s is %g of %g (%.0f%%)", c.metric, project, region.Name, c.usage, c.limit, 100*c.usage/c.limit)})
+	}
+	return findings, nil
+}
+
+func (h *handlers) healthCheckFindings(ctx context.Context, _ *mcp.CallToolRequest, args *healthCheckFindingsArgs) (*mcp.CallToolResult, any, error) {
+	h.healthChecks.mu.Lock()
+	lastRun := h.healthChecks.lastRun
+	h.healthChecks.mu.Unlock()
+
+	var output strings.Builder
+	if lastRun.IsZero() {
+		output.WriteString(fmt.Sprintf("The health checks haven't completed a run yet (every %s).\n", h.healthChecks.interval))
+	} else {
+		output.WriteString(fmt.Sprintf("Last run: %s (every %s).\n", lastRun.UTC().Format(time.RFC3339), h.healthChecks.interval))
+	}
+	findings := h.healthChecks.list(args.Check)
+	if len(findings) == 0 {
+		output.WriteString("\nNo findings.\n")
+	} else {
+		output.WriteString("\n")
+		w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SEVERITY\tCHECK\tSINCE\tFINDING")
+		for _, f := range findings {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.severity, f.check, f.since.UTC().Format(time.RFC3339), f.summary)
+		}
+		w.Flush()
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
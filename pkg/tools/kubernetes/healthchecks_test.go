@@ -0,0 +1,118 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// findingKeys returns the keys of findings.
+func findingKeys(findings []healthFinding) []string {
+	var keys []string
+	for _, f := range findings {
+		keys = append(keys, f.key)
+	}
+	return keys
+}
+
+func TestBackgroundHealthChecksUpdate(t *testing.T) {
+	b := newBackgroundHealthChecks(time.Minute, []string{"cluster_health", "cert_expiry"})
+	first := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(time.Minute)
+
+	added, resolved := b.update("cluster_health", []healthFinding{
+		{key: "node/a", severity: "error", summary: "Node a is not ready"},
+		{key: "pod/shop/web", severity: "warning", summary: "Pod shop/web is unhealthy"},
+	}, first)
+	if diff := cmp.Diff([]string{"node/a", "pod/shop/web"}, findingKeys(added)); diff != "" {
+		t.Errorf("first update() added mismatch (-want +got):\n%s", diff)
+	}
+	if len(resolved) != 0 {
+		t.Errorf("first update() resolved = %v, want none", findingKeys(resolved))
+	}
+
+	added, resolved = b.update("cluster_health", []healthFinding{
+		{key: "pod/shop/web", severity: "warning", summary: "Pod shop/web is unhealthy (3 restarts)"},
+		{key: "pvc/shop/data", severity: "warning", summary: "PersistentVolumeClaim shop/data is Pending"},
+	}, second)
+	if diff := cmp.Diff([]string{"pvc/shop/data"}, findingKeys(added)); diff != "" {
+		t.Errorf("second update() added mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"node/a"}, findingKeys(resolved)); diff != "" {
+		t.Errorf("second update() resolved mismatch (-want +got):\n%s", diff)
+	}
+	b.update("cert_expiry", []healthFinding{{key: "cert/x", severity: "error", summary: "expired"}}, second)
+
+	// Findings are listed by severity, then by the time they were first
+	// found, with their latest summary.
+	list := b.list("")
+	if diff := cmp.Diff([]string{"cert/x", "pod/shop/web", "pvc/shop/data"}, findingKeys(list)); diff != "" {
+		t.Errorf("list() mismatch (-want +got):\n%s", diff)
+	}
+	if got := list[1]; !got.since.Equal(first) || got.summary != "Pod shop/web is unhealthy (3 restarts)" || got.check != "cluster_health" {
+		t.Errorf("list()[1] = %+v, want the latest summary since %s", got, first)
+	}
+	if diff := cmp.Diff([]string{"cert/x"}, findingKeys(b.list("cert_expiry"))); diff != "" {
+		t.Errorf("list(cert_expiry) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHealthReportFindings(t *testing.T) {
+	report := &clusterHealthReport{
+		Nodes:               nodeHealth{NotReadyNodes: []nodeCondition{{Name: "node-1", Conditions: []string{"Ready=Unknown"}}}},
+		UnhealthyPods:       map[string][]podHealth{"shop": {{Name: "web-1", Reason: "CrashLoopBackOff", Restarts: 7}}},
+		DegradedDeployments: []deploymentHealth{{Namespace: "shop", Name: "web", Desired: 3, Available: 1}},
+		FailingWebhooks:     []webhookHealth{{Configuration: "validating/policy", Webhook: "validate.example.com", FailurePolicy: "Fail", Problem: "no ready endpoints"}},
+		WarningEvents:       []eventSummary{{Reason: "BackOff"}},
+		Errors:              []string{"persistentvolumeclaims: forbidden"},
+	}
+	got := map[string]string{}
+	for _, f := range healthReportFindings(report) {
+		got[f.key] = string(f.severity) + ": " + f.summary
+	}
+	want := map[string]string{
+		"node/node-1":         "error: Node node-1 is not ready: Ready=Unknown",
+		"pod/shop/web-1":      "warning: Pod shop/web-1 is unhealthy: CrashLoopBackOff (7 restarts)",
+		"deployment/shop/web": "warning: Deployment shop/web has 1 of 3 replicas available",
+		"webhook/validating/policy/validate.example.com": "error: Webhook validate.example.com of validating/policy (failure policy Fail) can't be called: no ready endpoints",
+		"error/persistentvolumeclaims":                   "warning: Health check of persistentvolumeclaims: forbidden",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("healthReportFindings() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCertFindings(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	certs, _ := parseCertSources([]certSource{
+		{name: "Secret shop/web-tls tls.crt", pem: newCACert(t, now.AddDate(-1, 0, 0), now.AddDate(0, 0, 12))},
+		{name: "APIService v1beta1.metrics.k8s.io", pem: newCACert(t, now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0))},
+		{name: "ValidatingWebhook policy/validate.example.com", pem: newCACert(t, now.AddDate(-1, 0, 0), now.AddDate(0, 0, -1))},
+	})
+	var got []string
+	for _, f := range certFindings(certs, now, 30) {
+		got = append(got, string(f.severity)+": "+f.summary)
+	}
+	want := []string{
+		"error: ValidatingWebhook policy/validate.example.com expired on 2025-05-31 (webhook-ca)",
+		"warning: Secret shop/web-tls tls.crt expires in 12 days (webhook-ca)",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("certFindings() mismatch (-want +got):\n%s", diff)
+	}
+}
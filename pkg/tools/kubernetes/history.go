@@ -0,0 +1,315 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// RolloutHistoryToolDescription contains the documentation for the Rollout History Kubernetes tool.
+// It is formatted in Markdown.
+const RolloutHistoryToolDescription = `
+This tool shows the revision history of a Deployment, StatefulSet or DaemonSet, with the changes of the pod template between consecutive revisions. Use it to answer "what changed right before it broke": compare the creation time of the revisions with the time the problems started, and look at the changes of the revision that introduced them.
+
+The revisions of Deployments are their ReplicaSets, and the revisions of StatefulSets and DaemonSets their ControllerRevisions. Only the revisions kept by the workload's *revisionHistoryLimit* are available.
+
+## Arguments
+
+* *kind*: The kind of workload: *deployment*, *statefulset* or *daemonset*.
+* *name*: The name of the workload.
+* *namespace*: (Optional) The namespace of the workload. Defaults to the server's default namespace.
+* *revision*: (Optional) A revision number: only the changes of this revision from the previous one are shown.
+* *max_revisions*: (Optional) The number of most recent revisions shown. Defaults to 10.
+
+## Response Format
+
+The revisions, the current one marked with *, followed by the changes of the pod template made by each revision:
+
+deployment/web in default: 3 revisions.
+
+REVISION  CREATED               REPLICAS  IMAGES      CHANGE-CAUSE
+1         2025-06-01T10:00:00Z  0         web:v1
+2         2025-06-02T10:00:00Z  0         web:v2
+3*        2025-06-03T10:00:00Z  3         web:v2      kubectl set env deployment/web LOG_LEVEL=debug
+
+### Revision 2 -> 3
+  spec:
+    containers:
+    - env:
++     - name: LOG_LEVEL
++       value: debug
+`
+
+type rolloutHistoryArgs struct {
+	Kind         string `json:"kind"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace,omitempty"`
+	Revision     int64  `json:"revision,omitempty"`
+	MaxRevisions int    `json:"max_revisions,omitempty"`
+}
+
+const (
+	defaultMaxRevisions = 10
+	// changeCauseAnnotation records the command that made a revision.
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+	// deploymentRevisionAnnotation holds the revision of the ReplicaSets of
+	// Deployments.
+	deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+)
+
+// workloadRevision is a revision of a workload.
+type workloadRevision struct {
+	revision    int64
+	created     time.Time
+	changeCause string
+	// replicas is the number of pods of the revision, or -1 if unknown.
+	replicas int32
+	template map[string]any
+}
+
+// images returns the images of the containers of the revision.
+func (r workloadRevision) images() []string {
+	var images []string
+	for _, key := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(r.template, "spec", key)
+		forEachMap(containers, func(container map[string]any) {
+			if image, ok := container["image"].(string); ok {
+				images = append(images, image)
+			}
+		})
+	}
+	return images
+}
+
+// ownedBy reports whether obj is controlled by the object with uid.
+func ownedBy(obj metav1.Object, uid types.UID) bool {
+	ref := metav1.GetControllerOf(obj)
+	return ref != nil && ref.UID == uid
+}
+
+// deploymentRevisions returns the revisions of the Deployment d from its
+// ReplicaSets rss, sorted by revision.
+func deploymentRevisions(d *appsv1.Deployment, rss []appsv1.ReplicaSet) ([]workloadRevision, error) {
+	var revisions []workloadRevision
+	for i := range rss {
+		rs := &rss[i]
+		if !ownedBy(rs, d.UID) {
+			continue
+		}
+		revision, err := strconv.ParseInt(rs.Annotations[deploymentRevisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		template, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&rs.Spec.Template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert the pod template of replicaset %s: %w", rs.Name, err)
+		}
+		// The hash label is added by the Deployment controller to tell its
+		// ReplicaSets apart: it changes with every revision.
+		unstructured.RemoveNestedField(template, "metadata", "labels", appsv1.DefaultDeploymentUniqueLabelKey)
+		unstructured.RemoveNestedField(template, "metadata", "creationTimestamp")
+		revisions = append(revisions, workloadRevision{
+			revision:    revision,
+			created:     rs.CreationTimestamp.Time,
+			changeCause: rs.Annotations[changeCauseAnnotation],
+			replicas:    rs.Status.Replicas,
+			template:    template,
+		})
+	}
+	sortRevisions(revisions)
+	return revisions, nil
+}
+
+// controllerRevisions returns the revisions of the StatefulSet or DaemonSet
+// with uid from its ControllerRevisions crs, sorted by revision.
+func controllerRevisions(uid types.UID, crs []appsv1.ControllerRevision) ([]workloadRevision, error) {
+	var revisions []workloadRevision
+	for i := range crs {
+		cr := &crs[i]
+		if !ownedBy(cr, uid) {
+			continue
+		}
+		// The data of the revision is a patch replacing the pod template.
+		var data map[string]any
+		if err := json.Unmarshal(cr.Data.Raw, &data); err != nil {
+			return nil, fmt.Errorf("failed to decode controllerrevision %s: %w", cr.Name, err)
+		}
+		template, _, _ := unstructured.NestedMap(data, "spec", "template")
+		delete(template, "$patch")
+		unstructured.RemoveNestedField(template, "metadata", "creationTimestamp")
+		revisions = append(revisions, workloadRevision{
+			revision:    cr.Revision,
+			created:     cr.CreationTimestamp.Time,
+			changeCause: cr.Annotations[changeCauseAnnotation],
+			replicas:    -1,
+			template:    template,
+		})
+	}
+	sortRevisions(revisions)
+	return revisions, nil
+}
+
+func sortRevisions(revisions []workloadRevision) {
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].revision < revisions[j].revision })
+}
+
+// formatRevisionHistory formats the revisions of the workload, the last
+// maxRevisions of them, with the changes of revision, or of all of them if
+// revision is 0.
+func formatRevisionHistory(workload string, revisions []workloadRevision, revision int64, maxRevisions int) (string, error) {
+	var output strings.Builder
+	if len(revisions) == 0 {
+		return fmt.Sprintf("%s has no revisions.\n", workload), nil
+	}
+	output.WriteString(fmt.Sprintf("%s: %d revisions.\n\n", workload, len(revisions)))
+
+	current := revisions[len(revisions)-1].revision
+	shown := revisions[max(0, len(revisions)-maxRevisions):]
+	output.WriteString("REVISION\tCREATED\tREPLICAS\tIMAGES\tCHANGE-CAUSE\n")
+	for _, r := range shown {
+		marker := ""
+		if r.revision == current {
+			marker = "*"
+		}
+		replicas := "-"
+		if r.replicas >= 0 {
+			replicas = strconv.Itoa(int(r.replicas))
+		}
+		output.WriteString(fmt.Sprintf("%d%s\t%s\t%s\t%s\t%s\n", r.revision, marker, r.created.UTC().Format(time.RFC3339), replicas, strings.Join(r.images(), ","), r.changeCause))
+	}
+
+	found := revision == 0
+	for i := 1; i < len(revisions); i++ {
+		prev, r := revisions[i-1], revisions[i]
+		if revision != 0 && r.revision != revision {
+			continue
+		}
+		if revision == 0 && i < len(revisions)-len(shown)+1 {
+			continue
+		}
+		found = true
+		before, err := yaml.Marshal(prev.template)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal revision %d: %w", prev.revision, err)
+		}
+		after, err := yaml.Marshal(r.template)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal revision %d: %w", r.revision, err)
+		}
+		output.WriteString(fmt.Sprintf("\n### Revision %d -> %d\n", prev.revision, r.revision))
+		if diff := lineDiff(string(before), string(after)); diff != "" {
+			output.WriteString(diff)
+		} else {
+			output.WriteString("No changes to the pod template.\n")
+		}
+	}
+	if !found {
+		if revisions[0].revision == revision {
+			output.WriteString(fmt.Sprintf("\nRevision %d is the oldest revision: there is no previous revision to compare it with.\n", revision))
+		} else {
+			return "", fmt.Errorf("revision %d of %s not found", revision, workload)
+		}
+	}
+	return output.String(), nil
+}
+
+func (h *handlers) rolloutHistory(ctx context.Context, _ *mcp.CallToolRequest, args *rolloutHistoryArgs) (*mcp.CallToolResult, any, error) {
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+	maxRevisions := args.MaxRevisions
+	if maxRevisions <= 0 {
+		maxRevisions = defaultMaxRevisions
+	}
+
+	apps := h.clientset.AppsV1()
+	var workload string
+	var revisions []workloadRevision
+	switch strings.ToLower(args.Kind) {
+	case "deployment", "deployments", "deploy":
+		d, err := apps.Deployments(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get deployment: %w", err)
+		}
+		selector, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse the selector of deployment %s: %w", d.Name, err)
+		}
+		rss, err := apps.ReplicaSets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list replicasets: %w", err)
+		}
+		workload = "deployment/" + d.Name
+		revisions, err = deploymentRevisions(d, rss.Items)
+		if err != nil {
+			return nil, nil, err
+		}
+	case "statefulset", "statefulsets", "sts", "daemonset", "daemonsets", "ds":
+		var uid types.UID
+		var labelSelector *metav1.LabelSelector
+		if strings.HasPrefix(strings.ToLower(args.Kind), "s") {
+			sts, err := apps.StatefulSets(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get statefulset: %w", err)
+			}
+			workload, uid, labelSelector = "statefulset/"+sts.Name, sts.UID, sts.Spec.Selector
+		} else {
+			ds, err := apps.DaemonSets(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to get daemonset: %w", err)
+			}
+			workload, uid, labelSelector = "daemonset/"+ds.Name, ds.UID, ds.Spec.Selector
+		}
+		selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse the selector of %s: %w", workload, err)
+		}
+		crs, err := apps.ControllerRevisions(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list controllerrevisions: %w", err)
+		}
+		revisions, err = controllerRevisions(uid, crs.Items)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("rollout history is not supported for kind %q, use deployment, statefulset or daemonset", args.Kind)
+	}
+
+	output, err := formatRevisionHistory(workload+" in "+namespace, revisions, args.Revision, maxRevisions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output},
+		},
+	}, nil, nil
+}
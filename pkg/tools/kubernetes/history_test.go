@@ -0,0 +1,122 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func testReplicaSet(owner types.UID, revision, image string, replicas int32, created time.Time) appsv1.ReplicaSet {
+	controller := true
+	return appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "web-" + revision,
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations:       map[string]string{deploymentRevisionAnnotation: revision},
+			OwnerReferences:   []metav1.OwnerReference{{Kind: "Deployment", Name: "web", UID: owner, Controller: &controller}},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web", appsv1.DefaultDeploymentUniqueLabelKey: "hash-" + revision}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}},
+			},
+		},
+		Status: appsv1.ReplicaSetStatus{Replicas: replicas},
+	}
+}
+
+func TestDeploymentRevisions(t *testing.T) {
+	d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", UID: "d1"}}
+	created := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	revisions, err := deploymentRevisions(d, []appsv1.ReplicaSet{
+		testReplicaSet("d1", "2", "web:v2", 3, created.Add(time.Hour)),
+		testReplicaSet("d1", "1", "web:v1", 0, created),
+		testReplicaSet("other", "3", "other:v1", 1, created),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := formatRevisionHistory("deployment/web in default", revisions, 0, defaultMaxRevisions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `deployment/web in default: 2 revisions.
+
+REVISION	CREATED	REPLICAS	IMAGES	CHANGE-CAUSE
+1	2025-06-01T10:00:00Z	0	web:v1	
+2*	2025-06-01T11:00:00Z	3	web:v2	
+
+### Revision 1 -> 2
+...
+  spec:
+    containers:
+-   - image: web:v1
++   - image: web:v2
+      name: web
+      resources: {}
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("formatRevisionHistory() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestControllerRevisions(t *testing.T) {
+	controller := true
+	revision := func(n int64, image string) appsv1.ControllerRevision {
+		return appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "db-" + image,
+				OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", UID: "s1", Controller: &controller}},
+			},
+			Revision: n,
+			Data:     runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"$patch":"replace","spec":{"containers":[{"name":"db","image":"` + image + `"}]}}}}`)},
+		}
+	}
+	revisions, err := controllerRevisions("s1", []appsv1.ControllerRevision{revision(2, "db:v2"), revision(1, "db:v1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][]string
+	for _, r := range revisions {
+		got = append(got, r.images())
+	}
+	if diff := cmp.Diff([][]string{{"db:v1"}, {"db:v2"}}, got); diff != "" {
+		t.Errorf("controllerRevisions() images mismatch (-want +got):\n%s", diff)
+	}
+	if _, ok := revisions[0].template["$patch"]; ok {
+		t.Errorf("controllerRevisions() kept the $patch directive")
+	}
+
+	out, err := formatRevisionHistory("statefulset/db in default", revisions, 1, defaultMaxRevisions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Revision 1 is the oldest revision") {
+		t.Errorf("formatRevisionHistory(revision 1) = %q, want the oldest revision message", out)
+	}
+	if _, err := formatRevisionHistory("statefulset/db in default", revisions, 7, defaultMaxRevisions); err == nil {
+		t.Errorf("formatRevisionHistory(revision 7) succeeded, want an error")
+	}
+}
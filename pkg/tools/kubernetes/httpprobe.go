@@ -0,0 +1,159 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// HTTPProbeToolDescription contains the documentation for the HTTP Probe Kubernetes tool.
+// It is formatted in Markdown.
+const HTTPProbeToolDescription = `
+This tool sends an HTTP GET request to a pod or a service through the API server's proxy (the *pods/proxy* and *services/proxy* subresources), and returns the status code, the latency and the beginning of the response body. Use it to check an application's health, readiness or status endpoint without exec'ing into a container or starting a port-forward.
+
+The request is sent by the API server, so it tests the network path from the control plane to the pod. On GKE, a failure with a timeout while the pod is healthy usually means that a firewall rule blocks the control plane from reaching the port. The latency includes the round trip to the API server.
+
+## Arguments
+
+* *resource*: *pod* or *service*.
+* *name*: The name of the pod or service.
+* *namespace*: (Optional) The namespace of the pod or service. Defaults to the server's default namespace.
+* *port*: (Optional) The port number or name to send the request to. Defaults to the first port of the service, or the default port of the pod.
+* *path*: (Optional) The path and query of the request, e.g. */healthz* or */status?verbose=1*. Defaults to */*.
+* *https*: (Optional) Set to *true* to use HTTPS between the API server and the pod. The certificate of the pod is not verified.
+* *max_body_bytes*: (Optional) The maximum number of bytes of the body to return. Defaults to 4096.
+
+## Response Format
+
+GET /healthz on service default/web port 8080: HTTP 200 in 45ms
+
+Body (2 bytes):
+ok
+`
+
+type httpProbeArgs struct {
+	Resource     string `json:"resource"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace,omitempty"`
+	Port         string `json:"port,omitempty"`
+	Path         string `json:"path,omitempty"`
+	HTTPS        bool   `json:"https,omitempty"`
+	MaxBodyBytes int    `json:"max_body_bytes,omitempty"`
+}
+
+const defaultProbeBodyBytes = 4096
+
+// proxyName returns the name of a pod or service in a proxy request, of the
+// form [scheme:]name[:port].
+func proxyName(name, port string, https bool) string {
+	if port != "" {
+		name += ":" + port
+	}
+	if https {
+		name = "https:" + name
+	}
+	return name
+}
+
+// truncateBody returns the first max bytes of body, without splitting a
+// UTF-8 character, and whether it was truncated.
+func truncateBody(body []byte, max int) (string, bool) {
+	if len(body) <= max {
+		return string(body), false
+	}
+	body = body[:max]
+	for len(body) > 0 && !utf8.Valid(body) {
+		body = body[:len(body)-1]
+	}
+	return string(body), true
+}
+
+func (h *handlers) httpProbe(ctx context.Context, _ *mcp.CallToolRequest, args *httpProbeArgs) (*mcp.CallToolResult, any, error) {
+	var resource string
+	switch strings.ToLower(args.Resource) {
+	case "pod", "pods", "po":
+		resource = "pods"
+	case "service", "services", "svc":
+		resource = "services"
+	default:
+		return nil, nil, fmt.Errorf("invalid resource %q: must be pod or service", args.Resource)
+	}
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+	maxBody := args.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = defaultProbeBodyBytes
+	}
+	target, err := url.Parse(args.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid path %q: %w", args.Path, err)
+	}
+
+	request := h.clientset.CoreV1().RESTClient().Get().
+		Namespace(namespace).
+		Resource(resource).
+		Name(proxyName(args.Name, args.Port, args.HTTPS)).
+		SubResource("proxy")
+	if p := strings.TrimPrefix(target.Path, "/"); p != "" {
+		request = request.Suffix(p)
+	}
+	for key, values := range target.Query() {
+		for _, v := range values {
+			request = request.Param(key, v)
+		}
+	}
+
+	start := time.Now()
+	result := request.Do(ctx)
+	latency := time.Since(start)
+	var code int
+	result.StatusCode(&code)
+	body, err := result.Raw()
+	if code == 0 {
+		return nil, nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	path := args.Path
+	if path == "" {
+		path = "/"
+	}
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("GET %s on %s %s/%s", path, strings.TrimSuffix(resource, "s"), namespace, args.Name))
+	if args.Port != "" {
+		output.WriteString(" port " + args.Port)
+	}
+	output.WriteString(fmt.Sprintf(": HTTP %d in %s\n", code, latency.Round(time.Millisecond)))
+	text, truncated := truncateBody(body, maxBody)
+	output.WriteString(fmt.Sprintf("\nBody (%d bytes", len(body)))
+	if truncated {
+		output.WriteString(fmt.Sprintf(", truncated to %d", len(text)))
+	}
+	output.WriteString("):\n" + text)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import "testing"
+
+func TestProxyName(t *testing.T) {
+	for _, tc := range []struct {
+		name, port string
+		https      bool
+		want       string
+	}{
+		{name: "web", want: "web"},
+		{name: "web", port: "8080", want: "web:8080"},
+		{name: "web", port: "admin", https: true, want: "https:web:admin"},
+	} {
+		if got := proxyName(tc.name, tc.port, tc.https); got != tc.want {
+			t.Errorf("proxyName(%q, %q, %t) = %q, want %q", tc.name, tc.port, tc.https, got, tc.want)
+		}
+	}
+}
+
+func TestTruncateBody(t *testing.T) {
+	if got, truncated := truncateBody([]byte("ok"), 10); got != "ok" || truncated {
+		t.Errorf("truncateBody(ok, 10) = %q, %t, want ok, false", got, truncated)
+	}
+	// "é" is 2 bytes long, and is not split.
+	if got, truncated := truncateBody([]byte("café au lait"), 4); got != "caf" || !truncated {
+		t.Errorf("truncateBody(café au lait, 4) = %q, %t, want caf, true", got, truncated)
+	}
+}
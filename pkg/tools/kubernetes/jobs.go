@@ -0,0 +1,367 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// JobReportToolDescription contains the documentation for the Job Report Kubernetes tool.
+// It is formatted in Markdown.
+const JobReportToolDescription = `
+This tool summarizes the recent runs of Jobs, for a CronJob or for the CronJobs and Jobs of a namespace. Use it to check the health of batch workloads.
+
+The report contains:
+
+* **CronJobs**: each CronJob with its schedule, its last scheduled and last successful runs, the number of succeeded and failed Jobs it keeps, their average duration, and its problems: suspended, last run failed, or missed schedules. CronJobs whose controller missed too many start times, e.g. because the CronJob was suspended or the controller was unavailable, stop being scheduled until *startingDeadlineSeconds* is set.
+* **Jobs**: each Job with its status, start time, duration, number of failed pods against its *backoffLimit*, and the reason of its failure, such as *BackoffLimitExceeded* or *DeadlineExceeded*. Running Jobs with failed pods are retrying with an exponential backoff.
+* **Last failure logs**: for each CronJob, or for the namespace without a CronJob, the last lines of the logs of the failed container of the most recent failed Job.
+
+Only the Jobs kept by the *successfulJobsHistoryLimit* and *failedJobsHistoryLimit* of CronJobs, or not yet deleted by their *ttlSecondsAfterFinished*, can be reported.
+
+## Arguments
+
+* *cronjob*: (Optional) The name of a CronJob: only its Jobs are reported.
+* *namespace*: (Optional) The namespace of the Jobs. Defaults to the server's default namespace.
+* *all_namespaces*: (Optional) Set to *true* to report on all namespaces. Can't be combined with *cronjob*.
+* *tail_lines*: (Optional) The number of lines of the failure logs. Defaults to 20.
+
+## Response Format
+
+CronJobs in namespace "default":
+NAMESPACE  NAME    SCHEDULE   LAST_SCHEDULE  LAST_SUCCESS  SUCCEEDED  FAILED  AVG_DURATION  STATUS
+default    backup  0 * * * *  12m            1h            2          1       4m            last run failed
+
+Jobs:
+NAMESPACE  NAME               CRONJOB  STATUS     STARTED  DURATION  FAILURES  REASON
+default    backup-29000000    backup   Failed     12m      2m        6/6       BackoffLimitExceeded: Job has reached the specified backoff limit
+
+Last failure logs:
+### default/backup-29000000, pod backup-29000000-x7k2, container backup
+pg_dump: error: connection to server failed
+`
+
+type jobReportArgs struct {
+	CronJob       string `json:"cronjob,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+	TailLines     int    `json:"tail_lines,omitempty"`
+}
+
+const defaultJobLogTailLines = 20
+
+// jobRun is the summary of a run of a Job.
+type jobRun struct {
+	namespace, name string
+	// cronJob is the name of the CronJob that created the Job, if any.
+	cronJob  string
+	status   string
+	started  time.Time
+	duration time.Duration
+	failures int32
+	// backoffLimit is the number of retries before the Job fails.
+	backoffLimit int32
+	reason       string
+}
+
+// summarizeJob returns the summary of job, running at now.
+func summarizeJob(job *batchv1.Job, now time.Time) jobRun {
+	r := jobRun{
+		namespace:    job.Namespace,
+		name:         job.Name,
+		status:       "Running",
+		failures:     job.Status.Failed,
+		backoffLimit: ptr.Deref(job.Spec.BackoffLimit, 6),
+	}
+	if ref := metav1.GetControllerOf(job); ref != nil && ref.Kind == "CronJob" {
+		r.cronJob = ref.Name
+	}
+	if job.Status.StartTime != nil {
+		r.started = job.Status.StartTime.Time
+	}
+	end := now
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			r.status = "Succeeded"
+			end = c.LastTransitionTime.Time
+		case batchv1.JobFailed:
+			r.status = "Failed"
+			end = c.LastTransitionTime.Time
+			r.reason = c.Reason
+			if c.Message != "" {
+				r.reason += ": " + c.Message
+			}
+		case batchv1.JobSuspended:
+			r.status = "Suspended"
+		}
+	}
+	if job.Status.CompletionTime != nil {
+		end = job.Status.CompletionTime.Time
+	}
+	if !r.started.IsZero() {
+		r.duration = end.Sub(r.started)
+	}
+	if r.status == "Running" && r.failures > 0 {
+		r.reason = fmt.Sprintf("retrying with backoff after %d failed pods", r.failures)
+	}
+	return r
+}
+
+// cronJobSummary is the summary of a CronJob and its Jobs.
+type cronJobSummary struct {
+	succeeded, failed int
+	avgDuration       time.Duration
+	lastFailed        bool
+	missedSchedules   string
+}
+
+// summarizeCronJob summarizes the runs of cj, sorted by start time, and the
+// missed schedules reported by its events.
+func summarizeCronJob(cj *batchv1.CronJob, runs []jobRun, events []corev1.Event) cronJobSummary {
+	var s cronJobSummary
+	var total time.Duration
+	for _, r := range runs {
+		if r.cronJob != cj.Name || r.namespace != cj.Namespace {
+			continue
+		}
+		switch r.status {
+		case "Succeeded":
+			s.succeeded++
+			total += r.duration
+		case "Failed":
+			s.failed++
+		}
+		if r.status == "Succeeded" || r.status == "Failed" {
+			s.lastFailed = r.status == "Failed"
+		}
+	}
+	if s.succeeded > 0 {
+		s.avgDuration = total / time.Duration(s.succeeded)
+	}
+	for _, e := range events {
+		if e.InvolvedObject.Kind != "CronJob" || e.InvolvedObject.Name != cj.Name || e.InvolvedObject.Namespace != cj.Namespace {
+			continue
+		}
+		// TooManyMissedTimes stops the scheduling of the CronJob, while
+		// MissSchedule reports a single missed run.
+		if e.Reason == "TooManyMissedTimes" || (e.Reason == "MissSchedule" && s.missedSchedules == "") {
+			s.missedSchedules = e.Message
+		}
+	}
+	return s
+}
+
+// status describes the problems of cj, or returns "OK".
+func (s cronJobSummary) status(cj *batchv1.CronJob) string {
+	var problems []string
+	if ptr.Deref(cj.Spec.Suspend, false) {
+		problems = append(problems, "suspended")
+	}
+	if s.lastFailed {
+		problems = append(problems, "last run failed")
+	}
+	if s.missedSchedules != "" {
+		problems = append(problems, "missed schedules: "+s.missedSchedules)
+	}
+	if len(problems) == 0 {
+		return "OK"
+	}
+	return strings.Join(problems, "; ")
+}
+
+// formatSince formats the time elapsed since t, or "<none>" if t is nil.
+func formatSince(t *metav1.Time, now time.Time) string {
+	if t == nil {
+		return "<none>"
+	}
+	return formatAge(now.Sub(t.Time))
+}
+
+// failedContainer returns the pod of pods that failed last, and its failed
+// container, or nil if no pod failed.
+func failedContainer(pods []corev1.Pod) (*corev1.Pod, string) {
+	var last *corev1.Pod
+	var lastContainer string
+	var lastFinished time.Time
+	for i := range pods {
+		pod := &pods[i]
+		for _, cs := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+			t := cs.State.Terminated
+			if t == nil || t.ExitCode == 0 {
+				continue
+			}
+			if last == nil || t.FinishedAt.After(lastFinished) {
+				last, lastContainer, lastFinished = pod, cs.Name, t.FinishedAt.Time
+			}
+		}
+	}
+	return last, lastContainer
+}
+
+// jobFailureLogs returns the last tailLines lines of the logs of the failed
+// container of the Job run.
+func (h *handlers) jobFailureLogs(ctx context.Context, run jobRun, tailLines int64) (string, error) {
+	pods, err := h.clientset.CoreV1().Pods(run.namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + run.name})
+	if err != nil {
+		return "", fmt.Errorf("failed to list the pods of job %s: %w", run.name, err)
+	}
+	pod, container := failedContainer(pods.Items)
+	if pod == nil {
+		return fmt.Sprintf("### %s/%s\nNo failed pods found: they may have been deleted.\n", run.namespace, run.name), nil
+	}
+	logs, err := h.clientset.CoreV1().Pods(run.namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: container, TailLines: &tailLines}).DoRaw(ctx)
+	if err != nil {
+		logs = []byte(fmt.Sprintf("failed to get logs: %v", err))
+	}
+	return fmt.Sprintf("### %s/%s, pod %s, container %s\n%s\n", run.namespace, run.name, pod.Name, container, strings.TrimRight(string(logs), "\n")), nil
+}
+
+func (h *handlers) jobReport(ctx context.Context, _ *mcp.CallToolRequest, args *jobReportArgs) (*mcp.CallToolResult, any, error) {
+	if args.CronJob != "" && args.AllNamespaces {
+		return nil, nil, fmt.Errorf("cronjob and all_namespaces cannot be combined")
+	}
+	namespace, err := h.namespaceScope(args.Namespace, args.AllNamespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	tailLines := int64(args.TailLines)
+	if tailLines <= 0 {
+		tailLines = defaultJobLogTailLines
+	}
+	now := time.Now()
+
+	batch := h.clientset.BatchV1()
+	var cronJobs []batchv1.CronJob
+	if args.CronJob != "" {
+		cj, err := batch.CronJobs(namespace).Get(ctx, args.CronJob, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get cronjob: %w", err)
+		}
+		cronJobs = append(cronJobs, *cj)
+	} else {
+		list, err := batch.CronJobs(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list cronjobs: %w", err)
+		}
+		cronJobs = list.Items
+	}
+	jobs, err := batch.Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	events, err := h.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: "involvedObject.kind=CronJob"})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	var runs []jobRun
+	for i := range jobs.Items {
+		run := summarizeJob(&jobs.Items[i], now)
+		if args.CronJob != "" && run.cronJob != args.CronJob {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].namespace != runs[j].namespace {
+			return runs[i].namespace < runs[j].namespace
+		}
+		return runs[i].started.Before(runs[j].started)
+	})
+	sort.Slice(cronJobs, func(i, j int) bool {
+		return cronJobs[i].Namespace+"/"+cronJobs[i].Name < cronJobs[j].Namespace+"/"+cronJobs[j].Name
+	})
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("CronJobs in %s:\n", describeScope(namespace)))
+	if len(cronJobs) == 0 {
+		output.WriteString("No CronJobs found.\n")
+	} else {
+		output.WriteString("NAMESPACE\tNAME\tSCHEDULE\tLAST_SCHEDULE\tLAST_SUCCESS\tSUCCEEDED\tFAILED\tAVG_DURATION\tSTATUS\n")
+		for i := range cronJobs {
+			cj := &cronJobs[i]
+			s := summarizeCronJob(cj, runs, events.Items)
+			avg := "-"
+			if s.succeeded > 0 {
+				avg = formatAge(s.avgDuration)
+			}
+			output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n", cj.Namespace, cj.Name, cj.Spec.Schedule,
+				formatSince(cj.Status.LastScheduleTime, now), formatSince(cj.Status.LastSuccessfulTime, now), s.succeeded, s.failed, avg, s.status(cj)))
+		}
+	}
+
+	output.WriteString("\nJobs:\n")
+	if len(runs) == 0 {
+		output.WriteString("No Jobs found.\n")
+	} else {
+		output.WriteString("NAMESPACE\tNAME\tCRONJOB\tSTATUS\tSTARTED\tDURATION\tFAILURES\tREASON\n")
+		for _, r := range runs {
+			started, duration := "-", "-"
+			if !r.started.IsZero() {
+				started, duration = formatAge(now.Sub(r.started)), formatAge(r.duration)
+			}
+			cronJob := r.cronJob
+			if cronJob == "" {
+				cronJob = "<none>"
+			}
+			output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%d/%d\t%s\n", r.namespace, r.name, cronJob, r.status, started, duration, r.failures, r.backoffLimit, r.reason))
+		}
+	}
+
+	// The most recent failed run of each CronJob, or of the Jobs without one
+	// per namespace.
+	lastFailed := map[string]jobRun{}
+	var keys []string
+	for _, r := range runs {
+		if r.status != "Failed" {
+			continue
+		}
+		key := r.namespace + "/" + r.cronJob
+		if _, ok := lastFailed[key]; !ok {
+			keys = append(keys, key)
+		}
+		lastFailed[key] = r
+	}
+	if len(keys) > 0 {
+		output.WriteString("\nLast failure logs:\n")
+		for _, key := range keys {
+			logs, err := h.jobFailureLogs(ctx, lastFailed[key], tailLines)
+			if err != nil {
+				return nil, nil, err
+			}
+			output.WriteString(logs)
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func testJob(name, cronJob string, started time.Time, condition batchv1.JobConditionType, finished time.Time, failed int32) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       batchv1.JobSpec{BackoffLimit: ptr.To[int32](2)},
+		Status:     batchv1.JobStatus{StartTime: &metav1.Time{Time: started}, Failed: failed},
+	}
+	if cronJob != "" {
+		job.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: cronJob, Controller: ptr.To(true)}}
+	}
+	if condition != "" {
+		c := batchv1.JobCondition{Type: condition, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: finished}}
+		if condition == batchv1.JobFailed {
+			c.Reason = "BackoffLimitExceeded"
+		}
+		job.Status.Conditions = append(job.Status.Conditions, c)
+	}
+	return job
+}
+
+func TestSummarizeJob(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		job  *batchv1.Job
+		want jobRun
+	}{
+		{
+			name: "succeeded",
+			job:  testJob("backup-1", "backup", now.Add(-time.Hour), batchv1.JobComplete, now.Add(-50*time.Minute), 0),
+			want: jobRun{namespace: "default", name: "backup-1", cronJob: "backup", status: "Succeeded", started: now.Add(-time.Hour), duration: 10 * time.Minute, backoffLimit: 2},
+		},
+		{
+			name: "failed",
+			job:  testJob("backup-2", "backup", now.Add(-time.Hour), batchv1.JobFailed, now.Add(-55*time.Minute), 3),
+			want: jobRun{namespace: "default", name: "backup-2", cronJob: "backup", status: "Failed", started: now.Add(-time.Hour), duration: 5 * time.Minute, failures: 3, backoffLimit: 2, reason: "BackoffLimitExceeded"},
+		},
+		{
+			name: "retrying",
+			job:  testJob("migrate", "", now.Add(-2*time.Minute), "", time.Time{}, 1),
+			want: jobRun{namespace: "default", name: "migrate", status: "Running", started: now.Add(-2 * time.Minute), duration: 2 * time.Minute, failures: 1, backoffLimit: 2, reason: "retrying with backoff after 1 failed pods"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, summarizeJob(tc.job, now), cmp.AllowUnexported(jobRun{})); diff != "" {
+				t.Errorf("summarizeJob() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJobReport(t *testing.T) {
+	now := time.Now()
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default"},
+		Spec:       batchv1.CronJobSpec{Schedule: "0 * * * *"},
+	}
+	failedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-2-x7k2", Namespace: "default", Labels: map[string]string{"job-name": "backup-2"}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "backup",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, FinishedAt: metav1.Time{Time: now}}},
+		}}},
+	}
+	missed := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "backup.1", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "CronJob", Name: "backup", Namespace: "default"},
+		Reason:         "TooManyMissedTimes",
+		Message:        "too many missed start times: 101",
+	}
+	h := &handlers{
+		clientset: fake.NewClientset(cronJob, failedPod, missed,
+			testJob("backup-1", "backup", now.Add(-2*time.Hour), batchv1.JobComplete, now.Add(-110*time.Minute), 0),
+			testJob("backup-2", "backup", now.Add(-time.Hour), batchv1.JobFailed, now.Add(-55*time.Minute), 3),
+		),
+		defaultNamespace: "default",
+	}
+
+	res, _, err := h.jobReport(context.Background(), nil, &jobReportArgs{CronJob: "backup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := res.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{
+		"default\tbackup\t0 * * * *\t<none>\t<none>\t1\t1\t10m\tlast run failed; missed schedules: too many missed start times: 101\n",
+		"default\tbackup-2\tbackup\tFailed\t1h\t5m\t3/2\tBackoffLimitExceeded\n",
+		"### default/backup-2, pod backup-2-x7k2, container backup\nfake logs\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("jobReport() = %q, want it to contain %q", got, want)
+		}
+	}
+}
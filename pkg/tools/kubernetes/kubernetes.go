@@ -18,33 +18,48 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"text/template"
 	"time"
 
 	"cloud.google.com/go/logging/logadmin"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/cache"
 	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/middleware"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/compute/v1"
 	"google.golang.org/api/container/v1"
 	"google.golang.org/api/iterator"
+	"google.golang.org/api/pubsub/v1"
 	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/jsonpath"
 	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/yaml"
 )
 
@@ -68,15 +83,21 @@ To get the details of a cluster named "my-cluster" in the "us-central1-a" zone:
 
 The tool provides functionality similar to "gcloud" command line:
 gcloud container clusters describe my-cluster --zone us-central1-a
+
+Cluster details are cached for a short time. Set "refresh" to true to bypass the cache, for example while waiting for an operation on the cluster to complete.
 `
 
 // GKEListClustersToolDescription contains the documentation for the GKE List Clusters tool.
 // It is formatted in Markdown.
 const GKEListClustersToolDescription = `
-Lists all clusters owned by a project in either the specified zone or all zones. This is equivalent to running "gcloud container clusters list".
+Lists all clusters owned by one or more projects in either the specified zone or all zones. This is equivalent to running "gcloud container clusters list".
 
 This tool is useful for getting an overview of all the clusters in a project.
 
+By default the tool returns a summary table with the project, name, location, version, status and node count of each cluster. Set "detail" to true to get the full cluster objects as JSON instead.
+
+Use "project_ids" to list clusters in several projects at once; the projects are queried in parallel, up to 8 at a time; duplicate projects are queried once. If no project is given, the default project is used.
+
 This tool calls the GKE API's projects.locations.clusters.list method.
 
 Example:
@@ -85,6 +106,11 @@ To list all clusters in the "us-central1" region:
   "location": "us-central1"
 }
 
+To list all clusters in two projects:
+{
+  "project_ids": ["my-project", "my-other-project"]
+}
+
 The tool provides functionality similar to "gcloud" command line:
 gcloud container clusters list --region us-central1
 `
@@ -92,25 +118,34 @@ gcloud container clusters list --region us-central1
 // GKEUpdateNodePoolToolDescription contains the documentation for the GKE Update Node Pool tool.
 // It is formatted in Markdown.
 const GKEUpdateNodePoolToolDescription = `
-Updates the settings of a specific node pool. This is similar to "gcloud container node-pools update".
+This tool updates the settings of a node pool of a GKE cluster, like *gcloud container node-pools update*, and returns the long-running operation updating it.
 
-This tool is useful for changing the machine type, enabling or disabling autoscaling, or updating the node version of a node pool.
+Use it to change the node version, the machine type, the node image type, the Kubernetes labels and taints of the nodes, or the autoscaling of a node pool. GKE runs one update of a node pool at a time: autoscaling is updated in its own call, without the other settings. Changing the image type, the machine type or the node version recreates the nodes.
 
-This tool calls the GKE API's projects.locations.clusters.nodePools.update method.
+The image type selects the operating system of the nodes: Linux (*COS_CONTAINERD*, *UBUNTU_CONTAINERD*) or Windows Server (*WINDOWS_LTSC_CONTAINERD*, *WINDOWS_SAC_CONTAINERD*). Windows node pools aren't supported on Autopilot clusters, need GKE 1.21 or later, and need another, Linux, node pool in the cluster for the system workloads.
 
-Example:
-To enable autoscaling for a node pool named "my-node-pool" in a cluster named "my-cluster" in the "us-central1-a" zone, with a minimum of 1 and a maximum of 5 nodes:
-{
-  "cluster_name": "my-cluster",
-  "location": "us-central1-a",
-  "node_pool_id": "my-node-pool",
-  "enable_autoscaling": true,
-  "min_nodes": 1,
-  "max_nodes": 5
-}
+GKE Sandbox can't be enabled on an existing node pool: create one with *gke_create_node_pool* and *enable_sandbox* instead. The nodes of GKE Sandbox node pools keep the *COS_CONTAINERD* image type.
 
-The tool provides functionality similar to "gcloud" command line:
-gcloud container node-pools update my-node-pool --cluster my-cluster --zone us-central1-a --enable-autoscaling --min-nodes 1 --max-nodes 5
+## Arguments
+
+* *cluster_name*: The name of the cluster.
+* *node_pool_id*: The name of the node pool.
+* *location*: (Optional) The region or zone of the cluster. Defaults to the default location.
+* *project_id*: (Optional) The project of the cluster. Defaults to the default project.
+* *node_version*: (Optional) The Kubernetes version of the nodes, e.g. *1.32.2-gke.1297000*, or *-* for the version of the control plane.
+* *machine_type*: (Optional) The machine type of the nodes.
+* *image_type*: (Optional) The node image type, e.g. *COS_CONTAINERD* or *WINDOWS_LTSC_CONTAINERD*.
+* *labels*: (Optional) The Kubernetes labels of the nodes, replacing the current ones. An empty object removes them.
+* *taints*: (Optional) The Kubernetes taints of the nodes, replacing the current ones, in the *key=value:Effect* format of *kubectl taint*, e.g. *dedicated=windows:NoSchedule*. An empty list removes them.
+* *enable_autoscaling*: (Optional) Whether the cluster autoscaler scales the node pool.
+* *min_nodes*, *max_nodes*: (Optional) The limits of the autoscaler, per zone.
+* *total_min_nodes*, *total_max_nodes*: (Optional) The limits of the autoscaler, across zones.
+
+## Response Format
+
+The operation: follow it with *gke_get_operation*.
+
+Started updating node pool win-pool in cluster web: operation projects/my-project/locations/us-central1/operations/operation-123 is RUNNING. Follow it with gke_get_operation.
 `
 
 // GKEGetOperationToolDescription contains the documentation for the Get GKE Operation tool.
@@ -135,42 +170,65 @@ gcloud container operations describe operation-12345
 // GKECreateClusterToolDescription contains the documentation for the Create GKE Cluster tool.
 // It is formatted in Markdown.
 const GKECreateClusterToolDescription = `
-Creates a new GKE cluster. This is equivalent to running "gcloud container clusters create".
+This tool creates a GKE Standard cluster with a default node pool, like *gcloud container clusters create*, and returns the long-running operation creating it.
 
-This tool is used to provision a new GKE cluster with a specified name and location.
+Creating infrastructure costs money: the tool first returns an estimate of the monthly cost of the cluster and an estimate token, without creating it. Show the estimate to the user, and once they accept it, call the tool again with the same arguments and *estimate_token*: the token only confirms the estimate of the same arguments. The server creates at most 5 clusters and node pools per hour.
 
-This tool calls the GKE API's projects.locations.clusters.create method.
+The estimate covers the cluster management fee, the nodes at approximate on-demand list prices, and their 100 GB boot disks, without discounts, the free tier, network or other services. The server only knows the prices of the E2, N1, N2, N2D, T2D, C2 and C2D families in us-central1: the prices of other machine types, of other regions and of GPUs are unknown, and estimates with unknown prices have no token unless *allow_unknown_price* is set.
 
-Example:
-To create a new cluster named "my-new-cluster" in the "us-central1-a" zone:
-{
-  "cluster_name": "my-new-cluster",
-  "location": "us-central1-a"
-}
+## Arguments
 
-The tool provides functionality similar to "gcloud" command line:
-gcloud container clusters create my-new-cluster --zone us-central1-a
+* *cluster_name*: The name of the cluster.
+* *location*: (Optional) The region or zone of the cluster. Defaults to the default location. Regional clusters have nodes in 3 zones.
+* *project_id*: (Optional) The project of the cluster. Defaults to the default project.
+* *machine_type*: (Optional) The machine type of the nodes. Defaults to *e2-medium*.
+* *num_nodes*: (Optional) The number of nodes of the default node pool, per zone. Defaults to 3.
+* *enable_shielded_nodes*: (Optional) Whether the nodes are Shielded VMs, with verifiable identities and integrity. Defaults to true.
+* *enable_confidential_nodes*: (Optional) Set to true to run all the nodes of the cluster as Confidential VMs, whose memory is encrypted. They need a machine type of the *n2d*, *c2d*, *c3d* or *c3* families, e.g. *n2d-standard-2*, and shielded nodes.
+* *enable_secure_boot*: (Optional) Set to true to verify the boot components of the nodes with Secure Boot. Defaults to false.
+* *enable_integrity_monitoring*: (Optional) Whether the boot integrity of the nodes is monitored. Defaults to true.
+* *cluster_spec*: (Optional) The full cluster, as a Cluster message of the Container API (https://cloud.google.com/kubernetes-engine/docs/reference/rest/v1/projects.locations.clusters#Cluster) in JSON or YAML, for the settings the other arguments don't cover. It is submitted as-is, and replaces the other arguments but *cluster_name*, *location* and *project_id*: unknown fields are rejected, and the fields set to false, 0 or "" are sent rather than ignored. Its name defaults to *cluster_name*. The estimate covers the node pools of the spec, with 100 GB boot disks, and not the pods of Autopilot clusters.
+* *allow_unknown_price*: (Optional) Set to true, only once the user accepted an estimate with unknown prices, to get a token for it.
+* *estimate_token*: (Optional) The estimate token returned with the estimate of the same arguments, once the user accepted it, to create the cluster.
+
+## Response Format
+
+Without *estimate_token*, the estimate, with its token:
+
+Estimated cost of cluster web in us-central1: ~$526/month
+  Cluster management fee: ~$73/month
+  Nodes: 9 x e2-medium (3 per zone in 3 zones): ~$363/month
+  Boot disks: 9 x 100 GB: ~$90/month
+
+With *estimate_token*, the operation: follow it with *gke_get_operation*.
 `
 
 // GKEUpdateClusterToolDescription contains the documentation for the Update GKE Cluster tool.
 // It is formatted in Markdown.
 const GKEUpdateClusterToolDescription = `
-Updates a GKE cluster. This is equivalent to running "gcloud container clusters update".
+This tool updates the settings of a GKE cluster, like *gcloud container clusters update*, and returns the long-running operation updating it.
 
-This tool is used to modify the settings of an existing GKE cluster. For example, you can use it to update the cluster's description.
+The update is a ClusterUpdate message of the Container API (https://cloud.google.com/kubernetes-engine/docs/reference/rest/v1/ClusterUpdate), given as JSON or YAML and submitted as-is: its *desired* fields are the new settings, e.g. *desiredReleaseChannel* or *desiredMasterAuthorizedNetworksConfig*. Unknown fields are rejected, and the fields set to false, 0 or "" are sent rather than ignored. GKE applies most settings in their own update: change one at a time. Prefer the dedicated tools, e.g. *gke_update_node_pool* for the node pools, when they cover the change.
 
-This tool calls the GKE API's projects.locations.clusters.update method.
+## Arguments
 
-Example:
-To update the description of a cluster named "my-cluster" in the "us-central1-a" zone:
-{
-  "cluster_name": "my-cluster",
-  "location": "us-central1-a",
-  "description": "This is my updated cluster description."
-}
+* *cluster_name*: The name of the cluster.
+* *update_spec*: The ClusterUpdate message, as JSON or YAML.
+* *location*: (Optional) The region or zone of the cluster. Defaults to the default location.
+* *project_id*: (Optional) The project of the cluster. Defaults to the default project.
 
-The tool provides functionality similar to "gcloud" command line:
-gcloud container clusters update my-cluster --zone us-central1-a --description "This is my updated cluster description."
+For example, to enroll the cluster in the stable release channel:
+
+` + "```" + `yaml
+desiredReleaseChannel:
+  channel: STABLE
+` + "```" + `
+
+## Response Format
+
+The operation: follow it with *gke_get_operation*.
+
+Started updating cluster web: operation projects/my-project/locations/us-central1/operations/operation-123 is RUNNING. Follow it with gke_get_operation.
 `
 
 // GKEDeleteClusterToolDescription contains the documentation for the Delete GKE Cluster tool.
@@ -216,7 +274,7 @@ gcloud container clusters get-upgrade-info my-cluster --zone us-central1-a
 // GKECreateNodePoolToolDescription contains the documentation for the Create GKE Node Pool tool.
 // It is formatted in Markdown.
 const GKECreateNodePoolToolDescription = `
-Creates a new node pool in a GKE cluster.
+This tool creates a node pool in a GKE cluster, like *gcloud container node-pools create*, and returns the long-running operation creating it.
 
 A node pool is a group of nodes within a cluster that all have the same configuration. Node pools are useful for creating groups of nodes with specific characteristics, such as machine type, autoscaling configuration, or attached accelerators. You might create a new node pool to:
 - Isolate workloads with different resource requirements.
@@ -224,7 +282,41 @@ A node pool is a group of nodes within a cluster that all have the same configur
 - Enable autoscaling for a specific set of nodes.
 - Add GPUs or other accelerators to a subset of your nodes.
 
-This tool calls the GKE API's projects.locations.clusters.nodePools.create method.
+Creating infrastructure costs money: the tool first returns an estimate of the monthly cost of the node pool and an estimate token, without creating it. Show the estimate to the user, and once they accept it, call the tool again with the same arguments and *estimate_token*: the token only confirms the estimate of the same arguments. The server creates at most 5 clusters and node pools per hour.
+
+The server only knows the prices of the E2, N1, N2, N2D, T2D, C2 and C2D families in us-central1: the prices of other machine types and of other regions are unknown, and estimates with unknown prices have no token unless *allow_unknown_price* is set.
+
+## Arguments
+
+* *cluster_name*: The name of the cluster.
+* *node_pool_name*: The name of the node pool.
+* *location*: (Optional) The region or zone of the cluster. Defaults to the default location.
+* *project_id*: (Optional) The project of the cluster. Defaults to the default project.
+* *machine_type*: (Optional) The machine type of the nodes. Defaults to *e2-medium*.
+* *num_nodes*: (Optional) The number of nodes, per zone of the cluster. Defaults to 3.
+* *image_type*: (Optional) The node image type: *COS_CONTAINERD*, the default, or *UBUNTU_CONTAINERD* for Linux nodes, and *WINDOWS_LTSC_CONTAINERD* or *WINDOWS_SAC_CONTAINERD* for Windows Server nodes. Windows node pools aren't supported on Autopilot clusters, need GKE 1.21 or later, and need another, Linux, node pool in the cluster for the system workloads. Their estimate includes the Windows Server licenses.
+* *labels*: (Optional) The Kubernetes labels of the nodes.
+* *taints*: (Optional) The Kubernetes taints of the nodes, in the *key=value:Effect* format of *kubectl taint*, e.g. *dedicated=gpu:NoSchedule*.
+* *service_account*: (Optional) The email of the IAM service account of the nodes. Defaults to the Compute Engine default service account, which is usually over-privileged: prefer a dedicated service account with the least privileges the nodes need.
+* *oauth_scopes*: (Optional) The OAuth scopes of the nodes, as URLs or as names such as *cloud-platform*, which limit the Google Cloud APIs the service account is used for. Defaults to the default scopes of GKE. With a dedicated service account, *cloud-platform* lets its IAM roles alone control the access.
+* *tags*: (Optional) The Compute Engine network tags of the nodes, which firewall rules and routes select them with.
+* *metadata*: (Optional) The Compute Engine metadata of the nodes, as key-value pairs. GKE rejects the keys it reserves, such as *kube-env*.
+* *enable_confidential_nodes*: (Optional) Set to true to run the nodes as Confidential VMs, whose memory is encrypted. They need a machine type of the *n2d*, *c2d*, *c3d* or *c3* families, e.g. *n2d-standard-2*, and a Linux image. The node pools of clusters with confidential nodes are always confidential.
+* *enable_secure_boot*: (Optional) Set to true to verify the boot components of the nodes with Secure Boot. Defaults to false.
+* *enable_integrity_monitoring*: (Optional) Whether the boot integrity of the nodes is monitored. Defaults to true.
+* *enable_sandbox*: (Optional) Set to true to run the pods using the *gvisor* RuntimeClass in GKE Sandbox, which isolates them from the node kernel with gVisor. GKE creates the RuntimeClass with the first GKE Sandbox node pool, and taints the nodes for those pods only. GKE Sandbox needs the *COS_CONTAINERD* image type, the default, and another node pool without GKE Sandbox for the system workloads.
+* *allow_unknown_price*: (Optional) Set to true, only once the user accepted an estimate with unknown prices, to get a token for it.
+* *estimate_token*: (Optional) The estimate token returned with the estimate of the same arguments, once the user accepted it, to create the node pool.
+
+## Response Format
+
+Without *estimate_token*, the estimate, with its token:
+
+Estimated cost of node pool batch in cluster web: ~$453/month
+  Nodes: 9 x e2-medium (3 per zone in 3 zones): ~$363/month
+  Boot disks: 9 x 100 GB: ~$90/month
+
+With *estimate_token*, the operation: follow it with *gke_get_operation*.
 `
 
 // GKEUpdateMasterToolDescription contains the documentation for the GKE Update Master tool.
@@ -434,17 +526,20 @@ To complete a control plane upgrade for a cluster named "my-cluster" in the "us-
 `
 
 type gkeUpdateNodePoolArgs struct {
-	ProjectID         string `json:"project_id,omitempty"`
-	Location          string `json:"location"`
-	ClusterName       string `json:"cluster_name"`
-	NodePoolID        string `json:"node_pool_id"`
-	NodeVersion       string `json:"node_version,omitempty"`
-	MachineType       string `json:"machine_type,omitempty"`
-	EnableAutoscaling *bool  `json:"enable_autoscaling,omitempty"`
-	MinNodes          *int64 `json:"min_nodes,omitempty"`
-	MaxNodes          *int64 `json:"max_nodes,omitempty"`
-	TotalMinNodes     *int64 `json:"total_min_nodes,omitempty"`
-	TotalMaxNodes     *int64 `json:"total_max_nodes,omitempty"`
+	ProjectID         string            `json:"project_id,omitempty"`
+	Location          string            `json:"location,omitempty"`
+	ClusterName       string            `json:"cluster_name"`
+	NodePoolID        string            `json:"node_pool_id"`
+	NodeVersion       string            `json:"node_version,omitempty"`
+	MachineType       string            `json:"machine_type,omitempty"`
+	ImageType         string            `json:"image_type,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Taints            []string          `json:"taints,omitempty"`
+	EnableAutoscaling *bool             `json:"enable_autoscaling,omitempty"`
+	MinNodes          *int64            `json:"min_nodes,omitempty"`
+	MaxNodes          *int64            `json:"max_nodes,omitempty"`
+	TotalMinNodes     *int64            `json:"total_min_nodes,omitempty"`
+	TotalMaxNodes     *int64            `json:"total_max_nodes,omitempty"`
 }
 
 type gkeGetOperationArgs struct {
@@ -452,16 +547,25 @@ type gkeGetOperationArgs struct {
 }
 
 type gkeCreateClusterArgs struct {
-	ProjectID   string `json:"project_id,omitempty"`
-	Location    string `json:"location"`
-	ClusterName string `json:"cluster_name"`
+	ProjectID                 string `json:"project_id,omitempty"`
+	Location                  string `json:"location,omitempty"`
+	ClusterName               string `json:"cluster_name"`
+	MachineType               string `json:"machine_type,omitempty"`
+	NumNodes                  int64  `json:"num_nodes,omitempty"`
+	EnableShieldedNodes       *bool  `json:"enable_shielded_nodes,omitempty"`
+	EnableConfidentialNodes   bool   `json:"enable_confidential_nodes,omitempty"`
+	EnableSecureBoot          bool   `json:"enable_secure_boot,omitempty"`
+	EnableIntegrityMonitoring *bool  `json:"enable_integrity_monitoring,omitempty"`
+	ClusterSpec               string `json:"cluster_spec,omitempty"`
+	AllowUnknownPrice         bool   `json:"allow_unknown_price,omitempty"`
+	EstimateToken             string `json:"estimate_token,omitempty"`
 }
 
 type gkeUpdateClusterArgs struct {
 	ProjectID   string `json:"project_id,omitempty"`
-	Location    string `json:"location"`
+	Location    string `json:"location,omitempty"`
 	ClusterName string `json:"cluster_name"`
-	Description string `json:"description,omitempty"`
+	UpdateSpec  string `json:"update_spec"`
 }
 
 type gkeDeleteClusterArgs struct {
@@ -477,12 +581,25 @@ type gkeFetchClusterUpgradeInfoArgs struct {
 }
 
 type gkeCreateNodePoolArgs struct {
-	ProjectID    string `json:"project_id,omitempty"`
-	Location     string `json:"location"`
-	ClusterName  string `json:"cluster_name"`
-	NodePoolName string `json:"node_pool_name"`
-	MachineType  string `json:"machine_type,omitempty"`
-	NumNodes     int64  `json:"num_nodes,omitempty"`
+	ProjectID                 string            `json:"project_id,omitempty"`
+	Location                  string            `json:"location,omitempty"`
+	ClusterName               string            `json:"cluster_name"`
+	NodePoolName              string            `json:"node_pool_name"`
+	MachineType               string            `json:"machine_type,omitempty"`
+	NumNodes                  int64             `json:"num_nodes,omitempty"`
+	ImageType                 string            `json:"image_type,omitempty"`
+	Labels                    map[string]string `json:"labels,omitempty"`
+	Taints                    []string          `json:"taints,omitempty"`
+	ServiceAccount            string            `json:"service_account,omitempty"`
+	OAuthScopes               []string          `json:"oauth_scopes,omitempty"`
+	Tags                      []string          `json:"tags,omitempty"`
+	Metadata                  map[string]string `json:"metadata,omitempty"`
+	EnableConfidentialNodes   bool              `json:"enable_confidential_nodes,omitempty"`
+	EnableSecureBoot          bool              `json:"enable_secure_boot,omitempty"`
+	EnableIntegrityMonitoring *bool             `json:"enable_integrity_monitoring,omitempty"`
+	EnableSandbox             bool              `json:"enable_sandbox,omitempty"`
+	AllowUnknownPrice         bool              `json:"allow_unknown_price,omitempty"`
+	EstimateToken             string            `json:"estimate_token,omitempty"`
 }
 
 type gkeUpdateMasterArgs struct {
@@ -556,6 +673,25 @@ const GKEReadLogsToolDescription = `
 This tool reads GKE logs using the Google Cloud Logging API. This is the equivalent of running "gcloud logging read". Before using this tool, it's **strongly** recommended to call the 'get_log_schema' tool to get information about supported log types and their schemas. Logs are returned in ascending order, based on the timestamp (i.e. oldest first).
 
 This tool calls the Google Cloud Logging API's entries.list method.
+
+By default, each entry is returned on one line in a compact format: the timestamp, the severity, the resource type with the namespace, pod, container or node, and an excerpt of the payload, e.g. *2025-01-02T10:00:00Z ERROR k8s_container:web/web-0/app connection refused*. Set *verbose* to *true* to return each entry as JSON with all its metadata, or set *format* to a Go template executed with each entry, e.g. *{{.Timestamp}} {{.Payload}}*.
+
+Rather than writing the whole filter in *query*, use the convenience arguments, which are combined with *query* with AND:
+
+* *cluster_name*, *namespace*, *pod_name*, *container*: filter on the *cluster_name*, *namespace_name*, *pod_name* and *container_name* resource labels, e.g. of *k8s_container* logs.
+* *severity_min*: the minimum severity of the entries, one of DEFAULT, DEBUG, INFO, NOTICE, WARNING, ERROR, CRITICAL, ALERT or EMERGENCY.
+
+For example, the errors of a pod: *{"cluster_name": "prod", "namespace": "web", "pod_name": "web-0", "severity_min": "ERROR", "since": "1h"}*. The raw *query* remains available for anything else, e.g. *resource.type="k8s_container" AND textPayload:"timeout"*.
+
+Set *aggregate* to count the matching entries instead of returning them, e.g. to answer "how many 5xx per hour over the last day" without fetching thousands of entries:
+
+* *aggregate*: what to count the entries by: *severity*, *log_name*, *resource_type*, *http_status*, *time*, a resource label such as *resource.labels.pod_name*, or an entry label such as *labels.k8s-pod/app*.
+* *interval*: (Optional) the size of the buckets when aggregating by *time*, e.g. *5m*. Defaults to *1h*.
+* *limit*: when aggregating, the maximum number of entries to count, most recent first. Defaults to 10000.
+
+For example, the 5xx responses of the last day per hour: *{"query": "httpRequest.status>=500", "since": "24h", "aggregate": "time", "interval": "1h"}*.
+
+Set *summarize* to *true* to return a summary of the entries instead of the raw JSON entries: the server asks the client's model, with MCP sampling, to cluster similar entries and highlight errors. Use it with a large *limit* to get an overview of hundreds of entries without exceeding the context window. If the client doesn't support sampling, the raw entries are returned.
 `
 
 // GKEGetLogSchemaToolDescription contains the documentation for the GKE Get Log Schema tool.
@@ -575,6 +711,10 @@ This tool retrieves one or more Kubernetes resources from the cluster's API serv
 
 In Kubernetes, "getting resources" means fetching the **live state and specifications** for all resources that match a specific type within a given scope (e.g., within a namespace or across the entire cluster). This is the equivalent of running a command like *kubectl get pods -n my-namespace*. If a name is specified, it will fetch a single resource, equivalent to *kubectl get pod my-pod*. The server returns a collection of complete object definitions.
 
+The response ends with a line reporting the scope that was used, e.g. *Scope: namespace "default"*, *Scope: all namespaces* or *Scope: cluster* for cluster-scoped resources.
+
+Unless the server is configured otherwise, the values of Secrets, and of environment variables with sensitive names such as *DB_PASSWORD*, are replaced with *[REDACTED]*. The keys are kept.
+
 ## Custom Columns:
 
 The 'customColumns' argument allows you to limit the output to specific fields as a table with custom columns. The value is a comma-separated list of 'HEADER:JSONPATH' pairs.
@@ -592,9 +732,15 @@ To get the name and image of all pods in the 'default' namespace, you would use
 
 This would produce output similar to this:
 
-NAME          IMAGE
-my-pod-1      nginx:latest
-my-pod-2      ubuntu:22.04
+NAME       IMAGE
+my-pod-1   nginx:latest
+my-pod-2   ubuntu:22.04
+
+The header is split from the JSONPath on the first colon, so the JSONPath can contain colons, and commas inside quotes or brackets don't separate columns, e.g. 'READY:.status.conditions[?(@.type=="Ready")].status'. A path can also be given in braces, e.g. '{.metadata.name}'.
+
+When a JSONPath matches several values, e.g. '.spec.containers[*].image', they are joined with commas. Lists and objects are rendered as JSON, and missing values as '<none>'.
+
+Set 'no_headers' to 'true' to omit the header row.
 
 ## Response Format: A List of YAML Documents
 
@@ -632,22 +778,36 @@ getResourcesArgs struct {
     Resource      string
     Name          string
     Namespace     string
+    AllNamespaces bool
     LabelSelector string
     FieldSelector string
     CustomColumns string
+    NoHeaders     bool
+    Output        string
+    OwnerKind     string
+    OwnerName     string
 }
 ` + "```" + `
 
 ### Argument Breakdown
 
-* *Resource*: The **plural, lowercase name** for the resource type (e.g., *pods*, *deployments*, *services*).
+* *Resource*: The **plural, lowercase name** for the resource type (e.g., *pods*, *deployments*, *services*). Kinds, singular and short names are also accepted, case-insensitively, and the name can be qualified with the API group, e.g. *deployments.apps*, or be a fully qualified *group/version/resource*, e.g. *networking.k8s.io/v1/ingresses*. If the resource type isn't found, the error suggests the closest ones; if it matches resources of several API groups, the error lists them to pick from.
 * *Name*: (Optional) The case-sensitive name of the specific resource instance you want to retrieve (e.g., *my-app-deployment*, *nginx-pod-123*). If omitted, all resources of the specified type will be returned.
-* *Namespace*: (Optional) The namespace from which to list resources.
+* *Namespace*: (Optional) The namespace from which to list resources. To list the resources of several namespaces in one call, give a comma-separated list of namespaces and regular expressions matching whole namespace names, e.g. *"checkout,payments,team-a-.*"*. A list cannot be combined with *Name*, *AllNamespaces* or *OwnerKind*.
     * If you provide a namespace, the tool will only list resources from that specific namespace.
-    * If this field is **omitted** for a namespaced resource type (like *Pods*), it will list resources from **all namespaces**.
-    * For cluster-scoped resources (like *Nodes*), this field should be omitted.
+    * If this field is **omitted** for a namespaced resource type (like *Pods*), the tool uses the server's default namespace, normally the namespace of the current kubeconfig context.
+    * For cluster-scoped resources (like *Nodes*), this field is ignored.
+* *AllNamespaces*: (Optional) Set to *true* to list a namespaced resource type across **all namespaces**. It cannot be combined with *Namespace* or *Name*.
 * *LabelSelector*: (Optional) A Kubernetes label selector to filter the resources.
+* *OwnerKind* and *OwnerName*: (Optional) The resource type and the name of an owner object, in the same namespace, to only list the resources it owns, directly or transitively through owner references, e.g. *deployments* and *web* for the pods of the *web* Deployment, owned by its ReplicaSets. Use them when the labels of the owned resources aren't known or don't match a selector. They cannot be combined with *Name* or *AllNamespaces*.
 * *FieldSelector*: (Optional) A Kubernetes field selector to filter the resources.
+* *CustomColumns*: (Optional) The columns of a table to return instead of the YAML documents, see above.
+* *NoHeaders*: (Optional) Set to *true* to omit the header row of the *custom-columns* and *csv* outputs.
+* *Output*: (Optional) The output format:
+    * *yaml*: The default, YAML documents, or the custom columns table if *CustomColumns* is set.
+    * *csv*: CSV records, with a header row, for scripts and spreadsheets. The columns are the custom columns if *CustomColumns* is set, and *NAMESPACE*, *NAME*, *KIND* and *CREATED* otherwise. Missing values are empty.
+    * *summary*: Only aggregate counts instead of the resources, when the shape of the data is enough: the total, the resources per namespace, and breakdowns by kind, e.g. the pods by phase, the ready deployments, statefulsets and daemonsets with their ready/desired replicas, the jobs by status, the nodes by readiness and the services by type. It is much smaller than the resources.
+    * *go-template=<template>*: The output of a [Go template](https://pkg.go.dev/text/template), as with *kubectl get -o go-template*. The template is executed on the resource when *Name* is set, and on a *List* holding the resources in *items* otherwise, e.g. *go-template={{range .items}}{{.metadata.name}}{{"\n"}}{{end}}*. The *base64decode*, *base64encode* and *toJSON* functions are available.
 
 ### Example
 
@@ -691,10 +851,28 @@ To apply a resource, you provide the full YAML manifest as a single string.
 // The actual struct includes JSON tags. They are omitted here for clarity.
 // Refer to the source code for the complete definition.
 applyResourceArgs struct {
-    Manifest string
+    Manifest    string
+    ManifestURL string
+    SHA256      string
+    Force       bool
 }
 ` + "```" + `
 
+### Applying Published Manifests
+
+Instead of *manifest*, set **manifest_url** to apply a published manifest that is too big to pass inline, such as the install manifest of an operator:
+
+* An *https* URL, e.g. *https://github.com/cert-manager/cert-manager/releases/download/v1.16.2/cert-manager.yaml*.
+* An OCI artifact, as *oci://REGISTRY/REPOSITORY:TAG* or *oci://REGISTRY/REPOSITORY@sha256:DIGEST*, such as an artifact pushed with *flux push artifact* or *oras push*. Public artifacts are pulled anonymously. The manifest is the only layer of the artifact, or its YAML layer; layers archived as tar+gzip are extracted, and their YAML files are applied.
+
+Manifests are limited to 10 MiB. Set **sha256** to the hex SHA-256 digest of the manifest, when the publisher provides one, to check that the manifest is the expected one. The layers of OCI artifacts are always checked against their digests.
+
+### Field Ownership and Conflicts
+
+Resources are applied with server-side apply. The server records the fields it sets under its own field manager, by default *kubeapi-mcp*.
+
+If a field in the manifest is owned by another field manager (for example, *spec.replicas* owned by a HorizontalPodAutoscaler, or fields managed by a controller or by *kubectl*), the apply fails and the tool returns the conflicting fields and their managers. Setting **force** to *true* takes ownership of these fields. Only do this after confirming with the user that overriding the other manager is intended, since the other manager may revert or fight the change.
+
 ### Response Format
 
 The tool's response is the full YAML of the object **after** it has been applied to the cluster. This returned manifest will include server-populated fields like the *status* block and fields within *metadata* (*uid*, *resourceVersion*, etc.), confirming the result of the operation.
@@ -784,6 +962,20 @@ The tool returns a table that provides the following information for each resour
 * **APIVERSION**: The API group and version (e.g., *v1*, *apps/v1*).
 * **NAMESPACED**: A boolean indicating whether the resource is namespaced (*true*) or cluster-scoped (*false*).
 * **KIND**: The CamelCase name of the resource kind (e.g., *Pod*).
+* **VERBS**: The verbs supported by the resource (e.g., *get,list,watch*). A resource without the *list* verb can't be listed with kube_get_resources, and one without *patch* can't be patched.
+
+The same resources are also returned as structured JSON, with the fields *name*, *shortNames*, *apiVersion*, *namespaced*, *kind* and *verbs*.
+
+The full list is large. Use the arguments to filter it:
+
+## Arguments
+
+* *api_group*: (Optional) Only list the resources of this API group, e.g. *apps* or *cert-manager.io*. Use *core* for the core group (*v1*).
+* *namespaced*: (Optional) Set to *true* to only list the namespaced resources, or to *false* to only list the cluster-scoped resources.
+* *verbs*: (Optional) Only list the resources supporting all these comma-separated verbs, e.g. *list,watch*.
+* *refresh*: (Optional) Set to *true* to bypass the cache.
+
+The list of resources is cached for a short time. Set *refresh* to *true* to bypass the cache, for example right after installing a new CRD.
 `
 
 // GetPodLogsToolDescription contains the documentation for the Get Kubernetes Pod Logs tool.
@@ -813,6 +1005,7 @@ getPodLogsArgs struct {
     Namespace string
     Container string
     Previous  bool
+    Summarize bool
 }
 ` + "```" + `
 
@@ -822,6 +1015,7 @@ getPodLogsArgs struct {
 * *Namespace*: The namespace where the pod exists.
 * *Container*: (Optional) The name of the container to get logs from. If omitted, and the pod has multiple containers, an error will be returned.
 * *Previous*: (Optional) If true, return logs from the previous instantiation of the container.
+* *Summarize*: (Optional) If true, return a summary of the logs instead of the raw logs: the server asks the client's model, with MCP sampling, to cluster similar lines and highlight errors. Use it for long or noisy logs. If the client doesn't support sampling, the raw logs are returned.
 
 ### Example
 
@@ -834,17 +1028,42 @@ To get logs from a pod named *my-app-pod-123* in the *production* namespace, you
 // DescribeResourceToolDescription contains the documentation for the Describe Kubernetes Resource tool.
 // It is formatted in Markdown.
 const DescribeResourceToolDescription = `
-This tool retrieves detailed information about a specific Kubernetes resource. It is similar to running "kubectl describe".
+This tool describes a Kubernetes object in one call, like *kubectl describe*: its metadata, the chain of its controllers, its conditions, its spec and status, and its events. Use it first when diagnosing a failing object, rather than fetching the object, its owners and its events separately.
 
-This tool is useful for inspecting the configuration, status, and events related to a resource.
+## Arguments
 
-Example:
-To describe a pod named "my-pod" in the "default" namespace:
-{
-  "resource": "pod",
-  "name": "my-pod",
-  "namespace": "default"
-}
+* *resource*: The kind or resource name of the object, e.g. *pod* or *deployment*.
+* *name*: The name of the object.
+* *namespace*: (Optional) The namespace of the object. Defaults to the server's default namespace for namespaced resources.
+
+## Response Format
+
+A text block, with the spec and status in YAML, and the events sorted by the time they were last seen. *Controlled By* lists the controller of the object, then the controller of that controller, and so on, e.g. the ReplicaSet and the Deployment of a pod:
+
+Name:           web-7d9f-abcde
+Namespace:      default
+Kind:           Pod
+APIVersion:     v1
+Labels:         app=web
+                pod-template-hash=7d9f
+Annotations:    <none>
+Created:        2025-01-01T10:00:00Z (3h ago)
+Controlled By:  ReplicaSet/web-7d9f
+                Deployment/web
+
+Conditions:
+  TYPE   STATUS  REASON              LAST_TRANSITION       MESSAGE
+  Ready  False   ContainersNotReady  2025-01-01T10:00:05Z  containers with unready status: [app]
+
+Spec:
+  ...
+
+Status:
+  ...
+
+Events:
+  TYPE     REASON   AGE  FROM     COUNT  MESSAGE
+  Warning  BackOff  2m   kubelet  12     Back-off restarting failed container
 `
 
 // RolloutStatusToolDescription contains the documentation for the Rollout Status Kubernetes tool.
@@ -876,6 +1095,8 @@ To get the CPU and memory usage of all pods in the "default" namespace:
   "resource": "pods",
   "namespace": "default"
 }
+
+If the namespace is omitted, pods of the server's default namespace are shown. Set "all_namespaces" to true to show pods of all namespaces. The response ends with a line reporting the scope that was used.
 `
 
 // GetComponentStatusesToolDescription contains the documentation for the Get Component Statuses Kubernetes tool.
@@ -910,6 +1131,26 @@ To dump cluster info:
 // It is formatted in Markdown.
 const PatchResourceToolDescription = `
 This tool patches a specific Kubernetes resource from the cluster.
+
+## Arguments
+
+* *resource*: The resource type, e.g. *deployments*.
+* *name*: The name of the resource.
+* *namespace*: (Optional) The namespace of the resource.
+* *patch*: The patch, in YAML or JSON.
+* *patchType*: (Optional) *strategic* (the default), *merge* or *json*.
+* *show_object*: (Optional) Set to *true* to also return the whole patched resource.
+
+## Response Format
+
+The fields changed by the patch, sorted by path, so that one-line changes are easy to verify. Lists are compared by index:
+
+Patched deployments/web, changed fields:
+~ metadata.generation: 3 -> 4
+~ spec.template.spec.containers[0].image: "nginx:1.25" -> "nginx:1.27"
++ spec.template.metadata.labels.tier: "frontend"
+
+Changes of other fields that happened at the same time are reported as well. The values of Secrets, and of environment variables with sensitive names, are shown as *[REDACTED]* unless the server is configured otherwise.
 `
 
 // CanIToolDescription contains the documentation for the Kubernetes Can I tool.
@@ -956,6 +1197,7 @@ type gkeGetClusterArgs struct {
 	ProjectID string `json:"project_id,omitempty"`
 	Location  string `json:"location"`
 	Name      string `json:"name"`
+	Refresh   bool   `json:"refresh,omitempty"`
 }
 
 type handlers struct {
@@ -967,18 +1209,126 @@ type handlers struct {
 	metricsClientset metricsv.Interface
 	logadminClient   *logadmin.Client
 	containerService *container.Service
+	computeService   *compute.Service
+	bigqueryService  *bigquery.Service
+	cache            *cache.Cache
+	// defaultNamespace is used by tools when the caller gives no namespace.
+	defaultNamespace string
+	// restConfig is used by tools that stream, such as port-forwards.
+	restConfig   *rest.Config
+	portForwards *portForwards
+	// savedQueries are the log queries run by gke_run_saved_query.
+	savedQueries []*savedQuery
+	// notifications are the GKE cluster notifications received from
+	// Pub/Sub, or nil if disabled.
+	notifications *clusterNotifications
+	// healthChecks are the background health checks, or nil if disabled.
+	healthChecks *backgroundHealthChecks
+	// undo records the changes of the write tools in each session, so that
+	// they can be undone.
+	undo *undoJournals
+	// gkeCreates limits the rate of the creations of clusters and node
+	// pools, shared by the handlers of the process.
+	gkeCreates *rateLimiter
+	// estimateKey signs the estimate tokens of the creations of clusters and
+	// node pools.
+	estimateKey []byte
+	// autopilot reports whether the cluster is a GKE Autopilot cluster, whose
+	// nodes are managed by Google.
+	autopilot bool
+}
+
+// kubeClientConfig returns the kubeconfig of the context of c.
+func kubeClientConfig(c *config.Config) clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	configOverrides := &clientcmd.ConfigOverrides{CurrentContext: c.KubeContext()}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
 }
 
-func Install(ctx context.Context, s *mcp.Server, c *config.Config) error {
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+// KubeContext returns the name of the kubeconfig context of c, or an empty
+// string if the kubeconfig can't be loaded.
+func KubeContext(c *config.Config) string {
+	if name := c.KubeContext(); name != "" {
+		return name
+	}
+	raw, err := kubeClientConfig(c).RawConfig()
+	if err != nil {
+		return ""
+	}
+	return raw.CurrentContext
+}
+
+// newRESTConfig returns the client configuration for the Kubernetes API
+// server of the kubeconfig context of c, tuned according to c.
+func newRESTConfig(c *config.Config) (*rest.Config, error) {
+	kubeConfig := kubeClientConfig(c)
 
 	restConfig, err := kubeConfig.ClientConfig()
 	if err != nil {
-		return fmt.Errorf("failed to get kubeconfig: %w", err)
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	// Timeouts are applied per tool call through the request context rather
+	// than globally on the client, so that large lists are not cut short.
+	restConfig.QPS = c.KubeQPS()
+	restConfig.Burst = c.KubeBurst()
+
+	if token := c.Credentials().KubeBearerToken; token != "" {
+		// Drop the credentials from the kubeconfig and keep only the
+		// connection settings.
+		restConfig = rest.AnonymousClientConfig(restConfig)
+		restConfig.BearerToken = token
+	}
+	return restConfig, nil
+}
+
+// DefaultNamespace returns the namespace configured in c, or else the
+// namespace of the kubeconfig context of c.
+func DefaultNamespace(c *config.Config) string {
+	if ns := c.DefaultNamespace(); ns != "" {
+		return ns
+	}
+	ns, _, err := kubeClientConfig(c).Namespace()
+	if err != nil || ns == "" {
+		return metav1.NamespaceDefault
+	}
+	return ns
+}
+
+// NewReadinessCheck returns a function reporting whether the Kubernetes API
+// server of the kubeconfig context of c is reachable and ready.
+func NewReadinessCheck(c *config.Config) (func(ctx context.Context) error, error) {
+	restConfig, err := newRESTConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	return func(ctx context.Context) error {
+		return dc.RESTClient().Get().AbsPath("/readyz").Do(ctx).Error()
+	}, nil
+}
+
+// The annotations of the tools that change resources, which the tool
+// middleware, such as approvals, tells apart from the tools reading them.
+var (
+	// writeTool annotates the tools that can update or delete resources.
+	writeTool = &mcp.ToolAnnotations{DestructiveHint: ptr.To(true)}
+	// createTool annotates the tools that only create resources.
+	createTool = &mcp.ToolAnnotations{DestructiveHint: ptr.To(false)}
+)
+
+func Install(ctx context.Context, s *mcp.Server, c *config.Config) error {
+	restConfig, err := newRESTConfig(c)
+	if err != nil {
+		return err
+	}
+
+	gcpOpts, err := c.GoogleClientOptions(ctx)
+	if err != nil {
+		return err
 	}
-	restConfig.Timeout = 30 * time.Second
 
 	clientset, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
@@ -996,21 +1346,47 @@ func Install(ctx context.Context, s *mcp.Server, c *config.Config) error {
 	}
 	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc))
 
+	// The requests of the detection of Autopilot are bounded too, so that
+	// they don't outlive it.
+	detectConfig := rest.CopyConfig(restConfig)
+	detectConfig.Timeout = autopilotDetectionTimeout
+	detectDC, err := discovery.NewDiscoveryClientForConfig(detectConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	detectCtx, cancel := context.WithTimeout(ctx, autopilotDetectionTimeout)
+	defer cancel()
+
 	metricsClientset, err := metricsv.NewForConfig(restConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create metrics clientset: %w", err)
 	}
 
-	logadminClient, err := logadmin.NewClient(ctx, c.DefaultProjectID())
+	logadminClient, err := logadmin.NewClient(ctx, c.DefaultProjectID(), gcpOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to create logadmin client: %w", err)
 	}
 
-	containerService, err := container.NewService(ctx)
+	containerService, err := container.NewService(ctx, gcpOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to create container service: %w", err)
 	}
 
+	computeService, err := compute.NewService(ctx, gcpOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create compute service: %w", err)
+	}
+
+	bigqueryService, err := bigquery.NewService(ctx, gcpOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create bigquery service: %w", err)
+	}
+
+	savedQueries, err := loadSavedQueries(c.LogQueriesPath())
+	if err != nil {
+		return fmt.Errorf("failed to load saved log queries: %w", err)
+	}
+
 	h := &handlers{
 		c:                c,
 		dyn:              dyn,
@@ -1020,186 +1396,533 @@ func Install(ctx context.Context, s *mcp.Server, c *config.Config) error {
 		metricsClientset: metricsClientset,
 		logadminClient:   logadminClient,
 		containerService: containerService,
+		computeService:   computeService,
+		bigqueryService:  bigqueryService,
+		cache:            cache.New(c.CacheTTL()),
+		defaultNamespace: DefaultNamespace(c),
+		restConfig:       restConfig,
+		portForwards:     newPortForwards(),
+		savedQueries:     savedQueries,
+		undo:             newUndoJournals(),
+		gkeCreates:       gkeCreates,
+		estimateKey:      newEstimateKey(),
+		autopilot:        isAutopilot(detectCtx, detectDC),
+	}
+	go func() {
+		<-ctx.Done()
+		h.portForwards.stopAll()
+	}()
+
+	// Servers created for client credentials don't receive notifications:
+	// the subscription is read once, with the server's own identity.
+	if sub := c.NotificationsSubscription(); sub != "" && c.Credentials() == (config.Credentials{}) {
+		pubsubService, err := pubsub.NewService(ctx, gcpOpts...)
+		if err != nil {
+			return fmt.Errorf("failed to create pubsub service: %w", err)
+		}
+		h.notifications = newClusterNotifications(sub)
+		go h.notifications.run(ctx, pubsubService, s)
 	}
 
-	mcp.AddTool(s, &mcp.Tool{
+	// Like notifications, the background health checks run with the
+	// server's own identity only.
+	if interval := c.HealthCheckInterval(); interval > 0 && c.Credentials() == (config.Credentials{}) {
+		h.healthChecks = newBackgroundHealthChecks(interval, c.HealthChecks())
+		go h.healthChecks.run(ctx, h.healthCheckFuncs(), s)
+	}
+
+	if store := c.Alerts(); store != nil && c.Credentials() == (config.Credentials{}) {
+		forwardAlerts(ctx, store, s)
+	}
+
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "kube_get_resources",
 		Description: GetResourcesToolDescription,
 	}, h.getResources)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_export_manifest",
+		Description: ExportManifestToolDescription,
+	}, h.exportManifest)
+
+	// Snapshots are files written on the host of the server, so only their
+	// default file is available in read-only mode.
+	snapshotTool := &mcp.Tool{
+		Name:        "kube_snapshot",
+		Description: SnapshotToolDescription,
+		Annotations: createTool,
+	}
+	if !c.ReadOnly() {
+		middleware.AddTool(s, snapshotTool, h.snapshotTool)
+	} else {
+		middleware.AddTool(s, snapshotTool, h.snapshotReadOnlyTool)
+	}
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_get_field",
+		Description: GetFieldToolDescription,
+	}, h.getField)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_generate_manifest",
+		Description: GenerateManifestToolDescription,
+	}, h.generateManifest)
+
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "kube_api_resources",
 		Description: APIResourcesToolDescription,
 	}, h.apiResources)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "kube_get_pod_logs",
 		Description: GetPodLogsToolDescription,
 	}, h.getPodLogs)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "kube_describe",
 		Description: DescribeResourceToolDescription,
 	}, h.describeResource)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "kube_rollout_status",
 		Description: RolloutStatusToolDescription,
 	}, h.rolloutStatus)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "kube_top",
 		Description: TopToolDescription,
 	}, h.top)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "kube_get_componentstatuses",
 		Description: GetComponentStatusesToolDescription,
 	}, h.getComponentStatuses)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "kube_get_clusterinfo",
 		Description: GetClusterInfoToolDescription,
 	}, h.getClusterInfo)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_cluster_info",
+		Description: ClusterInfoToolDescription,
+	}, h.clusterInfo)
+
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "kube_can_i",
 		Description: CanIToolDescription,
 	}, h.canI)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_watch_resources",
+		Description: WatchResourcesToolDescription,
+	}, h.watchResources)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_rollout_watch",
+		Description: RolloutWatchToolDescription,
+	}, h.rolloutWatch)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_rollout_history",
+		Description: RolloutHistoryToolDescription,
+	}, h.rolloutHistory)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_node_diagnostics",
+		Description: NodeDiagnosticsToolDescription,
+	}, h.nodeDiagnostics)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_cluster_health",
+		Description: ClusterHealthToolDescription,
+	}, h.clusterHealth)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_find_orphans",
+		Description: FindOrphansToolDescription,
+	}, h.findOrphans)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_security_audit",
+		Description: SecurityAuditToolDescription,
+	}, h.securityAudit)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_webhook_diagnostics",
+		Description: WebhookDiagnosticsToolDescription,
+	}, h.webhookDiagnostics)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_cert_expiry_scan",
+		Description: CertExpiryScanToolDescription,
+	}, h.certExpiryScan)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_control_plane_probes",
+		Description: ControlPlaneProbesToolDescription,
+	}, h.controlPlaneProbes)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_upgrade_readiness",
+		Description: UpgradeReadinessToolDescription,
+	}, h.upgradeReadiness)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_events",
+		Description: EventsToolDescription,
+	}, h.events)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_events_timeline",
+		Description: EventsTimelineToolDescription,
+	}, h.eventsTimeline)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_preemption_analysis",
+		Description: PreemptionAnalysisToolDescription,
+	}, h.preemptionAnalysis)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_simulate_scheduling",
+		Description: SimulateSchedulingToolDescription,
+	}, h.simulateScheduling)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_pdb_report",
+		Description: PDBReportToolDescription,
+	}, h.pdbReport)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_job_report",
+		Description: JobReportToolDescription,
+	}, h.jobReport)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_statefulset_diagnostics",
+		Description: StatefulSetDiagnosticsToolDescription,
+	}, h.statefulSetDiagnostics)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_daemonset_coverage",
+		Description: DaemonSetCoverageToolDescription,
+	}, h.daemonSetCoverage)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_config_references",
+		Description: ConfigReferencesToolDescription,
+	}, h.configReferences)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_image_pull_check",
+		Description: ImagePullCheckToolDescription,
+	}, h.imagePullCheck)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_scrape_metrics",
+		Description: ScrapeMetricsToolDescription,
+	}, h.scrapeMetrics)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_port_forward_list",
+		Description: PortForwardListToolDescription,
+	}, h.portForwardList)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_port_forward_stop",
+		Description: PortForwardStopToolDescription,
+	}, h.portForwardStop)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_http_probe",
+		Description: HTTPProbeToolDescription,
+	}, h.httpProbe)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "kube_node_logs",
+		Description: NodeLogsToolDescription,
+	}, h.nodeLogs)
+
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "gke_read_logs",
 		Description: GKEReadLogsToolDescription,
 	}, h.queryLogs)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "gke_get_log_schema",
 		Description: GKEGetLogSchemaToolDescription,
 	}, h.getLogSchema)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "gke_audit_who_changed",
+		Description: GKEAuditWhoChangedToolDescription,
+	}, h.gkeAuditWhoChanged)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "gke_run_saved_query",
+		Description: GKERunSavedQueryToolDescription + savedQueriesCatalog(savedQueries),
+	}, h.gkeRunSavedQuery)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "gke_event_history",
+		Description: GKEEventHistoryToolDescription,
+	}, h.gkeEventHistory)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "gke_spot_interruptions",
+		Description: GKESpotInterruptionsToolDescription,
+	}, h.gkeSpotInterruptions)
+
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "gke_get_cluster",
 		Description: GKEGetClusterToolDescription,
 	}, h.gkeGetCluster)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "gke_export_cluster_config",
+		Description: GKEExportClusterConfigToolDescription,
+	}, h.gkeExportClusterConfig)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "gke_usage_report",
+		Description: GKEUsageReportToolDescription,
+	}, h.gkeUsageReport)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "gcp_check_quotas",
+		Description: GCPCheckQuotasToolDescription,
+	}, h.gcpCheckQuotas)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "gke_rightsize_node_pools",
+		Description: GKERightsizeNodePoolsToolDescription,
+	}, h.gkeRightsizeNodePools)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "gke_check_service_firewall",
+		Description: GKECheckServiceFirewallToolDescription,
+	}, h.gkeCheckServiceFirewall)
+
+	if h.notifications != nil {
+		middleware.AddTool(s, &mcp.Tool{
+			Name:        "gke_recent_notifications",
+			Description: GKERecentNotificationsToolDescription,
+		}, h.gkeRecentNotifications)
+	}
+
+	if h.healthChecks != nil {
+		middleware.AddTool(s, &mcp.Tool{
+			Name:        "kube_health_check_findings",
+			Description: HealthCheckFindingsToolDescription,
+		}, h.healthCheckFindings)
+	}
+
+	if c.Alerts() != nil {
+		middleware.AddTool(s, &mcp.Tool{
+			Name:        "alerts_list",
+			Description: AlertsListToolDescription,
+		}, h.alertsList)
+	}
+
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "gke_list_clusters",
 		Description: GKEListClustersToolDescription,
 	}, h.gkeListClusters)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "gke_get_operation",
 		Description: GKEGetOperationToolDescription,
 	}, h.gkeGetOperation)
 
 	if !c.ReadOnly() {
-		mcp.AddTool(s, &mcp.Tool{
+		applyResourceDescription, applyBundleDescription := ApplyResourceToolDescription, ApplyBundleToolDescription
+		if h.autopilot {
+			applyResourceDescription += autopilotToolNote
+			applyBundleDescription += autopilotToolNote
+		}
+		middleware.AddTool(s, &mcp.Tool{
 			Name:        "kube_apply_resource",
-			Description: ApplyResourceToolDescription,
+			Description: applyResourceDescription,
+			Annotations: writeTool,
 		}, h.applyResource)
-		mcp.AddTool(s, &mcp.Tool{
+		middleware.AddTool(s, &mcp.Tool{
 			Name:        "kube_delete_resource",
 			Description: DeleteResourceToolDescription,
+			Annotations: writeTool,
 		}, h.deleteResource)
 
-		mcp.AddTool(s, &mcp.Tool{
+		middleware.AddTool(s, &mcp.Tool{
 			Name:        "kube_patch_resource",
 			Description: PatchResourceToolDescription,
+			Annotations: writeTool,
 		}, h.patchResource)
 
+		middleware.AddTool(s, &mcp.Tool{
+			Name:        "kube_batch",
+			Description: BatchToolDescription,
+			Annotations: writeTool,
+		}, h.batch)
+
+		middleware.AddTool(s, &mcp.Tool{
+			Name:        "kube_undo_last_change",
+			Description: UndoLastChangeToolDescription,
+			Annotations: writeTool,
+		}, h.undoLastChange)
+
+		middleware.AddTool(s, &mcp.Tool{
+			Name:        "kube_apply_bundle",
+			Description: applyBundleDescription,
+			Annotations: writeTool,
+		}, h.applyBundle)
+
+		middleware.AddTool(s, &mcp.Tool{
+			Name:        "kube_exec",
+			Description: ExecToolDescription,
+			Annotations: writeTool,
+		}, h.exec)
+
+		// Port-forwards open the pods and services of the cluster to local
+		// clients, like kube_exec runs commands in them.
+		middleware.AddTool(s, &mcp.Tool{
+			Name:        "kube_port_forward_start",
+			Description: PortForwardStartToolDescription,
+			Annotations: writeTool,
+		}, h.portForwardStart)
+
+		// Namespaces and nodes are cluster-scoped.
+		if !c.NamespacedWritesOnly() {
+			middleware.AddTool(s, &mcp.Tool{
+				Name:        "kube_clone_namespace",
+				Description: CloneNamespaceToolDescription,
+				Annotations: createTool,
+			}, h.cloneNamespace)
+
+			middleware.AddTool(s, &mcp.Tool{
+				Name:        "kube_create_namespace",
+				Description: CreateNamespaceToolDescription,
+				Annotations: createTool,
+			}, h.createNamespace)
+
+			middleware.AddTool(s, &mcp.Tool{
+				Name:        "kube_delete_namespace",
+				Description: DeleteNamespaceToolDescription,
+				Annotations: writeTool,
+			}, h.deleteNamespace)
+
+			// Autopilot rejects the privileged pods of node debugging.
+			if c.AllowNodeDebug() && !h.autopilot {
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "kube_debug_node",
+					Description: DebugNodeToolDescription,
+					Annotations: createTool,
+				}, h.debugNode)
+			}
+		}
+
 		if ExtraTools {
-			mcp.AddTool(s, &mcp.Tool{
-				Name:        "gke_update_node_pool",
-				Description: GKEUpdateNodePoolToolDescription,
-			}, h.gkeUpdateNodePool)
-
-			mcp.AddTool(s, &mcp.Tool{
-				Name:        "gke_create_cluster",
-				Description: GKECreateClusterToolDescription,
-			}, h.gkeCreateCluster)
-
-			mcp.AddTool(s, &mcp.Tool{
-				Name:        "gke_update_cluster",
-				Description: GKEUpdateClusterToolDescription,
-			}, h.gkeUpdateCluster)
-
-			mcp.AddTool(s, &mcp.Tool{
-				Name:        "gke_delete_cluster",
-				Description: GKEDeleteClusterToolDescription,
-			}, h.gkeDeleteCluster)
-
-			mcp.AddTool(s, &mcp.Tool{
+			middleware.AddTool(s, &mcp.Tool{
 				Name:        "gke_fetch_cluster_upgrade_info",
 				Description: GKEFetchClusterUpgradeInfoToolDescription,
 			}, h.gkeFetchClusterUpgradeInfo)
 
-			mcp.AddTool(s, &mcp.Tool{
-				Name:        "gke_create_node_pool",
-				Description: GKECreateNodePoolToolDescription,
-			}, h.gkeCreateNodePool)
-
-			mcp.AddTool(s, &mcp.Tool{
-				Name:        "gke_update_master",
-				Description: GKEUpdateMasterToolDescription,
-			}, h.gkeUpdateMaster)
-
-			mcp.AddTool(s, &mcp.Tool{
-				Name:        "gke_start_ip_rotation",
-				Description: GKEStartIPRotationToolDescription,
-			}, h.gkeStartIPRotation)
-
-			mcp.AddTool(s, &mcp.Tool{
-				Name:        "gke_set_maintenance_policy",
-				Description: GKESetMaintenancePolicyToolDescription,
-			}, h.gkeSetMaintenancePolicy)
-
-			mcp.AddTool(s, &mcp.Tool{
+			middleware.AddTool(s, &mcp.Tool{
 				Name:        "gke_get_server_config",
 				Description: GKEGetServerConfigToolDescription,
 			}, h.gkeGetServerConfig)
 
-			mcp.AddTool(s, &mcp.Tool{
+			middleware.AddTool(s, &mcp.Tool{
 				Name:        "gke_get_open_id_config",
 				Description: GKEGetOpenIDConfigToolDescription,
 			}, h.gkeGetOpenIDConfig)
 
-			mcp.AddTool(s, &mcp.Tool{
+			middleware.AddTool(s, &mcp.Tool{
 				Name:        "gke_get_json_web_keys",
 				Description: GKEGetJSONWebKeysToolDescription,
 			}, h.gkeGetJSONWebKeys)
 
-			mcp.AddTool(s, &mcp.Tool{
+			middleware.AddTool(s, &mcp.Tool{
 				Name:        "gke_list_usable_subnetworks",
 				Description: GKEListUsableSubnetworksToolDescription,
 			}, h.gkeListUsableSubnetworks)
 
-			mcp.AddTool(s, &mcp.Tool{
+			middleware.AddTool(s, &mcp.Tool{
 				Name:        "gke_check_autopilot_compatibility",
 				Description: GKECheckAutopilotCompatibilityToolDescription,
 			}, h.gkeCheckAutopilotCompatibility)
 
-			mcp.AddTool(s, &mcp.Tool{
-				Name:        "gke_complete_convert_to_autopilot",
-				Description: GKECompleteConvertToAutopilotToolDescription,
-			}, h.gkeCompleteConvertToAutopilot)
-
-			mcp.AddTool(s, &mcp.Tool{
-				Name:        "gke_complete_control_plane_upgrade",
-				Description: GKECompleteControlPlaneUpgradeToolDescription,
-			}, h.gkeCompleteControlPlaneUpgrade)
+			// The GKE control plane is only changed with cluster-scoped writes.
+			if !c.NamespacedWritesOnly() {
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_update_node_pool",
+					Description: GKEUpdateNodePoolToolDescription,
+					Annotations: writeTool,
+				}, h.gkeUpdateNodePool)
+
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_create_cluster",
+					Description: GKECreateClusterToolDescription,
+					Annotations: createTool,
+				}, h.gkeCreateCluster)
+
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_update_cluster",
+					Description: GKEUpdateClusterToolDescription,
+					Annotations: writeTool,
+				}, h.gkeUpdateCluster)
+
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_enable_usage_metering",
+					Description: GKEEnableUsageMeteringToolDescription,
+					Annotations: writeTool,
+				}, h.gkeEnableUsageMetering)
+
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_delete_cluster",
+					Description: GKEDeleteClusterToolDescription,
+					Annotations: writeTool,
+				}, h.gkeDeleteCluster)
+
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_create_node_pool",
+					Description: GKECreateNodePoolToolDescription,
+					Annotations: createTool,
+				}, h.gkeCreateNodePool)
+
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_update_master",
+					Description: GKEUpdateMasterToolDescription,
+					Annotations: writeTool,
+				}, h.gkeUpdateMaster)
+
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_start_ip_rotation",
+					Description: GKEStartIPRotationToolDescription,
+					Annotations: writeTool,
+				}, h.gkeStartIPRotation)
+
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_set_maintenance_policy",
+					Description: GKESetMaintenancePolicyToolDescription,
+					Annotations: writeTool,
+				}, h.gkeSetMaintenancePolicy)
+
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_complete_convert_to_autopilot",
+					Description: GKECompleteConvertToAutopilotToolDescription,
+					Annotations: writeTool,
+				}, h.gkeCompleteConvertToAutopilot)
+
+				middleware.AddTool(s, &mcp.Tool{
+					Name:        "gke_complete_control_plane_upgrade",
+					Description: GKECompleteControlPlaneUpgradeToolDescription,
+					Annotations: writeTool,
+				}, h.gkeCompleteControlPlaneUpgrade)
+			}
 		}
 	}
 	return nil
 }
 
-func (h *handlers) gkeUpdateNodePool(ctx context.Context, _ *mcp.CallToolRequest, args *gkeUpdateNodePoolArgs) (*mcp.CallToolResult, any, error) {
-	return nil, nil, fmt.Errorf("tool not implemented: this tool is a placeholder. Stop execution and inform the user.")
-}
-
-func (h *handlers) gkeCreateNodePool(ctx context.Context, _ *mcp.CallToolRequest, args *gkeCreateNodePoolArgs) (*mcp.CallToolResult, any, error) {
-	return nil, nil, fmt.Errorf("tool not implemented: this tool is a placeholder. Stop execution and inform the user.")
-}
-
 func (h *handlers) gkeUpdateMaster(ctx context.Context, _ *mcp.CallToolRequest, args *gkeUpdateMasterArgs) (*mcp.CallToolResult, any, error) {
 	return nil, nil, fmt.Errorf("tool not implemented: this tool is a placeholder. Stop execution and inform the user.")
 }
@@ -1252,17 +1975,11 @@ func (h *handlers) gkeDeleteCluster(ctx context.Context, _ *mcp.CallToolRequest,
 	return nil, nil, fmt.Errorf("tool not implemented: this tool is a placeholder. Stop execution and inform the user.")
 }
 
-func (h *handlers) gkeUpdateCluster(ctx context.Context, _ *mcp.CallToolRequest, args *gkeUpdateClusterArgs) (*mcp.CallToolResult, any, error) {
-	return nil, nil, fmt.Errorf("tool not implemented: this tool is a placeholder. Stop execution and inform the user.")
-}
-
-func (h *handlers) gkeCreateCluster(ctx context.Context, _ *mcp.CallToolRequest, args *gkeCreateClusterArgs) (*mcp.CallToolResult, any, error) {
-	return nil, nil, fmt.Errorf("tool not implemented: this tool is a placeholder. Stop execution and inform the user.")
-}
-
 type gkeListClustersArgs struct {
-	ProjectID string `json:"project_id,omitempty"`
-	Location  string `json:"location,omitempty"`
+	ProjectID  string   `json:"project_id,omitempty"`
+	ProjectIDs []string `json:"project_ids,omitempty"`
+	Location   string   `json:"location,omitempty"`
+	Detail     bool     `json:"detail,omitempty"`
 }
 
 func (h *handlers) gkeGetOperation(ctx context.Context, _ *mcp.CallToolRequest, args *gkeGetOperationArgs) (*mcp.CallToolResult, any, error) {
@@ -1281,41 +1998,109 @@ func (h *handlers) gkeGetOperation(ctx context.Context, _ *mcp.CallToolRequest,
 	}, nil, nil
 }
 
+// maxConcurrentProjects bounds the projects that gke_list_clusters queries in
+// parallel.
+const maxConcurrentProjects = 8
+
 func (h *handlers) gkeListClusters(ctx context.Context, _ *mcp.CallToolRequest, args *gkeListClustersArgs) (*mcp.CallToolResult, any, error) {
-	projectID := args.ProjectID
-	if projectID == "" {
-		projectID = h.c.DefaultProjectID()
+	projectIDs := args.ProjectIDs
+	if args.ProjectID != "" {
+		projectIDs = append([]string{args.ProjectID}, projectIDs...)
 	}
+	if len(projectIDs) == 0 {
+		projectIDs = []string{h.c.DefaultProjectID()}
+	}
+	var unique []string
+	for _, projectID := range projectIDs {
+		if !slices.Contains(unique, projectID) {
+			unique = append(unique, projectID)
+		}
+	}
+	projectIDs = unique
 	location := args.Location
 	if location == "" {
 		location = "-"
 	}
-	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
-	resp, err := h.containerService.Projects.Locations.Clusters.List(parent).Context(ctx).Do()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to list clusters: %w", err)
+
+	type projectClusters struct {
+		resp *container.ListClustersResponse
+		err  error
 	}
-	b, err := json.Marshal(resp)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal clusters: %w", err)
+	results := make([]projectClusters, len(projectIDs))
+	var g errgroup.Group
+	g.SetLimit(maxConcurrentProjects)
+	for i, projectID := range projectIDs {
+		// The failures of projects are reported with their clusters.
+		g.Go(func() error {
+			parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
+			resp, err := h.containerService.Projects.Locations.Clusters.List(parent).Context(ctx).Do()
+			results[i] = projectClusters{resp: resp, err: err}
+			return nil
+		})
+	}
+	g.Wait()
+
+	if len(projectIDs) == 1 && results[0].err != nil {
+		return nil, nil, fmt.Errorf("failed to list clusters: %w", results[0].err)
+	}
+
+	var output strings.Builder
+	if args.Detail {
+		var detail any = results[0].resp
+		if len(projectIDs) > 1 {
+			byProject := make(map[string]any, len(projectIDs))
+			for i, projectID := range projectIDs {
+				if results[i].err != nil {
+					byProject[projectID] = map[string]string{"error": results[i].err.Error()}
+					continue
+				}
+				byProject[projectID] = results[i].resp
+			}
+			detail = byProject
+		}
+		b, err := json.Marshal(detail)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal clusters: %w", err)
+		}
+		output.Write(b)
+	} else {
+		output.WriteString("PROJECT\tNAME\tLOCATION\tVERSION\tSTATUS\tNODES\n")
+		for i, projectID := range projectIDs {
+			if results[i].err != nil {
+				continue
+			}
+			for _, cluster := range results[i].resp.Clusters {
+				output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%d\n",
+					projectID,
+					cluster.Name,
+					cluster.Location,
+					cluster.CurrentMasterVersion,
+					cluster.Status,
+					cluster.CurrentNodeCount,
+				))
+			}
+		}
+		for i, projectID := range projectIDs {
+			if results[i].err != nil {
+				output.WriteString(fmt.Sprintf("\nfailed to list clusters in project %q: %v\n", projectID, results[i].err))
+			}
+			if results[i].resp != nil && len(results[i].resp.MissingZones) > 0 {
+				output.WriteString(fmt.Sprintf("\nproject %q: could not reach zones %s\n", projectID, strings.Join(results[i].resp.MissingZones, ", ")))
+			}
+		}
 	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: string(b)},
+			&mcp.TextContent{Text: output.String()},
 		},
 	}, nil, nil
 }
 
 func (h *handlers) gkeGetCluster(ctx context.Context, _ *mcp.CallToolRequest, args *gkeGetClusterArgs) (*mcp.CallToolResult, any, error) {
-	projectID := args.ProjectID
-	if projectID == "" {
-		projectID = h.c.DefaultProjectID()
-	}
-	name := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, args.Location, args.Name)
-	cluster, err := h.containerService.Projects.Locations.Clusters.Get(name).Context(ctx).Do()
+	cluster, err := h.getCluster(ctx, args.ProjectID, args.Location, args.Name, args.Refresh)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get cluster: %w", err)
+		return nil, nil, err
 	}
 	b, err := json.Marshal(cluster)
 	if err != nil {
@@ -1436,12 +2221,21 @@ type canIArgs struct {
 }
 
 type queryLogsArgs struct {
-	Query     string                  `json:"query"`
-	ProjectID string                  `json:"project_id"`
-	Format    string                  `json:"format,omitempty"`
-	Limit     int                     `json:"limit,omitempty"`
-	Since     string                  `json:"since,omitempty"`
-	TimeRange *queryLogsTimeRangeArgs `json:"time_range,omitempty"`
+	Query       string                  `json:"query,omitempty"`
+	ProjectID   string                  `json:"project_id"`
+	Format      string                  `json:"format,omitempty"`
+	Limit       int                     `json:"limit,omitempty"`
+	Since       string                  `json:"since,omitempty"`
+	TimeRange   *queryLogsTimeRangeArgs `json:"time_range,omitempty"`
+	Summarize   bool                    `json:"summarize,omitempty"`
+	Verbose     bool                    `json:"verbose,omitempty"`
+	ClusterName string                  `json:"cluster_name,omitempty"`
+	Namespace   string                  `json:"namespace,omitempty"`
+	PodName     string                  `json:"pod_name,omitempty"`
+	Container   string                  `json:"container,omitempty"`
+	SeverityMin string                  `json:"severity_min,omitempty"`
+	Aggregate   string                  `json:"aggregate,omitempty"`
+	Interval    string                  `json:"interval,omitempty"`
 }
 
 type queryLogsTimeRangeArgs struct {
@@ -1453,93 +2247,342 @@ type getResourcesArgs struct {
 	Resource      string `json:"resource"`
 	Name          string `json:"name,omitempty"`
 	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
 	LabelSelector string `json:"labelSelector,omitempty"`
 	FieldSelector string `json:"fieldSelector,omitempty"`
 	CustomColumns string `json:"customColumns,omitempty"`
+	NoHeaders     bool   `json:"no_headers,omitempty"`
+	Output        string `json:"output,omitempty"`
+	OwnerKind     string `json:"owner_kind,omitempty"`
+	OwnerName     string `json:"owner_name,omitempty"`
 }
 
+// listChunkSize is the page size used when listing resources, so that large
+// collections are fetched and formatted incrementally.
+const listChunkSize = 500
+
 func (h *handlers) getResources(ctx context.Context, _ *mcp.CallToolRequest, args *getResourcesArgs) (*mcp.CallToolResult, any, error) {
 	gvr, err := h.findGVR(args.Resource)
 	if err != nil {
 		return nil, nil, err
 	}
-	var resources []unstructured.Unstructured
 
-	if args.Name != "" {
-		var obj *unstructured.Unstructured
-		var err error
-		if args.Namespace != "" {
-			obj, err = h.dyn.Resource(gvr).Namespace(args.Namespace).Get(ctx, args.Name, metav1.GetOptions{})
-		} else {
-			obj, err = h.dyn.Resource(gvr).Get(ctx, args.Name, metav1.GetOptions{})
+	namespaced, err := h.isNamespaced(gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if args.AllNamespaces && args.Name != "" {
+		return nil, nil, fmt.Errorf("all_namespaces cannot be combined with name")
+	}
+	if (args.OwnerKind == "") != (args.OwnerName == "") {
+		return nil, nil, fmt.Errorf("owner_kind and owner_name must be given together")
+	}
+	if args.OwnerKind != "" && (args.Name != "" || args.AllNamespaces) {
+		return nil, nil, fmt.Errorf("owner_kind cannot be combined with name or all_namespaces")
+	}
+	scope := clusterScope
+	ris := []dynamic.ResourceInterface{h.dyn.Resource(gvr)}
+	ownerNamespace := h.defaultNamespace
+	if namespaced && isNamespaceSet(args.Namespace) {
+		if args.AllNamespaces || args.Name != "" || args.OwnerKind != "" {
+			return nil, nil, fmt.Errorf("a list of namespaces cannot be combined with all_namespaces, name or owner_kind")
 		}
+		namespaces, err := h.matchNamespaces(ctx, args.Namespace)
 		if err != nil {
 			return nil, nil, err
 		}
-		resources = append(resources, *obj)
-	} else {
-		var list *unstructured.UnstructuredList
-		var err error
-		listOptions := metav1.ListOptions{}
-		if args.LabelSelector != "" {
-			listOptions.LabelSelector = args.LabelSelector
-		}
-		if args.FieldSelector != "" {
-			listOptions.FieldSelector = args.FieldSelector
-		}
-		if args.Namespace != "" {
-			list, err = h.dyn.Resource(gvr).Namespace(args.Namespace).List(ctx, listOptions)
-		} else {
-			list, err = h.dyn.Resource(gvr).List(ctx, listOptions)
+		scope = describeNamespaces(namespaces)
+		ris = nil
+		for _, namespace := range namespaces {
+			ris = append(ris, h.dyn.Resource(gvr).Namespace(namespace))
 		}
+	} else if namespaced {
+		namespace, err := h.namespaceScope(args.Namespace, args.AllNamespaces)
 		if err != nil {
 			return nil, nil, err
 		}
-		resources = list.Items
+		scope = describeScope(namespace)
+		ris = []dynamic.ResourceInterface{h.dyn.Resource(gvr).Namespace(namespace)}
+		ownerNamespace = namespace
 	}
-
-	if args.CustomColumns != "" {
-		customOutput, err := FmtCustomColumns(resources, args.CustomColumns)
-		if err != nil {
+	var owners *ownerFilter
+	if args.OwnerKind != "" {
+		if owners, err = h.newOwnerFilter(ctx, args.OwnerKind, args.OwnerName, ownerNamespace); err != nil {
 			return nil, nil, err
 		}
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: customOutput},
-			},
-		}, nil, nil
+		scope += fmt.Sprintf(", owned by %s %s", args.OwnerKind, args.OwnerName)
 	}
 
-	var yamlDocs []string
-	for _, item := range resources {
-		// Convert Unstructured to JSON
-		itemJsonData, err := json.Marshal(item.Object)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to marshal resource to JSON: %w", err)
+	var output strings.Builder
+	write := writeYAMLDocument
+	printer, err := newResourcePrinter(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if printer != nil {
+		write = func(_ *strings.Builder, obj *unstructured.Unstructured) error {
+			return printer.add(obj)
 		}
+	}
+	writeRedacted := func(out *strings.Builder, obj *unstructured.Unstructured) error {
+		return write(out, h.redact(obj))
+	}
 
-		// Convert JSON to YAML
-		yamlData, err := yaml.JSONToYAML(itemJsonData)
+	if args.Name != "" {
+		obj, err := ris[0].Get(ctx, args.Name, metav1.GetOptions{})
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to convert JSON to YAML: %w", err)
+			return nil, nil, err
+		}
+		if err := writeRedacted(&output, obj); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		for _, ri := range ris {
+			listOptions := metav1.ListOptions{
+				LabelSelector: args.LabelSelector,
+				FieldSelector: args.FieldSelector,
+				Limit:         listChunkSize,
+			}
+			for {
+				list, err := ri.List(ctx, listOptions)
+				if err != nil {
+					return nil, nil, err
+				}
+				for i := range list.Items {
+					if owners != nil && !owners.owned(ctx, &list.Items[i]) {
+						continue
+					}
+					if err := writeRedacted(&output, &list.Items[i]); err != nil {
+						return nil, nil, err
+					}
+				}
+				listOptions.Continue = list.GetContinue()
+				if listOptions.Continue == "" {
+					break
+				}
+			}
+		}
+	}
+	if printer != nil {
+		if err := printer.flush(&output); err != nil {
+			return nil, nil, err
 		}
-		yamlDocs = append(yamlDocs, string(yamlData))
 	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: strings.Join(yamlDocs, "---\n")},
+			&mcp.TextContent{Text: output.String()},
+			&mcp.TextContent{Text: "Scope: " + scope},
 		},
 	}, nil, nil
 }
 
+// clusterScope describes the scope of requests for cluster-scoped resources.
+const clusterScope = "cluster"
+
+// namespaceScope returns the namespace to list resources from: namespace if
+// given, all namespaces (the empty string) if allNamespaces is set, and the
+// default namespace otherwise.
+func (h *handlers) namespaceScope(namespace string, allNamespaces bool) (string, error) {
+	switch {
+	case allNamespaces && namespace != "":
+		return "", fmt.Errorf("namespace and all_namespaces cannot be combined")
+	case allNamespaces:
+		return metav1.NamespaceAll, nil
+	case namespace != "":
+		return namespace, nil
+	default:
+		return h.defaultNamespace, nil
+	}
+}
+
+// describeScope describes the namespace scope returned by namespaceScope.
+func describeScope(namespace string) string {
+	if namespace == metav1.NamespaceAll {
+		return "all namespaces"
+	}
+	return fmt.Sprintf("namespace %q", namespace)
+}
+
+// isNamespaceSet reports whether the namespace argument names a set of
+// namespaces for matchNamespaces, rather than a single namespace.
+func isNamespaceSet(namespace string) bool {
+	return strings.Contains(namespace, ",") || (namespace != "" && len(validation.IsDNS1123Label(namespace)) > 0)
+}
+
+// matchNamespaces returns the sorted namespaces matching set, a
+// comma-separated list of namespace names and regular expressions. A regular
+// expression, any entry that isn't a valid namespace name, must match the
+// whole name of an existing namespace.
+func (h *handlers) matchNamespaces(ctx context.Context, set string) ([]string, error) {
+	var names []string
+	var patterns []*regexp.Regexp
+	for entry := range strings.SplitSeq(set, ",") {
+		entry = strings.TrimSpace(entry)
+		switch {
+		case entry == "":
+		case len(validation.IsDNS1123Label(entry)) == 0:
+			names = append(names, entry)
+		default:
+			re, err := regexp.Compile("^(?:" + entry + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid namespace pattern %q: %w", entry, err)
+			}
+			patterns = append(patterns, re)
+		}
+	}
+	if len(patterns) > 0 {
+		list, err := h.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		for _, ns := range list.Items {
+			if slices.ContainsFunc(patterns, func(re *regexp.Regexp) bool { return re.MatchString(ns.Name) }) {
+				names = append(names, ns.Name)
+			}
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no namespace matches %q", set)
+	}
+	return names, nil
+}
+
+// describeNamespaces describes the namespaces returned by matchNamespaces.
+func describeNamespaces(namespaces []string) string {
+	quoted := make([]string, len(namespaces))
+	for i, namespace := range namespaces {
+		quoted[i] = strconv.Quote(namespace)
+	}
+	return "namespaces " + strings.Join(quoted, ", ")
+}
+
+// isNamespaced reports whether gvr is a namespaced resource.
+func (h *handlers) isNamespaced(gvr schema.GroupVersionResource) (bool, error) {
+	gvk, err := h.mapper.KindFor(gvr)
+	if err != nil {
+		return false, fmt.Errorf("failed to get kind for %s: %w", gvr, err)
+	}
+	mapping, err := h.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return false, fmt.Errorf("failed to get REST mapping: %w", err)
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// checkWriteScope returns an error if the resource name of gvr can't be
+// changed because it's cluster-scoped, and the configuration only allows
+// changes of namespaced resources.
+func (h *handlers) checkWriteScope(gvr schema.GroupVersionResource, name string) error {
+	if !h.c.NamespacedWritesOnly() {
+		return nil
+	}
+	namespaced, err := h.isNamespaced(gvr)
+	if err != nil {
+		return err
+	}
+	if !namespaced {
+		return clusterScopedWriteError(gvr.GroupResource().String(), name)
+	}
+	return nil
+}
+
+// checkProtectedNamespace returns an error if the resources of namespace
+// can't be changed because it's protected, such as kube-system.
+func (h *handlers) checkProtectedNamespace(namespace string) error {
+	if namespace != "" && h.c.ProtectedNamespace(namespace) {
+		return fmt.Errorf("namespace %s is protected: the server doesn't change the resources of system namespaces, unless started with --unsafe-allow-system-namespaces. Don't work around the protection: give the user the commands to run if the change is really needed", namespace)
+	}
+	return nil
+}
+
+// checkWriteNamespace returns an error if the resource name of gvr in
+// namespace can't be changed because the namespace is protected, or because
+// the resource is a protected namespace itself.
+func (h *handlers) checkWriteNamespace(gvr schema.GroupVersionResource, namespace, name string) error {
+	if gvr.Group == "" && gvr.Resource == "namespaces" {
+		return h.checkProtectedNamespace(name)
+	}
+	return h.checkProtectedNamespace(namespace)
+}
+
+// clusterScopedWriteError returns the error of a change of the cluster-scoped
+// resource name, refused by the configuration.
+func clusterScopedWriteError(resource, name string) error {
+	return fmt.Errorf("%s %q is cluster-scoped, and the server only allows changes of namespaced resources: ask a cluster administrator to make this change", resource, name)
+}
+
+// writeYAMLDocument appends obj to out as a YAML document, prefixed with a
+// document separator if out already holds other documents.
+func writeYAMLDocument(out *strings.Builder, obj *unstructured.Unstructured) error {
+	// Convert Unstructured to JSON
+	jsonData, err := json.Marshal(obj.Object)
+	if err != nil {
+		return fmt.Errorf("failed to marshal resource to JSON: %w", err)
+	}
+
+	// Convert JSON to YAML
+	yamlData, err := yaml.JSONToYAML(jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to convert JSON to YAML: %w", err)
+	}
+	if out.Len() > 0 {
+		out.WriteString("---\n")
+	}
+	out.Write(yamlData)
+	return nil
+}
+
 type applyResourceArgs struct {
-	Manifest string `json:"manifest"`
+	Manifest    string `json:"manifest,omitempty"`
+	ManifestURL string `json:"manifest_url,omitempty"`
+	SHA256      string `json:"sha256,omitempty"`
+	Force       bool   `json:"force,omitempty"`
+}
+
+// applyConflictError describes the fields of a failed server-side apply that
+// are owned by other field managers.
+func applyConflictError(kind, name string, err error) error {
+	var statusErr *apierrors.StatusError
+	if !errors.As(err, &statusErr) || statusErr.ErrStatus.Details == nil {
+		return err
+	}
+	var conflicts []string
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			conflicts = append(conflicts, "- "+cause.Message)
+		}
+	}
+	if len(conflicts) == 0 {
+		return err
+	}
+	return fmt.Errorf("applying %s %q conflicts with fields owned by other field managers:\n%s\nRetry with force set to true to take ownership of these fields", kind, name, strings.Join(conflicts, "\n"))
 }
 
-func (h *handlers) applyResource(ctx context.Context, _ *mcp.CallToolRequest, args *applyResourceArgs) (*mcp.CallToolResult, any, error) {
+func (h *handlers) applyResource(ctx context.Context, req *mcp.CallToolRequest, args *applyResourceArgs) (*mcp.CallToolResult, any, error) {
+	switch {
+	case args.Manifest != "" && args.ManifestURL != "":
+		return nil, nil, fmt.Errorf("manifest and manifest_url are mutually exclusive")
+	case args.ManifestURL != "":
+		manifest, err := fetchManifest(ctx, http.DefaultClient, args.ManifestURL, args.SHA256)
+		if err != nil {
+			return nil, nil, err
+		}
+		args.Manifest = manifest
+	case args.Manifest == "":
+		return nil, nil, fmt.Errorf("manifest or manifest_url is required")
+	}
+
 	yamlParts := strings.Split(args.Manifest, "---")
 	var appliedYamls []string
+	// The objects applied before a failure are recorded as well.
+	var changed []undoObject
+	defer func() { h.undo.forSession(req).record("kube_apply_resource", changed) }()
+	// autopilotNotes are the settings of the objects that GKE Autopilot
+	// rejects or changes.
+	var autopilotNotes []string
 
 	for _, part := range yamlParts {
 		part = strings.TrimSpace(part)
@@ -1567,20 +2610,51 @@ func (h *handlers) applyResource(ctx context.Context, _ *mcp.CallToolRequest, ar
 		gvr := mapping.Resource
 		namespace := obj.GetNamespace()
 		name := obj.GetName()
+		if h.c.NamespacedWritesOnly() && mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			return nil, nil, clusterScopedWriteError(gvr.GroupResource().String(), name)
+		}
+		if err := h.checkWriteNamespace(gvr, namespace, name); err != nil {
+			return nil, nil, err
+		}
 
-		var appliedObj *unstructured.Unstructured
-		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
-			appliedObj, err = h.dyn.Resource(gvr).Namespace(namespace).Apply(ctx, name, &obj, metav1.ApplyOptions{FieldManager: "kubeapi-mcp", Force: true})
-		} else {
-			appliedObj, err = h.dyn.Resource(gvr).Apply(ctx, name, &obj, metav1.ApplyOptions{FieldManager: "kubeapi-mcp", Force: true})
+		opts := metav1.ApplyOptions{FieldManager: h.c.FieldManager(), Force: args.Force}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			namespace = ""
+		}
+		ri := h.resourceInterface(gvr, namespace)
+		// The prior state of the object is recorded for kube_undo_last_change,
+		// unless it can't be read.
+		prior, priorErr := ri.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(priorErr) {
+			prior, priorErr = nil, nil
+		}
+		var warnings []string
+		if h.autopilot {
+			warnings = autopilotWarnings(&obj)
 		}
+		appliedObj, err := ri.Apply(ctx, name, &obj, opts)
 
+		if apierrors.IsConflict(err) {
+			return nil, nil, applyConflictError(gvk.Kind, name, err)
+		}
+		if err != nil && len(warnings) > 0 {
+			return nil, nil, fmt.Errorf("%w\nThe cluster is a GKE Autopilot cluster:\n- %s", err, strings.Join(warnings, "\n- "))
+		}
 		if err != nil {
 			return nil, nil, err
 		}
+		if h.autopilot {
+			if adjustment := resourceAdjustment(appliedObj); adjustment != "" {
+				warnings = append(warnings, adjustment)
+			}
+			autopilotNotes = append(autopilotNotes, warnings...)
+		}
+		if priorErr == nil {
+			changed = append(changed, undoObject{gvr: gvr, namespace: namespace, name: name, prior: prior, resourceVersion: appliedObj.GetResourceVersion()})
+		}
 
 		// Convert Unstructured to JSON for YAML conversion
-		appliedJson, err := json.Marshal(appliedObj.Object)
+		appliedJson, err := json.Marshal(h.redact(appliedObj).Object)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to marshal resource to JSON: %w", err)
 		}
@@ -1593,11 +2667,13 @@ func (h *handlers) applyResource(ctx context.Context, _ *mcp.CallToolRequest, ar
 		appliedYamls = append(appliedYamls, string(yamlData))
 	}
 
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: strings.Join(appliedYamls, "---\n")},
-		},
-	}, nil, nil
+	content := []mcp.Content{
+		&mcp.TextContent{Text: strings.Join(appliedYamls, "---\n")},
+	}
+	if len(autopilotNotes) > 0 {
+		content = append(content, &mcp.TextContent{Text: "GKE Autopilot:\n- " + strings.Join(autopilotNotes, "\n- ") + "\n"})
+	}
+	return &mcp.CallToolResult{Content: content}, nil, nil
 }
 
 type deleteResourceArgs struct {
@@ -1606,19 +2682,27 @@ type deleteResourceArgs struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
-func (h *handlers) deleteResource(ctx context.Context, _ *mcp.CallToolRequest, args *deleteResourceArgs) (*mcp.CallToolResult, any, error) {
+func (h *handlers) deleteResource(ctx context.Context, req *mcp.CallToolRequest, args *deleteResourceArgs) (*mcp.CallToolResult, any, error) {
 	gvr, err := h.findGVR(args.Resource)
 	if err != nil {
 		return nil, nil, err
 	}
-	if args.Namespace != "" {
-		err = h.dyn.Resource(gvr).Namespace(args.Namespace).Delete(ctx, args.Name, metav1.DeleteOptions{})
-	} else {
-		err = h.dyn.Resource(gvr).Delete(ctx, args.Name, metav1.DeleteOptions{})
+	if err := h.checkWriteScope(gvr, args.Name); err != nil {
+		return nil, nil, err
 	}
-	if err != nil {
+	if err := h.checkWriteNamespace(gvr, args.Namespace, args.Name); err != nil {
+		return nil, nil, err
+	}
+	ri := h.resourceInterface(gvr, args.Namespace)
+	// The prior state of the object is recorded for kube_undo_last_change,
+	// unless it can't be read.
+	prior, priorErr := ri.Get(ctx, args.Name, metav1.GetOptions{})
+	if err := ri.Delete(ctx, args.Name, metav1.DeleteOptions{}); err != nil {
 		return nil, nil, err
 	}
+	if priorErr == nil {
+		h.undo.forSession(req).record("kube_delete_resource", []undoObject{{gvr: gvr, namespace: args.Namespace, name: args.Name, prior: prior}})
+	}
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: fmt.Sprintf("Resource %s/%s deleted.", args.Resource, args.Name)},
@@ -1626,40 +2710,120 @@ func (h *handlers) deleteResource(ctx context.Context, _ *mcp.CallToolRequest, a
 	}, nil, nil
 }
 
-type apiResourcesArgs struct{}
+type apiResourcesArgs struct {
+	APIGroup   string `json:"api_group,omitempty"`
+	Namespaced *bool  `json:"namespaced,omitempty"`
+	Verbs      string `json:"verbs,omitempty"`
+	Refresh    bool   `json:"refresh,omitempty"`
+}
 
-func (h *handlers) apiResources(ctx context.Context, _ *mcp.CallToolRequest, args *apiResourcesArgs) (*mcp.CallToolResult, any, error) {
-	_, resourceLists, err := h.dc.ServerGroupsAndResources()
-	if err != nil {
-		if _, ok := err.(*discovery.ErrGroupDiscoveryFailed); !ok {
-			return nil, nil, fmt.Errorf("failed to get server groups and resources: %w", err)
+// apiResource is an API resource, as returned in the structured output of
+// kube_api_resources.
+type apiResource struct {
+	Name       string   `json:"name"`
+	ShortNames []string `json:"shortNames,omitempty"`
+	APIVersion string   `json:"apiVersion"`
+	Namespaced bool     `json:"namespaced"`
+	Kind       string   `json:"kind"`
+	Verbs      []string `json:"verbs"`
+}
+
+// apiResourcesOutput is the structured output of kube_api_resources.
+type apiResourcesOutput struct {
+	Resources []apiResource `json:"resources"`
+}
+
+// filterAPIResources returns the resources of resourceLists matching the
+// filters of args.
+func filterAPIResources(resourceLists []*metav1.APIResourceList, args *apiResourcesArgs) []apiResource {
+	group := args.APIGroup
+	if group == "core" {
+		group = ""
+	}
+	var verbs []string
+	for _, v := range strings.Split(args.Verbs, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			verbs = append(verbs, v)
 		}
 	}
 
-	var output strings.Builder
-	output.WriteString("NAME\tSHORTNAMES\tAPIVERSION\tNAMESPACED\tKIND\n")
-
+	resources := []apiResource{}
 	for _, list := range resourceLists {
 		gv, err := schema.ParseGroupVersion(list.GroupVersion)
 		if err != nil {
 			continue
 		}
+		if args.APIGroup != "" && gv.Group != group {
+			continue
+		}
 		for _, resource := range list.APIResources {
-			output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%t\t%s\n",
-				resource.Name,
-				strings.Join(resource.ShortNames, ","),
-				gv.String(),
-				resource.Namespaced,
-				resource.Kind,
-			))
+			if args.Namespaced != nil && resource.Namespaced != *args.Namespaced {
+				continue
+			}
+			if !supportsVerbs(resource.Verbs, verbs) {
+				continue
+			}
+			resources = append(resources, apiResource{
+				Name:       resource.Name,
+				ShortNames: resource.ShortNames,
+				APIVersion: gv.String(),
+				Namespaced: resource.Namespaced,
+				Kind:       resource.Kind,
+				Verbs:      resource.Verbs,
+			})
 		}
 	}
+	return resources
+}
+
+// supportsVerbs returns whether supported contains all the verbs.
+func supportsVerbs(supported []string, verbs []string) bool {
+	for _, v := range verbs {
+		if !slices.Contains(supported, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *handlers) apiResources(ctx context.Context, _ *mcp.CallToolRequest, args *apiResourcesArgs) (*mcp.CallToolResult, any, error) {
+	resourceLists, err := cache.GetOrLoad(h.cache, "discovery:groups-and-resources", args.Refresh, func() ([]*metav1.APIResourceList, error) {
+		_, resourceLists, err := h.dc.ServerGroupsAndResources()
+		if err != nil {
+			if _, ok := err.(*discovery.ErrGroupDiscoveryFailed); !ok {
+				return nil, err
+			}
+		}
+		return resourceLists, nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get server groups and resources: %w", err)
+	}
+	if args.Refresh {
+		// Also drop the preferred resources used to resolve resource kinds so
+		// that newly installed CRDs become visible to the other tools.
+		h.cache.Delete(preferredResourcesCacheKey)
+	}
+
+	resources := filterAPIResources(resourceLists, args)
+	var output strings.Builder
+	output.WriteString("NAME\tSHORTNAMES\tAPIVERSION\tNAMESPACED\tKIND\tVERBS\n")
+	for _, resource := range resources {
+		output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%t\t%s\t%s\n",
+			resource.Name,
+			strings.Join(resource.ShortNames, ","),
+			resource.APIVersion,
+			resource.Namespaced,
+			resource.Kind,
+			strings.Join(resource.Verbs, ","),
+		))
+	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: output.String()},
 		},
-	}, nil, nil
+	}, &apiResourcesOutput{Resources: resources}, nil
 }
 
 type getPodLogsArgs struct {
@@ -1667,6 +2831,7 @@ type getPodLogsArgs struct {
 	Namespace string `json:"namespace"`
 	Container string `json:"container,omitempty"`
 	Previous  bool   `json:"previous,omitempty"`
+	Summarize bool   `json:"summarize,omitempty"`
 }
 
 type describeResourceArgs struct {
@@ -1682,8 +2847,9 @@ type rolloutStatusArgs struct {
 }
 
 type topArgs struct {
-	Resource  string `json:"resource"`
-	Namespace string `json:"namespace,omitempty"`
+	Resource      string `json:"resource"`
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
 }
 
 type getComponentStatusesArgs struct{}
@@ -1692,7 +2858,7 @@ type getClusterInfoArgs struct {
 	Dump bool `json:"dump,omitempty"`
 }
 
-func (h *handlers) getPodLogs(ctx context.Context, _ *mcp.CallToolRequest, args *getPodLogsArgs) (*mcp.CallToolResult, any, error) {
+func (h *handlers) getPodLogs(ctx context.Context, toolReq *mcp.CallToolRequest, args *getPodLogsArgs) (*mcp.CallToolResult, any, error) {
 	podLogOpts := &corev1.PodLogOptions{
 		Container: args.Container,
 		Previous:  args.Previous,
@@ -1710,6 +2876,16 @@ func (h *handlers) getPodLogs(ctx context.Context, _ *mcp.CallToolRequest, args
 		return nil, nil, fmt.Errorf("failed to read pod logs: %w", err)
 	}
 	logs := buf.String()
+	if args.Summarize {
+		what := fmt.Sprintf("logs of pod %s/%s", args.Namespace, args.Name)
+		if args.Container != "" {
+			what += ", container " + args.Container
+		}
+		logs, err = summarizeLogs(ctx, toolReq, what, logs)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -1722,12 +2898,7 @@ func (h *handlers) getClusterInfo(ctx context.Context, _ *mcp.CallToolRequest, a
 	var output strings.Builder
 
 	// Get cluster endpoint
-	restConfig, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load kubeconfig: %w", err)
-	}
-	clusterName := restConfig.Contexts[restConfig.CurrentContext].Cluster
-	output.WriteString(fmt.Sprintf("Kubernetes control plane is running at %s\n", restConfig.Clusters[clusterName].Server))
+	output.WriteString(fmt.Sprintf("Kubernetes control plane is running at %s\n", h.restConfig.Host))
 
 	// Get services with label kubernetes.io/cluster-service=true
 	services, err := h.clientset.CoreV1().Services("kube-system").List(ctx, metav1.ListOptions{LabelSelector: "kubernetes.io/cluster-service=true"})
@@ -1801,6 +2972,7 @@ func (h *handlers) getComponentStatuses(ctx context.Context, _ *mcp.CallToolRequ
 
 func (h *handlers) top(ctx context.Context, _ *mcp.CallToolRequest, args *topArgs) (*mcp.CallToolResult, any, error) {
 	var output strings.Builder
+	scope := clusterScope
 	switch args.Resource {
 	case "nodes", "node":
 		nodeMetrics, err := h.metricsClientset.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
@@ -1817,10 +2989,18 @@ func (h *handlers) top(ctx context.Context, _ *mcp.CallToolRequest, args *topArg
 			))
 		}
 	case "pods", "pod":
-		podMetrics, err := h.metricsClientset.MetricsV1beta1().PodMetricses(args.Namespace).List(ctx, metav1.ListOptions{})
+		namespace, err := h.namespaceScope(args.Namespace, args.AllNamespaces)
+		if err != nil {
+			return nil, nil, err
+		}
+		scope = describeScope(namespace)
+		podMetrics, err := h.metricsClientset.MetricsV1beta1().PodMetricses(namespace).List(ctx, metav1.ListOptions{})
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to get pod metrics: %w", err)
 		}
+		if namespace == metav1.NamespaceAll {
+			output.WriteString("NAMESPACE\t")
+		}
 		output.WriteString("NAME\tCPU(cores)\tMEMORY(bytes)\n")
 		for _, item := range podMetrics.Items {
 			var cpuTotal int64
@@ -1829,6 +3009,9 @@ func (h *handlers) top(ctx context.Context, _ *mcp.CallToolRequest, args *topArg
 				cpuTotal += cont.Usage.Cpu().MilliValue()
 				memTotal += cont.Usage.Memory().Value()
 			}
+			if namespace == metav1.NamespaceAll {
+				output.WriteString(item.Namespace + "\t")
+			}
 			output.WriteString(fmt.Sprintf("%s\t%dm\t%d\n",
 				item.Name,
 				cpuTotal,
@@ -1842,6 +3025,7 @@ func (h *handlers) top(ctx context.Context, _ *mcp.CallToolRequest, args *topArg
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: output.String()},
+			&mcp.TextContent{Text: "Scope: " + scope},
 		},
 	}, nil, nil
 }
@@ -1922,94 +3106,26 @@ func (h *handlers) rolloutStatus(ctx context.Context, _ *mcp.CallToolRequest, ar
 	}, nil, nil
 }
 
-func (h *handlers) describeResource(ctx context.Context, _ *mcp.CallToolRequest, args *describeResourceArgs) (*mcp.CallToolResult, any, error) {
-	gvr, err := h.findGVR(args.Resource)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	var obj *unstructured.Unstructured
-	if args.Namespace != "" {
-		obj, err = h.dyn.Resource(gvr).Namespace(args.Namespace).Get(ctx, args.Name, metav1.GetOptions{})
-	} else {
-		obj, err = h.dyn.Resource(gvr).Get(ctx, args.Name, metav1.GetOptions{})
-	}
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get resource: %w", err)
-	}
-
-	// Get events for the resource
-	events, err := h.clientset.CoreV1().Events(obj.GetNamespace()).Search(scheme.Scheme, obj)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get events for resource: %w", err)
-	}
-
-	var output strings.Builder
-	output.WriteString(fmt.Sprintf("Name: %s\n", obj.GetName()))
-	output.WriteString(fmt.Sprintf("Namespace: %s\n", obj.GetNamespace()))
-	output.WriteString(fmt.Sprintf("Kind: %s\n", obj.GetKind()))
-	output.WriteString(fmt.Sprintf("APIVersion: %s\n", obj.GetAPIVersion()))
-	output.WriteString("\n")
-
-	// Add more details from the object spec and status
-	spec, ok := obj.Object["spec"].(map[string]interface{})
-	if ok {
-		specYAML, err := yaml.Marshal(spec)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to marshal spec to YAML: %w", err)
-		}
-		output.WriteString("Spec:\n")
-		output.WriteString(string(specYAML))
-		output.WriteString("\n")
-	}
-
-	status, ok := obj.Object["status"].(map[string]interface{})
-	if ok {
-		statusYAML, err := yaml.Marshal(status)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to marshal status to YAML: %w", err)
-		}
-		output.WriteString("Status:\n")
-		output.WriteString(string(statusYAML))
-		output.WriteString("\n")
-	}
-
-	// Add events
-	if len(events.Items) > 0 {
-		output.WriteString("Events:\n")
-		output.WriteString("  Type\tReason\tAge\tFrom\tMessage\n")
-		output.WriteString("  ----\t------\t---\t----\t-------\n")
-		for _, e := range events.Items {
-			output.WriteString(fmt.Sprintf("  %s\t%s\t%s\t%s\t%s\n",
-				e.Type,
-				e.Reason,
-				time.Since(e.LastTimestamp.Time).Truncate(time.Second).String(),
-				e.Source.Component,
-				e.Message,
-			))
-		}
-	}
-
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			&mcp.TextContent{Text: output.String()},
-		},
-	}, nil, nil
-}
-
 type patchResourceArgs struct {
-	Resource  string `json:"resource"`
-	Name      string `json:"name"`
-	Namespace string `json:"namespace,omitempty"`
-	Patch     string `json:"patch"`
-	PatchType string `json:"patchType,omitempty"`
+	Resource   string `json:"resource"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Patch      string `json:"patch"`
+	PatchType  string `json:"patchType,omitempty"`
+	ShowObject bool   `json:"show_object,omitempty"`
 }
 
-func (h *handlers) patchResource(ctx context.Context, _ *mcp.CallToolRequest, args *patchResourceArgs) (*mcp.CallToolResult, any, error) {
+func (h *handlers) patchResource(ctx context.Context, req *mcp.CallToolRequest, args *patchResourceArgs) (*mcp.CallToolResult, any, error) {
 	gvr, err := h.findGVR(args.Resource)
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := h.checkWriteScope(gvr, args.Name); err != nil {
+		return nil, nil, err
+	}
+	if err := h.checkWriteNamespace(gvr, args.Namespace, args.Name); err != nil {
+		return nil, nil, err
+	}
 
 	patchType := types.StrategicMergePatchType
 	switch args.PatchType {
@@ -2031,37 +3147,89 @@ func (h *handlers) patchResource(ctx context.Context, _ *mcp.CallToolRequest, ar
 		return nil, nil, fmt.Errorf("failed to convert patch from YAML to JSON: %w", err)
 	}
 
-	var patchedObj *unstructured.Unstructured
+	var ri dynamic.ResourceInterface = h.dyn.Resource(gvr)
 	if args.Namespace != "" {
-		patchedObj, err = h.dyn.Resource(gvr).Namespace(args.Namespace).Patch(ctx, args.Name, patchType, patchBytes, metav1.PatchOptions{})
-	} else {
-		patchedObj, err = h.dyn.Resource(gvr).Patch(ctx, args.Name, patchType, patchBytes, metav1.PatchOptions{})
+		ri = h.dyn.Resource(gvr).Namespace(args.Namespace)
 	}
+	// The object before the patch is only used to report the changes.
+	obj, err := ri.Get(ctx, args.Name, metav1.GetOptions{})
 	if err != nil {
 		return nil, nil, err
 	}
-
-	// Convert Unstructured to JSON for YAML conversion
-	jsonData, err := json.Marshal(patchedObj.Object)
+	patchedObj, err := ri.Patch(ctx, args.Name, patchType, patchBytes, metav1.PatchOptions{})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal resource to JSON: %w", err)
+		return nil, nil, err
 	}
+	h.undo.forSession(req).record("kube_patch_resource", []undoObject{{gvr: gvr, namespace: args.Namespace, name: args.Name, prior: obj, resourceVersion: patchedObj.GetResourceVersion()}})
 
-	// Convert JSON to YAML
-	yamlData, err := yaml.JSONToYAML(jsonData)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to convert JSON to YAML: %w", err)
+	var output strings.Builder
+	changes := fieldDiff(patchedFields(obj), patchedFields(patchedObj), h.redactedPaths(obj, patchedObj))
+	if len(changes) == 0 {
+		output.WriteString(fmt.Sprintf("Patched %s/%s: no changes.\n", args.Resource, args.Name))
+	} else {
+		output.WriteString(fmt.Sprintf("Patched %s/%s, changed fields:\n", args.Resource, args.Name))
+		for _, c := range changes {
+			output.WriteString(c + "\n")
+		}
+	}
+	if args.ShowObject {
+		output.WriteString("\n")
+		if err := writeYAMLDocument(&output, h.redact(patchedObj)); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: string(yamlData)},
+			&mcp.TextContent{Text: output.String()},
 		},
 	}, nil, nil
 }
 
-func (h *handlers) queryLogs(ctx context.Context, _ *mcp.CallToolRequest, args *queryLogsArgs) (*mcp.CallToolResult, any, error) {
-	filter := args.Query
+// patchedFields returns the fields of obj compared by kube_patch_resource,
+// without the fields updated by every write.
+func patchedFields(obj *unstructured.Unstructured) map[string]any {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "resourceVersion")
+	return obj.Object
+}
+
+// logSeverities are the Cloud Logging severities, in increasing order.
+var logSeverities = []string{"DEFAULT", "DEBUG", "INFO", "NOTICE", "WARNING", "ERROR", "CRITICAL", "ALERT", "EMERGENCY"}
+
+// logFilter returns the Cloud Logging filter of args: the raw query, if any,
+// and the convenience filters on resource labels and severity.
+func logFilter(args *queryLogsArgs) (string, error) {
+	var clauses []string
+	if q := strings.TrimSpace(args.Query); q != "" {
+		clauses = append(clauses, "("+q+")")
+	}
+	for _, label := range []struct{ name, value string }{
+		{"cluster_name", args.ClusterName},
+		{"namespace_name", args.Namespace},
+		{"pod_name", args.PodName},
+		{"container_name", args.Container},
+	} {
+		if label.value != "" {
+			clauses = append(clauses, fmt.Sprintf("resource.labels.%s=%q", label.name, label.value))
+		}
+	}
+	if args.SeverityMin != "" {
+		severity := strings.ToUpper(args.SeverityMin)
+		if !slices.Contains(logSeverities, severity) {
+			return "", fmt.Errorf("invalid severity_min %q: must be one of %s", args.SeverityMin, strings.Join(logSeverities, ", "))
+		}
+		clauses = append(clauses, "severity>="+severity)
+	}
+	return strings.Join(clauses, " AND "), nil
+}
+
+func (h *handlers) queryLogs(ctx context.Context, req *mcp.CallToolRequest, args *queryLogsArgs) (*mcp.CallToolResult, any, error) {
+	filter, err := logFilter(args)
+	if err != nil {
+		return nil, nil, err
+	}
 	if args.Since != "" {
 		d, err := time.ParseDuration(args.Since)
 		if err != nil {
@@ -2073,6 +3241,10 @@ func (h *handlers) queryLogs(ctx context.Context, _ *mcp.CallToolRequest, args *
 		filter += fmt.Sprintf(` timestamp >= "%s" AND timestamp <= "%s"`, args.TimeRange.StartTime, args.TimeRange.EndTime)
 	}
 
+	if args.Aggregate != "" {
+		return h.aggregateLogs(ctx, filter, args)
+	}
+
 	it := h.logadminClient.Entries(ctx, logadmin.Filter(filter))
 	var result strings.Builder
 	limit := 10
@@ -2080,12 +3252,17 @@ func (h *handlers) queryLogs(ctx context.Context, _ *mcp.CallToolRequest, args *
 		limit = args.Limit
 	}
 
-	tmpl, err := template.New("log").Parse(args.Format)
-	if err != nil {
-		return nil, nil, fmt.Errorf("invalid format template: %w", err)
+	// An empty or blank format means the default format.
+	var tmpl *template.Template
+	if strings.TrimSpace(args.Format) != "" {
+		tmpl, err = template.New("log").Parse(args.Format)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid format template: %w", err)
+		}
 	}
 
-	for i := 0; i < limit; i++ {
+	entries := 0
+	for ; entries < limit; entries++ {
 		entry, err := it.Next()
 		if err == iterator.Done {
 			break
@@ -2094,94 +3271,215 @@ func (h *handlers) queryLogs(ctx context.Context, _ *mcp.CallToolRequest, args *
 			return nil, nil, fmt.Errorf("failed to get next log entry: %w", err)
 		}
 
-		if args.Format != "" {
+		switch {
+		case tmpl != nil:
 			var buf bytes.Buffer
 			if err := tmpl.Execute(&buf, entry); err != nil {
 				return nil, nil, fmt.Errorf("failed to execute template: %w", err)
 			}
 			result.WriteString(buf.String())
-		} else {
+		case args.Verbose:
 			b, err := json.Marshal(entry)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to marshal log entry: %w", err)
 			}
 			result.Write(b)
+		default:
+			result.WriteString(compactLogEntry(entry))
 		}
 		result.WriteString("\n")
 	}
 
+	text := result.String()
+	if args.Summarize {
+		text, err = summarizeLogs(ctx, req, fmt.Sprintf("%d Cloud Logging entries", entries), text)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: result.String()},
+			&mcp.TextContent{Text: text},
 		},
 	}, nil, nil
 }
 
+const preferredResourcesCacheKey = "discovery:preferred-resources"
+
+func (h *handlers) serverPreferredResources(refresh bool) ([]*metav1.APIResourceList, error) {
+	return cache.GetOrLoad(h.cache, preferredResourcesCacheKey, refresh, func() ([]*metav1.APIResourceList, error) {
+		lists, err := h.dc.ServerPreferredResources()
+		if err != nil {
+			if _, ok := err.(*discovery.ErrGroupDiscoveryFailed); !ok {
+				return nil, fmt.Errorf("failed to get server preferred resources: %w", err)
+			}
+		}
+		return lists, nil
+	})
+}
+
 func (h *handlers) findGVR(resourceKind string) (schema.GroupVersionResource, error) {
-	lists, err := h.dc.ServerPreferredResources()
+	gvr, err := h.lookupGVR(resourceKind, false)
+	if err == errResourceNotFound {
+		// The cached discovery data may predate a newly installed CRD.
+		gvr, err = h.lookupGVR(resourceKind, true)
+	}
+	if err == errResourceNotFound {
+		lists, err := h.serverPreferredResources(false)
+		if err != nil {
+			return schema.GroupVersionResource{}, err
+		}
+		return schema.GroupVersionResource{}, resourceNotFoundError(lists, resourceKind)
+	}
+	return gvr, err
+}
+
+var errResourceNotFound = errors.New("resource not found")
+
+func (h *handlers) lookupGVR(resourceKind string, refresh bool) (schema.GroupVersionResource, error) {
+	lists, err := h.serverPreferredResources(refresh)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return matchGVR(lists, resourceKind)
+}
+
+// FmtCustomColumns renders items as a table of customColumns, a
+// comma-separated list of 'HEADER:JSONPATH' columns.
+func FmtCustomColumns(items []unstructured.Unstructured, customColumns string) (string, error) {
+	printer, err := newCustomColumnsPrinter(customColumns, false)
 	if err != nil {
-		if _, ok := err.(*discovery.ErrGroupDiscoveryFailed); !ok {
-			return schema.GroupVersionResource{}, fmt.Errorf("failed to get server preferred resources: %w", err)
+		return "", err
+	}
+	for i := range items {
+		if err := printer.add(&items[i]); err != nil {
+			return "", err
 		}
 	}
+	var output strings.Builder
+	if err := printer.flush(&output); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
 
-	for _, list := range lists {
-		for _, resource := range list.APIResources {
-			if resource.Kind == resourceKind || resource.Name == resourceKind || resource.SingularName == resourceKind || contains(resource.ShortNames, resourceKind) {
-				gv, err := schema.ParseGroupVersion(list.GroupVersion)
-				if err != nil {
-					return schema.GroupVersionResource{}, fmt.Errorf("failed to parse group version %q: %w", list.GroupVersion, err)
-				}
-				return gv.WithResource(resource.Name), nil
+// customColumnsPrinter renders resources as a table of 'HEADER:JSONPATH'
+// columns. The JSONPath expressions are parsed once and reused for every row,
+// and the rows are buffered so that the columns can be aligned.
+type customColumnsPrinter struct {
+	headers   []string
+	paths     []*jsonpath.JSONPath
+	noHeaders bool
+	// missing is the value of the cells without values.
+	missing string
+	rows    [][]string
+}
+
+func newCustomColumnsPrinter(customColumns string, noHeaders bool) (*customColumnsPrinter, error) {
+	p := &customColumnsPrinter{noHeaders: noHeaders, missing: "<none>"}
+	for _, col := range splitCustomColumns(customColumns) {
+		// The header can't contain a colon, but the JSONPath can, e.g. in
+		// filters or annotation keys.
+		header, path, ok := strings.Cut(col, ":")
+		if !ok || header == "" || path == "" {
+			return nil, fmt.Errorf("invalid custom column format %q, expected HEADER:JSONPATH", col)
+		}
+		if !strings.HasPrefix(path, "{") {
+			path = "{" + path + "}"
+		}
+		j := jsonpath.New(header).AllowMissingKeys(true)
+		if err := j.Parse(path); err != nil {
+			return nil, fmt.Errorf("failed to parse jsonpath of column %s: %w", header, err)
+		}
+		p.headers = append(p.headers, header)
+		p.paths = append(p.paths, j)
+	}
+	return p, nil
+}
+
+// splitCustomColumns splits customColumns on the commas that are outside of
+// quotes and brackets, so that JSONPath filters and quoted keys can contain
+// commas.
+func splitCustomColumns(customColumns string) []string {
+	var cols []string
+	var quote rune
+	depth, start := 0, 0
+	escaped := false
+	for i, r := range customColumns {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
 			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '[' || r == '(' || r == '{':
+			depth++
+		case r == ']' || r == ')' || r == '}':
+			depth--
+		case r == ',' && depth == 0:
+			cols = append(cols, strings.TrimSpace(customColumns[start:i]))
+			start = i + 1
 		}
 	}
-
-	return schema.GroupVersionResource{}, fmt.Errorf("resource kind %q not found", resourceKind)
+	return append(cols, strings.TrimSpace(customColumns[start:]))
 }
 
-func contains(slice []string, s string) bool {
-	for _, item := range slice {
-		if item == s {
-			return true
+// add adds the row of obj to the table.
+func (p *customColumnsPrinter) add(obj *unstructured.Unstructured) error {
+	row := make([]string, 0, len(p.paths))
+	for i, j := range p.paths {
+		results, err := j.FindResults(obj.Object)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate the jsonpath of column %s: %w", p.headers[i], err)
 		}
+		var values []string
+		for _, result := range results {
+			for _, v := range result {
+				values = append(values, formatColumnValue(v.Interface()))
+			}
+		}
+		if len(values) == 0 {
+			values = []string{p.missing}
+		}
+		row = append(row, strings.Join(values, ","))
 	}
-	return false
+	p.rows = append(p.rows, row)
+	return nil
 }
 
-func FmtCustomColumns(items []unstructured.Unstructured, customColumns string) (string, error) {
-	var output strings.Builder
-	columns := strings.Split(customColumns, ",")
-	var headers []string
-	var paths []string
-	for _, col := range columns {
-		parts := strings.Split(col, ":")
-		if len(parts) != 2 {
-			return "", fmt.Errorf("invalid custom column format: %s", col)
-		}
-		headers = append(headers, parts[0])
-		paths = append(paths, parts[1])
-	}
-	output.WriteString(strings.Join(headers, "\t") + "\n")
-
-	for _, item := range items {
-		var row []string
-		for _, path := range paths {
-			j := jsonpath.New("custom")
-			if err := j.Parse(fmt.Sprintf("{%s}", path)); err != nil {
-				return "", fmt.Errorf("failed to parse jsonpath: %w", err)
-			}
-			results, err := j.FindResults(item.Object)
-			if err != nil {
-				return "", fmt.Errorf("failed to find results: %w", err)
-			}
-			if len(results) > 0 && len(results[0]) > 0 {
-				row = append(row, fmt.Sprintf("%v", results[0][0].Interface()))
-			} else {
-				row = append(row, "<none>")
-			}
+// formatColumnValue formats v as a cell: scalars as is, and lists and
+// objects as JSON.
+func formatColumnValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return v
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
 		}
-		output.WriteString(strings.Join(row, "\t") + "\n")
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", v)
 	}
-	return output.String(), nil
+}
+
+// flush writes the table to out, with aligned columns.
+func (p *customColumnsPrinter) flush(out *strings.Builder) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if !p.noHeaders {
+		fmt.Fprintln(w, strings.Join(p.headers, "\t"))
+	}
+	for _, row := range p.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
 }
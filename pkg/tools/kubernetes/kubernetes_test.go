@@ -0,0 +1,369 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/container/v1"
+	"google.golang.org/api/option"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newPod(name, image string) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "main", "image": image},
+			},
+		},
+	}}
+}
+
+func TestFmtCustomColumns(t *testing.T) {
+	items := []unstructured.Unstructured{
+		newPod("pod-1", "nginx:latest"),
+		newPod("pod-2", "ubuntu:22.04"),
+	}
+
+	got, err := FmtCustomColumns(items, "NAME:.metadata.name,IMAGE:.spec.containers[0].image")
+	if err != nil {
+		t.Fatalf("FmtCustomColumns() returned error: %v", err)
+	}
+	want := "NAME    IMAGE\npod-1   nginx:latest\npod-2   ubuntu:22.04\n"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FmtCustomColumns() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := FmtCustomColumns(items, "NAME"); err == nil {
+		t.Errorf("FmtCustomColumns() expected error for invalid column spec")
+	}
+}
+
+func TestCustomColumnsPrinter(t *testing.T) {
+	pod := newPod("pod-1", "nginx:latest")
+	pod.Object["metadata"].(map[string]interface{})["annotations"] = map[string]interface{}{"example.com/owner": "team:a"}
+	pod.Object["spec"].(map[string]interface{})["containers"] = []interface{}{
+		map[string]interface{}{"name": "main", "image": "nginx:latest", "ports": []interface{}{map[string]interface{}{"containerPort": int64(80)}}},
+		map[string]interface{}{"name": "sidecar", "image": "envoy:v1"},
+	}
+
+	printer, err := newCustomColumnsPrinter(`NAME:{.metadata.name},IMAGES:.spec.containers[*].image,MAIN:.spec.containers[?(@.image=="nginx:latest")].ports,OWNER:.metadata.annotations.example\.com/owner,MISSING:.status.podIP`, true)
+	if err != nil {
+		t.Fatalf("newCustomColumnsPrinter() returned error: %v", err)
+	}
+	if err := printer.add(&pod); err != nil {
+		t.Fatalf("add() returned error: %v", err)
+	}
+	var got strings.Builder
+	if err := printer.flush(&got); err != nil {
+		t.Fatalf("flush() returned error: %v", err)
+	}
+	want := `pod-1   nginx:latest,envoy:v1   [{"containerPort":80}]   team:a   <none>` + "\n"
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("flush() mismatch (-want +got):\n%s", diff)
+	}
+
+	cols := splitCustomColumns(`A:.a, B:.b[?(@.x=="1,2")], C:.c['d,e']`)
+	if diff := cmp.Diff([]string{`A:.a`, `B:.b[?(@.x=="1,2")]`, `C:.c['d,e']`}, cols); diff != "" {
+		t.Errorf("splitCustomColumns() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteYAMLDocument(t *testing.T) {
+	var out strings.Builder
+	for _, name := range []string{"pod-1", "pod-2"} {
+		pod := newPod(name, "nginx")
+		if err := writeYAMLDocument(&out, &pod); err != nil {
+			t.Fatalf("writeYAMLDocument() returned error: %v", err)
+		}
+	}
+	docs := strings.Split(out.String(), "---\n")
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 YAML documents, got %d:\n%s", len(docs), out.String())
+	}
+	if !strings.Contains(docs[1], "name: pod-2") {
+		t.Errorf("second document does not describe pod-2:\n%s", docs[1])
+	}
+}
+
+func TestApplyConflictError(t *testing.T) {
+	err := apierrors.NewApplyConflict([]metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldManagerConflict,
+		Message: `conflict with "hpa-controller": .spec.replicas`,
+		Field:   ".spec.replicas",
+	}}, "Apply failed with 1 conflict")
+
+	got := applyConflictError("Deployment", "web", err).Error()
+	want := `applying Deployment "web" conflicts with fields owned by other field managers:
+- conflict with "hpa-controller": .spec.replicas
+Retry with force set to true to take ownership of these fields`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("applyConflictError() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNamespaceScope(t *testing.T) {
+	h := &handlers{defaultNamespace: "team-a"}
+	for _, tc := range []struct {
+		namespace     string
+		allNamespaces bool
+		want          string
+		wantScope     string
+		wantErr       bool
+	}{
+		{namespace: "", want: "team-a", wantScope: `namespace "team-a"`},
+		{namespace: "prod", want: "prod", wantScope: `namespace "prod"`},
+		{allNamespaces: true, want: "", wantScope: "all namespaces"},
+		{namespace: "prod", allNamespaces: true, wantErr: true},
+	} {
+		got, err := h.namespaceScope(tc.namespace, tc.allNamespaces)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("namespaceScope(%q, %v) error = %v, wantErr %v", tc.namespace, tc.allNamespaces, err, tc.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("namespaceScope(%q, %v) = %q, want %q", tc.namespace, tc.allNamespaces, got, tc.want)
+		}
+		if scope := describeScope(got); scope != tc.wantScope {
+			t.Errorf("describeScope(%q) = %q, want %q", got, scope, tc.wantScope)
+		}
+	}
+}
+
+func TestMatchNamespaces(t *testing.T) {
+	var objs []runtime.Object
+	for _, name := range []string{"checkout", "payments", "team-a-api", "team-a-web", "team-b-api"} {
+		objs = append(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	h := &handlers{clientset: fake.NewClientset(objs...)}
+	for _, tc := range []struct {
+		set     string
+		isSet   bool
+		want    string
+		wantErr bool
+	}{
+		{set: "checkout", isSet: false},
+		{set: "payments,checkout", isSet: true, want: `namespaces "checkout", "payments"`},
+		{set: "team-a-.*", isSet: true, want: `namespaces "team-a-api", "team-a-web"`},
+		{set: "checkout, team-.*-api, checkout", isSet: true, want: `namespaces "checkout", "team-a-api", "team-b-api"`},
+		{set: "staging.*", isSet: true, wantErr: true},
+		{set: "team-(", isSet: true, wantErr: true},
+	} {
+		if got := isNamespaceSet(tc.set); got != tc.isSet {
+			t.Errorf("isNamespaceSet(%q) = %v, want %v", tc.set, got, tc.isSet)
+		}
+		if !tc.isSet {
+			continue
+		}
+		got, err := h.matchNamespaces(context.Background(), tc.set)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("matchNamespaces(%q) error = %v, wantErr %v", tc.set, err, tc.wantErr)
+		}
+		if err == nil && describeNamespaces(got) != tc.want {
+			t.Errorf("matchNamespaces(%q) = %q, want %q", tc.set, describeNamespaces(got), tc.want)
+		}
+	}
+}
+
+func TestCheckWriteNamespace(t *testing.T) {
+	pods := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	namespaces := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	h := &handlers{c: config.New("test", config.Options{ProtectedNamespaces: []string{"kube-system", "istio-system"}})}
+	for _, tc := range []struct {
+		gvr       schema.GroupVersionResource
+		namespace string
+		name      string
+		wantErr   bool
+	}{
+		{gvr: pods, namespace: "shop", name: "web"},
+		{gvr: pods, namespace: "kube-system", name: "kube-dns", wantErr: true},
+		{gvr: pods, namespace: "istio-system", name: "istiod", wantErr: true},
+		{gvr: namespaces, name: "kube-system", wantErr: true},
+		{gvr: namespaces, name: "shop"},
+	} {
+		if err := h.checkWriteNamespace(tc.gvr, tc.namespace, tc.name); (err != nil) != tc.wantErr {
+			t.Errorf("checkWriteNamespace(%s, %q, %q) error = %v, want error %t", tc.gvr.Resource, tc.namespace, tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestCheckWriteScope(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}, meta.RESTScopeRoot)
+	pods := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	clusterRoles := schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"}
+
+	h := &handlers{c: config.New("test", config.Options{NamespacedWritesOnly: true}), mapper: mapper}
+	if err := h.checkWriteScope(pods, "web"); err != nil {
+		t.Errorf("checkWriteScope(pods) failed: %v", err)
+	}
+	err := h.checkWriteScope(clusterRoles, "admin")
+	if want := `clusterroles.rbac.authorization.k8s.io "admin" is cluster-scoped`; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("checkWriteScope(clusterroles) error = %v, want %q", err, want)
+	}
+
+	h.c = config.New("test", config.Options{})
+	if err := h.checkWriteScope(clusterRoles, "admin"); err != nil {
+		t.Errorf("checkWriteScope(clusterroles) failed without the restriction: %v", err)
+	}
+}
+
+func TestFilterAPIResources(t *testing.T) {
+	lists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Namespaced: true, Kind: "Pod", ShortNames: []string{"po"}, Verbs: []string{"get", "list", "watch", "patch"}},
+				{Name: "pods/log", Namespaced: true, Kind: "Pod", Verbs: []string{"get"}},
+				{Name: "nodes", Kind: "Node", Verbs: []string{"get", "list", "watch"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Namespaced: true, Kind: "Deployment", Verbs: []string{"get", "list"}},
+			},
+		},
+	}
+	names := func(resources []apiResource) []string {
+		var names []string
+		for _, r := range resources {
+			names = append(names, r.APIVersion+" "+r.Name)
+		}
+		return names
+	}
+	namespaced, clusterScoped := true, false
+	for _, tc := range []struct {
+		args apiResourcesArgs
+		want []string
+	}{
+		{args: apiResourcesArgs{}, want: []string{"v1 pods", "v1 pods/log", "v1 nodes", "apps/v1 deployments"}},
+		{args: apiResourcesArgs{APIGroup: "core"}, want: []string{"v1 pods", "v1 pods/log", "v1 nodes"}},
+		{args: apiResourcesArgs{APIGroup: "apps"}, want: []string{"apps/v1 deployments"}},
+		{args: apiResourcesArgs{Namespaced: &clusterScoped}, want: []string{"v1 nodes"}},
+		{args: apiResourcesArgs{Namespaced: &namespaced, Verbs: "list, watch"}, want: []string{"v1 pods"}},
+	} {
+		if diff := cmp.Diff(tc.want, names(filterAPIResources(lists, &tc.args))); diff != "" {
+			t.Errorf("filterAPIResources(%+v) mismatch (-want +got):\n%s", tc.args, diff)
+		}
+	}
+}
+
+func TestLogFilter(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		args    queryLogsArgs
+		want    string
+		wantErr bool
+	}{
+		{name: "raw query", args: queryLogsArgs{Query: `resource.type="k8s_node"`}, want: `(resource.type="k8s_node")`},
+		{
+			name: "convenience filters",
+			args: queryLogsArgs{ClusterName: "prod", Namespace: "web", PodName: "web-0", Container: "app", SeverityMin: "warning"},
+			want: `resource.labels.cluster_name="prod" AND resource.labels.namespace_name="web" AND resource.labels.pod_name="web-0" AND resource.labels.container_name="app" AND severity>=WARNING`,
+		},
+		{
+			name: "query and convenience filters",
+			args: queryLogsArgs{Query: `textPayload:"a" OR textPayload:"b"`, Namespace: "web"},
+			want: `(textPayload:"a" OR textPayload:"b") AND resource.labels.namespace_name="web"`,
+		},
+		{name: "invalid severity", args: queryLogsArgs{SeverityMin: "FATAL"}, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := logFilter(&tc.args)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("logFilter() error = %v, wantErr %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("logFilter() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGKEListClustersProjects(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	requested := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested[strings.Split(r.URL.Path, "/")[3]]++
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write([]byte(`{"clusters": [{"name": "prod", "location": "us-central1", "status": "RUNNING"}]}`))
+	}))
+	defer srv.Close()
+	svc, err := container.NewService(ctx, option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &handlers{c: config.New("test", config.Options{}), containerService: svc}
+
+	var projectIDs []string
+	for i := range 3 * maxConcurrentProjects {
+		projectIDs = append(projectIDs, fmt.Sprintf("project-%d", i%(2*maxConcurrentProjects)))
+	}
+	res, _, err := h.gkeListClusters(ctx, nil, &gkeListClustersArgs{ProjectID: "project-0", ProjectIDs: projectIDs})
+	if err != nil {
+		t.Fatalf("gkeListClusters() failed: %v", err)
+	}
+	if len(requested) != 2*maxConcurrentProjects {
+		t.Errorf("requested %d projects, want %d", len(requested), 2*maxConcurrentProjects)
+	}
+	for projectID, n := range requested {
+		if n != 1 {
+			t.Errorf("project %s requested %d times, want once", projectID, n)
+		}
+	}
+	if maxInFlight > maxConcurrentProjects {
+		t.Errorf("%d projects queried in parallel, want at most %d", maxInFlight, maxConcurrentProjects)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; strings.Count(text, "\tprod\t") != 2*maxConcurrentProjects {
+		t.Errorf("gkeListClusters() = %q, want one cluster per project", text)
+	}
+}
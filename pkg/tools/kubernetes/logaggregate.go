@@ -0,0 +1,184 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/logging/logadmin"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// defaultAggregateEntries is the default number of entries counted by
+	// an aggregation.
+	defaultAggregateEntries = 10000
+	// aggregatePageSize is the page size of the requests of aggregations,
+	// the maximum of the Logging API.
+	aggregatePageSize        = 1000
+	defaultAggregateInterval = time.Hour
+)
+
+// logAggregation counts log entries by a key.
+type logAggregation struct {
+	by       string
+	interval time.Duration
+	counts   map[string]int
+	total    int
+}
+
+// newLogAggregation returns an aggregation of log entries by severity,
+// log_name, resource_type, http_status, resource.labels.NAME, labels.NAME or
+// time, in buckets of interval.
+func newLogAggregation(by string, interval time.Duration) (*logAggregation, error) {
+	switch {
+	case by == "severity", by == "log_name", by == "resource_type", by == "http_status", by == "time":
+	case strings.HasPrefix(by, "resource.labels.") && len(by) > len("resource.labels."):
+	case strings.HasPrefix(by, "labels.") && len(by) > len("labels."):
+	default:
+		return nil, fmt.Errorf("invalid aggregate %q: must be severity, log_name, resource_type, http_status, time, resource.labels.NAME or labels.NAME", by)
+	}
+	if interval <= 0 {
+		interval = defaultAggregateInterval
+	}
+	return &logAggregation{by: by, interval: interval, counts: map[string]int{}}, nil
+}
+
+// key returns the key of entry.
+func (a *logAggregation) key(entry *logging.Entry) string {
+	var key string
+	switch {
+	case a.by == "severity":
+		key = entry.Severity.String()
+	case a.by == "log_name":
+		key = entry.LogName
+		if i := strings.Index(key, "/logs/"); i >= 0 {
+			key = key[i+len("/logs/"):]
+		}
+	case a.by == "resource_type":
+		key = entry.Resource.GetType()
+	case a.by == "http_status":
+		if entry.HTTPRequest != nil {
+			key = strconv.Itoa(entry.HTTPRequest.Status)
+		}
+	case a.by == "time":
+		key = entry.Timestamp.UTC().Truncate(a.interval).Format(time.RFC3339)
+	case strings.HasPrefix(a.by, "resource.labels."):
+		key = entry.Resource.GetLabels()[strings.TrimPrefix(a.by, "resource.labels.")]
+	case strings.HasPrefix(a.by, "labels."):
+		key = entry.Labels[strings.TrimPrefix(a.by, "labels.")]
+	}
+	if key == "" {
+		return "(none)"
+	}
+	return key
+}
+
+func (a *logAggregation) add(entry *logging.Entry) {
+	a.counts[a.key(entry)]++
+	a.total++
+}
+
+// String returns the counts as a table: in time order, with empty buckets,
+// for time, and by decreasing count otherwise.
+func (a *logAggregation) String() string {
+	var output strings.Builder
+	header := strings.ToUpper(a.by)
+	keys := make([]string, 0, len(a.counts))
+	if a.by == "time" {
+		header = fmt.Sprintf("TIME (%s BUCKETS)", a.interval)
+		var first, last time.Time
+		for k := range a.counts {
+			t, _ := time.Parse(time.RFC3339, k)
+			if first.IsZero() || t.Before(first) {
+				first = t
+			}
+			if t.After(last) {
+				last = t
+			}
+		}
+		for t := first; len(a.counts) > 0 && !t.After(last); t = t.Add(a.interval) {
+			keys = append(keys, t.Format(time.RFC3339))
+		}
+	} else {
+		for k := range a.counts {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if a.counts[keys[i]] != a.counts[keys[j]] {
+				return a.counts[keys[i]] > a.counts[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+	}
+	output.WriteString(header + "\tCOUNT\n")
+	for _, k := range keys {
+		output.WriteString(fmt.Sprintf("%s\t%d\n", k, a.counts[k]))
+	}
+	output.WriteString(fmt.Sprintf("TOTAL\t%d\n", a.total))
+	return output.String()
+}
+
+// aggregateLogs counts the entries matching filter instead of returning them.
+func (h *handlers) aggregateLogs(ctx context.Context, filter string, args *queryLogsArgs) (*mcp.CallToolResult, any, error) {
+	var interval time.Duration
+	if args.Interval != "" {
+		d, err := time.ParseDuration(args.Interval)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid interval duration %q: %w", args.Interval, err)
+		}
+		interval = d
+	}
+	aggregation, err := newLogAggregation(args.Aggregate, interval)
+	if err != nil {
+		return nil, nil, err
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultAggregateEntries
+	}
+
+	it := h.logadminClient.Entries(ctx, logadmin.Filter(filter), logadmin.NewestFirst(), logadmin.PageSize(aggregatePageSize))
+	for aggregation.total < limit {
+		entry, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get next log entry: %w", err)
+		}
+		aggregation.add(entry)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Counted %d log entries by %s", aggregation.total, args.Aggregate))
+	if aggregation.total == limit {
+		output.WriteString(fmt.Sprintf(" (only the %d most recent entries were counted; increase limit or narrow the filter to count them all)", limit))
+	}
+	output.WriteString(":\n" + aggregation.String())
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
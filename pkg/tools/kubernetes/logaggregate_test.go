@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/google/go-cmp/cmp"
+	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
+)
+
+func TestLogAggregation(t *testing.T) {
+	start := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	entry := func(offset time.Duration, severity logging.Severity, pod string, status int) *logging.Entry {
+		e := &logging.Entry{
+			Timestamp: start.Add(offset),
+			Severity:  severity,
+			LogName:   "projects/p/logs/stderr",
+			Resource:  &mrpb.MonitoredResource{Type: "k8s_container", Labels: map[string]string{"pod_name": pod}},
+		}
+		if status != 0 {
+			e.HTTPRequest = &logging.HTTPRequest{Status: status}
+		}
+		return e
+	}
+	entries := []*logging.Entry{
+		entry(0, logging.Error, "web-0", 500),
+		entry(10*time.Minute, logging.Error, "web-1", 503),
+		entry(20*time.Minute, logging.Info, "web-0", 200),
+		entry(3*time.Hour+5*time.Minute, logging.Error, "web-0", 500),
+		entry(3*time.Hour+6*time.Minute, logging.Warning, "", 0),
+	}
+
+	for _, tc := range []struct {
+		by   string
+		want string
+	}{
+		{by: "severity", want: "SEVERITY\tCOUNT\nError\t3\nInfo\t1\nWarning\t1\nTOTAL\t5\n"},
+		{by: "resource.labels.pod_name", want: "RESOURCE.LABELS.POD_NAME\tCOUNT\nweb-0\t3\n(none)\t1\nweb-1\t1\nTOTAL\t5\n"},
+		{by: "http_status", want: "HTTP_STATUS\tCOUNT\n500\t2\n(none)\t1\n200\t1\n503\t1\nTOTAL\t5\n"},
+		{by: "log_name", want: "LOG_NAME\tCOUNT\nstderr\t5\nTOTAL\t5\n"},
+		{by: "time", want: "TIME (1h0m0s BUCKETS)\tCOUNT\n" +
+			"2025-01-02T10:00:00Z\t3\n2025-01-02T11:00:00Z\t0\n2025-01-02T12:00:00Z\t0\n2025-01-02T13:00:00Z\t2\nTOTAL\t5\n"},
+	} {
+		a, err := newLogAggregation(tc.by, 0)
+		if err != nil {
+			t.Fatalf("newLogAggregation(%q) error = %v", tc.by, err)
+		}
+		for _, e := range entries {
+			a.add(e)
+		}
+		if diff := cmp.Diff(tc.want, a.String()); diff != "" {
+			t.Errorf("aggregation by %s mismatch (-want +got):\n%s", tc.by, diff)
+		}
+	}
+
+	if _, err := newLogAggregation("resource.labels.", 0); err == nil {
+		t.Errorf("newLogAggregation() with an empty label succeeded, want error")
+	}
+}
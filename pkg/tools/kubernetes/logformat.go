@@ -0,0 +1,108 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// maxCompactPayload bounds the payload excerpt of a compact log entry.
+const maxCompactPayload = 500
+
+// compactResourceLabels are the resource labels identifying the source of a
+// log entry in the compact format, in this order.
+var compactResourceLabels = []string{"namespace_name", "pod_name", "container_name", "node_name"}
+
+// messageFields are the fields of JSON payloads that usually hold the
+// message, in order of preference.
+var messageFields = []string{"message", "MESSAGE", "msg", "log"}
+
+// compactLogEntry formats entry on one line: timestamp, severity, resource
+// and an excerpt of the payload.
+func compactLogEntry(entry *logging.Entry) string {
+	resource := entry.Resource.GetType()
+	var labels []string
+	for _, l := range compactResourceLabels {
+		if v := entry.Resource.GetLabels()[l]; v != "" {
+			labels = append(labels, v)
+		}
+	}
+	if len(labels) > 0 {
+		resource += ":" + strings.Join(labels, "/")
+	}
+	message := strings.Join(strings.Fields(logPayloadText(entry)), " ")
+	if len(message) > maxCompactPayload {
+		message, _ = truncateBody([]byte(message), maxCompactPayload)
+		message += "..."
+	}
+	return fmt.Sprintf("%s %s %s %s", entry.Timestamp.UTC().Format(time.RFC3339Nano), strings.ToUpper(entry.Severity.String()), resource, message)
+}
+
+// logPayloadText returns the text of the payload of entry: the message of
+// text and JSON payloads, and the method, resource and principal of audit
+// logs. Other payloads are returned as JSON.
+func logPayloadText(entry *logging.Entry) string {
+	var text string
+	switch p := entry.Payload.(type) {
+	case string:
+		text = p
+	case *structpb.Struct:
+		for _, f := range messageFields {
+			if v, ok := p.GetFields()[f]; ok && v.GetStringValue() != "" {
+				return v.GetStringValue()
+			}
+		}
+		b, err := protojson.Marshal(p)
+		if err != nil {
+			return fmt.Sprintf("%v", p)
+		}
+		text = string(b)
+	case *audit.AuditLog:
+		text = fmt.Sprintf("%s %s by %s", p.GetMethodName(), p.GetResourceName(), p.GetAuthenticationInfo().GetPrincipalEmail())
+		if code := p.GetStatus().GetCode(); code != 0 {
+			text += fmt.Sprintf(" failed with code %d: %s", code, p.GetStatus().GetMessage())
+		}
+	case proto.Message:
+		b, err := protojson.Marshal(p)
+		if err != nil {
+			return fmt.Sprintf("%v", p)
+		}
+		text = string(b)
+	case nil:
+	default:
+		b, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Sprintf("%v", p)
+		}
+		text = string(b)
+	}
+	if r := entry.HTTPRequest; r != nil && r.Request != nil {
+		request := fmt.Sprintf("%s %s %d", r.Request.Method, r.Request.URL, r.Status)
+		if text == "" {
+			return request
+		}
+		text = request + " " + text
+	}
+	return text
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestCompactLogEntry(t *testing.T) {
+	timestamp := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	container := &mrpb.MonitoredResource{Type: "k8s_container", Labels: map[string]string{
+		"project_id": "p", "cluster_name": "prod", "namespace_name": "web", "pod_name": "web-0", "container_name": "app",
+	}}
+	jsonPayload, err := structpb.NewStruct(map[string]any{"level": "error", "message": "connection refused"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestURL, _ := url.Parse("http://example.com/api")
+
+	for _, tc := range []struct {
+		name  string
+		entry *logging.Entry
+		want  string
+	}{
+		{
+			name:  "text payload",
+			entry: &logging.Entry{Timestamp: timestamp, Severity: logging.Error, Resource: container, Payload: "dial tcp:\n  connection refused\n"},
+			want:  "2025-01-02T10:00:00Z ERROR k8s_container:web/web-0/app dial tcp: connection refused",
+		},
+		{
+			name:  "JSON payload message",
+			entry: &logging.Entry{Timestamp: timestamp, Severity: logging.Warning, Resource: container, Payload: jsonPayload},
+			want:  "2025-01-02T10:00:00Z WARNING k8s_container:web/web-0/app connection refused",
+		},
+		{
+			name: "audit log",
+			entry: &logging.Entry{Timestamp: timestamp, Severity: logging.Notice, Resource: &mrpb.MonitoredResource{Type: "k8s_cluster"}, Payload: &audit.AuditLog{
+				MethodName:         "io.k8s.apps.v1.deployments.delete",
+				ResourceName:       "apps/v1/namespaces/web/deployments/web",
+				AuthenticationInfo: &audit.AuthenticationInfo{PrincipalEmail: "alice@example.com"},
+			}},
+			want: "2025-01-02T10:00:00Z NOTICE k8s_cluster io.k8s.apps.v1.deployments.delete apps/v1/namespaces/web/deployments/web by alice@example.com",
+		},
+		{
+			name: "HTTP request",
+			entry: &logging.Entry{Timestamp: timestamp, Severity: logging.Info, Resource: &mrpb.MonitoredResource{Type: "http_load_balancer"}, HTTPRequest: &logging.HTTPRequest{
+				Request: &http.Request{Method: "GET", URL: requestURL},
+				Status:  503,
+			}},
+			want: "2025-01-02T10:00:00Z INFO http_load_balancer GET http://example.com/api 503",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := compactLogEntry(tc.entry); got != tc.want {
+				t.Errorf("compactLogEntry() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	long := compactLogEntry(&logging.Entry{Timestamp: timestamp, Resource: container, Payload: strings.Repeat("x", 2*maxCompactPayload)})
+	if !strings.HasSuffix(long, strings.Repeat("x", 10)+"...") || len(long) > 2*maxCompactPayload {
+		t.Errorf("compactLogEntry() of a long payload = %q, want it truncated", long)
+	}
+}
@@ -0,0 +1,348 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	// maxManifestSize is the maximum size of a manifest fetched from a URL or
+	// an OCI artifact, after decompression.
+	maxManifestSize = 10 << 20
+
+	ociManifestMediaType        = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType     = "application/vnd.docker.distribution.manifest.v2+json"
+	ociTitleAnnotation          = "org.opencontainers.image.title"
+	wwwAuthenticateBearerScheme = "Bearer "
+)
+
+// fetchManifest returns the manifest at rawURL: an https URL, or an OCI
+// artifact of the form oci://REGISTRY/REPOSITORY:TAG or
+// oci://REGISTRY/REPOSITORY@sha256:DIGEST. If checksum is set, it must be
+// the hex SHA-256 digest of the manifest.
+func fetchManifest(ctx context.Context, client *http.Client, rawURL, checksum string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid manifest URL %q: %w", rawURL, err)
+	}
+	var data []byte
+	switch u.Scheme {
+	case "https":
+		data, err = fetchLimited(ctx, client, rawURL, nil)
+	case "oci":
+		data, err = fetchOCIManifest(ctx, client, u.Host, strings.TrimPrefix(u.Path, "/"))
+	default:
+		return "", fmt.Errorf("unsupported manifest URL scheme %q: must be https or oci", u.Scheme)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch manifest %s: %w", rawURL, err)
+	}
+	if checksum != "" {
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, strings.TrimPrefix(checksum, "sha256:")) {
+			return "", fmt.Errorf("checksum mismatch of manifest %s: got sha256:%s, want %s", rawURL, got, checksum)
+		}
+	}
+	return string(data), nil
+}
+
+// fetchLimited returns the body of a GET request of rawURL, failing if it
+// exceeds maxManifestSize.
+func fetchLimited(ctx context.Context, client *http.Client, rawURL string, header http.Header) ([]byte, error) {
+	resp, err := doGet(ctx, client, rawURL, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+	}
+	return readLimited(resp.Body)
+}
+
+func doGet(ctx context.Context, client *http.Client, rawURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return client.Do(req)
+}
+
+// readLimited reads r, failing if it exceeds maxManifestSize.
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxManifestSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxManifestSize {
+		return nil, fmt.Errorf("manifest exceeds %d MiB", maxManifestSize>>20)
+	}
+	return data, nil
+}
+
+// ociDescriptor is a content descriptor of the OCI image specification.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// ociRegistry fetches content from an OCI distribution registry, with
+// anonymous bearer tokens if the registry requires them.
+type ociRegistry struct {
+	client     *http.Client
+	host       string
+	repository string
+	token      string
+}
+
+// fetchOCIManifest returns the Kubernetes manifest stored in the artifact
+// reference of the repository of the registry host.
+func fetchOCIManifest(ctx context.Context, client *http.Client, host, reference string) ([]byte, error) {
+	repository, ref := splitOCIReference(reference)
+	if host == "" || repository == "" {
+		return nil, fmt.Errorf("invalid OCI reference %q", host+"/"+reference)
+	}
+	r := &ociRegistry{client: client, host: host, repository: repository}
+
+	data, err := r.get(ctx, "manifests/"+ref, ociManifestMediaType+", "+dockerManifestMediaType)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(ref, "sha256:") {
+		if err := verifyDigest(data, ref); err != nil {
+			return nil, err
+		}
+	}
+	var m ociManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse OCI manifest: %w", err)
+	}
+	layer, err := manifestLayer(m.Layers)
+	if err != nil {
+		return nil, err
+	}
+	if layer.Size > maxManifestSize {
+		return nil, fmt.Errorf("manifest exceeds %d MiB", maxManifestSize>>20)
+	}
+	blob, err := r.get(ctx, "blobs/"+layer.Digest, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyDigest(blob, layer.Digest); err != nil {
+		return nil, err
+	}
+	if isTarGzip(layer.MediaType) {
+		return yamlFromTarGzip(blob)
+	}
+	return blob, nil
+}
+
+// splitOCIReference splits REPOSITORY:TAG or REPOSITORY@DIGEST. The tag
+// defaults to latest.
+func splitOCIReference(reference string) (repository, ref string) {
+	if repo, digest, ok := strings.Cut(reference, "@"); ok {
+		return repo, digest
+	}
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		return reference[:i], reference[i+1:]
+	}
+	return reference, "latest"
+}
+
+// manifestLayer returns the layer of layers holding the Kubernetes manifest:
+// the only layer, or else the first YAML or tar+gzip one.
+func manifestLayer(layers []ociDescriptor) (ociDescriptor, error) {
+	if len(layers) == 1 {
+		return layers[0], nil
+	}
+	for _, l := range layers {
+		title := l.Annotations[ociTitleAnnotation]
+		if strings.Contains(l.MediaType, "yaml") || strings.HasSuffix(title, ".yaml") || strings.HasSuffix(title, ".yml") || isTarGzip(l.MediaType) {
+			return l, nil
+		}
+	}
+	return ociDescriptor{}, fmt.Errorf("the OCI artifact has %d layers and none of them is a YAML manifest", len(layers))
+}
+
+func isTarGzip(mediaType string) bool {
+	return strings.HasSuffix(mediaType, "tar+gzip") || strings.HasSuffix(mediaType, ".tar.gzip")
+}
+
+// verifyDigest checks that data has the sha256 digest.
+func verifyDigest(data []byte, digest string) error {
+	sum := sha256.Sum256(data)
+	if got := "sha256:" + hex.EncodeToString(sum[:]); got != digest {
+		return fmt.Errorf("digest mismatch: got %s, want %s", got, digest)
+	}
+	return nil
+}
+
+// yamlFromTarGzip returns the YAML files of a tar+gzip archive, as a
+// multi-document manifest.
+func yamlFromTarGzip(blob []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(blob))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress layer: %w", err)
+	}
+	data, err := readLimited(gz)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(bytes.NewReader(data))
+	var docs []string
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read layer archive: %w", err)
+		}
+		if ext := path.Ext(hdr.Name); hdr.Typeflag != tar.TypeReg || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+		docs = append(docs, strings.TrimSpace(string(b)))
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("the layer archive has no YAML files")
+	}
+	return []byte(strings.Join(docs, "\n---\n")), nil
+}
+
+// get returns the content of a path of the repository, such as
+// manifests/latest, requesting an anonymous token if the registry requires
+// one.
+func (r *ociRegistry) get(ctx context.Context, p, accept string) ([]byte, error) {
+	rawURL := fmt.Sprintf("https://%s/v2/%s/%s", r.host, r.repository, p)
+	for attempt := 0; ; attempt++ {
+		header := http.Header{}
+		if accept != "" {
+			header.Set("Accept", accept)
+		}
+		if r.token != "" {
+			header.Set("Authorization", "Bearer "+r.token)
+		}
+		resp, err := doGet(ctx, r.client, rawURL, header)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if r.token, err = r.anonymousToken(ctx, challenge); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+		}
+		return readLimited(resp.Body)
+	}
+}
+
+// anonymousToken requests a token from the realm of a Bearer challenge of
+// the registry, as described by the Docker registry token authentication.
+func (r *ociRegistry) anonymousToken(ctx context.Context, challenge string) (string, error) {
+	if !strings.HasPrefix(challenge, wwwAuthenticateBearerScheme) {
+		return "", fmt.Errorf("registry %s requires authentication, which isn't supported", r.host)
+	}
+	params := parseChallengeParams(strings.TrimPrefix(challenge, wwwAuthenticateBearerScheme))
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme != "https" {
+		return "", fmt.Errorf("invalid token realm %q of registry %s", params["realm"], r.host)
+	}
+	q := realm.Query()
+	if service := params["service"]; service != "" {
+		q.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + r.repository + ":pull"
+	}
+	q.Set("scope", scope)
+	realm.RawQuery = q.Encode()
+
+	data, err := fetchLimited(ctx, r.client, realm.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get token of registry %s: %w", r.host, err)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", fmt.Errorf("failed to parse token of registry %s: %w", r.host, err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// parseChallengeParams parses the comma-separated key="value" parameters of
+// a WWW-Authenticate challenge.
+func parseChallengeParams(s string) map[string]string {
+	params := map[string]string{}
+	for s != "" {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.TrimSpace(key)
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				break
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[key] = value
+		s = strings.TrimLeft(rest, ", ")
+	}
+	return params
+}
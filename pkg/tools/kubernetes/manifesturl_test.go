@@ -0,0 +1,175 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testManifest = "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: test\n"
+
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func tarGzip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"a.yaml", "README.md", "b.yml"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// newTestRegistry returns a TLS server serving manifest.yaml over https, and
+// the OCI artifacts test/plain:v1, with a single YAML layer, and test/tgz:v1,
+// with a tar+gzip layer. The registry requires an anonymous bearer token.
+func newTestRegistry(t *testing.T) *httptest.Server {
+	t.Helper()
+	plainBlob := []byte(testManifest)
+	tgzBlob := tarGzip(t, map[string]string{
+		"a.yaml":    testManifest,
+		"README.md": "# docs",
+		"b.yml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n",
+	})
+	manifestOf := func(mediaType string, blob []byte) []byte {
+		b, _ := json.Marshal(ociManifest{
+			MediaType: ociManifestMediaType,
+			Layers:    []ociDescriptor{{MediaType: mediaType, Digest: sha256Digest(blob), Size: int64(len(blob))}},
+		})
+		return b
+	}
+	content := map[string][]byte{
+		"/v2/test/plain/manifests/v1":                             manifestOf("application/yaml", plainBlob),
+		"/v2/test/plain/blobs/" + sha256Digest(plainBlob):         plainBlob,
+		"/v2/test/tgz/manifests/v1":                               manifestOf("application/vnd.cncf.flux.content.v1.tar+gzip", tgzBlob),
+		"/v2/test/tgz/blobs/" + sha256Digest(tgzBlob):             tgzBlob,
+		"/v2/test/corrupt/manifests/v1":                           manifestOf("application/yaml", []byte("other")),
+		"/v2/test/corrupt/blobs/" + sha256Digest([]byte("other")): plainBlob,
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/manifest.yaml":
+			w.Write([]byte(testManifest))
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") == "" {
+				http.Error(w, "missing scope", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"token":"anonymous"}`))
+		case strings.HasPrefix(r.URL.Path, "/v2/") && r.Header.Get("Authorization") != "Bearer anonymous":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			data, ok := content[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(data)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchManifest(t *testing.T) {
+	ctx := context.Background()
+	srv := newTestRegistry(t)
+	host := strings.TrimPrefix(srv.URL, "https://")
+	client := srv.Client()
+
+	for _, tc := range []struct {
+		name     string
+		url      string
+		checksum string
+		want     string
+		wantErr  string
+	}{
+		{name: "https", url: srv.URL + "/manifest.yaml", want: testManifest},
+		{name: "https checksum", url: srv.URL + "/manifest.yaml", checksum: sha256Digest([]byte(testManifest)), want: testManifest},
+		{name: "https checksum mismatch", url: srv.URL + "/manifest.yaml", checksum: "0123", wantErr: "checksum mismatch"},
+		{name: "https not found", url: srv.URL + "/missing.yaml", wantErr: "404"},
+		{name: "http", url: "http://example.com/manifest.yaml", wantErr: "unsupported manifest URL scheme"},
+		{name: "oci", url: "oci://" + host + "/test/plain:v1", want: testManifest},
+		{name: "oci tar+gzip", url: "oci://" + host + "/test/tgz:v1", want: strings.TrimSpace(testManifest) + "\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b"},
+		{name: "oci corrupt blob", url: "oci://" + host + "/test/corrupt:v1", wantErr: "digest mismatch"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := fetchManifest(ctx, client, tc.url, tc.checksum)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("fetchManifest() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("fetchManifest() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSplitOCIReference(t *testing.T) {
+	for _, tc := range []struct {
+		reference, wantRepository, wantRef string
+	}{
+		{"org/app:v1.2.0", "org/app", "v1.2.0"},
+		{"org/app", "org/app", "latest"},
+		{"org/app@sha256:abc", "org/app", "sha256:abc"},
+	} {
+		repository, ref := splitOCIReference(tc.reference)
+		if repository != tc.wantRepository || ref != tc.wantRef {
+			t.Errorf("splitOCIReference(%q) = %q, %q, want %q, %q", tc.reference, repository, ref, tc.wantRepository, tc.wantRef)
+		}
+	}
+}
+
+func TestParseChallengeParams(t *testing.T) {
+	got := parseChallengeParams(`realm="https://ghcr.io/token",service="ghcr.io",scope="repository:org/app:pull"`)
+	want := map[string]string{"realm": "https://ghcr.io/token", "service": "ghcr.io", "scope": "repository:org/app:pull"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseChallengeParams() mismatch (-want +got):\n%s", diff)
+	}
+}
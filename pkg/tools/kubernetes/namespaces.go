@@ -0,0 +1,208 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateNamespaceToolDescription contains the documentation for the Kubernetes Create Namespace tool.
+// It is formatted in Markdown.
+const CreateNamespaceToolDescription = `
+This tool creates a namespace, like *kubectl create namespace*, without having to write a manifest.
+
+## Arguments
+
+* *name*: The name of the namespace.
+* *labels*: (Optional) The labels of the namespace, e.g. *{"team": "payments", "pod-security.kubernetes.io/enforce": "restricted"}*.
+* *annotations*: (Optional) The annotations of the namespace.
+
+## Response Format
+
+The created namespace:
+
+Namespace payments created.
+Labels: kubernetes.io/metadata.name=payments, team=payments
+`
+
+// DeleteNamespaceToolDescription contains the documentation for the Kubernetes Delete Namespace tool.
+// It is formatted in Markdown.
+const DeleteNamespaceToolDescription = `
+This tool deletes a namespace and all the resources in it, like *kubectl delete namespace*. Confirm the deletion with the user first: it can't be undone.
+
+A namespace is deleted in the background: it stays in the *Terminating* phase until all its resources are deleted and its finalizers are removed. Namespaces commonly get stuck in this phase because of resources whose finalizers are never removed, e.g. when the controller handling them was uninstalled, or because an aggregated API, such as *metrics.k8s.io*, is unavailable. The tool waits for the deletion and, if the namespace still exists, reports why from the namespace conditions: the remaining resources and finalizers, and the failed discovery or deletion.
+
+## Arguments
+
+* *name*: The name of the namespace.
+* *wait_seconds*: (Optional) How long to wait for the deletion to complete, at most 60 seconds. Defaults to 10 seconds.
+
+## Response Format
+
+Namespace payments is still Terminating after 10s:
+- NamespaceContentRemaining: Some resources are remaining: certificates.cert-manager.io has 1 resource instances
+- NamespaceFinalizersRemaining: Some content in the namespace has finalizers remaining: cert-manager.io/finalizer in 1 resource instances
+`
+
+type createNamespaceArgs struct {
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type deleteNamespaceArgs struct {
+	Name        string `json:"name"`
+	WaitSeconds int    `json:"wait_seconds,omitempty"`
+}
+
+const (
+	defaultNamespaceDeletionWait = 10 * time.Second
+	maxNamespaceDeletionWait     = 60 * time.Second
+	namespaceDeletionPoll        = time.Second
+)
+
+// formatLabels formats labels as sorted key=value pairs.
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "<none>"
+	}
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+func (h *handlers) createNamespace(ctx context.Context, _ *mcp.CallToolRequest, args *createNamespaceArgs) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return nil, nil, fmt.Errorf("name is required")
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        args.Name,
+		Labels:      args.Labels,
+		Annotations: args.Annotations,
+	}}
+	created, err := h.clientset.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{FieldManager: h.c.FieldManager()})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create namespace %s: %w", args.Name, err)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Namespace %s created.\n", created.Name))
+	output.WriteString(fmt.Sprintf("Labels: %s\n", formatLabels(created.Labels)))
+	if len(created.Annotations) > 0 {
+		output.WriteString(fmt.Sprintf("Annotations: %s\n", formatLabels(created.Annotations)))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+// namespaceDeletionProblems returns the reasons why the deletion of ns isn't
+// complete, from its conditions and finalizers.
+func namespaceDeletionProblems(ns *corev1.Namespace) []string {
+	var problems []string
+	for _, c := range ns.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case corev1.NamespaceDeletionDiscoveryFailure, corev1.NamespaceDeletionContentFailure, corev1.NamespaceDeletionGVParsingFailure,
+			corev1.NamespaceContentRemaining, corev1.NamespaceFinalizersRemaining:
+			problems = append(problems, fmt.Sprintf("%s: %s", c.Type, c.Message))
+		}
+	}
+	if len(ns.Spec.Finalizers) > 0 {
+		finalizers := make([]string, 0, len(ns.Spec.Finalizers))
+		for _, f := range ns.Spec.Finalizers {
+			finalizers = append(finalizers, string(f))
+		}
+		problems = append(problems, fmt.Sprintf("Namespace finalizers: %s", strings.Join(finalizers, ", ")))
+	}
+	return problems
+}
+
+func (h *handlers) deleteNamespace(ctx context.Context, _ *mcp.CallToolRequest, args *deleteNamespaceArgs) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return nil, nil, fmt.Errorf("name is required")
+	}
+	if err := h.checkProtectedNamespace(args.Name); err != nil {
+		return nil, nil, err
+	}
+	wait := defaultNamespaceDeletionWait
+	if args.WaitSeconds > 0 {
+		wait = min(time.Duration(args.WaitSeconds)*time.Second, maxNamespaceDeletionWait)
+	}
+
+	namespaces := h.clientset.CoreV1().Namespaces()
+	if err := namespaces.Delete(ctx, args.Name, metav1.DeleteOptions{}); err != nil {
+		return nil, nil, fmt.Errorf("failed to delete namespace %s: %w", args.Name, err)
+	}
+
+	var ns *corev1.Namespace
+	deadline := time.Now().Add(wait)
+	for {
+		var err error
+		ns, err = namespaces.Get(ctx, args.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: fmt.Sprintf("Namespace %s deleted.", args.Name)},
+				},
+			}, nil, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get namespace %s: %w", args.Name, err)
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(namespaceDeletionPoll):
+		}
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Namespace %s is still %s after %s", args.Name, ns.Status.Phase, wait))
+	problems := namespaceDeletionProblems(ns)
+	if len(problems) == 0 {
+		output.WriteString(": its resources are being deleted. Check again later with kube_get_resources.\n")
+	} else {
+		output.WriteString(":\n")
+		for _, p := range problems {
+			output.WriteString("- " + p + "\n")
+		}
+		output.WriteString("\nRemaining resources are deleted once the controllers handling their finalizers remove them. If these controllers are gone, remove the finalizers of the resources with kube_patch_resource after checking with the user.\n")
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
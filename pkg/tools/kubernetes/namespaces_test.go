@@ -0,0 +1,92 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNamespaceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	h := &handlers{c: &config.Config{}, clientset: fake.NewClientset()}
+
+	res, _, err := h.createNamespace(ctx, nil, &createNamespaceArgs{Name: "payments", Labels: map[string]string{"team": "payments", "env": "dev"}})
+	if err != nil {
+		t.Fatalf("createNamespace() returned error: %v", err)
+	}
+	want := "Namespace payments created.\nLabels: env=dev, team=payments\n"
+	if diff := cmp.Diff(want, res.Content[0].(*mcp.TextContent).Text); diff != "" {
+		t.Errorf("createNamespace() mismatch (-want +got):\n%s", diff)
+	}
+	if _, _, err := h.createNamespace(ctx, nil, &createNamespaceArgs{Name: "payments"}); err == nil {
+		t.Errorf("createNamespace() of an existing namespace expected error")
+	}
+
+	res, _, err = h.deleteNamespace(ctx, nil, &deleteNamespaceArgs{Name: "payments"})
+	if err != nil {
+		t.Fatalf("deleteNamespace() returned error: %v", err)
+	}
+	if got := res.Content[0].(*mcp.TextContent).Text; got != "Namespace payments deleted." {
+		t.Errorf("deleteNamespace() = %q, want %q", got, "Namespace payments deleted.")
+	}
+}
+
+func TestDeleteProtectedNamespace(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}})
+	h := &handlers{c: config.New("test", config.Options{}), clientset: clientset}
+	if _, _, err := h.deleteNamespace(ctx, nil, &deleteNamespaceArgs{Name: "kube-system"}); err == nil {
+		t.Fatal("deleteNamespace(kube-system) succeeded, want error")
+	}
+	if _, err := clientset.CoreV1().Namespaces().Get(ctx, "kube-system", metav1.GetOptions{}); err != nil {
+		t.Errorf("kube-system was deleted: %v", err)
+	}
+
+	h.c = config.New("test", config.Options{UnsafeAllowSystemNamespaces: true})
+	if _, _, err := h.deleteNamespace(ctx, nil, &deleteNamespaceArgs{Name: "kube-system"}); err != nil {
+		t.Errorf("deleteNamespace(kube-system) with unsafe_allow_system_namespaces failed: %v", err)
+	}
+}
+
+func TestNamespaceDeletionProblems(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "payments"},
+		Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes}},
+		Status: corev1.NamespaceStatus{
+			Phase: corev1.NamespaceTerminating,
+			Conditions: []corev1.NamespaceCondition{
+				{Type: corev1.NamespaceDeletionDiscoveryFailure, Status: corev1.ConditionFalse, Message: "All resources successfully discovered"},
+				{Type: corev1.NamespaceContentRemaining, Status: corev1.ConditionTrue, Message: "Some resources are remaining: certificates.cert-manager.io has 1 resource instances"},
+				{Type: corev1.NamespaceFinalizersRemaining, Status: corev1.ConditionTrue, Message: "Some content in the namespace has finalizers remaining: cert-manager.io/finalizer in 1 resource instances"},
+			},
+		},
+	}
+	want := []string{
+		"NamespaceContentRemaining: Some resources are remaining: certificates.cert-manager.io has 1 resource instances",
+		"NamespaceFinalizersRemaining: Some content in the namespace has finalizers remaining: cert-manager.io/finalizer in 1 resource instances",
+		"Namespace finalizers: kubernetes",
+	}
+	if diff := cmp.Diff(want, namespaceDeletionProblems(ns)); diff != "" {
+		t.Errorf("namespaceDeletionProblems() mismatch (-want +got):\n%s", diff)
+	}
+}
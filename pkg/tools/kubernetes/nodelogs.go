@@ -0,0 +1,224 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging/logadmin"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/iterator"
+)
+
+// NodeLogsToolDescription contains the documentation for the Node Logs Kubernetes tool.
+// It is formatted in Markdown.
+const NodeLogsToolDescription = `
+This tool fetches the logs of a node's system services, such as the kubelet or the container runtime. Use it to investigate node problems, e.g. a NotReady node, pods stuck in ContainerCreating, or volume mount failures.
+
+The logs are fetched from the first available source:
+
+* **Node log query**: the kubelet's */logs* endpoint, through the *nodes/proxy* subresource of the API server, i.e. */api/v1/nodes/NODE/proxy/logs/?query=SERVICE*. It requires the *NodeLogQuery* feature and the kubelet's *enableSystemLogQuery* setting, and returns the logs of the node's journal.
+* **Cloud Logging**: on GKE, the node's logs exported to Cloud Logging, i.e. the *k8s_node* log entries of the node, in the default project.
+
+## Arguments
+
+* *node*: The name of the node.
+* *service*: (Optional) The system service to fetch the logs of, e.g. *kubelet*, *containerd* or *docker*. Defaults to *kubelet*.
+* *since*: (Optional) How far back to fetch logs, as a duration such as *30m*. Defaults to *1h*.
+* *tail_lines*: (Optional) The maximum number of most recent lines to return. Defaults to 100.
+* *pattern*: (Optional) A regular expression the lines must match, e.g. *error|failed*.
+* *source*: (Optional) *node* or *cloud_logging* to only use this source. Defaults to trying the node log query, then Cloud Logging.
+
+## Response Format
+
+The source of the logs, followed by the log lines, oldest first.
+`
+
+type nodeLogsArgs struct {
+	Node      string `json:"node"`
+	Service   string `json:"service,omitempty"`
+	Since     string `json:"since,omitempty"`
+	TailLines int    `json:"tail_lines,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	Source    string `json:"source,omitempty"`
+}
+
+const (
+	defaultNodeLogService   = "kubelet"
+	defaultNodeLogWindow    = time.Hour
+	defaultNodeLogTailLines = 100
+)
+
+// isLogDirectoryListing reports whether body is the listing of the node's
+// /var/log directory, which kubelets without the node log query feature
+// return instead of the logs.
+func isLogDirectoryListing(body string) bool {
+	return strings.HasPrefix(strings.TrimSpace(body), "<pre>")
+}
+
+// nodeLogFilter returns the Cloud Logging filter of the logs of service on
+// node since start.
+func nodeLogFilter(node, service, pattern string, start time.Time) string {
+	filter := fmt.Sprintf(`resource.type="k8s_node" AND resource.labels.node_name=%q AND logName:%q AND timestamp>=%q`,
+		node, "logs/"+service, start.UTC().Format(time.RFC3339))
+	if pattern != "" {
+		filter += fmt.Sprintf(` AND textPayload=~%q`, pattern)
+	}
+	return filter
+}
+
+// lastLines returns the last n non-empty lines of text.
+func lastLines(text string, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// nodeLogQuery fetches logs with the kubelet's node log query. It returns
+// false if the node doesn't support it.
+func (h *handlers) nodeLogQuery(ctx context.Context, args *nodeLogsArgs, service string, start time.Time, tail int) ([]string, bool, error) {
+	request := h.clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").
+		Name(args.Node).
+		SubResource("proxy").
+		Suffix("logs/").
+		Param("query", service).
+		Param("sinceTime", start.UTC().Format(time.RFC3339)).
+		Param("tailLines", strconv.Itoa(tail))
+	if args.Pattern != "" {
+		request = request.Param("pattern", args.Pattern)
+	}
+	body, err := request.DoRaw(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if isLogDirectoryListing(string(body)) {
+		return nil, false, nil
+	}
+	return lastLines(string(body), tail), true, nil
+}
+
+// cloudLoggingNodeLogs fetches the logs exported to Cloud Logging.
+func (h *handlers) cloudLoggingNodeLogs(ctx context.Context, args *nodeLogsArgs, service string, start time.Time, tail int) ([]string, error) {
+	it := h.logadminClient.Entries(ctx, logadmin.Filter(nodeLogFilter(args.Node, service, args.Pattern, start)), logadmin.NewestFirst())
+	var lines []string
+	for len(lines) < tail {
+		entry, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get next log entry: %w", err)
+		}
+		payload, ok := entry.Payload.(string)
+		if !ok {
+			b, err := json.Marshal(entry.Payload)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal log entry: %w", err)
+			}
+			payload = string(b)
+		}
+		lines = append(lines, entry.Timestamp.UTC().Format(time.RFC3339Nano)+" "+strings.TrimRight(payload, "\n"))
+	}
+	slices.Reverse(lines)
+	return lines, nil
+}
+
+func (h *handlers) nodeLogs(ctx context.Context, _ *mcp.CallToolRequest, args *nodeLogsArgs) (*mcp.CallToolResult, any, error) {
+	if args.Node == "" {
+		return nil, nil, fmt.Errorf("node is required")
+	}
+	service := args.Service
+	if service == "" {
+		service = defaultNodeLogService
+	}
+	since := defaultNodeLogWindow
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		since = d
+	}
+	tail := args.TailLines
+	if tail <= 0 {
+		tail = defaultNodeLogTailLines
+	}
+	start := time.Now().Add(-since)
+
+	var source string
+	var lines []string
+	var queryErr error
+	switch args.Source {
+	case "", "node":
+		var ok bool
+		lines, ok, queryErr = h.nodeLogQuery(ctx, args, service, start, tail)
+		if ok {
+			source = "node log query"
+			break
+		}
+		if queryErr == nil {
+			queryErr = fmt.Errorf("the node log query feature is not enabled on the node")
+		}
+		if args.Source == "node" {
+			return nil, nil, fmt.Errorf("failed to query node logs: %w", queryErr)
+		}
+		fallthrough
+	case "cloud_logging":
+		var err error
+		lines, err = h.cloudLoggingNodeLogs(ctx, args, service, start, tail)
+		if err != nil {
+			if queryErr != nil {
+				return nil, nil, fmt.Errorf("failed to query node logs: %v; and failed to read them from Cloud Logging: %w", queryErr, err)
+			}
+			return nil, nil, err
+		}
+		source = "Cloud Logging"
+	default:
+		return nil, nil, fmt.Errorf("invalid source %q: must be node or cloud_logging", args.Source)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Logs of %s on node %s since %s, from %s", service, args.Node, start.UTC().Format(time.RFC3339), source))
+	if queryErr != nil {
+		output.WriteString(fmt.Sprintf(" (node log query unavailable: %v)", queryErr))
+	}
+	output.WriteString(":\n")
+	if len(lines) == 0 {
+		output.WriteString("No log lines found.\n")
+	}
+	for _, line := range lines {
+		output.WriteString(line + "\n")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNodeLogFilter(t *testing.T) {
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	got := nodeLogFilter("node-1", "kubelet", "error|failed", start)
+	want := `resource.type="k8s_node" AND resource.labels.node_name="node-1" AND logName:"logs/kubelet" AND timestamp>="2025-06-01T10:00:00Z" AND textPayload=~"error|failed"`
+	if got != want {
+		t.Errorf("nodeLogFilter() = %s, want %s", got, want)
+	}
+}
+
+func TestIsLogDirectoryListing(t *testing.T) {
+	if !isLogDirectoryListing("<pre>\n<a href=\"containers/\">containers/</a>\n</pre>\n") {
+		t.Errorf("isLogDirectoryListing() of a directory listing = false, want true")
+	}
+	if isLogDirectoryListing("Jun 01 10:00:00 node-1 kubelet[1234]: I0601 started\n") {
+		t.Errorf("isLogDirectoryListing() of logs = true, want false")
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	if diff := cmp.Diff([]string{"b", "c"}, lastLines("a\n\nb\nc\n", 2)); diff != "" {
+		t.Errorf("lastLines() mismatch (-want +got):\n%s", diff)
+	}
+}
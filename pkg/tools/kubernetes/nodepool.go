@@ -0,0 +1,282 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/container/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// nodeImageTypes are the node image types of GKE node pools.
+var nodeImageTypes = []string{"COS_CONTAINERD", "UBUNTU_CONTAINERD", "WINDOWS_LTSC_CONTAINERD", "WINDOWS_SAC_CONTAINERD"}
+
+// minWindowsNodeVersion is the first GKE version supporting the containerd
+// Windows Server node images.
+var minWindowsNodeVersion = version.MustParseGeneric("1.21")
+
+// taintEffects maps the taint effects of Kubernetes to those of the GKE API.
+var taintEffects = map[string]string{
+	"NoSchedule":       "NO_SCHEDULE",
+	"PreferNoSchedule": "PREFER_NO_SCHEDULE",
+	"NoExecute":        "NO_EXECUTE",
+}
+
+// oauthScopePrefix is the prefix of the OAuth scopes of the Google Cloud
+// APIs, which the scopes of node pools can omit, like gcloud.
+const oauthScopePrefix = "https://www.googleapis.com/auth/"
+
+// sandboxImageType is the only image type of GKE Sandbox node pools.
+const sandboxImageType = "COS_CONTAINERD"
+
+// nodeImageType returns the GKE image type named by imageType, in any case.
+// The empty image type is the default of GKE.
+func nodeImageType(imageType string) (string, error) {
+	if imageType == "" {
+		return "", nil
+	}
+	imageType = strings.ToUpper(imageType)
+	if !slices.Contains(nodeImageTypes, imageType) {
+		return "", fmt.Errorf("unsupported image_type %q: use one of %s", imageType, strings.Join(nodeImageTypes, ", "))
+	}
+	return imageType, nil
+}
+
+// isWindowsImageType reports whether the nodes of imageType run Windows
+// Server.
+func isWindowsImageType(imageType string) bool {
+	return strings.HasPrefix(imageType, "WINDOWS_")
+}
+
+// parseNodeTaints parses taints of the form key=value:Effect, or key:Effect,
+// of kubectl taint.
+func parseNodeTaints(taints []string) ([]*container.NodeTaint, error) {
+	if taints == nil {
+		return nil, nil
+	}
+	parsed := []*container.NodeTaint{}
+	for _, taint := range taints {
+		keyValue, effect, ok := strings.Cut(taint, ":")
+		if !ok || taintEffects[effect] == "" {
+			return nil, fmt.Errorf("invalid taint %q: use key=value:Effect, with the effect NoSchedule, PreferNoSchedule or NoExecute", taint)
+		}
+		key, value, _ := strings.Cut(keyValue, "=")
+		if key == "" {
+			return nil, fmt.Errorf("invalid taint %q: the key is empty", taint)
+		}
+		parsed = append(parsed, &container.NodeTaint{Key: key, Value: value, Effect: taintEffects[effect]})
+	}
+	return parsed, nil
+}
+
+// oauthScopes returns the full URLs of the OAuth scopes scopes, given as URLs
+// or as names such as cloud-platform.
+func oauthScopes(scopes []string) []string {
+	if scopes == nil {
+		return nil
+	}
+	full := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !strings.Contains(scope, "://") {
+			scope = oauthScopePrefix + scope
+		}
+		full = append(full, scope)
+	}
+	return full
+}
+
+// checkNodeServiceAccount returns an error if serviceAccount isn't the email
+// of a service account, or "default" for the Compute Engine default service
+// account.
+func checkNodeServiceAccount(serviceAccount string) error {
+	if serviceAccount != "" && serviceAccount != "default" && !strings.Contains(serviceAccount, "@") {
+		return fmt.Errorf("invalid service_account %q: use the email of the service account", serviceAccount)
+	}
+	return nil
+}
+
+// checkNetworkTags returns an error if a tag of tags isn't a valid Compute
+// Engine network tag, which firewall rules and routes select the nodes with.
+func checkNetworkTags(tags []string) error {
+	for _, tag := range tags {
+		if errs := validation.IsDNS1035Label(tag); len(errs) > 0 {
+			return fmt.Errorf("invalid network tag %q: %s", tag, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// setSandbox runs the pods using the gvisor RuntimeClass on the nodes of
+// config in GKE Sandbox. The default image type is the one of GKE Sandbox.
+func setSandbox(config *container.NodeConfig) error {
+	if err := checkSandboxImageType(config.ImageType); err != nil {
+		return err
+	}
+	config.ImageType = sandboxImageType
+	config.SandboxConfig = &container.SandboxConfig{Type: "GVISOR"}
+	return nil
+}
+
+// checkSandboxImageType returns an error if the nodes of GKE Sandbox node
+// pools can't run imageType.
+func checkSandboxImageType(imageType string) error {
+	if imageType != "" && imageType != sandboxImageType {
+		return fmt.Errorf("GKE Sandbox nodes need the %s image type, not %s", sandboxImageType, imageType)
+	}
+	return nil
+}
+
+// checkWindowsNodePool returns an error if the node pool pool of cluster, or
+// a new node pool if pool is empty, can't run the Windows Server image
+// imageType at nodeVersion. An empty nodeVersion, or "-", is the version of
+// the control plane.
+func checkWindowsNodePool(cluster *container.Cluster, imageType, nodeVersion, pool string) error {
+	if cluster.Autopilot != nil && cluster.Autopilot.Enabled {
+		return fmt.Errorf("cluster %s is an Autopilot cluster, which doesn't support Windows Server nodes (%s)", cluster.Name, imageType)
+	}
+	if nodeVersion == "" || nodeVersion == "-" {
+		nodeVersion = cluster.CurrentMasterVersion
+	}
+	if v, err := version.ParseGeneric(nodeVersion); err == nil && v.LessThan(minWindowsNodeVersion) {
+		return fmt.Errorf("%s nodes need GKE %s or later, not %s", imageType, minWindowsNodeVersion, nodeVersion)
+	}
+	linux := slices.ContainsFunc(cluster.NodePools, func(np *container.NodePool) bool {
+		return np.Name != pool && (np.Config == nil || !isWindowsImageType(np.Config.ImageType))
+	})
+	if !linux {
+		return fmt.Errorf("cluster %s has no Linux node pool: create one for the system workloads before the %s node pool", cluster.Name, imageType)
+	}
+	return nil
+}
+
+func (h *handlers) gkeUpdateNodePool(ctx context.Context, _ *mcp.CallToolRequest, args *gkeUpdateNodePoolArgs) (*mcp.CallToolResult, any, error) {
+	if args.ClusterName == "" || args.NodePoolID == "" {
+		return nil, nil, fmt.Errorf("cluster_name and node_pool_id are required")
+	}
+	projectID, location := args.ProjectID, args.Location
+	if projectID == "" {
+		projectID = h.c.DefaultProjectID()
+	}
+	if location == "" {
+		location = h.c.DefaultLocation()
+	}
+	if location == "" {
+		return nil, nil, fmt.Errorf("location is required: there is no default location")
+	}
+	autoscaling := args.EnableAutoscaling != nil || args.MinNodes != nil || args.MaxNodes != nil || args.TotalMinNodes != nil || args.TotalMaxNodes != nil
+	settings := args.NodeVersion != "" || args.MachineType != "" || args.ImageType != "" || args.Labels != nil || args.Taints != nil
+	switch {
+	case autoscaling && settings:
+		return nil, nil, fmt.Errorf("GKE runs one update of a node pool at a time: update the autoscaling and the other settings in separate calls")
+	case !autoscaling && !settings:
+		return nil, nil, fmt.Errorf("nothing to update: give the settings to change")
+	}
+	cluster, err := h.getCluster(ctx, projectID, location, args.ClusterName, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkNotAutopilot(cluster); err != nil {
+		return nil, nil, err
+	}
+	name := fmt.Sprintf("projects/%s/locations/%s/clusters/%s/nodePools/%s", projectID, location, args.ClusterName, args.NodePoolID)
+	what := fmt.Sprintf("updating node pool %s in cluster %s", args.NodePoolID, args.ClusterName)
+
+	if autoscaling {
+		req := &container.SetNodePoolAutoscalingRequest{Autoscaling: &container.NodePoolAutoscaling{}}
+		if args.EnableAutoscaling != nil {
+			req.Autoscaling.Enabled = *args.EnableAutoscaling
+		} else {
+			// Setting limits enables the autoscaler, like gcloud.
+			req.Autoscaling.Enabled = true
+		}
+		for _, limit := range []struct {
+			value *int64
+			field *int64
+		}{
+			{args.MinNodes, &req.Autoscaling.MinNodeCount},
+			{args.MaxNodes, &req.Autoscaling.MaxNodeCount},
+			{args.TotalMinNodes, &req.Autoscaling.TotalMinNodeCount},
+			{args.TotalMaxNodes, &req.Autoscaling.TotalMaxNodeCount},
+		} {
+			if limit.value != nil {
+				*limit.field = *limit.value
+			}
+		}
+		op, err := h.containerService.Projects.Locations.Clusters.NodePools.SetAutoscaling(name, req).Context(ctx).Do()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update the autoscaling of node pool: %w", err)
+		}
+		return operationResult(op, projectID, location, what), nil, nil
+	}
+
+	imageType, err := nodeImageType(args.ImageType)
+	if err != nil {
+		return nil, nil, err
+	}
+	taints, err := parseNodeTaints(args.Taints)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The node version and image type are required: the current ones are
+	// kept unless changed.
+	pool, err := h.containerService.Projects.Locations.Clusters.NodePools.Get(name).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get node pool: %w", err)
+	}
+	nodeVersion := args.NodeVersion
+	if nodeVersion == "" {
+		nodeVersion = pool.Version
+	}
+	if imageType == "" && pool.Config != nil {
+		imageType = pool.Config.ImageType
+	}
+	if pool.Config != nil && pool.Config.SandboxConfig != nil {
+		if err := checkSandboxImageType(imageType); err != nil {
+			return nil, nil, fmt.Errorf("node pool %s runs GKE Sandbox: %w", args.NodePoolID, err)
+		}
+	}
+	if isWindowsImageType(imageType) && (args.ImageType != "" || args.NodeVersion != "") {
+		cluster, err := h.getCluster(ctx, projectID, location, args.ClusterName, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkWindowsNodePool(cluster, imageType, nodeVersion, args.NodePoolID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	req := &container.UpdateNodePoolRequest{
+		NodeVersion: nodeVersion,
+		ImageType:   imageType,
+		MachineType: args.MachineType,
+	}
+	if args.Labels != nil {
+		req.Labels = &container.NodeLabels{Labels: args.Labels}
+	}
+	if taints != nil {
+		req.Taints = &container.NodeTaints{Taints: taints}
+	}
+	op, err := h.containerService.Projects.Locations.Clusters.NodePools.Update(name, req).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update node pool: %w", err)
+	}
+	return operationResult(op, projectID, location, what), nil, nil
+}
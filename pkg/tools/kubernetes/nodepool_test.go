@@ -0,0 +1,214 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/cache"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/container/v1"
+)
+
+func TestNodeImageType(t *testing.T) {
+	for _, tc := range []struct {
+		imageType string
+		want      string
+		windows   bool
+		wantErr   bool
+	}{
+		{imageType: "", want: ""},
+		{imageType: "cos_containerd", want: "COS_CONTAINERD"},
+		{imageType: "WINDOWS_LTSC_CONTAINERD", want: "WINDOWS_LTSC_CONTAINERD", windows: true},
+		{imageType: "WINDOWS_LTSC", wantErr: true},
+	} {
+		got, err := nodeImageType(tc.imageType)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("nodeImageType(%q) error = %v, wantErr %v", tc.imageType, err, tc.wantErr)
+		}
+		if got != tc.want || isWindowsImageType(got) != tc.windows {
+			t.Errorf("nodeImageType(%q) = %q, windows %t, want %q, windows %t", tc.imageType, got, isWindowsImageType(got), tc.want, tc.windows)
+		}
+	}
+}
+
+func TestParseNodeTaints(t *testing.T) {
+	got, err := parseNodeTaints([]string{"dedicated=windows:NoSchedule", "gpu:PreferNoSchedule"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*container.NodeTaint{
+		{Key: "dedicated", Value: "windows", Effect: "NO_SCHEDULE"},
+		{Key: "gpu", Effect: "PREFER_NO_SCHEDULE"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseNodeTaints() mismatch (-want +got):\n%s", diff)
+	}
+	if got, err := parseNodeTaints([]string{}); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("parseNodeTaints(empty) = %v, %v, want an empty list removing the taints", got, err)
+	}
+	for _, taint := range []string{"dedicated=windows", "dedicated=windows:Never", "=windows:NoSchedule"} {
+		if _, err := parseNodeTaints([]string{taint}); err == nil {
+			t.Errorf("parseNodeTaints(%q) succeeded, want error", taint)
+		}
+	}
+}
+
+func TestOAuthScopes(t *testing.T) {
+	got := oauthScopes([]string{"cloud-platform", "https://www.googleapis.com/auth/devstorage.read_only"})
+	want := []string{"https://www.googleapis.com/auth/cloud-platform", "https://www.googleapis.com/auth/devstorage.read_only"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("oauthScopes() mismatch (-want +got):\n%s", diff)
+	}
+	if got := oauthScopes(nil); got != nil {
+		t.Errorf("oauthScopes(nil) = %v, want nil", got)
+	}
+}
+
+func TestCheckNodeIdentity(t *testing.T) {
+	for _, tc := range []struct {
+		serviceAccount string
+		tags           []string
+		wantErr        bool
+	}{
+		{serviceAccount: "nodes@my-project.iam.gserviceaccount.com", tags: []string{"web", "allow-health-checks"}},
+		{serviceAccount: "default"},
+		{serviceAccount: "nodes", wantErr: true},
+		{tags: []string{"Web"}, wantErr: true},
+		{tags: []string{"1web"}, wantErr: true},
+	} {
+		err := checkNodeServiceAccount(tc.serviceAccount)
+		if err == nil {
+			err = checkNetworkTags(tc.tags)
+		}
+		if (err != nil) != tc.wantErr {
+			t.Errorf("checking service account %q and tags %v: error = %v, want error %v", tc.serviceAccount, tc.tags, err, tc.wantErr)
+		}
+	}
+}
+
+func TestSetSandbox(t *testing.T) {
+	for _, tc := range []struct {
+		imageType string
+		wantErr   bool
+	}{
+		{imageType: ""},
+		{imageType: "COS_CONTAINERD"},
+		{imageType: "UBUNTU_CONTAINERD", wantErr: true},
+		{imageType: "WINDOWS_LTSC_CONTAINERD", wantErr: true},
+	} {
+		config := &container.NodeConfig{ImageType: tc.imageType}
+		err := setSandbox(config)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("setSandbox(%q) error = %v, want error %v", tc.imageType, err, tc.wantErr)
+			continue
+		}
+		if err == nil && (config.ImageType != "COS_CONTAINERD" || config.SandboxConfig == nil || config.SandboxConfig.Type != "GVISOR") {
+			t.Errorf("setSandbox(%q) = image type %q, sandbox %+v, want COS_CONTAINERD and GVISOR", tc.imageType, config.ImageType, config.SandboxConfig)
+		}
+	}
+}
+
+func TestCheckWindowsNodePool(t *testing.T) {
+	linuxPool := &container.NodePool{Name: "default-pool", Config: &container.NodeConfig{ImageType: "COS_CONTAINERD"}}
+	windowsPool := &container.NodePool{Name: "win", Config: &container.NodeConfig{ImageType: "WINDOWS_LTSC_CONTAINERD"}}
+	for _, tc := range []struct {
+		name        string
+		cluster     *container.Cluster
+		nodeVersion string
+		pool        string
+		wantErr     string
+	}{
+		{
+			name:    "standard",
+			cluster: &container.Cluster{Name: "web", CurrentMasterVersion: "1.31.5-gke.1014001", NodePools: []*container.NodePool{linuxPool}},
+		},
+		{
+			name:    "autopilot",
+			cluster: &container.Cluster{Name: "web", CurrentMasterVersion: "1.31.5-gke.1014001", Autopilot: &container.Autopilot{Enabled: true}},
+			wantErr: "Autopilot cluster",
+		},
+		{
+			name:    "old control plane",
+			cluster: &container.Cluster{Name: "web", CurrentMasterVersion: "1.20.15-gke.300", NodePools: []*container.NodePool{linuxPool}},
+			wantErr: "need GKE 1.21 or later",
+		},
+		{
+			name:        "old node version",
+			cluster:     &container.Cluster{Name: "web", CurrentMasterVersion: "1.31.5-gke.1014001", NodePools: []*container.NodePool{linuxPool}},
+			nodeVersion: "1.20.15-gke.300",
+			wantErr:     "need GKE 1.21 or later",
+		},
+		{
+			name:    "no linux node pool",
+			cluster: &container.Cluster{Name: "web", CurrentMasterVersion: "1.31.5-gke.1014001", NodePools: []*container.NodePool{windowsPool}},
+			wantErr: "no Linux node pool",
+		},
+		{
+			name:    "only linux node pool updated",
+			cluster: &container.Cluster{Name: "web", CurrentMasterVersion: "1.31.5-gke.1014001", NodePools: []*container.NodePool{linuxPool}},
+			pool:    "default-pool",
+			wantErr: "no Linux node pool",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkWindowsNodePool(tc.cluster, "WINDOWS_LTSC_CONTAINERD", tc.nodeVersion, tc.pool)
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("checkWindowsNodePool() failed: %v", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("checkWindowsNodePool() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestWindowsLicensesEstimate(t *testing.T) {
+	e := &costEstimate{title: "Estimated cost of node pool win in cluster web", region: pricedRegion}
+	e.addNodes("e2-medium", 2, 4096, 3, 1)
+	e.addWindowsLicenses(2, 3)
+	want := `Estimated cost of node pool win in cluster web: ~$353/month
+  Nodes: 3 x e2-medium: ~$121/month
+  Boot disks: 3 x 100 GB: ~$30/month
+  Windows Server licenses: 3 x 2 vCPUs: ~$201/month
+`
+	if diff := cmp.Diff(want, e.String()); diff != "" {
+		t.Errorf("String() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpdateNodePoolArgs(t *testing.T) {
+	h := &handlers{c: config.New("test", config.Options{}), cache: cache.New(time.Hour)}
+	h.cache.Set("cluster:projects/p/locations/us-central1/clusters/ap", &container.Cluster{Name: "ap", Autopilot: &container.Autopilot{Enabled: true}})
+	enable := true
+	for _, tc := range []struct {
+		args    *gkeUpdateNodePoolArgs
+		wantErr string
+	}{
+		{args: &gkeUpdateNodePoolArgs{Location: "us-central1", ClusterName: "web"}, wantErr: "node_pool_id are required"},
+		{args: &gkeUpdateNodePoolArgs{Location: "us-central1", ClusterName: "web", NodePoolID: "pool"}, wantErr: "nothing to update"},
+		{args: &gkeUpdateNodePoolArgs{Location: "us-central1", ClusterName: "web", NodePoolID: "pool", EnableAutoscaling: &enable, ImageType: "COS_CONTAINERD"}, wantErr: "separate calls"},
+		{args: &gkeUpdateNodePoolArgs{ProjectID: "p", Location: "us-central1", ClusterName: "ap", NodePoolID: "pool", EnableAutoscaling: &enable}, wantErr: "is a GKE Autopilot cluster"},
+	} {
+		_, _, err := h.gkeUpdateNodePool(context.Background(), nil, tc.args)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("gkeUpdateNodePool(%+v) error = %v, want %q", tc.args, err, tc.wantErr)
+		}
+	}
+}
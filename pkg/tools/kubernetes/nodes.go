@@ -0,0 +1,276 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// NodeDiagnosticsToolDescription contains the documentation for the Node Diagnostics Kubernetes tool.
+// It is formatted in Markdown.
+const NodeDiagnosticsToolDescription = `
+This tool produces a health report for a single Kubernetes node in one call. It combines what would otherwise take *kubectl describe node*, *kubectl get pods --field-selector spec.nodeName=...* and *kubectl get events*.
+
+The report contains:
+
+* **System info**: kubelet and container runtime versions, OS image and kernel, whether the node is cordoned, and its taints.
+* **Conditions**: *Ready*, *MemoryPressure*, *DiskPressure*, *PIDPressure* and any other node conditions, with their reason and message.
+* **Resources**: allocatable CPU, memory, ephemeral storage and pods, compared with the requests and limits of the pods running on the node.
+* **Pods**: the non-terminated pods on the node with their phase, readiness and restarts.
+* **Events**: the most recent events about the node.
+* **GCE instance**: (Optional) for GKE and other GCE nodes, the status of the underlying Compute Engine instance, such as *RUNNING*, *STOPPING* or *TERMINATED*, and whether it is a Spot or preemptible VM.
+
+## Arguments
+
+* *name*: The name of the node.
+* *include_instance*: (Optional) Set to *true* to include the status of the Compute Engine instance backing the node.
+
+Example:
+{
+  "name": "gke-cluster-1-default-pool-1234abcd-wxyz",
+  "include_instance": true
+}
+`
+
+type nodeDiagnosticsArgs struct {
+	Name            string `json:"name"`
+	IncludeInstance bool   `json:"include_instance,omitempty"`
+}
+
+// maxNodeEvents is the number of most recent node events in the report.
+const maxNodeEvents = 20
+
+func (h *handlers) nodeDiagnostics(ctx context.Context, _ *mcp.CallToolRequest, args *nodeDiagnosticsArgs) (*mcp.CallToolResult, any, error) {
+	node, err := h.clientset.CoreV1().Nodes().Get(ctx, args.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	pods, err := h.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", node.Name).String(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods on node: %w", err)
+	}
+	events, err := h.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{"involvedObject.kind": "Node", "involvedObject.name": node.Name}.String(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list node events: %w", err)
+	}
+
+	var output strings.Builder
+	writeNodeInfo(&output, node)
+	writeNodeConditions(&output, node)
+
+	var active []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			active = append(active, pod)
+		}
+	}
+	writeNodeResources(&output, node, active)
+	writeNodePods(&output, active)
+	writeNodeEvents(&output, events.Items)
+
+	if args.IncludeInstance {
+		output.WriteString("\nGCE Instance:\n")
+		if err := h.writeGCEInstance(ctx, &output, node); err != nil {
+			output.WriteString(fmt.Sprintf("  unavailable: %v\n", err))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+func writeNodeInfo(out *strings.Builder, node *corev1.Node) {
+	info := node.Status.NodeInfo
+	out.WriteString(fmt.Sprintf("Node: %s\n", node.Name))
+	out.WriteString(fmt.Sprintf("Created: %s\n", node.CreationTimestamp.UTC().Format(time.RFC3339)))
+	out.WriteString(fmt.Sprintf("Kubelet Version: %s\n", info.KubeletVersion))
+	out.WriteString(fmt.Sprintf("Container Runtime: %s\n", info.ContainerRuntimeVersion))
+	out.WriteString(fmt.Sprintf("OS Image: %s\n", info.OSImage))
+	out.WriteString(fmt.Sprintf("Kernel Version: %s\n", info.KernelVersion))
+	out.WriteString(fmt.Sprintf("Unschedulable: %t\n", node.Spec.Unschedulable))
+	var taints []string
+	for _, t := range node.Spec.Taints {
+		taints = append(taints, t.ToString())
+	}
+	if len(taints) == 0 {
+		taints = []string{"<none>"}
+	}
+	out.WriteString(fmt.Sprintf("Taints: %s\n", strings.Join(taints, ", ")))
+}
+
+func writeNodeConditions(out *strings.Builder, node *corev1.Node) {
+	out.WriteString("\nConditions:\n")
+	out.WriteString("TYPE\tSTATUS\tREASON\tLAST_TRANSITION\tMESSAGE\n")
+	for _, c := range node.Status.Conditions {
+		out.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, c.LastTransitionTime.UTC().Format(time.RFC3339), c.Message))
+	}
+}
+
+func writeNodeResources(out *strings.Builder, node *corev1.Node, pods []corev1.Pod) {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for i := range pods {
+		podRequests, podLimits := podRequestsAndLimits(&pods[i])
+		addResources(requests, podRequests)
+		addResources(limits, podLimits)
+	}
+
+	out.WriteString("\nResources:\n")
+	out.WriteString("RESOURCE\tALLOCATABLE\tREQUESTS\tLIMITS\n")
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+		allocatable := node.Status.Allocatable[name]
+		out.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\n", name, allocatable.String(), formatShare(requests[name], allocatable), formatShare(limits[name], allocatable)))
+	}
+	allocatablePods := node.Status.Allocatable[corev1.ResourcePods]
+	out.WriteString(fmt.Sprintf("pods\t%s\t%d\t-\n", allocatablePods.String(), len(pods)))
+}
+
+// formatShare formats q and its share of total, e.g. "500m (25%)".
+func formatShare(q, total resource.Quantity) string {
+	if total.IsZero() {
+		return q.String()
+	}
+	return fmt.Sprintf("%s (%d%%)", q.String(), q.MilliValue()*100/total.MilliValue())
+}
+
+// podRequestsAndLimits returns the effective requests and limits of pod: the
+// sum over its containers, or the largest init container if higher, plus
+// the pod overhead.
+func podRequestsAndLimits(pod *corev1.Pod) (requests, limits corev1.ResourceList) {
+	requests, limits = corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range pod.Spec.Containers {
+		addResources(requests, c.Resources.Requests)
+		addResources(limits, c.Resources.Limits)
+	}
+	for _, c := range pod.Spec.InitContainers {
+		maxResources(requests, c.Resources.Requests)
+		maxResources(limits, c.Resources.Limits)
+	}
+	addResources(requests, pod.Spec.Overhead)
+	addResources(limits, pod.Spec.Overhead)
+	return requests, limits
+}
+
+func addResources(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		sum := dst[name]
+		sum.Add(q)
+		dst[name] = sum
+	}
+}
+
+func maxResources(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		if cur, ok := dst[name]; !ok || q.Cmp(cur) > 0 {
+			dst[name] = q.DeepCopy()
+		}
+	}
+}
+
+func writeNodePods(out *strings.Builder, pods []corev1.Pod) {
+	out.WriteString(fmt.Sprintf("\nPods (%d):\n", len(pods)))
+	out.WriteString("NAMESPACE\tNAME\tPHASE\tREADY\tRESTARTS\n")
+	for i := range pods {
+		pod := &pods[i]
+		var restarts int32
+		for _, cs := range pod.Status.ContainerStatuses {
+			restarts += cs.RestartCount
+		}
+		ready := "true"
+		if reason := podNotReadyReason(pod); reason != "" {
+			ready = "false: " + reason
+		}
+		out.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%d\n", pod.Namespace, pod.Name, pod.Status.Phase, ready, restarts))
+	}
+}
+
+func writeNodeEvents(out *strings.Builder, events []corev1.Event) {
+	sort.Slice(events, func(i, j int) bool { return eventTime(&events[i]).Before(eventTime(&events[j])) })
+	if len(events) > maxNodeEvents {
+		events = events[len(events)-maxNodeEvents:]
+	}
+	out.WriteString("\nEvents:\n")
+	if len(events) == 0 {
+		out.WriteString("<none>\n")
+		return
+	}
+	out.WriteString("LAST_SEEN\tTYPE\tREASON\tCOUNT\tMESSAGE\n")
+	for i := range events {
+		e := &events[i]
+		out.WriteString(fmt.Sprintf("%s\t%s\t%s\t%d\t%s\n", eventTime(e).UTC().Format(time.RFC3339), e.Type, e.Reason, e.Count, e.Message))
+	}
+}
+
+// parseGCEProviderID splits a provider ID of the form
+// gce://PROJECT/ZONE/INSTANCE.
+func parseGCEProviderID(providerID string) (project, zone, instance string, err error) {
+	rest, ok := strings.CutPrefix(providerID, "gce://")
+	parts := strings.Split(rest, "/")
+	if !ok || len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("node provider ID %q is not a GCE instance", providerID)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+func (h *handlers) writeGCEInstance(ctx context.Context, out *strings.Builder, node *corev1.Node) error {
+	project, zone, name, err := parseGCEProviderID(node.Spec.ProviderID)
+	if err != nil {
+		return err
+	}
+	instance, err := h.computeService.Instances.Get(project, zone, name).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get instance: %w", err)
+	}
+	out.WriteString(fmt.Sprintf("  Name: %s\n", instance.Name))
+	out.WriteString(fmt.Sprintf("  Zone: %s\n", zone))
+	out.WriteString(fmt.Sprintf("  Status: %s\n", instance.Status))
+	if instance.StatusMessage != "" {
+		out.WriteString(fmt.Sprintf("  Status Message: %s\n", instance.StatusMessage))
+	}
+	out.WriteString(fmt.Sprintf("  Machine Type: %s\n", path.Base(instance.MachineType)))
+	if sched := instance.Scheduling; sched != nil {
+		model := sched.ProvisioningModel
+		if model == "" && sched.Preemptible {
+			model = "PREEMPTIBLE"
+		}
+		if model != "" {
+			out.WriteString(fmt.Sprintf("  Provisioning Model: %s\n", model))
+		}
+	}
+	if instance.LastStartTimestamp != "" {
+		out.WriteString(fmt.Sprintf("  Last Start: %s\n", instance.LastStartTimestamp))
+	}
+	if instance.LastStopTimestamp != "" {
+		out.WriteString(fmt.Sprintf("  Last Stop: %s\n", instance.LastStopTimestamp))
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestPodRequestsAndLimits(t *testing.T) {
+	resources := func(cpu, memory string) corev1.ResourceRequirements {
+		list := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}
+		return corev1.ResourceRequirements{Requests: list, Limits: list}
+	}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{
+			{Resources: resources("100m", "128Mi")},
+			{Resources: resources("200m", "128Mi")},
+		},
+		// The init container needs more memory than all containers together.
+		InitContainers: []corev1.Container{
+			{Resources: resources("100m", "512Mi")},
+		},
+	}}
+
+	requests, _ := podRequestsAndLimits(pod)
+	if got, want := requests[corev1.ResourceCPU], resource.MustParse("300m"); got.Cmp(want) != 0 {
+		t.Errorf("cpu requests = %s, want %s", got.String(), want.String())
+	}
+	if got, want := requests[corev1.ResourceMemory], resource.MustParse("512Mi"); got.Cmp(want) != 0 {
+		t.Errorf("memory requests = %s, want %s", got.String(), want.String())
+	}
+}
+
+func TestParseGCEProviderID(t *testing.T) {
+	project, zone, instance, err := parseGCEProviderID("gce://my-project/us-central1-a/gke-node-1")
+	if err != nil {
+		t.Fatalf("parseGCEProviderID() error = %v", err)
+	}
+	if project != "my-project" || zone != "us-central1-a" || instance != "gke-node-1" {
+		t.Errorf("parseGCEProviderID() = %q, %q, %q", project, zone, instance)
+	}
+
+	for _, id := range []string{"", "aws:///us-east-1a/i-123", "gce://my-project/us-central1-a"} {
+		if _, _, _, err := parseGCEProviderID(id); err == nil {
+			t.Errorf("parseGCEProviderID(%q) succeeded, want error", id)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/pubsub/v1"
+)
+
+// GKERecentNotificationsToolDescription contains the documentation for the GKE Recent Notifications tool.
+// It is formatted in Markdown.
+const GKERecentNotificationsToolDescription = `
+This tool returns the GKE cluster notifications received by the server since it started, such as available and started upgrades, end of support notices and security bulletins. GKE publishes these notifications to the Pub/Sub topic configured on the cluster, and the server receives them through the subscription set with *--notifications-subscription*. Clients that set a logging level also receive each notification as a log message as soon as it arrives.
+
+Notification types include:
+
+* *UpgradeAvailableEvent*: a new version is available in the cluster's release channel.
+* *UpgradeEvent*: an upgrade of the control plane or of a node pool started.
+* *UpgradeInfoEvent*: the progress of an upgrade, or an end of support notice.
+* *SecurityBulletinEvent*: a security bulletin affects the cluster.
+
+## Arguments
+
+* *type*: (Optional) Only return notifications of this type, e.g. *SecurityBulletinEvent*. Case-insensitive.
+* *cluster_name*: (Optional) Only return notifications of this cluster.
+* *since*: (Optional) Only return notifications received in this period, as a duration such as *24h*.
+* *include_payload*: (Optional) Set to *true* to include the JSON payload of each notification, with details such as the target version or the bulletin URI.
+
+## Response Format
+
+The notifications, most recent first:
+
+TIME	TYPE	PROJECT	LOCATION	CLUSTER	SUMMARY
+2025-01-01T10:00:00Z	UpgradeEvent	123456789	us-central1	prod	Master of cluster prod is upgrading to version 1.30.5-gke.1014001.
+`
+
+type gkeRecentNotificationsArgs struct {
+	Type           string `json:"type,omitempty"`
+	ClusterName    string `json:"cluster_name,omitempty"`
+	Since          string `json:"since,omitempty"`
+	IncludePayload bool   `json:"include_payload,omitempty"`
+}
+
+const (
+	// maxClusterNotifications is the number of most recent notifications
+	// kept in memory.
+	maxClusterNotifications = 500
+	// notificationsPullBatch is the maximum number of messages of a pull.
+	notificationsPullBatch  = 100
+	maxNotificationsBackoff = time.Minute
+	// notificationsLogger is the logger name of the log messages sent to
+	// clients for notifications.
+	notificationsLogger = "gke-notifications"
+)
+
+// clusterNotification is a GKE cluster notification.
+type clusterNotification struct {
+	time      time.Time
+	eventType string
+	project   string
+	location  string
+	cluster   string
+	summary   string
+	payload   string
+}
+
+// newClusterNotification returns the notification of a message published by
+// GKE: the summary is the data of the message, and the details are in its
+// attributes.
+func newClusterNotification(m *pubsub.PubsubMessage) (clusterNotification, error) {
+	data, err := base64.StdEncoding.DecodeString(m.Data)
+	if err != nil {
+		return clusterNotification{}, fmt.Errorf("failed to decode message %s: %w", m.MessageId, err)
+	}
+	n := clusterNotification{
+		project:  m.Attributes["project_id"],
+		location: m.Attributes["cluster_location"],
+		cluster:  m.Attributes["cluster_name"],
+		summary:  strings.TrimSpace(string(data)),
+		payload:  m.Attributes["payload"],
+	}
+	// Types are of the form type.googleapis.com/google.container.v1beta1.UpgradeEvent.
+	typeURL := m.Attributes["type_url"]
+	n.eventType = typeURL[strings.LastIndex(typeURL, ".")+1:]
+	if n.eventType == "" {
+		n.eventType = "(unknown)"
+	}
+	n.time, err = time.Parse(time.RFC3339Nano, m.PublishTime)
+	if err != nil {
+		n.time = time.Now()
+	}
+	return n, nil
+}
+
+// logParams returns the log message sent to clients for n. Security
+// bulletins are warnings.
+func (n clusterNotification) logParams() *mcp.LoggingMessageParams {
+	level := mcp.LoggingLevel("notice")
+	if n.eventType == "SecurityBulletinEvent" {
+		level = "warning"
+	}
+	data := map[string]any{
+		"time":     n.time.UTC().Format(time.RFC3339),
+		"type":     n.eventType,
+		"project":  n.project,
+		"location": n.location,
+		"cluster":  n.cluster,
+		"summary":  n.summary,
+	}
+	var payload any
+	if err := json.Unmarshal([]byte(n.payload), &payload); err == nil {
+		data["payload"] = payload
+	}
+	return &mcp.LoggingMessageParams{Logger: notificationsLogger, Level: level, Data: data}
+}
+
+// clusterNotifications receives the notifications of a Pub/Sub subscription
+// and keeps the most recent ones.
+type clusterNotifications struct {
+	subscription string
+
+	mu sync.Mutex
+	// recent are the notifications in the order they were received.
+	recent []clusterNotification
+}
+
+func newClusterNotifications(subscription string) *clusterNotifications {
+	return &clusterNotifications{subscription: subscription}
+}
+
+func (n *clusterNotifications) add(cn clusterNotification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.recent = append(n.recent, cn)
+	if len(n.recent) > maxClusterNotifications {
+		n.recent = n.recent[len(n.recent)-maxClusterNotifications:]
+	}
+}
+
+// list returns the notifications of eventType and cluster, if not empty,
+// published after since, most recent first.
+func (n *clusterNotifications) list(eventType, cluster string, since time.Time) []clusterNotification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var list []clusterNotification
+	for i := len(n.recent) - 1; i >= 0; i-- {
+		cn := n.recent[i]
+		if eventType != "" && !strings.EqualFold(cn.eventType, eventType) {
+			continue
+		}
+		if cluster != "" && cn.cluster != cluster {
+			continue
+		}
+		if cn.time.Before(since) {
+			continue
+		}
+		list = append(list, cn)
+	}
+	return list
+}
+
+// run pulls the messages of the subscription until ctx is done, and sends
+// each notification to the clients connected to s.
+func (n *clusterNotifications) run(ctx context.Context, svc *pubsub.Service, s *mcp.Server) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		resp, err := svc.Projects.Subscriptions.Pull(n.subscription, &pubsub.PullRequest{MaxMessages: notificationsPullBatch}).Context(ctx).Do()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("Failed to pull GKE cluster notifications", "subscription", n.subscription, "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxNotificationsBackoff)
+			continue
+		}
+		backoff = time.Second
+
+		var ackIDs []string
+		for _, rm := range resp.ReceivedMessages {
+			ackIDs = append(ackIDs, rm.AckId)
+			cn, err := newClusterNotification(rm.Message)
+			if err != nil {
+				slog.Warn("Dropped GKE cluster notification", "error", err)
+				continue
+			}
+			n.add(cn)
+			for ss := range s.Sessions() {
+				if err := ss.Log(ctx, cn.logParams()); err != nil {
+					slog.Debug("Failed to send GKE cluster notification to client", "error", err)
+				}
+			}
+		}
+		if len(ackIDs) > 0 {
+			if _, err := svc.Projects.Subscriptions.Acknowledge(n.subscription, &pubsub.AcknowledgeRequest{AckIds: ackIDs}).Context(ctx).Do(); err != nil && ctx.Err() == nil {
+				slog.Warn("Failed to acknowledge GKE cluster notifications", "subscription", n.subscription, "error", err)
+			}
+		}
+	}
+}
+
+func (h *handlers) gkeRecentNotifications(ctx context.Context, _ *mcp.CallToolRequest, args *gkeRecentNotificationsArgs) (*mcp.CallToolResult, any, error) {
+	var since time.Time
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		since = time.Now().Add(-d)
+	}
+	list := h.notifications.list(args.Type, args.ClusterName, since)
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%d notifications received from %s.\n", len(list), h.notifications.subscription))
+	if len(list) > 0 {
+		output.WriteString("TIME\tTYPE\tPROJECT\tLOCATION\tCLUSTER\tSUMMARY\n")
+	}
+	for _, cn := range list {
+		output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\n",
+			cn.time.UTC().Format(time.RFC3339), cn.eventType, cn.project, cn.location, cn.cluster, cn.summary))
+		if args.IncludePayload && cn.payload != "" {
+			output.WriteString("  payload: " + cn.payload + "\n")
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+)
+
+func testNotificationMessage(id, typeURL, cluster, summary string, published time.Time) *pubsub.PubsubMessage {
+	return &pubsub.PubsubMessage{
+		MessageId:   id,
+		Data:        base64.StdEncoding.EncodeToString([]byte(summary)),
+		PublishTime: published.Format(time.RFC3339Nano),
+		Attributes: map[string]string{
+			"project_id":       "123456789",
+			"cluster_location": "us-central1",
+			"cluster_name":     cluster,
+			"type_url":         typeURL,
+			"payload":          `{"resourceType":"MASTER"}`,
+		},
+	}
+}
+
+func TestNewClusterNotification(t *testing.T) {
+	published := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	got, err := newClusterNotification(testNotificationMessage("1", "type.googleapis.com/google.container.v1beta1.UpgradeEvent", "prod", "Master is upgrading.\n", published))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := clusterNotification{
+		time:      published,
+		eventType: "UpgradeEvent",
+		project:   "123456789",
+		location:  "us-central1",
+		cluster:   "prod",
+		summary:   "Master is upgrading.",
+		payload:   `{"resourceType":"MASTER"}`,
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(clusterNotification{})); diff != "" {
+		t.Errorf("newClusterNotification() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := newClusterNotification(&pubsub.PubsubMessage{Data: "not base64!"}); err == nil {
+		t.Error("newClusterNotification() of invalid data = nil error, want error")
+	}
+}
+
+func TestClusterNotificationsList(t *testing.T) {
+	now := time.Now()
+	n := newClusterNotifications("projects/p/subscriptions/s")
+	for i := 0; i < maxClusterNotifications; i++ {
+		n.add(clusterNotification{time: now.Add(-48 * time.Hour), eventType: "UpgradeInfoEvent", cluster: "old"})
+	}
+	n.add(clusterNotification{time: now.Add(-2 * time.Hour), eventType: "UpgradeEvent", cluster: "prod", summary: "upgrade"})
+	n.add(clusterNotification{time: now.Add(-time.Hour), eventType: "SecurityBulletinEvent", cluster: "prod", summary: "bulletin"})
+	n.add(clusterNotification{time: now.Add(-time.Minute), eventType: "UpgradeEvent", cluster: "dev", summary: "dev upgrade"})
+
+	if got := len(n.list("", "", time.Time{})); got != maxClusterNotifications {
+		t.Errorf("list() returned %d notifications, want %d", got, maxClusterNotifications)
+	}
+	summaries := func(list []clusterNotification) []string {
+		var s []string
+		for _, cn := range list {
+			s = append(s, cn.summary)
+		}
+		return s
+	}
+	if diff := cmp.Diff([]string{"dev upgrade", "bulletin", "upgrade"}, summaries(n.list("", "", now.Add(-24*time.Hour)))); diff != "" {
+		t.Errorf("list() since mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"upgrade"}, summaries(n.list("upgradeevent", "prod", time.Time{}))); diff != "" {
+		t.Errorf("list() of type and cluster mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClusterNotificationsRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const subscription = "projects/p/subscriptions/s"
+	var mu sync.Mutex
+	var acked []string
+	pulled := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/v1/" + subscription + ":pull":
+			resp := &pubsub.PullResponse{}
+			if pulled {
+				// Pulls wait for messages.
+				time.Sleep(10 * time.Millisecond)
+			} else {
+				pulled = true
+				resp.ReceivedMessages = []*pubsub.ReceivedMessage{{
+					AckId:   "ack-1",
+					Message: testNotificationMessage("1", "type.googleapis.com/google.container.v1beta1.SecurityBulletinEvent", "prod", "GCP-2025-001", time.Now()),
+				}}
+			}
+			json.NewEncoder(w).Encode(resp)
+		case "/v1/" + subscription + ":acknowledge":
+			var req pubsub.AcknowledgeRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			acked = append(acked, req.AckIds...)
+			w.Write([]byte("{}"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	svc, err := pubsub.NewService(ctx, option.WithEndpoint(srv.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	logs := make(chan *mcp.LoggingMessageParams, 1)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			logs <- req.Params
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+
+	n := newClusterNotifications(subscription)
+	go n.run(ctx, svc, server)
+
+	select {
+	case params := <-logs:
+		if params.Logger != notificationsLogger || params.Level != "warning" {
+			t.Errorf("log message logger, level = %s, %s, want %s, warning", params.Logger, params.Level, notificationsLogger)
+		}
+		if b, _ := json.Marshal(params.Data); !strings.Contains(string(b), `"summary":"GCP-2025-001"`) {
+			t.Errorf("log message data = %s, want the summary", b)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("no log message received")
+	}
+	if got := n.list("", "", time.Time{}); len(got) != 1 || got[0].eventType != "SecurityBulletinEvent" {
+		t.Errorf("list() = %v, want the security bulletin", got)
+	}
+
+	// The acknowledgement is sent after the log message.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		mu.Lock()
+		done := len(acked) > 0
+		mu.Unlock()
+		if done || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if diff := cmp.Diff([]string{"ack-1"}, acked); diff != "" {
+		t.Errorf("acknowledged mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FindOrphansToolDescription contains the documentation for the Find Orphans Kubernetes tool.
+// It is formatted in Markdown.
+const FindOrphansToolDescription = `
+This tool finds resources that have no owner and no consumers, which are candidates for cleanup and cost reduction:
+
+* **PersistentVolumeClaims** not mounted by any pod. Unused claims keep their disks, and their cost, around.
+* **ConfigMaps** and **Secrets** not referenced by any pod, service account or ingress. Service account tokens, Helm release records and the *kube-root-ca.crt* ConfigMap are not reported.
+* **Services** whose selector matches no ready pods, so they have no endpoints.
+* **Jobs** that finished and were not cleaned up: either their *ttlSecondsAfterFinished* has passed, or they have no TTL and finished more than a day ago. Jobs owned by a CronJob are not reported, since the CronJob's history limits clean them up.
+
+Resources with an owner reference are never reported. References from custom resources, e.g. a ConfigMap used by an operator, are not detected, so **always confirm with the user before deleting anything reported by this tool**.
+
+## Arguments
+
+* *namespace*: (Optional) The namespace to scan. Defaults to the server's default namespace.
+* *all_namespaces*: (Optional) Set to *true* to scan all namespaces.
+
+## Response Format
+
+A table with one row per orphaned resource:
+
+KIND                   NAMESPACE  NAME           AGE   REASON
+PersistentVolumeClaim  default    data-old-db-0  90d   not mounted by any pod
+Service                default    legacy-api     200d  no ready endpoints
+`
+
+type findOrphansArgs struct {
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+}
+
+// finishedJobRetention is how long a finished Job without a TTL is kept
+// before being reported.
+const finishedJobRetention = 24 * time.Hour
+
+// orphan is a resource without owner or consumers.
+type orphan struct {
+	kind, namespace, name string
+	created               time.Time
+	reason                string
+}
+
+func (h *handlers) findOrphans(ctx context.Context, _ *mcp.CallToolRequest, args *findOrphansArgs) (*mcp.CallToolResult, any, error) {
+	namespace, err := h.namespaceScope(args.Namespace, args.AllNamespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	orphans, err := h.listOrphans(ctx, namespace, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var output strings.Builder
+	output.WriteString("KIND\tNAMESPACE\tNAME\tAGE\tREASON\n")
+	for _, o := range orphans {
+		output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n", o.kind, o.namespace, o.name, formatAge(time.Since(o.created)), o.reason))
+	}
+	output.WriteString(fmt.Sprintf("\nFound %d orphaned resources in %s.", len(orphans), describeScope(namespace)))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+// formatAge formats d like kubectl, e.g. 45s, 10m, 5h or 3d.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// listOrphans returns the orphaned resources in namespace, or in all
+// namespaces if namespace is empty, sorted by kind, namespace and name.
+func (h *handlers) listOrphans(ctx context.Context, namespace string, now time.Time) ([]orphan, error) {
+	core := h.clientset.CoreV1()
+	pods, err := core.Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	serviceAccounts, err := core.ServiceAccounts(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	ingresses, err := h.clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingresses: %w", err)
+	}
+
+	refs := newPodReferences()
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		refs.addPod(pod)
+	}
+	for _, sa := range serviceAccounts.Items {
+		for _, s := range sa.ImagePullSecrets {
+			refs.secrets[sa.Namespace+"/"+s.Name] = true
+		}
+		for _, s := range sa.Secrets {
+			refs.secrets[sa.Namespace+"/"+s.Name] = true
+		}
+	}
+	for _, ing := range ingresses.Items {
+		for _, tls := range ing.Spec.TLS {
+			refs.secrets[ing.Namespace+"/"+tls.SecretName] = true
+		}
+	}
+
+	var orphans []orphan
+	add := func(kind string, obj metav1.Object, reason string) {
+		orphans = append(orphans, orphan{kind: kind, namespace: obj.GetNamespace(), name: obj.GetName(), created: obj.GetCreationTimestamp().Time, reason: reason})
+	}
+
+	pvcs, err := core.PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list persistent volume claims: %w", err)
+	}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if len(pvc.OwnerReferences) == 0 && !refs.pvcs[pvc.Namespace+"/"+pvc.Name] {
+			add("PersistentVolumeClaim", pvc, "not mounted by any pod")
+		}
+	}
+
+	configMaps, err := core.ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list config maps: %w", err)
+	}
+	for i := range configMaps.Items {
+		cm := &configMaps.Items[i]
+		if cm.Name == "kube-root-ca.crt" || len(cm.OwnerReferences) > 0 {
+			continue
+		}
+		if !refs.configMaps[cm.Namespace+"/"+cm.Name] {
+			add("ConfigMap", cm, "not referenced by any pod")
+		}
+	}
+
+	secrets, err := core.Secrets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		switch {
+		case len(secret.OwnerReferences) > 0,
+			secret.Type == corev1.SecretTypeServiceAccountToken,
+			strings.HasPrefix(string(secret.Type), "helm.sh/"):
+			continue
+		}
+		if !refs.secrets[secret.Namespace+"/"+secret.Name] {
+			add("Secret", secret, "not referenced by any pod, service account or ingress")
+		}
+	}
+
+	services, err := core.Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	slices, err := h.clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoint slices: %w", err)
+	}
+	withEndpoints := map[string]bool{}
+	for _, slice := range slices.Items {
+		service := slice.Labels[discoveryv1.LabelServiceName]
+		for _, ep := range slice.Endpoints {
+			if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+				withEndpoints[slice.Namespace+"/"+service] = true
+			}
+		}
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		// Services without a selector have manually managed endpoints.
+		if svc.Spec.Type == corev1.ServiceTypeExternalName || len(svc.Spec.Selector) == 0 || len(svc.OwnerReferences) > 0 {
+			continue
+		}
+		if !withEndpoints[svc.Namespace+"/"+svc.Name] {
+			add("Service", svc, "no ready endpoints")
+		}
+	}
+
+	jobs, err := h.clientset.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if len(job.OwnerReferences) > 0 {
+			continue
+		}
+		if reason := finishedJobReason(job, now); reason != "" {
+			add("Job", job, reason)
+		}
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		a, b := orphans[i], orphans[j]
+		if a.kind != b.kind {
+			return a.kind < b.kind
+		}
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		return a.name < b.name
+	})
+	return orphans, nil
+}
+
+// finishedJobReason explains why a finished job should have been cleaned up
+// by now, or returns the empty string if it is still running or recent.
+func finishedJobReason(job *batchv1.Job, now time.Time) string {
+	var finished time.Time
+	for _, c := range job.Status.Conditions {
+		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
+			finished = c.LastTransitionTime.Time
+		}
+	}
+	if finished.IsZero() {
+		return ""
+	}
+	if ttl := job.Spec.TTLSecondsAfterFinished; ttl != nil {
+		if expiry := finished.Add(time.Duration(*ttl) * time.Second); now.After(expiry) {
+			return fmt.Sprintf("finished %s ago, past its TTL of %ds", formatAge(now.Sub(finished)), *ttl)
+		}
+		return ""
+	}
+	if now.Sub(finished) > finishedJobRetention {
+		return fmt.Sprintf("finished %s ago and has no TTL", formatAge(now.Sub(finished)))
+	}
+	return ""
+}
+
+// podReferences records the ConfigMaps, Secrets and PersistentVolumeClaims
+// used by pods, keyed by namespace/name.
+type podReferences struct {
+	configMaps, secrets, pvcs map[string]bool
+}
+
+func newPodReferences() *podReferences {
+	return &podReferences{configMaps: map[string]bool{}, secrets: map[string]bool{}, pvcs: map[string]bool{}}
+}
+
+func (r *podReferences) addPod(pod *corev1.Pod) {
+	ns := pod.Namespace + "/"
+	for _, s := range pod.Spec.ImagePullSecrets {
+		r.secrets[ns+s.Name] = true
+	}
+	for _, v := range pod.Spec.Volumes {
+		switch {
+		case v.ConfigMap != nil:
+			r.configMaps[ns+v.ConfigMap.Name] = true
+		case v.Secret != nil:
+			r.secrets[ns+v.Secret.SecretName] = true
+		case v.PersistentVolumeClaim != nil:
+			r.pvcs[ns+v.PersistentVolumeClaim.ClaimName] = true
+		case v.Ephemeral != nil:
+			// Generic ephemeral volumes are backed by a claim named after
+			// the pod and volume.
+			r.pvcs[ns+pod.Name+"-"+v.Name] = true
+		case v.Projected != nil:
+			for _, src := range v.Projected.Sources {
+				if src.ConfigMap != nil {
+					r.configMaps[ns+src.ConfigMap.Name] = true
+				}
+				if src.Secret != nil {
+					r.secrets[ns+src.Secret.Name] = true
+				}
+			}
+		}
+	}
+	containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, c := range containers {
+		for _, env := range c.EnvFrom {
+			if env.ConfigMapRef != nil {
+				r.configMaps[ns+env.ConfigMapRef.Name] = true
+			}
+			if env.SecretRef != nil {
+				r.secrets[ns+env.SecretRef.Name] = true
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				r.configMaps[ns+ref.Name] = true
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				r.secrets[ns+ref.Name] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestListOrphans(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "default"}
+	}
+	finishedJob := func(name string, finished time.Time, ttl *int32) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: meta(name),
+			Spec:       batchv1.JobSpec{TTLSecondsAfterFinished: ttl},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+				Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(finished),
+			}}},
+		}
+	}
+	clientset := fake.NewClientset(
+		&corev1.Pod{
+			ObjectMeta: meta("web"),
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{
+					{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "used-pvc"}}},
+					{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "used-cm"}}}},
+				},
+				Containers: []corev1.Container{{
+					Name: "main",
+					Env: []corev1.EnvVar{{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{
+						SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "used-secret"}, Key: "password"},
+					}}},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.PersistentVolumeClaim{ObjectMeta: meta("used-pvc")},
+		&corev1.PersistentVolumeClaim{ObjectMeta: meta("old-pvc")},
+		&corev1.ConfigMap{ObjectMeta: meta("used-cm")},
+		&corev1.ConfigMap{ObjectMeta: meta("unused-cm")},
+		&corev1.ConfigMap{ObjectMeta: meta("kube-root-ca.crt")},
+		&corev1.Secret{ObjectMeta: meta("used-secret")},
+		&corev1.Secret{ObjectMeta: meta("sa-token"), Type: corev1.SecretTypeServiceAccountToken},
+		&corev1.Secret{ObjectMeta: meta("unused-secret")},
+		&corev1.Service{ObjectMeta: meta("legacy"), Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "legacy"}}},
+		&corev1.Service{ObjectMeta: meta("external"), Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName}},
+		finishedJob("expired", now.Add(-2*time.Hour), ptr.To[int32](60)),
+		finishedJob("recent", now.Add(-time.Hour), nil),
+		finishedJob("stale", now.Add(-72*time.Hour), nil),
+	)
+	h := &handlers{clientset: clientset}
+
+	orphans, err := h.listOrphans(context.Background(), "default", now)
+	if err != nil {
+		t.Fatalf("listOrphans() error = %v", err)
+	}
+	var got []string
+	for _, o := range orphans {
+		got = append(got, o.kind+" "+o.name+": "+o.reason)
+	}
+	want := []string{
+		"ConfigMap unused-cm: not referenced by any pod",
+		"Job expired: finished 2h ago, past its TTL of 60s",
+		"Job stale: finished 3d ago and has no TTL",
+		"PersistentVolumeClaim old-pvc: not mounted by any pod",
+		"Secret unused-secret: not referenced by any pod, service account or ingress",
+		"Service legacy: no ready endpoints",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("listOrphans() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// resourcePrinter renders the resources returned by kube_get_resources in a
+// format other than YAML. The resources are added one by one while they are
+// listed, and rendered once all of them are known.
+type resourcePrinter interface {
+	add(obj *unstructured.Unstructured) error
+	flush(out *strings.Builder) error
+}
+
+const (
+	outputYAML       = "yaml"
+	outputCSV        = "csv"
+	outputSummary    = "summary"
+	outputGoTemplate = "go-template="
+)
+
+// defaultCSVColumns are the columns of the CSV output when no custom columns
+// are given.
+const defaultCSVColumns = "NAMESPACE:.metadata.namespace,NAME:.metadata.name,KIND:.kind,CREATED:.metadata.creationTimestamp"
+
+// newResourcePrinter returns the printer of args, or nil for the default
+// YAML output.
+func newResourcePrinter(args *getResourcesArgs) (resourcePrinter, error) {
+	switch {
+	case args.Output == "" || args.Output == outputYAML:
+		if args.CustomColumns == "" {
+			return nil, nil
+		}
+		return newCustomColumnsPrinter(args.CustomColumns, args.NoHeaders)
+	case args.Output == outputCSV:
+		columns := args.CustomColumns
+		if columns == "" {
+			columns = defaultCSVColumns
+		}
+		p, err := newCustomColumnsPrinter(columns, args.NoHeaders)
+		if err != nil {
+			return nil, err
+		}
+		p.missing = ""
+		return &csvPrinter{p}, nil
+	case args.Output == outputSummary:
+		if args.CustomColumns != "" {
+			return nil, fmt.Errorf("customColumns cannot be combined with output %q", outputSummary)
+		}
+		return newSummaryPrinter(), nil
+	case strings.HasPrefix(args.Output, outputGoTemplate):
+		if args.CustomColumns != "" {
+			return nil, fmt.Errorf("customColumns cannot be combined with output %q", outputGoTemplate+"...")
+		}
+		return newGoTemplatePrinter(strings.TrimPrefix(args.Output, outputGoTemplate), args.Name != "")
+	default:
+		return nil, fmt.Errorf("invalid output %q, expected %q, %q, %q or %q", args.Output, outputYAML, outputCSV, outputSummary, outputGoTemplate+"<template>")
+	}
+}
+
+// csvPrinter renders resources as CSV records of custom columns.
+type csvPrinter struct {
+	*customColumnsPrinter
+}
+
+func (p *csvPrinter) flush(out *strings.Builder) error {
+	w := csv.NewWriter(out)
+	if !p.noHeaders {
+		if err := w.Write(p.headers); err != nil {
+			return fmt.Errorf("failed to write CSV header: %w", err)
+		}
+	}
+	if err := w.WriteAll(p.rows); err != nil {
+		return fmt.Errorf("failed to write CSV records: %w", err)
+	}
+	return nil
+}
+
+// templateFuncs are the functions available to the go-template output, in
+// addition to the built-in ones.
+var templateFuncs = template.FuncMap{
+	"base64decode": func(s string) (string, error) {
+		data, err := base64.StdEncoding.DecodeString(s)
+		return string(data), err
+	},
+	"base64encode": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"toJSON": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// goTemplatePrinter renders resources with a Go template. As with kubectl,
+// the template is executed on the resource when a single resource is
+// fetched, and on a List holding the resources in items otherwise.
+type goTemplatePrinter struct {
+	tmpl   *template.Template
+	single bool
+	items  []any
+}
+
+func newGoTemplatePrinter(text string, single bool) (*goTemplatePrinter, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go-template: %w", err)
+	}
+	return &goTemplatePrinter{tmpl: tmpl, single: single}, nil
+}
+
+func (p *goTemplatePrinter) add(obj *unstructured.Unstructured) error {
+	p.items = append(p.items, obj.Object)
+	return nil
+}
+
+func (p *goTemplatePrinter) flush(out *strings.Builder) error {
+	items := p.items
+	if items == nil {
+		items = []any{}
+	}
+	var data any = map[string]any{"apiVersion": "v1", "kind": "List", "items": items}
+	if p.single && len(p.items) == 1 {
+		data = p.items[0]
+	}
+	if err := p.tmpl.Execute(out, data); err != nil {
+		return fmt.Errorf("failed to execute go-template: %w", err)
+	}
+	return nil
+}
+
+// summaryPrinter renders aggregate counts of resources instead of the
+// resources: the number of resources per namespace, and kind specific
+// breakdowns such as the pods by phase or the ready deployments.
+type summaryPrinter struct {
+	kinds      map[string]int
+	namespaces map[string]int
+	// breakdowns are counts by value of the breakdown titles, e.g. "phase",
+	// in order of first appearance.
+	titles     []string
+	breakdowns map[string]map[string]int
+	// ready and desired are the replicas of workloads.
+	ready, desired int64
+	workloads      bool
+	// pods and restartedPods count the pods, and the ones with restarted
+	// containers.
+	pods, restartedPods int
+}
+
+func newSummaryPrinter() *summaryPrinter {
+	return &summaryPrinter{kinds: map[string]int{}, namespaces: map[string]int{}, breakdowns: map[string]map[string]int{}}
+}
+
+// count counts value in the breakdown title.
+func (p *summaryPrinter) count(title, value string) {
+	if value == "" {
+		value = "<none>"
+	}
+	counts, ok := p.breakdowns[title]
+	if !ok {
+		counts = map[string]int{}
+		p.breakdowns[title] = counts
+		p.titles = append(p.titles, title)
+	}
+	counts[value]++
+}
+
+// countReplicas counts a workload of ready out of desired replicas.
+func (p *summaryPrinter) countReplicas(ready, desired int64) {
+	p.workloads = true
+	p.ready += ready
+	p.desired += desired
+	if ready >= desired {
+		p.count("readiness", "ready")
+	} else {
+		p.count("readiness", "not ready")
+	}
+}
+
+func (p *summaryPrinter) add(obj *unstructured.Unstructured) error {
+	p.kinds[obj.GetKind()]++
+	if ns := obj.GetNamespace(); ns != "" {
+		p.namespaces[ns]++
+	}
+	field := func(fields ...string) int64 {
+		v, _, _ := unstructured.NestedInt64(obj.Object, fields...)
+		return v
+	}
+	str := func(fields ...string) string {
+		v, _, _ := unstructured.NestedString(obj.Object, fields...)
+		return v
+	}
+
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		desired, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			desired = 1
+		}
+		p.countReplicas(field("status", "readyReplicas"), desired)
+	case "DaemonSet":
+		p.countReplicas(field("status", "numberReady"), field("status", "desiredNumberScheduled"))
+	case "Job":
+		switch {
+		case hasCondition(obj, "Complete"):
+			p.count("status", "Complete")
+		case hasCondition(obj, "Failed"):
+			p.count("status", "Failed")
+		case field("status", "active") > 0:
+			p.count("status", "Active")
+		default:
+			p.count("status", "Pending")
+		}
+	case "Node":
+		if hasCondition(obj, "Ready") {
+			p.count("readiness", "Ready")
+		} else {
+			p.count("readiness", "NotReady")
+		}
+		if unschedulable, _, _ := unstructured.NestedBool(obj.Object, "spec", "unschedulable"); unschedulable {
+			p.count("scheduling", "cordoned")
+		}
+	case "Service":
+		p.count("type", str("spec", "type"))
+	default:
+		if phase := str("status", "phase"); phase != "" {
+			p.count("phase", phase)
+		}
+	}
+	if obj.GetKind() == "Pod" {
+		p.pods++
+		var restarts int64
+		statuses, _, _ := unstructured.NestedSlice(obj.Object, "status", "containerStatuses")
+		for _, s := range statuses {
+			if m, ok := s.(map[string]any); ok {
+				n, _, _ := unstructured.NestedInt64(m, "restartCount")
+				restarts += n
+			}
+		}
+		if restarts > 0 {
+			p.restartedPods++
+		}
+	}
+	return nil
+}
+
+// jobCondition returns whether obj has the condition of type conditionType
+// with status True.
+func hasCondition(obj *unstructured.Unstructured, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]any)
+		if ok && m["type"] == conditionType && m["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCounts writes counts to out, by decreasing count.
+func writeCounts(out *strings.Builder, counts map[string]int) {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	for _, k := range keys {
+		fmt.Fprintf(out, "  %s\t%d\n", k, counts[k])
+	}
+}
+
+func (p *summaryPrinter) flush(out *strings.Builder) error {
+	total := 0
+	for _, n := range p.kinds {
+		total += n
+	}
+	fmt.Fprintf(out, "Total: %d\n", total)
+	if len(p.kinds) > 1 {
+		out.WriteString("By kind:\n")
+		writeCounts(out, p.kinds)
+	}
+	if len(p.namespaces) > 1 {
+		fmt.Fprintf(out, "By namespace (%d):\n", len(p.namespaces))
+		writeCounts(out, p.namespaces)
+	}
+	for _, title := range p.titles {
+		fmt.Fprintf(out, "By %s:\n", title)
+		writeCounts(out, p.breakdowns[title])
+	}
+	if p.workloads {
+		fmt.Fprintf(out, "Replicas: %d/%d ready\n", p.ready, p.desired)
+	}
+	if p.pods > 0 {
+		fmt.Fprintf(out, "Pods with restarted containers: %d/%d\n", p.restartedPods, p.pods)
+	}
+	return nil
+}
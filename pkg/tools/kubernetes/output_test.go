@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func printResources(t *testing.T, args *getResourcesArgs, items ...unstructured.Unstructured) string {
+	t.Helper()
+	printer, err := newResourcePrinter(args)
+	if err != nil {
+		t.Fatalf("newResourcePrinter(%+v) returned error: %v", args, err)
+	}
+	for i := range items {
+		if err := printer.add(&items[i]); err != nil {
+			t.Fatalf("add() returned error: %v", err)
+		}
+	}
+	var out strings.Builder
+	if err := printer.flush(&out); err != nil {
+		t.Fatalf("flush() returned error: %v", err)
+	}
+	return out.String()
+}
+
+func TestResourcePrinters(t *testing.T) {
+	pods := []unstructured.Unstructured{newPod("pod-1", "nginx:latest"), newPod("pod-2", `registry/"quoted",image`)}
+	for _, tc := range []struct {
+		name string
+		args getResourcesArgs
+		want string
+	}{
+		{
+			name: "csv",
+			args: getResourcesArgs{Output: "csv"},
+			want: "NAMESPACE,NAME,KIND,CREATED\ndefault,pod-1,Pod,\ndefault,pod-2,Pod,\n",
+		},
+		{
+			name: "csv with custom columns",
+			args: getResourcesArgs{Output: "csv", CustomColumns: "NAME:.metadata.name,IMAGE:.spec.containers[*].image", NoHeaders: true},
+			want: "pod-1,nginx:latest\npod-2,\"registry/\"\"quoted\"\",image\"\n",
+		},
+		{
+			name: "go-template on list",
+			args: getResourcesArgs{Output: `go-template={{range .items}}{{.metadata.name}} {{(index .spec.containers 0).image | base64encode}}{{"\n"}}{{end}}`},
+			want: "pod-1 bmdpbng6bGF0ZXN0\npod-2 cmVnaXN0cnkvInF1b3RlZCIsaW1hZ2U=\n",
+		},
+	} {
+		if diff := cmp.Diff(tc.want, printResources(t, &tc.args, pods...)); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", tc.name, diff)
+		}
+	}
+
+	args := &getResourcesArgs{Name: "pod-1", Output: "go-template={{.kind}}/{{.metadata.name}}"}
+	if got := printResources(t, args, pods[0]); got != "Pod/pod-1" {
+		t.Errorf("go-template on resource = %q, want %q", got, "Pod/pod-1")
+	}
+
+	for _, args := range []*getResourcesArgs{
+		{Output: "json"},
+		{Output: "go-template={{.metadata.name"},
+		{Output: "go-template={{.kind}}", CustomColumns: "NAME:.metadata.name"},
+	} {
+		if _, err := newResourcePrinter(args); err == nil {
+			t.Errorf("newResourcePrinter(%+v) expected error", args)
+		}
+	}
+}
+
+func TestSummaryPrinter(t *testing.T) {
+	objects := []string{`
+apiVersion: v1
+kind: Pod
+metadata: {name: a, namespace: default}
+status:
+  phase: Running
+  containerStatuses: [{restartCount: 2}]
+`, `
+apiVersion: v1
+kind: Pod
+metadata: {name: b, namespace: default}
+status: {phase: Running}
+`, `
+apiVersion: v1
+kind: Pod
+metadata: {name: c, namespace: prod}
+status: {phase: Pending}
+`, `
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web, namespace: prod}
+spec: {replicas: 3}
+status: {readyReplicas: 1}
+`}
+	var items []unstructured.Unstructured
+	for _, o := range objects {
+		items = append(items, *decodeObject(t, o))
+	}
+	got := printResources(t, &getResourcesArgs{Output: "summary"}, items...)
+	want := `Total: 4
+By kind:
+  Pod	3
+  Deployment	1
+By namespace (2):
+  default	2
+  prod	2
+By phase:
+  Running	2
+  Pending	1
+By readiness:
+  not ready	1
+Replicas: 1/3 ready
+Pods with restarted containers: 1/3
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("summary mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// maxOwnerDepth bounds the chains of owners followed by ownerFilter, e.g. 2
+// for the pods of a Deployment, owned by its ReplicaSets.
+const maxOwnerDepth = 5
+
+// ownerFilter matches the objects owned, directly or transitively, by an
+// owner object.
+type ownerFilter struct {
+	h   *handlers
+	uid types.UID
+	// owners caches the owner references of the intermediate owners, by
+	// UID. Owners that can't be read have no references.
+	owners map[types.UID][]metav1.OwnerReference
+}
+
+// newOwnerFilter returns the filter of the objects owned by the object name
+// of resource kind in namespace.
+func (h *handlers) newOwnerFilter(ctx context.Context, kind, name, namespace string) (*ownerFilter, error) {
+	gvr, err := h.findGVR(kind)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner_kind: %w", err)
+	}
+	namespaced, err := h.isNamespaced(gvr)
+	if err != nil {
+		return nil, err
+	}
+	if !namespaced {
+		namespace = ""
+	}
+	owner, err := h.resourceInterface(gvr, namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owner: %w", err)
+	}
+	return &ownerFilter{h: h, uid: owner.GetUID(), owners: map[types.UID][]metav1.OwnerReference{}}, nil
+}
+
+// owned reports whether obj is owned by the owner of f, following the owner
+// references of obj and of its owners, in its namespace.
+func (f *ownerFilter) owned(ctx context.Context, obj *unstructured.Unstructured) bool {
+	refs := obj.GetOwnerReferences()
+	seen := map[types.UID]bool{}
+	for depth := 0; depth < maxOwnerDepth && len(refs) > 0; depth++ {
+		var next []metav1.OwnerReference
+		for _, ref := range refs {
+			if ref.UID == f.uid {
+				return true
+			}
+			if seen[ref.UID] {
+				continue
+			}
+			seen[ref.UID] = true
+			next = append(next, f.ownerReferences(ctx, ref, obj.GetNamespace())...)
+		}
+		refs = next
+	}
+	return false
+}
+
+// ownerReferences returns the owner references of the owner ref of an object
+// of namespace.
+func (f *ownerFilter) ownerReferences(ctx context.Context, ref metav1.OwnerReference, namespace string) []metav1.OwnerReference {
+	if refs, ok := f.owners[ref.UID]; ok {
+		return refs
+	}
+	f.owners[ref.UID] = nil
+	owner, err := f.h.getOwner(ctx, ref, namespace)
+	if err != nil {
+		return nil
+	}
+	f.owners[ref.UID] = owner.GetOwnerReferences()
+	return f.owners[ref.UID]
+}
+
+// getOwner returns the owner ref of an object of namespace.
+func (h *handlers) getOwner(ctx context.Context, ref metav1.OwnerReference, namespace string) (*unstructured.Unstructured, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := h.mapper.RESTMapping(gv.WithKind(ref.Kind).GroupKind(), gv.Version)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		namespace = ""
+	}
+	owner, err := h.resourceInterface(mapping.Resource, namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// A recreated owner with the same name isn't the owner of the reference.
+	if owner.GetUID() != ref.UID {
+		return nil, fmt.Errorf("%s %s was recreated", ref.Kind, ref.Name)
+	}
+	return owner, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func ownedObject(apiVersion, kind, name, uid string, owners ...*unstructured.Unstructured) *unstructured.Unstructured {
+	var refs []any
+	for _, owner := range owners {
+		refs = append(refs, map[string]any{
+			"apiVersion": owner.GetAPIVersion(),
+			"kind":       owner.GetKind(),
+			"name":       owner.GetName(),
+			"uid":        string(owner.GetUID()),
+		})
+	}
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]any{"name": name, "namespace": "shop", "uid": uid, "ownerReferences": refs},
+	}}
+}
+
+func TestOwnerFilter(t *testing.T) {
+	ctx := context.Background()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}, meta.RESTScopeNamespace)
+
+	web := ownedObject("apps/v1", "Deployment", "web", "web-uid")
+	webRS := ownedObject("apps/v1", "ReplicaSet", "web-1", "web-1-uid", web)
+	api := ownedObject("apps/v1", "Deployment", "api", "api-uid")
+	apiRS := ownedObject("apps/v1", "ReplicaSet", "api-1", "api-1-uid", api)
+	// A ReplicaSet recreated with the name of a deleted one, whose pods
+	// aren't owned by the web Deployment.
+	recreated := ownedObject("apps/v1", "ReplicaSet", "web-0", "web-0-new-uid", api)
+	staleRef := ownedObject("apps/v1", "ReplicaSet", "web-0", "web-0-uid")
+
+	h := &handlers{
+		dyn:    dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), web, webRS, api, apiRS, recreated),
+		mapper: mapper,
+	}
+	f := &ownerFilter{h: h, uid: types.UID("web-uid"), owners: map[types.UID][]metav1.OwnerReference{}}
+	tests := []struct {
+		name string
+		obj  *unstructured.Unstructured
+		want bool
+	}{
+		{"owned directly", ownedObject("v1", "Pod", "web-1-a", "a", web), true},
+		{"owned transitively", ownedObject("v1", "Pod", "web-1-b", "b", webRS), true},
+		{"owned by another", ownedObject("v1", "Pod", "api-1-a", "c", apiRS), false},
+		{"stale owner", ownedObject("v1", "Pod", "web-0-a", "d", staleRef), false},
+		{"no owner", ownedObject("v1", "Pod", "debug", "e"), false},
+		{"owner itself", web, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.owned(ctx, tt.obj); got != tt.want {
+				t.Errorf("owned() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
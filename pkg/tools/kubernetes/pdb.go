@@ -0,0 +1,198 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+)
+
+// PDBReportToolDescription contains the documentation for the PDB Report Kubernetes tool.
+// It is formatted in Markdown.
+const PDBReportToolDescription = `
+This tool reports the PodDisruptionBudget (PDB) coverage of the workloads in the cluster. PDBs limit voluntary disruptions such as node drains, which are also performed by cluster upgrades and the cluster autoscaler.
+
+The report contains:
+
+* **PodDisruptionBudgets**: each PDB with its budget (*minAvailable* or *maxUnavailable*), the number of currently healthy pods, the number of healthy pods it requires, the number of disruptions currently allowed, and the workloads it covers. PDBs that allow no disruption block every drain of the nodes running their pods, which stalls node upgrades until they time out. PDBs that select no pods are reported too.
+* **Workloads without a PDB**: the Deployments and StatefulSets with at least one replica whose pods are not selected by any PDB. Their pods can all be evicted at the same time during a drain.
+
+## Arguments
+
+* *namespace*: (Optional) The namespace to report on. Defaults to the server's default namespace.
+* *all_namespaces*: (Optional) Set to *true* to report on all namespaces.
+
+## Response Format
+
+NAMESPACE  NAME     BUDGET            CURRENT_HEALTHY  DESIRED_HEALTHY  ALLOWED_DISRUPTIONS  WORKLOADS       STATUS
+default    web-pdb  minAvailable=3    3                3                0                    Deployment/web  blocks all disruptions
+
+Workloads without a PodDisruptionBudget:
+NAMESPACE  WORKLOAD          REPLICAS
+default    Deployment/api    2
+`
+
+type pdbReportArgs struct {
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+}
+
+// pdbWorkload is a workload that PodDisruptionBudgets may cover.
+type pdbWorkload struct {
+	namespace, name string
+	replicas        int32
+	podLabels       map[string]string
+}
+
+// pdbCoverage returns, for each PDB in pdbs, the workloads whose pods it
+// selects, and the workloads with at least one replica selected by none.
+func pdbCoverage(pdbs []policyv1.PodDisruptionBudget, workloads []pdbWorkload) (covered [][]string, uncovered []pdbWorkload, err error) {
+	covered = make([][]string, len(pdbs))
+	selectors := make([]labels.Selector, len(pdbs))
+	for i := range pdbs {
+		selectors[i], err = metav1.LabelSelectorAsSelector(pdbs[i].Spec.Selector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse selector of PodDisruptionBudget %s/%s: %w", pdbs[i].Namespace, pdbs[i].Name, err)
+		}
+		// A nil selector selects no pods, unlike an empty one.
+		if pdbs[i].Spec.Selector == nil {
+			selectors[i] = labels.Nothing()
+		}
+	}
+	for _, w := range workloads {
+		found := false
+		for i := range pdbs {
+			if pdbs[i].Namespace == w.namespace && selectors[i].Matches(labels.Set(w.podLabels)) {
+				covered[i] = append(covered[i], w.name)
+				found = true
+			}
+		}
+		if !found && w.replicas > 0 {
+			uncovered = append(uncovered, w)
+		}
+	}
+	return covered, uncovered, nil
+}
+
+// pdbStatus describes the problems of pdb, or returns "OK".
+func pdbStatus(pdb *policyv1.PodDisruptionBudget) string {
+	var problems []string
+	switch {
+	case pdb.Status.ExpectedPods == 0:
+		problems = append(problems, "selects no pods")
+	case pdb.Status.DisruptionsAllowed == 0:
+		problems = append(problems, "blocks all disruptions")
+	}
+	if pdb.Status.CurrentHealthy < pdb.Status.DesiredHealthy {
+		problems = append(problems, fmt.Sprintf("%d healthy pods, %d required", pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy))
+	}
+	if len(problems) == 0 {
+		return "OK"
+	}
+	return strings.Join(problems, "; ")
+}
+
+func pdbBudget(pdb *policyv1.PodDisruptionBudget) string {
+	switch {
+	case pdb.Spec.MinAvailable != nil:
+		return "minAvailable=" + pdb.Spec.MinAvailable.String()
+	case pdb.Spec.MaxUnavailable != nil:
+		return "maxUnavailable=" + pdb.Spec.MaxUnavailable.String()
+	}
+	return "<none>"
+}
+
+func (h *handlers) pdbReport(ctx context.Context, _ *mcp.CallToolRequest, args *pdbReportArgs) (*mcp.CallToolResult, any, error) {
+	namespace, err := h.namespaceScope(args.Namespace, args.AllNamespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	pdbs, err := h.clientset.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pod disruption budgets: %w", err)
+	}
+	deployments, err := h.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	statefulSets, err := h.clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list stateful sets: %w", err)
+	}
+
+	var workloads []pdbWorkload
+	for _, d := range deployments.Items {
+		workloads = append(workloads, pdbWorkload{namespace: d.Namespace, name: "Deployment/" + d.Name, replicas: ptr.Deref(d.Spec.Replicas, 1), podLabels: d.Spec.Template.Labels})
+	}
+	for _, s := range statefulSets.Items {
+		workloads = append(workloads, pdbWorkload{namespace: s.Namespace, name: "StatefulSet/" + s.Name, replicas: ptr.Deref(s.Spec.Replicas, 1), podLabels: s.Spec.Template.Labels})
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		return workloads[i].namespace+"/"+workloads[i].name < workloads[j].namespace+"/"+workloads[j].name
+	})
+	items := pdbs.Items
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Namespace+"/"+items[i].Name < items[j].Namespace+"/"+items[j].Name
+	})
+
+	covered, uncovered, err := pdbCoverage(items, workloads)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var output strings.Builder
+	var blocking int
+	output.WriteString(fmt.Sprintf("PodDisruptionBudgets in %s:\n", describeScope(namespace)))
+	output.WriteString("NAMESPACE\tNAME\tBUDGET\tCURRENT_HEALTHY\tDESIRED_HEALTHY\tALLOWED_DISRUPTIONS\tWORKLOADS\tSTATUS\n")
+	for i := range items {
+		pdb := &items[i]
+		if pdb.Status.ExpectedPods > 0 && pdb.Status.DisruptionsAllowed == 0 {
+			blocking++
+		}
+		workloadNames := "<none>"
+		if len(covered[i]) > 0 {
+			workloadNames = strings.Join(covered[i], ",")
+		}
+		output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%d\t%d\t%d\t%s\t%s\n", pdb.Namespace, pdb.Name, pdbBudget(pdb),
+			pdb.Status.CurrentHealthy, pdb.Status.DesiredHealthy, pdb.Status.DisruptionsAllowed, workloadNames, pdbStatus(pdb)))
+	}
+	if len(items) == 0 {
+		output.WriteString("No PodDisruptionBudgets found.\n")
+	}
+
+	output.WriteString(fmt.Sprintf("\nWorkloads without a PodDisruptionBudget: %d\n", len(uncovered)))
+	if len(uncovered) > 0 {
+		output.WriteString("NAMESPACE\tWORKLOAD\tREPLICAS\n")
+		for _, w := range uncovered {
+			output.WriteString(fmt.Sprintf("%s\t%s\t%d\n", w.namespace, w.name, w.replicas))
+		}
+	}
+
+	output.WriteString(fmt.Sprintf("\nSummary: %d PodDisruptionBudgets, %d blocking all disruptions, %d workloads without a PodDisruptionBudget.\n", len(items), blocking, len(uncovered)))
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
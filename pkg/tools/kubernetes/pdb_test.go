@@ -0,0 +1,88 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPDBCoverage(t *testing.T) {
+	pdb := func(namespace, name string, selector *metav1.LabelSelector) policyv1.PodDisruptionBudget {
+		return policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: selector},
+		}
+	}
+	pdbs := []policyv1.PodDisruptionBudget{
+		pdb("default", "web", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}),
+		pdb("default", "nil-selector", nil),
+		// Selectors only match pods of their own namespace.
+		pdb("other", "api", &metav1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}),
+	}
+	workloads := []pdbWorkload{
+		{namespace: "default", name: "Deployment/web", replicas: 3, podLabels: map[string]string{"app": "web", "tier": "frontend"}},
+		{namespace: "default", name: "Deployment/api", replicas: 2, podLabels: map[string]string{"app": "api"}},
+		{namespace: "default", name: "Deployment/scaled-down", replicas: 0, podLabels: map[string]string{"app": "batch"}},
+	}
+
+	covered, uncovered, err := pdbCoverage(pdbs, workloads)
+	if err != nil {
+		t.Fatalf("pdbCoverage() error = %v", err)
+	}
+	if diff := cmp.Diff([][]string{{"Deployment/web"}, nil, nil}, covered); diff != "" {
+		t.Errorf("pdbCoverage() covered mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]pdbWorkload{workloads[1]}, uncovered, cmp.AllowUnexported(pdbWorkload{})); diff != "" {
+		t.Errorf("pdbCoverage() uncovered mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPDBStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status policyv1.PodDisruptionBudgetStatus
+		want   string
+	}{
+		{
+			name:   "ok",
+			status: policyv1.PodDisruptionBudgetStatus{ExpectedPods: 3, CurrentHealthy: 3, DesiredHealthy: 2, DisruptionsAllowed: 1},
+			want:   "OK",
+		},
+		{
+			name:   "blocking",
+			status: policyv1.PodDisruptionBudgetStatus{ExpectedPods: 3, CurrentHealthy: 3, DesiredHealthy: 3},
+			want:   "blocks all disruptions",
+		},
+		{
+			name:   "unhealthy",
+			status: policyv1.PodDisruptionBudgetStatus{ExpectedPods: 3, CurrentHealthy: 1, DesiredHealthy: 2},
+			want:   "blocks all disruptions; 1 healthy pods, 2 required",
+		},
+		{
+			name: "no pods",
+			want: "selects no pods",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pdbStatus(&policyv1.PodDisruptionBudget{Status: tc.status}); got != tc.want {
+				t.Errorf("pdbStatus() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
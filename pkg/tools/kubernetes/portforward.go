@@ -0,0 +1,436 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PortForwardStartToolDescription contains the documentation for the Port Forward Start Kubernetes tool.
+// It is formatted in Markdown.
+const PortForwardStartToolDescription = `
+This tool forwards a local port of the machine running the MCP server to a port of a pod or service, like *kubectl port-forward*. Use it to reach an endpoint that is not exposed outside of the cluster, e.g. an application's admin or debug endpoint, with other tools running on the same machine.
+
+The port-forward runs in the background as a named session, until it is stopped with *kube_port_forward_stop*, its TTL expires, the target pod terminates, or the MCP server shuts down. The local port only listens on *127.0.0.1*.
+
+For a service, the port-forward connects to one of the ready pods selected by the service, like *kubectl port-forward service/...*: it does not load balance and stops if that pod terminates.
+
+## Arguments
+
+* *resource*: *pod* or *service*.
+* *name*: The name of the pod or service.
+* *namespace*: (Optional) The namespace of the pod or service. Defaults to the server's default namespace.
+* *port*: The port to forward to: a port number or a named port of the pod or service.
+* *local_port*: (Optional) The local port to listen on. Defaults to a random free port.
+* *session*: (Optional) The name of the session. Defaults to a generated name.
+* *ttl*: (Optional) How long to keep the port-forward, as a duration such as *10m*. Defaults to *30m*, at most *24h*.
+
+## Response Format
+
+The session name and the local address to connect to, e.g. *Session pf-1 forwarding 127.0.0.1:41234 to pod default/web-7d9f-abcde port 8080, until 2025-01-01T10:30:00Z.*
+`
+
+// PortForwardListToolDescription contains the documentation for the Port Forward List Kubernetes tool.
+// It is formatted in Markdown.
+const PortForwardListToolDescription = `
+This tool lists the port-forward sessions started with *kube_port_forward_start* that are still running.
+
+## Response Format
+
+SESSION  LOCAL_ADDRESS    TARGET                        REMOTE_PORT  STARTED               EXPIRES
+pf-1     127.0.0.1:41234  pod default/web-7d9f-abcde    8080         2025-01-01T10:00:00Z  2025-01-01T10:30:00Z
+`
+
+// PortForwardStopToolDescription contains the documentation for the Port Forward Stop Kubernetes tool.
+// It is formatted in Markdown.
+const PortForwardStopToolDescription = `
+This tool stops port-forward sessions started with *kube_port_forward_start*, and frees their local port.
+
+## Arguments
+
+* *session*: (Optional) The name of the session to stop.
+* *all*: (Optional) Set to *true* to stop all sessions.
+`
+
+type portForwardStartArgs struct {
+	Resource  string `json:"resource"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Port      string `json:"port"`
+	LocalPort int    `json:"local_port,omitempty"`
+	Session   string `json:"session,omitempty"`
+	TTL       string `json:"ttl,omitempty"`
+}
+
+type portForwardListArgs struct{}
+
+type portForwardStopArgs struct {
+	Session string `json:"session,omitempty"`
+	All     bool   `json:"all,omitempty"`
+}
+
+const (
+	defaultPortForwardTTL = 30 * time.Minute
+	maxPortForwardTTL     = 24 * time.Hour
+	// portForwardReadyTimeout bounds the time to establish a port-forward.
+	portForwardReadyTimeout = 30 * time.Second
+)
+
+// portForwardEntry describes a port-forward session.
+type portForwardEntry struct {
+	name                  string
+	target                string
+	localPort, remotePort uint16
+	started, expires      time.Time
+}
+
+// portForwardSession is a running port-forward. Its entry is guarded by the
+// mutex of the portForwards it belongs to.
+type portForwardSession struct {
+	portForwardEntry
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	timer    *time.Timer
+}
+
+func (s *portForwardSession) stop() {
+	s.stopOnce.Do(func() {
+		s.timer.Stop()
+		close(s.stopCh)
+	})
+}
+
+// portForwards tracks the running port-forward sessions.
+type portForwards struct {
+	mu       sync.Mutex
+	sessions map[string]*portForwardSession
+	// next is used to generate session names.
+	next int
+}
+
+func newPortForwards() *portForwards {
+	return &portForwards{sessions: map[string]*portForwardSession{}}
+}
+
+// add registers s, generating its name if it has none.
+func (p *portForwards) add(s *portForwardSession) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if s.name == "" {
+		for s.name == "" || p.sessions[s.name] != nil {
+			p.next++
+			s.name = fmt.Sprintf("pf-%d", p.next)
+		}
+	}
+	if _, ok := p.sessions[s.name]; ok {
+		return fmt.Errorf("port-forward session %q already exists", s.name)
+	}
+	p.sessions[s.name] = s
+	return nil
+}
+
+// remove stops and unregisters the session named name, and reports whether
+// it existed.
+func (p *portForwards) remove(name string) bool {
+	p.mu.Lock()
+	s, ok := p.sessions[name]
+	delete(p.sessions, name)
+	p.mu.Unlock()
+	if ok {
+		s.stop()
+	}
+	return ok
+}
+
+// ready records that s listens on localPort, and expires after ttl.
+func (p *portForwards) ready(s *portForwardSession, localPort uint16, ttl time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s.localPort = localPort
+	s.started = time.Now()
+	s.expires = s.started.Add(ttl)
+	s.timer.Reset(ttl)
+}
+
+// list returns the sessions sorted by name.
+func (p *portForwards) list() []portForwardEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sessions := make([]portForwardEntry, 0, len(p.sessions))
+	for _, s := range p.sessions {
+		sessions = append(sessions, s.portForwardEntry)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].name < sessions[j].name })
+	return sessions
+}
+
+// stopAll stops all sessions and returns their names.
+func (p *portForwards) stopAll() []string {
+	var names []string
+	for _, s := range p.list() {
+		if p.remove(s.name) {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
+
+// containerPort returns the number of the container port of pod named name.
+func containerPort(pod *corev1.Pod, name string) (int32, error) {
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == name {
+				return p.ContainerPort, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("pod %q has no port named %q", pod.Name, name)
+}
+
+// podPort resolves port, a number or a port name, on pod.
+func podPort(pod *corev1.Pod, port string) (int32, error) {
+	if n, err := strconv.ParseUint(port, 10, 16); err == nil {
+		return int32(n), nil
+	}
+	return containerPort(pod, port)
+}
+
+// servicePodPort returns the pod port that the service port named or
+// numbered port targets on pod.
+func servicePodPort(svc *corev1.Service, pod *corev1.Pod, port string) (int32, error) {
+	for _, p := range svc.Spec.Ports {
+		if p.Name != port && strconv.Itoa(int(p.Port)) != port {
+			continue
+		}
+		switch {
+		case p.TargetPort.IntValue() != 0:
+			return int32(p.TargetPort.IntValue()), nil
+		case p.TargetPort.String() != "" && p.TargetPort.String() != "0":
+			return containerPort(pod, p.TargetPort.String())
+		default:
+			return p.Port, nil
+		}
+	}
+	return 0, fmt.Errorf("service %q has no port %q", svc.Name, port)
+}
+
+// readyPod returns a running and ready pod among pods.
+func readyPod(pods []corev1.Pod) *corev1.Pod {
+	for i := range pods {
+		if pods[i].DeletionTimestamp == nil && podNotReadyReason(&pods[i]) == "" {
+			return &pods[i]
+		}
+	}
+	return nil
+}
+
+func (h *handlers) portForwardStart(ctx context.Context, _ *mcp.CallToolRequest, args *portForwardStartArgs) (*mcp.CallToolResult, any, error) {
+	ttl := defaultPortForwardTTL
+	if args.TTL != "" {
+		d, err := time.ParseDuration(args.TTL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid ttl %q: %w", args.TTL, err)
+		}
+		if d <= 0 || d > maxPortForwardTTL {
+			return nil, nil, fmt.Errorf("invalid ttl %q: must be positive and at most %s", args.TTL, maxPortForwardTTL)
+		}
+		ttl = d
+	}
+	if args.LocalPort < 0 || args.LocalPort > 65535 {
+		return nil, nil, fmt.Errorf("invalid local_port %d", args.LocalPort)
+	}
+	if args.Port == "" {
+		return nil, nil, fmt.Errorf("port is required")
+	}
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+
+	var pod *corev1.Pod
+	var remotePort int32
+	switch strings.ToLower(args.Resource) {
+	case "pod", "pods", "po":
+		var err error
+		pod, err = h.clientset.CoreV1().Pods(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get pod: %w", err)
+		}
+		if pod.Status.Phase != corev1.PodRunning {
+			return nil, nil, fmt.Errorf("pod %q is %s, not Running", pod.Name, pod.Status.Phase)
+		}
+		if remotePort, err = podPort(pod, args.Port); err != nil {
+			return nil, nil, err
+		}
+	case "service", "services", "svc":
+		svc, err := h.clientset.CoreV1().Services(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get service: %w", err)
+		}
+		if len(svc.Spec.Selector) == 0 {
+			return nil, nil, fmt.Errorf("service %q has no selector", svc.Name)
+		}
+		pods, err := h.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String()})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		if pod = readyPod(pods.Items); pod == nil {
+			return nil, nil, fmt.Errorf("service %q has no ready pods", svc.Name)
+		}
+		if remotePort, err = servicePodPort(svc, pod, args.Port); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid resource %q: must be pod or service", args.Resource)
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(h.restConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create port-forward transport: %w", err)
+	}
+	url := h.clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+	// Prefer the WebSocket protocol, like kubectl, and fall back to SPDY for
+	// older API servers.
+	if tunnelingDialer, err := portforward.NewSPDYOverWebsocketDialer(url, h.restConfig); err == nil {
+		dialer = portforward.NewFallbackDialer(tunnelingDialer, dialer, func(err error) bool {
+			return httpstream.IsUpgradeFailure(err) || httpstream.IsHTTPSProxyError(err)
+		})
+	}
+
+	session := &portForwardSession{
+		portForwardEntry: portForwardEntry{
+			name:       args.Session,
+			target:     fmt.Sprintf("pod %s/%s", pod.Namespace, pod.Name),
+			remotePort: uint16(remotePort),
+		},
+		stopCh: make(chan struct{}),
+	}
+	// The timer is started once the port-forward is ready.
+	session.timer = time.AfterFunc(ttl, func() { h.portForwards.remove(session.name) })
+	session.timer.Stop()
+	if err := h.portForwards.add(session); err != nil {
+		return nil, nil, err
+	}
+	name := session.name
+
+	readyCh := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("%d:%d", args.LocalPort, remotePort)}, session.stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		h.portForwards.remove(name)
+		return nil, nil, fmt.Errorf("failed to create port-forward: %w", err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		err := forwarder.ForwardPorts()
+		if err != nil {
+			slog.Warn("Port-forward session stopped", "session", name, "target", session.target, "error", err)
+		}
+		// The session ends when the connection to the pod is lost.
+		h.portForwards.remove(name)
+		errCh <- err
+	}()
+
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return nil, nil, fmt.Errorf("failed to start port-forward: %w", err)
+	case <-time.After(portForwardReadyTimeout):
+		h.portForwards.remove(name)
+		return nil, nil, fmt.Errorf("timed out starting port-forward after %s", portForwardReadyTimeout)
+	case <-ctx.Done():
+		h.portForwards.remove(name)
+		return nil, nil, ctx.Err()
+	}
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		h.portForwards.remove(name)
+		return nil, nil, fmt.Errorf("failed to get the local port: %w", err)
+	}
+	h.portForwards.ready(session, ports[0].Local, ttl)
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Session %s forwarding 127.0.0.1:%d to %s port %d, until %s.",
+				session.name, session.localPort, session.target, session.remotePort, session.expires.UTC().Format(time.RFC3339))},
+		},
+	}, nil, nil
+}
+
+func (h *handlers) portForwardList(_ context.Context, _ *mcp.CallToolRequest, _ *portForwardListArgs) (*mcp.CallToolResult, any, error) {
+	sessions := h.portForwards.list()
+	var output strings.Builder
+	if len(sessions) == 0 {
+		output.WriteString("No port-forward sessions.")
+	} else {
+		output.WriteString("SESSION\tLOCAL_ADDRESS\tTARGET\tREMOTE_PORT\tSTARTED\tEXPIRES\n")
+		for _, s := range sessions {
+			// Sessions that are still starting have no local port yet.
+			if s.localPort == 0 {
+				continue
+			}
+			output.WriteString(fmt.Sprintf("%s\t127.0.0.1:%d\t%s\t%d\t%s\t%s\n", s.name, s.localPort, s.target, s.remotePort,
+				s.started.UTC().Format(time.RFC3339), s.expires.UTC().Format(time.RFC3339)))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+func (h *handlers) portForwardStop(_ context.Context, _ *mcp.CallToolRequest, args *portForwardStopArgs) (*mcp.CallToolResult, any, error) {
+	var text string
+	switch {
+	case args.All:
+		stopped := h.portForwards.stopAll()
+		text = fmt.Sprintf("Stopped %d port-forward sessions.", len(stopped))
+		if len(stopped) > 0 {
+			text = fmt.Sprintf("Stopped %d port-forward sessions: %s.", len(stopped), strings.Join(stopped, ", "))
+		}
+	case args.Session != "":
+		if !h.portForwards.remove(args.Session) {
+			return nil, nil, fmt.Errorf("port-forward session %q not found", args.Session)
+		}
+		text = fmt.Sprintf("Stopped port-forward session %s.", args.Session)
+	default:
+		return nil, nil, fmt.Errorf("either session or all is required")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestServicePodPort(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "web",
+			Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}, {Name: "admin", ContainerPort: 9090}},
+		}}},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web"},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Name: "http", Port: 80, TargetPort: intstr.FromString("http")},
+			{Name: "admin", Port: 9000, TargetPort: intstr.FromInt32(9090)},
+			{Name: "metrics", Port: 9100},
+		}},
+	}
+	for _, tc := range []struct {
+		port    string
+		want    int32
+		wantErr bool
+	}{
+		{port: "80", want: 8080},
+		{port: "http", want: 8080},
+		{port: "admin", want: 9090},
+		{port: "metrics", want: 9100},
+		{port: "443", wantErr: true},
+	} {
+		got, err := servicePodPort(svc, pod, tc.port)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("servicePodPort(%q) error = %v, wantErr %t", tc.port, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("servicePodPort(%q) = %d, want %d", tc.port, got, tc.want)
+		}
+	}
+
+	if got, err := podPort(pod, "admin"); err != nil || got != 9090 {
+		t.Errorf("podPort(admin) = %d, %v, want 9090", got, err)
+	}
+	if _, err := podPort(pod, "grpc"); err == nil {
+		t.Errorf("podPort(grpc) succeeded, want error")
+	}
+}
+
+func TestPortForwards(t *testing.T) {
+	p := newPortForwards()
+	newSession := func(name string) *portForwardSession {
+		s := &portForwardSession{portForwardEntry: portForwardEntry{name: name}, stopCh: make(chan struct{})}
+		s.timer = time.AfterFunc(time.Hour, func() { p.remove(s.name) })
+		s.timer.Stop()
+		return s
+	}
+
+	first, named, second := newSession(""), newSession("admin"), newSession("")
+	for _, s := range []*portForwardSession{first, named, second} {
+		if err := p.add(s); err != nil {
+			t.Fatalf("add() error = %v", err)
+		}
+	}
+	if err := p.add(newSession("admin")); err == nil {
+		t.Errorf("add() of a duplicate session succeeded, want error")
+	}
+	p.ready(first, 41234, time.Minute)
+
+	var names []string
+	for _, e := range p.list() {
+		names = append(names, e.name)
+	}
+	if diff := cmp.Diff([]string{"admin", "pf-1", "pf-2"}, names); diff != "" {
+		t.Errorf("list() mismatch (-want +got):\n%s", diff)
+	}
+
+	if !p.remove("pf-1") {
+		t.Errorf("remove(pf-1) = false, want true")
+	}
+	select {
+	case <-first.stopCh:
+	default:
+		t.Errorf("remove(pf-1) did not stop the session")
+	}
+	if p.remove("pf-1") {
+		t.Errorf("remove(pf-1) of a removed session = true, want false")
+	}
+
+	if diff := cmp.Diff([]string{"admin", "pf-2"}, p.stopAll()); diff != "" {
+		t.Errorf("stopAll() mismatch (-want +got):\n%s", diff)
+	}
+	if len(p.list()) != 0 {
+		t.Errorf("list() after stopAll() = %v, want empty", p.list())
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// PreemptionAnalysisToolDescription contains the documentation for the Preemption Analysis Kubernetes tool.
+// It is formatted in Markdown.
+const PreemptionAnalysisToolDescription = `
+This tool analyzes pod priority and preemption in the cluster. Preemption lets the scheduler evict lower priority pods to make room for a pending higher priority pod, which can surprise the owners of the evicted pods.
+
+The report contains:
+
+* **PriorityClasses**: all PriorityClasses with their value, preemption policy, whether they are the global default, and how many pods use them.
+* **Recent preemptions**: the pods preempted within the *since* window, from the *Preempted* events, with the pod that preempted them.
+* **Preemption simulation**: if a pending pod is given, whether it fits on a node as is, or else which running pods would be preempted to fit it, on the node requiring the fewest and lowest priority victims.
+
+The simulation only considers CPU and memory requests, the number of pods per node, node selectors, cordoned nodes and *NoSchedule* taints. It ignores affinity rules, topology spread constraints and PodDisruptionBudgets, which the scheduler also honors on a best effort basis, so the actual victims may differ.
+
+## Arguments
+
+* *pod*: (Optional) The name of a pending pod to simulate preemption for.
+* *namespace*: (Optional) The namespace of the pending pod. Defaults to the server's default namespace.
+* *since*: (Optional) How far back to look for preemptions, as a duration such as *30m* or *24h*. Defaults to *24h*.
+`
+
+type preemptionAnalysisArgs struct {
+	Pod       string `json:"pod,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Since     string `json:"since,omitempty"`
+}
+
+const defaultPreemptionWindow = 24 * time.Hour
+
+// preemptionPlan is the set of pods to preempt on a node to fit a pod.
+type preemptionPlan struct {
+	node    string
+	victims []*corev1.Pod
+}
+
+// podPriority returns the priority of pod, which the admission controller
+// resolves from its PriorityClass.
+func podPriority(pod *corev1.Pod) int32 {
+	if pod.Spec.Priority != nil {
+		return *pod.Spec.Priority
+	}
+	return 0
+}
+
+// nodeAccepts reports whether pod can be scheduled on node, ignoring
+// resources: the node is schedulable, matches the pod's node selector and
+// has no NoSchedule or NoExecute taints the pod doesn't tolerate.
+func nodeAccepts(node *corev1.Node, pod *corev1.Pod) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	if !labels.SelectorFromSet(pod.Spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// simulatePreemption returns where pod fits. If it fits on a node without
+// preemption, the plan has no victims. Otherwise it is the node requiring
+// the fewest victims, preferring victims of lower priority, or nil if pod
+// can't fit anywhere. running maps node names to the pods running on them.
+func simulatePreemption(pod *corev1.Pod, nodes []corev1.Node, running map[string][]*corev1.Pod) *preemptionPlan {
+	requests, _ := podRequestsAndLimits(pod)
+	priority := podPriority(pod)
+	canPreempt := pod.Spec.PreemptionPolicy == nil || *pod.Spec.PreemptionPolicy != corev1.PreemptNever
+
+	var best *preemptionPlan
+	var bestMaxPriority int32
+	for i := range nodes {
+		node := &nodes[i]
+		if !nodeAccepts(node, pod) {
+			continue
+		}
+		free := node.Status.Allocatable.DeepCopy()
+		freePods := free.Pods().Value()
+		for _, p := range running[node.Name] {
+			r, _ := podRequestsAndLimits(p)
+			subtractResources(free, r)
+			freePods--
+		}
+		if fits(requests, free) && freePods > 0 {
+			return &preemptionPlan{node: node.Name}
+		}
+		if !canPreempt {
+			continue
+		}
+
+		// Like the scheduler, remove all lower priority pods, then reprieve
+		// as many as possible, highest priority first.
+		var candidates []*corev1.Pod
+		for _, p := range running[node.Name] {
+			if podPriority(p) < priority {
+				r, _ := podRequestsAndLimits(p)
+				addResources(free, r)
+				freePods++
+				candidates = append(candidates, p)
+			}
+		}
+		if !fits(requests, free) || freePods <= 0 {
+			continue
+		}
+		sort.SliceStable(candidates, func(i, j int) bool { return podPriority(candidates[i]) > podPriority(candidates[j]) })
+		var victims []*corev1.Pod
+		for _, p := range candidates {
+			r, _ := podRequestsAndLimits(p)
+			subtractResources(free, r)
+			if fits(requests, free) && freePods > 1 {
+				freePods--
+				continue
+			}
+			addResources(free, r)
+			victims = append(victims, p)
+		}
+		// victims are sorted by decreasing priority.
+		maxPriority := podPriority(victims[0])
+		if best == nil || len(victims) < len(best.victims) || (len(victims) == len(best.victims) && maxPriority < bestMaxPriority) {
+			best = &preemptionPlan{node: node.Name, victims: victims}
+			bestMaxPriority = maxPriority
+		}
+	}
+	return best
+}
+
+func subtractResources(dst, src corev1.ResourceList) {
+	for name, q := range src {
+		v := dst[name]
+		v.Sub(q)
+		dst[name] = v
+	}
+}
+
+// fits reports whether requests fit in free, considering CPU and memory.
+func fits(requests, free corev1.ResourceList) bool {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if r, ok := requests[name]; ok && r.Cmp(free[name]) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *handlers) preemptionAnalysis(ctx context.Context, _ *mcp.CallToolRequest, args *preemptionAnalysisArgs) (*mcp.CallToolResult, any, error) {
+	since := defaultPreemptionWindow
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		since = d
+	}
+
+	classes, err := h.clientset.SchedulingV1().PriorityClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list priority classes: %w", err)
+	}
+	pods, err := h.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var output strings.Builder
+	usage := map[string]int{}
+	running := map[string][]*corev1.Pod{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		usage[pod.Spec.PriorityClassName]++
+		if pod.Spec.NodeName != "" {
+			running[pod.Spec.NodeName] = append(running[pod.Spec.NodeName], pod)
+		}
+	}
+
+	sort.Slice(classes.Items, func(i, j int) bool { return classes.Items[i].Value > classes.Items[j].Value })
+	output.WriteString("PriorityClasses:\n")
+	output.WriteString("NAME\tVALUE\tGLOBAL_DEFAULT\tPREEMPTION_POLICY\tPODS\n")
+	for _, pc := range classes.Items {
+		policy := string(corev1.PreemptLowerPriority)
+		if pc.PreemptionPolicy != nil {
+			policy = string(*pc.PreemptionPolicy)
+		}
+		output.WriteString(fmt.Sprintf("%s\t%d\t%t\t%s\t%d\n", pc.Name, pc.Value, pc.GlobalDefault, policy, usage[pc.Name]))
+	}
+	output.WriteString(fmt.Sprintf("Pods without a PriorityClass: %d\n", usage[""]))
+
+	events, err := h.clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("reason", "Preempted").String(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list preemption events: %w", err)
+	}
+	cutoff := time.Now().Add(-since)
+	var preempted []corev1.Event
+	for _, e := range events.Items {
+		if e.Reason == "Preempted" && eventTime(&e).After(cutoff) {
+			preempted = append(preempted, e)
+		}
+	}
+	sort.Slice(preempted, func(i, j int) bool { return eventTime(&preempted[i]).Before(eventTime(&preempted[j])) })
+	output.WriteString(fmt.Sprintf("\nRecent preemptions (last %s): %d\n", since, len(preempted)))
+	if len(preempted) > 0 {
+		output.WriteString("TIME\tNAMESPACE\tPOD\tMESSAGE\n")
+		for i := range preempted {
+			e := &preempted[i]
+			output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\n", eventTime(e).UTC().Format(time.RFC3339), e.InvolvedObject.Namespace, e.InvolvedObject.Name, e.Message))
+		}
+	}
+
+	if args.Pod != "" {
+		namespace := args.Namespace
+		if namespace == "" {
+			namespace = h.defaultNamespace
+		}
+		pod, err := h.clientset.CoreV1().Pods(namespace).Get(ctx, args.Pod, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get pod: %w", err)
+		}
+		nodes, err := h.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list nodes: %w", err)
+		}
+		output.WriteString(fmt.Sprintf("\nPreemption simulation for pod %s/%s (priority %d", pod.Namespace, pod.Name, podPriority(pod)))
+		if pod.Spec.PriorityClassName != "" {
+			output.WriteString(", class " + pod.Spec.PriorityClassName)
+		}
+		output.WriteString("):\n")
+		if pod.Spec.NodeName != "" {
+			output.WriteString(fmt.Sprintf("The pod is already scheduled on node %s.\n", pod.Spec.NodeName))
+		} else {
+			writePreemptionPlan(&output, pod, simulatePreemption(pod, nodes.Items, running))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+func writePreemptionPlan(out *strings.Builder, pod *corev1.Pod, plan *preemptionPlan) {
+	switch {
+	case plan == nil && pod.Spec.PreemptionPolicy != nil && *pod.Spec.PreemptionPolicy == corev1.PreemptNever:
+		out.WriteString("The pod fits on no node, and it can't preempt other pods because its preemption policy is Never.\n")
+	case plan == nil:
+		out.WriteString("The pod fits on no node, even after preempting all lower priority pods.\n")
+	case len(plan.victims) == 0:
+		out.WriteString(fmt.Sprintf("The pod fits on node %s without preempting any pod.\n", plan.node))
+	default:
+		out.WriteString(fmt.Sprintf("The pod would fit on node %s after preempting %d pods:\n", plan.node, len(plan.victims)))
+		out.WriteString("NAMESPACE\tPOD\tPRIORITY\tPRIORITY_CLASS\n")
+		for _, v := range plan.victims {
+			out.WriteString(fmt.Sprintf("%s\t%s\t%d\t%s\n", v.Namespace, v.Name, podPriority(v), v.Spec.PriorityClassName))
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestSimulatePreemption(t *testing.T) {
+	node := func(name, cpu string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			}},
+		}
+	}
+	pod := func(name, cpu string, priority int32) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PodSpec{
+				Priority: ptr.To(priority),
+				Containers: []corev1.Container{{
+					Name:      "c",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+				}},
+			},
+		}
+	}
+	tainted := node("tainted", "8")
+	tainted.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+	cordoned := node("cordoned", "8")
+	cordoned.Spec.Unschedulable = true
+	nodes := []corev1.Node{node("a", "4"), node("b", "4"), tainted, cordoned}
+	running := map[string][]*corev1.Pod{
+		"a": {pod("a-low", "1", 0), pod("a-mid", "2", 100), pod("a-high", "1", 1000)},
+		"b": {pod("b-low-1", "1", 0), pod("b-low-2", "1", 0), pod("b-high", "2", 1000)},
+	}
+
+	victimNames := func(plan *preemptionPlan) []string {
+		if plan == nil {
+			return nil
+		}
+		names := []string{plan.node}
+		for _, v := range plan.victims {
+			names = append(names, v.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want []string
+	}{
+		{
+			name: "fits without preemption",
+			pod:  pod("small", "0", 500),
+			want: []string{"a"},
+		},
+		{
+			name: "fewest victims",
+			pod:  pod("medium", "2", 500),
+			want: []string{"a", "a-mid"},
+		},
+		{
+			name: "only one node fits",
+			pod:  pod("large", "3", 500),
+			want: []string{"a", "a-mid", "a-low"},
+		},
+		{
+			name: "no lower priority pods",
+			pod:  pod("large", "1", 0),
+			want: nil,
+		},
+		{
+			name: "preemption policy never",
+			pod: func() *corev1.Pod {
+				p := pod("never", "1", 500)
+				p.Spec.PreemptionPolicy = ptr.To(corev1.PreemptNever)
+				return p
+			}(),
+			want: nil,
+		},
+		{
+			name: "toleration",
+			pod: func() *corev1.Pod {
+				p := pod("gpu", "3", 0)
+				p.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu"}}
+				return p
+			}(),
+			want: []string{"tainted"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := victimNames(simulatePreemption(tc.pod, nodes, running))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("simulatePreemption() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
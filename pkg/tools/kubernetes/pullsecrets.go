@@ -0,0 +1,314 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ImagePullCheckToolDescription contains the documentation for the Image Pull Check Kubernetes tool.
+// It is formatted in Markdown.
+const ImagePullCheckToolDescription = `
+This tool checks the identity and registry credentials of a workload, to diagnose pods that fail to start with *ErrImagePull* or *ImagePullBackOff*, and tell authentication problems apart from typos in image names and tags.
+
+It reports:
+
+* **ServiceAccount**: whether the ServiceAccount used by the pods exists. Pods referencing a missing ServiceAccount are not created at all.
+* **imagePullSecrets**: the pull secrets of the pod spec and of its ServiceAccount, whether they exist, have a Docker config type and can be parsed, and the registries they hold credentials for.
+* **Images**: for each image, its registry and the pull secret with credentials for it. Images of Google registries (*gcr.io*, *pkg.dev*) without a pull secret are pulled with the node's service account on GKE, which needs read access to the repository.
+* **Pull errors**: the current image pull errors of the workload's pods, classified as *authentication* (e.g. unauthorized, denied or forbidden), *not found* (the image or tag doesn't exist, usually a typo) or *network* problems.
+
+## Arguments
+
+* *resource*: The kind or resource name of the workload, e.g. *deployment*, *statefulset*, *daemonset*, *job*, *cronjob* or *pod*.
+* *name*: The name of the workload.
+* *namespace*: (Optional) The namespace of the workload. Defaults to the server's default namespace.
+`
+
+type imagePullCheckArgs struct {
+	Resource  string `json:"resource"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// workloadPodSpec returns the pod spec of obj, which is a pod or a workload
+// with a pod template, and the selector of the workload's pods, if any.
+func workloadPodSpec(obj *unstructured.Unstructured) (*corev1.PodSpec, *metav1.LabelSelector, error) {
+	var specPath []string
+	switch obj.GetKind() {
+	case "Pod":
+		specPath = []string{"spec"}
+	case "CronJob":
+		specPath = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	default:
+		specPath = []string{"spec", "template", "spec"}
+	}
+	specMap, found, err := unstructured.NestedMap(obj.Object, specPath...)
+	if err != nil || !found {
+		return nil, nil, fmt.Errorf("%s %q has no pod template", obj.GetKind(), obj.GetName())
+	}
+	spec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(specMap, spec); err != nil {
+		return nil, nil, fmt.Errorf("failed to convert pod spec: %w", err)
+	}
+
+	var selector *metav1.LabelSelector
+	if selectorMap, found, err := unstructured.NestedMap(obj.Object, "spec", "selector"); err == nil && found {
+		selector = &metav1.LabelSelector{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, selector); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert selector: %w", err)
+		}
+	}
+	return spec, selector, nil
+}
+
+// imageRegistry returns the registry host of image, e.g. "docker.io" for
+// "nginx" and "us-docker.pkg.dev" for "us-docker.pkg.dev/p/r/app:1".
+func imageRegistry(image string) string {
+	host, _, found := strings.Cut(image, "/")
+	if !found || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		return "docker.io"
+	}
+	return host
+}
+
+// normalizeRegistry returns the registry host of a Docker config key, which
+// may be a URL such as "https://index.docker.io/v1/".
+func normalizeRegistry(key string) string {
+	if u, err := url.Parse(key); err == nil && u.Host != "" {
+		key = u.Host
+	}
+	key, _, _ = strings.Cut(key, "/")
+	switch key {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return "docker.io"
+	}
+	return key
+}
+
+// isGoogleRegistry reports whether GKE nodes authenticate to registry with
+// their service account.
+func isGoogleRegistry(registry string) bool {
+	return registry == "gcr.io" || strings.HasSuffix(registry, ".gcr.io") || strings.HasSuffix(registry, "-docker.pkg.dev")
+}
+
+// pullSecretRegistries returns the registries secret holds credentials
+// for.
+func pullSecretRegistries(secret *corev1.Secret) ([]string, error) {
+	var auths map[string]json.RawMessage
+	switch secret.Type {
+	case corev1.SecretTypeDockerConfigJson:
+		var config struct {
+			Auths map[string]json.RawMessage `json:"auths"`
+		}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", corev1.DockerConfigJsonKey, err)
+		}
+		auths = config.Auths
+	case corev1.SecretTypeDockercfg:
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &auths); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", corev1.DockerConfigKey, err)
+		}
+	default:
+		return nil, fmt.Errorf("type is %s, not %s", secret.Type, corev1.SecretTypeDockerConfigJson)
+	}
+	registries := make([]string, 0, len(auths))
+	for key := range auths {
+		registries = append(registries, normalizeRegistry(key))
+	}
+	return registries, nil
+}
+
+// classifyPullError returns the likely cause of an image pull error message.
+func classifyPullError(message string) string {
+	m := strings.ToLower(message)
+	for _, s := range []string{"unauthorized", "authentication required", "denied", "forbidden", "401", "403", "permission"} {
+		if strings.Contains(m, s) {
+			return "authentication"
+		}
+	}
+	for _, s := range []string{"not found", "manifest unknown", "404", "name unknown"} {
+		if strings.Contains(m, s) {
+			return "not found"
+		}
+	}
+	for _, s := range []string{"i/o timeout", "no such host", "connection refused", "tls handshake", "deadline exceeded"} {
+		if strings.Contains(m, s) {
+			return "network"
+		}
+	}
+	return "unknown"
+}
+
+func (h *handlers) imagePullCheck(ctx context.Context, _ *mcp.CallToolRequest, args *imagePullCheckArgs) (*mcp.CallToolResult, any, error) {
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+	gvr, err := h.findGVR(args.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	obj, err := h.dyn.Resource(gvr).Namespace(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get resource: %w", err)
+	}
+	spec, selector, err := workloadPodSpec(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var output strings.Builder
+	saName := spec.ServiceAccountName
+	if saName == "" {
+		saName = "default"
+	}
+	pullSecrets := append([]corev1.LocalObjectReference{}, spec.ImagePullSecrets...)
+	sa, err := h.clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, saName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		output.WriteString(fmt.Sprintf("ServiceAccount: %q NOT FOUND. Pods of the workload can't be created until it exists.\n", saName))
+	case err != nil:
+		return nil, nil, fmt.Errorf("failed to get service account: %w", err)
+	default:
+		output.WriteString(fmt.Sprintf("ServiceAccount: %q exists", saName))
+		if len(sa.ImagePullSecrets) > 0 {
+			output.WriteString(fmt.Sprintf(", with %d imagePullSecrets", len(sa.ImagePullSecrets)))
+		}
+		output.WriteString(".\n")
+		pullSecrets = append(pullSecrets, sa.ImagePullSecrets...)
+	}
+
+	// credentials maps registries to the pull secrets holding credentials
+	// for them.
+	credentials := map[string][]string{}
+	output.WriteString("\nimagePullSecrets:\n")
+	if len(pullSecrets) == 0 {
+		output.WriteString("none\n")
+	}
+	seen := map[string]bool{}
+	for _, ref := range pullSecrets {
+		if seen[ref.Name] {
+			continue
+		}
+		seen[ref.Name] = true
+		secret, err := h.clientset.CoreV1().Secrets(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			output.WriteString(fmt.Sprintf("- %s: NOT FOUND\n", ref.Name))
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get secret: %w", err)
+		}
+		registries, err := pullSecretRegistries(secret)
+		if err != nil {
+			output.WriteString(fmt.Sprintf("- %s: INVALID: %v\n", ref.Name, err))
+			continue
+		}
+		for _, r := range registries {
+			credentials[r] = append(credentials[r], ref.Name)
+		}
+		output.WriteString(fmt.Sprintf("- %s: registries %s\n", ref.Name, strings.Join(registries, ", ")))
+	}
+
+	output.WriteString("\nIMAGE\tREGISTRY\tCREDENTIALS\n")
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		registry := imageRegistry(c.Image)
+		creds := strings.Join(credentials[registry], ",")
+		switch {
+		case creds != "":
+		case isGoogleRegistry(registry):
+			creds = "node service account"
+		default:
+			creds = "none (anonymous pull)"
+		}
+		output.WriteString(fmt.Sprintf("%s\t%s\t%s\n", c.Image, registry, creds))
+	}
+
+	var pods []corev1.Pod
+	if obj.GetKind() == "Pod" {
+		pod := corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert pod: %w", err)
+		}
+		pods = append(pods, pod)
+	} else if selector != nil {
+		pods, err = h.listSelectedPods(ctx, namespace, selector)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(pods) > 0 {
+		// The ImagePullBackOff message doesn't include the pull error, which
+		// is in the latest Failed event of the pod.
+		pullFailures := map[string]string{}
+		events, err := h.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list events: %w", err)
+		}
+		latest := map[string]time.Time{}
+		for i := range events.Items {
+			e := &events.Items[i]
+			if e.InvolvedObject.Kind != "Pod" || e.Reason != "Failed" || !strings.Contains(e.Message, "pull") {
+				continue
+			}
+			if t := eventTime(e); t.After(latest[e.InvolvedObject.Name]) {
+				latest[e.InvolvedObject.Name] = t
+				pullFailures[e.InvolvedObject.Name] = e.Message
+			}
+		}
+
+		var pullErrors strings.Builder
+		for _, pod := range pods {
+			statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+			for _, cs := range statuses {
+				w := cs.State.Waiting
+				if w == nil || (w.Reason != "ErrImagePull" && w.Reason != "ImagePullBackOff") {
+					continue
+				}
+				message := w.Message
+				if failure, ok := pullFailures[pod.Name]; ok && w.Reason == "ImagePullBackOff" {
+					message = failure
+				}
+				pullErrors.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n", pod.Name, cs.Name, w.Reason, classifyPullError(message), message))
+			}
+		}
+		if pullErrors.Len() > 0 {
+			output.WriteString("\nPull errors:\nPOD\tCONTAINER\tREASON\tCAUSE\tMESSAGE\n")
+			output.WriteString(pullErrors.String())
+		} else {
+			output.WriteString(fmt.Sprintf("\nNo image pull errors in the %d pods of the workload.\n", len(pods)))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
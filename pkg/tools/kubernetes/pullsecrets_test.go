@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestImageRegistry(t *testing.T) {
+	for image, want := range map[string]string{
+		"nginx":                                 "docker.io",
+		"library/nginx:1.27":                    "docker.io",
+		"localhost/app":                         "localhost",
+		"registry.example.com:5000/app:1":       "registry.example.com:5000",
+		"us-docker.pkg.dev/project/repo/app:v1": "us-docker.pkg.dev",
+	} {
+		if got := imageRegistry(image); got != want {
+			t.Errorf("imageRegistry(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestPullSecretRegistries(t *testing.T) {
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"auth":"x"},"europe-docker.pkg.dev":{"auth":"y"}}}`),
+		},
+	}
+	got, err := pullSecretRegistries(secret)
+	if err != nil {
+		t.Fatalf("pullSecretRegistries() error = %v", err)
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff([]string{"docker.io", "europe-docker.pkg.dev"}, got); diff != "" {
+		t.Errorf("pullSecretRegistries() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := pullSecretRegistries(&corev1.Secret{Type: corev1.SecretTypeOpaque}); err == nil {
+		t.Errorf("pullSecretRegistries() of an Opaque secret succeeded, want error")
+	}
+}
+
+func TestClassifyPullError(t *testing.T) {
+	for message, want := range map[string]string{
+		`Failed to pull image "private/app:1": pull access denied, repository does not exist or may require authorization`: "authentication",
+		`Failed to pull image "nginx:1.999": rpc error: code = NotFound desc = failed to resolve reference: not found`:     "not found",
+		`Failed to pull image "registry.internal/app": dial tcp: lookup registry.internal: no such host`:                   "network",
+		`Back-off pulling image "nginx"`: "unknown",
+	} {
+		if got := classifyPullError(message); got != want {
+			t.Errorf("classifyPullError(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/compute/v1"
+)
+
+// GCPCheckQuotasToolDescription contains the documentation for the GCP Check Quotas tool.
+// It is formatted in Markdown.
+const GCPCheckQuotasToolDescription = `
+This tool reads the Compute Engine quotas of a region, such as CPUs, in-use IP addresses, persistent disk and GPUs, and the project-wide CPU and GPU quotas, and checks whether adding nodes would exceed them. Use it before creating a cluster or a node pool, or resizing one: a quota error is otherwise only reported once the operation fails, which can take several minutes.
+
+Without *node_count*, the tool reports the current usage of the quotas relevant to GKE nodes.
+
+## Arguments
+
+* *region*: The region of the cluster, e.g. *us-central1*. For a zonal cluster, the region of its zone.
+* *project_id*: (Optional) The project. Defaults to the default project.
+* *node_count*: (Optional) The number of nodes to add. For regional clusters, node pools are created with *node_count* nodes in each zone: multiply it by the number of zones.
+* *machine_type*: (Optional) The machine type of the new nodes. Defaults to *e2-medium*.
+* *disk_type*: (Optional) The boot disk type: *pd-standard*, *pd-balanced* or *pd-ssd*. Defaults to *pd-balanced*.
+* *disk_size_gb*: (Optional) The boot disk size in GB. Defaults to 100.
+* *local_ssd_count*: (Optional) The number of local SSDs of each node.
+* *accelerator_type*: (Optional) The GPU type of the new nodes, e.g. *nvidia-tesla-t4* or *nvidia-l4*.
+* *accelerator_count*: (Optional) The number of GPUs of each node. Defaults to 1 if *accelerator_type* is set.
+* *spot*: (Optional) Set to *true* for Spot or preemptible nodes, which use the preemptible quotas when the project has them.
+* *private_nodes*: (Optional) Set to *true* if the nodes have no external IP address, which then doesn't count towards *IN_USE_ADDRESSES*.
+
+## Response Format
+
+One row per quota. REQUESTED is the quantity the new nodes need, and STATUS is *EXCEEDED* if the usage would exceed the limit, *WARNING* if it would be at least 80% of the limit, and *OK* otherwise:
+
+METRIC	USAGE	LIMIT	REQUESTED	AFTER	STATUS
+CPUS	20	24	8	28	EXCEEDED
+IN_USE_ADDRESSES	5	69	4	9	OK
+`
+
+type gcpCheckQuotasArgs struct {
+	ProjectID        string `json:"project_id,omitempty"`
+	Region           string `json:"region"`
+	NodeCount        int64  `json:"node_count,omitempty"`
+	MachineType      string `json:"machine_type,omitempty"`
+	DiskType         string `json:"disk_type,omitempty"`
+	DiskSizeGB       int64  `json:"disk_size_gb,omitempty"`
+	LocalSSDCount    int64  `json:"local_ssd_count,omitempty"`
+	AcceleratorType  string `json:"accelerator_type,omitempty"`
+	AcceleratorCount int64  `json:"accelerator_count,omitempty"`
+	Spot             bool   `json:"spot,omitempty"`
+	PrivateNodes     bool   `json:"private_nodes,omitempty"`
+}
+
+const (
+	defaultQuotaMachineType = "e2-medium"
+	defaultQuotaDiskType    = "pd-balanced"
+	defaultQuotaDiskSizeGB  = 100
+	// localSSDSizeGB is the size of a local SSD.
+	localSSDSizeGB = 375
+	// quotaWarningRatio is the share of a limit above which a quota is
+	// reported as a warning.
+	quotaWarningRatio = 0.8
+)
+
+// nodeQuotaMetrics are the quotas reported when no nodes are requested.
+var nodeQuotaMetrics = []string{"CPUS", "INSTANCES", "IN_USE_ADDRESSES", "SSD_TOTAL_GB", "DISKS_TOTAL_GB", "LOCAL_SSD_TOTAL_GB", "PREEMPTIBLE_CPUS", "CPUS_ALL_REGIONS", "GPUS_ALL_REGIONS"}
+
+// quotaCheck is the usage of a quota before and after a request.
+type quotaCheck struct {
+	metric    string
+	usage     float64
+	limit     float64
+	requested float64
+}
+
+func (c quotaCheck) status() string {
+	after := c.usage + c.requested
+	switch {
+	case c.limit >= 0 && after > c.limit:
+		return "EXCEEDED"
+	case c.limit > 0 && after >= quotaWarningRatio*c.limit:
+		return "WARNING"
+	}
+	return "OK"
+}
+
+// cpuQuotaMetric returns the CPU quota of machineType: CPUS for the N1, E2,
+// F1 and G1 families, and FAMILY_CPUS otherwise, e.g. N2D_CPUS.
+func cpuQuotaMetric(machineType string) string {
+	family, _, _ := strings.Cut(machineType, "-")
+	switch family {
+	case "n1", "e2", "f1", "g1", "custom":
+		return "CPUS"
+	}
+	return strings.ToUpper(family) + "_CPUS"
+}
+
+// gpuQuotaMetric returns the GPU quota of acceleratorType, e.g.
+// NVIDIA_T4_GPUS for nvidia-tesla-t4.
+func gpuQuotaMetric(acceleratorType string) string {
+	name := strings.TrimPrefix(acceleratorType, "nvidia-")
+	name = strings.TrimPrefix(name, "tesla-")
+	return "NVIDIA_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_GPUS"
+}
+
+// diskQuotaMetric returns the disk quota of diskType. Balanced persistent
+// disks count towards the SSD quota.
+func diskQuotaMetric(diskType string) string {
+	if diskType == "pd-standard" {
+		return "DISKS_TOTAL_GB"
+	}
+	return "SSD_TOTAL_GB"
+}
+
+// quotaDemand returns the quantity of each quota the nodes of args need,
+// given the number of CPUs of their machine type. The preemptible quotas are
+// used for Spot nodes if the project has them.
+func quotaDemand(args *gcpCheckQuotasArgs, cpus int64, limits map[string]float64) map[string]float64 {
+	n := float64(args.NodeCount)
+	preemptible := func(metric string) string {
+		if args.Spot && limits["PREEMPTIBLE_"+metric] > 0 {
+			return "PREEMPTIBLE_" + metric
+		}
+		return metric
+	}
+	demand := map[string]float64{
+		"INSTANCES":        n,
+		"CPUS_ALL_REGIONS": n * float64(cpus),
+	}
+	cpuMetric := cpuQuotaMetric(args.MachineType)
+	if args.Spot && limits["PREEMPTIBLE_CPUS"] > 0 {
+		cpuMetric = "PREEMPTIBLE_CPUS"
+	}
+	demand[cpuMetric] += n * float64(cpus)
+	if !args.PrivateNodes {
+		demand["IN_USE_ADDRESSES"] += n
+	}
+	demand[diskQuotaMetric(args.DiskType)] += n * float64(args.DiskSizeGB)
+	if args.LocalSSDCount > 0 {
+		demand[preemptible("LOCAL_SSD_TOTAL_GB")] += n * float64(args.LocalSSDCount*localSSDSizeGB)
+	}
+	if args.AcceleratorType != "" {
+		demand[preemptible(gpuQuotaMetric(args.AcceleratorType))] += n * float64(args.AcceleratorCount)
+		demand["GPUS_ALL_REGIONS"] += n * float64(args.AcceleratorCount)
+	}
+	return demand
+}
+
+// checkQuotas returns the checks of the quotas of demand, in the order of
+// quotas, followed by the metrics missing from quotas, which have a limit of
+// zero. Without demand, it returns the checks of nodeQuotaMetrics.
+func checkQuotas(quotas []*compute.Quota, demand map[string]float64) []quotaCheck {
+	var checks []quotaCheck
+	seen := map[string]bool{}
+	for _, q := range quotas {
+		requested, ok := demand[q.Metric]
+		if len(demand) == 0 {
+			for _, m := range nodeQuotaMetrics {
+				ok = ok || m == q.Metric
+			}
+		}
+		if !ok {
+			continue
+		}
+		seen[q.Metric] = true
+		checks = append(checks, quotaCheck{metric: q.Metric, usage: q.Usage, limit: q.Limit, requested: requested})
+	}
+	var missing []string
+	for metric, requested := range demand {
+		if !seen[metric] && requested > 0 {
+			missing = append(missing, metric)
+		}
+	}
+	sort.Strings(missing)
+	for _, metric := range missing {
+		checks = append(checks, quotaCheck{metric: metric, requested: demand[metric]})
+	}
+	return checks
+}
+
+func (h *handlers) gcpCheckQuotas(ctx context.Context, _ *mcp.CallToolRequest, args *gcpCheckQuotasArgs) (*mcp.CallToolResult, any, error) {
+	if args.Region == "" {
+		return nil, nil, fmt.Errorf("region is required")
+	}
+	projectID := args.ProjectID
+	if projectID == "" {
+		projectID = h.c.DefaultProjectID()
+	}
+	if args.MachineType == "" {
+		args.MachineType = defaultQuotaMachineType
+	}
+	if args.DiskType == "" {
+		args.DiskType = defaultQuotaDiskType
+	}
+	if args.DiskSizeGB <= 0 {
+		args.DiskSizeGB = defaultQuotaDiskSizeGB
+	}
+	if args.AcceleratorType != "" && args.AcceleratorCount <= 0 {
+		args.AcceleratorCount = 1
+	}
+
+	region, err := h.computeService.Regions.Get(projectID, args.Region).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get region: %w", err)
+	}
+	project, err := h.computeService.Projects.Get(projectID).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	quotas := append(region.Quotas, project.Quotas...)
+	limits := map[string]float64{}
+	for _, q := range quotas {
+		limits[q.Metric] = q.Limit
+	}
+
+	var output strings.Builder
+	var demand map[string]float64
+	if args.NodeCount > 0 {
+		if len(region.Zones) == 0 {
+			return nil, nil, fmt.Errorf("region %s has no zones", args.Region)
+		}
+		zone := path.Base(region.Zones[0])
+		machineType, err := h.computeService.MachineTypes.Get(projectID, zone, args.MachineType).Context(ctx).Do()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get machine type: %w", err)
+		}
+		demand = quotaDemand(args, machineType.GuestCpus, limits)
+		output.WriteString(fmt.Sprintf("Quotas of %s in %s for %d %s nodes (%d vCPUs, %s %dGB):\n",
+			projectID, args.Region, args.NodeCount, args.MachineType, machineType.GuestCpus, args.DiskType, args.DiskSizeGB))
+	} else {
+		output.WriteString(fmt.Sprintf("Quotas of %s in %s:\n", projectID, args.Region))
+	}
+
+	checks := checkQuotas(quotas, demand)
+	exceeded := 0
+	output.WriteString("METRIC\tUSAGE\tLIMIT\tREQUESTED\tAFTER\tSTATUS\n")
+	for _, c := range checks {
+		status := c.status()
+		if status == "EXCEEDED" {
+			exceeded++
+		}
+		output.WriteString(fmt.Sprintf("%s\t%g\t%g\t%g\t%g\t%s\n", c.metric, c.usage, c.limit, c.requested, c.usage+c.requested, status))
+	}
+	if args.NodeCount > 0 {
+		if exceeded > 0 {
+			output.WriteString(fmt.Sprintf("\nThe request would exceed %d quotas: request an increase at https://console.cloud.google.com/iam-admin/quotas?project=%s before creating or resizing the nodes.\n", exceeded, projectID))
+		} else {
+			output.WriteString("\nThe request fits within the quotas. Stockouts of the machine type in a zone are not checked.\n")
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/api/compute/v1"
+)
+
+func TestQuotaMetrics(t *testing.T) {
+	for _, tc := range []struct {
+		got, want string
+	}{
+		{cpuQuotaMetric("e2-standard-4"), "CPUS"},
+		{cpuQuotaMetric("n1-highmem-8"), "CPUS"},
+		{cpuQuotaMetric("n2d-standard-16"), "N2D_CPUS"},
+		{cpuQuotaMetric("c3-standard-22"), "C3_CPUS"},
+		{gpuQuotaMetric("nvidia-tesla-t4"), "NVIDIA_T4_GPUS"},
+		{gpuQuotaMetric("nvidia-l4"), "NVIDIA_L4_GPUS"},
+		{gpuQuotaMetric("nvidia-a100-80gb"), "NVIDIA_A100_80GB_GPUS"},
+		{diskQuotaMetric("pd-standard"), "DISKS_TOTAL_GB"},
+		{diskQuotaMetric("pd-balanced"), "SSD_TOTAL_GB"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("got %s, want %s", tc.got, tc.want)
+		}
+	}
+}
+
+func TestQuotaDemand(t *testing.T) {
+	args := &gcpCheckQuotasArgs{
+		NodeCount:        3,
+		MachineType:      "n1-standard-4",
+		DiskType:         "pd-balanced",
+		DiskSizeGB:       100,
+		AcceleratorType:  "nvidia-tesla-t4",
+		AcceleratorCount: 1,
+		Spot:             true,
+	}
+	limits := map[string]float64{"PREEMPTIBLE_CPUS": 100}
+	got := quotaDemand(args, 4, limits)
+	want := map[string]float64{
+		"INSTANCES":        3,
+		"CPUS_ALL_REGIONS": 12,
+		"PREEMPTIBLE_CPUS": 12,
+		"IN_USE_ADDRESSES": 3,
+		"SSD_TOTAL_GB":     300,
+		// The project has no preemptible T4 quota.
+		"NVIDIA_T4_GPUS":   3,
+		"GPUS_ALL_REGIONS": 3,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("quotaDemand() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckQuotas(t *testing.T) {
+	quotas := []*compute.Quota{
+		{Metric: "CPUS", Usage: 20, Limit: 24},
+		{Metric: "IN_USE_ADDRESSES", Usage: 5, Limit: 69},
+		{Metric: "SSD_TOTAL_GB", Usage: 500, Limit: 1000},
+		{Metric: "NETWORKS", Usage: 1, Limit: 5},
+	}
+	demand := map[string]float64{"CPUS": 8, "IN_USE_ADDRESSES": 4, "SSD_TOTAL_GB": 300, "NVIDIA_T4_GPUS": 2}
+	got := checkQuotas(quotas, demand)
+	want := []quotaCheck{
+		{metric: "CPUS", usage: 20, limit: 24, requested: 8},
+		{metric: "IN_USE_ADDRESSES", usage: 5, limit: 69, requested: 4},
+		{metric: "SSD_TOTAL_GB", usage: 500, limit: 1000, requested: 300},
+		{metric: "NVIDIA_T4_GPUS", requested: 2},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(quotaCheck{})); diff != "" {
+		t.Errorf("checkQuotas() mismatch (-want +got):\n%s", diff)
+	}
+	var statuses []string
+	for _, c := range got {
+		statuses = append(statuses, c.status())
+	}
+	if diff := cmp.Diff([]string{"EXCEEDED", "OK", "WARNING", "EXCEEDED"}, statuses); diff != "" {
+		t.Errorf("status() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Without demand, the quotas relevant to nodes are reported.
+	got = checkQuotas(quotas, nil)
+	if len(got) != 3 {
+		t.Errorf("checkQuotas() without demand = %v, want the 3 node quotas", got)
+	}
+}
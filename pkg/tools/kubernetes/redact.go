@@ -0,0 +1,128 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// redactedValue replaces the secret values in tool results.
+const redactedValue = "[REDACTED]"
+
+// lastAppliedAnnotation holds the manifest of the last client-side apply,
+// including the values of Secrets.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// sensitiveEnvNameRe matches the names of environment variables that likely
+// hold credentials, e.g. DB_PASSWORD or GITHUB_TOKEN.
+var sensitiveEnvNameRe = regexp.MustCompile(`(?i)(password|passwd|secret|token|credential|api_?key|private_?key|access_?key)`)
+
+// redact returns obj with its secret values masked if the configuration
+// requires it, and obj itself otherwise.
+func (h *handlers) redact(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	if !h.c.RedactSecrets() {
+		return obj
+	}
+	return redactSecrets(obj)
+}
+
+// redactSecrets returns a copy of obj with its secret values masked: the
+// data of Secrets, the values of environment variables with sensitive names,
+// and the same values in the last-applied-configuration annotation.
+func redactSecrets(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	obj = obj.DeepCopy()
+	redactFields(obj.Object)
+	annotations := obj.GetAnnotations()
+	if lastApplied, ok := annotations[lastAppliedAnnotation]; ok {
+		var applied map[string]any
+		if err := json.Unmarshal([]byte(lastApplied), &applied); err == nil {
+			redactFields(applied)
+			if data, err := json.Marshal(applied); err == nil {
+				annotations[lastAppliedAnnotation] = string(data)
+			} else {
+				annotations[lastAppliedAnnotation] = redactedValue
+			}
+		} else {
+			annotations[lastAppliedAnnotation] = redactedValue
+		}
+		obj.SetAnnotations(annotations)
+	}
+	return obj
+}
+
+// redactFields masks in place the secret values of the object obj.
+func redactFields(obj map[string]any) {
+	if obj["kind"] == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			if data, ok := obj[field].(map[string]any); ok {
+				for k := range data {
+					data[k] = redactedValue
+				}
+			}
+		}
+	}
+	redactEnv(obj)
+}
+
+// redactEnv masks in place the values of the environment variables with
+// sensitive names found anywhere in v, e.g. in the containers of pods and
+// of pod templates.
+func redactEnv(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		if env, ok := v["env"].([]any); ok {
+			for _, e := range env {
+				e, ok := e.(map[string]any)
+				if !ok {
+					continue
+				}
+				name, _ := e["name"].(string)
+				if value, ok := e["value"].(string); ok && value != "" && sensitiveEnvNameRe.MatchString(name) {
+					e["value"] = redactedValue
+				}
+			}
+		}
+		for _, child := range v {
+			redactEnv(child)
+		}
+	case []any:
+		for _, child := range v {
+			redactEnv(child)
+		}
+	}
+}
+
+// redactedPaths returns the paths of the fields of objs, as flattened by
+// flattenFields, whose values are masked by the configuration.
+func (h *handlers) redactedPaths(objs ...*unstructured.Unstructured) map[string]bool {
+	if !h.c.RedactSecrets() {
+		return nil
+	}
+	paths := map[string]bool{}
+	for _, obj := range objs {
+		raw, redacted := map[string]string{}, map[string]string{}
+		flattenFields("", obj.Object, raw)
+		flattenFields("", redactSecrets(obj).Object, redacted)
+		for path, v := range raw {
+			if redacted[path] != v {
+				paths[path] = true
+			}
+		}
+	}
+	return paths
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	secret := decodeObject(t, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{"apiVersion":"v1","kind":"Secret","metadata":{"name":"db"},"stringData":{"password":"hunter2"}}'
+type: Opaque
+data:
+  password: aHVudGVyMg==
+  username: YWRtaW4=
+`)
+	want := decodeObject(t, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{"apiVersion":"v1","kind":"Secret","metadata":{"name":"db"},"stringData":{"password":"[REDACTED]"}}'
+type: Opaque
+data:
+  password: '[REDACTED]'
+  username: '[REDACTED]'
+`)
+	original := secret.DeepCopy()
+	if diff := cmp.Diff(want.Object, redactSecrets(secret).Object); diff != "" {
+		t.Errorf("redactSecrets(Secret) mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(original.Object, secret.Object); diff != "" {
+		t.Errorf("redactSecrets() modified its argument (-want +got):\n%s", diff)
+	}
+
+	deployment := decodeObject(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        env:
+        - name: DB_PASSWORD
+          value: hunter2
+        - name: GITHUB_TOKEN
+          value: ghp_abc
+        - name: API_KEY
+          valueFrom:
+            secretKeyRef: {name: api, key: key}
+        - name: LOG_LEVEL
+          value: debug
+`)
+	want = decodeObject(t, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        env:
+        - name: DB_PASSWORD
+          value: '[REDACTED]'
+        - name: GITHUB_TOKEN
+          value: '[REDACTED]'
+        - name: API_KEY
+          valueFrom:
+            secretKeyRef: {name: api, key: key}
+        - name: LOG_LEVEL
+          value: debug
+`)
+	if diff := cmp.Diff(want.Object, redactSecrets(deployment).Object); diff != "" {
+		t.Errorf("redactSecrets(Deployment) mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,474 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GKERightsizeNodePoolsToolDescription contains the documentation for the GKE Rightsize Node Pools tool.
+// It is formatted in Markdown.
+const GKERightsizeNodePoolsToolDescription = `
+This tool recommends machine type and size changes for the node pools of a GKE cluster, with an estimate of the cost difference. It combines the requests of the pods, the usage of the nodes from the metrics server, a bin-packing estimate and the machine types available in the zone of the nodes.
+
+For each node pool, the tool packs the pods running on it, by CPU and memory requests, onto each candidate machine type, first fit decreasing, keeping the requested headroom free and reserving on every node the requests of the DaemonSet pods and the resources GKE reserves for the system. It then ranks the candidates by estimated hourly cost.
+
+Costs are estimated from approximate on-demand list prices in us-central1 for the E2, N1, N2, N2D, T2D, C2 and C2D families, without discounts, Spot pricing, disks or GPUs: use them to compare the candidates, not as a bill. Machine types of other families are listed without cost. For node pools outside us-central1, the costs are still those of us-central1, and only their differences are meaningful.
+
+Nodes are packed by requests: if the usage is much lower than the requests, right-size the requests of the workloads first, e.g. with the Vertical Pod Autoscaler recommendations.
+
+## Arguments
+
+* *node_pool*: (Optional) The node pool to analyze. Defaults to all node pools.
+* *families*: (Optional) The machine families to consider, e.g. *["e2", "n2d"]*. Defaults to the family of the node pool and E2.
+* *headroom_percent*: (Optional) The share of the allocatable resources of each node to keep free for spikes and rolling updates. Defaults to 20.
+
+## Response Format
+
+For each node pool, its current shape and utilization, and the candidates, cheapest first:
+
+Node pool default-pool: 3 x n2-standard-8 in us-central1-a, ~$1.165/h
+  Requests: cpu 5.9 of 23.6 (25%), memory 14.2Gi of 85.1Gi (16%)
+  Usage: cpu 2.1 (9%), memory 11.8Gi (13%)
+  DaemonSet requests per node: cpu 350m, memory 480Mi
+
+  MACHINE_TYPE    NODES  COST/H  DELTA/MONTH
+  e2-standard-4   3      $0.402  -$557
+  n2-standard-4   3      $0.583  -$425
+  n2-standard-8   2      $0.777  -$283   (current type)
+
+  Recommendation: 3 x e2-standard-4, saving ~$557/month.
+`
+
+type gkeRightsizeNodePoolsArgs struct {
+	NodePool        string   `json:"node_pool,omitempty"`
+	Families        []string `json:"families,omitempty"`
+	HeadroomPercent *int     `json:"headroom_percent,omitempty"`
+}
+
+const (
+	defaultRightsizingHeadroom = 20
+	hoursPerMonth              = 730
+	instanceTypeLabel          = "node.kubernetes.io/instance-type"
+)
+
+// pricedRegion is the region of machinePrices and diskPricePerGBMonth: the
+// prices of the other regions differ, usually higher.
+const pricedRegion = "us-central1"
+
+// machinePrice is the approximate on-demand price of a machine family in
+// us-central1, in dollars per hour.
+type machinePrice struct {
+	vCPU, memoryGB float64
+}
+
+// machinePrices are the approximate on-demand prices of the predefined machine
+// types of common families in us-central1.
+var machinePrices = map[string]machinePrice{
+	"e2":  {0.021811, 0.002923},
+	"n1":  {0.031611, 0.004237},
+	"n2":  {0.031611, 0.004237},
+	"n2d": {0.027502, 0.003686},
+	"t2d": {0.027502, 0.003686},
+	"c2":  {0.03398, 0.00455},
+	"c2d": {0.029563, 0.003959},
+}
+
+// machineFamily returns the family of machineType, e.g. "n2d" for
+// n2d-standard-4.
+func machineFamily(machineType string) string {
+	family, _, _ := strings.Cut(machineType, "-")
+	return family
+}
+
+// zoneRegion returns the region of zone, e.g. us-central1 for us-central1-a.
+func zoneRegion(zone string) string {
+	if !isZone(zone) {
+		return zone
+	}
+	return zone[:strings.LastIndex(zone, "-")]
+}
+
+// hourlyPrice returns the estimated hourly price of a machine type of family
+// with cpus vCPUs and memoryMB of memory.
+func hourlyPrice(family string, cpus, memoryMB int64) (float64, bool) {
+	p, ok := machinePrices[family]
+	if !ok {
+		return 0, false
+	}
+	return float64(cpus)*p.vCPU + float64(memoryMB)/1024*p.memoryGB, true
+}
+
+// reservationTier is a tier of the resources GKE reserves on a node: share
+// of the next size of the resource, or of the rest if size is 0.
+type reservationTier struct {
+	size  int64
+	share float64
+}
+
+// tieredReservation returns the reservation of tiers on total.
+func tieredReservation(total int64, tiers []reservationTier) int64 {
+	var reserved int64
+	remaining := total
+	for _, t := range tiers {
+		n := remaining
+		if t.size > 0 {
+			n = min(remaining, t.size)
+		}
+		reserved += int64(float64(n) * t.share)
+		remaining -= n
+		if remaining <= 0 {
+			break
+		}
+	}
+	return reserved
+}
+
+// gkeAllocatable returns the allocatable CPU, in millicores, and memory, in
+// bytes, of a GKE node with cpus vCPUs and memoryMB of memory, after the
+// resources GKE reserves for the system and the eviction threshold.
+func gkeAllocatable(cpus, memoryMB int64) (int64, int64) {
+	milli := cpus * 1000
+	reservedCPU := tieredReservation(milli, []reservationTier{{1000, 0.06}, {1000, 0.01}, {2000, 0.005}, {0, 0.0025}})
+
+	const gi = int64(1) << 30
+	const evictionThreshold = 100 << 20
+	memory := memoryMB << 20
+	reservedMemory := int64(255 << 20)
+	if memory >= gi {
+		reservedMemory = tieredReservation(memory, []reservationTier{{4 * gi, 0.25}, {4 * gi, 0.2}, {8 * gi, 0.1}, {112 * gi, 0.06}, {0, 0.02}})
+	}
+	return milli - reservedCPU, memory - reservedMemory - evictionThreshold
+}
+
+// podDemand is the CPU, in millicores, and memory, in bytes, requested by a
+// pod or available on a node.
+type podDemand struct {
+	cpu, memory int64
+}
+
+func newPodDemand(requests corev1.ResourceList) podDemand {
+	return podDemand{cpu: requests.Cpu().MilliValue(), memory: requests.Memory().Value()}
+}
+
+// packPods returns the number of nodes of the given capacity, each running
+// at most maxPods pods, needed to run pods, packed first fit decreasing. It
+// returns false if a pod doesn't fit on an empty node.
+func packPods(pods []podDemand, capacity podDemand, maxPods int) (int, bool) {
+	size := func(p podDemand) float64 {
+		return max(float64(p.cpu)/float64(capacity.cpu), float64(p.memory)/float64(capacity.memory))
+	}
+	sorted := slices.Clone(pods)
+	sort.SliceStable(sorted, func(i, j int) bool { return size(sorted[i]) > size(sorted[j]) })
+
+	type bin struct {
+		free podDemand
+		pods int
+	}
+	var bins []*bin
+	for _, p := range sorted {
+		if p.cpu > capacity.cpu || p.memory > capacity.memory {
+			return 0, false
+		}
+		var target *bin
+		for _, b := range bins {
+			if p.cpu <= b.free.cpu && p.memory <= b.free.memory && b.pods < maxPods {
+				target = b
+				break
+			}
+		}
+		if target == nil {
+			target = &bin{free: capacity}
+			bins = append(bins, target)
+		}
+		target.free.cpu -= p.cpu
+		target.free.memory -= p.memory
+		target.pods++
+	}
+	return max(len(bins), 1), true
+}
+
+// isPerNodePod reports whether pod runs on every node of its pool: a
+// DaemonSet pod, or the mirror pod of a static pod such as kube-proxy.
+func isPerNodePod(pod *corev1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" || ref.Kind == "Node" {
+			return true
+		}
+	}
+	return false
+}
+
+// rightsizingCandidate is a machine type considered for a node pool.
+type rightsizingCandidate struct {
+	machineType string
+	nodes       int
+	// cost is the estimated hourly cost of the nodes, or -1 if unknown.
+	cost float64
+}
+
+// formatMonthlyDelta formats the monthly difference of an hourly cost.
+func formatMonthlyDelta(hourly float64) string {
+	monthly := hourly * hoursPerMonth
+	if monthly < 0 {
+		return fmt.Sprintf("-$%.0f", -monthly)
+	}
+	return fmt.Sprintf("+$%.0f", monthly)
+}
+
+// formatShareOf formats used as a share of total.
+func formatShareOf(used, total float64) string {
+	if total <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", used*100/total)
+}
+
+func (h *handlers) gkeRightsizeNodePools(ctx context.Context, _ *mcp.CallToolRequest, args *gkeRightsizeNodePoolsArgs) (*mcp.CallToolResult, any, error) {
+	headroom := defaultRightsizingHeadroom
+	if args.HeadroomPercent != nil {
+		headroom = *args.HeadroomPercent
+	}
+	if headroom < 0 || headroom >= 100 {
+		return nil, nil, fmt.Errorf("headroom_percent must be between 0 and 99, got %d", headroom)
+	}
+	// The nodes analyzed are those of the cluster of the kubeconfig context.
+	if h.autopilot {
+		return nil, nil, fmt.Errorf("the cluster is a GKE Autopilot cluster: Google manages and sizes its node pools")
+	}
+
+	nodes, err := h.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := h.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	podsByNode := map[string][]*corev1.Pod{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName != "" && pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+		}
+	}
+	usage := map[string]corev1.ResourceList{}
+	if nodeMetrics, err := h.metricsClientset.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{}); err == nil {
+		for _, m := range nodeMetrics.Items {
+			usage[m.Name] = m.Usage
+		}
+	}
+
+	pools := map[string][]*corev1.Node{}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		pool := nodePoolOf(node)
+		if args.NodePool == "" || pool == args.NodePool {
+			pools[pool] = append(pools[pool], node)
+		}
+	}
+	if len(pools) == 0 {
+		return nil, nil, fmt.Errorf("no nodes found in node pool %q", args.NodePool)
+	}
+	names := make([]string, 0, len(pools))
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var output strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			output.WriteString("\n")
+		}
+		if err := h.writeRightsizing(ctx, &output, name, pools[name], podsByNode, usage, args.Families, headroom); err != nil {
+			output.WriteString(fmt.Sprintf("Node pool %s: can't be analyzed: %v\n", name, err))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+// writeRightsizing writes the rightsizing recommendation of the node pool
+// name made of nodes.
+func (h *handlers) writeRightsizing(ctx context.Context, out *strings.Builder, name string, nodes []*corev1.Node, podsByNode map[string][]*corev1.Pod, usage map[string]corev1.ResourceList, families []string, headroom int) error {
+	current := nodes[0].Labels[instanceTypeLabel]
+	if current == "" {
+		return fmt.Errorf("node %s has no %s label", nodes[0].Name, instanceTypeLabel)
+	}
+	project, zone, _, err := parseGCEProviderID(nodes[0].Spec.ProviderID)
+	if err != nil {
+		return err
+	}
+
+	var perNode podDemand
+	var workloads []podDemand
+	var requested, allocatable, used corev1.ResourceList = corev1.ResourceList{}, corev1.ResourceList{}, corev1.ResourceList{}
+	maxPods := 110
+	for _, node := range nodes {
+		addResources(allocatable, node.Status.Allocatable)
+		if u, ok := usage[node.Name]; ok {
+			addResources(used, u)
+		}
+		if p := node.Status.Allocatable.Pods().Value(); p > 0 {
+			maxPods = int(p)
+		}
+		var nodeOverhead podDemand
+		for _, pod := range podsByNode[node.Name] {
+			r, _ := podRequestsAndLimits(pod)
+			addResources(requested, r)
+			d := newPodDemand(r)
+			if isPerNodePod(pod) {
+				nodeOverhead.cpu += d.cpu
+				nodeOverhead.memory += d.memory
+			} else {
+				workloads = append(workloads, d)
+			}
+		}
+		perNode.cpu = max(perNode.cpu, nodeOverhead.cpu)
+		perNode.memory = max(perNode.memory, nodeOverhead.memory)
+	}
+
+	currentFamily := machineFamily(current)
+	if len(families) == 0 {
+		families = []string{currentFamily, "e2"}
+	}
+	machineTypes, err := h.computeService.MachineTypes.List(project, zone).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to list machine types of zone %s: %w", zone, err)
+	}
+
+	var currentPrice float64 = -1
+	var candidates []rightsizingCandidate
+	for _, mt := range machineTypes.Items {
+		if mt.Name == current {
+			if price, ok := hourlyPrice(currentFamily, mt.GuestCpus, mt.MemoryMb); ok {
+				currentPrice = price * float64(len(nodes))
+			}
+		}
+		if mt.IsSharedCpu || mt.Deprecated != nil || (!slices.Contains(families, machineFamily(mt.Name)) && mt.Name != current) {
+			continue
+		}
+		if !strings.Contains(mt.Name, "-standard-") && !strings.Contains(mt.Name, "-highmem-") && !strings.Contains(mt.Name, "-highcpu-") {
+			continue
+		}
+		cpu, memory := gkeAllocatable(mt.GuestCpus, mt.MemoryMb)
+		capacity := podDemand{
+			cpu:    cpu*int64(100-headroom)/100 - perNode.cpu,
+			memory: memory*int64(100-headroom)/100 - perNode.memory,
+		}
+		if capacity.cpu <= 0 || capacity.memory <= 0 {
+			continue
+		}
+		n, ok := packPods(workloads, capacity, maxPods)
+		if !ok {
+			continue
+		}
+		c := rightsizingCandidate{machineType: mt.Name, nodes: n, cost: -1}
+		if price, ok := hourlyPrice(machineFamily(mt.Name), mt.GuestCpus, mt.MemoryMb); ok {
+			c.cost = price * float64(n)
+		}
+		candidates = append(candidates, c)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if (ci.cost < 0) != (cj.cost < 0) {
+			return cj.cost < 0
+		}
+		if ci.cost != cj.cost {
+			return ci.cost < cj.cost
+		}
+		return ci.nodes < cj.nodes
+	})
+
+	out.WriteString(fmt.Sprintf("Node pool %s: %d x %s in %s", name, len(nodes), current, zone))
+	if currentPrice >= 0 {
+		out.WriteString(fmt.Sprintf(", ~$%.3f/h", currentPrice))
+	}
+	out.WriteString("\n")
+	if region := zoneRegion(zone); region != pricedRegion {
+		out.WriteString(fmt.Sprintf("  Costs are %s list prices, not those of %s: compare the candidates by their relative cost.\n", pricedRegion, region))
+	}
+	cpuRequested, cpuAllocatable := float64(requested.Cpu().MilliValue())/1000, float64(allocatable.Cpu().MilliValue())/1000
+	memRequested, memAllocatable := float64(requested.Memory().Value()), float64(allocatable.Memory().Value())
+	out.WriteString(fmt.Sprintf("  Requests: cpu %.1f of %.1f (%s), memory %s of %s (%s)\n",
+		cpuRequested, cpuAllocatable, formatShareOf(cpuRequested, cpuAllocatable),
+		formatGi(requested.Memory()), formatGi(allocatable.Memory()), formatShareOf(memRequested, memAllocatable)))
+	if len(used) > 0 {
+		cpuUsed := float64(used.Cpu().MilliValue()) / 1000
+		out.WriteString(fmt.Sprintf("  Usage: cpu %.1f (%s), memory %s (%s)\n",
+			cpuUsed, formatShareOf(cpuUsed, cpuAllocatable), formatGi(used.Memory()), formatShareOf(float64(used.Memory().Value()), memAllocatable)))
+	} else {
+		out.WriteString("  Usage: unavailable, the metrics server can't be read\n")
+	}
+	out.WriteString(fmt.Sprintf("  DaemonSet requests per node: cpu %dm, memory %dMi\n", perNode.cpu, perNode.memory>>20))
+
+	if len(candidates) == 0 {
+		out.WriteString("  No candidate machine type fits the pods.\n")
+		return nil
+	}
+	const maxCandidates = 5
+	out.WriteString("\n")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  MACHINE_TYPE\tNODES\tCOST/H\tDELTA/MONTH\t")
+	for i, c := range candidates {
+		if i >= maxCandidates && c.machineType != current {
+			continue
+		}
+		cost, delta := "-", "-"
+		if c.cost >= 0 {
+			cost = fmt.Sprintf("$%.3f", c.cost)
+			if currentPrice >= 0 {
+				delta = formatMonthlyDelta(c.cost - currentPrice)
+			}
+		}
+		note := ""
+		if c.machineType == current {
+			note = "(current type)"
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%s\t%s\t%s\n", c.machineType, c.nodes, cost, delta, note)
+	}
+	w.Flush()
+
+	best := candidates[0]
+	switch {
+	case best.cost < 0 || currentPrice < 0:
+		out.WriteString(fmt.Sprintf("\n  Recommendation: %d x %s fit the pods; the cost of the current type can't be estimated.\n", best.nodes, best.machineType))
+	case best.cost < currentPrice:
+		out.WriteString(fmt.Sprintf("\n  Recommendation: %d x %s, saving ~$%.0f/month.\n", best.nodes, best.machineType, (currentPrice-best.cost)*hoursPerMonth))
+	default:
+		out.WriteString("\n  Recommendation: keep the current shape, no candidate is cheaper.\n")
+	}
+	return nil
+}
+
+// formatGi formats q in GiB.
+func formatGi(q *resource.Quantity) string {
+	return fmt.Sprintf("%.1fGi", float64(q.Value())/(1<<30))
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+)
+
+func TestGKEAllocatable(t *testing.T) {
+	for _, tc := range []struct {
+		cpus, memoryMB      int64
+		wantCPU, wantMemory int64
+	}{
+		// e2-standard-4: 3920m and 16 GiB - 2.6 GiB reserved - 100 MiB.
+		{cpus: 4, memoryMB: 16384, wantCPU: 3920, wantMemory: 16<<30 - 2791728742 - 100<<20},
+		// Less than 1 GiB of memory: 255 MiB reserved.
+		{cpus: 2, memoryMB: 1024 - 1, wantCPU: 1930, wantMemory: 1023<<20 - 255<<20 - 100<<20},
+	} {
+		cpu, memory := gkeAllocatable(tc.cpus, tc.memoryMB)
+		if cpu != tc.wantCPU || memory != tc.wantMemory {
+			t.Errorf("gkeAllocatable(%d, %d) = %d, %d, want %d, %d", tc.cpus, tc.memoryMB, cpu, memory, tc.wantCPU, tc.wantMemory)
+		}
+	}
+}
+
+func TestPackPods(t *testing.T) {
+	capacity := podDemand{cpu: 2000, memory: 4 << 30}
+	pods := []podDemand{
+		{cpu: 1500, memory: 1 << 30},
+		{cpu: 500, memory: 1 << 30},
+		{cpu: 1000, memory: 3 << 30},
+		{cpu: 1000, memory: 1 << 30},
+	}
+	if got, ok := packPods(pods, capacity, 110); !ok || got != 2 {
+		t.Errorf("packPods() = %d, %v, want 2, true", got, ok)
+	}
+	if got, ok := packPods(pods, capacity, 1); !ok || got != 4 {
+		t.Errorf("packPods() with 1 pod per node = %d, %v, want 4, true", got, ok)
+	}
+	if _, ok := packPods(append(pods, podDemand{cpu: 3000}), capacity, 110); ok {
+		t.Error("packPods() with a pod larger than a node succeeded, want false")
+	}
+	if got, ok := packPods(nil, capacity, 110); !ok || got != 1 {
+		t.Errorf("packPods() without pods = %d, %v, want 1, true", got, ok)
+	}
+}
+
+func TestHourlyPrice(t *testing.T) {
+	price, ok := hourlyPrice(machineFamily("n2-standard-8"), 8, 32768)
+	if !ok || price < 0.388 || price > 0.389 {
+		t.Errorf("hourlyPrice(n2-standard-8) = %f, %v, want ~0.388", price, ok)
+	}
+	if _, ok := hourlyPrice(machineFamily("a3-highgpu-8g"), 208, 1916928); ok {
+		t.Error("hourlyPrice(a3-highgpu-8g) succeeded, want no price")
+	}
+	if got := formatMonthlyDelta(-0.5); got != "-$365" {
+		t.Errorf("formatMonthlyDelta(-0.5) = %s, want -$365", got)
+	}
+}
@@ -0,0 +1,363 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// RolloutWatchToolDescription contains the documentation for the Rollout Watch Kubernetes tool.
+// It is formatted in Markdown.
+const RolloutWatchToolDescription = `
+This tool follows the rollout of a Deployment or StatefulSet until it completes, fails or times out. This is the equivalent of running *kubectl rollout status --watch*.
+
+While the rollout progresses, the tool sends progress notifications with the number of updated and ready replicas and the pods that are not ready, if the client requested them.
+
+## Arguments
+
+* *kind*: The kind of workload: *deployment* or *statefulset*.
+* *name*: The name of the workload.
+* *namespace*: (Optional) The namespace of the workload. Defaults to the server's default namespace.
+* *timeout_seconds*: (Optional) How long to follow the rollout, at most 600 seconds. Defaults to 300 seconds. The watch also ends before the tool call times out.
+
+## Response Format
+
+The final status of the rollout, one of:
+
+* **complete**: all replicas run the new revision and are ready.
+* **failed**: the Deployment exceeded its progress deadline (*ProgressDeadlineExceeded*).
+* **timed out**: the rollout was still in progress when the watch ended.
+
+It is followed by the progress observed during the watch and, unless the rollout is complete, the pods that are not ready with the reason and their most recent events.
+
+Example:
+{
+  "kind": "deployment",
+  "name": "web",
+  "namespace": "default"
+}
+`
+
+type rolloutWatchArgs struct {
+	Kind           string `json:"kind"`
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+}
+
+const (
+	defaultRolloutTimeout = 5 * time.Minute
+	maxRolloutTimeout     = 10 * time.Minute
+	rolloutPollInterval   = 2 * time.Second
+	// maxPodEvents is the number of most recent events shown per pod.
+	maxPodEvents = 5
+)
+
+// rolloutState is the state of a rollout at a point in time.
+type rolloutState struct {
+	desired, updated, ready int32
+	done, failed            bool
+	message                 string
+}
+
+func (s rolloutState) String() string {
+	return fmt.Sprintf("%d/%d updated, %d/%d ready: %s", s.updated, s.desired, s.ready, s.desired, s.message)
+}
+
+func deploymentRolloutState(d *appsv1.Deployment) rolloutState {
+	desired := int32(1)
+	if d.Spec.Replicas != nil {
+		desired = *d.Spec.Replicas
+	}
+	s := rolloutState{desired: desired, updated: d.Status.UpdatedReplicas, ready: d.Status.ReadyReplicas}
+	if d.Status.ObservedGeneration < d.Generation {
+		s.message = "waiting for the deployment spec update to be observed"
+		return s
+	}
+	for _, c := range d.Status.Conditions {
+		if c.Type == appsv1.DeploymentProgressing && c.Reason == "ProgressDeadlineExceeded" {
+			s.failed = true
+			s.message = fmt.Sprintf("deployment %q exceeded its progress deadline", d.Name)
+			return s
+		}
+	}
+	switch {
+	case d.Status.UpdatedReplicas < desired:
+		s.message = fmt.Sprintf("%d out of %d new replicas have been updated", d.Status.UpdatedReplicas, desired)
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		s.message = fmt.Sprintf("%d old replicas are pending termination", d.Status.Replicas-d.Status.UpdatedReplicas)
+	case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+		s.message = fmt.Sprintf("%d of %d updated replicas are available", d.Status.AvailableReplicas, d.Status.UpdatedReplicas)
+	default:
+		s.done = true
+		s.message = fmt.Sprintf("deployment %q successfully rolled out", d.Name)
+	}
+	return s
+}
+
+func statefulSetRolloutState(sts *appsv1.StatefulSet) rolloutState {
+	desired := int32(1)
+	if sts.Spec.Replicas != nil {
+		desired = *sts.Spec.Replicas
+	}
+	s := rolloutState{desired: desired, updated: sts.Status.UpdatedReplicas, ready: sts.Status.ReadyReplicas}
+	if sts.Status.ObservedGeneration < sts.Generation {
+		s.message = "waiting for the statefulset spec update to be observed"
+		return s
+	}
+	var partition int32
+	if ru := sts.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+		partition = *ru.Partition
+	}
+	switch {
+	case sts.Status.ReadyReplicas < desired:
+		s.message = fmt.Sprintf("%d of %d pods are ready", sts.Status.ReadyReplicas, desired)
+	case partition > 0 && sts.Status.UpdatedReplicas < desired-partition:
+		s.message = fmt.Sprintf("%d of %d pods above partition %d have been updated", sts.Status.UpdatedReplicas, desired-partition, partition)
+	case partition == 0 && sts.Status.UpdateRevision != sts.Status.CurrentRevision:
+		s.message = fmt.Sprintf("waiting for pods to be updated to revision %s", sts.Status.UpdateRevision)
+	default:
+		s.done = true
+		s.message = fmt.Sprintf("statefulset %q successfully rolled out", sts.Name)
+	}
+	return s
+}
+
+func (h *handlers) rolloutWatch(ctx context.Context, req *mcp.CallToolRequest, args *rolloutWatchArgs) (*mcp.CallToolResult, any, error) {
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+
+	var get func(ctx context.Context) (rolloutState, *metav1.LabelSelector, error)
+	switch strings.ToLower(args.Kind) {
+	case "deployment", "deployments", "deploy":
+		get = func(ctx context.Context) (rolloutState, *metav1.LabelSelector, error) {
+			d, err := h.clientset.AppsV1().Deployments(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+			if err != nil {
+				return rolloutState{}, nil, fmt.Errorf("failed to get deployment: %w", err)
+			}
+			return deploymentRolloutState(d), d.Spec.Selector, nil
+		}
+	case "statefulset", "statefulsets", "sts":
+		get = func(ctx context.Context) (rolloutState, *metav1.LabelSelector, error) {
+			sts, err := h.clientset.AppsV1().StatefulSets(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+			if err != nil {
+				return rolloutState{}, nil, fmt.Errorf("failed to get statefulset: %w", err)
+			}
+			return statefulSetRolloutState(sts), sts.Spec.Selector, nil
+		}
+	default:
+		return nil, nil, fmt.Errorf("rollout watch is not supported for kind %q, use deployment or statefulset", args.Kind)
+	}
+
+	timeout := defaultRolloutTimeout
+	if args.TimeoutSeconds > 0 {
+		timeout = min(time.Duration(args.TimeoutSeconds)*time.Second, maxRolloutTimeout)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = min(timeout, max(time.Until(deadline)-watchDeadlineMargin, 0))
+	}
+	watchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	token := req.Params.GetProgressToken()
+	var (
+		history  []string
+		last     string
+		state    rolloutState
+		selector *metav1.LabelSelector
+	)
+	ticker := time.NewTicker(rolloutPollInterval)
+	defer ticker.Stop()
+poll:
+	for {
+		var err error
+		state, selector, err = get(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if line := state.String(); line != last {
+			last = line
+			history = append(history, time.Now().UTC().Format(time.RFC3339)+" "+line)
+			if token != nil {
+				if !state.done {
+					if pods, err := h.unreadyPods(ctx, namespace, selector); err == nil && len(pods) > 0 {
+						line += "; not ready: " + strings.Join(pods, ", ")
+					}
+				}
+				// Progress notifications are best effort.
+				_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+					ProgressToken: token,
+					Progress:      float64(len(history)),
+					Message:       line,
+				})
+			}
+		}
+		if state.done || state.failed {
+			break
+		}
+		select {
+		case <-watchCtx.Done():
+			break poll
+		case <-ticker.C:
+		}
+	}
+
+	var output strings.Builder
+	switch {
+	case state.done:
+		output.WriteString("Rollout complete: " + state.message + "\n")
+	case state.failed:
+		output.WriteString("Rollout failed: " + state.message + "\n")
+	default:
+		output.WriteString(fmt.Sprintf("Rollout timed out after %s: %s\n", timeout.Round(time.Second), state.message))
+	}
+	output.WriteString("\nProgress:\n")
+	for _, line := range history {
+		output.WriteString("  " + line + "\n")
+	}
+	if !state.done {
+		if err := h.writeUnreadyPods(ctx, &output, namespace, selector); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+// listSelectedPods lists the pods in namespace matching selector.
+func (h *handlers) listSelectedPods(ctx context.Context, namespace string, selector *metav1.LabelSelector) ([]corev1.Pod, error) {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse selector: %w", err)
+	}
+	pods, err := h.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: s.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	return pods.Items, nil
+}
+
+// unreadyPods returns "name (reason)" for each selected pod that is not
+// ready.
+func (h *handlers) unreadyPods(ctx context.Context, namespace string, selector *metav1.LabelSelector) ([]string, error) {
+	pods, err := h.listSelectedPods(ctx, namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+	var unready []string
+	for i := range pods {
+		if reason := podNotReadyReason(&pods[i]); reason != "" {
+			unready = append(unready, fmt.Sprintf("%s (%s)", pods[i].Name, reason))
+		}
+	}
+	return unready, nil
+}
+
+// writeUnreadyPods writes the selected pods that are not ready, with their
+// most recent events.
+func (h *handlers) writeUnreadyPods(ctx context.Context, out *strings.Builder, namespace string, selector *metav1.LabelSelector) error {
+	pods, err := h.listSelectedPods(ctx, namespace, selector)
+	if err != nil {
+		return err
+	}
+	var header bool
+	for i := range pods {
+		pod := &pods[i]
+		reason := podNotReadyReason(pod)
+		if reason == "" {
+			continue
+		}
+		if !header {
+			out.WriteString("\nPods not ready:\n")
+			header = true
+		}
+		out.WriteString(fmt.Sprintf("  %s: %s\n", pod.Name, reason))
+		events, err := h.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("involvedObject.uid", string(pod.UID)).String(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list events for pod %q: %w", pod.Name, err)
+		}
+		items := events.Items
+		sort.Slice(items, func(i, j int) bool { return eventTime(&items[i]).Before(eventTime(&items[j])) })
+		if len(items) > maxPodEvents {
+			items = items[len(items)-maxPodEvents:]
+		}
+		for _, e := range items {
+			out.WriteString(fmt.Sprintf("    %s %s %s: %s\n", eventTime(&e).UTC().Format(time.RFC3339), e.Type, e.Reason, e.Message))
+		}
+	}
+	return nil
+}
+
+// eventTime returns the time an event last occurred.
+func eventTime(e *corev1.Event) time.Time {
+	switch {
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// podNotReadyReason explains why pod is not ready, or returns the empty
+// string if it is ready.
+func podNotReadyReason(pod *corev1.Pod) string {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+			return ""
+		}
+	}
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	for _, cs := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		switch {
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason != "":
+			return cs.State.Waiting.Reason
+		case cs.State.Terminated != nil && cs.State.Terminated.Reason != "" && cs.State.Terminated.ExitCode != 0:
+			return cs.State.Terminated.Reason
+		}
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+			return "Unschedulable: " + c.Message
+		}
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return string(pod.Status.Phase)
+	}
+	return "NotReady"
+}
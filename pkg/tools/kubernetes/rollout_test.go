@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDeploymentRolloutState(t *testing.T) {
+	newDeployment := func(status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+			Status:     status,
+		}
+	}
+	for _, tc := range []struct {
+		name               string
+		status             appsv1.DeploymentStatus
+		wantDone, wantFail bool
+	}{
+		{
+			name:   "not observed",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3},
+		},
+		{
+			name:   "updating",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 2, AvailableReplicas: 3},
+		},
+		{
+			name:   "old replicas terminating",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 3, AvailableReplicas: 3},
+		},
+		{
+			name:     "complete",
+			status:   appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, ReadyReplicas: 3, AvailableReplicas: 3},
+			wantDone: true,
+		},
+		{
+			name: "progress deadline exceeded",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 1, Conditions: []appsv1.DeploymentCondition{{
+				Type:   appsv1.DeploymentProgressing,
+				Status: corev1.ConditionFalse,
+				Reason: "ProgressDeadlineExceeded",
+			}}},
+			wantFail: true,
+		},
+	} {
+		got := deploymentRolloutState(newDeployment(tc.status))
+		if got.done != tc.wantDone || got.failed != tc.wantFail {
+			t.Errorf("%s: deploymentRolloutState() = %+v, want done %v, failed %v", tc.name, got, tc.wantDone, tc.wantFail)
+		}
+	}
+}
+
+func TestStatefulSetRolloutState(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To[int32](2)},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 1,
+			ReadyReplicas:      2,
+			UpdatedReplicas:    1,
+			CurrentRevision:    "db-1",
+			UpdateRevision:     "db-2",
+		},
+	}
+	if got := statefulSetRolloutState(sts); got.done {
+		t.Errorf("statefulSetRolloutState() with pending revision = %+v, want not done", got)
+	}
+	sts.Status.UpdatedReplicas = 2
+	sts.Status.CurrentRevision = "db-2"
+	if got := statefulSetRolloutState(sts); !got.done {
+		t.Errorf("statefulSetRolloutState() after update = %+v, want done", got)
+	}
+}
+
+func TestPodNotReadyReason(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status corev1.PodStatus
+		want   string
+	}{
+		{
+			name: "ready",
+			status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{
+				{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+			}},
+			want: "",
+		},
+		{
+			name: "crash looping",
+			status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}}},
+			want: "CrashLoopBackOff",
+		},
+		{
+			name: "unschedulable",
+			status: corev1.PodStatus{Phase: corev1.PodPending, Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Message: "0/3 nodes are available"},
+			}},
+			want: "Unschedulable: 0/3 nodes are available",
+		},
+	} {
+		if got := podNotReadyReason(&corev1.Pod{Status: tc.status}); got != tc.want {
+			t.Errorf("%s: podNotReadyReason() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"sigs.k8s.io/yaml"
+)
+
+// GKERunSavedQueryToolDescription contains the documentation for the GKE Run Saved Query tool.
+// It is formatted in Markdown.
+const GKERunSavedQueryToolDescription = `
+This tool runs a saved Cloud Logging query, i.e. a named and parameterized query of the query library, and returns the log entries like the gke_read_logs tool. Prefer a saved query over writing a filter for gke_read_logs when one matches the question: saved queries are the canonical queries of the team operating the cluster.
+
+Besides the built-in queries, queries are loaded from the YAML files given with the server's *--log-queries* flag.
+
+## Arguments
+
+* *name*: The name of the saved query.
+* *parameters*: (Optional) The values of the parameters of the query, e.g. *{"cluster_name": "prod", "namespace": "web"}*. Parameters that are not given take their default value.
+* *since*: (Optional) How far back to search, as a duration such as *2h*. Defaults to the window of the query.
+* *limit*: (Optional) The maximum number of log entries to return. Defaults to 10.
+* *format*: (Optional) A Go template to format each log entry, as in gke_read_logs.
+* *verbose*: (Optional) Set to *true* to return each log entry as JSON, rather than in the compact format of gke_read_logs.
+
+## Response Format
+
+The Cloud Logging filter that was run, followed by the log entries, oldest first.
+`
+
+type gkeRunSavedQueryArgs struct {
+	Name       string            `json:"name"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Since      string            `json:"since,omitempty"`
+	Limit      int               `json:"limit,omitempty"`
+	Format     string            `json:"format,omitempty"`
+	Verbose    bool              `json:"verbose,omitempty"`
+}
+
+// savedQuery is a named Cloud Logging query. Its query is a Go template
+// executed with the values of its parameters.
+type savedQuery struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Query       string                `json:"query"`
+	Since       string                `json:"since,omitempty"`
+	Parameters  []savedQueryParameter `json:"parameters,omitempty"`
+
+	tmpl *template.Template
+}
+
+type savedQueryParameter struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// savedQueryFile is the format of the files of saved queries.
+type savedQueryFile struct {
+	Queries []*savedQuery `json:"queries"`
+}
+
+// builtinSavedQueries are the saved queries available without configuration.
+const builtinSavedQueries = `
+queries:
+- name: oom_kills
+  description: Containers killed by the kernel because they ran out of memory.
+  since: 24h
+  parameters:
+  - name: cluster_name
+    description: The name of the GKE cluster.
+    required: true
+  query: >-
+    resource.type="k8s_node" AND resource.labels.cluster_name={{quote .cluster_name}}
+    AND (jsonPayload.MESSAGE:"Memory cgroup out of memory" OR jsonPayload.MESSAGE:"oom-kill")
+- name: container_errors
+  description: Error logs of the containers of a namespace.
+  since: 1h
+  parameters:
+  - name: cluster_name
+    description: The name of the GKE cluster.
+    required: true
+  - name: namespace
+    description: The namespace of the containers.
+    default: default
+  query: >-
+    resource.type="k8s_container" AND resource.labels.cluster_name={{quote .cluster_name}}
+    AND resource.labels.namespace_name={{quote .namespace}} AND severity>=ERROR
+- name: resource_deletions
+  description: Kubernetes resources deleted through the API server, from the audit logs.
+  since: 24h
+  parameters:
+  - name: cluster_name
+    description: The name of the GKE cluster.
+    required: true
+  query: >-
+    resource.type="k8s_cluster" AND resource.labels.cluster_name={{quote .cluster_name}}
+    AND log_id("cloudaudit.googleapis.com/activity") AND protoPayload.methodName:"delete"
+`
+
+// parseSavedQueries parses and validates the saved queries of a file.
+func parseSavedQueries(data []byte) ([]*savedQuery, error) {
+	var file savedQueryFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, err
+	}
+	for _, q := range file.Queries {
+		if q.Name == "" || q.Query == "" {
+			return nil, fmt.Errorf("saved queries must have a name and a query")
+		}
+		tmpl, err := template.New(q.Name).
+			Funcs(template.FuncMap{"quote": strconv.Quote}).
+			Option("missingkey=error").
+			Parse(q.Query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query %q: %w", q.Name, err)
+		}
+		q.tmpl = tmpl
+	}
+	return file.Queries, nil
+}
+
+// loadSavedQueries returns the built-in saved queries and those of the YAML
+// file at path, or of the YAML files of the directory at path. Queries of
+// path override built-in queries of the same name.
+func loadSavedQueries(path string) ([]*savedQuery, error) {
+	queries, err := parseSavedQueries([]byte(builtinSavedQueries))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse built-in saved queries: %w", err)
+	}
+	byName := map[string]*savedQuery{}
+	for _, q := range queries {
+		byName[q.Name] = q
+	}
+
+	var files []string
+	if path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = []string{path}
+		} else {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, e := range entries {
+				if ext := filepath.Ext(e.Name()); e.Type().IsRegular() && (ext == ".yaml" || ext == ".yml") {
+					files = append(files, filepath.Join(path, e.Name()))
+				}
+			}
+		}
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parseSavedQueries(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse saved queries of %s: %w", f, err)
+		}
+		for _, q := range parsed {
+			byName[q.Name] = q
+		}
+	}
+
+	queries = make([]*savedQuery, 0, len(byName))
+	for _, q := range byName {
+		queries = append(queries, q)
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Name < queries[j].Name })
+	return queries, nil
+}
+
+// savedQueriesCatalog describes queries in Markdown, to be appended to the
+// description of the tool running them.
+func savedQueriesCatalog(queries []*savedQuery) string {
+	var out strings.Builder
+	out.WriteString("\n## Saved Queries\n\n")
+	for _, q := range queries {
+		out.WriteString(fmt.Sprintf("* *%s*: %s", q.Name, q.Description))
+		if q.Since != "" {
+			out.WriteString(fmt.Sprintf(" Defaults to the last %s.", q.Since))
+		}
+		out.WriteString("\n")
+		for _, p := range q.Parameters {
+			out.WriteString(fmt.Sprintf("  * *%s*: ", p.Name))
+			switch {
+			case p.Required:
+				out.WriteString("(Required)")
+			case p.Default != "":
+				out.WriteString(fmt.Sprintf("(Defaults to *%s*)", p.Default))
+			default:
+				out.WriteString("(Optional)")
+			}
+			if p.Description != "" {
+				out.WriteString(" " + p.Description)
+			}
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
+
+// render returns the filter of q with the given parameter values.
+func (q *savedQuery) render(values map[string]string) (string, error) {
+	data := map[string]string{}
+	for _, p := range q.Parameters {
+		v, ok := values[p.Name]
+		if !ok {
+			if p.Required {
+				return "", fmt.Errorf("parameter %q of saved query %q is required", p.Name, q.Name)
+			}
+			v = p.Default
+		}
+		data[p.Name] = v
+	}
+	for name := range values {
+		if _, ok := data[name]; !ok {
+			return "", fmt.Errorf("saved query %q has no parameter %q", q.Name, name)
+		}
+	}
+	var out strings.Builder
+	if err := q.tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render saved query %q: %w", q.Name, err)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+func (h *handlers) gkeRunSavedQuery(ctx context.Context, req *mcp.CallToolRequest, args *gkeRunSavedQueryArgs) (*mcp.CallToolResult, any, error) {
+	var query *savedQuery
+	names := make([]string, 0, len(h.savedQueries))
+	for _, q := range h.savedQueries {
+		if q.Name == args.Name {
+			query = q
+		}
+		names = append(names, q.Name)
+	}
+	if query == nil {
+		return nil, nil, fmt.Errorf("unknown saved query %q: must be one of %s", args.Name, strings.Join(names, ", "))
+	}
+	filter, err := query.render(args.Parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+	since := args.Since
+	if since == "" {
+		since = query.Since
+	}
+
+	result, _, err := h.queryLogs(ctx, req, &queryLogsArgs{
+		Query:   filter,
+		Format:  args.Format,
+		Verbose: args.Verbose,
+		Limit:   args.Limit,
+		Since:   since,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if text == "" {
+		text = "No log entries found.\n"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Filter: %s\n\n%s", filter, text)},
+		},
+	}, nil, nil
+}
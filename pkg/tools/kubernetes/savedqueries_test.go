@@ -0,0 +1,98 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadSavedQueries(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"team.yaml": `
+queries:
+- name: oom_kills
+  description: OOM kills of the team's node pool.
+  parameters:
+  - name: node_pool
+    required: true
+  query: resource.type="k8s_node" AND resource.labels.node_pool={{quote .node_pool}}
+- name: crash_loops
+  description: Crash loops.
+  parameters:
+  - name: namespace
+    default: default
+  query: resource.labels.namespace_name={{quote .namespace}} AND jsonPayload.reason="BackOff"
+`,
+		"README.md": "not a query file",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	queries, err := loadSavedQueries(dir)
+	if err != nil {
+		t.Fatalf("loadSavedQueries() error = %v", err)
+	}
+	var names []string
+	byName := map[string]*savedQuery{}
+	for _, q := range queries {
+		names = append(names, q.Name)
+		byName[q.Name] = q
+	}
+	if diff := cmp.Diff([]string{"container_errors", "crash_loops", "oom_kills", "resource_deletions"}, names); diff != "" {
+		t.Errorf("loadSavedQueries() names mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		values  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "oom_kills", values: map[string]string{"node_pool": `pool "a"`}, want: `resource.type="k8s_node" AND resource.labels.node_pool="pool \"a\""`},
+		{name: "oom_kills", wantErr: true},
+		{name: "crash_loops", want: `resource.labels.namespace_name="default" AND jsonPayload.reason="BackOff"`},
+		{name: "crash_loops", values: map[string]string{"cluster": "prod"}, wantErr: true},
+		{name: "container_errors", values: map[string]string{"cluster_name": "prod", "namespace": "web"}, want: `resource.type="k8s_container" AND resource.labels.cluster_name="prod" AND resource.labels.namespace_name="web" AND severity>=ERROR`},
+	} {
+		got, err := byName[tc.name].render(tc.values)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("render(%s, %v) error = %v, wantErr %t", tc.name, tc.values, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("render(%s, %v) = %q, want %q", tc.name, tc.values, got, tc.want)
+		}
+	}
+
+	catalog := savedQueriesCatalog(queries)
+	if want := "* *crash_loops*: Crash loops.\n  * *namespace*: (Defaults to *default*)\n"; !strings.Contains(catalog, want) {
+		t.Errorf("savedQueriesCatalog() = %q, want it to contain %q", catalog, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "bad.yml"), []byte("queries:\n- name: broken\n  query: '{{.x'\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSavedQueries(dir); err == nil {
+		t.Errorf("loadSavedQueries() with an invalid template succeeded, want error")
+	}
+}
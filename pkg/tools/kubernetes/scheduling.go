@@ -0,0 +1,432 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// SimulateSchedulingToolDescription contains the documentation for the Kubernetes Simulate Scheduling tool.
+// It is formatted in Markdown.
+const SimulateSchedulingToolDescription = `
+This tool estimates how many replicas of a hypothetical pod fit on the current nodes, and what blocks the rest, without creating anything. Use it to answer "can I deploy this without adding nodes?" before the cluster autoscaler, or Pending pods, answer it.
+
+For each node, the tool checks the scheduling constraints of the pod: cordoned and not ready nodes, node selectors, required node affinity and taints, including the node selector, tolerations and overhead of its RuntimeClass. Pods using a RuntimeClass that doesn't exist, e.g. *gvisor* without a GKE Sandbox node pool, are rejected before scheduling: the tool reports it instead of the fit. On the nodes accepting the pod, it counts how many replicas fit in the allocatable resources left by the running pods, for each resource the pod requests, including extended resources such as GPUs, and in the number of pods allowed per node. Pods using host ports, or with a required anti-affinity to themselves on *kubernetes.io/hostname*, fit at most once per node.
+
+The estimate ignores topology spread constraints, inter-pod affinity other than the above, volumes and preemption: the actual scheduling may differ. It also ignores the nodes the cluster autoscaler could add.
+
+## Arguments
+
+* *pod_spec*: (Optional) The pod to simulate, as YAML or JSON: a pod spec, a Pod manifest or the manifest of a workload with a pod template, e.g. a Deployment.
+* *cpu*: (Optional) The CPU request of the pod, e.g. *500m*. Overrides the requests of *pod_spec*.
+* *memory*: (Optional) The memory request of the pod, e.g. *1Gi*. Overrides the requests of *pod_spec*.
+* *count*: (Optional) The number of replicas. Defaults to 1.
+
+Either *pod_spec*, or *cpu* or *memory*, is required.
+
+## Response Format
+
+The requests of the pod, the verdict, the fit of each node and the reasons blocking the replicas that don't fit:
+
+Pod requests: cpu=500m, memory=1Gi
+10 replicas: 6 fit on the current nodes, 4 would stay Pending.
+
+NODE                            FITS  FREE                      LIMITED_BY
+gke-prod-default-pool-1a2b3c4d  4     cpu=2200m, memory=5Gi     cpu
+gke-prod-default-pool-5e6f7a8b  2     cpu=1100m, memory=2100Mi  cpu
+gke-prod-gpu-pool-9c0d1e2f      0     -                         taint nvidia.com/gpu=present:NoSchedule not tolerated
+
+Blocked: 2 nodes full: cpu; 1 node: taint nvidia.com/gpu=present:NoSchedule not tolerated.
+`
+
+type simulateSchedulingArgs struct {
+	PodSpec string `json:"pod_spec,omitempty"`
+	CPU     string `json:"cpu,omitempty"`
+	Memory  string `json:"memory,omitempty"`
+	Count   int    `json:"count,omitempty"`
+}
+
+// gvisorRuntimeClass is the RuntimeClass of the pods running in GKE Sandbox.
+const gvisorRuntimeClass = "gvisor"
+
+// simulatedPod is the pod simulated by kube_simulate_scheduling.
+type simulatedPod struct {
+	labels   map[string]string
+	spec     corev1.PodSpec
+	requests corev1.ResourceList
+	// oncePerNode is set for pods that can't share a node with another
+	// replica.
+	oncePerNode bool
+	// runtimeNodeSelector is the node selector of the RuntimeClass of the
+	// pod.
+	runtimeNodeSelector map[string]string
+}
+
+// nodeFit is the number of replicas of a simulated pod fitting on a node.
+type nodeFit struct {
+	node     string
+	replicas int
+	free     corev1.ResourceList
+	// reason is why the node rejects the pod, or which resource limits the
+	// number of replicas.
+	reason string
+}
+
+// parsePodSpec returns the labels and the pod spec of manifest: a pod spec,
+// a Pod or an object with a pod template.
+func parsePodSpec(manifest string) (map[string]string, corev1.PodSpec, error) {
+	var spec corev1.PodSpec
+	var obj map[string]any
+	if err := yaml.Unmarshal([]byte(manifest), &obj); err != nil {
+		return nil, spec, fmt.Errorf("failed to parse pod_spec: %w", err)
+	}
+	podLabels := map[string]string{}
+	if _, ok := obj["kind"]; ok {
+		u := &unstructured.Unstructured{Object: obj}
+		if template, ok, _ := unstructured.NestedMap(obj, "spec", "template"); ok {
+			u = &unstructured.Unstructured{Object: template}
+		}
+		podLabels = u.GetLabels()
+		s, ok, _ := unstructured.NestedMap(u.Object, "spec")
+		if !ok {
+			return nil, spec, fmt.Errorf("%s has no pod spec", obj["kind"])
+		}
+		obj = s
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &spec); err != nil {
+		return nil, spec, fmt.Errorf("failed to parse pod_spec: %w", err)
+	}
+	if len(spec.Containers) == 0 {
+		return nil, spec, fmt.Errorf("pod_spec has no containers")
+	}
+	return podLabels, spec, nil
+}
+
+// newSimulatedPod returns the pod simulated for args.
+func newSimulatedPod(args *simulateSchedulingArgs) (*simulatedPod, error) {
+	pod := &simulatedPod{}
+	if args.PodSpec != "" {
+		var err error
+		if pod.labels, pod.spec, err = parsePodSpec(args.PodSpec); err != nil {
+			return nil, err
+		}
+		pod.requests, _ = podRequestsAndLimits(&corev1.Pod{Spec: pod.spec})
+	} else if args.CPU == "" && args.Memory == "" {
+		return nil, fmt.Errorf("either pod_spec, or cpu and memory, are required")
+	} else {
+		pod.requests = corev1.ResourceList{}
+	}
+	for name, value := range map[corev1.ResourceName]string{corev1.ResourceCPU: args.CPU, corev1.ResourceMemory: args.Memory} {
+		if value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", name, value, err)
+		}
+		pod.requests[name] = q
+	}
+
+	for _, c := range pod.spec.Containers {
+		for _, p := range c.Ports {
+			if p.HostPort != 0 {
+				pod.oncePerNode = true
+			}
+		}
+	}
+	if a := pod.spec.Affinity; a != nil && a.PodAntiAffinity != nil {
+		for _, term := range a.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			if term.TopologyKey != corev1.LabelHostname {
+				continue
+			}
+			selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+			if err == nil && !selector.Empty() && selector.Matches(labels.Set(pod.labels)) {
+				pod.oncePerNode = true
+			}
+		}
+	}
+	return pod, nil
+}
+
+// setRuntimeClass adds the scheduling constraints and the overhead of the
+// RuntimeClass rc of pod to pod, like the RuntimeClass admission controller.
+func (pod *simulatedPod) setRuntimeClass(rc *nodev1.RuntimeClass) {
+	if rc.Scheduling != nil {
+		pod.runtimeNodeSelector = rc.Scheduling.NodeSelector
+		pod.spec.Tolerations = append(pod.spec.Tolerations, rc.Scheduling.Tolerations...)
+	}
+	if rc.Overhead != nil {
+		addResources(pod.requests, rc.Overhead.PodFixed)
+	}
+}
+
+// missingRuntimeClass explains why the pods using the RuntimeClass name,
+// which doesn't exist, are never scheduled.
+func missingRuntimeClass(name string) string {
+	text := fmt.Sprintf("RuntimeClass %s doesn't exist: the API server rejects the pods using it, which are never created nor scheduled.\n", name)
+	if name == gvisorRuntimeClass {
+		text += "GKE creates it with the first GKE Sandbox node pool: create one with gke_create_node_pool and enable_sandbox.\n"
+	}
+	return text
+}
+
+// nodeRejection returns why node doesn't accept pod regardless of its
+// resources, or "" if it does.
+func nodeRejection(node *corev1.Node, pod *simulatedPod) string {
+	if node.Spec.Unschedulable {
+		return "cordoned"
+	}
+	if !isNodeReady(node) {
+		return "not ready"
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range pod.spec.Tolerations {
+			if pod.spec.Tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return fmt.Sprintf("taint %s not tolerated", taint.ToString())
+		}
+	}
+	if !labels.SelectorFromSet(pod.runtimeNodeSelector).Matches(labels.Set(node.Labels)) {
+		return fmt.Sprintf("runtime class %s: node selector %s not matched", *pod.spec.RuntimeClassName, labels.SelectorFromSet(pod.runtimeNodeSelector))
+	}
+	if !labels.SelectorFromSet(pod.spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return fmt.Sprintf("node selector %s not matched", labels.SelectorFromSet(pod.spec.NodeSelector))
+	}
+	if a := pod.spec.Affinity; a != nil && a.NodeAffinity != nil && a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		if !matchesNodeSelectorTerms(a.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, node) {
+			return "required node affinity not matched"
+		}
+	}
+	return ""
+}
+
+// fitOnNode returns how many replicas of pod fit on node, running the pods
+// running.
+func fitOnNode(node *corev1.Node, pod *simulatedPod, running []*corev1.Pod) nodeFit {
+	fit := nodeFit{node: node.Name}
+	if fit.reason = nodeRejection(node, pod); fit.reason != "" {
+		return fit
+	}
+	free := node.Status.Allocatable.DeepCopy()
+	freePods := free.Pods().Value()
+	for _, p := range running {
+		r, _ := podRequestsAndLimits(p)
+		subtractResources(free, r)
+		freePods--
+	}
+	delete(free, corev1.ResourcePods)
+	fit.free = free
+
+	fit.replicas, fit.reason = int(max(freePods, 0)), "pods per node"
+	if pod.oncePerNode && fit.replicas > 1 {
+		fit.replicas, fit.reason = 1, "one replica per node"
+	}
+	names := make([]string, 0, len(pod.requests))
+	for name := range pod.requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		request := pod.requests[corev1.ResourceName(name)]
+		if request.IsZero() {
+			continue
+		}
+		available := free[corev1.ResourceName(name)]
+		n := 0
+		if available.Sign() > 0 {
+			n = int(available.MilliValue() / request.MilliValue())
+		}
+		if n < fit.replicas {
+			fit.replicas, fit.reason = n, name
+		}
+	}
+	return fit
+}
+
+// formatResources formats the CPU, memory and requested resources of list.
+func formatResources(list corev1.ResourceList, requested corev1.ResourceList) string {
+	var parts []string
+	names := []string{string(corev1.ResourceCPU), string(corev1.ResourceMemory)}
+	var extra []string
+	for name := range requested {
+		if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+			extra = append(extra, string(name))
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range append(names, extra...) {
+		if q, ok := list[corev1.ResourceName(name)]; ok {
+			parts = append(parts, name+"="+q.String())
+		}
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// schedulingBlockers summarizes why the nodes of fits can't run more
+// replicas, like the FailedScheduling events: the number of nodes per
+// reason.
+func schedulingBlockers(fits []nodeFit) string {
+	counts := map[string]int{}
+	for _, f := range fits {
+		if f.free == nil {
+			counts[f.reason]++
+		} else {
+			counts["full: "+f.reason]++
+		}
+	}
+	reasons := make([]string, 0, len(counts))
+	for r := range counts {
+		reasons = append(reasons, r)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if counts[reasons[i]] != counts[reasons[j]] {
+			return counts[reasons[i]] > counts[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	for i, r := range reasons {
+		noun := "nodes"
+		if counts[r] == 1 {
+			noun = "node"
+		}
+		if rest, ok := strings.CutPrefix(r, "full: "); ok {
+			reasons[i] = fmt.Sprintf("%d %s full: %s", counts[r], noun, rest)
+		} else {
+			reasons[i] = fmt.Sprintf("%d %s: %s", counts[r], noun, r)
+		}
+	}
+	return strings.Join(reasons, "; ")
+}
+
+func (h *handlers) simulateScheduling(ctx context.Context, _ *mcp.CallToolRequest, args *simulateSchedulingArgs) (*mcp.CallToolResult, any, error) {
+	pod, err := newSimulatedPod(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	count := 1
+	if args.Count > 0 {
+		count = args.Count
+	}
+	if name := pod.spec.RuntimeClassName; name != nil && *name != "" {
+		rc, err := h.clientset.NodeV1().RuntimeClasses().Get(ctx, *name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: missingRuntimeClass(*name)},
+				},
+			}, nil, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get runtime class: %w", err)
+		}
+		pod.setRuntimeClass(rc)
+	}
+
+	nodes, err := h.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := h.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	running := map[string][]*corev1.Pod{}
+	pending := 0
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if p.Spec.NodeName == "" {
+			pending++
+			continue
+		}
+		running[p.Spec.NodeName] = append(running[p.Spec.NodeName], p)
+	}
+
+	fits := make([]nodeFit, 0, len(nodes.Items))
+	total := 0
+	for i := range nodes.Items {
+		fit := fitOnNode(&nodes.Items[i], pod, running[nodes.Items[i].Name])
+		total += fit.replicas
+		fits = append(fits, fit)
+	}
+	sort.SliceStable(fits, func(i, j int) bool { return fits[i].replicas > fits[j].replicas })
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Pod requests: %s\n", formatResources(pod.requests, pod.requests)))
+	if total >= count {
+		output.WriteString(fmt.Sprintf("%d replicas: all fit on the current nodes, which have room for %d.\n", count, total))
+	} else {
+		output.WriteString(fmt.Sprintf("%d replicas: %d fit on the current nodes, %d would stay Pending.\n", count, total, count-total))
+	}
+	if pending > 0 {
+		output.WriteString(fmt.Sprintf("%d pods are already Pending and compete for the same capacity.\n", pending))
+	}
+	if len(pod.requests) == 0 {
+		output.WriteString("The pod requests no resources: it fits wherever the node accepts it, and the scheduler can overcommit the node.\n")
+	}
+
+	output.WriteString("\n")
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tFITS\tFREE\tLIMITED_BY")
+	for _, f := range fits {
+		free := "-"
+		if f.free != nil {
+			free = formatResources(f.free, pod.requests)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", f.node, f.replicas, free, f.reason)
+	}
+	w.Flush()
+
+	if total < count {
+		if blockers := schedulingBlockers(fits); blockers != "" {
+			output.WriteString("\nBlocked: " + blockers + ".\n")
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func newSchedulingNode(name, cpu, memory string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"kubernetes.io/hostname": name}},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func newRunningPod(name, node, cpu string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{
+				Name:      "main",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestParsePodSpec(t *testing.T) {
+	for _, manifest := range []string{
+		`
+containers:
+- name: web
+  image: nginx
+  resources:
+    requests:
+      cpu: 250m
+`,
+		`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx
+        resources:
+          requests:
+            cpu: 250m
+`,
+	} {
+		_, spec, err := parsePodSpec(manifest)
+		if err != nil {
+			t.Fatalf("parsePodSpec() failed: %v", err)
+		}
+		if got := spec.Containers[0].Resources.Requests.Cpu().String(); got != "250m" {
+			t.Errorf("parsePodSpec() cpu request = %s, want 250m", got)
+		}
+	}
+	if _, _, err := parsePodSpec("kind: ConfigMap\ndata: {}"); err == nil {
+		t.Error("parsePodSpec() of a ConfigMap succeeded, want error")
+	}
+}
+
+func TestFitOnNode(t *testing.T) {
+	node := newSchedulingNode("n1", "4", "8Gi")
+	running := []*corev1.Pod{newRunningPod("a", "n1", "1500m")}
+
+	pod, err := newSimulatedPod(&simulateSchedulingArgs{CPU: "500m", Memory: "1Gi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fit := fitOnNode(node, pod, running); fit.replicas != 5 || fit.reason != "cpu" {
+		t.Errorf("fitOnNode() = %d limited by %q, want 5 limited by cpu", fit.replicas, fit.reason)
+	}
+
+	pod, err = newSimulatedPod(&simulateSchedulingArgs{PodSpec: `
+apiVersion: v1
+kind: Pod
+metadata:
+  labels:
+    app: web
+spec:
+  affinity:
+    podAntiAffinity:
+      requiredDuringSchedulingIgnoredDuringExecution:
+      - topologyKey: kubernetes.io/hostname
+        labelSelector:
+          matchLabels:
+            app: web
+  containers:
+  - name: web
+    image: nginx
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fit := fitOnNode(node, pod, running); fit.replicas != 1 || fit.reason != "one replica per node" {
+		t.Errorf("fitOnNode() = %d limited by %q, want 1 limited by one replica per node", fit.replicas, fit.reason)
+	}
+
+	tainted := newSchedulingNode("gpu", "4", "8Gi", corev1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: corev1.TaintEffectNoSchedule})
+	if fit := fitOnNode(tainted, pod, nil); fit.replicas != 0 || fit.reason != "taint nvidia.com/gpu=present:NoSchedule not tolerated" {
+		t.Errorf("fitOnNode() = %d because %q, want 0 because of the taint", fit.replicas, fit.reason)
+	}
+}
+
+func TestSimulateScheduling(t *testing.T) {
+	h := &handlers{
+		c: &config.Config{},
+		clientset: fake.NewClientset(
+			newSchedulingNode("n1", "4", "8Gi"),
+			newSchedulingNode("n2", "2", "8Gi"),
+			newSchedulingNode("gpu", "8", "32Gi", corev1.Taint{Key: "nvidia.com/gpu", Value: "present", Effect: corev1.TaintEffectNoSchedule}),
+			newRunningPod("a", "n1", "1"),
+		),
+	}
+	result, _, err := h.simulateScheduling(context.Background(), nil, &simulateSchedulingArgs{CPU: "1", Count: 8})
+	if err != nil {
+		t.Fatalf("simulateScheduling() failed: %v", err)
+	}
+	got := result.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{
+		"Pod requests: cpu=1\n",
+		"8 replicas: 5 fit on the current nodes, 3 would stay Pending.",
+		"Blocked: 2 nodes full: cpu; 1 node: taint nvidia.com/gpu=present:NoSchedule not tolerated.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("simulateScheduling() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestSimulateSchedulingRuntimeClass(t *testing.T) {
+	sandbox := newSchedulingNode("sandbox", "4", "8Gi", corev1.Taint{Key: "sandbox.gke.io/runtime", Value: "gvisor", Effect: corev1.TaintEffectNoSchedule})
+	sandbox.Labels["sandbox.gke.io/runtime"] = "gvisor"
+	gvisor := &nodev1.RuntimeClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gvisor"},
+		Handler:    "gvisor",
+		Overhead:   &nodev1.Overhead{PodFixed: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")}},
+		Scheduling: &nodev1.Scheduling{
+			NodeSelector: map[string]string{"sandbox.gke.io/runtime": "gvisor"},
+			Tolerations:  []corev1.Toleration{{Key: "sandbox.gke.io/runtime", Operator: corev1.TolerationOpEqual, Value: "gvisor", Effect: corev1.TaintEffectNoSchedule}},
+		},
+	}
+	podSpec := "runtimeClassName: gvisor\ncontainers:\n- name: main\n  image: nginx\n  resources:\n    requests:\n      cpu: 750m\n"
+
+	for _, tc := range []struct {
+		name    string
+		objects []runtime.Object
+		want    []string
+	}{
+		{
+			name:    "sandbox node pool",
+			objects: []runtime.Object{newSchedulingNode("n1", "4", "8Gi"), sandbox, gvisor},
+			want: []string{
+				"Pod requests: cpu=1\n",
+				"sandbox  4",
+				"runtime class gvisor: node selector sandbox.gke.io/runtime=gvisor not matched",
+			},
+		},
+		{
+			name:    "no runtime class",
+			objects: []runtime.Object{newSchedulingNode("n1", "4", "8Gi")},
+			want:    []string{"RuntimeClass gvisor doesn't exist", "enable_sandbox"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &handlers{c: &config.Config{}, clientset: fake.NewClientset(tc.objects...)}
+			result, _, err := h.simulateScheduling(context.Background(), nil, &simulateSchedulingArgs{PodSpec: podSpec})
+			if err != nil {
+				t.Fatalf("simulateScheduling() failed: %v", err)
+			}
+			got := result.Content[0].(*mcp.TextContent).Text
+			for _, want := range tc.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("simulateScheduling() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/rest"
+)
+
+// ScrapeMetricsToolDescription contains the documentation for the Scrape Metrics Kubernetes tool.
+// It is formatted in Markdown.
+const ScrapeMetricsToolDescription = `
+This tool fetches Prometheus metrics from a component of the cluster, through the API server. Many controller problems, e.g. work queue backlogs, throttled clients or failing reconciles, are only visible in their metrics.
+
+The following targets are supported:
+
+* *apiserver*: the API server's */metrics* endpoint.
+* *node*: the kubelet of a node, through the *nodes/proxy* subresource. Use the *path* argument to fetch e.g. *metrics/cadvisor* or *metrics/resource*.
+* *pod*: a pod serving metrics, through the *pods/proxy* subresource. The pod must serve plain HTTP on the given port.
+
+Without *prefixes*, the tool returns the list of metric families with their type and number of samples, which is a good first step to discover the available metrics. With *prefixes*, it returns the samples of the metric families whose name starts with one of the prefixes.
+
+## Arguments
+
+* *target*: One of *apiserver*, *node* or *pod*.
+* *name*: The name of the node or pod. Required for the *node* and *pod* targets.
+* *namespace*: (Optional) The namespace of the pod. Defaults to the server's default namespace.
+* *port*: (Optional) The port the pod serves metrics on, e.g. *8080*. Defaults to the pod's default port.
+* *path*: (Optional) The path to fetch. Defaults to *metrics*.
+* *prefixes*: (Optional) Only return the metric families whose name starts with one of these prefixes, e.g. *["workqueue_", "rest_client_requests_total"]*.
+`
+
+type scrapeMetricsArgs struct {
+	Target    string   `json:"target"`
+	Name      string   `json:"name,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
+	Port      string   `json:"port,omitempty"`
+	Path      string   `json:"path,omitempty"`
+	Prefixes  []string `json:"prefixes,omitempty"`
+}
+
+// maxMetricLines is the maximum number of lines returned by the tool.
+const maxMetricLines = 2000
+
+// metricFamily summarizes a metric family of a Prometheus text exposition.
+type metricFamily struct {
+	name, metricType string
+	samples          int
+}
+
+// metricName returns the name of the metric family line belongs to, and
+// whether line is a sample rather than a HELP or TYPE comment. It returns the
+// empty string for other comments and blank lines.
+func metricName(line string) (name string, sample bool) {
+	if rest, ok := strings.CutPrefix(line, "# HELP "); ok {
+		name, _, _ = strings.Cut(rest, " ")
+		return name, false
+	}
+	if rest, ok := strings.CutPrefix(line, "# TYPE "); ok {
+		name, _, _ = strings.Cut(rest, " ")
+		return name, false
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+	if i := strings.IndexAny(line, "{ "); i >= 0 {
+		return line[:i], true
+	}
+	return line, true
+}
+
+// filterMetrics returns the lines of the Prometheus text exposition body
+// about metrics whose name starts with one of prefixes.
+func filterMetrics(body string, prefixes []string) []string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		name, _ := metricName(line)
+		if name == "" {
+			continue
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(name, p) {
+				lines = append(lines, line)
+				break
+			}
+		}
+	}
+	return lines
+}
+
+// summarizeMetrics returns the metric families of the Prometheus text
+// exposition body, sorted by name. Samples of histograms and summaries,
+// e.g. "_bucket" and "_sum", are counted in their family.
+func summarizeMetrics(body string) []metricFamily {
+	families := map[string]*metricFamily{}
+	var current *metricFamily
+	for _, line := range strings.Split(body, "\n") {
+		name, sample := metricName(line)
+		if name == "" {
+			continue
+		}
+		if !sample {
+			if current == nil || current.name != name {
+				current = &metricFamily{name: name, metricType: "untyped"}
+				families[name] = current
+			}
+			if rest, ok := strings.CutPrefix(line, "# TYPE "+name+" "); ok {
+				current.metricType = strings.TrimSpace(rest)
+			}
+			continue
+		}
+		if current == nil || !strings.HasPrefix(name, current.name) {
+			current = families[name]
+			if current == nil {
+				current = &metricFamily{name: name, metricType: "untyped"}
+				families[name] = current
+			}
+		}
+		current.samples++
+	}
+
+	summary := make([]metricFamily, 0, len(families))
+	for _, f := range families {
+		summary = append(summary, *f)
+	}
+	sort.Slice(summary, func(i, j int) bool { return summary[i].name < summary[j].name })
+	return summary
+}
+
+func (h *handlers) scrapeMetrics(ctx context.Context, _ *mcp.CallToolRequest, args *scrapeMetricsArgs) (*mcp.CallToolResult, any, error) {
+	path := strings.TrimPrefix(args.Path, "/")
+	if path == "" {
+		path = "metrics"
+	}
+
+	var request *rest.Request
+	switch args.Target {
+	case "apiserver":
+		request = h.dc.RESTClient().Get().AbsPath("/" + path)
+	case "node":
+		if args.Name == "" {
+			return nil, nil, fmt.Errorf("name is required for the node target")
+		}
+		request = h.clientset.CoreV1().RESTClient().Get().Resource("nodes").Name(args.Name).SubResource("proxy").Suffix(path)
+	case "pod":
+		if args.Name == "" {
+			return nil, nil, fmt.Errorf("name is required for the pod target")
+		}
+		namespace := args.Namespace
+		if namespace == "" {
+			namespace = h.defaultNamespace
+		}
+		name := args.Name
+		if args.Port != "" {
+			name += ":" + args.Port
+		}
+		request = h.clientset.CoreV1().RESTClient().Get().Namespace(namespace).Resource("pods").Name(name).SubResource("proxy").Suffix(path)
+	default:
+		return nil, nil, fmt.Errorf("invalid target %q: must be one of apiserver, node or pod", args.Target)
+	}
+	body, err := request.DoRaw(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch metrics: %w", err)
+	}
+
+	var lines []string
+	if len(args.Prefixes) == 0 {
+		families := summarizeMetrics(string(body))
+		lines = append(lines, fmt.Sprintf("%d metric families. Call the tool again with prefixes to get their samples.", len(families)), "NAME\tTYPE\tSAMPLES")
+		for _, f := range families {
+			lines = append(lines, fmt.Sprintf("%s\t%s\t%d", f.name, f.metricType, f.samples))
+		}
+	} else {
+		lines = filterMetrics(string(body), args.Prefixes)
+		if len(lines) == 0 {
+			lines = append(lines, fmt.Sprintf("No metrics match the prefixes %s.", strings.Join(args.Prefixes, ", ")))
+		}
+	}
+	if len(lines) > maxMetricLines {
+		truncated := len(lines) - maxMetricLines
+		lines = append(lines[:maxMetricLines], fmt.Sprintf("... %d more lines truncated, use more specific prefixes.", truncated))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(lines, "\n")},
+		},
+	}, nil, nil
+}
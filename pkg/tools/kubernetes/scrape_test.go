@@ -0,0 +1,61 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testMetrics = `# HELP apiserver_request_total Counter of apiserver requests.
+# TYPE apiserver_request_total counter
+apiserver_request_total{code="200",verb="GET"} 10
+apiserver_request_total{code="500",verb="GET"} 2
+# HELP workqueue_depth Current depth of workqueue.
+# TYPE workqueue_depth gauge
+workqueue_depth{name="deployment"} 3
+# HELP rest_client_request_duration_seconds Request latency.
+# TYPE rest_client_request_duration_seconds histogram
+rest_client_request_duration_seconds_bucket{le="0.1"} 5
+rest_client_request_duration_seconds_bucket{le="+Inf"} 6
+rest_client_request_duration_seconds_sum 0.4
+rest_client_request_duration_seconds_count 6
+process_start_time_seconds 1.7e+09
+`
+
+func TestSummarizeMetrics(t *testing.T) {
+	want := []metricFamily{
+		{name: "apiserver_request_total", metricType: "counter", samples: 2},
+		{name: "process_start_time_seconds", metricType: "untyped", samples: 1},
+		{name: "rest_client_request_duration_seconds", metricType: "histogram", samples: 4},
+		{name: "workqueue_depth", metricType: "gauge", samples: 1},
+	}
+	if diff := cmp.Diff(want, summarizeMetrics(testMetrics), cmp.AllowUnexported(metricFamily{})); diff != "" {
+		t.Errorf("summarizeMetrics() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFilterMetrics(t *testing.T) {
+	want := []string{
+		"# HELP workqueue_depth Current depth of workqueue.",
+		"# TYPE workqueue_depth gauge",
+		`workqueue_depth{name="deployment"} 3`,
+		"process_start_time_seconds 1.7e+09",
+	}
+	if diff := cmp.Diff(want, filterMetrics(testMetrics, []string{"workqueue_", "process_"})); diff != "" {
+		t.Errorf("filterMetrics() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecurityAuditToolDescription contains the documentation for the Security Audit Kubernetes tool.
+// It is formatted in Markdown.
+const SecurityAuditToolDescription = `
+This tool audits the security posture of the workloads running in the cluster. It scans the specs of running pods and scores each workload against the [Pod Security Standards](https://kubernetes.io/docs/concepts/security/pod-security-standards/) levels:
+
+* **privileged**: the workload violates the *baseline* level, e.g. it runs privileged containers, uses *hostPath* volumes, *hostNetwork*, *hostPID*, *hostIPC* or host ports, or adds capabilities beyond the baseline set.
+* **baseline**: the workload meets the *baseline* level but not the *restricted* level, e.g. it may run as root, allows privilege escalation, does not drop *ALL* capabilities, or has no *RuntimeDefault* seccomp profile.
+* **restricted**: the workload meets the *restricted* level.
+
+It also reports the following best-practice findings, which don't affect the level: containers without a *securityContext*, images using the *:latest* tag or no tag, and containers without CPU or memory limits.
+
+Pods of the same workload (e.g. the same ReplicaSet) are reported once.
+
+## Arguments
+
+* *namespace*: (Optional) The namespace to audit. Defaults to the server's default namespace.
+* *all_namespaces*: (Optional) Set to *true* to audit all namespaces.
+
+## Response Format
+
+A summary of the number of workloads per level, followed by a table with one row per workload that has findings:
+
+NAMESPACE  WORKLOAD             PODS  LEVEL       FINDINGS
+default    ReplicaSet/web-7d9f  3     baseline    [restricted] container "web": allowPrivilegeEscalation is not false; [best-practice] container "web": image "nginx:latest" uses the latest tag
+`
+
+type securityAuditArgs struct {
+	Namespace     string `json:"namespace,omitempty"`
+	AllNamespaces bool   `json:"all_namespaces,omitempty"`
+}
+
+// Pod Security Standards levels, and the category of findings outside of
+// them.
+const (
+	levelPrivileged   = "privileged"
+	levelBaseline     = "baseline"
+	levelRestricted   = "restricted"
+	levelBestPractice = "best-practice"
+)
+
+// baselineCapabilities are the capabilities the baseline level allows
+// containers to add.
+var baselineCapabilities = map[corev1.Capability]bool{
+	"AUDIT_WRITE": true, "CHOWN": true, "DAC_OVERRIDE": true, "FOWNER": true, "FSETID": true, "KILL": true, "MKNOD": true,
+	"NET_BIND_SERVICE": true, "SETFCAP": true, "SETGID": true, "SETPCAP": true, "SETUID": true, "SYS_CHROOT": true,
+}
+
+// securityFinding is a violation of the level named by level.
+type securityFinding struct {
+	level   string
+	message string
+}
+
+func (f securityFinding) String() string {
+	return fmt.Sprintf("[%s] %s", f.level, f.message)
+}
+
+// auditPodSpec returns the security findings of spec.
+func auditPodSpec(spec *corev1.PodSpec) []securityFinding {
+	var findings []securityFinding
+	add := func(level, format string, a ...any) {
+		findings = append(findings, securityFinding{level: level, message: fmt.Sprintf(format, a...)})
+	}
+
+	if spec.HostNetwork {
+		add(levelBaseline, "pod uses hostNetwork")
+	}
+	if spec.HostPID {
+		add(levelBaseline, "pod uses hostPID")
+	}
+	if spec.HostIPC {
+		add(levelBaseline, "pod uses hostIPC")
+	}
+	for _, v := range spec.Volumes {
+		if v.HostPath != nil {
+			add(levelBaseline, "volume %q mounts host path %s", v.Name, v.HostPath.Path)
+		}
+	}
+
+	podSC := spec.SecurityContext
+	if podSC == nil {
+		podSC = &corev1.PodSecurityContext{}
+	}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		addContainer := func(level, format string, a ...any) {
+			add(level, "container %q: "+format, append([]any{c.Name}, a...)...)
+		}
+		sc := c.SecurityContext
+		if sc == nil {
+			addContainer(levelBestPractice, "no securityContext")
+			sc = &corev1.SecurityContext{}
+		}
+
+		// Baseline.
+		if sc.Privileged != nil && *sc.Privileged {
+			addContainer(levelBaseline, "runs privileged")
+		}
+		for _, p := range c.Ports {
+			if p.HostPort != 0 {
+				addContainer(levelBaseline, "uses host port %d", p.HostPort)
+			}
+		}
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				if !baselineCapabilities[capability] {
+					addContainer(levelBaseline, "adds capability %s", capability)
+				}
+			}
+		}
+
+		// Restricted.
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			addContainer(levelRestricted, "allowPrivilegeEscalation is not false")
+		}
+		runAsNonRoot := podSC.RunAsNonRoot
+		if sc.RunAsNonRoot != nil {
+			runAsNonRoot = sc.RunAsNonRoot
+		}
+		if runAsNonRoot == nil || !*runAsNonRoot {
+			addContainer(levelRestricted, "runAsNonRoot is not true")
+		}
+		if sc.Capabilities == nil || !containsCapability(sc.Capabilities.Drop, "ALL") {
+			addContainer(levelRestricted, "does not drop ALL capabilities")
+		}
+		seccomp := podSC.SeccompProfile
+		if sc.SeccompProfile != nil {
+			seccomp = sc.SeccompProfile
+		}
+		if seccomp == nil || (seccomp.Type != corev1.SeccompProfileTypeRuntimeDefault && seccomp.Type != corev1.SeccompProfileTypeLocalhost) {
+			addContainer(levelRestricted, "seccompProfile is not RuntimeDefault or Localhost")
+		}
+
+		// Best practices.
+		if tag := imageTag(c.Image); tag == "" || tag == "latest" {
+			addContainer(levelBestPractice, "image %q uses the latest tag", c.Image)
+		}
+		var missing []string
+		for _, r := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, ok := c.Resources.Limits[r]; !ok {
+				missing = append(missing, string(r))
+			}
+		}
+		if len(missing) > 0 {
+			addContainer(levelBestPractice, "no %s limits", strings.Join(missing, " or "))
+		}
+	}
+	return findings
+}
+
+func containsCapability(caps []corev1.Capability, capability corev1.Capability) bool {
+	for _, c := range caps {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// imageTag returns the tag of image, or the empty string if it has none.
+// Images pinned by digest are reported as tagged with the digest.
+func imageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+	// The tag follows the last colon, unless the colon is part of a
+	// registry host:port.
+	if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image[i:], "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// securityLevel returns the highest Pod Security Standards level met by a
+// pod with findings.
+func securityLevel(findings []securityFinding) string {
+	level := levelRestricted
+	for _, f := range findings {
+		switch f.level {
+		case levelBaseline:
+			return levelPrivileged
+		case levelRestricted:
+			level = levelBaseline
+		}
+	}
+	return level
+}
+
+func (h *handlers) securityAudit(ctx context.Context, _ *mcp.CallToolRequest, args *securityAuditArgs) (*mcp.CallToolResult, any, error) {
+	namespace, err := h.namespaceScope(args.Namespace, args.AllNamespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	pods, err := h.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	type workload struct {
+		namespace, name string
+		pods            int
+		findings        []securityFinding
+	}
+	workloads := map[string]*workload{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		name := "Pod/" + pod.Name
+		if owner := metav1.GetControllerOf(pod); owner != nil {
+			name = owner.Kind + "/" + owner.Name
+		}
+		key := pod.Namespace + "/" + name
+		if w, ok := workloads[key]; ok {
+			w.pods++
+			continue
+		}
+		workloads[key] = &workload{namespace: pod.Namespace, name: name, pods: 1, findings: auditPodSpec(&pod.Spec)}
+	}
+
+	keys := make([]string, 0, len(workloads))
+	for k := range workloads {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	counts := map[string]int{}
+	var table strings.Builder
+	table.WriteString("NAMESPACE\tWORKLOAD\tPODS\tLEVEL\tFINDINGS\n")
+	for _, k := range keys {
+		w := workloads[k]
+		level := securityLevel(w.findings)
+		counts[level]++
+		if len(w.findings) == 0 {
+			continue
+		}
+		findings := make([]string, len(w.findings))
+		for i, f := range w.findings {
+			findings[i] = f.String()
+		}
+		table.WriteString(fmt.Sprintf("%s\t%s\t%d\t%s\t%s\n", w.namespace, w.name, w.pods, level, strings.Join(findings, "; ")))
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Audited %d workloads in %s: %d privileged, %d baseline, %d restricted.\n\n",
+		len(workloads), describeScope(namespace), counts[levelPrivileged], counts[levelBaseline], counts[levelRestricted]))
+	output.WriteString(table.String())
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
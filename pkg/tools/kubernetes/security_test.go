@@ -0,0 +1,106 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func TestAuditPodSpec(t *testing.T) {
+	limits := corev1.ResourceRequirements{Limits: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	}}
+	restricted := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: ptr.To(false),
+		RunAsNonRoot:             ptr.To(true),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	for _, tc := range []struct {
+		name      string
+		spec      corev1.PodSpec
+		wantLevel string
+		want      []string
+	}{
+		{
+			name: "restricted",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", Image: "registry.example.com:5000/app:1.2.3", SecurityContext: restricted, Resources: limits},
+			}},
+			wantLevel: levelRestricted,
+		},
+		{
+			name: "baseline",
+			spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "app", Image: "nginx", Resources: limits},
+			}},
+			wantLevel: levelBaseline,
+			want: []string{
+				`[best-practice] container "app": no securityContext`,
+				`[restricted] container "app": allowPrivilegeEscalation is not false`,
+				`[restricted] container "app": runAsNonRoot is not true`,
+				`[restricted] container "app": does not drop ALL capabilities`,
+				`[restricted] container "app": seccompProfile is not RuntimeDefault or Localhost`,
+				`[best-practice] container "app": image "nginx" uses the latest tag`,
+			},
+		},
+		{
+			name: "privileged",
+			spec: corev1.PodSpec{
+				HostNetwork: true,
+				Volumes: []corev1.Volume{
+					{Name: "root", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/"}}},
+				},
+				Containers: []corev1.Container{{
+					Name:  "agent",
+					Image: "agent@sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31",
+					SecurityContext: &corev1.SecurityContext{
+						Privileged:               ptr.To(true),
+						AllowPrivilegeEscalation: ptr.To(false),
+						RunAsNonRoot:             ptr.To(true),
+						Capabilities:             &corev1.Capabilities{Add: []corev1.Capability{"SYS_ADMIN"}, Drop: []corev1.Capability{"ALL"}},
+						SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					},
+				}},
+			},
+			wantLevel: levelPrivileged,
+			want: []string{
+				`[baseline] pod uses hostNetwork`,
+				`[baseline] volume "root" mounts host path /`,
+				`[baseline] container "agent": runs privileged`,
+				`[baseline] container "agent": adds capability SYS_ADMIN`,
+				`[best-practice] container "agent": no cpu or memory limits`,
+			},
+		},
+	} {
+		findings := auditPodSpec(&tc.spec)
+		var got []string
+		for _, f := range findings {
+			got = append(got, f.String())
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("%s: auditPodSpec() mismatch (-want +got):\n%s", tc.name, diff)
+		}
+		if level := securityLevel(findings); level != tc.wantLevel {
+			t.Errorf("%s: securityLevel() = %q, want %q", tc.name, level, tc.wantLevel)
+		}
+	}
+}
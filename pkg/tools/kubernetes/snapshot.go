@@ -0,0 +1,433 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/cache"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/output"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+)
+
+// SnapshotToolDescription contains the documentation for the Kubernetes Snapshot tool.
+// It is formatted in Markdown.
+const SnapshotToolDescription = `
+This tool exports a sanitized snapshot of the cluster state for offline analysis, e.g. as a support bundle attached to an escalation, like a focused *kubectl cluster-info dump*. The snapshot is a gzipped tarball of YAML files:
+
+* *cluster/version.yaml*: the version of the API server.
+* *cluster/nodes.yaml*: the nodes, with their status, conditions and capacity.
+* *NAMESPACE/RESOURCE.yaml*: the resources of each namespace, and *cluster/RESOURCE.yaml* for cluster-scoped resources.
+* *NAMESPACE/events.yaml*: the events of each namespace, oldest first.
+* *summary.txt*: the number of objects in each file, and the resources that couldn't be exported.
+
+The snapshot is always sanitized, whatever the secret redaction policy of the server: the values of Secrets, and of environment variables with sensitive names, are replaced with *[REDACTED]*, and managed fields are removed. Secrets are not exported unless requested.
+
+The same snapshot can be taken from the command line with *kubeapi-mcp snapshot*.
+
+## Arguments
+
+* *namespaces*: (Optional) The namespaces to export. Defaults to the default namespace.
+* *all_namespaces*: (Optional) Set to *true* to export all namespaces.
+* *resources*: (Optional) The types of resources to export, e.g. *["deployments", "services"]*. Defaults to the common workload, networking, configuration and storage resources.
+* *destination*: (Optional) Where to write the snapshot: a new file, as a path relative to the output directory of the server, or a Cloud Storage object as *gs://BUCKET/OBJECT* in a bucket allowed by the server. Existing files are never overwritten. Defaults to a new file in the output directory. Not available in read-only mode.
+
+## Response Format
+
+The destination of the snapshot and its summary:
+
+Snapshot written to /tmp/kubeapi-mcp/kube-snapshot-20250301-101500.tar.gz (48213 bytes).
+
+FILE                          OBJECTS
+cluster/version.yaml          1
+cluster/nodes.yaml            3
+default/pods.yaml             12
+default/events.yaml           57
+`
+
+type snapshotArgs struct {
+	Namespaces    []string `json:"namespaces,omitempty"`
+	AllNamespaces bool     `json:"all_namespaces,omitempty"`
+	Resources     []string `json:"resources,omitempty"`
+	Destination   string   `json:"destination,omitempty"`
+}
+
+// defaultSnapshotResources are the resources exported when none are given.
+var defaultSnapshotResources = []string{
+	"pods", "deployments", "replicasets", "statefulsets", "daemonsets", "jobs", "cronjobs",
+	"services", "endpointslices", "ingresses", "networkpolicies",
+	"configmaps", "serviceaccounts", "horizontalpodautoscalers", "poddisruptionbudgets",
+	"persistentvolumeclaims", "persistentvolumes", "storageclasses",
+}
+
+// snapshotVersionFile is the file of a snapshot holding the version of the
+// API server, the only file that isn't made of manifests.
+const snapshotVersionFile = "cluster/version.yaml"
+
+// snapshotFile is a file of a snapshot.
+type snapshotFile struct {
+	name    string
+	objects int
+}
+
+// snapshotArchive writes the files of a snapshot to a gzipped tarball.
+type snapshotArchive struct {
+	gz    *gzip.Writer
+	tw    *tar.Writer
+	now   time.Time
+	files []snapshotFile
+}
+
+func newSnapshotArchive(w io.Writer, now time.Time) *snapshotArchive {
+	gz := gzip.NewWriter(w)
+	return &snapshotArchive{gz: gz, tw: tar.NewWriter(gz), now: now}
+}
+
+// add adds the file name, holding objects objects, to the archive.
+func (a *snapshotArchive) add(name string, data []byte, objects int) error {
+	if err := a.tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: a.now}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := a.tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	a.files = append(a.files, snapshotFile{name: name, objects: objects})
+	return nil
+}
+
+// addObjects adds the file name holding objs, sanitized, as YAML documents.
+func (a *snapshotArchive) addObjects(name string, objs []unstructured.Unstructured) error {
+	var out strings.Builder
+	for i := range objs {
+		obj := sanitizeForSnapshot(&objs[i])
+		if err := writeYAMLDocument(&out, obj); err != nil {
+			return err
+		}
+	}
+	return a.add(name, []byte(out.String()), len(objs))
+}
+
+func (a *snapshotArchive) close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
+
+// sanitizeForSnapshot returns a copy of obj with its secret values masked
+// and without managed fields.
+func sanitizeForSnapshot(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	obj = redactSecrets(obj)
+	obj.SetManagedFields(nil)
+	return obj
+}
+
+// typedToUnstructured converts the typed objects of a list to unstructured
+// objects of kind.
+func typedToUnstructured[T any](items []T, apiVersion, kind string) ([]unstructured.Unstructured, error) {
+	objs := make([]unstructured.Unstructured, 0, len(items))
+	for i := range items {
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&items[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", kind, err)
+		}
+		obj := unstructured.Unstructured{Object: m}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// snapshotFileName returns the name of the file of a snapshot taken at now.
+func snapshotFileName(now time.Time) string {
+	return fmt.Sprintf("kube-snapshot-%s.tar.gz", now.UTC().Format("20060102-150405"))
+}
+
+// writeSnapshot writes the snapshot of args to archive, and returns the
+// resources that couldn't be exported.
+func (h *handlers) writeSnapshot(ctx context.Context, archive *snapshotArchive, args *snapshotArgs) ([]string, error) {
+	var namespaces []string
+	switch {
+	case args.AllNamespaces:
+		list, err := h.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		for _, ns := range list.Items {
+			namespaces = append(namespaces, ns.Name)
+		}
+	case len(args.Namespaces) > 0:
+		namespaces = args.Namespaces
+	default:
+		namespaces = []string{h.defaultNamespace}
+	}
+	resources := args.Resources
+	if len(resources) == 0 {
+		resources = defaultSnapshotResources
+	}
+
+	var problems []string
+	if version, err := h.clientset.Discovery().ServerVersion(); err == nil {
+		data := fmt.Sprintf("gitVersion: %s\nplatform: %s\nbuildDate: %s\n", version.GitVersion, version.Platform, version.BuildDate)
+		if err := archive.add(snapshotVersionFile, []byte(data), 1); err != nil {
+			return nil, err
+		}
+	} else {
+		problems = append(problems, fmt.Sprintf("version: %v", err))
+	}
+
+	nodes, err := h.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("nodes: %v", err))
+	} else {
+		objs, err := typedToUnstructured(nodes.Items, "v1", "Node")
+		if err != nil {
+			return nil, err
+		}
+		if err := archive.addObjects("cluster/nodes.yaml", objs); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, resource := range resources {
+		gvr, err := h.findGVR(resource)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", resource, err))
+			continue
+		}
+		namespaced, err := h.isNamespaced(gvr)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", resource, err))
+			continue
+		}
+		scopes := namespaces
+		if !namespaced {
+			scopes = []string{""}
+		}
+		for _, ns := range scopes {
+			var ri dynamic.ResourceInterface = h.dyn.Resource(gvr)
+			dir := "cluster"
+			if namespaced {
+				ri = h.dyn.Resource(gvr).Namespace(ns)
+				dir = ns
+			}
+			list, err := ri.List(ctx, metav1.ListOptions{})
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s/%s: %v", dir, gvr.Resource, err))
+				continue
+			}
+			if len(list.Items) == 0 {
+				continue
+			}
+			if err := archive.addObjects(path.Join(dir, gvr.Resource+".yaml"), list.Items); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, ns := range namespaces {
+		events, err := h.clientset.CoreV1().Events(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s/events: %v", ns, err))
+			continue
+		}
+		if len(events.Items) == 0 {
+			continue
+		}
+		sort.SliceStable(events.Items, func(i, j int) bool {
+			return eventTime(&events.Items[i]).Before(eventTime(&events.Items[j]))
+		})
+		objs, err := typedToUnstructured[corev1.Event](events.Items, "v1", "Event")
+		if err != nil {
+			return nil, err
+		}
+		if err := archive.addObjects(path.Join(ns, "events.yaml"), objs); err != nil {
+			return nil, err
+		}
+	}
+	return problems, nil
+}
+
+// snapshotSummary returns the summary of the files of a snapshot and of the
+// resources that couldn't be exported.
+func snapshotSummary(files []snapshotFile, problems []string) string {
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tOBJECTS")
+	for _, f := range files {
+		fmt.Fprintf(w, "%s\t%d\n", f.name, f.objects)
+	}
+	w.Flush()
+	if len(problems) > 0 {
+		out.WriteString("\nNot exported:\n")
+		for _, p := range problems {
+			out.WriteString("- " + p + "\n")
+		}
+	}
+	return out.String()
+}
+
+// snapshot takes the snapshot of args, and returns the gzipped tarball and
+// its summary.
+func (h *handlers) snapshot(ctx context.Context, args *snapshotArgs, now time.Time) ([]byte, string, error) {
+	var buf bytes.Buffer
+	archive := newSnapshotArchive(&buf, now)
+	problems, err := h.writeSnapshot(ctx, archive, args)
+	if err != nil {
+		return nil, "", err
+	}
+	// The summary is part of the snapshot, for whoever analyzes it.
+	summary := snapshotSummary(archive.files, problems)
+	if err := archive.add("summary.txt", []byte(summary), 0); err != nil {
+		return nil, "", err
+	}
+	if err := archive.close(); err != nil {
+		return nil, "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return buf.Bytes(), summary, nil
+}
+
+func (h *handlers) snapshotTool(ctx context.Context, _ *mcp.CallToolRequest, args *snapshotArgs) (*mcp.CallToolResult, any, error) {
+	now := time.Now()
+	destination := args.Destination
+	if destination == "" {
+		destination = snapshotFileName(now)
+	}
+	// Check the destination before taking a snapshot that couldn't be
+	// written.
+	if err := output.Check(h.c, destination); err != nil {
+		return nil, nil, err
+	}
+	data, summary, err := h.snapshot(ctx, args, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	written, err := output.Write(ctx, h.c, destination, data, "application/gzip")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Snapshot written to %s (%d bytes).\n\n%s", written, len(data), summary)},
+		},
+	}, nil, nil
+}
+
+// snapshotReadOnlyArgs are the arguments of the kube_snapshot tool in
+// read-only mode, where snapshots are only written to the default file.
+type snapshotReadOnlyArgs struct {
+	Namespaces    []string `json:"namespaces,omitempty"`
+	AllNamespaces bool     `json:"all_namespaces,omitempty"`
+	Resources     []string `json:"resources,omitempty"`
+}
+
+func (h *handlers) snapshotReadOnlyTool(ctx context.Context, req *mcp.CallToolRequest, args *snapshotReadOnlyArgs) (*mcp.CallToolResult, any, error) {
+	return h.snapshotTool(ctx, req, &snapshotArgs{
+		Namespaces:    args.Namespaces,
+		AllNamespaces: args.AllNamespaces,
+		Resources:     args.Resources,
+	})
+}
+
+// SnapshotOptions selects what the snapshot command exports, and where.
+type SnapshotOptions struct {
+	Namespaces    []string
+	AllNamespaces bool
+	Resources     []string
+	// Destination is a local path or a gs://BUCKET/OBJECT URL. Empty means
+	// a file in the temporary directory. Unlike the destination of the tool,
+	// it is chosen by the user running the command, so it isn't restricted
+	// to the output directory and allowed buckets.
+	Destination string
+}
+
+// Snapshot writes a snapshot of the cluster of the kubeconfig context of c,
+// like the kube_snapshot tool, and returns its summary.
+func Snapshot(ctx context.Context, c *config.Config, opts SnapshotOptions) (string, error) {
+	restConfig, err := newRESTConfig(c)
+	if err != nil {
+		return "", err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create clientset: %w", err)
+	}
+	dyn, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	h := &handlers{
+		c:                c,
+		dyn:              dyn,
+		mapper:           restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(dc)),
+		dc:               dc,
+		clientset:        clientset,
+		cache:            cache.New(c.CacheTTL()),
+		defaultNamespace: DefaultNamespace(c),
+		restConfig:       restConfig,
+	}
+	now := time.Now()
+	destination := opts.Destination
+	if destination == "" {
+		destination = filepath.Join(os.TempDir(), snapshotFileName(now))
+	}
+	if _, _, toGCS := output.ParseGCSURL(destination); strings.HasPrefix(destination, "gs://") && !toGCS {
+		return "", fmt.Errorf("invalid Cloud Storage destination %q, expected gs://BUCKET/OBJECT", destination)
+	}
+	data, summary, err := h.snapshot(ctx, &snapshotArgs{
+		Namespaces:    opts.Namespaces,
+		AllNamespaces: opts.AllNamespaces,
+		Resources:     opts.Resources,
+	}, now)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(destination, "gs://") {
+		err = output.Upload(ctx, c, destination, data, "application/gzip")
+	} else {
+		err = os.WriteFile(destination, data, 0o600)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return fmt.Sprintf("Snapshot written to %s (%d bytes).\n\n%s", destination, len(data), summary), nil
+}
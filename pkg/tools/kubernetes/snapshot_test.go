@@ -0,0 +1,109 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSnapshotArchive(t *testing.T) {
+	secret := decodeObject(t, `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: default
+  managedFields:
+  - manager: kubectl
+    operation: Apply
+data:
+  password: aHVudGVyMg==
+`)
+	var buf bytes.Buffer
+	archive := newSnapshotArchive(&buf, time.Date(2025, 3, 1, 10, 15, 0, 0, time.UTC))
+	if err := archive.addObjects("default/secrets.yaml", []unstructured.Unstructured{*secret}); err != nil {
+		t.Fatalf("addObjects() failed: %v", err)
+	}
+	if err := archive.add("summary.txt", []byte("summary\n"), 0); err != nil {
+		t.Fatalf("add() failed: %v", err)
+	}
+	if err := archive.close(); err != nil {
+		t.Fatalf("close() failed: %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() failed: %v", err)
+	}
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() failed: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("ReadAll() failed: %v", err)
+		}
+		files[header.Name] = string(data)
+	}
+
+	secrets := files["default/secrets.yaml"]
+	if strings.Contains(secrets, "aHVudGVyMg==") || !strings.Contains(secrets, redactedValue) {
+		t.Errorf("secret values aren't redacted:\n%s", secrets)
+	}
+	if strings.Contains(secrets, "managedFields") {
+		t.Errorf("managed fields aren't removed:\n%s", secrets)
+	}
+	if files["summary.txt"] != "summary\n" {
+		t.Errorf("summary.txt = %q, want %q", files["summary.txt"], "summary\n")
+	}
+	// The archived objects are sanitized copies.
+	if len(secret.GetManagedFields()) == 0 {
+		t.Errorf("addObjects() modified its objects")
+	}
+	want := []snapshotFile{{name: "default/secrets.yaml", objects: 1}, {name: "summary.txt"}}
+	if diff := cmp.Diff(want, archive.files, cmp.AllowUnexported(snapshotFile{})); diff != "" {
+		t.Errorf("files mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSnapshotSummary(t *testing.T) {
+	files := []snapshotFile{{name: "cluster/nodes.yaml", objects: 3}, {name: "default/pods.yaml", objects: 12}}
+	got := snapshotSummary(files, []string{"cluster/storageclasses: forbidden"})
+	want := `FILE                OBJECTS
+cluster/nodes.yaml  3
+default/pods.yaml   12
+
+Not exported:
+- cluster/storageclasses: forbidden
+`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("snapshotSummary() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/logging/logadmin"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/iterator"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GKESpotInterruptionsToolDescription contains the documentation for the GKE Spot Interruptions tool.
+// It is formatted in Markdown.
+const GKESpotInterruptionsToolDescription = `
+This tool reports the recent preemptions of the Spot and preemptible VMs of the cluster, the pods they terminated, and the current Spot capacity of each node pool. Compute Engine can reclaim Spot VMs at any time: the node is shut down, its pods are terminated and rescheduled, and the node is usually recreated with the same name a few minutes later. This churn is a frequent and confusing source of pod restarts.
+
+The report contains:
+
+* **Capacity**: the nodes of each node pool, how many are Spot or preemptible and ready, their allocatable CPU and memory, and the number of preemptions of the pool in the period.
+* **Preemptions**: the *compute.instances.preempted* system events of Compute Engine for the instances of the cluster's node pools, read from Cloud Logging.
+* **Terminated pods**: the pods still in the cluster that the kubelet terminated during a node shutdown, with the node they ran on. Pods of controllers are usually replaced, and their terminated pods garbage-collected, so this list is not exhaustive.
+
+Instances are matched to the cluster by the instance group prefix of its current nodes, so the preemptions of a node pool that was scaled to zero or deleted are not reported.
+
+## Arguments
+
+* *since*: (Optional) How far back to look for preemptions, as a duration such as *6h* or *168h*. Defaults to *24h*.
+* *limit*: (Optional) The maximum number of preemption log entries to read, most recent first. Defaults to 500.
+
+## Response Format
+
+Capacity:
+NODE_POOL	NODES	SPOT	READY	CPU	MEMORY	PREEMPTIONS
+spot-pool	4	4	3	15820m	53Gi	7
+
+Preemptions (7):
+TIME	NODE_POOL	ZONE	INSTANCE
+2025-01-01T10:00:02Z	spot-pool	us-central1-a	gke-prod-spot-pool-1234abcd-wxyz
+
+Terminated pods (2):
+NAMESPACE	POD	NODE	TERMINATED	REASON
+default	web-7d9c-abcde	gke-prod-spot-pool-1234abcd-wxyz	2025-01-01T10:00:31Z	Pod was terminated in response to imminent node shutdown.
+`
+
+type gkeSpotInterruptionsArgs struct {
+	Since string `json:"since,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+const (
+	defaultSpotWindow = 24 * time.Hour
+	defaultSpotLimit  = 500
+
+	nodePoolLabel    = "cloud.google.com/gke-nodepool"
+	spotLabel        = "cloud.google.com/gke-spot"
+	preemptibleLabel = "cloud.google.com/gke-preemptible"
+)
+
+// spotPreemption is the preemption of a Compute Engine instance.
+type spotPreemption struct {
+	time     time.Time
+	zone     string
+	instance string
+	nodePool string
+}
+
+// spotPoolCapacity is the capacity of a node pool.
+type spotPoolCapacity struct {
+	nodePool    string
+	nodes       int
+	spot        int
+	ready       int
+	cpu         resource.Quantity
+	memory      resource.Quantity
+	preemptions int
+}
+
+// spotPreemptionFilter returns the Cloud Logging filter of the preemptions of
+// Compute Engine instances since start.
+func spotPreemptionFilter(start time.Time) string {
+	return fmt.Sprintf(`resource.type="gce_instance" AND log_id("cloudaudit.googleapis.com/system_event") AND protoPayload.methodName="compute.instances.preempted" AND timestamp>=%q`,
+		start.UTC().Format(time.RFC3339))
+}
+
+// newSpotPreemption returns the preemption of the system event entry.
+func newSpotPreemption(entry *logging.Entry) (spotPreemption, bool) {
+	payload, ok := entry.Payload.(*audit.AuditLog)
+	if !ok || payload.GetResourceName() == "" {
+		return spotPreemption{}, false
+	}
+	// Resource names are of the form projects/P/zones/Z/instances/NAME.
+	p := spotPreemption{
+		time:     entry.Timestamp,
+		instance: path.Base(payload.GetResourceName()),
+		zone:     entry.Resource.GetLabels()["zone"],
+	}
+	if p.zone == "" {
+		if _, rest, ok := strings.Cut(payload.GetResourceName(), "/zones/"); ok {
+			p.zone, _, _ = strings.Cut(rest, "/")
+		}
+	}
+	return p, true
+}
+
+// instanceGroupPrefix returns the prefix of the names of the instances of the
+// instance group of a GKE node, e.g. gke-prod-pool-1234abcd for the node
+// gke-prod-pool-1234abcd-wxyz.
+func instanceGroupPrefix(node string) string {
+	if i := strings.LastIndex(node, "-"); i > 0 {
+		return node[:i+1]
+	}
+	return node
+}
+
+// isSpotNode returns whether node is a Spot or preemptible VM.
+func isSpotNode(node *corev1.Node) bool {
+	return node.Labels[spotLabel] == "true" || node.Labels[preemptibleLabel] == "true"
+}
+
+// nodePoolOf returns the node pool of node, or "(none)".
+func nodePoolOf(node *corev1.Node) string {
+	if pool := node.Labels[nodePoolLabel]; pool != "" {
+		return pool
+	}
+	return "(none)"
+}
+
+// matchSpotPreemptions keeps the preemptions of instances of the instance
+// groups of nodes, and sets their node pool.
+func matchSpotPreemptions(preemptions []spotPreemption, nodes []corev1.Node) []spotPreemption {
+	pools := map[string]string{}
+	for i := range nodes {
+		pools[instanceGroupPrefix(nodes[i].Name)] = nodePoolOf(&nodes[i])
+	}
+	var matched []spotPreemption
+	for _, p := range preemptions {
+		pool, ok := pools[instanceGroupPrefix(p.instance)]
+		if !ok {
+			continue
+		}
+		p.nodePool = pool
+		matched = append(matched, p)
+	}
+	return matched
+}
+
+// spotCapacity returns the capacity of the node pools of nodes, sorted by
+// name, with their number of preemptions.
+func spotCapacity(nodes []corev1.Node, preemptions []spotPreemption) []*spotPoolCapacity {
+	pools := map[string]*spotPoolCapacity{}
+	for i := range nodes {
+		node := &nodes[i]
+		name := nodePoolOf(node)
+		pool, ok := pools[name]
+		if !ok {
+			pool = &spotPoolCapacity{nodePool: name}
+			pools[name] = pool
+		}
+		pool.nodes++
+		if isSpotNode(node) {
+			pool.spot++
+		}
+		if isNodeReady(node) {
+			pool.ready++
+		}
+		pool.cpu.Add(node.Status.Allocatable[corev1.ResourceCPU])
+		pool.memory.Add(node.Status.Allocatable[corev1.ResourceMemory])
+	}
+	for _, p := range preemptions {
+		if pool, ok := pools[p.nodePool]; ok {
+			pool.preemptions++
+		}
+	}
+	capacity := make([]*spotPoolCapacity, 0, len(pools))
+	for _, pool := range pools {
+		capacity = append(capacity, pool)
+	}
+	sort.Slice(capacity, func(i, j int) bool { return capacity[i].nodePool < capacity[j].nodePool })
+	return capacity
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// shutdownTermination returns when and why pod was terminated by the kubelet
+// during a node shutdown.
+func shutdownTermination(pod *corev1.Pod) (time.Time, string, bool) {
+	var terminated time.Time
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.DisruptionTarget && c.Status == corev1.ConditionTrue && c.Reason == "TerminationByKubelet" {
+			terminated = c.LastTransitionTime.Time
+		}
+	}
+	switch pod.Status.Reason {
+	case "Terminated", "Shutdown", "NodeShutdown":
+	default:
+		if terminated.IsZero() {
+			return time.Time{}, "", false
+		}
+	}
+	if terminated.IsZero() {
+		for _, cs := range pod.Status.ContainerStatuses {
+			if t := cs.State.Terminated; t != nil && t.FinishedAt.After(terminated) {
+				terminated = t.FinishedAt.Time
+			}
+		}
+	}
+	reason := pod.Status.Message
+	if reason == "" {
+		reason = pod.Status.Reason
+	}
+	return terminated, reason, true
+}
+
+func (h *handlers) gkeSpotInterruptions(ctx context.Context, _ *mcp.CallToolRequest, args *gkeSpotInterruptionsArgs) (*mcp.CallToolResult, any, error) {
+	since := defaultSpotWindow
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		since = d
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultSpotLimit
+	}
+	start := time.Now().Add(-since)
+
+	nodes, err := h.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := h.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var preemptions []spotPreemption
+	var loggingErr error
+	it := h.logadminClient.Entries(ctx, logadmin.Filter(spotPreemptionFilter(start)), logadmin.NewestFirst())
+	for read := 0; read < limit; read++ {
+		entry, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			loggingErr = err
+			break
+		}
+		if p, ok := newSpotPreemption(entry); ok {
+			preemptions = append(preemptions, p)
+		}
+	}
+	preemptions = matchSpotPreemptions(preemptions, nodes.Items)
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Spot interruptions since %s.\n", start.UTC().Format(time.RFC3339)))
+	if loggingErr != nil {
+		output.WriteString(fmt.Sprintf("Failed to read preemptions from Cloud Logging: %v\n", loggingErr))
+	}
+
+	output.WriteString("\nCapacity:\nNODE_POOL\tNODES\tSPOT\tREADY\tCPU\tMEMORY\tPREEMPTIONS\n")
+	for _, pool := range spotCapacity(nodes.Items, preemptions) {
+		output.WriteString(fmt.Sprintf("%s\t%d\t%d\t%d\t%s\t%s\t%d\n",
+			pool.nodePool, pool.nodes, pool.spot, pool.ready, pool.cpu.String(), pool.memory.String(), pool.preemptions))
+	}
+
+	output.WriteString(fmt.Sprintf("\nPreemptions (%d):\n", len(preemptions)))
+	if len(preemptions) > 0 {
+		output.WriteString("TIME\tNODE_POOL\tZONE\tINSTANCE\n")
+		for _, p := range preemptions {
+			output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\n", p.time.UTC().Format(time.RFC3339), p.nodePool, p.zone, p.instance))
+		}
+	}
+
+	type terminatedPod struct {
+		pod    *corev1.Pod
+		time   time.Time
+		reason string
+	}
+	var terminated []terminatedPod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		t, reason, ok := shutdownTermination(pod)
+		if !ok || (!t.IsZero() && t.Before(start)) {
+			continue
+		}
+		terminated = append(terminated, terminatedPod{pod: pod, time: t, reason: reason})
+	}
+	sort.Slice(terminated, func(i, j int) bool { return terminated[i].time.After(terminated[j].time) })
+	output.WriteString(fmt.Sprintf("\nTerminated pods (%d):\n", len(terminated)))
+	if len(terminated) > 0 {
+		output.WriteString("NAMESPACE\tPOD\tNODE\tTERMINATED\tREASON\n")
+		for _, t := range terminated {
+			when := "-"
+			if !t.time.IsZero() {
+				when = t.time.UTC().Format(time.RFC3339)
+			}
+			output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\n", t.pod.Namespace, t.pod.Name, t.pod.Spec.NodeName, when, t.reason))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
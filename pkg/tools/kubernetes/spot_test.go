@@ -0,0 +1,149 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func spotTestNode(name, pool string, spot, ready bool) corev1.Node {
+	node := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{nodePoolLabel: pool}},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse}},
+		},
+	}
+	if spot {
+		node.Labels[spotLabel] = "true"
+	}
+	if ready {
+		node.Status.Conditions[0].Status = corev1.ConditionTrue
+	}
+	return node
+}
+
+func TestNewSpotPreemption(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	entry := &logging.Entry{
+		Timestamp: now,
+		Resource:  &monitoredres.MonitoredResource{Type: "gce_instance", Labels: map[string]string{"zone": "us-central1-a"}},
+		Payload: &audit.AuditLog{
+			MethodName:   "compute.instances.preempted",
+			ResourceName: "projects/my-project/zones/us-central1-a/instances/gke-prod-spot-1234abcd-wxyz",
+		},
+	}
+	got, ok := newSpotPreemption(entry)
+	if !ok {
+		t.Fatal("newSpotPreemption() = false, want true")
+	}
+	want := spotPreemption{time: now, zone: "us-central1-a", instance: "gke-prod-spot-1234abcd-wxyz"}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(spotPreemption{})); diff != "" {
+		t.Errorf("newSpotPreemption() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, ok := newSpotPreemption(&logging.Entry{Payload: "text"}); ok {
+		t.Error("newSpotPreemption() of a text entry = true, want false")
+	}
+}
+
+func TestSpotCapacity(t *testing.T) {
+	nodes := []corev1.Node{
+		spotTestNode("gke-prod-spot-1234abcd-wxyz", "spot", true, true),
+		spotTestNode("gke-prod-spot-1234abcd-abcd", "spot", true, false),
+		spotTestNode("gke-prod-spot-5678efab-efgh", "spot", true, true),
+		spotTestNode("gke-prod-default-9999aaaa-ijkl", "default", false, true),
+	}
+	preemptions := matchSpotPreemptions([]spotPreemption{
+		{instance: "gke-prod-spot-1234abcd-wxyz"},
+		// Recreated with a new name in the same instance group.
+		{instance: "gke-prod-spot-5678efab-zzzz"},
+		// Another cluster.
+		{instance: "gke-dev-spot-1111bbbb-mnop"},
+	}, nodes)
+	if diff := cmp.Diff([]spotPreemption{
+		{instance: "gke-prod-spot-1234abcd-wxyz", nodePool: "spot"},
+		{instance: "gke-prod-spot-5678efab-zzzz", nodePool: "spot"},
+	}, preemptions, cmp.AllowUnexported(spotPreemption{})); diff != "" {
+		t.Errorf("matchSpotPreemptions() mismatch (-want +got):\n%s", diff)
+	}
+
+	got := spotCapacity(nodes, preemptions)
+	want := []*spotPoolCapacity{
+		{nodePool: "default", nodes: 1, ready: 1, cpu: resource.MustParse("2"), memory: resource.MustParse("4Gi")},
+		{nodePool: "spot", nodes: 3, spot: 3, ready: 2, cpu: resource.MustParse("6"), memory: resource.MustParse("12Gi"), preemptions: 2},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(spotPoolCapacity{}), cmp.Comparer(func(a, b resource.Quantity) bool { return a.Cmp(b) == 0 })); diff != "" {
+		t.Errorf("spotCapacity() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestShutdownTermination(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name       string
+		status     corev1.PodStatus
+		wantOK     bool
+		wantTime   time.Time
+		wantReason string
+	}{
+		{
+			name: "disruption condition",
+			status: corev1.PodStatus{
+				Phase:      corev1.PodFailed,
+				Reason:     "Terminated",
+				Message:    "Pod was terminated in response to imminent node shutdown.",
+				Conditions: []corev1.PodCondition{{Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue, Reason: "TerminationByKubelet", LastTransitionTime: metav1.NewTime(now)}},
+			},
+			wantOK:     true,
+			wantTime:   now,
+			wantReason: "Pod was terminated in response to imminent node shutdown.",
+		},
+		{
+			name: "reason only",
+			status: corev1.PodStatus{
+				Phase:             corev1.PodFailed,
+				Reason:            "Shutdown",
+				ContainerStatuses: []corev1.ContainerStatus{{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: metav1.NewTime(now)}}}},
+			},
+			wantOK:     true,
+			wantTime:   now,
+			wantReason: "Shutdown",
+		},
+		{
+			name:   "evicted",
+			status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gotTime, gotReason, ok := shutdownTermination(&corev1.Pod{Status: tc.status})
+			if ok != tc.wantOK || !gotTime.Equal(tc.wantTime) || gotReason != tc.wantReason {
+				t.Errorf("shutdownTermination() = %v, %q, %t, want %v, %q, %t", gotTime, gotReason, ok, tc.wantTime, tc.wantReason, tc.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/ptr"
+)
+
+// StatefulSetDiagnosticsToolDescription contains the documentation for the StatefulSet Diagnostics Kubernetes tool.
+// It is formatted in Markdown.
+const StatefulSetDiagnosticsToolDescription = `
+This tool diagnoses a StatefulSet, covering the behaviors specific to StatefulSets that generic rollout tools miss:
+
+* **Rolling updates**: StatefulSets update their pods one at a time, from the highest ordinal to the lowest, and wait for each pod to be ready. An unready pod blocks the update. A broken pod that still runs the old revision is never replaced by the controller: it must be deleted after the template is fixed.
+* **Partitions and update strategies**: with a *partition*, only the ordinals greater than or equal to the partition are updated; with the *OnDelete* strategy, pods are only updated when they are deleted.
+* **Ordered pod management**: with *OrderedReady*, a pod is only created once all the pods with lower ordinals are ready.
+* **Volume claims**: the PersistentVolumeClaim created for each replica from each volume claim template, with its binding status. Claims of removed replicas are kept after a scale down, unless the retention policy deletes them.
+* **Headless service**: the service of *serviceName*, which must exist, be headless and select the pods to give them stable DNS names, and the pods that have a DNS record.
+
+## Arguments
+
+* *name*: The name of the StatefulSet.
+* *namespace*: (Optional) The namespace of the StatefulSet. Defaults to the server's default namespace.
+
+## Response Format
+
+The status of the StatefulSet, its pods with their revision and DNS name, its volume claims and its headless service, followed by the problems found:
+
+StatefulSet default/db: 2/3 ready, 1/3 updated, update strategy RollingUpdate, pod management OrderedReady.
+
+POD   REVISION  STATUS            DNS
+db-0  db-6f7c   Ready             db-0.db.default.svc.cluster.local
+db-1  db-6f7c   CrashLoopBackOff  <none>
+db-2  db-8d9e   Ready             db-2.db.default.svc.cluster.local
+
+...
+
+Problems:
+- rolling update blocked by pod db-1 (CrashLoopBackOff), which runs the old revision db-6f7c: ...
+`
+
+type statefulSetDiagnosticsArgs struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// statefulSetState is a StatefulSet and the objects it depends on.
+type statefulSetState struct {
+	sts *appsv1.StatefulSet
+	// pods and pvcs are keyed by name.
+	pods map[string]*corev1.Pod
+	pvcs map[string]*corev1.PersistentVolumeClaim
+	// service is the service of serviceName, or nil if it doesn't exist.
+	service *corev1.Service
+	slices  []discoveryv1.EndpointSlice
+}
+
+// diagnoseStatefulSet returns the report of s.
+func diagnoseStatefulSet(s statefulSetState) string {
+	sts := s.sts
+	replicas := ptr.Deref(sts.Spec.Replicas, 1)
+	var problems []string
+	var out strings.Builder
+
+	out.WriteString(fmt.Sprintf("StatefulSet %s/%s: %d/%d ready, %d/%d updated, update strategy %s, pod management %s.\n",
+		sts.Namespace, sts.Name, sts.Status.ReadyReplicas, replicas, sts.Status.UpdatedReplicas, replicas,
+		sts.Spec.UpdateStrategy.Type, sts.Spec.PodManagementPolicy))
+	updating := sts.Status.UpdateRevision != "" && sts.Status.UpdateRevision != sts.Status.CurrentRevision
+	if updating {
+		out.WriteString(fmt.Sprintf("Rolling out revision %s, current revision %s.\n", sts.Status.UpdateRevision, sts.Status.CurrentRevision))
+	}
+
+	// Update strategy and partition.
+	var partition int32
+	if ru := sts.Spec.UpdateStrategy.RollingUpdate; ru != nil {
+		partition = ptr.Deref(ru.Partition, 0)
+	}
+	switch {
+	case sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType && updating:
+		problems = append(problems, fmt.Sprintf("the update strategy is OnDelete: pods are only updated to revision %s when they are deleted", sts.Status.UpdateRevision))
+	case partition > 0 && partition >= replicas:
+		problems = append(problems, fmt.Sprintf("the partition %d is not lower than the %d replicas: no pod is updated", partition, replicas))
+	case partition > 0:
+		out.WriteString(fmt.Sprintf("Partition %d: only the pods with an ordinal of %d or more are updated, the others keep the current revision.\n", partition, partition))
+	}
+
+	// Pods, by ordinal.
+	dnsNames := s.dnsNames()
+	out.WriteString("\nPOD\tREVISION\tSTATUS\tDNS\n")
+	ordered := sts.Spec.PodManagementPolicy != appsv1.ParallelPodManagement
+	firstUnready := ""
+	for i := int32(0); i < replicas; i++ {
+		name := fmt.Sprintf("%s-%d", sts.Name, i)
+		pod, ok := s.pods[name]
+		if !ok {
+			out.WriteString(fmt.Sprintf("%s\t-\tmissing\t-\n", name))
+			if ordered && firstUnready != "" {
+				problems = append(problems, fmt.Sprintf("pod %s is not created: with the OrderedReady pod management, it waits for pod %s to be ready", name, firstUnready))
+			} else {
+				problems = append(problems, fmt.Sprintf("pod %s is missing: check the events of the StatefulSet for creation errors", name))
+			}
+			if firstUnready == "" {
+				firstUnready = name
+			}
+			continue
+		}
+		revision := pod.Labels[appsv1.ControllerRevisionHashLabelKey]
+		status := podNotReadyReason(pod)
+		if status == "" {
+			status = "Ready"
+		} else if firstUnready == "" {
+			firstUnready = name
+		}
+		dns := dnsNames[name]
+		if dns == "" {
+			dns = "<none>"
+		}
+		out.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\n", name, revision, status, dns))
+
+		// Pods below the partition keep the current revision.
+		if status != "Ready" && updating && sts.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType && i >= partition {
+			if revision == sts.Status.UpdateRevision {
+				problems = append(problems, fmt.Sprintf("rolling update waiting for pod %s (%s), already updated to revision %s, to be ready: the new revision may be broken", name, status, revision))
+			} else {
+				problems = append(problems, fmt.Sprintf("rolling update blocked by pod %s (%s), which runs the old revision %s: the controller doesn't replace unready pods, delete the pod once the template is fixed", name, status, revision))
+			}
+		}
+	}
+	var extra []string
+	for name, pod := range s.pods {
+		if ordinal, ok := podOrdinal(sts.Name, name); ok && ordinal >= replicas && pod.DeletionTimestamp == nil {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		problems = append(problems, fmt.Sprintf("pod %s is above the %d replicas and is not being deleted: the scale down may be blocked by an unready pod", name, replicas))
+	}
+
+	// Volume claims.
+	if len(sts.Spec.VolumeClaimTemplates) > 0 {
+		out.WriteString("\nCLAIM\tPOD\tSTATUS\tSTORAGE_CLASS\tCAPACITY\tVOLUME\n")
+		for _, template := range sts.Spec.VolumeClaimTemplates {
+			for i := int32(0); i < replicas; i++ {
+				podName := fmt.Sprintf("%s-%d", sts.Name, i)
+				name := template.Name + "-" + podName
+				pvc, ok := s.pvcs[name]
+				if !ok {
+					out.WriteString(fmt.Sprintf("%s\t%s\tmissing\t-\t-\t-\n", name, podName))
+					if _, podExists := s.pods[podName]; podExists {
+						problems = append(problems, fmt.Sprintf("claim %s of pod %s doesn't exist", name, podName))
+					}
+					continue
+				}
+				capacity := "-"
+				if q, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+					capacity = q.String()
+				}
+				out.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\n", name, podName, pvc.Status.Phase,
+					ptr.Deref(pvc.Spec.StorageClassName, "<default>"), capacity, valueOrNone(pvc.Spec.VolumeName)))
+				switch pvc.Status.Phase {
+				case corev1.ClaimPending:
+					problems = append(problems, fmt.Sprintf("claim %s is Pending: check its events and storage class; with WaitForFirstConsumer binding, it's bound once pod %s is scheduled", name, podName))
+				case corev1.ClaimLost:
+					problems = append(problems, fmt.Sprintf("claim %s is Lost: its volume was deleted", name))
+				}
+			}
+		}
+		var retained []string
+		for name := range s.pvcs {
+			for _, template := range sts.Spec.VolumeClaimTemplates {
+				podName, ok := strings.CutPrefix(name, template.Name+"-")
+				if ordinal, isPod := podOrdinal(sts.Name, podName); ok && isPod && ordinal >= replicas {
+					retained = append(retained, name)
+				}
+			}
+		}
+		if len(retained) > 0 {
+			sort.Strings(retained)
+			out.WriteString(fmt.Sprintf("Claims of ordinals above the replicas, kept after a scale down: %s.\n", strings.Join(retained, ", ")))
+		}
+	}
+
+	// Headless service.
+	out.WriteString("\n")
+	switch {
+	case sts.Spec.ServiceName == "":
+		out.WriteString("No headless service.\n")
+		problems = append(problems, "serviceName is not set: the pods have no stable DNS names")
+	case s.service == nil:
+		out.WriteString(fmt.Sprintf("Headless service %s: not found.\n", sts.Spec.ServiceName))
+		problems = append(problems, fmt.Sprintf("the service %s of serviceName doesn't exist: the DNS names of the pods don't resolve", sts.Spec.ServiceName))
+	default:
+		svc := s.service
+		out.WriteString(fmt.Sprintf("Headless service %s: clusterIP %s, publishNotReadyAddresses %t, %d pods with a DNS record.\n",
+			svc.Name, svc.Spec.ClusterIP, svc.Spec.PublishNotReadyAddresses, len(dnsNames)))
+		if svc.Spec.ClusterIP != corev1.ClusterIPNone {
+			problems = append(problems, fmt.Sprintf("the service %s isn't headless (clusterIP %s): the pods have no DNS records; services can't be made headless in place, recreate it with clusterIP None", svc.Name, svc.Spec.ClusterIP))
+		}
+		if len(svc.Spec.Selector) == 0 || !labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(sts.Spec.Template.Labels)) {
+			problems = append(problems, fmt.Sprintf("the selector of service %s doesn't select the pods of the StatefulSet", svc.Name))
+		}
+	}
+
+	out.WriteString("\n")
+	if len(problems) == 0 {
+		out.WriteString("No problems found.\n")
+	} else {
+		out.WriteString("Problems:\n")
+		for _, p := range problems {
+			out.WriteString("- " + p + "\n")
+		}
+	}
+	return out.String()
+}
+
+// dnsNames returns the DNS names of the pods of the endpoint slices of the
+// headless service, by pod name.
+func (s statefulSetState) dnsNames() map[string]string {
+	names := map[string]string{}
+	if s.service == nil || s.service.Spec.ClusterIP != corev1.ClusterIPNone {
+		return names
+	}
+	for _, slice := range s.slices {
+		for _, e := range slice.Endpoints {
+			if e.TargetRef == nil || e.Hostname == nil {
+				continue
+			}
+			ready := e.Conditions.Ready == nil || *e.Conditions.Ready
+			if !ready && !s.service.Spec.PublishNotReadyAddresses {
+				continue
+			}
+			names[e.TargetRef.Name] = fmt.Sprintf("%s.%s.%s.svc.cluster.local", *e.Hostname, s.service.Name, s.service.Namespace)
+		}
+	}
+	return names
+}
+
+// podOrdinal returns the ordinal of the pod name of the StatefulSet sts.
+func podOrdinal(sts, name string) (int32, bool) {
+	suffix, ok := strings.CutPrefix(name, sts+"-")
+	if !ok || suffix == "" {
+		return 0, false
+	}
+	var ordinal int32
+	for _, c := range suffix {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		ordinal = ordinal*10 + c - '0'
+	}
+	return ordinal, true
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+func (h *handlers) statefulSetDiagnostics(ctx context.Context, _ *mcp.CallToolRequest, args *statefulSetDiagnosticsArgs) (*mcp.CallToolResult, any, error) {
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+	sts, err := h.clientset.AppsV1().StatefulSets(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get statefulset: %w", err)
+	}
+	s := statefulSetState{sts: sts, pods: map[string]*corev1.Pod{}, pvcs: map[string]*corev1.PersistentVolumeClaim{}}
+
+	selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse the selector of statefulset %s: %w", sts.Name, err)
+	}
+	pods, err := h.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for i := range pods.Items {
+		if ownedBy(&pods.Items[i], sts.UID) {
+			s.pods[pods.Items[i].Name] = &pods.Items[i]
+		}
+	}
+	if len(sts.Spec.VolumeClaimTemplates) > 0 {
+		pvcs, err := h.clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list persistent volume claims: %w", err)
+		}
+		for i := range pvcs.Items {
+			s.pvcs[pvcs.Items[i].Name] = &pvcs.Items[i]
+		}
+	}
+	if sts.Spec.ServiceName != "" {
+		svc, err := h.clientset.CoreV1().Services(namespace).Get(ctx, sts.Spec.ServiceName, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+		case err != nil:
+			return nil, nil, fmt.Errorf("failed to get service %s: %w", sts.Spec.ServiceName, err)
+		default:
+			s.service = svc
+			slices, err := h.clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+				LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: svc.Name}).String(),
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to list endpoint slices: %w", err)
+			}
+			s.slices = slices.Items
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: diagnoseStatefulSet(s)},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func testStatefulSetPod(name, revision string, ready bool, waiting string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{appsv1.ControllerRevisionHashLabelKey: revision}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if ready {
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	} else {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "db", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: waiting}}}}
+	}
+	return pod
+}
+
+func TestDiagnoseStatefulSet(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:             ptr.To[int32](3),
+			ServiceName:          "db",
+			PodManagementPolicy:  appsv1.OrderedReadyPodManagement,
+			UpdateStrategy:       appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+			Template:             corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "db"}}},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "data"}}},
+		},
+		Status: appsv1.StatefulSetStatus{ReadyReplicas: 2, UpdatedReplicas: 1, CurrentRevision: "db-old", UpdateRevision: "db-new"},
+	}
+	s := statefulSetState{
+		sts: sts,
+		pods: map[string]*corev1.Pod{
+			"db-0": testStatefulSetPod("db-0", "db-old", true, ""),
+			"db-1": testStatefulSetPod("db-1", "db-old", false, "CrashLoopBackOff"),
+			"db-2": testStatefulSetPod("db-2", "db-new", true, ""),
+		},
+		pvcs: map[string]*corev1.PersistentVolumeClaim{
+			"data-db-0": {Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+			"data-db-1": {Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+			"data-db-2": {Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending}},
+			"data-db-3": {Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+		},
+		service: &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.1", Selector: map[string]string{"app": "db"}},
+		},
+		slices: []discoveryv1.EndpointSlice{{
+			Endpoints: []discoveryv1.Endpoint{{Hostname: ptr.To("db-0"), TargetRef: &corev1.ObjectReference{Name: "db-0"}}},
+		}},
+	}
+
+	got := diagnoseStatefulSet(s)
+	for _, want := range []string{
+		"StatefulSet default/db: 2/3 ready, 1/3 updated, update strategy RollingUpdate, pod management OrderedReady.\n",
+		"db-1\tdb-old\tCrashLoopBackOff\t<none>\n",
+		"- rolling update blocked by pod db-1 (CrashLoopBackOff), which runs the old revision db-old",
+		"- claim data-db-2 is Pending",
+		"Claims of ordinals above the replicas, kept after a scale down: data-db-3.\n",
+		"- the service db isn't headless (clusterIP 10.0.0.1)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("diagnoseStatefulSet() = %q, want it to contain %q", got, want)
+		}
+	}
+
+	// Headless, the pods have DNS names.
+	s.service.Spec.ClusterIP = corev1.ClusterIPNone
+	got = diagnoseStatefulSet(s)
+	if want := "db-0\tdb-old\tReady\tdb-0.db.default.svc.cluster.local\n"; !strings.Contains(got, want) {
+		t.Errorf("diagnoseStatefulSet() = %q, want it to contain %q", got, want)
+	}
+	if strings.Contains(got, "isn't headless") {
+		t.Errorf("diagnoseStatefulSet() = %q, want no headless service problem", got)
+	}
+
+	// Below the partition, the unready pod doesn't block the update.
+	sts.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateStatefulSetStrategy{Partition: ptr.To[int32](2)}
+	if got := diagnoseStatefulSet(s); strings.Contains(got, "rolling update blocked") {
+		t.Errorf("diagnoseStatefulSet() = %q, want no blocked rolling update below the partition", got)
+	}
+}
+
+func TestPodOrdinal(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want int32
+		ok   bool
+	}{
+		{"db-0", 0, true},
+		{"db-12", 12, true},
+		{"db-", 0, false},
+		{"db-abc", 0, false},
+		{"web-1", 0, false},
+	} {
+		got, ok := podOrdinal("db", tc.name)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("podOrdinal(%q) = %d, %t, want %d, %t", tc.name, got, ok, tc.want, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// maxSummaryInputBytes bounds the logs sent to the client's model. The
+	// most recent logs are kept.
+	maxSummaryInputBytes = 256 * 1024
+	// summaryMaxTokens is the maximum length of a summary.
+	summaryMaxTokens = 1500
+)
+
+// summarizeLogsPrompt is the instruction sent to the client's model along
+// with the logs.
+const summarizeLogsPrompt = `Summarize the following %s for an engineer investigating a problem in a Kubernetes cluster.
+
+* Group similar entries into clusters, e.g. the same message with different IDs or timestamps, and give the count and the time range of each cluster.
+* List errors, warnings and stack traces first, quoting one representative entry of each cluster verbatim.
+* Point out when the behavior changes, e.g. when errors start or stop.
+* Do not speculate beyond what the logs show. Answer in plain text or Markdown, without a preamble.
+
+%s`
+
+// summarizeLogs asks the model of the client to summarize logs, a text
+// describing what they are, with MCP sampling. If the client doesn't support
+// sampling, it returns logs unchanged with a note.
+func summarizeLogs(ctx context.Context, req *mcp.CallToolRequest, what, logs string) (string, error) {
+	if req == nil || req.Session == nil || req.Session.InitializeParams() == nil ||
+		req.Session.InitializeParams().Capabilities == nil || req.Session.InitializeParams().Capabilities.Sampling == nil {
+		return "The client doesn't support sampling, so the logs could not be summarized.\n\n" + logs, nil
+	}
+	if strings.TrimSpace(logs) == "" {
+		return "No logs to summarize.\n", nil
+	}
+
+	input := logs
+	var note string
+	if len(input) > maxSummaryInputBytes {
+		input = input[len(input)-maxSummaryInputBytes:]
+		// Drop the partial first line.
+		if i := strings.IndexByte(input, '\n'); i >= 0 {
+			input = input[i+1:]
+		}
+		note = fmt.Sprintf(" (only the last %d of %d bytes were summarized)", len(input), len(logs))
+	}
+	result, err := req.Session.CreateMessage(ctx, &mcp.CreateMessageParams{
+		Messages: []*mcp.SamplingMessage{{
+			Role:    "user",
+			Content: &mcp.TextContent{Text: fmt.Sprintf(summarizeLogsPrompt, what, input)},
+		}},
+		SystemPrompt: "You are an expert Site Reliability Engineer who summarizes logs accurately and concisely.",
+		MaxTokens:    summaryMaxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize logs with the client's model: %w", err)
+	}
+	text, ok := result.Content.(*mcp.TextContent)
+	if !ok {
+		return "", fmt.Errorf("failed to summarize logs: the client's model returned %T content, want text", result.Content)
+	}
+	return fmt.Sprintf("Summary of %s by %s%s:\n\n%s", what, result.Model, note, text.Text), nil
+}
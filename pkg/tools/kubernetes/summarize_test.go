@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type summarizeTestArgs struct {
+	Logs string `json:"logs"`
+}
+
+// callSummarize calls summarizeLogs from a tool called by a client with the
+// given options.
+func callSummarize(t *testing.T, opts *mcp.ClientOptions, logs string) string {
+	t.Helper()
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "summarize"}, func(ctx context.Context, req *mcp.CallToolRequest, args *summarizeTestArgs) (*mcp.CallToolResult, any, error) {
+		text, err := summarizeLogs(ctx, req, "logs of pod default/web", args.Logs)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, opts)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "summarize", Arguments: map[string]any{"logs": logs}})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("CallTool() returned error %v", result.Content[0].(*mcp.TextContent).Text)
+	}
+	return result.Content[0].(*mcp.TextContent).Text
+}
+
+func TestSummarizeLogs(t *testing.T) {
+	logs := strings.Repeat("connection refused\n", 3)
+
+	var prompt string
+	got := callSummarize(t, &mcp.ClientOptions{
+		CreateMessageHandler: func(_ context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			prompt = req.Params.Messages[0].Content.(*mcp.TextContent).Text
+			return &mcp.CreateMessageResult{Model: "test-model", Role: "assistant", Content: &mcp.TextContent{Text: "3x connection refused"}}, nil
+		},
+	}, logs)
+	if want := "Summary of logs of pod default/web by test-model:\n\n3x connection refused"; got != want {
+		t.Errorf("summarizeLogs() = %q, want %q", got, want)
+	}
+	if !strings.HasSuffix(prompt, logs) {
+		t.Errorf("summarizeLogs() prompt = %q, want it to end with the logs", prompt)
+	}
+
+	got = callSummarize(t, nil, logs)
+	if !strings.HasPrefix(got, "The client doesn't support sampling") || !strings.HasSuffix(got, logs) {
+		t.Errorf("summarizeLogs() without sampling = %q, want the logs with a note", got)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// TargetNamespaces returns the namespaces targeted by the call of tool with
+// args, as decoded from JSON: the namespace arguments, the namespaces of the
+// manifests of kube_apply_resource, kube_apply_bundle and kube_batch, and the
+// namespaces created or deleted by name. The targets without a namespace are
+// in defaultNamespace. The manifests read from URLs and files aren't known
+// before the call, and aren't inspected.
+func TargetNamespaces(tool string, args map[string]any, defaultNamespace string) []string {
+	var namespaces []string
+	add := func(namespace string) {
+		if namespace != "" && !slices.Contains(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	namespace := stringArg(args, "namespace")
+	add(namespace)
+	add(stringArg(args, "source_namespace"))
+	add(stringArg(args, "target_namespace"))
+	if tool == "kube_delete_namespace" {
+		add(stringArg(args, "name"))
+	}
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	for _, obj := range manifestObjects(stringArg(args, "manifest")) {
+		add(objectNamespace(obj, defaultNamespace))
+	}
+	if bundle := stringArg(args, "bundle"); bundle != "" {
+		// Bundles that fail to parse aren't applied at all.
+		objs, _ := parseBundle([]byte(bundle))
+		for _, obj := range objs {
+			add(objectNamespace(obj, namespace))
+		}
+	}
+	operations, _ := args["operations"].([]any)
+	for _, op := range operations {
+		op, _ := op.(map[string]any)
+		if manifest := stringArg(op, "manifest"); manifest != "" {
+			for _, obj := range manifestObjects(manifest) {
+				add(objectNamespace(obj, defaultNamespace))
+			}
+			continue
+		}
+		if ns := stringArg(op, "namespace"); ns != "" {
+			add(ns)
+		} else {
+			add(defaultNamespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		add(defaultNamespace)
+	}
+	return namespaces
+}
+
+// stringArg returns the string argument key of args, if any.
+func stringArg(args map[string]any, key string) string {
+	s, _ := args[key].(string)
+	return s
+}
+
+// manifestObjects returns the objects of the manifest of kube_apply_resource,
+// parsed as it does. The documents that fail to parse are skipped: the call
+// fails on them, once the previous documents are applied.
+func manifestObjects(manifest string) []*unstructured.Unstructured {
+	var objs []*unstructured.Unstructured
+	for _, part := range strings.Split(manifest, "---") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		jsonData, err := yaml.YAMLToJSON([]byte(part))
+		if err != nil {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(jsonData); err != nil {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	return objs
+}
+
+// objectNamespace returns the namespace targeted by obj: the namespace
+// itself for Namespace objects, or else the namespace of obj, defaulting to
+// namespace.
+func objectNamespace(obj *unstructured.Unstructured, namespace string) string {
+	if obj.GetKind() == "Namespace" && obj.GroupVersionKind().Group == "" {
+		return obj.GetName()
+	}
+	if ns := obj.GetNamespace(); ns != "" {
+		return ns
+	}
+	return namespace
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTargetNamespaces(t *testing.T) {
+	const manifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: prod-shop
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: prod-billing
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: token
+`
+	for _, tc := range []struct {
+		name string
+		tool string
+		args map[string]any
+		want []string
+	}{
+		{name: "default", tool: "kube_get_resources", args: map[string]any{"resource": "pods"}, want: []string{"shop"}},
+		{name: "namespace", tool: "kube_scale", args: map[string]any{"namespace": "prod-shop"}, want: []string{"prod-shop"}},
+		{name: "clone", tool: "kube_clone_namespace", args: map[string]any{"source_namespace": "shop", "target_namespace": "prod-shop"}, want: []string{"shop", "prod-shop"}},
+		{name: "delete namespace", tool: "kube_delete_namespace", args: map[string]any{"name": "prod-shop"}, want: []string{"prod-shop"}},
+		{name: "manifest", tool: "kube_apply_resource", args: map[string]any{"manifest": manifest}, want: []string{"prod-shop", "prod-billing", "shop"}},
+		{name: "bundle", tool: "kube_apply_bundle", args: map[string]any{"bundle": manifest, "namespace": "staging"}, want: []string{"staging", "prod-shop", "prod-billing"}},
+		{name: "batch", tool: "kube_batch", args: map[string]any{"operations": []any{
+			map[string]any{"op": "delete", "resource": "pods", "name": "web", "namespace": "prod-web"},
+			map[string]any{"op": "apply", "manifest": manifest},
+			map[string]any{"op": "get", "resource": "pods"},
+		}}, want: []string{"prod-web", "prod-shop", "prod-billing", "shop"}},
+		{name: "invalid manifest", tool: "kube_apply_resource", args: map[string]any{"manifest": "kind: [\n---\n" + manifest}, want: []string{"prod-shop", "prod-billing", "shop"}},
+	} {
+		if got := TargetNamespaces(tc.tool, tc.args, "shop"); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: TargetNamespaces() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
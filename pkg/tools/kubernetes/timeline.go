@@ -0,0 +1,272 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// EventsTimelineToolDescription contains the documentation for the Events Timeline Kubernetes tool.
+// It is formatted in Markdown.
+const EventsTimelineToolDescription = `
+This tool builds a single, chronologically sorted timeline of the events of a workload and the objects related to it. This is the timeline that is otherwise built by hand from several *kubectl describe* and *kubectl get events* calls during an incident.
+
+Starting from the given object, the timeline includes the events of:
+
+* the object itself.
+* for a Deployment, its ReplicaSets.
+* the pods selected by the workload (Deployments, StatefulSets, DaemonSets, ReplicaSets and Jobs).
+* the PersistentVolumeClaims mounted by these pods.
+* the HorizontalPodAutoscalers targeting the workload.
+
+Repeated events, i.e. events of the same object with the same reason and message, are merged into one entry with the total count and the first and last time they were seen.
+
+## Arguments
+
+* *resource*: The kind or resource name of the object, e.g. *deployment*, *statefulset* or *pod*.
+* *name*: The name of the object.
+* *namespace*: (Optional) The namespace of the object. Defaults to the server's default namespace.
+* *since*: (Optional) How far back to look for events, as a duration such as *30m* or *2h*. Defaults to *1h*.
+
+## Response Format
+
+The objects included, followed by one row per event:
+
+FIRST_SEEN            LAST_SEEN             COUNT  TYPE     OBJECT                 REASON            MESSAGE
+2025-01-01T10:00:00Z  2025-01-01T10:00:00Z  1      Normal   Deployment/web         ScalingReplicaSet Scaled up replica set web-7d9f to 3
+2025-01-01T10:00:02Z  2025-01-01T10:04:10Z  12     Warning  Pod/web-7d9f-abcde     BackOff           Back-off restarting failed container
+`
+
+type eventsTimelineArgs struct {
+	Resource  string `json:"resource"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Since     string `json:"since,omitempty"`
+}
+
+const defaultTimelineWindow = time.Hour
+
+// timelineEntry is a merged set of identical events.
+type timelineEntry struct {
+	firstSeen, lastSeen time.Time
+	count               int32
+	eventType           string
+	object              string
+	reason              string
+	message             string
+}
+
+func (h *handlers) eventsTimeline(ctx context.Context, _ *mcp.CallToolRequest, args *eventsTimelineArgs) (*mcp.CallToolResult, any, error) {
+	since := defaultTimelineWindow
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		since = d
+	}
+	namespace := args.Namespace
+	if namespace == "" {
+		namespace = h.defaultNamespace
+	}
+
+	gvr, err := h.findGVR(args.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	obj, err := h.dyn.Resource(gvr).Namespace(namespace).Get(ctx, args.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get resource: %w", err)
+	}
+	objects, err := h.relatedObjects(ctx, obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events, err := h.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	timeline := buildTimeline(events.Items, objects, time.Now().Add(-since))
+
+	names := make([]string, 0, len(objects))
+	for _, name := range objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Objects: %s\n\n", strings.Join(names, ", ")))
+	output.WriteString("FIRST_SEEN\tLAST_SEEN\tCOUNT\tTYPE\tOBJECT\tREASON\tMESSAGE\n")
+	for _, e := range timeline {
+		output.WriteString(fmt.Sprintf("%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			e.firstSeen.UTC().Format(time.RFC3339),
+			e.lastSeen.UTC().Format(time.RFC3339),
+			e.count,
+			e.eventType,
+			e.object,
+			e.reason,
+			e.message,
+		))
+	}
+	if len(timeline) == 0 {
+		output.WriteString(fmt.Sprintf("No events in the last %s.\n", since))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+// relatedObjects returns obj and the objects related to it, as a map from
+// UID to "Kind/name".
+func (h *handlers) relatedObjects(ctx context.Context, obj *unstructured.Unstructured) (map[types.UID]string, error) {
+	namespace := obj.GetNamespace()
+	objects := map[types.UID]string{obj.GetUID(): obj.GetKind() + "/" + obj.GetName()}
+
+	var pods []corev1.Pod
+	switch obj.GetKind() {
+	case "Pod":
+		pod := &corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, pod); err != nil {
+			return nil, fmt.Errorf("failed to convert pod: %w", err)
+		}
+		pods = append(pods, *pod)
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		selectorMap, found, err := unstructured.NestedMap(obj.Object, "spec", "selector")
+		if err != nil || !found {
+			break
+		}
+		selector := &metav1.LabelSelector{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selectorMap, selector); err != nil {
+			return nil, fmt.Errorf("failed to convert selector: %w", err)
+		}
+		pods, err = h.listSelectedPods(ctx, namespace, selector)
+		if err != nil {
+			return nil, err
+		}
+		if obj.GetKind() == "Deployment" {
+			replicaSets, err := h.clientset.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list replica sets: %w", err)
+			}
+			for _, rs := range replicaSets.Items {
+				if owner := metav1.GetControllerOf(&rs); owner != nil && owner.UID == obj.GetUID() {
+					objects[rs.UID] = "ReplicaSet/" + rs.Name
+				}
+			}
+		}
+	}
+
+	refs := newPodReferences()
+	for i := range pods {
+		objects[pods[i].UID] = "Pod/" + pods[i].Name
+		refs.addPod(&pods[i])
+	}
+	if len(refs.pvcs) > 0 {
+		pvcs, err := h.clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list persistent volume claims: %w", err)
+		}
+		for _, pvc := range pvcs.Items {
+			if refs.pvcs[pvc.Namespace+"/"+pvc.Name] {
+				objects[pvc.UID] = "PersistentVolumeClaim/" + pvc.Name
+			}
+		}
+	}
+
+	hpas, err := h.clientset.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list horizontal pod autoscalers: %w", err)
+	}
+	for _, hpa := range hpas.Items {
+		if ref := hpa.Spec.ScaleTargetRef; ref.Kind == obj.GetKind() && ref.Name == obj.GetName() {
+			objects[hpa.UID] = "HorizontalPodAutoscaler/" + hpa.Name
+		}
+	}
+	return objects, nil
+}
+
+// buildTimeline merges the events about objects last seen after cutoff into
+// a timeline sorted by the time they were first seen.
+func buildTimeline(events []corev1.Event, objects map[types.UID]string, cutoff time.Time) []timelineEntry {
+	entries := map[string]*timelineEntry{}
+	for i := range events {
+		e := &events[i]
+		object, ok := objects[e.InvolvedObject.UID]
+		if !ok {
+			continue
+		}
+		last := eventTime(e)
+		if last.Before(cutoff) {
+			continue
+		}
+		first := e.FirstTimestamp.Time
+		if first.IsZero() || first.After(last) {
+			first = last
+		}
+		count := e.Count
+		if e.Series != nil {
+			count = e.Series.Count
+		}
+		count = max(count, 1)
+
+		key := strings.Join([]string{object, e.Type, e.Reason, e.Message}, "\x00")
+		if entry, ok := entries[key]; ok {
+			entry.count += count
+			if first.Before(entry.firstSeen) {
+				entry.firstSeen = first
+			}
+			if last.After(entry.lastSeen) {
+				entry.lastSeen = last
+			}
+			continue
+		}
+		entries[key] = &timelineEntry{
+			firstSeen: first,
+			lastSeen:  last,
+			count:     count,
+			eventType: e.Type,
+			object:    object,
+			reason:    e.Reason,
+			message:   e.Message,
+		}
+	}
+
+	timeline := make([]timelineEntry, 0, len(entries))
+	for _, entry := range entries {
+		timeline = append(timeline, *entry)
+	}
+	sort.Slice(timeline, func(i, j int) bool {
+		if !timeline[i].firstSeen.Equal(timeline[j].firstSeen) {
+			return timeline[i].firstSeen.Before(timeline[j].firstSeen)
+		}
+		return timeline[i].object < timeline[j].object
+	})
+	return timeline
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	base := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) metav1.Time { return metav1.NewTime(base.Add(time.Duration(minutes) * time.Minute)) }
+	event := func(uid types.UID, reason string, first, last int, count int32) corev1.Event {
+		return corev1.Event{
+			InvolvedObject: corev1.ObjectReference{UID: uid},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Message:        reason + " message",
+			FirstTimestamp: at(first),
+			LastTimestamp:  at(last),
+			Count:          count,
+		}
+	}
+	objects := map[types.UID]string{"deploy": "Deployment/web", "pod": "Pod/web-1"}
+	events := []corev1.Event{
+		event("pod", "BackOff", 5, 8, 3),
+		event("deploy", "ScalingReplicaSet", 1, 1, 1),
+		// A duplicate of the first event, e.g. after the event was recreated.
+		event("pod", "BackOff", 9, 12, 2),
+		// Events of unrelated objects and old events are dropped.
+		event("other", "BackOff", 5, 8, 1),
+		event("pod", "Pulled", -120, -90, 1),
+	}
+
+	got := buildTimeline(events, objects, base)
+	want := []timelineEntry{
+		{firstSeen: at(1).Time, lastSeen: at(1).Time, count: 1, eventType: "Warning", object: "Deployment/web", reason: "ScalingReplicaSet", message: "ScalingReplicaSet message"},
+		{firstSeen: at(5).Time, lastSeen: at(12).Time, count: 5, eventType: "Warning", object: "Pod/web-1", reason: "BackOff", message: "BackOff message"},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(timelineEntry{})); diff != "" {
+		t.Errorf("buildTimeline() mismatch (-want +got):\n%s", diff)
+	}
+}
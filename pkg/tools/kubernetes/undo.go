@@ -0,0 +1,292 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// UndoLastChangeToolDescription contains the documentation for the Kubernetes Undo Last Change tool.
+// It is formatted in Markdown.
+const UndoLastChangeToolDescription = `
+This tool undoes the last change made in the MCP session with *kube_apply_resource*, *kube_patch_resource* or *kube_delete_resource*, including the operations of *kube_batch*: the server records the state of the objects before each change, and restores it. Modified objects get their prior manifest back, created objects are deleted, and deleted objects are recreated, with a new UID. Calling the tool again undoes the change before, up to the last 50 changes of the session made since it started or since the profile changed. The changes of other sessions are never undone.
+
+An object changed again since, e.g. by a controller or another user, isn't restored unless *force* is set, since the restore would discard that change. Confirm the undo with the user first: show them the result of a dry run.
+
+Recreating deleted objects restores their manifest, not their data: the data of deleted persistent volumes, or the pods of deleted jobs, aren't restored.
+
+## Arguments
+
+* *dry_run*: (Optional) If true, describes the undo without making it.
+* *force*: (Optional) If true, restores the objects changed since the change.
+
+## Response Format
+
+Undid kube_patch_resource of 2025-03-01T10:05:00Z:
+- deployments.apps shop/web: restored the prior manifest
+`
+
+type undoLastChangeArgs struct {
+	DryRun bool `json:"dry_run,omitempty"`
+	Force  bool `json:"force,omitempty"`
+}
+
+// maxUndoChanges is the number of changes the undo journal keeps.
+const maxUndoChanges = 50
+
+// undoObject is the state of an object before a change.
+type undoObject struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+	// prior is the object before the change, or nil if the change created
+	// it.
+	prior *unstructured.Unstructured
+	// resourceVersion is the version of the object after the change, or
+	// empty if the change deleted it.
+	resourceVersion string
+}
+
+// String returns the resource, namespace and name of o.
+func (o undoObject) String() string {
+	if o.namespace == "" {
+		return fmt.Sprintf("%s %s", o.gvr.GroupResource(), o.name)
+	}
+	return fmt.Sprintf("%s %s/%s", o.gvr.GroupResource(), o.namespace, o.name)
+}
+
+// undoChange is a change of objects made by a tool call.
+type undoChange struct {
+	tool    string
+	time    time.Time
+	objects []undoObject
+}
+
+// undoJournal records the state of the objects before the changes of the
+// write tools, most recent last, so that they can be undone.
+type undoJournal struct {
+	// undoing serializes the undos, which update the objects of changes.
+	undoing sync.Mutex
+
+	mu      sync.Mutex
+	changes []*undoChange
+}
+
+// record adds the change of objects by tool to j, if any. A nil journal
+// records nothing.
+func (j *undoJournal) record(tool string, objects []undoObject) {
+	if j == nil || len(objects) == 0 {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.changes = append(j.changes, &undoChange{tool: tool, time: time.Now(), objects: objects})
+	if len(j.changes) > maxUndoChanges {
+		j.changes = j.changes[len(j.changes)-maxUndoChanges:]
+	}
+}
+
+// last returns the most recent change, or nil if there is none.
+func (j *undoJournal) last() *undoChange {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if len(j.changes) == 0 {
+		return nil
+	}
+	return j.changes[len(j.changes)-1]
+}
+
+// remove drops change from j once undone.
+func (j *undoJournal) remove(change *undoChange) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for i, c := range j.changes {
+		if c == change {
+			j.changes = append(j.changes[:i], j.changes[i+1:]...)
+			return
+		}
+	}
+}
+
+// undoJournals are the undo journals of the MCP sessions, by session ID, so
+// that a session only undoes its own changes.
+type undoJournals struct {
+	mu sync.Mutex
+	// journals are the journals by session ID. Requests without a session,
+	// and stdio sessions, which have no ID, share the empty ID.
+	journals map[string]*undoJournal
+}
+
+func newUndoJournals() *undoJournals {
+	return &undoJournals{journals: map[string]*undoJournal{}}
+}
+
+// forSession returns the journal of the session of req, creating it on first
+// use. A nil set of journals returns a nil journal, which records nothing.
+func (u *undoJournals) forSession(req *mcp.CallToolRequest) *undoJournal {
+	if u == nil {
+		return nil
+	}
+	var ss *mcp.ServerSession
+	if req != nil {
+		ss = req.Session
+	}
+	id := ""
+	if ss != nil {
+		id = ss.ID()
+	}
+	return u.journal(id, ss)
+}
+
+// journal returns the journal of the session id, creating it on first use.
+// Journals created for ss are dropped when ss ends.
+func (u *undoJournals) journal(id string, ss *mcp.ServerSession) *undoJournal {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	j, ok := u.journals[id]
+	if !ok {
+		j = &undoJournal{}
+		u.journals[id] = j
+		if ss != nil {
+			go func() {
+				ss.Wait()
+				u.forget(id)
+			}()
+		}
+	}
+	return j
+}
+
+// forget drops the journal of the session id.
+func (u *undoJournals) forget(id string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.journals, id)
+}
+
+// resourceInterface returns the client of the objects of gvr in namespace,
+// which is empty for cluster-scoped resources.
+func (h *handlers) resourceInterface(gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	if namespace == "" {
+		return h.dyn.Resource(gvr)
+	}
+	return h.dyn.Resource(gvr).Namespace(namespace)
+}
+
+// undoObjectChange restores o, or only checks that it can be restored if
+// dryRun is set, and returns what it did.
+func (h *handlers) undoObjectChange(ctx context.Context, o undoObject, dryRun, force bool) (string, error) {
+	ri := h.resourceInterface(o.gvr, o.namespace)
+	current, err := ri.Get(ctx, o.name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get %s: %w", o, err)
+	}
+	exists := err == nil
+	if exists && o.resourceVersion != "" && current.GetResourceVersion() != o.resourceVersion && !force {
+		return "", fmt.Errorf("%s was changed since, e.g. by a controller or another user: check its state, and retry with force set to true to discard that change", o)
+	}
+
+	switch {
+	case o.prior == nil && !exists:
+		return "already deleted", nil
+	case o.prior == nil:
+		if dryRun {
+			return "would delete the created object", nil
+		}
+		if err := ri.Delete(ctx, o.name, metav1.DeleteOptions{}); err != nil {
+			return "", fmt.Errorf("failed to delete %s: %w", o, err)
+		}
+		return "deleted the created object", nil
+	case exists && o.resourceVersion == "":
+		return "", fmt.Errorf("%s was deleted, but was recreated since: check its state, and delete it first to restore the deleted object", o)
+	case !exists:
+		obj := o.prior.DeepCopy()
+		sanitizeManifest(obj)
+		if dryRun {
+			return "would recreate the deleted object", nil
+		}
+		if _, err := ri.Create(ctx, obj, metav1.CreateOptions{FieldManager: h.c.FieldManager()}); err != nil {
+			return "", fmt.Errorf("failed to recreate %s: %w", o, err)
+		}
+		return "recreated the deleted object", nil
+	default:
+		obj := o.prior.DeepCopy()
+		unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+		obj.SetResourceVersion(current.GetResourceVersion())
+		if dryRun {
+			return "would restore the prior manifest", nil
+		}
+		if _, err := ri.Update(ctx, obj, metav1.UpdateOptions{FieldManager: h.c.FieldManager()}); err != nil {
+			return "", fmt.Errorf("failed to restore %s: %w", o, err)
+		}
+		return "restored the prior manifest", nil
+	}
+}
+
+func (h *handlers) undoLastChange(ctx context.Context, req *mcp.CallToolRequest, args *undoLastChangeArgs) (*mcp.CallToolResult, any, error) {
+	journal := h.undo.forSession(req)
+	journal.undoing.Lock()
+	defer journal.undoing.Unlock()
+	change := journal.last()
+	if change == nil {
+		return nil, nil, fmt.Errorf("there is no change to undo: only the changes made in this session with kube_apply_resource, kube_patch_resource, kube_delete_resource and kube_batch since it started, or since the profile changed, can be undone")
+	}
+
+	var output strings.Builder
+	verb := "Undid"
+	if args.DryRun {
+		verb = "Would undo"
+	}
+	output.WriteString(fmt.Sprintf("%s %s of %s:\n", verb, change.tool, change.time.UTC().Format(time.RFC3339)))
+	// The objects are restored in the reverse order of the change, and the
+	// change stays in the journal until all of them are.
+	for i := len(change.objects) - 1; i >= 0; i-- {
+		o := change.objects[i]
+		result, err := h.undoObjectChange(ctx, o, args.DryRun, args.Force)
+		if err != nil {
+			if !args.DryRun {
+				change.objects = change.objects[:i+1]
+			}
+			output.WriteString(fmt.Sprintf("- %s: failed: %v\n", o, err))
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: output.String()},
+				},
+				IsError: true,
+			}, nil, nil
+		}
+		output.WriteString(fmt.Sprintf("- %s: %s\n", o, result))
+	}
+	if !args.DryRun {
+		journal.remove(change)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
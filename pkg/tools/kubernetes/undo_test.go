@@ -0,0 +1,166 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func configMap(name, resourceVersion, value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": name, "namespace": "shop", "resourceVersion": resourceVersion},
+		"data":       map[string]any{"mode": value},
+	}}
+}
+
+func TestUndoLastChange(t *testing.T) {
+	ctx := context.Background()
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	h := &handlers{
+		c:    config.New("test", config.Options{}),
+		dyn:  dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap("web", "2", "fast"), configMap("created", "5", "new")),
+		undo: newUndoJournals(),
+	}
+	h.undo.journal("", nil).record("kube_delete_resource", []undoObject{{gvr: configMaps, namespace: "shop", name: "deleted", prior: configMap("deleted", "3", "old")}})
+	h.undo.journal("", nil).record("kube_apply_resource", []undoObject{
+		{gvr: configMaps, namespace: "shop", name: "web", prior: configMap("web", "1", "safe"), resourceVersion: "2"},
+		{gvr: configMaps, namespace: "shop", name: "created", resourceVersion: "5"},
+	})
+
+	undo := func(args *undoLastChangeArgs) string {
+		t.Helper()
+		res, _, err := h.undoLastChange(ctx, &mcp.CallToolRequest{}, args)
+		if err != nil {
+			t.Fatalf("undoLastChange() failed: %v", err)
+		}
+		if res.IsError {
+			t.Fatalf("undoLastChange() = %q, want success", res.Content[0].(*mcp.TextContent).Text)
+		}
+		return res.Content[0].(*mcp.TextContent).Text
+	}
+	get := func(name string) (*unstructured.Unstructured, error) {
+		return h.dyn.Resource(configMaps).Namespace("shop").Get(ctx, name, metav1.GetOptions{})
+	}
+
+	if got := undo(&undoLastChangeArgs{DryRun: true}); !strings.Contains(got, "- configmaps shop/created: would delete the created object\n- configmaps shop/web: would restore the prior manifest\n") {
+		t.Errorf("undoLastChange() dry run = %q", got)
+	}
+	if _, err := get("created"); err != nil {
+		t.Fatalf("the dry run deleted the created object: %v", err)
+	}
+
+	undo(&undoLastChangeArgs{})
+	if _, err := get("created"); !apierrors.IsNotFound(err) {
+		t.Errorf("get(created) error = %v, want not found", err)
+	}
+	web, err := get("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode, _, _ := unstructured.NestedString(web.Object, "data", "mode"); mode != "safe" {
+		t.Errorf("web mode = %q, want safe", mode)
+	}
+
+	if got := undo(&undoLastChangeArgs{}); !strings.Contains(got, "- configmaps shop/deleted: recreated the deleted object\n") {
+		t.Errorf("undoLastChange() = %q", got)
+	}
+	if _, err := get("deleted"); err != nil {
+		t.Errorf("the deleted object wasn't recreated: %v", err)
+	}
+	if _, _, err := h.undoLastChange(ctx, &mcp.CallToolRequest{}, &undoLastChangeArgs{}); err == nil {
+		t.Error("undoLastChange() of an empty journal succeeded, want error")
+	}
+}
+
+func TestUndoChangedSince(t *testing.T) {
+	ctx := context.Background()
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	h := &handlers{
+		c:    config.New("test", config.Options{}),
+		dyn:  dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap("web", "7", "tuned")),
+		undo: newUndoJournals(),
+	}
+	h.undo.journal("", nil).record("kube_patch_resource", []undoObject{{gvr: configMaps, namespace: "shop", name: "web", prior: configMap("web", "1", "safe"), resourceVersion: "2"}})
+
+	res, _, err := h.undoLastChange(ctx, &mcp.CallToolRequest{}, &undoLastChangeArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !res.IsError || !strings.Contains(text, "was changed since") {
+		t.Errorf("undoLastChange() = %v %q, want an error result", res.IsError, text)
+	}
+	if h.undo.journal("", nil).last() == nil {
+		t.Fatal("the failed undo dropped the change")
+	}
+	if _, _, err := h.undoLastChange(ctx, &mcp.CallToolRequest{}, &undoLastChangeArgs{Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if h.undo.journal("", nil).last() != nil {
+		t.Error("the change is still in the journal after the undo")
+	}
+}
+
+func TestUndoJournalsPerSession(t *testing.T) {
+	ctx := context.Background()
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	h := &handlers{
+		c:    config.New("test", config.Options{}),
+		dyn:  dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), configMap("web", "2", "fast")),
+		undo: newUndoJournals(),
+	}
+	h.undo.journal("session-a", nil).record("kube_patch_resource", []undoObject{{gvr: configMaps, namespace: "shop", name: "web", prior: configMap("web", "1", "safe"), resourceVersion: "2"}})
+
+	// Another session can't undo the change.
+	if _, _, err := h.undoLastChange(ctx, &mcp.CallToolRequest{}, &undoLastChangeArgs{}); err == nil {
+		t.Error("undoLastChange() of another session succeeded, want error")
+	}
+	if h.undo.journal("session-b", nil).last() != nil {
+		t.Error("journal(session-b).last() = a change of session-a, want nil")
+	}
+	if h.undo.journal("session-a", nil).last() == nil {
+		t.Error("journal(session-a).last() = nil, want the change")
+	}
+	h.undo.forget("session-a")
+	if h.undo.journal("session-a", nil).last() != nil {
+		t.Error("journal(session-a).last() after forget() = a change, want nil")
+	}
+}
+
+func TestUndoJournalLimit(t *testing.T) {
+	j := &undoJournal{}
+	for i := 0; i < maxUndoChanges+5; i++ {
+		j.record("kube_delete_resource", []undoObject{{name: "web"}})
+	}
+	j.record("kube_apply_resource", nil)
+	if got := len(j.changes); got != maxUndoChanges {
+		t.Errorf("len(changes) = %d, want %d", got, maxUndoChanges)
+	}
+	if got := j.last().tool; got != "kube_delete_resource" {
+		t.Errorf("last().tool = %q, want kube_delete_resource", got)
+	}
+}
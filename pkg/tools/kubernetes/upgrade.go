@@ -0,0 +1,441 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// UpgradeReadinessToolDescription contains the documentation for the Kubernetes Upgrade Readiness tool.
+// It is formatted in Markdown.
+const UpgradeReadinessToolDescription = `
+This tool checks whether the cluster is ready to upgrade to a target Kubernetes version, in a single call. It compares the versions of the control plane, of the kubelets of the nodes and of the client of the server against the [version skew policy](https://kubernetes.io/releases/version-skew-policy/), and lists the resources and clients still using APIs removed in the target version.
+
+The skew policy allows kubelets to be up to 3 minor versions older than the control plane, and never newer. The control plane is upgraded one minor version at a time, so kubelets older than the target by more than 3 minor versions must be upgraded first.
+
+Resources using removed APIs are found from the API version recorded in their field managers and in their *kubectl.kubernetes.io/last-applied-configuration* annotation: these are the manifests and controllers that must be updated before the upgrade. The *apiserver_requested_deprecated_apis* metric of the API server adds the APIs requested by clients since the API server started, if the server can read it.
+
+## Arguments
+
+* *target_version*: (Optional) The target Kubernetes version, e.g. *1.32*.
+* *project_id*, *location*, *cluster_name*: (Optional) The GKE cluster. When *target_version* is not given, the target is the version of the next automatic upgrade of the cluster, as reported by gke_fetch_cluster_upgrade_info.
+
+Without a target version or a GKE cluster, the target is the next minor version of the control plane.
+
+## Response Format
+
+Control plane: v1.31.5-gke.1014001
+Client: client-go v0.34.2 (Kubernetes 1.34)
+Target: 1.32 (next automatic upgrade of the GKE cluster)
+
+NODE                            KUBELET               STATUS
+gke-prod-default-pool-1a2b3c4d  v1.28.15-gke.1287000  OK now, UNSUPPORTED with 1.32: 4 minor versions older
+
+Resources using APIs removed by 1.32:
+KIND        NAMESPACE/NAME  API                                   REMOVED  REPLACEMENT                      SOURCE
+FlowSchema  /custom-limits  flowcontrol.apiserver.k8s.io/v1beta3  1.32     flowcontrol.apiserver.k8s.io/v1  field manager helm
+
+Summary: 1 nodes must be upgraded before the control plane; 1 uses of removed APIs.
+`
+
+type upgradeReadinessArgs struct {
+	TargetVersion string `json:"target_version,omitempty"`
+	ProjectID     string `json:"project_id,omitempty"`
+	Location      string `json:"location,omitempty"`
+	ClusterName   string `json:"cluster_name,omitempty"`
+}
+
+// maxKubeletSkew is the number of minor versions kubelets may lag behind the
+// control plane.
+const maxKubeletSkew = 3
+
+// removedAPI is a version of a resource that was removed from Kubernetes.
+type removedAPI struct {
+	groupVersion string
+	resource     string
+	// removedIn is the minor version removing the API, e.g. "1.25".
+	removedIn string
+	// replacement is the group and version to migrate to, or empty if the
+	// resource was removed altogether.
+	replacement string
+}
+
+// removedAPIs are the APIs removed from Kubernetes, from the deprecated API
+// migration guide.
+var removedAPIs = []removedAPI{
+	{"extensions/v1beta1", "deployments", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "daemonsets", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "replicasets", "1.16", "apps/v1"},
+	{"extensions/v1beta1", "networkpolicies", "1.16", "networking.k8s.io/v1"},
+	{"apps/v1beta1", "deployments", "1.16", "apps/v1"},
+	{"apps/v1beta1", "statefulsets", "1.16", "apps/v1"},
+	{"apps/v1beta2", "deployments", "1.16", "apps/v1"},
+	{"apps/v1beta2", "statefulsets", "1.16", "apps/v1"},
+	{"apps/v1beta2", "daemonsets", "1.16", "apps/v1"},
+	{"apps/v1beta2", "replicasets", "1.16", "apps/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "mutatingwebhookconfigurations", "1.22", "admissionregistration.k8s.io/v1"},
+	{"admissionregistration.k8s.io/v1beta1", "validatingwebhookconfigurations", "1.22", "admissionregistration.k8s.io/v1"},
+	{"apiextensions.k8s.io/v1beta1", "customresourcedefinitions", "1.22", "apiextensions.k8s.io/v1"},
+	{"apiregistration.k8s.io/v1beta1", "apiservices", "1.22", "apiregistration.k8s.io/v1"},
+	{"certificates.k8s.io/v1beta1", "certificatesigningrequests", "1.22", "certificates.k8s.io/v1"},
+	{"coordination.k8s.io/v1beta1", "leases", "1.22", "coordination.k8s.io/v1"},
+	{"extensions/v1beta1", "ingresses", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "ingresses", "1.22", "networking.k8s.io/v1"},
+	{"networking.k8s.io/v1beta1", "ingressclasses", "1.22", "networking.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "clusterroles", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "clusterrolebindings", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "roles", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"rbac.authorization.k8s.io/v1beta1", "rolebindings", "1.22", "rbac.authorization.k8s.io/v1"},
+	{"scheduling.k8s.io/v1beta1", "priorityclasses", "1.22", "scheduling.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "csidrivers", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "csinodes", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "storageclasses", "1.22", "storage.k8s.io/v1"},
+	{"storage.k8s.io/v1beta1", "volumeattachments", "1.22", "storage.k8s.io/v1"},
+	{"batch/v1beta1", "cronjobs", "1.25", "batch/v1"},
+	{"discovery.k8s.io/v1beta1", "endpointslices", "1.25", "discovery.k8s.io/v1"},
+	{"events.k8s.io/v1beta1", "events", "1.25", "events.k8s.io/v1"},
+	{"autoscaling/v2beta1", "horizontalpodautoscalers", "1.25", "autoscaling/v2"},
+	{"policy/v1beta1", "poddisruptionbudgets", "1.25", "policy/v1"},
+	{"policy/v1beta1", "podsecuritypolicies", "1.25", ""},
+	{"node.k8s.io/v1beta1", "runtimeclasses", "1.25", "node.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "flowschemas", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta1", "prioritylevelconfigurations", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+	{"autoscaling/v2beta2", "horizontalpodautoscalers", "1.26", "autoscaling/v2"},
+	{"storage.k8s.io/v1beta1", "csistoragecapacities", "1.27", "storage.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "flowschemas", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta2", "prioritylevelconfigurations", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "flowschemas", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+	{"flowcontrol.apiserver.k8s.io/v1beta3", "prioritylevelconfigurations", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// removedAPIsUpTo returns the APIs removed in versions newer than current,
+// up to and including target.
+func removedAPIsUpTo(current, target *version.Version) []removedAPI {
+	var removed []removedAPI
+	for _, api := range removedAPIs {
+		v := version.MustParseGeneric(api.removedIn)
+		if v.GreaterThan(majorMinor(current)) && !v.GreaterThan(majorMinor(target)) {
+			removed = append(removed, api)
+		}
+	}
+	return removed
+}
+
+// majorMinor returns the major and minor components of v.
+func majorMinor(v *version.Version) *version.Version {
+	return version.MajorMinor(v.Major(), v.Minor())
+}
+
+// minorSkew returns the number of minor versions a is newer than b, negative
+// if a is older.
+func minorSkew(a, b *version.Version) int {
+	return int(a.Minor()) - int(b.Minor()) + 100*(int(a.Major())-int(b.Major()))
+}
+
+// kubeletSkew returns the skew status of a kubelet against the control plane
+// and the target version, and whether the kubelet must be upgraded before
+// the control plane.
+func kubeletSkew(kubelet, controlPlane, target *version.Version) (string, bool) {
+	var now string
+	switch skew := minorSkew(kubelet, controlPlane); {
+	case skew > 0:
+		now = fmt.Sprintf("UNSUPPORTED now: %d minor versions newer than the control plane", skew)
+	case -skew > maxKubeletSkew:
+		now = fmt.Sprintf("UNSUPPORTED now: %d minor versions older", -skew)
+	default:
+		now = "OK now"
+	}
+	if skew := minorSkew(target, kubelet); skew > maxKubeletSkew {
+		return fmt.Sprintf("%s, UNSUPPORTED with %s: %d minor versions older", now, majorMinor(target), skew), true
+	}
+	return now, false
+}
+
+// clientGoVersion returns the version of the client-go module the server is
+// built with, or an empty string if unknown.
+func clientGoVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "k8s.io/client-go" {
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+// clientKubernetesVersion returns the Kubernetes version matching a
+// client-go version: client-go v0.X.Y is released with Kubernetes 1.X.Y.
+func clientKubernetesVersion(clientGo string) (*version.Version, error) {
+	v, err := version.ParseGeneric(clientGo)
+	if err != nil {
+		return nil, err
+	}
+	return version.MajorMinor(1, v.Minor()), nil
+}
+
+// removedAPIUse is the use of a removed API by a resource or a client.
+type removedAPIUse struct {
+	kind, name string
+	api        removedAPI
+	source     string
+}
+
+// removedAPIUses returns the uses of the removed APIs apis recorded in obj,
+// of type resource: the API versions of its field managers and of its last
+// applied configuration.
+func removedAPIUses(obj *unstructured.Unstructured, resource string, apis []removedAPI) []removedAPIUse {
+	name := obj.GetNamespace() + "/" + obj.GetName()
+	var uses []removedAPIUse
+	for _, api := range apis {
+		if api.resource != resource {
+			continue
+		}
+		var managers []string
+		for _, f := range obj.GetManagedFields() {
+			if f.APIVersion == api.groupVersion && !slices.Contains(managers, f.Manager) {
+				managers = append(managers, f.Manager)
+			}
+		}
+		for _, m := range managers {
+			uses = append(uses, removedAPIUse{kind: obj.GetKind(), name: name, api: api, source: "field manager " + m})
+		}
+		if lastApplied, ok := obj.GetAnnotations()[lastAppliedAnnotation]; ok {
+			var applied struct {
+				APIVersion string `json:"apiVersion"`
+			}
+			if err := json.Unmarshal([]byte(lastApplied), &applied); err == nil && applied.APIVersion == api.groupVersion {
+				uses = append(uses, removedAPIUse{kind: obj.GetKind(), name: name, api: api, source: "last applied configuration"})
+			}
+		}
+	}
+	return uses
+}
+
+// deprecatedAPIMetricRe matches a sample of the apiserver_requested_deprecated_apis
+// metric, capturing its labels.
+var deprecatedAPIMetricRe = regexp.MustCompile(`^apiserver_requested_deprecated_apis\{(.*)\}`)
+
+// metricLabelRe matches a label of a Prometheus sample.
+var metricLabelRe = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// requestedRemovedAPIs returns the removed APIs of apis requested by clients
+// according to the metrics body of the API server.
+func requestedRemovedAPIs(body string, apis []removedAPI) []removedAPI {
+	var requested []removedAPI
+	for _, line := range filterMetrics(body, []string{"apiserver_requested_deprecated_apis"}) {
+		m := deprecatedAPIMetricRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		labels := map[string]string{}
+		for _, l := range metricLabelRe.FindAllStringSubmatch(m[1], -1) {
+			labels[l[1]] = l[2]
+		}
+		gv := schema.GroupVersion{Group: labels["group"], Version: labels["version"]}.String()
+		for _, api := range apis {
+			if api.groupVersion == gv && api.resource == labels["resource"] && !slices.Contains(requested, api) {
+				requested = append(requested, api)
+			}
+		}
+	}
+	return requested
+}
+
+// upgradeTarget returns the target version of args, and a description of
+// where it comes from.
+func (h *handlers) upgradeTarget(ctx context.Context, args *upgradeReadinessArgs, controlPlane *version.Version) (*version.Version, string, error) {
+	if args.TargetVersion != "" {
+		target, err := version.ParseGeneric(args.TargetVersion)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid target_version %q: %w", args.TargetVersion, err)
+		}
+		return target, "", nil
+	}
+	if args.ClusterName != "" && args.Location != "" {
+		projectID := args.ProjectID
+		if projectID == "" {
+			projectID = h.c.DefaultProjectID()
+		}
+		name := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, args.Location, args.ClusterName)
+		info, err := h.containerService.Projects.Locations.Clusters.FetchClusterUpgradeInfo(name).Context(ctx).Do()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to fetch cluster upgrade info: %w", err)
+		}
+		for _, v := range []string{info.MinorTargetVersion, info.PatchTargetVersion} {
+			if v == "" {
+				continue
+			}
+			target, err := version.ParseGeneric(v)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid target version %q of cluster %s: %w", v, args.ClusterName, err)
+			}
+			return target, "next automatic upgrade of the GKE cluster", nil
+		}
+	}
+	return majorMinor(controlPlane).AddMinor(1), "next minor version", nil
+}
+
+func (h *handlers) upgradeReadiness(ctx context.Context, _ *mcp.CallToolRequest, args *upgradeReadinessArgs) (*mcp.CallToolResult, any, error) {
+	serverVersion, err := h.dc.ServerVersion()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get server version: %w", err)
+	}
+	controlPlane, err := version.ParseGeneric(serverVersion.GitVersion)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse server version %q: %w", serverVersion.GitVersion, err)
+	}
+	target, source, err := h.upgradeTarget(ctx, args, controlPlane)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var output strings.Builder
+	var problems []string
+	output.WriteString(fmt.Sprintf("Control plane: %s\n", serverVersion.GitVersion))
+	if clientGo := clientGoVersion(); clientGo != "" {
+		output.WriteString(fmt.Sprintf("Client: client-go %s", clientGo))
+		if client, err := clientKubernetesVersion(clientGo); err == nil {
+			output.WriteString(fmt.Sprintf(" (Kubernetes %s)", client))
+			if skew := minorSkew(target, client); skew > 1 || skew < -1 {
+				output.WriteString(fmt.Sprintf(", UNSUPPORTED with %s: clients are supported within one minor version of the control plane", majorMinor(target)))
+				problems = append(problems, "the client of the server is too old for the target version")
+			}
+		}
+		output.WriteString("\n")
+	}
+	output.WriteString(fmt.Sprintf("Target: %s", target))
+	if source != "" {
+		output.WriteString(" (" + source + ")")
+	}
+	output.WriteString("\n")
+	switch skew := minorSkew(target, controlPlane); {
+	case skew < 0:
+		output.WriteString("The target is older than the control plane: Kubernetes doesn't support downgrading the control plane across minor versions.\n")
+		problems = append(problems, "the target version is a downgrade")
+	case skew > 1:
+		output.WriteString(fmt.Sprintf("The control plane is upgraded one minor version at a time: the upgrade to %s goes through %d intermediate versions, and the checks below are for the final version.\n", majorMinor(target), skew-1))
+	}
+
+	nodes, err := h.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	output.WriteString("\n")
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tKUBELET\tSTATUS")
+	var blocking, unsupported int
+	for _, node := range nodes.Items {
+		kubeletVersion := node.Status.NodeInfo.KubeletVersion
+		kubelet, err := version.ParseGeneric(kubeletVersion)
+		if err != nil {
+			fmt.Fprintf(w, "%s\t%s\tunknown version\n", node.Name, valueOrNone(kubeletVersion))
+			continue
+		}
+		status, blocks := kubeletSkew(kubelet, controlPlane, target)
+		if strings.HasPrefix(status, "UNSUPPORTED now") {
+			unsupported++
+		}
+		if blocks {
+			blocking++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", node.Name, kubeletVersion, status)
+	}
+	w.Flush()
+	if unsupported > 0 {
+		problems = append(problems, fmt.Sprintf("%d nodes have an unsupported version skew now", unsupported))
+	}
+	if blocking > 0 {
+		problems = append(problems, fmt.Sprintf("%d nodes must be upgraded before the control plane", blocking))
+	}
+
+	apis := removedAPIsUpTo(controlPlane, target)
+	var uses []removedAPIUse
+	listed := map[string]bool{}
+	for _, api := range apis {
+		if api.replacement == "" || listed[api.replacement+"/"+api.resource] {
+			continue
+		}
+		listed[api.replacement+"/"+api.resource] = true
+		gv, err := schema.ParseGroupVersion(api.replacement)
+		if err != nil {
+			return nil, nil, err
+		}
+		gvr := gv.WithResource(api.resource)
+		list, err := h.dyn.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+		}
+		for i := range list.Items {
+			uses = append(uses, removedAPIUses(&list.Items[i], api.resource, apis)...)
+		}
+	}
+	if body, err := h.dc.RESTClient().Get().AbsPath("/metrics").DoRaw(ctx); err == nil {
+		for _, api := range requestedRemovedAPIs(string(body), apis) {
+			uses = append(uses, removedAPIUse{kind: api.resource, api: api, source: "client requests (apiserver_requested_deprecated_apis)"})
+		}
+	}
+	sort.SliceStable(uses, func(i, j int) bool {
+		if uses[i].kind != uses[j].kind {
+			return uses[i].kind < uses[j].kind
+		}
+		return uses[i].name < uses[j].name
+	})
+
+	if len(uses) == 0 {
+		output.WriteString(fmt.Sprintf("\nNo resources or clients use APIs removed by %s.\n", majorMinor(target)))
+	} else {
+		output.WriteString(fmt.Sprintf("\nResources using APIs removed by %s:\n", majorMinor(target)))
+		w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KIND\tNAMESPACE/NAME\tAPI\tREMOVED\tREPLACEMENT\tSOURCE")
+		for _, u := range uses {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", u.kind, valueOrNone(u.name), u.api.groupVersion, u.api.removedIn, valueOrNone(u.api.replacement), u.source)
+		}
+		w.Flush()
+		problems = append(problems, fmt.Sprintf("%d uses of removed APIs", len(uses)))
+	}
+
+	if len(problems) == 0 {
+		output.WriteString(fmt.Sprintf("\nSummary: the cluster is ready to upgrade to %s.", majorMinor(target)))
+	} else {
+		output.WriteString("\nSummary: " + strings.Join(problems, "; ") + ".")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
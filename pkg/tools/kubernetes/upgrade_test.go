@@ -0,0 +1,117 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+func TestKubeletSkew(t *testing.T) {
+	controlPlane := version.MustParseGeneric("v1.31.5-gke.1014001")
+	target := version.MustParseGeneric("1.32")
+	for _, tc := range []struct {
+		kubelet    string
+		wantStatus string
+		wantBlocks bool
+	}{
+		{kubelet: "v1.31.5-gke.1014001", wantStatus: "OK now"},
+		{kubelet: "v1.29.1", wantStatus: "OK now"},
+		{kubelet: "v1.28.15-gke.1287000", wantStatus: "OK now, UNSUPPORTED with 1.32: 4 minor versions older", wantBlocks: true},
+		{kubelet: "v1.27.3", wantStatus: "UNSUPPORTED now: 4 minor versions older, UNSUPPORTED with 1.32: 5 minor versions older", wantBlocks: true},
+		{kubelet: "v1.32.0", wantStatus: "UNSUPPORTED now: 1 minor versions newer than the control plane"},
+	} {
+		status, blocks := kubeletSkew(version.MustParseGeneric(tc.kubelet), controlPlane, target)
+		if status != tc.wantStatus || blocks != tc.wantBlocks {
+			t.Errorf("kubeletSkew(%s) = %q, %v, want %q, %v", tc.kubelet, status, blocks, tc.wantStatus, tc.wantBlocks)
+		}
+	}
+}
+
+func TestRemovedAPIsUpTo(t *testing.T) {
+	var got []string
+	for _, api := range removedAPIsUpTo(version.MustParseGeneric("v1.28.3"), version.MustParseGeneric("1.32.1")) {
+		got = append(got, api.groupVersion+"/"+api.resource)
+	}
+	want := []string{
+		"flowcontrol.apiserver.k8s.io/v1beta2/flowschemas",
+		"flowcontrol.apiserver.k8s.io/v1beta2/prioritylevelconfigurations",
+		"flowcontrol.apiserver.k8s.io/v1beta3/flowschemas",
+		"flowcontrol.apiserver.k8s.io/v1beta3/prioritylevelconfigurations",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("removedAPIsUpTo() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRemovedAPIUses(t *testing.T) {
+	obj := decodeObject(t, `
+apiVersion: flowcontrol.apiserver.k8s.io/v1
+kind: FlowSchema
+metadata:
+  name: custom-limits
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{"apiVersion":"flowcontrol.apiserver.k8s.io/v1beta3","kind":"FlowSchema"}'
+  managedFields:
+  - manager: helm
+    operation: Update
+    apiVersion: flowcontrol.apiserver.k8s.io/v1beta3
+  - manager: helm
+    operation: Update
+    apiVersion: flowcontrol.apiserver.k8s.io/v1beta3
+  - manager: api-priority-and-fairness-config-consumer-v1
+    operation: Update
+    apiVersion: flowcontrol.apiserver.k8s.io/v1
+`)
+	apis := removedAPIsUpTo(version.MustParseGeneric("1.31"), version.MustParseGeneric("1.32"))
+	var got []string
+	for _, u := range removedAPIUses(obj, "flowschemas", apis) {
+		got = append(got, u.kind+" "+u.name+" "+u.api.groupVersion+" "+u.source)
+	}
+	want := []string{
+		"FlowSchema /custom-limits flowcontrol.apiserver.k8s.io/v1beta3 field manager helm",
+		"FlowSchema /custom-limits flowcontrol.apiserver.k8s.io/v1beta3 last applied configuration",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("removedAPIUses() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRequestedRemovedAPIs(t *testing.T) {
+	body := `# HELP apiserver_requested_deprecated_apis [STABLE] Gauge of deprecated APIs that have been requested, broken out by API group, version, resource, subresource, and removed_release.
+# TYPE apiserver_requested_deprecated_apis gauge
+apiserver_requested_deprecated_apis{group="flowcontrol.apiserver.k8s.io",removed_release="1.32",resource="flowschemas",subresource="",version="v1beta3"} 1
+apiserver_requested_deprecated_apis{group="flowcontrol.apiserver.k8s.io",removed_release="1.32",resource="flowschemas",subresource="status",version="v1beta3"} 1
+apiserver_requested_deprecated_apis{group="",removed_release="",resource="componentstatuses",subresource="",version="v1"} 1
+`
+	apis := removedAPIsUpTo(version.MustParseGeneric("1.31"), version.MustParseGeneric("1.32"))
+	got := requestedRemovedAPIs(body, apis)
+	want := []removedAPI{{"flowcontrol.apiserver.k8s.io/v1beta3", "flowschemas", "1.32", "flowcontrol.apiserver.k8s.io/v1"}}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(removedAPI{})); diff != "" {
+		t.Errorf("requestedRemovedAPIs() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientKubernetesVersion(t *testing.T) {
+	got, err := clientKubernetesVersion("v0.34.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != "1.34" {
+		t.Errorf("clientKubernetesVersion(v0.34.2) = %s, want 1.34", got)
+	}
+}
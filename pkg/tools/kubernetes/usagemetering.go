@@ -0,0 +1,313 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/container/v1"
+)
+
+// GKEUsageReportToolDescription contains the documentation for the GKE Usage Report tool.
+// It is formatted in Markdown.
+const GKEUsageReportToolDescription = `
+This tool reports the resource usage of the namespaces of a GKE cluster over the last days, from the BigQuery tables exported by GKE usage metering. Use it to answer historical questions such as "which team used the most CPU last month?", which the live metrics of *kube_top* can't answer.
+
+GKE usage metering must be enabled on the cluster, e.g. with *gke_enable_usage_metering*: GKE then exports the resource requests of the pods to the *gke_cluster_resource_usage* table of the BigQuery dataset, and, with consumption metering, their actual usage to the *gke_cluster_resource_consumption* table. The tables are updated every hour, and only cover the time since metering was enabled.
+
+## Arguments
+
+* *cluster_name*: The name of the cluster.
+* *location*: (Optional) The region or zone of the cluster. Defaults to the default location.
+* *project_id*: (Optional) The project of the cluster and of the dataset. Defaults to the default project.
+* *dataset*: (Optional) The BigQuery dataset of usage metering. Defaults to the dataset of the cluster.
+* *namespace*: (Optional) Only report the usage of this namespace.
+* *days*: (Optional) The number of days to report. Defaults to 7, at most 90.
+* *consumption*: (Optional) Set to true to report the actual usage of the pods, from consumption metering, rather than their requests.
+
+## Response Format
+
+One row per namespace and resource, with the total usage and the average usage over the period. CPU and GPU usage are in core-hours and cores, memory and storage in GiB-hours and GiB, and network egress in GiB:
+
+Requests of cluster web in the last 7 days, from dataset gke_usage:
+
+NAMESPACE  RESOURCE  TOTAL              AVERAGE
+checkout   cpu       672.0 core-hours   4.00 cores
+checkout   memory    1344.0 GiB-hours   8.00 GiB
+`
+
+// GKEEnableUsageMeteringToolDescription contains the documentation for the GKE Enable Usage Metering tool.
+// It is formatted in Markdown.
+const GKEEnableUsageMeteringToolDescription = `
+This tool enables, or disables, GKE usage metering on a cluster, like *gcloud container clusters update --resource-usage-bigquery-dataset*, and returns the long-running operation updating it. GKE then exports the resource requests, and optionally the actual usage, of the pods of each namespace to a BigQuery dataset every hour: query them with *gke_usage_report*.
+
+The dataset must exist in the project of the cluster, e.g. created with *bq mk gke_usage*. BigQuery bills the storage of the exported tables and the queries on them.
+
+## Arguments
+
+* *cluster_name*: The name of the cluster.
+* *dataset*: The BigQuery dataset to export to. Required unless *disable* is set.
+* *location*: (Optional) The region or zone of the cluster. Defaults to the default location.
+* *project_id*: (Optional) The project of the cluster and of the dataset. Defaults to the default project.
+* *enable_consumption_metering*: (Optional) Whether the actual usage of the pods is exported too, besides their requests. Defaults to true.
+* *enable_network_egress_metering*: (Optional) Set to true to export the network egress of the pods, which deploys a network metering agent on the nodes.
+* *disable*: (Optional) Set to true to disable usage metering. The exported tables are kept.
+
+## Response Format
+
+The operation: follow it with *gke_get_operation*.
+
+Started enabling usage metering on cluster web: operation projects/my-project/locations/us-central1/operations/operation-123 is RUNNING. Follow it with gke_get_operation.
+`
+
+type gkeUsageReportArgs struct {
+	ProjectID   string `json:"project_id,omitempty"`
+	Location    string `json:"location,omitempty"`
+	ClusterName string `json:"cluster_name"`
+	Dataset     string `json:"dataset,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Days        int    `json:"days,omitempty"`
+	Consumption bool   `json:"consumption,omitempty"`
+}
+
+type gkeEnableUsageMeteringArgs struct {
+	ProjectID                   string `json:"project_id,omitempty"`
+	Location                    string `json:"location,omitempty"`
+	ClusterName                 string `json:"cluster_name"`
+	Dataset                     string `json:"dataset,omitempty"`
+	EnableConsumptionMetering   *bool  `json:"enable_consumption_metering,omitempty"`
+	EnableNetworkEgressMetering bool   `json:"enable_network_egress_metering,omitempty"`
+	Disable                     bool   `json:"disable,omitempty"`
+}
+
+const (
+	defaultUsageDays = 7
+	maxUsageDays     = 90
+	// usageQueryTimeoutMs is how long a usage query may run.
+	usageQueryTimeoutMs = 60000
+	// The tables of usage metering: the requests and the actual usage.
+	usageTable       = "gke_cluster_resource_usage"
+	consumptionTable = "gke_cluster_resource_consumption"
+)
+
+// datasetIDPattern matches the IDs of BigQuery datasets.
+var datasetIDPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// projectIDPattern matches the IDs of Google Cloud projects: 6 to 30
+// lowercase letters, digits and hyphens, starting with a letter, optionally
+// prefixed with the domain of legacy domain-scoped projects.
+var projectIDPattern = regexp.MustCompile(`^([a-z0-9][a-z0-9.-]*[a-z0-9]:)?[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+
+// usageQuery returns the query of the total usage per namespace and resource
+// of a cluster in table, for the parameters cluster, location, days and, if
+// namespace is set, namespace.
+func usageQuery(table string, namespace bool) string {
+	query := fmt.Sprintf("SELECT namespace, resource_name, usage.unit AS unit, SUM(usage.amount) AS amount\n"+
+		"FROM `%s`\n"+
+		"WHERE cluster_name = @cluster AND cluster_location = @location\n"+
+		"  AND start_time >= TIMESTAMP_SUB(CURRENT_TIMESTAMP(), INTERVAL @days DAY)\n", table)
+	if namespace {
+		query += "  AND namespace = @namespace\n"
+	}
+	return query + "GROUP BY 1, 2, 3\nORDER BY 1, 2"
+}
+
+// queryParameter returns the named query parameter name of type typ.
+func queryParameter(name, typ, value string) *bigquery.QueryParameter {
+	return &bigquery.QueryParameter{
+		Name:           name,
+		ParameterType:  &bigquery.QueryParameterType{Type: typ},
+		ParameterValue: &bigquery.QueryParameterValue{Value: value},
+	}
+}
+
+// formatUsage formats the total amount in unit used in days days, and its
+// average over them.
+func formatUsage(unit string, amount float64, days int) (total, average string) {
+	seconds := float64(days) * 24 * 3600
+	switch unit {
+	case "seconds":
+		return fmt.Sprintf("%.1f core-hours", amount/3600), fmt.Sprintf("%.2f cores", amount/seconds)
+	case "byte-seconds":
+		return fmt.Sprintf("%.1f GiB-hours", amount/(1<<30)/3600), fmt.Sprintf("%.2f GiB", amount/(1<<30)/seconds)
+	case "bytes":
+		return fmt.Sprintf("%.1f GiB", amount/(1<<30)), "-"
+	}
+	return fmt.Sprintf("%g %s", amount, unit), "-"
+}
+
+// usageTableRows formats the rows of the result of usageQuery.
+func usageTableRows(out *strings.Builder, rows []*bigquery.TableRow, days int) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tRESOURCE\tTOTAL\tAVERAGE")
+	for _, row := range rows {
+		if len(row.F) != 4 {
+			return fmt.Errorf("unexpected usage row with %d columns", len(row.F))
+		}
+		var cells [4]string
+		for i, cell := range row.F {
+			if cell.V != nil {
+				cells[i] = fmt.Sprint(cell.V)
+			}
+		}
+		amount, err := strconv.ParseFloat(cells[3], 64)
+		if err != nil {
+			return fmt.Errorf("invalid usage amount %q: %w", cells[3], err)
+		}
+		namespace := cells[0]
+		if namespace == "" {
+			namespace = "-"
+		}
+		total, average := formatUsage(cells[2], amount, days)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", namespace, cells[1], total, average)
+	}
+	return w.Flush()
+}
+
+func (h *handlers) gkeUsageReport(ctx context.Context, _ *mcp.CallToolRequest, args *gkeUsageReportArgs) (*mcp.CallToolResult, any, error) {
+	if args.ClusterName == "" {
+		return nil, nil, fmt.Errorf("cluster_name is required")
+	}
+	projectID, location := args.ProjectID, args.Location
+	if projectID == "" {
+		projectID = h.c.DefaultProjectID()
+	}
+	// The project is part of the table of the query, which can't be a
+	// parameter.
+	if !projectIDPattern.MatchString(projectID) {
+		return nil, nil, fmt.Errorf("invalid project_id %q", projectID)
+	}
+	if location == "" {
+		location = h.c.DefaultLocation()
+	}
+	if location == "" {
+		return nil, nil, fmt.Errorf("location is required: there is no default location")
+	}
+	days := defaultUsageDays
+	if args.Days > 0 {
+		days = args.Days
+	}
+	if days > maxUsageDays {
+		return nil, nil, fmt.Errorf("days must be at most %d", maxUsageDays)
+	}
+
+	dataset := args.Dataset
+	if dataset == "" {
+		cluster, err := h.getCluster(ctx, projectID, location, args.ClusterName, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if c := cluster.ResourceUsageExportConfig; c != nil && c.BigqueryDestination != nil {
+			dataset = c.BigqueryDestination.DatasetId
+		}
+		if dataset == "" {
+			return nil, nil, fmt.Errorf("usage metering isn't enabled on cluster %s: enable it with gke_enable_usage_metering", args.ClusterName)
+		}
+	}
+	if !datasetIDPattern.MatchString(dataset) {
+		return nil, nil, fmt.Errorf("invalid dataset %q", dataset)
+	}
+	table, what := usageTable, "Requests"
+	if args.Consumption {
+		table, what = consumptionTable, "Actual usage"
+	}
+
+	useLegacySQL := false
+	req := &bigquery.QueryRequest{
+		Query:         usageQuery(fmt.Sprintf("%s.%s.%s", projectID, dataset, table), args.Namespace != ""),
+		UseLegacySql:  &useLegacySQL,
+		ParameterMode: "NAMED",
+		QueryParameters: []*bigquery.QueryParameter{
+			queryParameter("cluster", "STRING", args.ClusterName),
+			queryParameter("location", "STRING", location),
+			queryParameter("days", "INT64", strconv.Itoa(days)),
+		},
+		TimeoutMs: usageQueryTimeoutMs,
+	}
+	if args.Namespace != "" {
+		req.QueryParameters = append(req.QueryParameters, queryParameter("namespace", "STRING", args.Namespace))
+	}
+	resp, err := h.bigqueryService.Jobs.Query(projectID, req).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query table %s of dataset %s: %w", table, dataset, err)
+	}
+	if !resp.JobComplete {
+		return nil, nil, fmt.Errorf("the usage query didn't complete in %ds", usageQueryTimeoutMs/1000)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%s of cluster %s in the last %d days, from dataset %s:\n\n", what, args.ClusterName, days, dataset))
+	if len(resp.Rows) == 0 {
+		output.WriteString("No usage found. GKE exports the usage every hour, from the time usage metering, or consumption metering for the actual usage, was enabled.\n")
+	} else if err := usageTableRows(&output, resp.Rows, days); err != nil {
+		return nil, nil, err
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+func (h *handlers) gkeEnableUsageMetering(ctx context.Context, _ *mcp.CallToolRequest, args *gkeEnableUsageMeteringArgs) (*mcp.CallToolResult, any, error) {
+	if args.ClusterName == "" {
+		return nil, nil, fmt.Errorf("cluster_name is required")
+	}
+	projectID, location := args.ProjectID, args.Location
+	if projectID == "" {
+		projectID = h.c.DefaultProjectID()
+	}
+	if location == "" {
+		location = h.c.DefaultLocation()
+	}
+	if location == "" {
+		return nil, nil, fmt.Errorf("location is required: there is no default location")
+	}
+
+	// An empty configuration disables usage metering.
+	config := &container.ResourceUsageExportConfig{}
+	what := "disabling usage metering on cluster " + args.ClusterName
+	if !args.Disable {
+		if !datasetIDPattern.MatchString(args.Dataset) {
+			return nil, nil, fmt.Errorf("invalid dataset %q: give the ID of a BigQuery dataset", args.Dataset)
+		}
+		if _, err := h.bigqueryService.Datasets.Get(projectID, args.Dataset).Context(ctx).Do(); err != nil {
+			return nil, nil, fmt.Errorf("failed to get dataset %s of project %s, which must exist: %w", args.Dataset, projectID, err)
+		}
+		config.BigqueryDestination = &container.BigQueryDestination{DatasetId: args.Dataset}
+		config.ConsumptionMeteringConfig = &container.ConsumptionMeteringConfig{
+			Enabled:         args.EnableConsumptionMetering == nil || *args.EnableConsumptionMetering,
+			ForceSendFields: []string{"Enabled"},
+		}
+		config.EnableNetworkEgressMetering = args.EnableNetworkEgressMetering
+		what = "enabling usage metering on cluster " + args.ClusterName
+	}
+
+	name := fmt.Sprintf("projects/%s/locations/%s/clusters/%s", projectID, location, args.ClusterName)
+	req := &container.UpdateClusterRequest{Update: &container.ClusterUpdate{DesiredResourceUsageExportConfig: config}}
+	op, err := h.containerService.Projects.Locations.Clusters.Update(name, req).Context(ctx).Do()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update cluster: %w", err)
+	}
+	return operationResult(op, projectID, location, what), nil, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"google.golang.org/api/bigquery/v2"
+)
+
+func TestUsageQuery(t *testing.T) {
+	query := usageQuery("my-project.gke_usage.gke_cluster_resource_usage", true)
+	for _, want := range []string{"FROM `my-project.gke_usage.gke_cluster_resource_usage`", "cluster_name = @cluster", "INTERVAL @days DAY", "namespace = @namespace"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("usageQuery() = %q, want it to contain %q", query, want)
+		}
+	}
+	if query := usageQuery("t", false); strings.Contains(query, "@namespace") {
+		t.Errorf("usageQuery() = %q, want no namespace filter", query)
+	}
+}
+
+func TestProjectIDPattern(t *testing.T) {
+	for id, want := range map[string]bool{
+		"my-project":                 true,
+		"example.com:my-project":     true,
+		"proj":                       false,
+		"My-Project":                 false,
+		"my-project-":                false,
+		"1project":                   false,
+		"my-project`.x.y; DROP --":   false,
+		"my-project.gke_usage.other": false,
+	} {
+		if got := projectIDPattern.MatchString(id); got != want {
+			t.Errorf("projectIDPattern.MatchString(%q) = %t, want %t", id, got, want)
+		}
+	}
+}
+
+func TestGKEUsageReportInvalidProject(t *testing.T) {
+	h := &handlers{c: config.New("test", config.Options{})}
+	_, _, err := h.gkeUsageReport(context.Background(), nil, &gkeUsageReportArgs{
+		ProjectID:   "p`; SELECT 1; --",
+		Location:    "us-central1",
+		ClusterName: "web",
+		Dataset:     "gke_usage",
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid project_id") {
+		t.Errorf("gkeUsageReport() error = %v, want invalid project_id", err)
+	}
+}
+
+func TestUsageTableRows(t *testing.T) {
+	row := func(cells ...any) *bigquery.TableRow {
+		r := &bigquery.TableRow{}
+		for _, c := range cells {
+			r.F = append(r.F, &bigquery.TableCell{V: c})
+		}
+		return r
+	}
+	var out strings.Builder
+	err := usageTableRows(&out, []*bigquery.TableRow{
+		row("checkout", "cpu", "seconds", "2419200"),
+		row("checkout", "memory", "byte-seconds", "5195192441241600"),
+		row(nil, "networkEgress", "bytes", "1073741824"),
+	}, 7)
+	if err != nil {
+		t.Fatalf("usageTableRows() failed: %v", err)
+	}
+	want := `NAMESPACE  RESOURCE       TOTAL             AVERAGE
+checkout   cpu            672.0 core-hours  4.00 cores
+checkout   memory         1344.0 GiB-hours  8.00 GiB
+-          networkEgress  1.0 GiB           -
+`
+	if out.String() != want {
+		t.Errorf("usageTableRows() = %q, want %q", out.String(), want)
+	}
+
+	if err := usageTableRows(&out, []*bigquery.TableRow{row("checkout", "cpu", "seconds", "many")}, 7); err == nil {
+		t.Errorf("usageTableRows() with an invalid amount succeeded, want an error")
+	}
+}
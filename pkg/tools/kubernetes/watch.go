@@ -0,0 +1,247 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// WatchResourcesToolDescription contains the documentation for the Watch Kubernetes Resources tool.
+// It is formatted in Markdown.
+const WatchResourcesToolDescription = `
+This tool watches Kubernetes resources for a bounded amount of time and returns the changes that happened meanwhile. This is the equivalent of running *kubectl get pods --watch-only* for a number of seconds.
+
+Use it to answer questions like "what changes while I run this test?" or "do the pods of this deployment restart?". Only changes made after the watch starts are reported, not the current state of the resources.
+
+## Arguments
+
+* *resource*: The **plural, lowercase name** of the resource type (e.g., *pods*, *deployments*).
+* *namespace*: (Optional) The namespace to watch. Defaults to the server's default namespace for namespaced resource types.
+* *all_namespaces*: (Optional) Set to *true* to watch all namespaces.
+* *labelSelector*: (Optional) A Kubernetes label selector to filter the resources.
+* *fieldSelector*: (Optional) A Kubernetes field selector to filter the resources, e.g. *metadata.name=my-pod*.
+* *duration_seconds*: (Optional) How long to watch, at most 300 seconds. Defaults to 30 seconds. The watch also ends before the tool call times out.
+* *max_events*: (Optional) Stop after this many events. Defaults to 200.
+
+## Response Format
+
+A table with one row per event, in the order they happened:
+
+TIME                 TYPE      NAMESPACE  NAME          RESOURCE_VERSION
+2025-01-01T10:00:01Z ADDED     default    web-7d9-abcde 1234
+2025-01-01T10:00:03Z MODIFIED  default    web-7d9-abcde 1240
+2025-01-01T10:00:09Z DELETED   default    web-7d9-fghij 1251
+
+The response ends with a summary of the watch. If the client requested progress notifications, each event is also sent as a progress notification as soon as it happens.
+`
+
+type watchResourcesArgs struct {
+	Resource        string `json:"resource"`
+	Namespace       string `json:"namespace,omitempty"`
+	AllNamespaces   bool   `json:"all_namespaces,omitempty"`
+	LabelSelector   string `json:"labelSelector,omitempty"`
+	FieldSelector   string `json:"fieldSelector,omitempty"`
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+	MaxEvents       int    `json:"max_events,omitempty"`
+}
+
+const (
+	defaultWatchDuration  = 30 * time.Second
+	maxWatchDuration      = 5 * time.Minute
+	defaultMaxWatchEvents = 200
+	// watchDeadlineMargin is kept between the end of a watch and the
+	// deadline of the tool call, to leave time to return the events.
+	watchDeadlineMargin = time.Second
+)
+
+// watchEvent is a change observed by a watch.
+type watchEvent struct {
+	time            time.Time
+	eventType       watch.EventType
+	namespace       string
+	name            string
+	resourceVersion string
+}
+
+func (e watchEvent) String() string {
+	return fmt.Sprintf("%s\t%s\t%s\t%s\t%s", e.time.UTC().Format(time.RFC3339), e.eventType, e.namespace, e.name, e.resourceVersion)
+}
+
+func (h *handlers) watchResources(ctx context.Context, req *mcp.CallToolRequest, args *watchResourcesArgs) (*mcp.CallToolResult, any, error) {
+	gvr, err := h.findGVR(args.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+	namespaced, err := h.isNamespaced(gvr)
+	if err != nil {
+		return nil, nil, err
+	}
+	scope := clusterScope
+	var ri dynamic.ResourceInterface = h.dyn.Resource(gvr)
+	if namespaced {
+		namespace, err := h.namespaceScope(args.Namespace, args.AllNamespaces)
+		if err != nil {
+			return nil, nil, err
+		}
+		scope = describeScope(namespace)
+		ri = h.dyn.Resource(gvr).Namespace(namespace)
+	}
+
+	duration := defaultWatchDuration
+	if args.DurationSeconds > 0 {
+		duration = min(time.Duration(args.DurationSeconds)*time.Second, maxWatchDuration)
+	}
+	var shortened bool
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline) - watchDeadlineMargin; remaining < duration {
+			duration = max(remaining, 0)
+			shortened = true
+		}
+	}
+	maxEvents := args.MaxEvents
+	if maxEvents <= 0 {
+		maxEvents = defaultMaxWatchEvents
+	}
+
+	// Start from the current resource version so that only changes are
+	// reported, not the existing objects.
+	list, err := ri.List(ctx, metav1.ListOptions{
+		LabelSelector: args.LabelSelector,
+		FieldSelector: args.FieldSelector,
+		Limit:         1,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	var notify func(watchEvent)
+	if token := req.Params.GetProgressToken(); token != nil {
+		var count int
+		notify = func(e watchEvent) {
+			count++
+			// Progress notifications are best effort.
+			_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: token,
+				Progress:      float64(count),
+				Total:         float64(maxEvents),
+				Message:       e.String(),
+			})
+		}
+	}
+
+	watchCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+	start := func(resourceVersion string) (watch.Interface, error) {
+		return ri.Watch(watchCtx, metav1.ListOptions{
+			LabelSelector:   args.LabelSelector,
+			FieldSelector:   args.FieldSelector,
+			ResourceVersion: resourceVersion,
+		})
+	}
+	events, err := collectWatchEvents(watchCtx, start, list.GetResourceVersion(), maxEvents, notify)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var output strings.Builder
+	output.WriteString("TIME\tTYPE\tNAMESPACE\tNAME\tRESOURCE_VERSION\n")
+	for _, e := range events {
+		output.WriteString(e.String() + "\n")
+	}
+	output.WriteString(fmt.Sprintf("\nWatched %s in %s for %s: %d events.", args.Resource, scope, duration.Round(time.Second), len(events)))
+	if len(events) >= maxEvents {
+		output.WriteString(fmt.Sprintf(" Stopped after max_events (%d) events.", maxEvents))
+	}
+	if shortened {
+		output.WriteString(" The watch was shortened to end before the tool call times out.")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+// collectWatchEvents watches from resourceVersion until ctx is done or
+// maxEvents events are seen, restarting the watch if the server closes it.
+// notify, if not nil, is called for each event as it arrives.
+func collectWatchEvents(ctx context.Context, start func(resourceVersion string) (watch.Interface, error), resourceVersion string, maxEvents int, notify func(watchEvent)) ([]watchEvent, error) {
+	var events []watchEvent
+	for ctx.Err() == nil && len(events) < maxEvents {
+		w, err := start(resourceVersion)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return nil, fmt.Errorf("failed to watch resources: %w", err)
+		}
+		resourceVersion, events, err = drainWatch(ctx, w, resourceVersion, events, maxEvents, notify)
+		w.Stop()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// drainWatch appends the events of w to events until w is closed, ctx is
+// done or maxEvents events are seen. It returns the last resource version
+// seen.
+func drainWatch(ctx context.Context, w watch.Interface, resourceVersion string, events []watchEvent, maxEvents int, notify func(watchEvent)) (string, []watchEvent, error) {
+	for len(events) < maxEvents {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, events, nil
+		case ev, ok := <-w.ResultChan():
+			if !ok {
+				return resourceVersion, events, nil
+			}
+			switch ev.Type {
+			case watch.Error:
+				return "", nil, fmt.Errorf("watch failed: %w", apierrors.FromObject(ev.Object))
+			case watch.Bookmark:
+				continue
+			}
+			obj, ok := ev.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			resourceVersion = obj.GetResourceVersion()
+			e := watchEvent{
+				time:            time.Now(),
+				eventType:       ev.Type,
+				namespace:       obj.GetNamespace(),
+				name:            obj.GetName(),
+				resourceVersion: resourceVersion,
+			}
+			events = append(events, e)
+			if notify != nil {
+				notify(e)
+			}
+		}
+	}
+	return resourceVersion, events, nil
+}
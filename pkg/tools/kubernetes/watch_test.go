@@ -0,0 +1,73 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func watchedPod(name, resourceVersion string) *unstructured.Unstructured {
+	pod := newPod(name, "nginx")
+	pod.SetResourceVersion(resourceVersion)
+	return &pod
+}
+
+func TestCollectWatchEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The first watch is closed by the server after one event, and the
+	// second one must resume from the last resource version seen.
+	var startedAt []string
+	start := func(resourceVersion string) (watch.Interface, error) {
+		startedAt = append(startedAt, resourceVersion)
+		w := watch.NewFakeWithChanSize(2, false)
+		switch len(startedAt) {
+		case 1:
+			w.Add(watchedPod("web-1", "11"))
+			w.Stop()
+		default:
+			w.Modify(watchedPod("web-1", "12"))
+			w.Delete(watchedPod("web-1", "13"))
+		}
+		return w, nil
+	}
+	var notified int
+	events, err := collectWatchEvents(ctx, start, "10", 3, func(watchEvent) { notified++ })
+	if err != nil {
+		t.Fatalf("collectWatchEvents() error = %v", err)
+	}
+
+	var got []string
+	for _, e := range events {
+		got = append(got, string(e.eventType)+" "+e.name+" "+e.resourceVersion)
+	}
+	want := []string{"ADDED web-1 11", "MODIFIED web-1 12", "DELETED web-1 13"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("collectWatchEvents() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"10", "11"}, startedAt); diff != "" {
+		t.Errorf("watch resource versions mismatch (-want +got):\n%s", diff)
+	}
+	if notified != len(events) {
+		t.Errorf("notified %d events, want %d", notified, len(events))
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// WebhookDiagnosticsToolDescription contains the documentation for the Webhook Diagnostics Kubernetes tool.
+// It is formatted in Markdown.
+const WebhookDiagnosticsToolDescription = `
+This tool inspects the admission webhooks of the cluster, i.e. all ValidatingWebhookConfigurations and MutatingWebhookConfigurations. Broken admission webhooks can silently block the creation and update of resources: requests fail with errors such as *failed calling webhook* or *context deadline exceeded*.
+
+For each webhook, the tool reports:
+
+* its failure policy and timeout. Webhooks with *failurePolicy: Fail* reject requests when they can't be reached.
+* its target: the in-cluster service or the URL it is called at.
+* whether the backing service exists and has ready endpoints.
+* whether its CA bundle is present, can be parsed, and is not expired or about to expire.
+* whether its namespace selector matches the namespace being debugged, if one is given.
+
+## Arguments
+
+* *namespace*: (Optional) The namespace being debugged. If set, the tool lists the webhooks with *failurePolicy: Fail* that intercept requests in this namespace, and highlights the broken ones, which block requests in the namespace.
+
+## Response Format
+
+A table with one row per webhook, followed, if a namespace is given, by the webhooks that can block requests in it:
+
+TYPE        CONFIGURATION  WEBHOOK                FAILURE_POLICY  TIMEOUT  TARGET                  MATCHES_NAMESPACE  STATUS
+validating  gatekeeper     validation.gatekeeper  Fail            3s       gatekeeper-system/svc   true               service has no ready endpoints
+`
+
+type webhookDiagnosticsArgs struct {
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// caExpiryWarning is how long before expiry a CA certificate is reported.
+const caExpiryWarning = 30 * 24 * time.Hour
+
+// webhook is an admission webhook of either type.
+type webhook struct {
+	// kind is "validating" or "mutating".
+	kind              string
+	configuration     string
+	name              string
+	clientConfig      admissionregistrationv1.WebhookClientConfig
+	failurePolicy     admissionregistrationv1.FailurePolicyType
+	timeoutSeconds    int32
+	namespaceSelector *metav1.LabelSelector
+}
+
+func (w *webhook) target() string {
+	if svc := w.clientConfig.Service; svc != nil {
+		target := svc.Namespace + "/" + svc.Name
+		if svc.Port != nil {
+			target += fmt.Sprintf(":%d", *svc.Port)
+		}
+		if svc.Path != nil {
+			target += *svc.Path
+		}
+		return target
+	}
+	if w.clientConfig.URL != nil {
+		return *w.clientConfig.URL
+	}
+	return "<none>"
+}
+
+// listWebhooks returns the validating and mutating admission webhooks of
+// the cluster, with API defaults applied.
+func (h *handlers) listWebhooks(ctx context.Context) ([]webhook, error) {
+	validating, err := h.clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validating webhook configurations: %w", err)
+	}
+	mutating, err := h.clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutating webhook configurations: %w", err)
+	}
+
+	newWebhook := func(kind, configuration, name string, cc admissionregistrationv1.WebhookClientConfig, policy *admissionregistrationv1.FailurePolicyType, timeout *int32, selector *metav1.LabelSelector) webhook {
+		w := webhook{
+			kind:              kind,
+			configuration:     configuration,
+			name:              name,
+			clientConfig:      cc,
+			failurePolicy:     admissionregistrationv1.Fail,
+			timeoutSeconds:    10,
+			namespaceSelector: selector,
+		}
+		if policy != nil {
+			w.failurePolicy = *policy
+		}
+		if timeout != nil {
+			w.timeoutSeconds = *timeout
+		}
+		return w
+	}
+	var webhooks []webhook
+	for _, c := range validating.Items {
+		for _, w := range c.Webhooks {
+			webhooks = append(webhooks, newWebhook("validating", c.Name, w.Name, w.ClientConfig, w.FailurePolicy, w.TimeoutSeconds, w.NamespaceSelector))
+		}
+	}
+	for _, c := range mutating.Items {
+		for _, w := range c.Webhooks {
+			webhooks = append(webhooks, newWebhook("mutating", c.Name, w.Name, w.ClientConfig, w.FailurePolicy, w.TimeoutSeconds, w.NamespaceSelector))
+		}
+	}
+	return webhooks, nil
+}
+
+// caBundleProblem describes what is wrong with the PEM encoded caBundle at
+// now, or returns the empty string if nothing is.
+func caBundleProblem(caBundle []byte, now time.Time) string {
+	if len(caBundle) == 0 {
+		return "empty caBundle"
+	}
+	var problems []string
+	var certs int
+	for rest := caBundle; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid CA certificate: %v", err))
+			continue
+		}
+		certs++
+		switch {
+		case now.After(cert.NotAfter):
+			problems = append(problems, fmt.Sprintf("CA %q expired on %s", cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339)))
+		case now.Add(caExpiryWarning).After(cert.NotAfter):
+			problems = append(problems, fmt.Sprintf("CA %q expires on %s", cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339)))
+		case now.Before(cert.NotBefore):
+			problems = append(problems, fmt.Sprintf("CA %q is not valid before %s", cert.Subject.CommonName, cert.NotBefore.UTC().Format(time.RFC3339)))
+		}
+	}
+	if certs == 0 && len(problems) == 0 {
+		return "caBundle contains no PEM certificates"
+	}
+	return strings.Join(problems, ", ")
+}
+
+// webhookProblems returns what is wrong with w: its service and its CA
+// bundle.
+func (h *handlers) webhookProblems(ctx context.Context, w *webhook, now time.Time) ([]string, error) {
+	var problems []string
+	if svc := w.clientConfig.Service; svc != nil {
+		problem, err := h.serviceProblem(ctx, svc.Namespace, svc.Name)
+		if err != nil {
+			return nil, err
+		}
+		if problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	// Webhooks called by URL may be signed by a public CA, in which case
+	// the caBundle is empty.
+	if w.clientConfig.Service != nil || len(w.clientConfig.CABundle) > 0 {
+		if problem := caBundleProblem(w.clientConfig.CABundle, now); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+	return problems, nil
+}
+
+func (h *handlers) webhookDiagnostics(ctx context.Context, _ *mcp.CallToolRequest, args *webhookDiagnosticsArgs) (*mcp.CallToolResult, any, error) {
+	webhooks, err := h.listWebhooks(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var namespace *corev1.Namespace
+	if args.Namespace != "" {
+		namespace, err = h.clientset.CoreV1().Namespaces().Get(ctx, args.Namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get namespace: %w", err)
+		}
+	}
+
+	now := time.Now()
+	var output, blocking strings.Builder
+	output.WriteString("TYPE\tCONFIGURATION\tWEBHOOK\tFAILURE_POLICY\tTIMEOUT\tTARGET\tMATCHES_NAMESPACE\tSTATUS\n")
+	var intercepting int
+	for i := range webhooks {
+		w := &webhooks[i]
+		problems, err := h.webhookProblems(ctx, w, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		status := "OK"
+		if len(problems) > 0 {
+			status = strings.Join(problems, "; ")
+		}
+		matches := "-"
+		if namespace != nil {
+			selector, err := metav1.LabelSelectorAsSelector(w.namespaceSelector)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to parse namespace selector of webhook %q: %w", w.name, err)
+			}
+			// A nil selector matches everything, like an empty one.
+			if w.namespaceSelector == nil {
+				selector = labels.Everything()
+			}
+			matched := selector.Matches(labels.Set(namespace.Labels))
+			matches = fmt.Sprint(matched)
+			if matched && w.failurePolicy == admissionregistrationv1.Fail {
+				intercepting++
+				line := fmt.Sprintf("- %s/%s (%s)", w.configuration, w.name, w.kind)
+				if len(problems) > 0 {
+					line += ": BROKEN, blocks requests in the namespace: " + status
+				}
+				blocking.WriteString(line + "\n")
+			}
+		}
+		output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%ds\t%s\t%s\t%s\n", w.kind, w.configuration, w.name, w.failurePolicy, w.timeoutSeconds, w.target(), matches, status))
+	}
+
+	if namespace != nil {
+		output.WriteString(fmt.Sprintf("\n%d webhooks with failurePolicy=Fail intercept requests in namespace %q:\n", intercepting, namespace.Name))
+		output.WriteString(blocking.String())
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newCACert(t *testing.T, notBefore, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "webhook-ca"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCABundleProblem(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name     string
+		caBundle []byte
+		want     string
+	}{
+		{
+			name:     "valid",
+			caBundle: newCACert(t, now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0)),
+			want:     "",
+		},
+		{
+			name:     "expired",
+			caBundle: newCACert(t, now.AddDate(-1, 0, 0), now.AddDate(0, 0, -1)),
+			want:     `CA "webhook-ca" expired on 2025-05-31T00:00:00Z`,
+		},
+		{
+			name:     "expiring",
+			caBundle: newCACert(t, now.AddDate(-1, 0, 0), now.AddDate(0, 0, 7)),
+			want:     `CA "webhook-ca" expires on 2025-06-08T00:00:00Z`,
+		},
+		{
+			name: "empty",
+			want: "empty caBundle",
+		},
+		{
+			name:     "not PEM",
+			caBundle: []byte("not a certificate"),
+			want:     "caBundle contains no PEM certificates",
+		},
+	} {
+		if got := caBundleProblem(tc.caBundle, now); got != tc.want {
+			t.Errorf("%s: caBundleProblem() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package middleware wraps the handlers of tools in a chain of middleware,
+// such as timeouts, applied when the tools are added to a server. Unlike
+// the receiving middleware of the server, tool middleware knows the tool it
+// wraps, such as its annotations, and its decoded arguments.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Handler handles a call of a tool, with the decoded arguments of the call.
+type Handler func(ctx context.Context, req *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error)
+
+// Middleware returns a handler of tool wrapping next.
+type Middleware func(tool *mcp.Tool, next Handler) Handler
+
+// chains holds the middleware of each server, until the server is
+// forgotten.
+var chains sync.Map // *mcp.Server -> []Middleware
+
+// Use appends mw to the middleware of the tools added to s with AddTool. The
+// first middleware is the outermost. Tools already added are not affected.
+func Use(s *mcp.Server, mw ...Middleware) {
+	chain := append(chainOf(s), mw...)
+	chains.Store(s, chain)
+}
+
+// Forget drops the middleware of s, once s is closed and no tools are added
+// to it anymore.
+func Forget(s *mcp.Server) {
+	chains.Delete(s)
+}
+
+func chainOf(s *mcp.Server) []Middleware {
+	chain, _ := chains.Load(s)
+	mw, _ := chain.([]Middleware)
+	// Copy the chain, so that Use never modifies the chain of tools already
+	// added.
+	return append([]Middleware(nil), mw...)
+}
+
+// Wrap returns handler wrapped in mw, the first middleware being the
+// outermost.
+func Wrap(tool *mcp.Tool, handler Handler, mw ...Middleware) Handler {
+	for i := len(mw) - 1; i >= 0; i-- {
+		handler = mw[i](tool, handler)
+	}
+	return handler
+}
+
+// AddTool adds the tool t to s like mcp.AddTool, with its handler wrapped in
+// the middleware of s.
+func AddTool[In, Out any](s *mcp.Server, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) {
+	chain := chainOf(s)
+	if len(chain) == 0 {
+		mcp.AddTool(s, t, h)
+		return
+	}
+	handler := Wrap(t, func(ctx context.Context, req *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
+		return h(ctx, req, args.(In))
+	}, chain...)
+	mcp.AddTool(s, t, func(ctx context.Context, req *mcp.CallToolRequest, args In) (*mcp.CallToolResult, Out, error) {
+		res, out, err := handler(ctx, req, args)
+		typed, _ := out.(Out)
+		return res, typed, err
+	})
+}
+
+// Recover turns panics of the handler into tool errors, so that a bug in a
+// single tool doesn't terminate the server. The stack of the panic is logged.
+func Recover(tool *mcp.Tool, next Handler) Handler {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args any) (res *mcp.CallToolResult, out any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.ErrorContext(ctx, "Tool call panicked", "tool", tool.Name, "panic", r, "stack", string(debug.Stack()))
+				res, out, err = nil, nil, fmt.Errorf("internal error in tool %s: %v", tool.Name, r)
+			}
+		}()
+		return next(ctx, req, args)
+	}
+}
+
+// Mutating reports whether tool changes resources. Tools that change
+// resources are annotated with their destructive hint; tools without
+// annotations, or annotated as read-only, only read resources.
+func Mutating(tool *mcp.Tool) bool {
+	return tool.Annotations != nil && !tool.Annotations.ReadOnlyHint
+}
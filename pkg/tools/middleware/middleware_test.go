@@ -0,0 +1,120 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type echoArgs struct {
+	Message string `json:"message"`
+}
+
+type echoResult struct {
+	Echo string `json:"echo"`
+}
+
+// recordingMiddleware appends name and the tool name to calls around each
+// call.
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(tool *mcp.Tool, next Handler) Handler {
+		return func(ctx context.Context, req *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
+			*calls = append(*calls, name+" "+tool.Name)
+			res, out, err := next(ctx, req, args)
+			*calls = append(*calls, name+" done")
+			return res, out, err
+		}
+	}
+}
+
+func TestAddTool(t *testing.T) {
+	ctx := context.Background()
+	s := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	var calls []string
+	Use(s, recordingMiddleware("outer", &calls))
+	Use(s, recordingMiddleware("inner", &calls))
+	AddTool(s, &mcp.Tool{Name: "echo"}, func(_ context.Context, _ *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, echoResult, error) {
+		calls = append(calls, "echo "+args.Message)
+		return nil, echoResult{Echo: args.Message}, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := s.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "client"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"message": "hello"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]any{"echo": "hello"}, res.StructuredContent); diff != "" {
+		t.Errorf("structured content mismatch (-want +got):\n%s", diff)
+	}
+	want := []string{"outer echo", "inner echo", "echo hello", "inner done", "outer done"}
+	if diff := cmp.Diff(want, calls); diff != "" {
+		t.Errorf("calls mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestForget(t *testing.T) {
+	s := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	var calls []string
+	Use(s, recordingMiddleware("outer", &calls))
+	Forget(s)
+	if _, ok := chains.Load(s); ok {
+		t.Error("chains has the middleware of a forgotten server")
+	}
+	if n := len(chainOf(s)); n != 0 {
+		t.Errorf("chainOf() of a forgotten server has %d middleware, want 0", n)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	handler := Recover(&mcp.Tool{Name: "broken"}, func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+		var m map[string]int
+		m["boom"]++
+		return nil, nil, nil
+	})
+	_, _, err := handler(context.Background(), &mcp.CallToolRequest{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "internal error in tool broken: assignment to entry in nil map") {
+		t.Errorf("handler() error = %v, want the panic", err)
+	}
+}
+
+func TestMutating(t *testing.T) {
+	for _, tc := range []struct {
+		tool *mcp.Tool
+		want bool
+	}{
+		{tool: &mcp.Tool{Name: "kube_get_resources"}},
+		{tool: &mcp.Tool{Name: "kube_list", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}},
+		{tool: &mcp.Tool{Name: "kube_delete_resource", Annotations: &mcp.ToolAnnotations{}}, want: true},
+	} {
+		if got := Mutating(tc.tool); got != tc.want {
+			t.Errorf("Mutating(%s) = %t, want %t", tc.tool.Name, got, tc.want)
+		}
+	}
+}
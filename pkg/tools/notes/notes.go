@@ -0,0 +1,406 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package notes keeps the findings that the agent records while
+// troubleshooting and a journal of the tool calls of each session, so that
+// they survive long sessions and profile switches, and assembles them into
+// incident reports.
+package notes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/logging"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/middleware"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/utils/ptr"
+)
+
+// SessionNoteAddToolDescription contains the documentation for the Session Note Add tool.
+// It is formatted in Markdown.
+const SessionNoteAddToolDescription = `
+This tool records a finding of the current troubleshooting session, such as a symptom, a ruled-out hypothesis or a suspected cause, so that it isn't lost when the conversation grows long. Record a note whenever you learn something you will need later, and read them back with *session_note_list* before drawing conclusions or reporting to the user.
+
+Notes are kept in memory for the MCP session: they survive profile switches, and are dropped when the client disconnects or the server restarts.
+
+## Arguments
+
+* *note*: The finding, e.g. *web pods restart every 5 minutes with exit code 137*.
+* *cluster*: (Optional) The cluster the finding is about. Defaults to the active profile, or to the kubeconfig context of the server.
+* *namespace*: (Optional) The namespace the finding is about.
+* *suspected_cause*: (Optional) The suspected cause of the problem, e.g. *memory limit too low for the new release*.
+
+## Response Format
+
+The number of the note and the number of notes of the session:
+
+Recorded note 3 (3 notes in this session).
+`
+
+// SessionNoteListToolDescription contains the documentation for the Session Note List tool.
+// It is formatted in Markdown.
+const SessionNoteListToolDescription = `
+This tool lists the notes recorded with *session_note_add* in the current MCP session, oldest first. Use it to recall the findings of the session before deciding on the next step or summarizing the investigation.
+
+## Arguments
+
+* *cluster*: (Optional) Only list the notes about this cluster.
+* *namespace*: (Optional) Only list the notes about this namespace.
+
+## Response Format
+
+A table of the notes:
+
+#  TIME                  CLUSTER  NAMESPACE  NOTE                                 SUSPECTED_CAUSE
+1  2025-03-01T10:05:00Z  prod     shop       web pods restart with exit code 137  memory limit too low
+`
+
+type sessionNoteAddArgs struct {
+	Note           string `json:"note"`
+	Cluster        string `json:"cluster,omitempty"`
+	Namespace      string `json:"namespace,omitempty"`
+	SuspectedCause string `json:"suspected_cause,omitempty"`
+}
+
+type sessionNoteListArgs struct {
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+const (
+	// maxNotes bounds the number of notes kept per session.
+	maxNotes = 200
+	// maxCalls bounds the number of tool calls kept per session.
+	maxCalls = 500
+	// maxOutputBytes bounds the output kept for each tool call.
+	maxOutputBytes = 8 << 10
+)
+
+type note struct {
+	time           time.Time
+	cluster        string
+	namespace      string
+	text           string
+	suspectedCause string
+}
+
+// call is a tool call recorded in the journal of a session.
+type call struct {
+	time     time.Time
+	duration time.Duration
+	tool     string
+	// args are the arguments of the call as redacted JSON.
+	args    string
+	isError bool
+	// output is the redacted text of the result, or the error of the call,
+	// truncated to maxOutputBytes.
+	output string
+}
+
+// session holds the notes and the tool calls of an MCP session, oldest
+// first.
+type session struct {
+	notes []note
+	calls []call
+}
+
+// Store keeps the notes and the journal of the tool calls of each MCP
+// session. It is created once per server so that they survive the
+// reinstallation of the tools by profile switches.
+type Store struct {
+	mu sync.Mutex
+	// sessions are the sessions by ID.
+	sessions map[string]*session
+}
+
+func NewStore() *Store {
+	return &Store{sessions: map[string]*session{}}
+}
+
+// Install adds the session note and report tools to s. They record the notes
+// in n, and default their cluster to the active profile or kubeconfig
+// context of c.
+func (n *Store) Install(ctx context.Context, s *mcp.Server, c *config.Config) error {
+	h := &handlers{store: n, c: c, cluster: defaultCluster(c)}
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "session_note_add",
+		Description: SessionNoteAddToolDescription,
+	}, h.sessionNoteAdd)
+
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "session_note_list",
+		Description: SessionNoteListToolDescription,
+	}, h.sessionNoteList)
+
+	// Reports written to a destination are files written on the host of the
+	// server, or uploaded to Cloud Storage, so they are only returned in
+	// read-only mode.
+	if !c.ReadOnly() {
+		middleware.AddTool(s, &mcp.Tool{
+			Name:        "generate_incident_report",
+			Description: GenerateIncidentReportToolDescription,
+			Annotations: &mcp.ToolAnnotations{DestructiveHint: ptr.To(false)},
+		}, h.generateIncidentReport)
+	} else {
+		middleware.AddTool(s, &mcp.Tool{
+			Name:        "generate_incident_report",
+			Description: GenerateIncidentReportToolDescription,
+		}, h.generateIncidentReportReadOnly)
+	}
+
+	return nil
+}
+
+// defaultCluster returns the cluster of the notes that don't name one.
+func defaultCluster(c *config.Config) string {
+	if c.Profile() != "" {
+		return c.Profile()
+	}
+	return c.KubeContext()
+}
+
+// session returns the session id, creating it on first use. Sessions created
+// for ss are dropped when ss ends. n.mu must be held.
+func (n *Store) session(id string, ss *mcp.ServerSession) *session {
+	s, ok := n.sessions[id]
+	if !ok {
+		s = &session{}
+		n.sessions[id] = s
+		if ss != nil {
+			go func() {
+				ss.Wait()
+				n.forget(id)
+			}()
+		}
+	}
+	return s
+}
+
+// add records nt in the session id of ss, and returns the number of notes of
+// the session. The oldest notes are dropped beyond maxNotes.
+func (n *Store) add(id string, ss *mcp.ServerSession, nt note) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s := n.session(id, ss)
+	s.notes = append(s.notes, nt)
+	if len(s.notes) > maxNotes {
+		s.notes = s.notes[len(s.notes)-maxNotes:]
+	}
+	return len(s.notes)
+}
+
+// addCall records c in the journal of the session id of ss. The oldest
+// calls are dropped beyond maxCalls.
+func (n *Store) addCall(id string, ss *mcp.ServerSession, c call) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	s := n.session(id, ss)
+	s.calls = append(s.calls, c)
+	if len(s.calls) > maxCalls {
+		s.calls = s.calls[len(s.calls)-maxCalls:]
+	}
+}
+
+// list returns the notes of the session id.
+func (n *Store) list(id string) []note {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if s, ok := n.sessions[id]; ok {
+		return append([]note(nil), s.notes...)
+	}
+	return nil
+}
+
+// calls returns the journal of the tool calls of the session id.
+func (n *Store) calls(id string) []call {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if s, ok := n.sessions[id]; ok {
+		return append([]call(nil), s.calls...)
+	}
+	return nil
+}
+
+// forget drops the notes and the calls of the session id.
+func (n *Store) forget(id string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.sessions, id)
+}
+
+// unjournaledTools are the tools whose calls aren't recorded in the journal:
+// the notes are reported as findings, not as calls.
+var unjournaledTools = map[string]bool{
+	"session_note_add":         true,
+	"session_note_list":        true,
+	"generate_incident_report": true,
+}
+
+// Journal is a tool middleware recording the calls of the tools, with their
+// redacted arguments and output, in the session journal of n, from which
+// generate_incident_report builds the timeline of incidents.
+func (n *Store) Journal(tool *mcp.Tool, next middleware.Handler) middleware.Handler {
+	if unjournaledTools[tool.Name] {
+		return next
+	}
+	return func(ctx context.Context, req *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
+		start := time.Now()
+		res, out, err := next(ctx, req, args)
+		c := call{time: start, duration: time.Since(start), tool: tool.Name, args: "{}"}
+		if data, jsonErr := json.Marshal(args); jsonErr == nil {
+			c.args = logging.Redact(string(data))
+		}
+		switch {
+		case err != nil:
+			c.isError, c.output = true, err.Error()
+		case res != nil:
+			c.isError, c.output = res.IsError, resultText(res)
+		}
+		c.output = truncate(logging.Redact(c.output), maxOutputBytes)
+		n.addCall(sessionID(req), serverSession(req), c)
+		return res, out, err
+	}
+}
+
+// resultText returns the text content of res.
+func resultText(res *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range res.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// truncate returns s cut to at most max bytes, on a rune boundary.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "\n... (truncated)"
+}
+
+type handlers struct {
+	store   *Store
+	c       *config.Config
+	cluster string
+}
+
+// sessionID returns the ID of the session of req. Requests without a session,
+// and stdio sessions, which have no ID, share the empty ID.
+func sessionID(req *mcp.CallToolRequest) string {
+	if ss := serverSession(req); ss != nil {
+		return ss.ID()
+	}
+	return ""
+}
+
+// serverSession returns the session of req, or nil.
+func serverSession(req *mcp.CallToolRequest) *mcp.ServerSession {
+	if req == nil {
+		return nil
+	}
+	return req.Session
+}
+
+func (h *handlers) sessionNoteAdd(ctx context.Context, req *mcp.CallToolRequest, args *sessionNoteAddArgs) (*mcp.CallToolResult, any, error) {
+	text := strings.TrimSpace(args.Note)
+	if text == "" {
+		return nil, nil, errors.New("note is required")
+	}
+	cluster := args.Cluster
+	if cluster == "" {
+		cluster = h.cluster
+	}
+	count := h.store.add(sessionID(req), serverSession(req), note{
+		time:           time.Now(),
+		cluster:        cluster,
+		namespace:      args.Namespace,
+		text:           text,
+		suspectedCause: strings.TrimSpace(args.SuspectedCause),
+	})
+
+	noun := "notes"
+	if count == 1 {
+		noun = "note"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Recorded note %d (%d %s in this session).\n", count, count, noun)},
+		},
+	}, nil, nil
+}
+
+// formatNotes formats the notes of list matching the cluster and namespace,
+// numbered in the order they were recorded.
+func formatNotes(list []note, cluster, namespace string) string {
+	var output strings.Builder
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTIME\tCLUSTER\tNAMESPACE\tNOTE\tSUSPECTED_CAUSE")
+	matches := 0
+	for i, nt := range list {
+		if cluster != "" && nt.cluster != cluster {
+			continue
+		}
+		if namespace != "" && nt.namespace != namespace {
+			continue
+		}
+		matches++
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", i+1, nt.time.UTC().Format(time.RFC3339),
+			valueOrNone(nt.cluster), valueOrNone(nt.namespace), oneLine(nt.text), valueOrNone(oneLine(nt.suspectedCause)))
+	}
+	w.Flush()
+	switch {
+	case len(list) == 0:
+		return "No notes recorded in this session.\n"
+	case matches == 0:
+		return "No notes of this session match the cluster and namespace.\n"
+	}
+	return output.String()
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// oneLine joins the lines of s so that it fits in a table cell.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func (h *handlers) sessionNoteList(ctx context.Context, req *mcp.CallToolRequest, args *sessionNoteListArgs) (*mcp.CallToolResult, any, error) {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: formatNotes(h.store.list(sessionID(req)), args.Cluster, args.Namespace)},
+		},
+	}, nil, nil
+}
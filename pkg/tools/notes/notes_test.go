@@ -0,0 +1,119 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestStore(t *testing.T) {
+	n := NewStore()
+	at := time.Date(2025, 3, 1, 10, 5, 0, 0, time.UTC)
+	if got := n.add("a", nil, note{time: at, cluster: "prod", text: "first"}); got != 1 {
+		t.Errorf("add() = %d, want 1", got)
+	}
+	n.add("b", nil, note{time: at, cluster: "dev", text: "other session"})
+	if got := n.add("a", nil, note{time: at, cluster: "prod", text: "second"}); got != 2 {
+		t.Errorf("add() = %d, want 2", got)
+	}
+	if got := len(n.list("a")); got != 2 {
+		t.Errorf("len(list(a)) = %d, want 2", got)
+	}
+	n.forget("a")
+	if got := len(n.list("a")); got != 0 {
+		t.Errorf("len(list(a)) after forget = %d, want 0", got)
+	}
+	if got := len(n.list("b")); got != 1 {
+		t.Errorf("len(list(b)) = %d, want 1", got)
+	}
+
+	for i := range maxNotes + 5 {
+		n.add("c", nil, note{text: fmt.Sprint(i)})
+	}
+	list := n.list("c")
+	if len(list) != maxNotes || list[0].text != "5" {
+		t.Errorf("list(c) has %d notes starting at %q, want %d starting at \"5\"", len(list), list[0].text, maxNotes)
+	}
+}
+
+func TestFormatNotes(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 5, 0, 0, time.UTC)
+	list := []note{
+		{time: at, cluster: "prod", namespace: "shop", text: "web pods restart\nwith exit code 137", suspectedCause: "memory limit too low"},
+		{time: at.Add(time.Minute), cluster: "prod", text: "node-1 is under memory pressure"},
+		{time: at.Add(2 * time.Minute), cluster: "dev", namespace: "shop", text: "dev is healthy"},
+	}
+
+	want := `#  TIME                  CLUSTER  NAMESPACE  NOTE                                 SUSPECTED_CAUSE
+1  2025-03-01T10:05:00Z  prod     shop       web pods restart with exit code 137  memory limit too low
+2  2025-03-01T10:06:00Z  prod     <none>     node-1 is under memory pressure      <none>
+`
+	if diff := cmp.Diff(want, formatNotes(list, "prod", "")); diff != "" {
+		t.Errorf("formatNotes(prod) mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := formatNotes(list, "staging", ""), "No notes of this session match the cluster and namespace.\n"; got != want {
+		t.Errorf("formatNotes(staging) = %q, want %q", got, want)
+	}
+	if got, want := formatNotes(nil, "", ""), "No notes recorded in this session.\n"; got != want {
+		t.Errorf("formatNotes(nil) = %q, want %q", got, want)
+	}
+}
+
+func TestJournal(t *testing.T) {
+	n := NewStore()
+	type getArgs struct {
+		Name  string `json:"name"`
+		Token string `json:"token"`
+	}
+	ok := func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("web-1 Running\n", maxOutputBytes/10)}}}, nil, nil
+	}
+	failed := func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+		return nil, nil, errors.New("pods \"web\" not found")
+	}
+	for _, tc := range []struct {
+		tool    string
+		handler func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error)
+	}{
+		{tool: "kube_get_resource", handler: ok},
+		{tool: "session_note_add", handler: ok},
+		{tool: "kube_get_pod_logs", handler: failed},
+	} {
+		handler := n.Journal(&mcp.Tool{Name: tc.tool}, tc.handler)
+		handler(context.Background(), &mcp.CallToolRequest{}, &getArgs{Name: "web", Token: "s3cr3t"})
+	}
+
+	calls := n.calls("")
+	if len(calls) != 2 {
+		t.Fatalf("calls() = %d calls, want 2", len(calls))
+	}
+	if got, want := calls[0].args, `{"name":"web","token":"[REDACTED]"}`; got != want {
+		t.Errorf("calls()[0].args = %s, want %s", got, want)
+	}
+	if got := calls[0].output; calls[0].isError || !strings.HasSuffix(got, "\n... (truncated)") || len(got) > maxOutputBytes+len("\n... (truncated)") {
+		t.Errorf("calls()[0] = %+v, want a truncated successful output", calls[0])
+	}
+	if got := calls[1]; got.tool != "kube_get_pod_logs" || !got.isError || got.output != `pods "web" not found` {
+		t.Errorf("calls()[1] = %+v, want the failed kube_get_pod_logs call", got)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notes
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/output"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GenerateIncidentReportToolDescription contains the documentation for the Generate Incident Report tool.
+// It is formatted in Markdown.
+const GenerateIncidentReportToolDescription = `
+This tool assembles the current MCP session into the skeleton of a Markdown postmortem: a timeline of the tool calls and of the notes recorded with *session_note_add*, the findings and suspected causes, placeholders for the summary and the remediation, and the outputs of selected tool calls. Arguments and outputs are redacted.
+
+Call it when the investigation is over, then fill in the placeholders with the user, or hand the report over as is. To select outputs, first generate the report without them: its timeline numbers the tool calls.
+
+## Arguments
+
+* *title*: (Optional) The title of the incident, e.g. *Checkout errors after the 2.3 release*.
+* *since*: (Optional) Only report the tool calls and notes of this period, as a duration such as *2h*. Defaults to the whole session.
+* *include_outputs*: (Optional) The numbers of the tool calls, as numbered in the timeline, whose outputs are included in the report, e.g. *[3, 7]*.
+* *destination*: (Optional) Also write the report to this new file, as a path relative to the output directory of the server, or to this Cloud Storage object, as *gs://BUCKET/OBJECT*, in a bucket allowed by the server. Existing files are never overwritten. Not available in read-only mode.
+
+## Response Format
+
+The report, preceded by where it was written if a destination was given:
+
+# Incident Report: Checkout errors after the 2.3 release
+
+* Generated: 2025-03-01T11:00:00Z
+* Cluster: prod
+* Investigation: 2025-03-01T10:05:00Z to 2025-03-01T10:40:00Z, 12 tool calls, 3 notes
+
+## Summary
+...
+## Timeline
+...
+## Findings
+...
+## Root Cause
+...
+## Remediation
+...
+## Tool Outputs
+...
+`
+
+type generateIncidentReportArgs struct {
+	Title          string `json:"title,omitempty"`
+	Since          string `json:"since,omitempty"`
+	IncludeOutputs []int  `json:"include_outputs,omitempty"`
+	Destination    string `json:"destination,omitempty"`
+}
+
+// generateIncidentReportReadOnlyArgs are the arguments of the
+// generate_incident_report tool in read-only mode, where reports are only
+// returned.
+type generateIncidentReportReadOnlyArgs struct {
+	Title          string `json:"title,omitempty"`
+	Since          string `json:"since,omitempty"`
+	IncludeOutputs []int  `json:"include_outputs,omitempty"`
+}
+
+// maxTimelineArgs bounds the length of the arguments of a call in the
+// timeline.
+const maxTimelineArgs = 200
+
+// incidentReport is the content of an incident report.
+type incidentReport struct {
+	title   string
+	cluster string
+	now     time.Time
+	// since is the start of the reported period. The calls and notes made
+	// before are left out, but the calls keep their number in the session.
+	since time.Time
+	calls []call
+	notes []note
+	// outputs are the numbers of the calls whose outputs are included.
+	outputs []int
+}
+
+// newIncidentReport returns the report of the calls and notes of a session
+// made since since, with the outputs of the calls numbered outputs.
+func newIncidentReport(args *generateIncidentReportArgs, cluster string, calls []call, notes []note, now time.Time) (*incidentReport, error) {
+	var since time.Time
+	if args.Since != "" {
+		d, err := time.ParseDuration(args.Since)
+		if err != nil {
+			return nil, fmt.Errorf("invalid since duration %q: %w", args.Since, err)
+		}
+		since = now.Add(-d)
+	}
+	for _, number := range args.IncludeOutputs {
+		if number < 1 || number > len(calls) {
+			return nil, fmt.Errorf("invalid tool call number %d: the session has %d tool calls", number, len(calls))
+		}
+	}
+	return &incidentReport{
+		title:   args.Title,
+		cluster: cluster,
+		now:     now,
+		since:   since,
+		calls:   calls,
+		notes:   notes,
+		outputs: args.IncludeOutputs,
+	}, nil
+}
+
+// timelineEvent is a tool call or a note of the timeline.
+type timelineEvent struct {
+	time  time.Time
+	event string
+}
+
+func (r *incidentReport) String() string {
+	var out strings.Builder
+	title := r.title
+	if title == "" {
+		title = "Untitled incident"
+	}
+	out.WriteString("# Incident Report: " + title + "\n\n")
+	out.WriteString("* Generated: " + r.now.UTC().Format(time.RFC3339) + "\n")
+	if r.cluster != "" {
+		out.WriteString("* Cluster: " + r.cluster + "\n")
+	}
+
+	var notes []note
+	for _, nt := range r.notes {
+		if !nt.time.Before(r.since) {
+			notes = append(notes, nt)
+		}
+	}
+	var events []timelineEvent
+	calls := 0
+	for i, c := range r.calls {
+		if c.time.Before(r.since) {
+			continue
+		}
+		calls++
+		event := fmt.Sprintf("%d. Called `%s` with `%s`", i+1, c.tool, tableCell(truncateLine(c.args, maxTimelineArgs)))
+		if c.isError {
+			event += ": **failed**"
+		}
+		events = append(events, timelineEvent{time: c.time, event: event})
+	}
+	for _, nt := range notes {
+		event := "Note (" + noteScope(nt) + "): " + tableCell(nt.text)
+		if nt.suspectedCause != "" {
+			event += " Suspected cause: " + tableCell(nt.suspectedCause)
+		}
+		events = append(events, timelineEvent{time: nt.time, event: event})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].time.Before(events[j].time) })
+	if len(events) > 0 {
+		out.WriteString(fmt.Sprintf("* Investigation: %s to %s, %d tool calls, %d notes\n",
+			events[0].time.UTC().Format(time.RFC3339), events[len(events)-1].time.UTC().Format(time.RFC3339), calls, len(notes)))
+	}
+
+	out.WriteString("\n## Summary\n\n_TODO: What happened, its impact on users, and how long it lasted._\n")
+
+	out.WriteString("\n## Timeline\n\n")
+	if len(events) == 0 {
+		out.WriteString("_No tool calls or notes were recorded in this session._\n")
+	} else {
+		out.WriteString("| Time (UTC) | Event |\n|---|---|\n")
+		for _, e := range events {
+			out.WriteString(fmt.Sprintf("| %s | %s |\n", e.time.UTC().Format(time.RFC3339), e.event))
+		}
+	}
+
+	out.WriteString("\n## Findings\n\n")
+	if len(notes) == 0 {
+		out.WriteString("_No findings were recorded with session_note_add._\n")
+	}
+	var causes []string
+	for _, nt := range notes {
+		out.WriteString(fmt.Sprintf("* **%s**: %s\n", noteScope(nt), oneLine(nt.text)))
+		if nt.suspectedCause != "" && !slices.Contains(causes, nt.suspectedCause) {
+			causes = append(causes, nt.suspectedCause)
+		}
+	}
+
+	out.WriteString("\n## Root Cause\n\n")
+	if len(causes) == 0 {
+		out.WriteString("_TODO: The cause of the incident._\n")
+	} else {
+		out.WriteString("Suspected causes recorded during the investigation:\n\n")
+		for _, cause := range causes {
+			out.WriteString("* " + oneLine(cause) + "\n")
+		}
+		out.WriteString("\n_TODO: Confirm the root cause._\n")
+	}
+
+	out.WriteString("\n## Remediation\n\n_TODO: The changes that mitigated the incident._\n\n### Follow-up Actions\n\n_TODO: The actions that will prevent the incident from happening again._\n")
+
+	if len(r.outputs) > 0 {
+		out.WriteString("\n## Tool Outputs\n")
+		for _, number := range r.outputs {
+			c := r.calls[number-1]
+			out.WriteString(fmt.Sprintf("\n### %d. %s (%s)\n\n", number, c.tool, c.time.UTC().Format(time.RFC3339)))
+			fence := codeFence(c.output)
+			out.WriteString(fence + "text\n" + strings.TrimRight(c.output, "\n") + "\n" + fence + "\n")
+		}
+	}
+	return out.String()
+}
+
+// noteScope returns the cluster and namespace of nt, as cluster/namespace.
+func noteScope(nt note) string {
+	scope := valueOrNone(nt.cluster)
+	if nt.namespace != "" {
+		scope += "/" + nt.namespace
+	}
+	return scope
+}
+
+// tableCell returns s on one line, with the pipes escaped, so that it fits
+// in a cell of a Markdown table.
+func tableCell(s string) string {
+	return strings.ReplaceAll(oneLine(s), "|", `\|`)
+}
+
+// truncateLine returns s cut to at most max bytes, with an ellipsis.
+func truncateLine(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.TrimSuffix(truncate(s, max), "\n... (truncated)") + "..."
+}
+
+// codeFence returns a fence of backticks longer than any run of backticks of
+// s, so that s can't close the code block.
+func codeFence(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+func (h *handlers) generateIncidentReport(ctx context.Context, req *mcp.CallToolRequest, args *generateIncidentReportArgs) (*mcp.CallToolResult, any, error) {
+	if args.Destination != "" {
+		if err := output.Check(h.c, args.Destination); err != nil {
+			return nil, nil, err
+		}
+	}
+	id := sessionID(req)
+	report, err := newIncidentReport(args, h.cluster, h.store.calls(id), h.store.list(id), time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+	text := report.String()
+	if args.Destination != "" {
+		written, err := output.Write(ctx, h.c, args.Destination, []byte(text), "text/markdown")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to write report: %w", err)
+		}
+		text = fmt.Sprintf("Report written to %s.\n\n%s", written, text)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, nil, nil
+}
+
+func (h *handlers) generateIncidentReportReadOnly(ctx context.Context, req *mcp.CallToolRequest, args *generateIncidentReportReadOnlyArgs) (*mcp.CallToolResult, any, error) {
+	return h.generateIncidentReport(ctx, req, &generateIncidentReportArgs{
+		Title:          args.Title,
+		Since:          args.Since,
+		IncludeOutputs: args.IncludeOutputs,
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestIncidentReport(t *testing.T) {
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	calls := []call{
+		{time: start, tool: "kube_cluster_health", args: "{}", output: "all good"},
+		{time: start.Add(5 * time.Minute), tool: "kube_get_resources", args: `{"kind":"Pod","namespace":"shop"}`, output: "NAME   STATUS\nweb-1  CrashLoopBackOff"},
+		{time: start.Add(7 * time.Minute), tool: "kube_get_pod_logs", args: `{"pod":"web-1","container":"a|b"}`, isError: true, output: "log with ``` fence"},
+	}
+	notes := []note{
+		{time: start.Add(6 * time.Minute), cluster: "prod", namespace: "shop", text: "web-1 is OOMKilled", suspectedCause: "memory limit too low"},
+		{time: start.Add(8 * time.Minute), cluster: "prod", text: "nodes are healthy"},
+	}
+	args := &generateIncidentReportArgs{Title: "Checkout errors", Since: "10m", IncludeOutputs: []int{2, 3}}
+	report, err := newIncidentReport(args, "prod", calls, notes, start.Add(12*time.Minute))
+	if err != nil {
+		t.Fatalf("newIncidentReport() failed: %v", err)
+	}
+
+	want := "# Incident Report: Checkout errors\n" +
+		"\n" +
+		"* Generated: 2025-03-01T10:12:00Z\n" +
+		"* Cluster: prod\n" +
+		"* Investigation: 2025-03-01T10:05:00Z to 2025-03-01T10:08:00Z, 2 tool calls, 2 notes\n" +
+		"\n" +
+		"## Summary\n" +
+		"\n" +
+		"_TODO: What happened, its impact on users, and how long it lasted._\n" +
+		"\n" +
+		"## Timeline\n" +
+		"\n" +
+		"| Time (UTC) | Event |\n" +
+		"|---|---|\n" +
+		"| 2025-03-01T10:05:00Z | 2. Called `kube_get_resources` with `{\"kind\":\"Pod\",\"namespace\":\"shop\"}` |\n" +
+		"| 2025-03-01T10:06:00Z | Note (prod/shop): web-1 is OOMKilled Suspected cause: memory limit too low |\n" +
+		"| 2025-03-01T10:07:00Z | 3. Called `kube_get_pod_logs` with `{\"pod\":\"web-1\",\"container\":\"a\\|b\"}`: **failed** |\n" +
+		"| 2025-03-01T10:08:00Z | Note (prod): nodes are healthy |\n" +
+		"\n" +
+		"## Findings\n" +
+		"\n" +
+		"* **prod/shop**: web-1 is OOMKilled\n" +
+		"* **prod**: nodes are healthy\n" +
+		"\n" +
+		"## Root Cause\n" +
+		"\n" +
+		"Suspected causes recorded during the investigation:\n" +
+		"\n" +
+		"* memory limit too low\n" +
+		"\n" +
+		"_TODO: Confirm the root cause._\n" +
+		"\n" +
+		"## Remediation\n" +
+		"\n" +
+		"_TODO: The changes that mitigated the incident._\n" +
+		"\n" +
+		"### Follow-up Actions\n" +
+		"\n" +
+		"_TODO: The actions that will prevent the incident from happening again._\n" +
+		"\n" +
+		"## Tool Outputs\n" +
+		"\n" +
+		"### 2. kube_get_resources (2025-03-01T10:05:00Z)\n" +
+		"\n" +
+		"```text\n" +
+		"NAME   STATUS\n" +
+		"web-1  CrashLoopBackOff\n" +
+		"```\n" +
+		"\n" +
+		"### 3. kube_get_pod_logs (2025-03-01T10:07:00Z)\n" +
+		"\n" +
+		"````text\n" +
+		"log with ``` fence\n" +
+		"````\n"
+	if diff := cmp.Diff(want, report.String()); diff != "" {
+		t.Errorf("report mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := newIncidentReport(&generateIncidentReportArgs{IncludeOutputs: []int{4}}, "prod", calls, notes, start); err == nil {
+		t.Errorf("newIncidentReport(include_outputs=[4]) succeeded, want error")
+	}
+}
+
+func TestIncidentReportEmptySession(t *testing.T) {
+	report, err := newIncidentReport(&generateIncidentReportArgs{}, "", nil, nil, time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("newIncidentReport() failed: %v", err)
+	}
+	got := report.String()
+	for _, want := range []string{"# Incident Report: Untitled incident\n", "_No tool calls or notes were recorded in this session._\n", "_No findings were recorded with session_note_add._\n", "_TODO: The cause of the incident._\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestGenerateIncidentReportDestination(t *testing.T) {
+	dir := t.TempDir()
+	h := &handlers{store: NewStore(), c: config.New("test", config.Options{OutputDir: dir})}
+	ctx := context.Background()
+
+	if _, _, err := h.generateIncidentReport(ctx, &mcp.CallToolRequest{}, &generateIncidentReportArgs{Destination: "report.md"}); err != nil {
+		t.Fatalf("generateIncidentReport() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "report.md")); err != nil {
+		t.Errorf("report wasn't written to the output directory: %v", err)
+	}
+	for _, destination := range []string{"report.md", "/tmp/report.md", "../report.md", "gs://bucket/report.md"} {
+		if _, _, err := h.generateIncidentReport(ctx, &mcp.CallToolRequest{}, &generateIncidentReportArgs{Destination: destination}); err == nil {
+			t.Errorf("generateIncidentReport(destination=%q) succeeded, want error", destination)
+		}
+	}
+}
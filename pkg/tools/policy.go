@@ -0,0 +1,135 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/logging"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/policy"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/kubernetes"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/middleware"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// policyMiddleware applies p to every tool call: denied calls fail, and calls
+// requiring confirmation run once the user confirms them, as asked by the
+// client. active returns the configuration of the active profile, which
+// provides the default cluster, namespace and project of the calls.
+func policyMiddleware(p *policy.Policy, active func() *config.Config) middleware.Middleware {
+	return func(tool *mcp.Tool, next middleware.Handler) middleware.Handler {
+		mutating := middleware.Mutating(tool)
+		return func(ctx context.Context, req *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
+			in := policyInput(tool.Name, mutating, args, active())
+			decision := p.Evaluate(ctx, in)
+			switch decision.Effect {
+			case policy.EffectDeny:
+				slog.InfoContext(ctx, "Tool call denied by policy", "tool", tool.Name, "rule", decision.Rule)
+				return policyResult(fmt.Sprintf("The call of %s is denied by %s.", tool.Name, decisionSource(decision)), decision,
+					"Don't retry the call or work around the policy: tell the user, who can ask an administrator of the server."), nil, nil
+			case policy.EffectConfirm:
+				confirmed, err := confirmCall(ctx, req, tool.Name, in, decision)
+				if err != nil {
+					return policyResult(fmt.Sprintf("The call of %s requires the confirmation of the user by %s, but the client can't ask for it: %v.", tool.Name, decisionSource(decision), err), decision,
+						"Tell the user to make the change themselves, or to use a client supporting elicitation."), nil, nil
+				}
+				if !confirmed {
+					return policyResult(fmt.Sprintf("The user didn't confirm the call of %s, required by %s.", tool.Name, decisionSource(decision)), decision,
+						"Don't retry the call unless the user asks for it."), nil, nil
+				}
+			}
+			return next(ctx, req, args)
+		}
+	}
+}
+
+// policyInput describes the call of tool with args for the policy. The
+// cluster and project arguments of the call default to the settings of c,
+// and its target namespaces to the default namespace of c or of its
+// kubeconfig context.
+func policyInput(tool string, mutating bool, args any, c *config.Config) *policy.Input {
+	in := &policy.Input{
+		Tool:     tool,
+		Args:     map[string]any{},
+		Mutating: mutating,
+		Cluster:  c.KubeContext(),
+		Profile:  c.Profile(),
+		Project:  c.DefaultProjectID(),
+	}
+	if data, err := json.Marshal(args); err == nil {
+		json.Unmarshal(data, &in.Args)
+	}
+	in.Namespaces = kubernetes.TargetNamespaces(tool, in.Args, kubernetes.DefaultNamespace(c))
+	in.Namespace = in.Namespaces[0]
+	if cluster, ok := in.Args["cluster_name"].(string); ok && cluster != "" {
+		in.Cluster = cluster
+	}
+	if project, ok := in.Args["project_id"].(string); ok && project != "" {
+		in.Project = project
+	}
+	return in
+}
+
+// decisionSource names the rule of decision.
+func decisionSource(decision policy.Decision) string {
+	switch decision.Rule {
+	case "":
+		return "the default policy of the server"
+	case "opa":
+		return "the policy server"
+	}
+	return fmt.Sprintf("policy rule %q", decision.Rule)
+}
+
+// policyResult returns the error result of a call stopped by decision.
+func policyResult(text string, decision policy.Decision, advice string) *mcp.CallToolResult {
+	if decision.Message != "" {
+		text += " " + decision.Message
+	}
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text + "\n\n" + advice},
+		},
+	}
+}
+
+// confirmCall asks the user of the session of req to confirm the call of
+// tool, and returns whether they confirmed it.
+func confirmCall(ctx context.Context, req *mcp.CallToolRequest, tool string, in *policy.Input, decision policy.Decision) (bool, error) {
+	if req == nil || req.Session == nil {
+		return false, fmt.Errorf("no session")
+	}
+	if params := req.Session.InitializeParams(); params == nil || params.Capabilities == nil || params.Capabilities.Elicitation == nil {
+		return false, fmt.Errorf("the client doesn't support elicitation")
+	}
+	args, _ := json.Marshal(in.Args)
+	message := fmt.Sprintf("Run %s with %s? The call requires your confirmation by %s.", tool, logging.Redact(string(args)), decisionSource(decision))
+	if decision.Message != "" {
+		message += " " + decision.Message
+	}
+	res, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+		Message:         message,
+		RequestedSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.Action == "accept", nil
+}
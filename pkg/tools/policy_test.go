@@ -0,0 +1,100 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/policy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type scaleArgs struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Replicas  int    `json:"replicas"`
+}
+
+func TestPolicyInput(t *testing.T) {
+	c := config.New("test", config.Options{DefaultNamespace: "shop"})
+	in := policyInput("kube_scale", true, &scaleArgs{Name: "web", Replicas: 3}, c)
+	if in.Namespace != "shop" || !in.Mutating || in.Args["replicas"] != 3.0 {
+		t.Errorf("policyInput() = %+v, want the defaults of the config", in)
+	}
+	in = policyInput("kube_scale", true, &scaleArgs{Namespace: "prod-shop", Name: "web"}, c)
+	if in.Namespace != "prod-shop" {
+		t.Errorf("policyInput() namespace = %q, want the namespace argument", in.Namespace)
+	}
+	in = policyInput("kube_apply_resource", true, map[string]any{"manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n  namespace: prod-shop\n"}, c)
+	if in.Namespace != "prod-shop" || !slices.Equal(in.Namespaces, []string{"prod-shop"}) {
+		t.Errorf("policyInput() namespaces = %q, want the namespace of the manifest", in.Namespaces)
+	}
+}
+
+func TestPolicyMiddleware(t *testing.T) {
+	p, err := policy.Parse([]byte(`
+rules:
+- name: no-prod
+  expression: call.mutating && call.namespaces.exists(ns, ns.startsWith("prod-"))
+  effect: deny
+  message: Changes to production go through the release pipeline.
+- name: confirm-scale
+  expression: call.tool == "kube_scale"
+  effect: confirm
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := config.New("test", config.Options{})
+	var ran bool
+	next := func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+		ran = true
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	mw := policyMiddleware(p, func() *config.Config { return c })
+	write := &mcp.Tool{Name: "kube_apply_resource", Annotations: &mcp.ToolAnnotations{}}
+
+	for _, tc := range []struct {
+		name     string
+		tool     *mcp.Tool
+		args     any
+		wantRan  bool
+		wantText string
+	}{
+		{name: "allowed", tool: write, args: &scaleArgs{Namespace: "shop"}, wantRan: true},
+		{name: "denied", tool: write, args: &scaleArgs{Namespace: "prod-shop"}, wantText: `denied by policy rule "no-prod". Changes to production go through the release pipeline.`},
+		{name: "denied manifest", tool: write, args: map[string]any{"manifest": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: prod-shop\n"}, wantText: `denied by policy rule "no-prod"`},
+		{name: "read in prod", tool: &mcp.Tool{Name: "kube_get_resources"}, args: &scaleArgs{Namespace: "prod-shop"}, wantRan: true},
+		{name: "no elicitation", tool: &mcp.Tool{Name: "kube_scale"}, args: &scaleArgs{}, wantText: "requires the confirmation of the user"},
+	} {
+		ran = false
+		res, _, err := mw(tc.tool, next)(context.Background(), &mcp.CallToolRequest{}, tc.args)
+		if err != nil {
+			t.Fatalf("%s: handler() failed: %v", tc.name, err)
+		}
+		if ran != tc.wantRan {
+			t.Errorf("%s: ran = %v, want %v", tc.name, ran, tc.wantRan)
+		}
+		if tc.wantText != "" {
+			if text := res.Content[0].(*mcp.TextContent).Text; !res.IsError || !strings.Contains(text, tc.wantText) {
+				t.Errorf("%s: handler() = %v %q, want an error result with %q", tc.name, res.IsError, text, tc.wantText)
+			}
+		}
+	}
+}
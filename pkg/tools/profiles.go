@@ -0,0 +1,284 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/middleware"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/utils/ptr"
+)
+
+// UseProfileToolDescription contains the documentation for the Use Profile tool.
+// It is formatted in Markdown.
+const UseProfileToolDescription = `
+This tool switches the server to another profile. A profile is a named environment, such as *dev* or *prod*: a Kubernetes cluster, a Google Cloud project and location, and the safety settings of the tools working on them, such as read-only mode.
+
+Switching profiles reinstalls the tools: all the tools then work on the cluster and project of the new profile, and the tools that the profile doesn't allow, such as write tools in read-only profiles, are removed. Port-forwards started with the previous profile are stopped. In HTTP mode, the switch only applies to the MCP session of the client.
+
+The server refuses to switch from a read-only profile, or one with namespaced writes only or without node debugging, to a profile allowing more changes, unless it was started with *--allow-profile-escalation*: ask the user to switch, or to restart the server, instead.
+
+Always confirm the active profile with *list_profiles* before changing resources, and switch to a read-only profile when only investigating.
+
+## Arguments
+
+* *name*: The name of the profile to switch to.
+
+## Response Format
+
+The confirmation of the switch, and the profiles, as listed by *list_profiles*.
+`
+
+// ListProfilesToolDescription contains the documentation for the List Profiles tool.
+// It is formatted in Markdown.
+const ListProfilesToolDescription = `
+This tool lists the profiles of the server, which *use_profile* switches between, and shows the active one. A profile is a named environment, such as *dev* or *prod*: a Kubernetes cluster, a Google Cloud project and location, and the safety settings of the tools working on them, such as read-only mode.
+
+## Arguments
+
+None.
+
+## Response Format
+
+The profiles, with the active one marked with *:
+
+ACTIVE	NAME	CONTEXT	PROJECT	LOCATION	READ_ONLY	DESCRIPTION
+*	dev	gke_my-dev_us-central1_dev	my-dev	us-central1	false	Development cluster
+	prod	gke_my-prod_us-central1_prod	my-prod	us-central1	true	Production, read-only
+`
+
+type useProfileArgs struct {
+	Name string `json:"name"`
+}
+
+type listProfilesArgs struct{}
+
+// profileSwitcher installs the tools with the configuration of the active
+// profile, and reinstalls them when the profile changes.
+type profileSwitcher struct {
+	// ctx bounds the background work of the tools of every profile.
+	ctx        context.Context
+	s          *mcp.Server
+	c          *config.Config
+	installers []installer
+
+	mu     sync.Mutex
+	active *config.Config
+	// cancel stops the background work of the tools of the active profile,
+	// such as port-forwards.
+	cancel context.CancelFunc
+}
+
+func newProfileSwitcher(ctx context.Context, s *mcp.Server, c *config.Config, installers []installer) *profileSwitcher {
+	return &profileSwitcher{ctx: ctx, s: s, c: c, installers: installers}
+}
+
+// activeConfig returns the configuration of the active profile, or the
+// configuration of the server before the first profile is installed.
+func (p *profileSwitcher) activeConfig() *config.Config {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == nil {
+		return p.c
+	}
+	return p.active
+}
+
+// writeAccess ranks the changes that c allows: none in read-only mode, the
+// changes of namespaced resources only, all changes but node debugging, or
+// all changes.
+func writeAccess(c *config.Config) int {
+	switch {
+	case c.ReadOnly():
+		return 0
+	case c.NamespacedWritesOnly():
+		return 1
+	case !c.AllowNodeDebug():
+		return 2
+	}
+	return 3
+}
+
+// use replaces the tools of the server with the tools of the profile name.
+// If they fail to install, the tools of the active profile are restored.
+// Switching to a profile allowing more changes than the active one fails
+// unless the server allows profile escalation.
+func (p *profileSwitcher) use(ctx context.Context, name string) error {
+	pc, err := p.c.WithProfile(name)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.active != nil && writeAccess(pc) > writeAccess(p.active) && !p.c.AllowProfileEscalation() {
+		return fmt.Errorf("profile %q allows more changes than the active profile %q: the server doesn't allow switching to it without --allow-profile-escalation", name, p.active.Profile())
+	}
+
+	if err := p.install(ctx, pc); err != nil {
+		if p.active != nil {
+			if restoreErr := p.install(ctx, p.active); restoreErr != nil {
+				slog.Error("Failed to restore the tools of the active profile", "profile", p.active.Profile(), "error", restoreErr)
+			}
+		}
+		return fmt.Errorf("failed to install the tools of profile %q: %w", name, err)
+	}
+	p.active = pc
+	return nil
+}
+
+// install removes the tools of the server and installs the tools of c.
+func (p *profileSwitcher) install(ctx context.Context, c *config.Config) error {
+	names, err := toolNames(ctx, p.s)
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.s.RemoveTools(names...)
+
+	installCtx, cancel := context.WithCancel(p.ctx)
+	p.cancel = cancel
+	for _, install := range p.installers {
+		if err := install(installCtx, p.s, c); err != nil {
+			return err
+		}
+	}
+	middleware.AddTool(p.s, &mcp.Tool{
+		Name:        "use_profile",
+		Description: UseProfileToolDescription + profilesCatalog(p.c.Profiles(), c.Profile()),
+		// Switching profiles changes the cluster and the permissions of the
+		// tools.
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: ptr.To(false)},
+	}, p.useProfile)
+	// Listing the profiles is read-only, so that it is neither queued for
+	// approval nor confirmed by the policy like switching.
+	middleware.AddTool(p.s, &mcp.Tool{
+		Name:        "list_profiles",
+		Description: ListProfilesToolDescription + profilesCatalog(p.c.Profiles(), c.Profile()),
+	}, p.listProfiles)
+	return nil
+}
+
+// toolNames returns the names of the tools of s, as listed by a client.
+func toolNames(ctx context.Context, s *mcp.Server) ([]string, error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ss, err := s.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer ss.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "kubeapi-mcp-profiles"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cs.Close()
+
+	var names []string
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, tool.Name)
+	}
+	return names, nil
+}
+
+// profilesCatalog returns the list of profiles appended to the description
+// of the use_profile tool.
+func profilesCatalog(profiles []config.Profile, active string) string {
+	var out strings.Builder
+	out.WriteString("\n## Available Profiles\n\n")
+	for _, p := range profiles {
+		out.WriteString(fmt.Sprintf("* *%s*", p.Name))
+		if p.Description != "" {
+			out.WriteString(": " + p.Description)
+		}
+		if p.ReadOnly {
+			out.WriteString(" (read-only)")
+		} else if p.NamespacedWritesOnly {
+			out.WriteString(" (namespaced writes only)")
+		}
+		if p.Name == active {
+			out.WriteString(" **(active)**")
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+func (p *profileSwitcher) useProfile(ctx context.Context, _ *mcp.CallToolRequest, args *useProfileArgs) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return nil, nil, fmt.Errorf("name is required: list the profiles with list_profiles")
+	}
+	if err := p.use(ctx, args.Name); err != nil {
+		return nil, nil, err
+	}
+	profiles, err := p.formatProfiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Switched to profile %s. The tools now work on its cluster and project.\n\n", args.Name) + profiles},
+		},
+	}, nil, nil
+}
+
+func (p *profileSwitcher) listProfiles(ctx context.Context, _ *mcp.CallToolRequest, _ *listProfilesArgs) (*mcp.CallToolResult, any, error) {
+	profiles, err := p.formatProfiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: profiles},
+		},
+	}, nil, nil
+}
+
+// formatProfiles formats the profiles, with the active one marked.
+func (p *profileSwitcher) formatProfiles() (string, error) {
+	var output strings.Builder
+	p.mu.Lock()
+	active := p.active
+	p.mu.Unlock()
+	output.WriteString("ACTIVE\tNAME\tCONTEXT\tPROJECT\tLOCATION\tREAD_ONLY\tDESCRIPTION\n")
+	for _, profile := range p.c.Profiles() {
+		pc, err := p.c.WithProfile(profile.Name)
+		if err != nil {
+			return "", err
+		}
+		marker := ""
+		if profile.Name == active.Profile() {
+			marker = "*"
+		}
+		kubeContext := pc.KubeContext()
+		if kubeContext == "" {
+			kubeContext = "(current)"
+		}
+		output.WriteString(fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%t\t%s\n",
+			marker, profile.Name, kubeContext, pc.DefaultProjectID(), pc.DefaultLocation(), pc.ReadOnly(), profile.Description))
+	}
+	return output.String(), nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/utils/ptr"
+)
+
+type emptyArgs struct{}
+
+// testInstaller installs a read tool named after the profile's context, and a
+// write tool unless the profile is read-only. It fails for the context
+// "broken".
+func testInstaller(ctx context.Context, s *mcp.Server, c *config.Config) error {
+	if c.KubeContext() == "broken" {
+		return errors.New("cluster unreachable")
+	}
+	handler := func(context.Context, *mcp.CallToolRequest, *emptyArgs) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	mcp.AddTool(s, &mcp.Tool{Name: "read_" + c.KubeContext()}, handler)
+	if !c.ReadOnly() {
+		mcp.AddTool(s, &mcp.Tool{Name: "write_" + c.KubeContext()}, handler)
+	}
+	return nil
+}
+
+func TestProfileSwitcher(t *testing.T) {
+	ctx := context.Background()
+	c := config.New("test", config.Options{
+		Profiles: []config.Profile{
+			{Name: "dev", Description: "Development", Context: "dev"},
+			{Name: "prod", Context: "prod", ReadOnly: true},
+			{Name: "broken-prod", Context: "broken", ReadOnly: true},
+		},
+	})
+	s := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	p := newProfileSwitcher(ctx, s, c, []installer{testInstaller})
+
+	tools := func() []string {
+		t.Helper()
+		names, err := toolNames(ctx, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	if err := p.use(ctx, c.StartProfile()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"list_profiles", "read_dev", "use_profile", "write_dev"}, tools()); diff != "" {
+		t.Errorf("tools of dev mismatch (-want +got):\n%s", diff)
+	}
+
+	res, _, err := p.useProfile(ctx, nil, &useProfileArgs{Name: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := res.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "Switched to profile prod") || !strings.Contains(text, "*\tprod\tprod\t") {
+		t.Errorf("use_profile(prod) = %q, want prod active", text)
+	}
+	if diff := cmp.Diff([]string{"list_profiles", "read_prod", "use_profile"}, tools()); diff != "" {
+		t.Errorf("tools of prod mismatch (-want +got):\n%s", diff)
+	}
+	res, _, err = p.listProfiles(ctx, nil, &listProfilesArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "*\tprod\tprod\t") || strings.Contains(text, "Switched") {
+		t.Errorf("list_profiles() = %q, want the profiles with prod active", text)
+	}
+	if _, _, err := p.useProfile(ctx, nil, &useProfileArgs{}); err == nil {
+		t.Error("use_profile() = nil error, want error")
+	}
+
+	// The tools of the active profile are restored if the new profile fails.
+	if err := p.use(ctx, "broken-prod"); err == nil {
+		t.Error("use(broken-prod) = nil error, want error")
+	}
+	if diff := cmp.Diff([]string{"list_profiles", "read_prod", "use_profile"}, tools()); diff != "" {
+		t.Errorf("tools after failed switch mismatch (-want +got):\n%s", diff)
+	}
+	if err := p.use(ctx, "staging"); err == nil {
+		t.Error("use(staging) = nil error, want error")
+	}
+}
+
+func TestProfileSwitcherEscalation(t *testing.T) {
+	ctx := context.Background()
+	profiles := []config.Profile{
+		{Name: "prod", Context: "prod", ReadOnly: true},
+		{Name: "apps", Context: "apps", NamespacedWritesOnly: true},
+		{Name: "dev", Context: "dev"},
+		{Name: "nodes", Context: "dev", AllowNodeDebug: ptr.To(true)},
+	}
+	for _, tc := range []struct {
+		name    string
+		allow   bool
+		wantErr []string
+	}{
+		{name: "refused", wantErr: []string{"apps", "dev", "nodes"}},
+		{name: "allowed", allow: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.New("test", config.Options{Profiles: profiles, AllowProfileEscalation: tc.allow})
+			p := newProfileSwitcher(ctx, mcp.NewServer(&mcp.Implementation{Name: "test"}, nil), c, []installer{testInstaller})
+			if err := p.use(ctx, "prod"); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"apps", "dev", "nodes"} {
+				err := p.use(ctx, name)
+				if slices.Contains(tc.wantErr, name) {
+					if err == nil || !strings.Contains(err.Error(), "--allow-profile-escalation") {
+						t.Errorf("use(%s) error = %v, want escalation refused", name, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("use(%s) failed: %v", name, err)
+				}
+			}
+			// Switching to a profile allowing fewer changes is always allowed.
+			if err := p.use(ctx, "prod"); err != nil {
+				t.Errorf("use(prod) failed: %v", err)
+			}
+		})
+	}
+
+	// Node debugging is an escalation of a profile allowing all other changes.
+	c := config.New("test", config.Options{Profiles: profiles})
+	p := newProfileSwitcher(ctx, mcp.NewServer(&mcp.Implementation{Name: "test"}, nil), c, []installer{testInstaller})
+	if err := p.use(ctx, "dev"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.use(ctx, "nodes"); err == nil {
+		t.Error("use(nodes) = nil error, want escalation refused")
+	}
+}
+
+func TestProfilesCatalog(t *testing.T) {
+	got := profilesCatalog([]config.Profile{
+		{Name: "dev", Description: "Development"},
+		{Name: "prod", ReadOnly: true},
+	}, "prod")
+	want := "\n## Available Profiles\n\n* *dev*: Development\n* *prod* (read-only) **(active)**\n"
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("profilesCatalog() mismatch (-want +got):\n%s", diff)
+	}
+}
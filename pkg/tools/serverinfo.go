@@ -0,0 +1,269 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/middleware"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ServerInfoToolDescription contains the documentation for the Server Info tool.
+// It is formatted in Markdown.
+const ServerInfoToolDescription = `
+This tool returns the version and build of the server, its active environment, its mode and limits, the tools it provides, and the reasons why other tools are disabled.
+
+Use it when a tool is missing or behaves unexpectedly, e.g. when a write tool isn't available, before reading the server logs, and to report the version of the server in bug reports.
+
+## Arguments
+
+None.
+
+## Response Format
+
+` + "```" + `
+Version: v0.3.0
+Build: go1.24.4, revision 1a2b3c4 (2025-09-30T12:00:00Z)
+
+## Environment
+
+Profile: dev
+Context: gke_my-dev_us-central1_dev
+Project: my-dev
+Location: us-central1
+Default namespace: (kubeconfig context)
+
+## Mode
+
+Mode: read-only
+Protected namespaces: kube-system, kube-public
+Secret redaction: mask
+Approval required: false
+Policy: false
+
+## Limits
+
+LIMIT            VALUE
+request timeout  none
+kube_get_logs    timeout 30s
+kube qps         client-go default
+kube burst       client-go default
+cache ttl        disabled
+
+## Tools
+
+GROUP   TOOLS
+gke     gke_get_cluster, gke_list_clusters
+kube    kube_get_resources, kube_list_api_resources
+server  server_info
+
+## Disabled Tools
+
+* Write tools, such as kube_apply_resource: the server is in read-only mode (--read-only).
+` + "```" + `
+`
+
+type serverInfoArgs struct{}
+
+// serverInfo provides the server_info tool, reporting the configuration c of
+// the tools of s.
+type serverInfo struct {
+	s *mcp.Server
+	c *config.Config
+}
+
+// installServerInfo adds the server_info tool to s.
+func installServerInfo(_ context.Context, s *mcp.Server, c *config.Config) error {
+	i := &serverInfo{s: s, c: c}
+	middleware.AddTool(s, &mcp.Tool{
+		Name:        "server_info",
+		Description: ServerInfoToolDescription,
+	}, i.serverInfo)
+	return nil
+}
+
+func (i *serverInfo) serverInfo(ctx context.Context, _ *mcp.CallToolRequest, _ *serverInfoArgs) (*mcp.CallToolResult, any, error) {
+	names, err := toolNames(ctx, i.s)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	c := i.c
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Version: %s\n", c.Version()))
+	output.WriteString(fmt.Sprintf("Build: %s\n", buildInfo()))
+
+	output.WriteString("\n## Environment\n\n")
+	output.WriteString(fmt.Sprintf("Profile: %s\n", orDefault(c.Profile(), "(none)")))
+	output.WriteString(fmt.Sprintf("Context: %s\n", orDefault(c.KubeContext(), "(current)")))
+	output.WriteString(fmt.Sprintf("Project: %s\n", orDefault(c.DefaultProjectID(), "(none)")))
+	output.WriteString(fmt.Sprintf("Location: %s\n", orDefault(c.DefaultLocation(), "(none)")))
+	output.WriteString(fmt.Sprintf("Default namespace: %s\n", orDefault(c.DefaultNamespace(), "(kubeconfig context)")))
+
+	output.WriteString("\n## Mode\n\n")
+	output.WriteString(fmt.Sprintf("Mode: %s\n", serverMode(c)))
+	output.WriteString(fmt.Sprintf("Protected namespaces: %s\n", orDefault(strings.Join(c.ProtectedNamespaces(), ", "), "(none)")))
+	redaction := config.SecretRedactionMask
+	if !c.RedactSecrets() {
+		redaction = config.SecretRedactionNone
+	}
+	output.WriteString(fmt.Sprintf("Secret redaction: %s\n", redaction))
+	output.WriteString(fmt.Sprintf("Approval required: %t\n", c.RequireApproval()))
+	output.WriteString(fmt.Sprintf("Policy: %t\n", c.Policy() != nil))
+
+	output.WriteString("\n## Limits\n\n")
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LIMIT\tVALUE")
+	fmt.Fprintf(w, "request timeout\t%s\n", durationOr(c.RequestTimeout(), "none"))
+	timeouts := c.ToolTimeouts()
+	for _, name := range slices.Sorted(maps.Keys(timeouts)) {
+		fmt.Fprintf(w, "%s\ttimeout %s\n", name, durationOr(timeouts[name], "none"))
+	}
+	kubeQPS, kubeBurst := "client-go default", "client-go default"
+	if c.KubeQPS() > 0 {
+		kubeQPS = fmt.Sprint(c.KubeQPS())
+	}
+	if c.KubeBurst() > 0 {
+		kubeBurst = fmt.Sprint(c.KubeBurst())
+	}
+	fmt.Fprintf(w, "kube qps\t%s\n", kubeQPS)
+	fmt.Fprintf(w, "kube burst\t%s\n", kubeBurst)
+	fmt.Fprintf(w, "cache ttl\t%s\n", durationOr(c.CacheTTL(), "disabled"))
+	if err := w.Flush(); err != nil {
+		return nil, nil, err
+	}
+
+	output.WriteString("\n## Tools\n\n")
+	w = tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GROUP\tTOOLS")
+	groups := map[string][]string{}
+	for _, name := range names {
+		group, _, _ := strings.Cut(name, "_")
+		groups[group] = append(groups[group], name)
+	}
+	for _, group := range slices.Sorted(maps.Keys(groups)) {
+		slices.Sort(groups[group])
+		fmt.Fprintf(w, "%s\t%s\n", group, strings.Join(groups[group], ", "))
+	}
+	if err := w.Flush(); err != nil {
+		return nil, nil, err
+	}
+
+	if disabled := disabledTools(c); len(disabled) > 0 {
+		output.WriteString("\n## Disabled Tools\n\n")
+		for _, reason := range disabled {
+			output.WriteString("* " + reason + "\n")
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: output.String()},
+		},
+	}, nil, nil
+}
+
+// buildInfo describes the Go version and the VCS revision of the binary.
+func buildInfo() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(unknown)"
+	}
+	settings := map[string]string{}
+	for _, s := range bi.Settings {
+		settings[s.Key] = s.Value
+	}
+	info := bi.GoVersion
+	if revision := settings["vcs.revision"]; revision != "" {
+		info += ", revision " + revision
+		if t := settings["vcs.time"]; t != "" {
+			info += " (" + t + ")"
+		}
+		if settings["vcs.modified"] == "true" {
+			info += ", modified"
+		}
+	}
+	return info
+}
+
+// serverMode describes the restrictions of the changes of c.
+func serverMode(c *config.Config) string {
+	switch {
+	case c.ReadOnly():
+		return "read-only"
+	case c.NamespacedWritesOnly():
+		return "namespaced writes only"
+	default:
+		return "read-write"
+	}
+}
+
+// disabledTools returns the tools disabled by c, with the reasons why and the
+// flags enabling them.
+func disabledTools(c *config.Config) []string {
+	var disabled []string
+	if c.ReadOnly() {
+		disabled = append(disabled, "Write tools, such as kube_apply_resource: the server is in read-only mode (--read-only).")
+	} else {
+		if c.NamespacedWritesOnly() {
+			disabled = append(disabled, "Writes of cluster-scoped resources and GKE clusters, such as kube_create_namespace and gke_create_cluster: only namespaced resources can be changed (--namespaced-writes-only).")
+		}
+		if !c.AllowNodeDebug() {
+			disabled = append(disabled, "kube_debug_node: node debugging isn't enabled (--allow-node-debug).")
+		}
+		if !c.RequireApproval() {
+			disabled = append(disabled, "pending_actions_list, approve_action, reject_action: changes don't require approval (--require-approval).")
+		}
+	}
+	if c.NotificationsSubscription() == "" {
+		disabled = append(disabled, "gke_recent_notifications: no Pub/Sub subscription of GKE notifications is configured (--notifications-subscription).")
+	}
+	if c.HealthCheckInterval() <= 0 {
+		disabled = append(disabled, "kube_health_check_findings: background health checks are disabled (--health-check-interval).")
+	}
+	if c.Alerts() == nil {
+		disabled = append(disabled, "alerts_list: alerts are only received in HTTP mode (--receive-alerts).")
+	}
+	if c.UDTPath() == "" {
+		disabled = append(disabled, "udt_*: no troubleshooting playbooks are configured (--udt).")
+	}
+	if len(c.Profiles()) == 0 {
+		disabled = append(disabled, "list_profiles, use_profile: no profiles are configured (--profiles).")
+	}
+	return disabled
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func durationOr(d time.Duration, def string) string {
+	if d <= 0 {
+		return def
+	}
+	return d.String()
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestServerInfo(t *testing.T) {
+	ctx := context.Background()
+	c := config.New("v1.2.3", config.Options{
+		ReadOnly:         true,
+		DefaultNamespace: "shop",
+		RequestTimeout:   time.Minute,
+		ToolTimeouts:     map[string]time.Duration{"kube_get_logs": 30 * time.Second},
+	})
+	s := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	handler := func(context.Context, *mcp.CallToolRequest, *emptyArgs) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	mcp.AddTool(s, &mcp.Tool{Name: "kube_get_resources"}, handler)
+	mcp.AddTool(s, &mcp.Tool{Name: "gke_list_clusters"}, handler)
+	mcp.AddTool(s, &mcp.Tool{Name: "kube_get_logs"}, handler)
+	if err := installServerInfo(ctx, s, c); err != nil {
+		t.Fatal(err)
+	}
+
+	i := &serverInfo{s: s, c: c}
+	res, _, err := i.serverInfo(ctx, nil, &serverInfoArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := res.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{
+		"Version: v1.2.3\n",
+		"Default namespace: shop\n",
+		"Mode: read-only\n",
+		"Secret redaction: mask\n",
+		"request timeout  1m0s\n",
+		"kube_get_logs    timeout 30s\n",
+		"cache ttl        disabled\n",
+		"gke     gke_list_clusters\n",
+		"kube    kube_get_logs, kube_get_resources\n",
+		"server  server_info\n",
+		"* Write tools, such as kube_apply_resource: the server is in read-only mode (--read-only).\n",
+		"* udt_*: no troubleshooting playbooks are configured (--udt).\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("serverInfo() = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "kube_debug_node") {
+		t.Errorf("serverInfo() = %q, want no node debugging reason in read-only mode", got)
+	}
+}
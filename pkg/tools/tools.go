@@ -16,26 +16,95 @@ package tools
 
 import (
 	"context"
+	"slices"
+	"time"
 
 	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
 	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/kubernetes"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/middleware"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/notes"
 	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/udt"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 type installer func(ctx context.Context, s *mcp.Server, c *config.Config) error
 
+var installers = []installer{
+	kubernetes.Install,
+	udt.Install,
+	installServerInfo,
+}
+
+// Install adds the tools to s. Their handlers are wrapped in the tool
+// middleware: panics are recovered, calls are recorded in the session
+// journal, changes wait for approval if required, expected failures are
+// returned with advice, and calls are bounded by timeouts.
 func Install(ctx context.Context, s *mcp.Server, c *config.Config) error {
-	installers := []installer{
-		kubernetes.Install,
-		udt.Install,
+	// The session notes and journal are kept in a single store, so that
+	// profile switches, which reinstall the tools, don't drop them.
+	store := notes.NewStore()
+	installers := append(slices.Clone(installers), store.Install)
+	var switcher *profileSwitcher
+	active := func() *config.Config { return c }
+	if len(c.Profiles()) > 0 {
+		switcher = newProfileSwitcher(ctx, s, c, installers)
+		active = switcher.activeConfig
+	}
+
+	chain := []middleware.Middleware{middleware.Recover, store.Journal}
+	if p := c.Policy(); p != nil {
+		chain = append(chain, policyMiddleware(p, active))
+	}
+	if approvalStore := c.Approvals(); approvalStore != nil {
+		installers = append(installers, func(ctx context.Context, s *mcp.Server, _ *config.Config) error {
+			return approvalStore.Install(ctx, s)
+		})
+		chain = append(chain, approvalStore.Middleware)
 	}
+	middleware.Use(s, append(chain, errorResultMiddleware, timeoutMiddleware(c))...)
 
-	for _, installer := range installers {
-		if err := installer(ctx, s, c); err != nil {
+	if switcher != nil {
+		if err := switcher.use(ctx, c.StartProfile()); err != nil {
 			return err
 		}
+	} else {
+		for _, installer := range installers {
+			if err := installer(ctx, s, c); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
+
+// callTimeout is implemented by the arguments of the tools that bound the
+// duration of their calls themselves, such as kube_exec with the timeout of
+// its command.
+type callTimeout interface {
+	// CallTimeout returns the maximum duration of the call.
+	CallTimeout() time.Duration
+}
+
+// timeoutMiddleware bounds the duration of every tool call by deriving a
+// context with the timeout of the tool, so that a hung watch or log stream
+// can't block the session. The timeout of the arguments of the call, if any,
+// overrides the request timeout, but not a timeout configured for the tool.
+func timeoutMiddleware(c *config.Config) middleware.Middleware {
+	return func(tool *mcp.Tool, next middleware.Handler) middleware.Handler {
+		timeout := c.ToolTimeout(tool.Name)
+		_, configured := c.ToolTimeouts()[tool.Name]
+		return func(ctx context.Context, req *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
+			timeout := timeout
+			if t, ok := args.(callTimeout); ok && !configured {
+				timeout = t.CallTimeout()
+			}
+			if timeout <= 0 {
+				return next(ctx, req, args)
+			}
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next(ctx, req, args)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// timeoutArgs are the arguments of a call bounding its own duration.
+type timeoutArgs struct{ timeout time.Duration }
+
+func (a *timeoutArgs) CallTimeout() time.Duration { return a.timeout }
+
+func TestTimeoutMiddleware(t *testing.T) {
+	c := config.New("test", config.Options{
+		RequestTimeout: time.Minute,
+		ToolTimeouts: map[string]time.Duration{
+			"kube_get_pod_logs": 5 * time.Minute,
+			"kube_debug_node":   0,
+		},
+	})
+	var deadline time.Time
+	var hasDeadline bool
+	next := func(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		deadline, hasDeadline = ctx.Deadline()
+		return nil, nil, nil
+	}
+
+	for _, tc := range []struct {
+		tool string
+		args any
+		want time.Duration
+	}{
+		{tool: "kube_get_resources", want: time.Minute},
+		{tool: "kube_get_pod_logs", want: 5 * time.Minute},
+		{tool: "kube_debug_node"},
+		// The timeout of the arguments overrides the request timeout, but
+		// not the timeout of the tool.
+		{tool: "kube_exec", args: &timeoutArgs{timeout: 10 * time.Minute}, want: 10 * time.Minute},
+		{tool: "kube_get_pod_logs", args: &timeoutArgs{timeout: 10 * time.Minute}, want: 5 * time.Minute},
+	} {
+		handler := timeoutMiddleware(c)(&mcp.Tool{Name: tc.tool}, next)
+		start := time.Now()
+		if _, _, err := handler(context.Background(), &mcp.CallToolRequest{}, tc.args); err != nil {
+			t.Fatal(err)
+		}
+		if tc.want == 0 {
+			if hasDeadline {
+				t.Errorf("%s has a deadline, want none", tc.tool)
+			}
+			continue
+		}
+		if got := deadline.Sub(start); !hasDeadline || got < tc.want || got > tc.want+time.Second {
+			t.Errorf("%s timeout = %v, want %v", tc.tool, got, tc.want)
+		}
+	}
+}
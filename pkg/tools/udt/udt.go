@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/dmitryshnayder/kubeapi-mcp/pkg/config"
+	"github.com/dmitryshnayder/kubeapi-mcp/pkg/tools/middleware"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -41,7 +42,7 @@ This tool scans a predefined directory for Markdown playbook files, extracts the
 When a user reports an issue, you must follow this procedure explicitly:
 
 **1. Initial Triage & Symptom Collection**
-* First, perform a preliminary investigation to gather clear symptoms. Use standard MCP tools (e.g., 'gke_get_cluster', 'kube_get_resource') to understand the initial state of the problem.
+* First, perform a preliminary investigation to gather clear symptoms. Start with 'kube_cluster_health' for a snapshot of the cluster, then use standard MCP tools (e.g., 'gke_get_cluster', 'kube_get_resource') to understand the initial state of the problem.
 * **Be proactive.** If you can find any required information yourself (like cluster location, resource names, etc.), you must do so without asking the user.
 
 **2. Playbook Discovery**
@@ -117,17 +118,17 @@ func Install(ctx context.Context, s *mcp.Server, c *config.Config) error {
 		return fmt.Errorf("failed to scan playbooks: %w", err)
 	}
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "udt_list_playbooks",
 		Description: udtListPlaybooksToolDescription,
 	}, h.listPlaybooks)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "udt_get_playbook",
 		Description: udtGetPlaybookToolDescription,
 	}, h.getPlaybook)
 
-	mcp.AddTool(s, &mcp.Tool{
+	middleware.AddTool(s, &mcp.Tool{
 		Name:        "udt_search_playbooks",
 		Description: udtSearchPlaybooksToolDescription,
 	}, h.searchPlaybooks)